@@ -0,0 +1,247 @@
+// Package auth authenticates the callers of the API, with API keys or OIDC
+// bearer tokens, and restricts the repositories and documents each may use.
+package auth
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// Authentication methods of principals.
+const (
+	MethodAPIKey = "api_key"
+	MethodOIDC   = "oidc"
+)
+
+var (
+	// ErrNoCredentials is returned for requests without an API key or token.
+	ErrNoCredentials = errors.New("missing API key or bearer token")
+	// ErrInvalidCredentials is returned for unknown keys and invalid tokens.
+	ErrInvalidCredentials = errors.New("invalid API key or bearer token")
+)
+
+// Config is the auth configuration file of the API.
+type Config struct {
+	APIKeys []APIKey    `json:"api_keys"`
+	OIDC    *OIDCConfig `json:"oidc,omitempty"`
+}
+
+// Access restricts what a principal may run. Empty lists allow everything.
+type Access struct {
+	// AllowedRepos are the GitHub repositories, as owner/name patterns
+	// (path.Match), that workflows may be run on, e.g. "canonical/*"
+	AllowedRepos []string `json:"allowed_repos"`
+
+	// AllowedDocs are the IDs of the Google Docs that may be processed
+	AllowedDocs []string `json:"allowed_docs"`
+}
+
+// APIKey is a key callers authenticate with. Only the SHA-256 of the key is
+// configured, e.g. from `printf %s "$KEY" | sha256sum`.
+type APIKey struct {
+	Name      string `json:"name"`
+	KeySHA256 string `json:"key_sha256"`
+	Access
+}
+
+// OIDCConfig accepts the ID tokens of an OpenID Connect issuer as bearer
+// tokens, for the principals it lists.
+type OIDCConfig struct {
+	Issuer   string `json:"issuer"`
+	Audience string `json:"audience"`
+
+	Principals []OIDCPrincipal `json:"principals"`
+}
+
+// OIDCPrincipal matches the tokens of a subject, or of verified emails
+// matching a pattern (path.Match), e.g. "*@example.com".
+type OIDCPrincipal struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Email   string `json:"email"`
+	Access
+}
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Name identifies the caller in logs and run records
+	Name   string
+	Method string
+	Access
+}
+
+// LoadConfig reads and validates an auth configuration file.
+func LoadConfig(filePath string) (*Config, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config: %w", err)
+	}
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse auth config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that keys and principals are complete and patterns valid.
+func (c *Config) Validate() error {
+	if len(c.APIKeys) == 0 && c.OIDC == nil {
+		return errors.New("auth config has no api_keys or oidc")
+	}
+	for i, key := range c.APIKeys {
+		if key.Name == "" {
+			return fmt.Errorf("api key %d has no name", i)
+		}
+		if sum, err := hex.DecodeString(key.KeySHA256); err != nil || len(sum) != sha256.Size {
+			return fmt.Errorf("api key %s: key_sha256 must be a hex SHA-256", key.Name)
+		}
+		if err := key.Access.validate(); err != nil {
+			return fmt.Errorf("api key %s: %w", key.Name, err)
+		}
+	}
+	if c.OIDC == nil {
+		return nil
+	}
+	if c.OIDC.Issuer == "" || c.OIDC.Audience == "" {
+		return errors.New("oidc needs an issuer and an audience")
+	}
+	for i, p := range c.OIDC.Principals {
+		if p.Subject == "" && p.Email == "" {
+			return fmt.Errorf("oidc principal %d has no subject or email", i)
+		}
+		if _, err := path.Match(p.Email, ""); err != nil {
+			return fmt.Errorf("oidc principal %d: invalid email pattern: %w", i, err)
+		}
+		if err := p.Access.validate(); err != nil {
+			return fmt.Errorf("oidc principal %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (a Access) validate() error {
+	for _, pattern := range a.AllowedRepos {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid repo pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// CanUseRepo reports whether workflows may be run on the repository owner/name.
+func (a Access) CanUseRepo(owner, name string) bool {
+	if len(a.AllowedRepos) == 0 {
+		return true
+	}
+	repo := strings.ToLower(owner + "/" + name)
+	for _, pattern := range a.AllowedRepos {
+		if ok, _ := path.Match(strings.ToLower(pattern), repo); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CanUseDoc reports whether the document may be processed.
+func (a Access) CanUseDoc(docID string) bool {
+	return len(a.AllowedDocs) == 0 || slices.Contains(a.AllowedDocs, docID)
+}
+
+// Authenticator checks the credentials of requests against a Config.
+type Authenticator struct {
+	keys     []APIKey
+	oidc     *OIDCConfig
+	verifier *oidc.IDTokenVerifier
+}
+
+// New returns an authenticator for cfg. With OIDC configured, the issuer's
+// discovery document is fetched, so it must be reachable.
+func New(ctx context.Context, cfg *Config) (*Authenticator, error) {
+	a := &Authenticator{keys: cfg.APIKeys, oidc: cfg.OIDC}
+	if cfg.OIDC != nil {
+		provider, err := oidc.NewProvider(ctx, cfg.OIDC.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover OIDC issuer: %w", err)
+		}
+		a.verifier = provider.Verifier(&oidc.Config{ClientID: cfg.OIDC.Audience})
+	}
+	return a, nil
+}
+
+// Authenticate returns the principal of a bearer token or API key.
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+	sum := sha256.Sum256([]byte(token))
+	for _, key := range a.keys {
+		want, _ := hex.DecodeString(key.KeySHA256)
+		if subtle.ConstantTimeCompare(sum[:], want) == 1 {
+			return &Principal{Name: key.Name, Method: MethodAPIKey, Access: key.Access}, nil
+		}
+	}
+	if a.verifier == nil || strings.Count(token, ".") != 2 {
+		return nil, ErrInvalidCredentials
+	}
+	return a.authenticateToken(ctx, token)
+}
+
+// authenticateToken verifies an ID token and returns the first principal
+// matching its subject or verified email.
+func (a *Authenticator) authenticateToken(ctx context.Context, raw string) (*Principal, error) {
+	token, err := a.verifier.Verify(ctx, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := token.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	for _, p := range a.oidc.Principals {
+		matched := p.Subject != "" && p.Subject == token.Subject
+		if !matched && p.Email != "" && claims.EmailVerified {
+			matched, _ = path.Match(strings.ToLower(p.Email), strings.ToLower(claims.Email))
+		}
+		if !matched {
+			continue
+		}
+		name := p.Name
+		if name == "" {
+			name = cmp.Or(claims.Email, token.Subject)
+		}
+		return &Principal{Name: name, Method: MethodOIDC, Access: p.Access}, nil
+	}
+	return nil, fmt.Errorf("%w: no principal for subject %s", ErrInvalidCredentials, token.Subject)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns ctx carrying the authenticated caller.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the authenticated caller, or nil when the API doesn't
+// require authentication.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/google/go-cmp/cmp"
+)
+
+const issuer = "https://issuer.example.com"
+
+func hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	cfg := &Config{APIKeys: []APIKey{
+		{Name: "docs", KeySHA256: hash("docs-key"), Access: Access{AllowedRepos: []string{"canonical/*"}, AllowedDocs: []string{"doc-a"}}},
+		{Name: "admin", KeySHA256: hash("admin-key")},
+	}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	a, err := New(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := a.Authenticate(context.Background(), "docs-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Principal{Name: "docs", Method: MethodAPIKey, Access: cfg.APIKeys[0].Access}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Authenticate() mismatch (-want +got):\n%s", diff)
+	}
+	if !got.CanUseRepo("Canonical", "ubuntu.com") || got.CanUseRepo("other", "ubuntu.com") {
+		t.Error("CanUseRepo() doesn't follow the allowed repos")
+	}
+	if !got.CanUseDoc("doc-a") || got.CanUseDoc("doc-b") {
+		t.Error("CanUseDoc() doesn't follow the allowed docs")
+	}
+
+	admin, err := a.Authenticate(context.Background(), "admin-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !admin.CanUseRepo("any", "repo") || !admin.CanUseDoc("doc-b") {
+		t.Error("key without restrictions is restricted")
+	}
+
+	if _, err := a.Authenticate(context.Background(), "wrong-key"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() of an unknown key error = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := a.Authenticate(context.Background(), ""); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Authenticate() without a key error = %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestAuthenticateOIDC(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(claims map[string]any) string {
+		t.Helper()
+		token, err := jwt.Signed(signer).Claims(claims).Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	cfg := &OIDCConfig{Issuer: issuer, Audience: "bauer", Principals: []OIDCPrincipal{
+		{Name: "deploy-bot", Subject: "bot-1"},
+		{Email: "*@example.com", Access: Access{AllowedDocs: []string{"doc-a"}}},
+	}}
+	a := &Authenticator{
+		oidc: cfg,
+		verifier: oidc.NewVerifier(issuer, &oidc.StaticKeySet{PublicKeys: []crypto.PublicKey{&key.PublicKey}}, &oidc.Config{
+			ClientID:             "bauer",
+			SupportedSigningAlgs: []string{oidc.RS256},
+		}),
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	for _, tt := range []struct {
+		name    string
+		claims  map[string]any
+		want    *Principal
+		wantErr error
+	}{
+		{
+			name:   "subject",
+			claims: map[string]any{"iss": issuer, "aud": "bauer", "sub": "bot-1", "exp": exp},
+			want:   &Principal{Name: "deploy-bot", Method: MethodOIDC},
+		},
+		{
+			name:   "verified email",
+			claims: map[string]any{"iss": issuer, "aud": "bauer", "sub": "u-2", "email": "Jo@example.com", "email_verified": true, "exp": exp},
+			want:   &Principal{Name: "Jo@example.com", Method: MethodOIDC, Access: Access{AllowedDocs: []string{"doc-a"}}},
+		},
+		{
+			name:    "unverified email",
+			claims:  map[string]any{"iss": issuer, "aud": "bauer", "sub": "u-2", "email": "jo@example.com", "exp": exp},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "other audience",
+			claims:  map[string]any{"iss": issuer, "aud": "other", "sub": "bot-1", "exp": exp},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "expired",
+			claims:  map[string]any{"iss": issuer, "aud": "bauer", "sub": "bot-1", "exp": time.Now().Add(-time.Hour).Unix()},
+			wantErr: ErrInvalidCredentials,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.Authenticate(context.Background(), sign(tt.claims))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Authenticate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		cfg  Config
+	}{
+		{"empty", Config{}},
+		{"unnamed key", Config{APIKeys: []APIKey{{KeySHA256: hash("k")}}}},
+		{"plain key", Config{APIKeys: []APIKey{{Name: "ci", KeySHA256: "k"}}}},
+		{"bad repo pattern", Config{APIKeys: []APIKey{{Name: "ci", KeySHA256: hash("k"), Access: Access{AllowedRepos: []string{"["}}}}}},
+		{"no audience", Config{OIDC: &OIDCConfig{Issuer: issuer}}},
+		{"principal without subject", Config{OIDC: &OIDCConfig{Issuer: issuer, Audience: "bauer", Principals: []OIDCPrincipal{{Name: "x"}}}}},
+	} {
+		if err := tt.cfg.Validate(); err == nil {
+			t.Errorf("Validate(%s) = nil, want an error", tt.name)
+		}
+	}
+}
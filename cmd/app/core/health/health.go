@@ -0,0 +1,110 @@
+// Package health checks the dependencies of the API server, for the health
+// endpoint orchestration platforms probe before routing jobs to it.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status of a check, and of the server.
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// probeTimeout is how long a probe may take before it fails.
+const probeTimeout = 15 * time.Second
+
+// Probe checks a dependency; nil means it's usable.
+type Probe func(ctx context.Context) error
+
+// Check is the outcome of a probe.
+type Check struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the outcome of the probes of a Checker, by name. The server is
+// ok when all of them are.
+type Report struct {
+	Status    string           `json:"status"`
+	Checks    map[string]Check `json:"checks"`
+	CheckedAt time.Time        `json:"checked_at"`
+}
+
+// Checker runs named probes, at most once per TTL: probes start processes
+// and call APIs, which platforms probing every few seconds would overload.
+type Checker struct {
+	TTL time.Duration
+
+	names  []string
+	probes map[string]Probe
+
+	mu   sync.Mutex
+	last *Report
+}
+
+// NewChecker returns a checker caching its report for ttl.
+func NewChecker(ttl time.Duration) *Checker {
+	return &Checker{TTL: ttl, probes: map[string]Probe{}}
+}
+
+// Add registers a probe under name.
+func (c *Checker) Add(name string, probe Probe) {
+	c.names = append(c.names, name)
+	c.probes[name] = probe
+}
+
+// Run runs the probes at the same time, or returns the last report while
+// it's fresh.
+func (c *Checker) Run(ctx context.Context) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.last != nil && time.Since(c.last.CheckedAt) < c.TTL {
+		return c.last
+	}
+
+	report := &Report{Status: StatusOK, Checks: map[string]Check{}, CheckedAt: time.Now().UTC()}
+	checks := make([]Check, len(c.names))
+	var wg sync.WaitGroup
+	for i, name := range c.names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checks[i] = run(ctx, c.probes[name])
+		}()
+	}
+	wg.Wait()
+	for i, name := range c.names {
+		report.Checks[name] = checks[i]
+		if checks[i].Status != StatusOK {
+			report.Status = StatusFailed
+		}
+	}
+	c.last = report
+	return report
+}
+
+func run(ctx context.Context, probe Probe) Check {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	start := time.Now()
+	errs := make(chan error, 1)
+	go func() { errs <- probe(ctx) }()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	check := Check{Status: StatusOK, Duration: time.Since(start).Round(time.Millisecond).String()}
+	if err != nil {
+		check.Status = StatusFailed
+		check.Error = err.Error()
+	}
+	return check
+}
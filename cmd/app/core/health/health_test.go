@@ -0,0 +1,48 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestChecker_Run(t *testing.T) {
+	calls := 0
+	checker := NewChecker(time.Minute)
+	checker.Add("ok", func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	checker.Add("broken", func(ctx context.Context) error {
+		return errors.New("not logged in")
+	})
+
+	report := checker.Run(context.Background())
+	if report.Status != StatusFailed {
+		t.Errorf("Status = %q, want %q", report.Status, StatusFailed)
+	}
+	if got := report.Checks["ok"].Status; got != StatusOK {
+		t.Errorf("ok check = %q, want %q", got, StatusOK)
+	}
+	if got := report.Checks["broken"]; got.Status != StatusFailed || got.Error != "not logged in" {
+		t.Errorf("broken check = %+v", got)
+	}
+
+	// The report is reused within the TTL
+	checker.Run(context.Background())
+	if calls != 1 {
+		t.Errorf("Probe ran %d times, want 1", calls)
+	}
+}
+
+func TestWritable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "bauer-output")
+	if err := Writable(dir)(context.Background()); err != nil {
+		t.Errorf("Writable() error = %v", err)
+	}
+	if err := DiskSpace(dir, 1)(context.Background()); err != nil {
+		t.Errorf("DiskSpace() error = %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"bauer/internal/copilotcli"
+	"bauer/internal/gdocs"
+	"bauer/internal/github"
+)
+
+// DefaultMinFreeBytes is the free disk space the output directory needs:
+// runs clone repositories and write their artifacts there.
+const DefaultMinFreeBytes = 1 << 30
+
+// GoogleCredentials checks the Google credentials of opts by fetching a token.
+func GoogleCredentials(opts gdocs.ClientOptions) Probe {
+	return func(ctx context.Context) error {
+		return gdocs.CheckCredentials(ctx, opts)
+	}
+}
+
+// GitHubCLI checks the gh CLI is installed and logged in to host.
+func GitHubCLI(host string) Probe {
+	return func(ctx context.Context) error {
+		return github.ValidateGitHubAuth(host)
+	}
+}
+
+// Copilot checks the Copilot CLI starts and answers a ping.
+func Copilot(cwd string) Probe {
+	return func(ctx context.Context) error {
+		return copilotcli.Ping(cwd)
+	}
+}
+
+// Writable checks a file can be created in dir, creating dir if needed.
+func Writable(dir string) Probe {
+	return func(ctx context.Context) error {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		f, err := os.CreateTemp(dir, ".bauer-health-*")
+		if err != nil {
+			return fmt.Errorf("%s is not writable: %w", dir, err)
+		}
+		f.Close()
+		return os.Remove(f.Name())
+	}
+}
+
+// DiskSpace checks the file system of dir has minFree bytes free.
+func DiskSpace(dir string, minFree uint64) Probe {
+	return func(ctx context.Context) error {
+		free, err := freeBytes(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read free disk space of %s: %w", dir, err)
+		}
+		if free < minFree {
+			return fmt.Errorf("%d MiB free in %s, under %d MiB", free>>20, dir, minFree>>20)
+		}
+		return nil
+	}
+}
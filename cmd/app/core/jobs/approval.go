@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotAwaitingApproval is returned by Decide for jobs that aren't waiting
+// for their plan to be approved.
+var ErrNotAwaitingApproval = errors.New("job is not waiting for approval")
+
+// Decision is the answer to the approval request of a job.
+type Decision struct {
+	Approved bool
+	Reason   string
+
+	// By is the principal that decided, when the API requires authentication
+	By string
+}
+
+// AwaitApproval blocks job id until its plan is decided with Decide. Drains of
+// the queue interrupt the wait with ErrInterrupted, so the job can be resumed.
+func (q *Queue) AwaitApproval(ctx context.Context, id string) (Decision, error) {
+	decided := make(chan Decision, 1)
+	q.mu.Lock()
+	q.approvals[id] = decided
+	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		delete(q.approvals, id)
+		q.mu.Unlock()
+	}()
+
+	select {
+	case decision := <-decided:
+		return decision, nil
+	case <-q.stopping:
+		return Decision{}, ErrInterrupted
+	case <-ctx.Done():
+		return Decision{}, ctx.Err()
+	}
+}
+
+// Decide approves or rejects the plan of job id.
+func (q *Queue) Decide(id string, decision Decision) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	decided, ok := q.approvals[id]
+	if !ok {
+		return ErrNotAwaitingApproval
+	}
+	delete(q.approvals, id)
+	decided <- decision
+	return nil
+}
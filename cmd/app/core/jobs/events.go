@@ -0,0 +1,116 @@
+package jobs
+
+import (
+	"time"
+
+	"bauer/internal/progress"
+)
+
+// EventStatus events report a change of a job's status.
+const EventStatus = "status"
+
+// maxEvents is how many events of a job are kept for subscribers that
+// connect late. Deltas aren't kept.
+const maxEvents = 1000
+
+// subscriberBuffer is how many events a subscriber can fall behind by. One
+// that falls further behind is dropped, to reconnect from its last event.
+const subscriberBuffer = 256
+
+// Event is a progress event of a job, numbered in the order it happened.
+type Event struct {
+	ID   int       `json:"id"`
+	Time time.Time `json:"time"`
+	progress.Event
+
+	// Status and Error are set for status events
+	Status Status `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// stream holds the events of a job and the subscribers following them.
+// It is guarded by the queue's mutex.
+type stream struct {
+	events      []Event
+	lastID      int
+	subscribers map[*subscriber]bool
+	closed      bool
+}
+
+type subscriber struct {
+	ch     chan Event
+	deltas bool
+}
+
+func newStream() *stream {
+	return &stream{subscribers: make(map[*subscriber]bool)}
+}
+
+// publish numbers an event, keeps it and sends it to the subscribers.
+func (s *stream) publish(event Event) {
+	s.lastID++
+	event.ID = s.lastID
+	event.Time = time.Now().UTC()
+	isDelta := event.Type == progress.EventDelta
+	if !isDelta {
+		s.events = append(s.events, event)
+		if len(s.events) > maxEvents {
+			s.events = s.events[len(s.events)-maxEvents:]
+		}
+	}
+	for sub := range s.subscribers {
+		if isDelta && !sub.deltas {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			s.unsubscribe(sub)
+		}
+	}
+}
+
+// close ends the stream once its job finished.
+func (s *stream) close() {
+	s.closed = true
+	for sub := range s.subscribers {
+		s.unsubscribe(sub)
+	}
+}
+
+func (s *stream) unsubscribe(sub *subscriber) {
+	if s.subscribers[sub] {
+		delete(s.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// Subscribe returns the kept events of a job after the event numbered after,
+// and a channel of the events that follow, closed when the job finishes;
+// deltas are only sent when asked for. Call cancel once done following the job.
+func (q *Queue) Subscribe(id string, after int, deltas bool) (events []Event, ch <-chan Event, cancel func(), ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	s, ok := q.streams[id]
+	if !ok {
+		return nil, nil, nil, false
+	}
+	for _, event := range s.events {
+		if event.ID > after {
+			events = append(events, event)
+		}
+	}
+	sub := &subscriber{ch: make(chan Event, subscriberBuffer), deltas: deltas}
+	if s.closed {
+		close(sub.ch)
+		return events, sub.ch, func() {}, true
+	}
+	s.subscribers[sub] = true
+	cancel = func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		s.unsubscribe(sub)
+	}
+	return events, sub.ch, cancel, true
+}
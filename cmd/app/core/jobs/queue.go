@@ -0,0 +1,409 @@
+// Package jobs runs API jobs in the background with a fixed number of
+// workers, and keeps their status for the job endpoints.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"bauer/internal/progress"
+)
+
+// Status of a job.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+
+	// StatusInterrupted is for jobs stopped by a shutdown of the server,
+	// which can be resumed
+	StatusInterrupted Status = "interrupted"
+)
+
+// maxFinishedJobs is how many finished jobs are kept; the oldest are dropped.
+const maxFinishedJobs = 500
+
+var (
+	// ErrQueueFull is returned by Submit when the queue can't take another job.
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrDraining is returned by Submit once the queue is drained.
+	ErrDraining = errors.New("server is shutting down")
+	// ErrInterrupted is returned by the RunFunc of jobs stopped by a drain,
+	// so they are marked interrupted rather than failed.
+	ErrInterrupted = errors.New("job interrupted by a shutdown of the server")
+)
+
+// Job is a snapshot of a job's status.
+type Job struct {
+	ID     string `json:"id"`
+	DocID  string `json:"doc_id"`
+	Status Status `json:"status"`
+
+	// SubmittedBy is the principal that submitted the job, when the API
+	// requires authentication
+	SubmittedBy string `json:"submitted_by,omitempty"`
+
+	// Request is what the job was submitted with
+	Request any `json:"request,omitempty"`
+
+	// Stage is the stage of the run the job is in, or was in when it finished
+	Stage  string        `json:"stage,omitempty"`
+	Stages []StageTiming `json:"stages,omitempty"`
+	Error  string        `json:"error,omitempty"`
+	Result any           `json:"result,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// Duration is the time the job has been running, or ran
+	Duration string `json:"duration,omitempty"`
+}
+
+// StageTiming is when a job entered a stage, and how long the stage took.
+type StageTiming struct {
+	Stage     string    `json:"stage"`
+	StartedAt time.Time `json:"started_at"`
+	Duration  string    `json:"duration,omitempty"`
+}
+
+// RunFunc runs a job and returns a summary of the result, kept with the job.
+// The progress it reports to ctx is streamed to the job's subscribers.
+type RunFunc func(ctx context.Context) (any, error)
+
+// Queue runs submitted jobs with a fixed number of workers, in the order they
+// were submitted.
+type Queue struct {
+	// OnChange, when set, is called with each new status and stage of a
+	// job, in order, e.g. to store it. It is called by a writer of its own,
+	// so a slow store doesn't hold up the queue.
+	OnChange func(Job)
+
+	pending chan pendingJob
+
+	// stopping is closed when the queue is drained
+	stopping chan struct{}
+	running  sync.WaitGroup
+	cancel   context.CancelFunc
+
+	mu       sync.Mutex
+	draining bool
+	jobs     map[string]*Job
+	streams  map[string]*stream
+
+	// approvals are the jobs waiting for their plan to be approved
+	approvals map[string]chan Decision
+
+	// changes are the snapshots waiting for OnChange; writing is set while
+	// the writer calls it, and written signalled when it stops
+	changeMu sync.Mutex
+	changes  []Job
+	writing  bool
+	written  *sync.Cond
+}
+
+type pendingJob struct {
+	id  string
+	run RunFunc
+}
+
+// NewQueue returns a queue holding up to capacity jobs waiting for a worker.
+// Call Start to run them.
+func NewQueue(capacity int) *Queue {
+	q := &Queue{
+		pending:  make(chan pendingJob, max(capacity, 1)),
+		stopping: make(chan struct{}),
+		jobs:     make(map[string]*Job),
+		streams:  make(map[string]*stream),
+
+		approvals: make(map[string]chan Decision),
+	}
+	q.written = sync.NewCond(&q.changeMu)
+	return q
+}
+
+// Start starts workers that run jobs until ctx is done or the queue is
+// drained. Running jobs are cancelled with ctx.
+func (q *Queue) Start(ctx context.Context, workers int) {
+	ctx, cancel := context.WithCancel(ctx)
+	q.mu.Lock()
+	q.cancel = cancel
+	q.mu.Unlock()
+	for range max(workers, 1) {
+		go q.work(ctx)
+	}
+}
+
+// Stopping returns a channel closed when the queue is drained, for running
+// jobs to stop at their next checkpoint.
+func (q *Queue) Stopping() <-chan struct{} {
+	return q.stopping
+}
+
+// Drain stops the queue taking and starting jobs, and signals running jobs to
+// stop (see Stopping). Jobs still queued are marked interrupted.
+func (q *Queue) Drain() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.draining {
+		return
+	}
+	q.draining = true
+	close(q.stopping)
+	for _, job := range q.jobs {
+		if job.Status == StatusQueued {
+			q.finish(job, ErrInterrupted)
+		}
+	}
+}
+
+// Wait waits for the running jobs to finish after a drain, and for their
+// changes to be passed to OnChange. When ctx is done first, the jobs are
+// cancelled, waited for, and ctx's error is returned.
+func (q *Queue) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		q.running.Wait()
+		q.flushChanges()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+	q.mu.Lock()
+	if q.cancel != nil {
+		q.cancel()
+	}
+	q.mu.Unlock()
+	<-done
+	return ctx.Err()
+}
+
+// Submit queues a job run by run. The ID, document, submitter and request
+// of spec are kept with the job; the queue sets the rest.
+func (q *Queue) Submit(spec Job, run RunFunc) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.draining {
+		return Job{}, ErrDraining
+	}
+	job := &Job{
+		ID:          spec.ID,
+		DocID:       spec.DocID,
+		SubmittedBy: spec.SubmittedBy,
+		Request:     spec.Request,
+		Status:      StatusQueued,
+		CreatedAt:   time.Now().UTC(),
+	}
+	select {
+	case q.pending <- pendingJob{id: job.ID, run: run}:
+	default:
+		return Job{}, ErrQueueFull
+	}
+	q.jobs[job.ID] = job
+	q.streams[job.ID] = newStream()
+	q.streams[job.ID].publish(Event{Status: StatusQueued, Event: progress.Event{Type: EventStatus}})
+	q.changed(job)
+	q.prune()
+	return job.snapshot(), nil
+}
+
+// Get returns a job by ID.
+func (q *Queue) Get(id string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return job.snapshot(), true
+}
+
+// List returns all jobs, most recent first.
+func (q *Queue) List() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]Job, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		jobs = append(jobs, job.snapshot())
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.stopping:
+			return
+		case pending := <-q.pending:
+			q.execute(ctx, pending.id, pending.run)
+		}
+	}
+}
+
+func (q *Queue) execute(ctx context.Context, id string, run RunFunc) {
+	q.mu.Lock()
+	job, ok := q.jobs[id]
+	// Jobs queued when the queue was drained are already interrupted
+	if !ok || job.Status != StatusQueued {
+		q.mu.Unlock()
+		return
+	}
+	q.running.Add(1)
+	defer q.running.Done()
+	stream := q.streams[id]
+	now := time.Now().UTC()
+	job.Status = StatusRunning
+	job.StartedAt = &now
+	stream.publish(Event{Status: StatusRunning, Event: progress.Event{Type: EventStatus}})
+	q.changed(job)
+	q.mu.Unlock()
+
+	slog.Info("job started", "requestID", id)
+	ctx = progress.WithReporter(ctx, func(event progress.Event) {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		stream.publish(Event{Event: event})
+		if event.Type == progress.EventStage {
+			job.setStage(event.Stage, time.Now().UTC())
+			q.changed(job)
+		}
+	})
+	result, err := run(ctx)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job.Result = result
+	q.finish(job, err)
+}
+
+// finish records that a job finished with err, and closes its stream; q.mu
+// must be held.
+func (q *Queue) finish(job *Job, err error) {
+	stream := q.streams[job.ID]
+	defer stream.close()
+	finished := time.Now().UTC()
+	job.FinishedAt = &finished
+	job.closeStage(finished)
+	switch {
+	case errors.Is(err, ErrInterrupted):
+		job.Status = StatusInterrupted
+		job.Error = err.Error()
+		slog.Warn("job interrupted", "error", err.Error(), "requestID", job.ID)
+	case err != nil:
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		slog.Error("job execution failed", "error", err.Error(), "requestID", job.ID)
+	default:
+		job.Status = StatusSucceeded
+		slog.Info("job executed successfully", "requestID", job.ID)
+	}
+	stream.publish(Event{Status: job.Status, Error: job.Error, Event: progress.Event{Type: EventStatus}})
+	q.changed(job)
+}
+
+// changed queues a snapshot of job for OnChange, starting the writer when it
+// isn't running; q.mu must be held, which keeps the snapshots in order.
+func (q *Queue) changed(job *Job) {
+	if q.OnChange == nil {
+		return
+	}
+	q.changeMu.Lock()
+	defer q.changeMu.Unlock()
+	q.changes = append(q.changes, job.snapshot())
+	if !q.writing {
+		q.writing = true
+		go q.writeChanges()
+	}
+}
+
+// writeChanges calls OnChange with the queued snapshots, in order, until
+// there are none left.
+func (q *Queue) writeChanges() {
+	q.changeMu.Lock()
+	defer q.changeMu.Unlock()
+	for len(q.changes) > 0 {
+		changes := q.changes
+		q.changes = nil
+		q.changeMu.Unlock()
+		for _, job := range changes {
+			q.OnChange(job)
+		}
+		q.changeMu.Lock()
+	}
+	q.writing = false
+	q.written.Broadcast()
+}
+
+// flushChanges waits for the queued snapshots to be passed to OnChange.
+func (q *Queue) flushChanges() {
+	q.changeMu.Lock()
+	defer q.changeMu.Unlock()
+	for q.writing {
+		q.written.Wait()
+	}
+}
+
+// prune drops the oldest finished jobs beyond maxFinishedJobs.
+func (q *Queue) prune() {
+	var finished []*Job
+	for _, job := range q.jobs {
+		if job.FinishedAt != nil {
+			finished = append(finished, job)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].FinishedAt.Before(*finished[j].FinishedAt)
+	})
+	for _, job := range finished[:len(finished)-maxFinishedJobs] {
+		delete(q.jobs, job.ID)
+		delete(q.streams, job.ID)
+	}
+}
+
+func (j *Job) setStage(stage string, now time.Time) {
+	j.closeStage(now)
+	j.Stage = stage
+	j.Stages = append(j.Stages, StageTiming{Stage: stage, StartedAt: now})
+}
+
+// closeStage records the duration of the current stage.
+func (j *Job) closeStage(now time.Time) {
+	if n := len(j.Stages); n > 0 && j.Stages[n-1].Duration == "" {
+		j.Stages[n-1].Duration = now.Sub(j.Stages[n-1].StartedAt).Round(time.Millisecond).String()
+	}
+}
+
+// snapshot copies the job, with its duration so far.
+func (j *Job) snapshot() Job {
+	out := *j
+	out.Stages = append([]StageTiming(nil), j.Stages...)
+	if j.StartedAt != nil {
+		end := time.Now().UTC()
+		if j.FinishedAt != nil {
+			end = *j.FinishedAt
+		}
+		out.Duration = end.Sub(*j.StartedAt).Round(time.Millisecond).String()
+	}
+	return out
+}
@@ -0,0 +1,283 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bauer/internal/progress"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// waitFor polls the queue until the job has the given status.
+func waitFor(t *testing.T, q *Queue, id string, status Status) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, ok := q.Get(id); ok && job.Status == status {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	job, _ := q.Get(id)
+	t.Fatalf("job %s is %s, want %s", id, job.Status, status)
+	return job
+}
+
+func TestQueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := NewQueue(1)
+	var changes []string
+	q.OnChange = func(job Job) {
+		if job.ID == "a" {
+			changes = append(changes, string(job.Status)+" "+job.Stage)
+		}
+	}
+
+	release := make(chan struct{})
+	_, err := q.Submit(Job{ID: "a", DocID: "doc-a"}, func(ctx context.Context) (any, error) {
+		progress.Report(ctx, progress.Event{Type: progress.EventStage, Stage: "extraction"})
+		<-release
+		progress.Report(ctx, progress.Event{Type: progress.EventStage, Stage: "execution"})
+		return "done", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Submit(Job{ID: "b", DocID: "doc-b"}, func(ctx context.Context) (any, error) {
+		return nil, errors.New("failed to process document")
+	}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Submit() to a full queue error = %v, want ErrQueueFull", err)
+	}
+
+	q.Start(ctx, 1)
+	job := waitFor(t, q, "a", StatusRunning)
+	if job.StartedAt == nil {
+		t.Error("running job has no start time")
+	}
+	if _, err := q.Submit(Job{ID: "b", DocID: "doc-b"}, func(ctx context.Context) (any, error) {
+		return nil, errors.New("failed to process document")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+
+	job = waitFor(t, q, "a", StatusSucceeded)
+	var stages []string
+	for _, stage := range job.Stages {
+		stages = append(stages, stage.Stage)
+		if stage.Duration == "" {
+			t.Errorf("stage %s has no duration", stage.Stage)
+		}
+	}
+	if diff := cmp.Diff([]string{"extraction", "execution"}, stages); diff != "" {
+		t.Errorf("stages mismatch (-want +got):\n%s", diff)
+	}
+	if job.Stage != "execution" || job.Result != "done" || job.FinishedAt == nil {
+		t.Errorf("finished job = %+v", job)
+	}
+
+	q.flushChanges()
+	wantChanges := []string{"queued ", "running ", "running extraction", "running execution", "succeeded execution"}
+	if diff := cmp.Diff(wantChanges, changes); diff != "" {
+		t.Errorf("OnChange mismatch (-want +got):\n%s", diff)
+	}
+
+	failed := waitFor(t, q, "b", StatusFailed)
+	if failed.Error != "failed to process document" {
+		t.Errorf("Error = %q", failed.Error)
+	}
+
+	var ids []string
+	for _, job := range q.List() {
+		ids = append(ids, job.ID)
+	}
+	if diff := cmp.Diff([]string{"b", "a"}, ids); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+	if _, ok := q.Get("missing"); ok {
+		t.Error("Get() found a job that was never submitted")
+	}
+}
+
+func TestQueueSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := NewQueue(1)
+
+	release := make(chan struct{})
+	if _, err := q.Submit(Job{ID: "a", DocID: "doc-a"}, func(ctx context.Context) (any, error) {
+		<-release
+		progress.Report(ctx, progress.Event{Type: progress.EventChunkStarted, Chunk: 1, TotalChunks: 2})
+		progress.Report(ctx, progress.Event{Type: progress.EventDelta, Chunk: 1, Text: "Updating"})
+		progress.Report(ctx, progress.Event{Type: progress.EventChunkCompleted, Chunk: 1, TotalChunks: 2})
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	describe := func(event Event) string {
+		if event.Type == EventStatus {
+			return "status " + string(event.Status)
+		}
+		return event.Type
+	}
+
+	history, events, stop, ok := q.Subscribe("a", 0, false)
+	if !ok {
+		t.Fatal("Subscribe() found no job")
+	}
+	defer stop()
+	_, deltaEvents, stopDeltas, _ := q.Subscribe("a", 0, true)
+	defer stopDeltas()
+
+	q.Start(ctx, 1)
+	close(release)
+
+	got := []string{}
+	for _, event := range history {
+		got = append(got, describe(event))
+	}
+	for event := range events {
+		got = append(got, describe(event))
+	}
+	want := []string{"status queued", "status running", "chunk_started", "chunk_completed", "status succeeded"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+
+	var deltas []string
+	for event := range deltaEvents {
+		if event.Type == progress.EventDelta {
+			deltas = append(deltas, event.Text)
+		}
+	}
+	if diff := cmp.Diff([]string{"Updating"}, deltas); diff != "" {
+		t.Errorf("deltas mismatch (-want +got):\n%s", diff)
+	}
+
+	// A late subscriber resuming after the second event gets the rest, without deltas
+	history, events, _, _ = q.Subscribe("a", 2, false)
+	got = []string{}
+	for _, event := range history {
+		got = append(got, describe(event))
+	}
+	if _, open := <-events; open {
+		t.Error("events of a finished job are still open")
+	}
+	if diff := cmp.Diff(want[2:], got); diff != "" {
+		t.Errorf("resumed events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestQueueDrain(t *testing.T) {
+	q := NewQueue(2)
+	q.Start(context.Background(), 1)
+
+	// Job a checkpoints when the queue is drained; b never gets a worker
+	if _, err := q.Submit(Job{ID: "a", DocID: "doc-a"}, func(ctx context.Context) (any, error) {
+		<-q.Stopping()
+		return nil, ErrInterrupted
+	}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, q, "a", StatusRunning)
+	if _, err := q.Submit(Job{ID: "b", DocID: "doc-b"}, func(ctx context.Context) (any, error) {
+		return "done", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	q.Drain()
+	if job, _ := q.Get("b"); job.Status != StatusInterrupted {
+		t.Errorf("queued job status after Drain() = %s, want %s", job.Status, StatusInterrupted)
+	}
+	if _, err := q.Submit(Job{ID: "c", DocID: "doc-c"}, nil); !errors.Is(err, ErrDraining) {
+		t.Errorf("Submit() after Drain() error = %v, want ErrDraining", err)
+	}
+	if err := q.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if job, _ := q.Get("a"); job.Status != StatusInterrupted {
+		t.Errorf("running job status after Wait() = %s, want %s", job.Status, StatusInterrupted)
+	}
+}
+
+func TestQueueWaitTimeout(t *testing.T) {
+	q := NewQueue(1)
+	q.Start(context.Background(), 1)
+	if _, err := q.Submit(Job{ID: "a", DocID: "doc-a"}, func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, q, "a", StatusRunning)
+
+	q.Drain()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want context.DeadlineExceeded", err)
+	}
+	if job, _ := q.Get("a"); job.Status != StatusFailed {
+		t.Errorf("cancelled job status = %s, want %s", job.Status, StatusFailed)
+	}
+}
+
+func TestQueueApproval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := NewQueue(2)
+
+	if err := q.Decide("a", Decision{Approved: true}); !errors.Is(err, ErrNotAwaitingApproval) {
+		t.Fatalf("Decide() before the job waits error = %v, want ErrNotAwaitingApproval", err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if _, err := q.Submit(Job{ID: id, DocID: "doc-" + id}, func(ctx context.Context) (any, error) {
+			progress.Report(ctx, progress.Event{Type: progress.EventStage, Stage: "approval"})
+			decision, err := q.AwaitApproval(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if !decision.Approved {
+				return nil, errors.New("plan rejected: " + decision.Reason)
+			}
+			return "done", nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q.Start(ctx, 2)
+
+	// The job waits until it is decided
+	decide := func(id string, decision Decision) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			err := q.Decide(id, decision)
+			if err == nil {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Decide(%s) error = %v", id, err)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	decide("a", Decision{Approved: true})
+	decide("b", Decision{Reason: "wrong page"})
+
+	if job := waitFor(t, q, "a", StatusSucceeded); job.Result != "done" {
+		t.Errorf("approved job result = %v", job.Result)
+	}
+	if job := waitFor(t, q, "b", StatusFailed); job.Error != "plan rejected: wrong page" {
+		t.Errorf("rejected job error = %q", job.Error)
+	}
+	if err := q.Decide("a", Decision{Approved: true}); !errors.Is(err, ErrNotAwaitingApproval) {
+		t.Errorf("Decide() of a finished job error = %v, want ErrNotAwaitingApproval", err)
+	}
+}
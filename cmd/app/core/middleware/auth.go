@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"bauer/cmd/app/core/auth"
+	"bauer/cmd/app/types"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Authenticate rejects requests without a valid API key or bearer token, and
+// passes the principal of the others on in their context. Keys are sent as
+// "Authorization: Bearer <key>" or "X-API-Key: <key>".
+func Authenticate(a *auth.Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			token = r.Header.Get("X-API-Key")
+		}
+		principal, err := a.Authenticate(r.Context(), strings.TrimSpace(token))
+		if err != nil {
+			requestID, _ := r.Context().Value("requestID").(string)
+			slog.Warn("authentication failed",
+				"error", err.Error(),
+				"remote_addr", r.RemoteAddr,
+				"path", r.URL.Path,
+				"requestID", requestID,
+			)
+			// Don't tell callers why their token was rejected
+			reason := auth.ErrInvalidCredentials
+			if errors.Is(err, auth.ErrNoCredentials) {
+				reason = auth.ErrNoCredentials
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="bauer"`)
+			if err := types.Unauthorized(reason).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error())
+			}
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
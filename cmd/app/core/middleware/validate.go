@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"bauer/cmd/app/types"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// ValidateRequests rejects requests whose parameters or body don't match
+// the operation the OpenAPI document describes for them with 400 Bad
+// Request. Requests for paths or methods the document doesn't describe are
+// passed on as they are. Credentials are left to Authenticate.
+func ValidateRequests(doc *openapi3.T, next http.Handler) (http.Handler, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to route OpenAPI operations: %w", err)
+	}
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		// The handlers apply their own defaults
+		SkipSettingDefaults: true,
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, params, err := router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: params,
+			Route:      route,
+			Options:    options,
+		})
+		if err != nil {
+			requestID, _ := r.Context().Value("requestID").(string)
+			slog.Warn("invalid request", "error", err.Error(), "path", r.URL.Path, "requestID", requestID)
+			if err := types.BadRequest(err).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error())
+			}
+			return
+		}
+		next.ServeHTTP(w, r)
+	}), nil
+}
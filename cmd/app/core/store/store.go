@@ -0,0 +1,338 @@
+// Package store keeps the record of the API's runs, jobs and workflows, in
+// SQLite or Postgres, so they outlive the server.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Kinds of runs.
+const (
+	KindJob      = "job"
+	KindWorkflow = "workflow"
+)
+
+// defaultListLimit is the number of runs listed when no limit is given.
+const defaultListLimit = 100
+
+// ErrNotFound is returned for runs that aren't in the store.
+var ErrNotFound = errors.New("run not found")
+
+// Run is the record of a job or workflow run.
+type Run struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	DocID  string `json:"doc_id"`
+	Status string `json:"status"`
+	Stage  string `json:"stage,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	// SubmittedBy is the principal that submitted the run, when the API
+	// requires authentication
+	SubmittedBy string `json:"submitted_by,omitempty"`
+
+	// Request is the request the run was submitted with, without secrets
+	Request json.RawMessage `json:"request,omitempty"`
+
+	// Result is the job result or workflow output
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Chunks are the Copilot outputs of the chunks; they are left out of lists
+	Chunks json.RawMessage `json:"chunks,omitempty"`
+
+	Stages json.RawMessage `json:"stages,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Filter selects the runs to list. Empty fields match all runs.
+type Filter struct {
+	Kind   string
+	Status string
+	DocID  string
+
+	// SubmittedBy keeps the runs of a principal
+	SubmittedBy string
+
+	// DocIDs, when set, keeps the runs of these documents only
+	DocIDs []string
+
+	// Since keeps the runs created at or after it
+	Since time.Time
+
+	Limit  int
+	Offset int
+}
+
+// Store is a database of runs.
+type Store struct {
+	db       *sql.DB
+	postgres bool
+}
+
+// Open opens the store at dsn: a postgres:// or postgresql:// URL, or else the
+// path of a SQLite database, created if missing. The schema is created when
+// the database has none.
+func Open(dsn string) (*Store, error) {
+	s := &Store{postgres: strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")}
+	driver := "sqlite3"
+	if s.postgres {
+		driver = "postgres"
+	} else {
+		dsn = strings.TrimPrefix(dsn, "sqlite://")
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job store: %w", err)
+	}
+	if !s.postgres {
+		// SQLite allows a single writer
+		db.SetMaxOpenConns(1)
+	}
+	s.db = db
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) migrate() error {
+	timestamp, document := "TIMESTAMP", "TEXT"
+	if s.postgres {
+		timestamp, document = "TIMESTAMPTZ", "JSONB"
+	}
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS runs (
+	id TEXT PRIMARY KEY,
+	kind TEXT NOT NULL,
+	doc_id TEXT NOT NULL,
+	status TEXT NOT NULL,
+	stage TEXT NOT NULL DEFAULT '',
+	error TEXT NOT NULL DEFAULT '',
+	submitted_by TEXT NOT NULL DEFAULT '',
+	request %[2]s,
+	result %[2]s,
+	chunks %[2]s,
+	stages %[2]s,
+	created_at %[1]s NOT NULL,
+	started_at %[1]s,
+	finished_at %[1]s
+)`, timestamp, document),
+		`CREATE INDEX IF NOT EXISTS runs_created_at ON runs (created_at)`,
+		`CREATE INDEX IF NOT EXISTS runs_doc_id ON runs (doc_id)`,
+	}
+	for _, stmt := range statements {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to create job store schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// Save inserts or updates a run. Empty request, result, chunks and stages
+// keep the ones saved before.
+func (s *Store) Save(ctx context.Context, run *Run) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`INSERT INTO runs
+	(id, kind, doc_id, status, stage, error, submitted_by, request, result, chunks, stages, created_at, started_at, finished_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (id) DO UPDATE SET
+	kind = excluded.kind,
+	doc_id = excluded.doc_id,
+	status = excluded.status,
+	stage = excluded.stage,
+	error = excluded.error,
+	submitted_by = excluded.submitted_by,
+	request = COALESCE(excluded.request, runs.request),
+	result = COALESCE(excluded.result, runs.result),
+	chunks = COALESCE(excluded.chunks, runs.chunks),
+	stages = COALESCE(excluded.stages, runs.stages),
+	started_at = COALESCE(excluded.started_at, runs.started_at),
+	finished_at = excluded.finished_at`),
+		run.ID, run.Kind, run.DocID, run.Status, run.Stage, run.Error, run.SubmittedBy,
+		document(run.Request), document(run.Result), document(run.Chunks), document(run.Stages),
+		run.CreatedAt.UTC(), timestamp(run.StartedAt), timestamp(run.FinishedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save run %s: %w", run.ID, err)
+	}
+	return nil
+}
+
+// Get returns a run with its chunks.
+func (s *Store) Get(ctx context.Context, id string) (*Run, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT
+	id, kind, doc_id, status, stage, error, submitted_by, request, result, chunks, stages, created_at, started_at, finished_at
+	FROM runs WHERE id = ?`), id)
+	run, err := scanRun(row, true)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run %s: %w", id, err)
+	}
+	return run, nil
+}
+
+// List returns the runs matching filter, most recent first, without chunks.
+func (s *Store) List(ctx context.Context, filter Filter) ([]Run, error) {
+	query := `SELECT id, kind, doc_id, status, stage, error, submitted_by, request, result, NULL, stages, created_at, started_at, finished_at FROM runs WHERE 1 = 1`
+	var args []any
+	for _, cond := range []struct {
+		column string
+		value  string
+	}{
+		{"kind", filter.Kind},
+		{"status", filter.Status},
+		{"doc_id", filter.DocID},
+		{"submitted_by", filter.SubmittedBy},
+	} {
+		if cond.value != "" {
+			query += " AND " + cond.column + " = ?"
+			args = append(args, cond.value)
+		}
+	}
+	if filter.DocIDs != nil {
+		// No documents match nothing, as IN () isn't valid SQL
+		query += " AND doc_id IN (NULL" + strings.Repeat(", ?", len(filter.DocIDs)) + ")"
+		for _, id := range filter.DocIDs {
+			args = append(args, id)
+		}
+	}
+	if !filter.Since.IsZero() {
+		query += " AND created_at >= ?"
+		args = append(args, filter.Since.UTC())
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, max(filter.Offset, 0))
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	defer rows.Close()
+	runs := []Run{}
+	for rows.Next() {
+		run, err := scanRun(rows, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read run: %w", err)
+		}
+		runs = append(runs, *run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	return runs, nil
+}
+
+// DeleteFinishedBefore deletes the runs that finished before t and returns
+// how many there were.
+func (s *Store) DeleteFinishedBefore(ctx context.Context, t time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM runs WHERE finished_at IS NOT NULL AND finished_at < ?`), t.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old runs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// FailUnfinished marks the runs still queued or running as failed with
+// message, e.g. those of a server that stopped, and returns how many there were.
+func (s *Store) FailUnfinished(ctx context.Context, message string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, s.rebind(`UPDATE runs SET status = 'failed', error = ?, finished_at = ?
+	WHERE status IN ('queued', 'running')`), message, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to update unfinished runs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRun(row scanner, withChunks bool) (*Run, error) {
+	run := &Run{}
+	var request, result, chunks, stages sql.NullString
+	var startedAt, finishedAt sql.NullTime
+	err := row.Scan(&run.ID, &run.Kind, &run.DocID, &run.Status, &run.Stage, &run.Error, &run.SubmittedBy,
+		&request, &result, &chunks, &stages, &run.CreatedAt, &startedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	run.Request = rawJSON(request)
+	run.Result = rawJSON(result)
+	run.Stages = rawJSON(stages)
+	if withChunks {
+		run.Chunks = rawJSON(chunks)
+	}
+	run.CreatedAt = run.CreatedAt.UTC()
+	if startedAt.Valid {
+		t := startedAt.Time.UTC()
+		run.StartedAt = &t
+	}
+	if finishedAt.Valid {
+		t := finishedAt.Time.UTC()
+		run.FinishedAt = &t
+	}
+	return run, nil
+}
+
+// rebind rewrites ? placeholders as $1, $2... for Postgres.
+func (s *Store) rebind(query string) string {
+	if !s.postgres {
+		return query
+	}
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func document(raw json.RawMessage) any {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return string(raw)
+}
+
+func rawJSON(s sql.NullString) json.RawMessage {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	return json.RawMessage(s.String)
+}
+
+func timestamp(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	base := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+	finished := base.Add(time.Hour)
+	runs := []*Run{
+		{ID: "old", Kind: KindJob, DocID: "doc-a", Status: "succeeded", CreatedAt: base.Add(-48 * time.Hour), FinishedAt: &finished},
+		{ID: "job", Kind: KindJob, DocID: "doc-a", Status: "running", CreatedAt: base, Request: json.RawMessage(`{"doc_id":"doc-a"}`)},
+		{ID: "workflow", Kind: KindWorkflow, DocID: "doc-b", Status: "queued", SubmittedBy: "ci", CreatedAt: base.Add(time.Minute)},
+	}
+	for _, run := range runs {
+		if err := s.Save(ctx, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Updates keep the request saved before
+	done := base.Add(2 * time.Hour)
+	err = s.Save(ctx, &Run{
+		ID: "job", Kind: KindJob, DocID: "doc-a", Status: "succeeded", CreatedAt: base, FinishedAt: &done,
+		Result: json.RawMessage(`{"chunks":2}`),
+		Chunks: json.RawMessage(`[{"ChunkNumber":1}]`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Get(ctx, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Run{
+		ID: "job", Kind: KindJob, DocID: "doc-a", Status: "succeeded", CreatedAt: base, FinishedAt: &done,
+		Request: json.RawMessage(`{"doc_id":"doc-a"}`),
+		Result:  json.RawMessage(`{"chunks":2}`),
+		Chunks:  json.RawMessage(`[{"ChunkNumber":1}]`),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := s.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of a missing run error = %v, want ErrNotFound", err)
+	}
+
+	ids := func(filter Filter) []string {
+		t.Helper()
+		runs, err := s.List(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := []string{}
+		for _, run := range runs {
+			if run.Chunks != nil {
+				t.Errorf("List() returned the chunks of %s", run.ID)
+			}
+			out = append(out, run.ID)
+		}
+		return out
+	}
+	for _, tt := range []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all", Filter{}, []string{"workflow", "job", "old"}},
+		{"kind", Filter{Kind: KindJob}, []string{"job", "old"}},
+		{"doc and status", Filter{DocID: "doc-a", Status: "succeeded"}, []string{"job", "old"}},
+		{"since", Filter{Since: base}, []string{"workflow", "job"}},
+		{"page", Filter{Limit: 1, Offset: 1}, []string{"job"}},
+		{"submitter", Filter{SubmittedBy: "ci"}, []string{"workflow"}},
+		{"docs", Filter{DocIDs: []string{"doc-b", "doc-c"}}, []string{"workflow"}},
+		{"no docs", Filter{DocIDs: []string{}}, []string{}},
+	} {
+		if diff := cmp.Diff(tt.want, ids(tt.filter)); diff != "" {
+			t.Errorf("List(%s) mismatch (-want +got):\n%s", tt.name, diff)
+		}
+	}
+
+	if n, err := s.FailUnfinished(ctx, "interrupted"); err != nil || n != 1 {
+		t.Errorf("FailUnfinished() = %d, %v, want 1", n, err)
+	}
+	if n, err := s.DeleteFinishedBefore(ctx, base.Add(90*time.Minute)); err != nil || n != 1 {
+		t.Errorf("DeleteFinishedBefore() = %d, %v, want 1", n, err)
+	}
+	if diff := cmp.Diff([]string{"workflow", "job"}, ids(Filter{})); diff != "" {
+		t.Errorf("List() after deletion mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -1,8 +1,14 @@
 package models
 
+import (
+	"bauer/internal/copilotcli"
+	"bauer/internal/gdocs"
+	"bauer/internal/prompt"
+)
+
 type JobPost struct {
 	// DocID is the Google Doc ID to extract feedback from.
-	DocID string `json:"doc_id"`
+	DocID string `json:"doc_id" binding:"required"`
 
 	// ChunkSize is the total number of chunks to create from all locations.
 	// Default is 1 if not specified, or 5 if PageRefresh is true.
@@ -11,4 +17,50 @@ type JobPost struct {
 	// PageRefresh indicates if the page refresh mode should be used.
 	// When true, uses page-refresh-instructions.md template and defaults ChunkSize to 5.
 	PageRefresh bool `json:"page_refresh"`
+
+	// RequireApproval holds the job between planning and execution until
+	// its plan is approved with POST /api/v1/job/{id}/approve.
+	RequireApproval bool `json:"require_approval"`
+}
+
+// JobResult summarises the result of a job that ran.
+type JobResult struct {
+	// OutputDir is where the job wrote its prompts and reports.
+	OutputDir string `json:"output_dir"`
+
+	// Chunks is the number of chunks the suggestions were split into.
+	Chunks int `json:"chunks"`
+
+	Stats *gdocs.SuggestionStats `json:"stats,omitempty"`
+	Usage *copilotcli.Usage      `json:"usage,omitempty"`
+
+	// ChunkOutputs are the Copilot outputs of the chunks, kept in the job
+	// store rather than returned with the job.
+	ChunkOutputs []copilotcli.ChunkOutput `json:"-"`
+}
+
+// JobPreview is what a job would do, from extraction and planning alone.
+type JobPreview struct {
+	DocumentID    string `json:"document_id"`
+	DocumentTitle string `json:"document_title"`
+
+	GroupedSuggestions []gdocs.LocationGroupedSuggestions `json:"grouped_suggestions"`
+	ConflictReport     *gdocs.ConflictReport              `json:"conflict_report,omitempty"`
+	Stats              *gdocs.SuggestionStats             `json:"stats,omitempty"`
+
+	// Manifest lists the chunks the suggestions would be applied in
+	Manifest *prompt.Manifest `json:"manifest"`
+}
+
+// ApprovalPost is the optional body of approve and reject requests.
+type ApprovalPost struct {
+	// Reason is why the plan was approved or rejected, kept with the job's
+	// error when rejected.
+	Reason string `json:"reason"`
+}
+
+// ExtractPost is the body of an extraction request.
+type ExtractPost struct {
+	// DocID is the Google Doc ID to extract suggestions from.
+	DocID string `json:"doc_id" binding:"required"`
 }
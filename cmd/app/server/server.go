@@ -0,0 +1,195 @@
+// Package server runs the Bauer API server, for the bauer-api command and
+// `bauer serve`.
+package server
+
+import (
+	"bauer/cmd/app/core/auth"
+	"bauer/cmd/app/core/jobs"
+	"bauer/cmd/app/core/middleware"
+	"bauer/cmd/app/core/store"
+	"bauer/cmd/app/types"
+	v1 "bauer/cmd/app/v1"
+	"bauer/internal/logging"
+	"bauer/internal/orchestrator"
+	"bauer/internal/workflow"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Run starts the API server configured by the flags in args, and serves
+// until SIGINT or SIGTERM, then shuts down gracefully.
+func Run(args []string) error {
+	cfg, err := types.LoadConfig(args)
+	if err != nil {
+		slog.Error("failed to load config", "error", err.Error())
+		return err
+	}
+	closeLog, err := logging.Setup(cfg.Log, "api")
+	if err != nil {
+		slog.Error("failed to set up logging", "error", err.Error())
+		return err
+	}
+	defer closeLog()
+	slog.Info("startup", "status", "initializing API")
+	defer slog.Info("shutdown complete")
+
+	orch := orchestrator.NewOrchestrator()
+
+	queue := jobs.NewQueue(cfg.QueueSize)
+	rc := types.RouteConfig{
+		APIConfig:    *cfg,
+		Orchestrator: orch,
+		Jobs:         queue,
+	}
+	var recorder workflow.RunRecorder
+	if cfg.StoreDSN != "" {
+		st, err := store.Open(cfg.StoreDSN)
+		if err != nil {
+			slog.Error("failed to open job store", "error", err.Error())
+			return err
+		}
+		defer st.Close()
+		// Runs of a previous server that stopped will never finish
+		if n, err := st.FailUnfinished(context.Background(), "interrupted by a restart of the server"); err != nil {
+			slog.Error("failed to update unfinished jobs", "error", err.Error())
+		} else if n > 0 {
+			slog.Warn("jobs interrupted by a restart marked failed", "count", n)
+		}
+		if cfg.Retention > 0 {
+			go pruneRuns(st, cfg.Retention)
+		}
+		queue.OnChange = v1.RecordJobs(st)
+		rc.Store = st
+		recorder = v1.WorkflowRecorder{Store: st}
+		// Jobs stopped by the last shutdown continue from their last chunk
+		if n, err := v1.ResumeJobs(context.Background(), rc); err != nil {
+			slog.Error("failed to resume interrupted jobs", "error", err.Error())
+		} else if n > 0 {
+			slog.Info("interrupted jobs queued to resume", "count", n)
+		}
+	}
+
+	queue.Start(context.Background(), cfg.Workers)
+	slog.Info("job queue started", "workers", cfg.Workers, "queue_size", cfg.QueueSize, "store", cfg.StoreDSN != "")
+
+	api := http.NewServeMux()
+	api.HandleFunc("/api/v1/job", v1.JobPost(rc))
+	api.HandleFunc("POST /api/v1/job/preview", v1.JobPreview(rc))
+	api.HandleFunc("GET /api/v1/job/{id}", v1.JobGet(rc))
+	api.HandleFunc("GET /api/v1/job/{id}/events", v1.JobEvents(rc))
+	api.HandleFunc("POST /api/v1/job/{id}/approve", v1.JobApprove(rc))
+	api.HandleFunc("POST /api/v1/job/{id}/reject", v1.JobReject(rc))
+	api.HandleFunc("GET /api/v1/jobs", v1.JobList(rc))
+	api.HandleFunc("GET /api/v1/extract", v1.Extract(rc))
+	api.HandleFunc("POST /api/v1/extract", v1.Extract(rc))
+	api.HandleFunc("/api/v1/workflow", workflow.ExecuteWorkflowHandler(orch, recorder, v1.AuthorizeWorkflow))
+
+	spec, err := v1.Spec()
+	if err != nil {
+		slog.Error("failed to build OpenAPI document", "error", err.Error())
+		return err
+	}
+	validated, err := middleware.ValidateRequests(spec, api)
+	if err != nil {
+		slog.Error("failed to set up request validation", "error", err.Error())
+		return err
+	}
+
+	mux := http.NewServeMux()
+	// The health check and API description stay open, for load balancers,
+	// probes and client generators
+	mux.HandleFunc("/api/v1/health", v1.Health(v1.HealthChecker(*cfg)))
+	mux.HandleFunc("GET /api/v1/openapi.json", v1.OpenAPI(spec))
+	if cfg.AuthConfig != "" {
+		authCfg, err := auth.LoadConfig(cfg.AuthConfig)
+		if err != nil {
+			slog.Error("failed to load auth config", "error", err.Error())
+			return err
+		}
+		authenticator, err := auth.New(context.Background(), authCfg)
+		if err != nil {
+			slog.Error("failed to set up authentication", "error", err.Error())
+			return err
+		}
+		slog.Info("API authentication enabled", "api_keys", len(authCfg.APIKeys), "oidc", authCfg.OIDC != nil)
+		mux.Handle("/", middleware.Authenticate(authenticator, validated))
+	} else {
+		slog.Warn("API authentication disabled; anyone reaching the server can submit jobs")
+		mux.Handle("/", validated)
+	}
+	server := &http.Server{
+		Addr:    ":8090",
+		Handler: middleware.RequestTrace(mux),
+		// Workflow requests stop between chunks on shutdown, like jobs
+		BaseContext: func(net.Listener) context.Context {
+			return orchestrator.StopAfterChunk(context.Background(), queue.Stopping())
+		},
+	}
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("starting server", "address", server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		slog.Error("server error", "error", err.Error())
+		slog.Info("shutdown complete with errors")
+		return err
+	case <-signals.Done():
+	}
+	// A second signal kills the server
+	stop()
+	return shutdown(server, queue, cfg.ShutdownTimeout)
+}
+
+// shutdown drains the queue and the server: no new jobs are taken, queued
+// jobs are left for the next start and running jobs and requests stop after
+// their current chunk. Those still running after timeout are cancelled.
+func shutdown(server *http.Server, queue *jobs.Queue, timeout time.Duration) error {
+	slog.Info("shutting down", "timeout", timeout.String())
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	queue.Drain()
+	jobsErr := make(chan error, 1)
+	go func() {
+		jobsErr <- queue.Wait(ctx)
+	}()
+	// Event streams end with their jobs
+	serverErr := server.Shutdown(ctx)
+	if serverErr != nil {
+		slog.Warn("requests still open at shutdown timeout; closing them", "error", serverErr.Error())
+		server.Close()
+	}
+	if err := <-jobsErr; err != nil {
+		slog.Warn("jobs still running at shutdown timeout were cancelled", "error", err.Error())
+	}
+	if serverErr != nil && !errors.Is(serverErr, context.DeadlineExceeded) {
+		return serverErr
+	}
+	return nil
+}
+
+// pruneRuns deletes the runs that finished longer than retention ago from
+// the store, now and every hour.
+func pruneRuns(st *store.Store, retention time.Duration) {
+	for {
+		n, err := st.DeleteFinishedBefore(context.Background(), time.Now().Add(-retention))
+		if err != nil {
+			slog.Error("failed to delete old jobs", "error", err.Error())
+		} else if n > 0 {
+			slog.Info("old jobs deleted", "count", n, "retention", retention.String())
+		}
+		time.Sleep(time.Hour)
+	}
+}
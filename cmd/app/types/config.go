@@ -2,14 +2,23 @@ package types
 
 import (
 	"bauer/internal/config"
+	"bauer/internal/logging"
+	"bauer/internal/sites"
+	"cmp"
+	"errors"
 	"flag"
 	"os"
+	"time"
 )
 
 type APIConfig struct {
 	// CredentialsPath is the path to the Google Cloud service account JSON key file.
 	CredentialsPath string
 
+	// CredentialsMode selects where Google credentials come from: "file"
+	// (default, CredentialsPath), "env", "adc" or "workload-identity".
+	CredentialsMode string
+
 	// OutputDir is the directory where generated prompt files will be saved.
 	// Default is "bauer-output" if not specified.
 	BaseOutputDir string
@@ -24,17 +33,69 @@ type APIConfig struct {
 
 	// TargetRepo is the path (relative or absolute) to the target repository
 	// where tasks should be executed. If not specified, uses the current directory.
-	TargetRepo string `json:"target_repo"`}
+	TargetRepo string `json:"target_repo"`
+
+	// Sites map the pages of documents onto the templates serving them, as
+	// set in the config file.
+	Sites sites.Table `json:"sites"`
+
+	// Workers is the number of jobs run at the same time.
+	// Default is 1 if not specified.
+	Workers int
+
+	// QueueSize is the number of jobs that can wait for a worker.
+	// Default is 100 if not specified.
+	QueueSize int
+
+	// StoreDSN is where jobs and workflow runs are recorded: a SQLite
+	// database path, which needs a cgo build, or a postgres:// URL. Empty,
+	// the default, disables the job store.
+	StoreDSN string
+
+	// Retention is how long finished runs are kept in the job store; zero
+	// keeps them forever. Default is 30 days if not specified.
+	Retention time.Duration
+
+	// ApprovalWebhook, when set, is posted the plan of jobs waiting for
+	// approval, e.g. a Slack incoming webhook.
+	ApprovalWebhook string
+
+	// AuthConfig is the path to the JSON file of the API keys and OIDC
+	// principals allowed to call the API. Empty leaves the API open.
+	AuthConfig string
+
+	// Log configures the logs of the server; by default JSON at info level
+	// on stdout.
+	Log logging.Options
 
-func LoadConfig() (*APIConfig, error) {
-	credentialsPath := flag.String("credentials", "", "Path to service account JSON (required)")
-	baseOutputDir := flag.String("base-output-dir", "bauer-output", "Base path of directory for generated prompt files (default: bauer-output)")
-	model := flag.String("model", "gpt-5-mini-high", "Copilot model to use for sessions (default: gpt-5-mini-high)")
-	summaryModel := flag.String("summary-model", "gpt-5-mini-high", "Copilot model to use for summary session (default: gpt-5-mini-high)")
-	configFile := flag.String("config", "", "Path to JSON config file")
-	targetRepo := flag.String("target-repo", "", "Path to target repository where tasks should be executed (default: current directory)")
+	// ShutdownTimeout is how long running jobs and requests are waited for
+	// on SIGTERM or SIGINT before they are cancelled.
+	// Default is 10 minutes if not specified.
+	ShutdownTimeout time.Duration
+}
 
-	flag.Parse()
+// LoadConfig parses the flags of the API server from args.
+func LoadConfig(args []string) (*APIConfig, error) {
+	fs := flag.NewFlagSet("bauer-api", flag.ExitOnError)
+	credentialsPath := fs.String("credentials", "", "Path to service account JSON (required)")
+	credentialsMode := fs.String("credentials-mode", "file", "Where Google credentials come from: file, env, adc or workload-identity (default: file)")
+	baseOutputDir := fs.String("base-output-dir", "bauer-output", "Base path of directory for generated prompt files (default: bauer-output)")
+	model := fs.String("model", "gpt-5-mini-high", "Copilot model to use for sessions (default: gpt-5-mini-high)")
+	summaryModel := fs.String("summary-model", "gpt-5-mini-high", "Copilot model to use for summary session (default: gpt-5-mini-high)")
+	configFile := fs.String("config", "", "Path to JSON config file")
+	targetRepo := fs.String("target-repo", "", "Path to target repository where tasks should be executed (default: current directory)")
+	workers := fs.Int("workers", 1, "Number of jobs run at the same time (default: 1)")
+	queueSize := fs.Int("queue-size", 100, "Number of jobs that can wait for a worker (default: 100)")
+	storeDSN := fs.String("store", "", "SQLite database path (needs a cgo build) or postgres:// URL recording jobs, e.g. bauer-jobs.db (default: none, jobs are kept in memory)")
+	retention := fs.Duration("retention", 30*24*time.Hour, "How long finished jobs are kept in the job store; 0 keeps them forever (default: 720h)")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 10*time.Minute, "How long running jobs are waited for on shutdown before they are cancelled (default: 10m)")
+	logOptions := logging.Flags(fs, logging.Options{Level: "info", Format: logging.FormatJSON, Output: logging.OutputStdout})
+	approvalWebhook := fs.String("approval-webhook", "", "URL posted the plan of jobs waiting for approval, e.g. a Slack incoming webhook")
+	authConfig := fs.String("auth-config", "", "Path to JSON file of the API keys and OIDC principals allowed to call the API (default: no authentication)")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
 
 	if *configFile != "" {
 		cfg, err := config.LoadFromJSONFile(*configFile)
@@ -43,24 +104,43 @@ func LoadConfig() (*APIConfig, error) {
 		}
 		return &APIConfig{
 			CredentialsPath: cfg.CredentialsPath,
+			CredentialsMode: cfg.CredentialsMode,
 			BaseOutputDir:   cfg.OutputDir,
 			Model:           cfg.Model,
 			SummaryModel:    cfg.SummaryModel,
 			TargetRepo:      cfg.TargetRepo,
+			Sites:           cfg.Sites,
+			ApprovalWebhook: cmp.Or(*approvalWebhook, cfg.ApprovalWebhook),
+			Workers:         *workers,
+			QueueSize:       *queueSize,
+			StoreDSN:        *storeDSN,
+			Retention:       *retention,
+			AuthConfig:      *authConfig,
+			ShutdownTimeout: *shutdownTimeout,
+			Log:             *logOptions,
 		}, nil
 	}
 
-	if *credentialsPath == "" {
-		flag.Usage()
+	if *credentialsPath == "" && (*credentialsMode == "" || *credentialsMode == "file") {
+		fs.Usage()
 		os.Exit(1)
 	}
 
 	cfg := &APIConfig{
 		CredentialsPath: *credentialsPath,
+		CredentialsMode: *credentialsMode,
 		BaseOutputDir:   *baseOutputDir,
 		Model:           *model,
 		SummaryModel:    *summaryModel,
 		TargetRepo: 	 *targetRepo,
+		ApprovalWebhook: *approvalWebhook,
+		Workers:         *workers,
+		QueueSize:       *queueSize,
+		StoreDSN:        *storeDSN,
+		Retention:       *retention,
+		AuthConfig:      *authConfig,
+		ShutdownTimeout: *shutdownTimeout,
+		Log:             *logOptions,
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -71,5 +151,20 @@ func LoadConfig() (*APIConfig, error) {
 }
 
 func (c *APIConfig) Validate() error {
-	return config.ValidateCredentialsPath(c.CredentialsPath)
+	if c.Workers < 1 {
+		return errors.New("workers must be at least 1")
+	}
+	if c.QueueSize < 1 {
+		return errors.New("queue-size must be at least 1")
+	}
+	if c.Retention < 0 {
+		return errors.New("retention must not be negative")
+	}
+	if c.ShutdownTimeout < 0 {
+		return errors.New("shutdown-timeout must not be negative")
+	}
+	if err := c.Log.Validate(); err != nil {
+		return err
+	}
+	return config.ValidateCredentials(c.CredentialsMode, c.CredentialsPath)
 }
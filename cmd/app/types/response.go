@@ -8,6 +8,7 @@ import (
 type Response struct {
 	Code  int    `json:"code"`
 	Error string `json:"error,omitempty"`
+	JobID string `json:"job_id,omitempty"`
 }
 
 func Success() *Response {
@@ -18,6 +19,10 @@ func Accepted() *Response {
 	return &Response{Code: http.StatusAccepted, Error: ""}
 }
 
+func AcceptedJob(jobID string) *Response {
+	return &Response{Code: http.StatusAccepted, JobID: jobID}
+}
+
 func BadRequest(err error) *Response {
 	return &Response{Code: http.StatusBadRequest, Error: err.Error()}
 }
@@ -26,6 +31,10 @@ func NotAllowed(err error) *Response {
 	return &Response{Code: http.StatusMethodNotAllowed, Error: err.Error()}
 }
 
+func Unauthorized(err error) *Response {
+	return &Response{Code: http.StatusUnauthorized, Error: err.Error()}
+}
+
 func Forbidden(err error) *Response {
 	return &Response{Code: http.StatusForbidden, Error: err.Error()}
 }
@@ -38,8 +47,23 @@ func NotFound(err error) *Response {
 	return &Response{Code: http.StatusNotFound, Error: err.Error()}
 }
 
+func Conflict(err error) *Response {
+	return &Response{Code: http.StatusConflict, Error: err.Error()}
+}
+
+func Unavailable(err error) *Response {
+	return &Response{Code: http.StatusServiceUnavailable, Error: err.Error()}
+}
+
 func (r *Response) Render(w http.ResponseWriter, _ *http.Request) error {
 	w.WriteHeader(r.Code)
 	w.Header().Set("Content-Type", "application/json")
 	return json.NewEncoder(w).Encode(r)
 }
+
+// RenderJSON writes v as a JSON response with the given status code.
+func RenderJSON(w http.ResponseWriter, code int, v any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	return json.NewEncoder(w).Encode(v)
+}
@@ -1,10 +1,16 @@
 package types
 
 import (
+	"bauer/cmd/app/core/jobs"
+	"bauer/cmd/app/core/store"
 	"bauer/internal/orchestrator"
 )
 
 type RouteConfig struct {
 	APIConfig    APIConfig
 	Orchestrator orchestrator.Orchestrator
+	Jobs         *jobs.Queue
+
+	// Store records jobs and workflow runs; nil when disabled
+	Store *store.Store
 }
@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"bauer/cmd/app/core/auth"
+	"bauer/internal/github"
+	"bauer/internal/workflow"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// canRead reports whether the caller of r may see the runs of a document.
+// Without authentication, everyone may.
+func canRead(r *http.Request, docID string) bool {
+	p := auth.FromContext(r.Context())
+	return p == nil || p.CanUseDoc(docID)
+}
+
+// submitter returns the name of the caller of ctx, or "" without authentication.
+func submitter(ctx context.Context) string {
+	if p := auth.FromContext(ctx); p != nil {
+		return p.Name
+	}
+	return ""
+}
+
+// audit logs that the caller of ctx submitted a run, or was refused one.
+func audit(ctx context.Context, action, requestID, docID string, err error, attrs ...any) {
+	p := auth.FromContext(ctx)
+	if p == nil {
+		return
+	}
+	attrs = append([]any{
+		"action", action,
+		"principal", p.Name,
+		"method", p.Method,
+		"doc_id", docID,
+		"requestID", requestID,
+	}, attrs...)
+	if err != nil {
+		slog.Warn("audit: request denied", append(attrs, "error", err.Error())...)
+		return
+	}
+	slog.Info("audit: request accepted", attrs...)
+}
+
+// authorizeDoc checks that the caller of ctx may process a document.
+func authorizeDoc(ctx context.Context, docID string) error {
+	if p := auth.FromContext(ctx); p != nil && !p.CanUseDoc(docID) {
+		return fmt.Errorf("%s may not process document %s", p.Name, docID)
+	}
+	return nil
+}
+
+// AuthorizeWorkflow checks that the caller of ctx may run a workflow on the
+// document and repository of req, and logs the decision.
+func AuthorizeWorkflow(ctx context.Context, req workflow.APIRequest) error {
+	requestID, _ := ctx.Value("requestID").(string)
+	err := authorizeDoc(ctx, req.DocID)
+	if p := auth.FromContext(ctx); err == nil && p != nil {
+		repo, parseErr := github.ParseGitHubRepo(req.GitHubRepo, req.GitHubHost)
+		if parseErr != nil {
+			err = parseErr
+		} else if !p.CanUseRepo(repo.Owner, repo.Name) {
+			err = fmt.Errorf("%s may not run workflows on %s/%s", p.Name, repo.Owner, repo.Name)
+		}
+	}
+	audit(ctx, "workflow", requestID, req.DocID, err, "github_repo", req.GitHubRepo)
+	return err
+}
@@ -1,16 +1,29 @@
 package v1
 
 import (
+	"bauer/cmd/app/core/auth"
+	"bauer/cmd/app/core/jobs"
+	"bauer/cmd/app/core/store"
 	"bauer/cmd/app/models/v1"
 	"bauer/cmd/app/types"
 	"bauer/internal/config"
+	"bauer/internal/orchestrator"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
 )
 
+// eventsKeepAlive is how often an idle event stream is sent a comment, so
+// proxies don't close it.
+const eventsKeepAlive = 15 * time.Second
+
 func JobPost(rc types.RouteConfig) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		requestID, ok := r.Context().Value("requestID").(string)
@@ -32,19 +45,31 @@ func JobPost(rc types.RouteConfig) func(w http.ResponseWriter, r *http.Request)
 		if err != nil {
 			return
 		}
-		cfg := config.Config{
-			DocID:           payload.DocID,
-			ChunkSize:       payload.ChunkSize,
-			PageRefresh:     payload.PageRefresh,
-			CredentialsPath: rc.APIConfig.CredentialsPath,
-			OutputDir:       fmt.Sprintf("%s/%s", rc.APIConfig.BaseOutputDir, requestID),
-			Model:           rc.APIConfig.Model,
-			SummaryModel:    rc.APIConfig.SummaryModel,
+		if err := authorizeDoc(r.Context(), payload.DocID); err != nil {
+			audit(r.Context(), "job", requestID, payload.DocID, err)
+			if err := types.Forbidden(err).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error(), "requestID", requestID)
+			}
+			return
 		}
+		cfg := jobConfig(rc, requestID, payload)
 
-		go executeJob(requestID, cfg, rc)
+		spec := jobs.Job{ID: requestID, DocID: payload.DocID, SubmittedBy: submitter(r.Context()), Request: payload}
+		job, err := rc.Jobs.Submit(spec, runJob(requestID, cfg, rc, payload.RequireApproval))
+		if err != nil {
+			slog.Error("failed to queue job", "error", err.Error(), "requestID", requestID)
+			if errors.Is(err, jobs.ErrDraining) {
+				w.Header().Set("Retry-After", "60")
+			}
+			err := types.Unavailable(err).Render(w, r)
+			if err != nil {
+				slog.Error("error writing response", "error", err.Error(), "requestID", requestID)
+			}
+			return
+		}
+		audit(r.Context(), "job", requestID, payload.DocID, nil)
 
-		err = types.Accepted().Render(w, r)
+		err = types.AcceptedJob(job.ID).Render(w, r)
 		if err != nil {
 			slog.Error("error writing response", "error", err.Error(), "requestID", requestID)
 		}
@@ -65,30 +90,222 @@ func getJobFromRequest(w http.ResponseWriter, r *http.Request, requestID string)
 	return &payload, nil
 }
 
-func executeJob(requestID string, cfg config.Config, rc types.RouteConfig) {
-	ctx := context.Background()
-	ctx = context.WithValue(ctx, "requestID", requestID)
+// jobConfig returns the configuration a job runs with. Its output directory
+// is named after the job, for it to be resumed, and stays relative to the
+// server's directory while the job works in the target repository.
+func jobConfig(rc types.RouteConfig, requestID string, payload *models.JobPost) config.Config {
+	outputDir := filepath.Join(rc.APIConfig.BaseOutputDir, requestID)
+	if abs, err := filepath.Abs(outputDir); err == nil {
+		outputDir = abs
+	}
+	return config.Config{
+		DocID:           payload.DocID,
+		ChunkSize:       payload.ChunkSize,
+		PageRefresh:     payload.PageRefresh,
+		CredentialsPath: rc.APIConfig.CredentialsPath,
+		CredentialsMode: rc.APIConfig.CredentialsMode,
+		OutputDir:       outputDir,
+		Model:           rc.APIConfig.Model,
+		SummaryModel:    rc.APIConfig.SummaryModel,
+		WorkDir:         rc.APIConfig.TargetRepo,
+		TargetRepo:      rc.APIConfig.TargetRepo,
+		Sites:           rc.APIConfig.Sites,
+		ApprovalWebhook: rc.APIConfig.ApprovalWebhook,
+	}
+}
 
-	_, err := rc.Orchestrator.Execute(ctx, &cfg)
-	if err != nil {
-		slog.Error("job execution failed",
-			"error", err.Error(),
-			"requestID", requestID,
-		)
-		return
+// runJob returns the function a queue worker runs the job with. Once the
+// queue is drained, the job stops after the chunk it is executing. Jobs
+// requiring approval wait for it before executing their chunks.
+func runJob(requestID string, cfg config.Config, rc types.RouteConfig, requireApproval bool) jobs.RunFunc {
+	return func(ctx context.Context) (any, error) {
+		ctx = context.WithValue(ctx, "requestID", requestID)
+		ctx = orchestrator.StopAfterChunk(ctx, rc.Jobs.Stopping())
+		if requireApproval {
+			ctx = orchestrator.WithApprover(ctx, jobApprover{queue: rc.Jobs, id: requestID})
+		}
+
+		result, err := rc.Orchestrator.Execute(ctx, &cfg)
+		if errors.Is(err, orchestrator.ErrStopped) || errors.Is(err, context.Canceled) {
+			err = fmt.Errorf("%w: %w", jobs.ErrInterrupted, err)
+		}
+		if result == nil {
+			return nil, err
+		}
+		jobResult := &models.JobResult{
+			OutputDir:    cfg.OutputDir,
+			Chunks:       len(result.Chunks),
+			ChunkOutputs: result.CopilotOutputs,
+		}
+		if result.ExtractionResult != nil {
+			jobResult.Stats = result.ExtractionResult.Stats
+		}
+		if !result.Usage.IsZero() {
+			jobResult.Usage = &result.Usage
+		}
+		return jobResult, err
 	}
+}
 
-	slog.Info("job executed successfully",
-		"requestID", requestID,
-	)
+// JobGet reports the status of a job. Jobs the server no longer holds, e.g.
+// those of before a restart, and workflow runs are read from the job store.
+// Callers only see the jobs of the documents they may process.
+func JobGet(rc types.RouteConfig) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		var body any
+		if job, ok := rc.Jobs.Get(id); ok {
+			if canRead(r, job.DocID) {
+				body = job
+			}
+		} else if rc.Store != nil {
+			run, err := rc.Store.Get(r.Context(), id)
+			if err != nil && !errors.Is(err, store.ErrNotFound) {
+				slog.Error("failed to read job", "error", err.Error(), "requestID", id)
+				if err := types.InternalError(err).Render(w, r); err != nil {
+					slog.Error("error writing response", "error", err.Error())
+				}
+				return
+			}
+			if run != nil && canRead(r, run.DocID) {
+				body = run
+			}
+		}
+		if body == nil {
+			err := types.NotFound(fmt.Errorf("no job %s", id)).Render(w, r)
+			if err != nil {
+				slog.Error("error writing response", "error", err.Error())
+			}
+			return
+		}
+		if err := types.RenderJSON(w, http.StatusOK, body); err != nil {
+			slog.Error("error writing response", "error", err.Error())
+		}
+	}
 }
 
+// JobEvents streams the progress of a job as server-sent events until it
+// finishes. Clients reconnecting with Last-Event-ID get the events they
+// missed; Copilot output deltas are only sent with ?deltas=true.
+func JobEvents(rc types.RouteConfig) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		after, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+		job, ok := rc.Jobs.Get(id)
+		if !ok || !canRead(r, job.DocID) {
+			err := types.NotFound(fmt.Errorf("no job %s", id)).Render(w, r)
+			if err != nil {
+				slog.Error("error writing response", "error", err.Error())
+			}
+			return
+		}
+		history, events, cancel, ok := rc.Jobs.Subscribe(id, after, r.URL.Query().Get("deltas") == "true")
+		if !ok {
+			err := types.NotFound(fmt.Errorf("no job %s", id)).Render(w, r)
+			if err != nil {
+				slog.Error("error writing response", "error", err.Error())
+			}
+			return
+		}
+		defer cancel()
 
-func GetHealth(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	err := types.Success().Render(w, r)
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			err := types.InternalError(fmt.Errorf("streaming is not supported")).Render(w, r)
+			if err != nil {
+				slog.Error("error writing response", "error", err.Error())
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		for _, event := range history {
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(eventsKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event, open := <-events:
+				if !open {
+					return
+				}
+				if err := writeEvent(w, event); err != nil {
+					return
+				}
+			case <-keepAlive.C:
+				if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w io.Writer, event jobs.Event) error {
+	data, err := json.Marshal(event)
 	if err != nil {
-		slog.Error("error writing response", "error", err.Error())
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// JobList reports jobs and workflow runs, most recent first, from the job
+// store, filtered by the query (see runFilter). Without a store, it reports
+// the jobs the server holds. Callers only see the jobs of the documents they
+// may process.
+func JobList(rc types.RouteConfig) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if rc.Store == nil {
+			list := []jobs.Job{}
+			for _, job := range rc.Jobs.List() {
+				if canRead(r, job.DocID) {
+					list = append(list, job)
+				}
+			}
+			err := types.RenderJSON(w, http.StatusOK, struct {
+				Jobs []jobs.Job `json:"jobs"`
+			}{list})
+			if err != nil {
+				slog.Error("error writing response", "error", err.Error())
+			}
+			return
+		}
+
+		filter, err := runFilter(r)
+		if err != nil {
+			if err := types.BadRequest(err).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error())
+			}
+			return
+		}
+		if p := auth.FromContext(r.Context()); p != nil && len(p.AllowedDocs) > 0 {
+			filter.DocIDs = p.AllowedDocs
+		}
+		runs, err := rc.Store.List(r.Context(), filter)
+		if err != nil {
+			slog.Error("failed to list jobs", "error", err.Error())
+			if err := types.InternalError(err).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error())
+			}
+			return
+		}
+		err = types.RenderJSON(w, http.StatusOK, struct {
+			Jobs []store.Run `json:"jobs"`
+		}{runs})
+		if err != nil {
+			slog.Error("error writing response", "error", err.Error())
+		}
 	}
-}
\ No newline at end of file
+}
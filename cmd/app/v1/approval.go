@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"bauer/cmd/app/core/jobs"
+	"bauer/cmd/app/models/v1"
+	"bauer/cmd/app/types"
+	"bauer/internal/orchestrator"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// jobApprover holds a job submitted with require_approval between planning
+// and execution, until POST /api/v1/job/{id}/approve or reject.
+type jobApprover struct {
+	queue *jobs.Queue
+	id    string
+}
+
+func (a jobApprover) Instructions(request orchestrator.ApprovalRequest) string {
+	return fmt.Sprintf("Approve with POST /api/v1/job/%s/approve, or reject with POST /api/v1/job/%s/reject.", a.id, a.id)
+}
+
+func (a jobApprover) Await(ctx context.Context, request orchestrator.ApprovalRequest) error {
+	decision, err := a.queue.AwaitApproval(ctx, a.id)
+	if err != nil {
+		return err
+	}
+	if decision.Approved {
+		return nil
+	}
+	err = orchestrator.ErrRejected
+	if decision.By != "" {
+		err = fmt.Errorf("%w by %s", err, decision.By)
+	}
+	if decision.Reason != "" {
+		err = fmt.Errorf("%w: %s", err, decision.Reason)
+	}
+	return err
+}
+
+// JobApprove approves the plan of a job waiting for approval; it goes on to
+// execute its chunks.
+func JobApprove(rc types.RouteConfig) func(w http.ResponseWriter, r *http.Request) {
+	return decideJob(rc, true)
+}
+
+// JobReject rejects the plan of a job waiting for approval; it fails without
+// executing its chunks.
+func JobReject(rc types.RouteConfig) func(w http.ResponseWriter, r *http.Request) {
+	return decideJob(rc, false)
+}
+
+func decideJob(rc types.RouteConfig, approved bool) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		job, ok := rc.Jobs.Get(id)
+		if !ok || !canRead(r, job.DocID) {
+			if err := types.NotFound(fmt.Errorf("no job %s", id)).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error())
+			}
+			return
+		}
+		action := "approve"
+		if !approved {
+			action = "reject"
+		}
+		if err := authorizeDoc(r.Context(), job.DocID); err != nil {
+			audit(r.Context(), action, id, job.DocID, err)
+			if err := types.Forbidden(err).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error(), "requestID", id)
+			}
+			return
+		}
+
+		payload := models.ApprovalPost{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+			err := types.BadRequest(fmt.Errorf("invalid request body: %w", err)).Render(w, r)
+			if err != nil {
+				slog.Error("error writing response", "error", err.Error(), "requestID", id)
+			}
+			return
+		}
+		decision := jobs.Decision{Approved: approved, Reason: payload.Reason, By: submitter(r.Context())}
+		if err := rc.Jobs.Decide(id, decision); err != nil {
+			if err := types.Conflict(err).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error(), "requestID", id)
+			}
+			return
+		}
+		audit(r.Context(), action, id, job.DocID, nil)
+		slog.Info("job plan decided", "approved", approved, "reason", payload.Reason, "requestID", id)
+
+		if err := types.Success().Render(w, r); err != nil {
+			slog.Error("error writing response", "error", err.Error(), "requestID", id)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"bauer/cmd/app/models/v1"
+	"bauer/cmd/app/types"
+	"bauer/internal/docsource"
+	"bauer/internal/gdocs"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// Extract returns the suggestions, comments and metadata extracted from a
+// document, as a ProcessingResult, without planning or running a job. The
+// document is given by ?doc_id= or, for POST, by the request body.
+func Extract(rc types.RouteConfig) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID, _ := r.Context().Value("requestID").(string)
+		docID := r.URL.Query().Get("doc_id")
+		if docID == "" && r.Method == http.MethodPost {
+			payload := models.ExtractPost{}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				slog.Error("failed to decode request body", "error", err.Error(), "requestID", requestID)
+				if err := types.BadRequest(fmt.Errorf("invalid request body: %w", err)).Render(w, r); err != nil {
+					slog.Error("error writing response", "error", err.Error(), "requestID", requestID)
+				}
+				return
+			}
+			docID = payload.DocID
+		}
+		if docID == "" {
+			if err := types.BadRequest(errors.New("missing doc_id")).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error(), "requestID", requestID)
+			}
+			return
+		}
+		if err := authorizeDoc(r.Context(), docID); err != nil {
+			audit(r.Context(), "extract", requestID, docID, err)
+			if err := types.Forbidden(err).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error(), "requestID", requestID)
+			}
+			return
+		}
+		audit(r.Context(), "extract", requestID, docID, nil)
+
+		result, err := extract(r, rc, docID)
+		if err != nil {
+			slog.Error("failed to extract document", "error", err.Error(), "requestID", requestID)
+			if err := types.InternalError(err).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error(), "requestID", requestID)
+			}
+			return
+		}
+		if err := types.RenderJSON(w, http.StatusOK, result); err != nil {
+			slog.Error("error writing response", "error", err.Error(), "requestID", requestID)
+		}
+	}
+}
+
+// extract reads a document with the server's credentials and computes the
+// stats of its suggestions.
+func extract(r *http.Request, rc types.RouteConfig, docID string) (*gdocs.ProcessingResult, error) {
+	provider, err := docsource.Open(r.Context(), docsource.Options{
+		CredentialsMode: rc.APIConfig.CredentialsMode,
+		CredentialsPath: rc.APIConfig.CredentialsPath,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, err := provider.ProcessDocument(r.Context(), docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process document: %w", err)
+	}
+	gdocs.ComputeSuggestionStats(result, 0)
+	return result, nil
+}
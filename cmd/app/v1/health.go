@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"bauer/cmd/app/core/health"
+	"bauer/cmd/app/types"
+	"bauer/internal/gdocs"
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// healthTTL is how long the outcome of the health probes is reused.
+const healthTTL = 30 * time.Second
+
+// HealthChecker returns the checker of the dependencies of the jobs of the
+// server: its Google credentials, the gh CLI, the Copilot CLI, and the
+// output directory's writability and free space.
+func HealthChecker(cfg types.APIConfig) *health.Checker {
+	checker := health.NewChecker(healthTTL)
+	checker.Add("google_credentials", health.GoogleCredentials(gdocs.ClientOptions{
+		Mode:            gdocs.CredentialsMode(cfg.CredentialsMode),
+		CredentialsPath: cfg.CredentialsPath,
+	}))
+	checker.Add("github_cli", health.GitHubCLI(""))
+	checker.Add("copilot", health.Copilot(cfg.TargetRepo))
+	checker.Add("output_dir", health.Writable(cfg.BaseOutputDir))
+	checker.Add("disk_space", health.DiskSpace(cfg.BaseOutputDir, health.DefaultMinFreeBytes))
+	return checker
+}
+
+// Health reports the status of each dependency, with 503 Service Unavailable
+// when any of them failed, so the server isn't sent jobs it can't run.
+func Health(checker *health.Checker) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// The report is shared by the requests of its TTL, so it isn't cut
+		// short by the one that started it going away
+		report := checker.Run(context.WithoutCancel(r.Context()))
+		code := http.StatusOK
+		if report.Status != health.StatusOK {
+			code = http.StatusServiceUnavailable
+		}
+		if err := types.RenderJSON(w, code, report); err != nil {
+			slog.Error("error writing response", "error", err.Error())
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package v1
+
+import (
+	"bauer/cmd/app/core/health"
+	"bauer/cmd/app/core/jobs"
+	"bauer/cmd/app/models/v1"
+	"bauer/cmd/app/types"
+	"bauer/internal/gdocs"
+	"bauer/internal/workflow"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+)
+
+// APIVersion is the version of the API in its OpenAPI document.
+const APIVersion = "1.0.0"
+
+// Spec returns the OpenAPI document of the v1 API. The schemas of request and
+// response bodies are generated from the Go types the handlers use: fields
+// tagged binding:"required" are required and default tags give defaults.
+func Spec() (*openapi3.T, error) {
+	schemas := openapi3.Schemas{}
+	for name, value := range map[string]any{
+		"JobPost":          models.JobPost{},
+		"JobPreview":       models.JobPreview{},
+		"ExtractPost":      models.ExtractPost{},
+		"ApprovalPost":     models.ApprovalPost{},
+		"ProcessingResult": gdocs.ProcessingResult{},
+		"Job":              jobs.Job{},
+		"Event":            jobs.Event{},
+		"Response":         types.Response{},
+		"HealthReport":     health.Report{},
+		"WorkflowRequest":  workflow.APIRequest{},
+		"WorkflowResponse": workflow.APIResponse{},
+	} {
+		ref, err := openapi3gen.NewSchemaRefForValue(value, schemas, openapi3gen.SchemaCustomizer(customizeSchema))
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate %s schema: %w", name, err)
+		}
+		schemas[name] = ref
+	}
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       "Bauer API",
+			Version:     APIVersion,
+			Description: "Applies the suggestions of Google Docs to website repositories.",
+		},
+		Paths: openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: schemas,
+			SecuritySchemes: openapi3.SecuritySchemes{
+				"bearer": &openapi3.SecuritySchemeRef{Value: openapi3.NewJWTSecurityScheme().
+					WithDescription("An API key or an OIDC ID token, when the server requires authentication")},
+				"apiKey": &openapi3.SecuritySchemeRef{Value: openapi3.NewSecurityScheme().
+					WithType("apiKey").WithIn("header").WithName("X-API-Key")},
+			},
+		},
+		Security: *openapi3.NewSecurityRequirements().
+			With(openapi3.NewSecurityRequirement().Authenticate("bearer")).
+			With(openapi3.NewSecurityRequirement().Authenticate("apiKey")),
+	}
+
+	doc.AddOperation("/api/v1/job", http.MethodPost, operation("submitJob", "Queue a job for a document",
+		jsonBody("JobPost"),
+		response(http.StatusAccepted, "The job was queued", "Response"),
+		response(http.StatusServiceUnavailable, "The queue is full or the server is shutting down", "Response"),
+	))
+	doc.AddOperation("/api/v1/job/preview", http.MethodPost, operation("previewJob", "Extract and plan a job without running it",
+		jsonBody("JobPost"),
+		response(http.StatusOK, "What the job would do", "JobPreview"),
+	))
+	doc.AddOperation("/api/v1/job/{id}", http.MethodGet, operation("getJob", "Status of a job or workflow run",
+		pathID(),
+		response(http.StatusOK, "The job", "Job"),
+		response(http.StatusNotFound, "No such job", "Response"),
+	))
+	doc.AddOperation("/api/v1/job/{id}/events", http.MethodGet, operation("streamJobEvents", "Progress of a job as server-sent events",
+		pathID(),
+		queryParam("deltas", openapi3.NewBoolSchema(), "Include Copilot output deltas", false),
+		func(op *openapi3.Operation) {
+			op.AddParameter(openapi3.NewHeaderParameter("Last-Event-ID").
+				WithSchema(openapi3.NewIntegerSchema()).
+				WithDescription("Resume after this event"))
+			op.AddResponse(http.StatusOK, openapi3.NewResponse().
+				WithDescription("Events of type status, stage, chunk and delta, with an Event as data").
+				WithContent(openapi3.Content{"text/event-stream": openapi3.NewMediaType().WithSchemaRef(schemaRef("Event"))}))
+		},
+		response(http.StatusNotFound, "No such job", "Response"),
+	))
+	for _, decision := range []struct{ action, summary, description string }{
+		{"approve", "Approve the plan of a job waiting for approval", "The job executes its chunks"},
+		{"reject", "Reject the plan of a job waiting for approval", "The job fails without executing its chunks"},
+	} {
+		doc.AddOperation("/api/v1/job/{id}/"+decision.action, http.MethodPost, operation(decision.action+"Job", decision.summary,
+			pathID(),
+			func(op *openapi3.Operation) {
+				op.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+					WithJSONSchemaRef(schemaRef("ApprovalPost"))}
+			},
+			response(http.StatusOK, decision.description, "Response"),
+			response(http.StatusNotFound, "No such job", "Response"),
+			response(http.StatusConflict, "The job isn't waiting for approval", "Response"),
+		))
+	}
+	doc.AddOperation("/api/v1/jobs", http.MethodGet, operation("listJobs", "Jobs and workflow runs, most recent first",
+		queryParam("kind", openapi3.NewStringSchema().WithEnum("job", "workflow"), "", false),
+		queryParam("status", openapi3.NewStringSchema(), "", false),
+		queryParam("doc_id", openapi3.NewStringSchema(), "", false),
+		queryParam("submitted_by", openapi3.NewStringSchema(), "", false),
+		queryParam("since", openapi3.NewDateTimeSchema(), "Runs created at or after this time", false),
+		queryParam("limit", openapi3.NewIntegerSchema().WithMin(1), "", false),
+		queryParam("offset", openapi3.NewIntegerSchema().WithMin(0), "", false),
+		func(op *openapi3.Operation) {
+			list := openapi3.NewObjectSchema().WithProperty("jobs", openapi3.NewArraySchema().WithItems(schemas["Job"].Value))
+			op.AddResponse(http.StatusOK, openapi3.NewResponse().WithDescription("The runs").WithJSONSchema(list))
+		},
+	))
+	doc.AddOperation("/api/v1/extract", http.MethodGet, operation("extract", "Suggestions, comments and metadata of a document",
+		queryParam("doc_id", openapi3.NewStringSchema().WithMinLength(1), "", true),
+		response(http.StatusOK, "The extraction result", "ProcessingResult"),
+	))
+	doc.AddOperation("/api/v1/extract", http.MethodPost, operation("extractPost", "Suggestions, comments and metadata of a document",
+		jsonBody("ExtractPost"),
+		response(http.StatusOK, "The extraction result", "ProcessingResult"),
+	))
+	doc.AddOperation("/api/v1/workflow", http.MethodPost, operation("runWorkflow", "Run a workflow, from cloning to the pull request",
+		jsonBody("WorkflowRequest"),
+		response(http.StatusOK, "The workflow ran", "WorkflowResponse"),
+	))
+	healthOp := operation("health", "Status of the server and of each dependency of its jobs",
+		response(http.StatusOK, "Every dependency is ok", "HealthReport"),
+		response(http.StatusServiceUnavailable, "A dependency failed", "HealthReport"),
+	)
+	healthOp.Security = openapi3.NewSecurityRequirements()
+	doc.AddOperation("/api/v1/health", http.MethodGet, healthOp)
+
+	if err := openapi3.NewLoader().ResolveRefsIn(doc, nil); err != nil {
+		return nil, fmt.Errorf("failed to resolve OpenAPI references: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	return doc, nil
+}
+
+// OpenAPI serves the OpenAPI document of the API.
+func OpenAPI(doc *openapi3.T) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := types.RenderJSON(w, http.StatusOK, doc); err != nil {
+			slog.Error("error writing response", "error", err.Error())
+		}
+	}
+}
+
+// customizeSchema applies the binding and default tags of struct fields.
+func customizeSchema(_ string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+	if value, ok := tag.Lookup("default"); ok {
+		switch {
+		case schema.Type.Is(openapi3.TypeInteger):
+			if n, err := strconv.Atoi(value); err == nil {
+				schema.Default = n
+			}
+		case schema.Type.Is(openapi3.TypeBoolean):
+			if b, err := strconv.ParseBool(value); err == nil {
+				schema.Default = b
+			}
+		case schema.Type.Is(openapi3.TypeString):
+			schema.Default = value
+		}
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Tag.Get("binding") != "required" {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		schema.Required = append(schema.Required, name)
+		if prop := schema.Properties[name]; prop != nil && prop.Value.Type.Is(openapi3.TypeString) {
+			prop.Value.MinLength = 1
+		}
+	}
+	return nil
+}
+
+// operationOption sets part of an operation.
+type operationOption func(op *openapi3.Operation)
+
+func operation(id, summary string, opts ...operationOption) *openapi3.Operation {
+	op := openapi3.NewOperation()
+	op.OperationID = id
+	op.Summary = summary
+	op.Responses = openapi3.NewResponsesWithCapacity(0)
+	for _, opt := range opts {
+		opt(op)
+	}
+	op.AddResponse(http.StatusBadRequest, openapi3.NewResponse().WithDescription("The request is invalid").WithJSONSchemaRef(schemaRef("Response")))
+	return op
+}
+
+func schemaRef(name string) *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, nil)
+}
+
+func jsonBody(schema string) operationOption {
+	return func(op *openapi3.Operation) {
+		op.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+			WithRequired(true).
+			WithJSONSchemaRef(schemaRef(schema))}
+	}
+}
+
+func response(status int, description, schema string) operationOption {
+	return func(op *openapi3.Operation) {
+		op.AddResponse(status, openapi3.NewResponse().WithDescription(description).WithJSONSchemaRef(schemaRef(schema)))
+	}
+}
+
+func pathID() operationOption {
+	return func(op *openapi3.Operation) {
+		op.AddParameter(openapi3.NewPathParameter("id").WithSchema(openapi3.NewStringSchema()))
+	}
+}
+
+func queryParam(name string, schema *openapi3.Schema, description string, required bool) operationOption {
+	return func(op *openapi3.Operation) {
+		op.AddParameter(openapi3.NewQueryParameter(name).WithSchema(schema).WithDescription(description).WithRequired(required))
+	}
+}
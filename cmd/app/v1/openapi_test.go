@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"bauer/cmd/app/core/middleware"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSpecValidatesRequests(t *testing.T) {
+	spec, err := Spec()
+	if err != nil {
+		t.Fatalf("Spec() error = %v", err)
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler, err := middleware.ValidateRequests(spec, ok)
+	if err != nil {
+		t.Fatalf("ValidateRequests() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{"job", http.MethodPost, "/api/v1/job", `{"doc_id":"doc-a","chunk_size":2}`, http.StatusNoContent},
+		{"job without doc", http.MethodPost, "/api/v1/job", `{"chunk_size":2}`, http.StatusBadRequest},
+		{"job with a string chunk size", http.MethodPost, "/api/v1/job", `{"doc_id":"doc-a","chunk_size":"2"}`, http.StatusBadRequest},
+		{"preview with an empty doc", http.MethodPost, "/api/v1/job/preview", `{"doc_id":""}`, http.StatusBadRequest},
+		{"approve", http.MethodPost, "/api/v1/job/a/approve", "", http.StatusNoContent},
+		{"reject with a reason", http.MethodPost, "/api/v1/job/a/reject", `{"reason":"wrong page"}`, http.StatusNoContent},
+		{"extract", http.MethodGet, "/api/v1/extract?doc_id=doc-a", "", http.StatusNoContent},
+		{"extract without doc", http.MethodGet, "/api/v1/extract", "", http.StatusBadRequest},
+		{"jobs with a bad limit", http.MethodGet, "/api/v1/jobs?limit=0", "", http.StatusBadRequest},
+		{"workflow without token", http.MethodPost, "/api/v1/workflow", `{"github_repo":"o/r","doc_id":"d","credentials":"c.json"}`, http.StatusBadRequest},
+		{"undescribed path", http.MethodGet, "/api/v1/other", "", http.StatusNoContent},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
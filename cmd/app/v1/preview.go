@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"bauer/cmd/app/models/v1"
+	"bauer/cmd/app/types"
+	"bauer/internal/config"
+	"cmp"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// JobPreview runs the extraction and planning of a job, without executing
+// it, and reports the suggestions, conflicts and chunks it would apply. The
+// preview's files are written to a temporary directory, removed once done.
+func JobPreview(rc types.RouteConfig) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID, _ := r.Context().Value("requestID").(string)
+		payload, err := getJobFromRequest(w, r, requestID)
+		if err != nil {
+			return
+		}
+		if err := authorizeDoc(r.Context(), payload.DocID); err != nil {
+			audit(r.Context(), "preview", requestID, payload.DocID, err)
+			if err := types.Forbidden(err).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error(), "requestID", requestID)
+			}
+			return
+		}
+		audit(r.Context(), "preview", requestID, payload.DocID, nil)
+
+		cfg, err := previewConfig(rc, requestID, payload)
+		if err != nil {
+			slog.Error("failed to prepare preview", "error", err.Error(), "requestID", requestID)
+			if err := types.InternalError(err).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error(), "requestID", requestID)
+			}
+			return
+		}
+		defer os.RemoveAll(cfg.WorkDir)
+
+		result, err := rc.Orchestrator.Execute(r.Context(), &cfg)
+		if err != nil {
+			slog.Error("failed to preview job", "error", err.Error(), "requestID", requestID)
+			if err := types.InternalError(fmt.Errorf("failed to preview job: %w", err)).Render(w, r); err != nil {
+				slog.Error("error writing response", "error", err.Error(), "requestID", requestID)
+			}
+			return
+		}
+
+		extraction := result.ExtractionResult
+		preview := models.JobPreview{
+			DocumentID:         extraction.DocumentID,
+			DocumentTitle:      extraction.DocumentTitle,
+			GroupedSuggestions: extraction.GroupedSuggestions,
+			ConflictReport:     extraction.ConflictReport,
+			Stats:              extraction.Stats,
+			Manifest:           result.Manifest,
+		}
+		if err := types.RenderJSON(w, http.StatusOK, preview); err != nil {
+			slog.Error("error writing response", "error", err.Error(), "requestID", requestID)
+		}
+	}
+}
+
+// previewConfig returns the configuration of a dry run of the job, working in
+// a new temporary directory while files are still found in the target repository.
+func previewConfig(rc types.RouteConfig, requestID string, payload *models.JobPost) (config.Config, error) {
+	cfg := jobConfig(rc, requestID, payload)
+	targetRepo, err := filepath.Abs(cmp.Or(cfg.TargetRepo, "."))
+	if err != nil {
+		return cfg, fmt.Errorf("failed to resolve target repository: %w", err)
+	}
+	workDir, err := os.MkdirTemp("", "bauer-preview-*")
+	if err != nil {
+		return cfg, fmt.Errorf("failed to create preview directory: %w", err)
+	}
+	cfg.DryRun = true
+	cfg.WorkDir = workDir
+	cfg.OutputDir = workDir
+	cfg.TargetRepo = targetRepo
+	return cfg, nil
+}
@@ -0,0 +1,198 @@
+package v1
+
+import (
+	"bauer/cmd/app/core/jobs"
+	"bauer/cmd/app/core/store"
+	"bauer/cmd/app/models/v1"
+	"bauer/cmd/app/types"
+	"bauer/internal/workflow"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RecordJobs returns the queue's OnChange function saving jobs to st.
+func RecordJobs(st *store.Store) func(jobs.Job) {
+	return func(job jobs.Job) {
+		run, err := jobRun(job)
+		if err == nil {
+			err = st.Save(context.Background(), run)
+		}
+		if err != nil {
+			slog.Error("failed to record job", "error", err.Error(), "requestID", job.ID)
+		}
+	}
+}
+
+// jobRun converts a job to its record.
+func jobRun(job jobs.Job) (*store.Run, error) {
+	run := &store.Run{
+		ID:          job.ID,
+		Kind:        store.KindJob,
+		DocID:       job.DocID,
+		Status:      string(job.Status),
+		Stage:       job.Stage,
+		Error:       job.Error,
+		SubmittedBy: job.SubmittedBy,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		FinishedAt:  job.FinishedAt,
+	}
+	var err error
+	if run.Request, err = marshalRecord(job.Request); err != nil {
+		return nil, err
+	}
+	if run.Stages, err = marshalRecord(job.Stages); err != nil {
+		return nil, err
+	}
+	if run.Result, err = marshalRecord(job.Result); err != nil {
+		return nil, err
+	}
+	if result, ok := job.Result.(*models.JobResult); ok {
+		if run.Chunks, err = marshalRecord(result.ChunkOutputs); err != nil {
+			return nil, err
+		}
+	}
+	return run, nil
+}
+
+// ResumeJobs queues again the jobs interrupted by the last shutdown of the
+// server, up to the size of the queue, to resume them from the last chunk
+// they completed. It returns the number of jobs queued.
+func ResumeJobs(ctx context.Context, rc types.RouteConfig) (int, error) {
+	runs, err := rc.Store.List(ctx, store.Filter{
+		Kind:   store.KindJob,
+		Status: string(jobs.StatusInterrupted),
+		Limit:  rc.APIConfig.QueueSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+	// Oldest first, as they were submitted
+	slices.Reverse(runs)
+	resumed := 0
+	for _, run := range runs {
+		payload := &models.JobPost{}
+		if err := json.Unmarshal(run.Request, payload); err != nil {
+			slog.Error("failed to read request of interrupted job", "error", err.Error(), "requestID", run.ID)
+			continue
+		}
+		cfg := jobConfig(rc, run.ID, payload)
+		cfg.Resume = true
+		spec := jobs.Job{ID: run.ID, DocID: run.DocID, SubmittedBy: run.SubmittedBy, Request: payload}
+		if _, err := rc.Jobs.Submit(spec, runJob(run.ID, cfg, rc, payload.RequireApproval)); err != nil {
+			return resumed, fmt.Errorf("failed to queue interrupted job %s: %w", run.ID, err)
+		}
+		resumed++
+	}
+	return resumed, nil
+}
+
+// WorkflowRecorder records the runs of the workflow endpoint in a store.
+type WorkflowRecorder struct {
+	Store *store.Store
+}
+
+// StartRun records a workflow run as running, under the ID of its request.
+func (rec WorkflowRecorder) StartRun(ctx context.Context, req workflow.APIRequest) string {
+	id, _ := ctx.Value("requestID").(string)
+	now := time.Now().UTC()
+	run := &store.Run{
+		ID:          id,
+		Kind:        store.KindWorkflow,
+		DocID:       req.DocID,
+		Status:      string(jobs.StatusRunning),
+		SubmittedBy: submitter(ctx),
+		CreatedAt:   now,
+		StartedAt:   &now,
+	}
+	request, err := marshalRecord(req.Redacted())
+	if err == nil {
+		run.Request = request
+		err = rec.Store.Save(context.WithoutCancel(ctx), run)
+	}
+	if err != nil {
+		slog.Error("failed to record workflow run", "error", err.Error(), "requestID", id)
+	}
+	return id
+}
+
+// FinishRun records the output of a workflow run.
+func (rec WorkflowRecorder) FinishRun(ctx context.Context, id string, output *workflow.WorkflowOutput, runErr error) {
+	ctx = context.WithoutCancel(ctx)
+	run, err := rec.Store.Get(ctx, id)
+	if err != nil {
+		slog.Error("failed to record workflow run", "error", err.Error(), "requestID", id)
+		return
+	}
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+	run.Status = string(jobs.StatusSucceeded)
+	if output != nil {
+		if output.Status != "success" {
+			run.Status = string(jobs.StatusFailed)
+		}
+		if len(output.Errors) > 0 {
+			run.Error = output.Errors[0]
+		}
+		if run.Result, err = marshalRecord(output); err == nil {
+			run.Chunks, err = marshalRecord(output.BauerResult.ChunkOutputs)
+		}
+	}
+	if runErr != nil {
+		run.Status = string(jobs.StatusFailed)
+		run.Error = runErr.Error()
+	}
+	if err == nil {
+		err = rec.Store.Save(ctx, run)
+	}
+	if err != nil {
+		slog.Error("failed to record workflow run", "error", err.Error(), "requestID", id)
+	}
+}
+
+// runFilter reads the filter of a run list from the query: kind, status,
+// doc_id, submitted_by, since (RFC 3339), limit and offset.
+func runFilter(r *http.Request) (store.Filter, error) {
+	query := r.URL.Query()
+	filter := store.Filter{
+		Kind:   query.Get("kind"),
+		Status: query.Get("status"),
+		DocID:  query.Get("doc_id"),
+
+		SubmittedBy: query.Get("submitted_by"),
+	}
+	var err error
+	if since := query.Get("since"); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return filter, fmt.Errorf("invalid since: %w", err)
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return filter, fmt.Errorf("invalid limit: %w", err)
+		}
+	}
+	if offset := query.Get("offset"); offset != "" {
+		if filter.Offset, err = strconv.Atoi(offset); err != nil {
+			return filter, fmt.Errorf("invalid offset: %w", err)
+		}
+	}
+	return filter, nil
+}
+
+func marshalRecord(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal run record: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bauer/internal/gdocs"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+)
+
+// runAuth implements `bauer auth login` and `bauer auth logout`, which manage
+// the user's own Google login used with --credentials-mode user.
+func runAuth(args []string) error {
+	usage := func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s auth login --client-secrets <path> [--keyring] [--no-browser]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s auth logout\n\n", os.Args[0])
+	}
+	if len(args) == 0 {
+		usage()
+		return fmt.Errorf("missing auth command")
+	}
+
+	switch args[0] {
+	case "login":
+		return runAuthLogin(args[1:])
+	case "logout":
+		if err := gdocs.Logout(); err != nil {
+			return err
+		}
+		fmt.Println("Logged out")
+		return nil
+	default:
+		usage()
+		return fmt.Errorf("unknown auth command: %s", args[0])
+	}
+}
+
+func runAuthLogin(args []string) error {
+	fs := flag.NewFlagSet("auth login", flag.ExitOnError)
+	clientSecrets := fs.String("client-secrets", os.Getenv("BAUER_OAUTH_CLIENT_SECRETS"), "OAuth client (Desktop app) JSON from the Google Cloud console (default: $BAUER_OAUTH_CLIENT_SECRETS)")
+	keyring := fs.Bool("keyring", gdocs.KeyringAvailable(), "Store the login in the OS keyring instead of a token file")
+	noBrowser := fs.Bool("no-browser", false, "Print the consent URL without opening a browser")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s auth login --client-secrets <path> [--keyring] [--no-browser]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *clientSecrets == "" {
+		fs.Usage()
+		return fmt.Errorf("--client-secrets is required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := gdocs.LoginOptions{
+		ClientSecretsPath: *clientSecrets,
+		UseKeyring:        *keyring,
+	}
+	if !*noBrowser {
+		opts.OpenBrowser = openBrowser
+	}
+
+	location, err := gdocs.Login(ctx, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Logged in; credentials stored in %s\n", location)
+	fmt.Println("Use --credentials-mode user to run Bauer with your account.")
+	return nil
+}
+
+// openBrowser opens url in the default browser.
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
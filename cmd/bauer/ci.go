@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bauer/internal/actions"
+	"bauer/internal/config"
+	"bauer/internal/docsource"
+	"bauer/internal/logging"
+	"bauer/internal/orchestrator"
+	"bauer/internal/workflow"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runCI implements `bauer ci`, the workflow run from GitHub Actions: the
+// document comes from the triggering event, the repository defaults to the
+// one running the workflow, and the report goes to the job summary, with the
+// PR URL and status as step outputs.
+func runCI(args []string) error {
+	fs := flag.NewFlagSet("ci", flag.ExitOnError)
+	pf := addPRFlags(fs)
+	d := addDocumentFlags(fs)
+	c := addCloneFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Perform a dry run without creating PR")
+	eventPath := fs.String("event", os.Getenv("GITHUB_EVENT_PATH"), "Event file the document is read from when --doc-id isn't set (default: $GITHUB_EVENT_PATH)")
+	p := addPlanFlags(fs)
+	e := addExecutionFlags(fs)
+	logOptions := addLogFlags(fs)
+	configPath := addConfigFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s ci [--doc-id <id>] [--github-repo <repo>] [flags]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := applyDefaults(fs, *configPath, "local-repo-path"); err != nil {
+		return err
+	}
+
+	if d.docID == "" && (d.source == docsource.SourceGoogleDocs || d.source == docsource.SourceRevisions) && d.replay == "" {
+		if *eventPath == "" {
+			return fmt.Errorf("--doc-id, or --event to read it from, is required")
+		}
+		event, err := actions.LoadEvent(*eventPath)
+		if err != nil {
+			return err
+		}
+		d.docID, err = event.DocumentID()
+		if err != nil {
+			return err
+		}
+	}
+	if err := d.resolve(true); err != nil {
+		return err
+	}
+	if pf.githubRepo == "" && len(d.siteTable) == 0 {
+		pf.githubRepo = os.Getenv("GITHUB_REPOSITORY")
+	}
+	if pf.githubRepo == "" && len(d.siteTable) == 0 {
+		return fmt.Errorf("--github-repo, $GITHUB_REPOSITORY or --sites is required")
+	}
+
+	closeLog, err := logging.Setup(*logOptions, d.docID)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	workflowInput, err := pf.workflowInput()
+	if err != nil {
+		return err
+	}
+	cfg := stageConfig(d, p, e)
+	workflowInput.ShallowClone = c.shallowClone
+	workflowInput.SparsePaths = config.SplitList(c.sparsePaths)
+	workflowInput.DocID = d.docID
+	workflowInput.LocalRepoPath = c.localRepoPath
+	workflowInput.DryRun = *dryRun
+	setWorkflowConfig(&workflowInput, cfg)
+
+	result, err := workflow.ExecuteWorkflow(context.Background(), workflowInput, orchestrator.NewOrchestrator())
+	if result == nil {
+		return err
+	}
+	printWorkflowResult(result)
+	if summaryErr := publishCIResult(d.docID, result); summaryErr != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: %v\n", summaryErr)
+	}
+	if err != nil {
+		return err
+	}
+	if result.Status == "failed" {
+		return fmt.Errorf("workflow failed: %s", strings.Join(result.Errors, "; "))
+	}
+	return nil
+}
+
+// publishCIResult writes the run report to the job summary and sets the
+// step outputs: doc_id, status, pr_url, run_id and report.
+func publishCIResult(docID string, result *workflow.WorkflowOutput) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Bauer %s**", result.Status)
+	if url := result.FinalizationInfo.PullRequest.URL; url != "" {
+		fmt.Fprintf(&sb, ": %s", url)
+	}
+	sb.WriteString("\n\n")
+	for _, e := range result.Errors {
+		fmt.Fprintf(&sb, "- Error: %s\n", e)
+	}
+	for _, w := range result.Warnings {
+		fmt.Fprintf(&sb, "- Warning: %s\n", w)
+	}
+	if reportPath := result.BauerResult.ReportPath; reportPath != "" {
+		markdown, err := os.ReadFile(filepath.Join(filepath.Dir(reportPath), orchestrator.ReportMarkdownFile))
+		if err != nil {
+			return fmt.Errorf("failed to read run report: %w", err)
+		}
+		sb.WriteString("\n")
+		sb.Write(markdown)
+	}
+	if err := actions.WriteSummary(sb.String()); err != nil {
+		return err
+	}
+
+	outputs := [][2]string{
+		{"doc_id", docID},
+		{"status", result.Status},
+		{"pr_url", result.FinalizationInfo.PullRequest.URL},
+		{"run_id", result.BauerResult.RunID},
+		{"report", result.BauerResult.ReportPath},
+	}
+	for _, output := range outputs {
+		if err := actions.SetOutput(output[0], output[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bauer/internal/config"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// runConfig implements `bauer config validate`, which checks the config files
+// and environment settings the commands would use.
+func runConfig(args []string) error {
+	usage := func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s config validate [--config <path>] [--repo <dir>]\n\n", os.Args[0])
+	}
+	if len(args) == 0 {
+		usage()
+		return fmt.Errorf("missing config command")
+	}
+
+	switch args[0] {
+	case "validate":
+		return runConfigValidate(args[1:])
+	default:
+		usage()
+		return fmt.Errorf("unknown config command: %s", args[0])
+	}
+}
+
+func runConfigValidate(args []string) error {
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	configPath := addConfigFlag(fs)
+	repoDir := fs.String("repo", ".", "Repository whose "+config.RepoFileName+" is checked")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s config validate [--config <path>] [--repo <dir>]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	files := config.ConfigFiles(*configPath, *repoDir)
+	defaults, err := config.LoadDefaults(*configPath, *repoDir)
+	if err != nil {
+		return err
+	}
+	if err := validateDefaults(defaults); err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		fmt.Println("Config files: none")
+	} else {
+		fmt.Printf("Config files: %s\n", strings.Join(files, ", "))
+	}
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %s = %q (%s)\n", name, defaults[name].Value, defaults[name].Source)
+	}
+	fmt.Println("Configuration is valid")
+	return nil
+}
+
+// validateDefaults checks settings by setting them on the flags of `bauer
+// run`, and checking the configuration they make.
+func validateDefaults(defaults config.Defaults) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	d := addDocumentFlags(fs)
+	p := addPlanFlags(fs)
+	e := addExecutionFlags(fs)
+	addPRFlags(fs)
+	addCloneFlags(fs)
+	logOptions := addLogFlags(fs)
+	for name, value := range defaults {
+		if err := fs.Set(config.Flag(name), value.Value); err != nil {
+			return fmt.Errorf("invalid %s from %s: %w", name, value.Source, err)
+		}
+	}
+
+	if err := d.resolve(false); err != nil {
+		return err
+	}
+	cfg := stageConfig(d, p, e)
+	cfg.ApplyDefaults()
+	if err := cfg.ValidateOptions(); err != nil {
+		return err
+	}
+	return logOptions.Validate()
+}
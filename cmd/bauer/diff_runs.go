@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bauer/internal/gdocs"
+	"bauer/internal/orchestrator"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runDiffRuns implements `bauer diff-runs <run-a> <run-b>`.
+// Each run is either an extraction result file or a directory containing one.
+func runDiffRuns(args []string) error {
+	fs := flag.NewFlagSet("diff-runs", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Print the comparison as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s diff-runs [--json] <run-a> <run-b>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Each run is a %s file or a directory containing one.\n\n", orchestrator.ExtractionResultFile)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("diff-runs expects exactly two runs, got %d", fs.NArg())
+	}
+
+	before, err := loadRunResult(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	after, err := loadRunResult(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	diff, err := gdocs.DiffProcessingResults(before, after)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal run diff: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	printRunDiff(diff)
+	return nil
+}
+
+// loadRunResult loads the extraction result of a run from a file or run directory.
+func loadRunResult(path string) (*gdocs.ProcessingResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access run %s: %w", path, err)
+	}
+	if info.IsDir() {
+		path = filepath.Join(path, orchestrator.ExtractionResultFile)
+	}
+	return gdocs.LoadProcessingResult(path)
+}
+
+// printRunDiff writes a human readable comparison report to stdout.
+func printRunDiff(diff *gdocs.RunDiff) {
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Printf("Run comparison for document %s\n", diff.DocumentID)
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Printf("Added: %d  Removed: %d  Changed: %d  Unchanged: %d\n\n",
+		len(diff.Added), len(diff.Removed), len(diff.Changed), diff.UnchangedCount)
+
+	printDiffSection("Added", diff.Added)
+	printDiffSection("Removed", diff.Removed)
+	printDiffSection("Changed", diff.Changed)
+}
+
+func printDiffSection(title string, entries []gdocs.SuggestionDiffEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	fmt.Printf("%s:\n", title)
+	for _, entry := range entries {
+		heading := entry.Location.ParentHeading
+		if heading == "" {
+			heading = entry.Location.Section
+		}
+		fmt.Printf("  - %s (%s)\n", entry.ID, heading)
+		if entry.Before != nil {
+			fmt.Printf("      before: %s\n", formatChange(entry.Before.Change))
+		}
+		if entry.After != nil {
+			fmt.Printf("      after:  %s\n", formatChange(entry.After.Change))
+		}
+	}
+	fmt.Println()
+}
+
+func formatChange(change gdocs.SuggestionChange) string {
+	if change.Type == "style" && !change.Style.IsEmpty() {
+		return fmt.Sprintf("style %q (%s)", change.OriginalText, change.Style)
+	}
+	return fmt.Sprintf("%s %q -> %q", change.Type, change.OriginalText, change.NewText)
+}
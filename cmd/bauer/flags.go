@@ -0,0 +1,413 @@
+package main
+
+import (
+	"bauer/internal/config"
+	"bauer/internal/docsource"
+	"bauer/internal/gdocs"
+	"bauer/internal/logging"
+	"bauer/internal/screenshot"
+	"bauer/internal/sites"
+	"bauer/internal/state"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// The flags of the commands are registered in groups, so the commands that
+// run the same stage take the same flags and resolve them the same way.
+
+// documentFlags select the document and how its suggestions are extracted.
+type documentFlags struct {
+	docID            string
+	credentials      string
+	credentialsMode  string
+	source           string
+	file             string
+	before           string
+	beforeRevision   string
+	afterRevision    string
+	tab              string
+	replay           string
+	apiMaxAttempts   int
+	noCache          bool
+	dumpRaw          bool
+	onlyAuthor       string
+	since            string
+	suggestionIDs    string
+	anchorLength     int
+	anchorBoundary   string
+	normalize        string
+	mergeSentences   bool
+	conflictStrategy string
+	includeComments  bool
+	staleCheck       string
+	stateFile        string
+	reapply          bool
+	sites            string
+
+	siteTable sites.Table
+}
+
+func addDocumentFlags(fs *flag.FlagSet) *documentFlags {
+	d := &documentFlags{}
+	fs.StringVar(&d.docID, "doc-id", "", "Google Doc ID")
+	fs.StringVar(&d.credentials, "credentials", "bau-test-creds.json", "Path to service account credentials JSON")
+	fs.StringVar(&d.credentialsMode, "credentials-mode", "file", "Where Google credentials come from: file, env, adc, workload-identity or user")
+	fs.StringVar(&d.source, "source", "gdocs", "Where the document comes from: gdocs, docx, diff or revisions")
+	fs.StringVar(&d.file, "file", "", "Path to the .docx file (with --source docx), or the new page version (with --source diff)")
+	fs.StringVar(&d.before, "before", "", "Old page version to diff against --file: a path or git:<revision>:<path> (with --source diff)")
+	fs.StringVar(&d.beforeRevision, "before-revision", "", "Old revision of the document to diff: head~<n>, first, named, a date or time, or a revision ID (with --source revisions)")
+	fs.StringVar(&d.afterRevision, "after-revision", "", "New revision of the document to diff, selected like --before-revision (default: the latest)")
+	fs.StringVar(&d.tab, "tab", "", "Only read the tab of this ID or title, in documents with tabs (default: every tab)")
+	fs.StringVar(&d.replay, "replay", "", "Build the run from a raw document saved with --dump-raw instead of fetching it")
+	fs.IntVar(&d.apiMaxAttempts, "api-max-attempts", 5, "Attempts per Google API call when rate limited or failing")
+	fs.BoolVar(&d.noCache, "no-cache", false, "Always fetch the full document instead of reusing the cached copy of an unchanged revision")
+	fs.BoolVar(&d.dumpRaw, "dump-raw", false, "Write the raw document JSON next to bauer-doc-suggestions.json")
+	fs.StringVar(&d.onlyAuthor, "only-author", "", "Only process suggestions by these authors (comma-separated names or emails)")
+	fs.StringVar(&d.since, "since", "", "Only process suggestions made since this date (YYYY-MM-DD) or RFC 3339 time")
+	fs.StringVar(&d.suggestionIDs, "suggestion-ids", "", "Only process these suggestions (comma-separated IDs)")
+	fs.IntVar(&d.anchorLength, "anchor-length", 0, "Length of the text around each suggestion used to locate it (default: 80)")
+	fs.StringVar(&d.anchorBoundary, "anchor-boundary", "", "Extend anchors to whole words or sentences: word or sentence")
+	fs.StringVar(&d.normalize, "normalize", "", "Normalize anchors to match rendered HTML: comma-separated nfc, quotes, whitespace or all")
+	fs.BoolVar(&d.mergeSentences, "merge-sentences", false, "Combine suggestions in the same sentence into a single replacement")
+	fs.StringVar(&d.conflictStrategy, "conflict-strategy", "largest", "Which overlapping suggestion wins: largest, newest, fail or interactive")
+	fs.BoolVar(&d.includeComments, "include-comments", false, "Treat unresolved comments on quoted text as suggestions")
+	fs.StringVar(&d.staleCheck, "stale-check", "", "Flag suggestions that no longer match the published page: http or repo")
+	fs.StringVar(&d.stateFile, "state-file", defaultStateFile(), "File recording the outcome of each suggestion across runs; empty disables it")
+	fs.BoolVar(&d.reapply, "reapply", false, "Process the suggestions earlier runs applied, as recorded in --state-file")
+	addSitesFlag(fs, &d.sites)
+	return d
+}
+
+// resolve checks the document flags, naming documents read from files after
+// the file, as it identifies them in branch names, commits and the PR.
+// required is false for commands that can do without a document, e.g. when
+// given an extraction.
+func (d *documentFlags) resolve(required bool) error {
+	table, err := parseSites(d.sites)
+	if err != nil {
+		return err
+	}
+	d.siteTable = table
+
+	switch {
+	case d.source == docsource.SourceDocx || d.source == docsource.SourceDiff:
+		if d.file == "" {
+			return fmt.Errorf("--file is required with --source %s", d.source)
+		}
+		if d.source == docsource.SourceDiff && d.before == "" {
+			return fmt.Errorf("--before is required with --source diff")
+		}
+		if d.docID == "" {
+			d.docID = fileDocID(d.file)
+		}
+	case d.source == docsource.SourceRevisions && d.beforeRevision == "":
+		return fmt.Errorf("--before-revision is required with --source revisions")
+	case d.replay != "":
+		if d.docID == "" {
+			d.docID = fileDocID(d.replay)
+		}
+	case d.docID == "" && required:
+		return fmt.Errorf("--doc-id is required")
+	case d.docID != "":
+		id, err := gdocs.ParseDocumentID(d.docID)
+		if err != nil {
+			return err
+		}
+		d.docID = id
+	}
+	return nil
+}
+
+// apply sets the document options of cfg.
+func (d *documentFlags) apply(cfg *config.Config) {
+	cfg.DocID = d.docID
+	cfg.CredentialsPath = d.credentials
+	cfg.CredentialsMode = d.credentialsMode
+	cfg.Source = d.source
+	cfg.File = d.file
+	cfg.Before = d.before
+	cfg.BeforeRevision = d.beforeRevision
+	cfg.AfterRevision = d.afterRevision
+	cfg.Tab = d.tab
+	cfg.Replay = d.replay
+	cfg.APIMaxAttempts = d.apiMaxAttempts
+	cfg.NoCache = d.noCache
+	cfg.DumpRaw = d.dumpRaw
+	cfg.OnlyAuthors = config.SplitList(d.onlyAuthor)
+	cfg.Since = d.since
+	cfg.SuggestionIDs = config.SplitList(d.suggestionIDs)
+	cfg.AnchorLength = d.anchorLength
+	cfg.AnchorBoundary = d.anchorBoundary
+	cfg.Normalize = config.SplitList(d.normalize)
+	cfg.MergeSentences = d.mergeSentences
+	cfg.ConflictStrategy = d.conflictStrategy
+	cfg.IncludeComments = d.includeComments
+	cfg.StaleCheck = d.staleCheck
+	cfg.StateFile = d.stateFile
+	cfg.Reapply = d.reapply
+	cfg.Sites = d.siteTable
+}
+
+// defaultStateFile is the state file of the user, or none when the user has
+// no config directory.
+func defaultStateFile() string {
+	path, err := state.DefaultFile()
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// addSitesFlag registers --sites, the sites of the pages of documents.
+func addSitesFlag(fs *flag.FlagSet, value *string) {
+	fs.StringVar(value, "sites", "", "Sites mapping page URLs onto repositories and templates: a YAML or JSON file, or JSON")
+}
+
+// parseSites parses the value of --sites; empty is no sites.
+func parseSites(value string) (sites.Table, error) {
+	if value == "" {
+		return nil, nil
+	}
+	table, err := sites.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --sites: %w", err)
+	}
+	return table, nil
+}
+
+// planFlags configure how the suggestions are split into chunks and prompts.
+type planFlags struct {
+	outputDir   string
+	chunkSize   int
+	pageRefresh bool
+	chunkOrder  string
+	templateDir string
+	directApply bool
+	threshold   float64
+	maxTokens   int
+	triage      string
+	routes      string
+}
+
+func addPlanFlags(fs *flag.FlagSet) *planFlags {
+	p := &planFlags{}
+	fs.StringVar(&p.outputDir, "output-dir", "bauer-output", "Output directory for Bauer results")
+	fs.IntVar(&p.chunkSize, "chunk-size", 0, "Total number of chunks to create (default: 1, or 5 with --page-refresh)")
+	fs.BoolVar(&p.pageRefresh, "page-refresh", false, "Whether this is a page refresh, or the default copy update")
+	fs.StringVar(&p.chunkOrder, "chunk-order", "position", "Order locations into chunks by position, difficulty or churn")
+	fs.StringVar(&p.templateDir, "template-dir", "", "Directory of templates replacing the built-in prompts: instructions.md.tmpl, summary.md.tmpl")
+	fs.BoolVar(&p.directApply, "direct-apply", false, "Patch suggestions whose text matches exactly once in their file without Copilot")
+	fs.Float64Var(&p.threshold, "direct-threshold", 0, "Similarity from 0 to 1 a fuzzy match needs to be patched with --direct-apply (default 0.9)")
+	fs.IntVar(&p.maxTokens, "max-chunk-tokens", 0, "Estimated tokens over which a chunk is flagged in the run report (default 60000)")
+	fs.StringVar(&p.triage, "triage", "", "Classify suggestions by category and risk: rules, or llm to have an OpenAI-compatible model review the rules")
+	fs.StringVar(&p.routes, "triage-routes", "", "Route categories or risk levels, e.g. legal=review,structural=review,high=skip (routes: apply, review, skip)")
+	return p
+}
+
+func (p *planFlags) apply(cfg *config.Config) {
+	cfg.OutputDir = p.outputDir
+	cfg.ChunkSize = p.chunkSize
+	cfg.PageRefresh = p.pageRefresh
+	cfg.ChunkOrder = p.chunkOrder
+	cfg.TemplateDir = p.templateDir
+	cfg.DirectApply = p.directApply
+	cfg.DirectThreshold = p.threshold
+	cfg.MaxChunkTokens = p.maxTokens
+	cfg.Triage = p.triage
+	cfg.TriageRoutes = config.SplitList(p.routes)
+}
+
+// executionFlags configure the sessions that apply the chunks, and the
+// checks of their changes.
+type executionFlags struct {
+	model             string
+	summaryModel      string
+	executor          string
+	allowedTools      string
+	excludedTools     string
+	denyShell         bool
+	denyNetwork       bool
+	restrictWrites    bool
+	chunkRetries      int
+	fallbackModel     string
+	validateCommand   string
+	validateRetries   int
+	skipTemplateCheck bool
+	resume            bool
+	approval          string
+	approvalWebhook   string
+	maxSuggestions    int
+	maxChunks         int
+	maxCopilotTime    string
+	maxFiles          int
+	maxLines          int
+	unexpectedChanges string
+	protectedPaths    string
+}
+
+func addExecutionFlags(fs *flag.FlagSet) *executionFlags {
+	e := &executionFlags{}
+	fs.StringVar(&e.model, "model", "gpt-5-mini-high", "Model to use for chunk sessions")
+	fs.StringVar(&e.summaryModel, "summary-model", "gpt-5-mini-high", "Model to use for the summary session")
+	fs.StringVar(&e.executor, "executor", "copilot", "LLM backend that applies the chunks: copilot or openai")
+	fs.StringVar(&e.allowedTools, "allowed-tools", "", "Only let Copilot sessions use these tools (comma-separated, e.g. view,edit)")
+	fs.StringVar(&e.excludedTools, "excluded-tools", "", "Remove these tools from Copilot sessions (comma-separated)")
+	fs.BoolVar(&e.denyShell, "deny-shell", false, "Refuse shell commands in Copilot sessions")
+	fs.BoolVar(&e.denyNetwork, "deny-network", false, "Refuse URL fetches and MCP server calls in Copilot sessions")
+	fs.BoolVar(&e.restrictWrites, "restrict-writes", false, "Refuse Copilot file writes outside the target repository")
+	fs.IntVar(&e.chunkRetries, "chunk-retries", 0, "Retries of a chunk after a Copilot session error or timeout")
+	fs.StringVar(&e.fallbackModel, "fallback-model", "", "Copilot model to use for the final retry of a chunk")
+	fs.StringVar(&e.validateCommand, "validate-command", "", "Command checking the changes before they are pushed, e.g. \"yarn build\"")
+	fs.IntVar(&e.validateRetries, "validate-retries", 2, "Sessions asked to fix the errors of a failing --validate-command")
+	fs.BoolVar(&e.skipTemplateCheck, "skip-template-check", false, "Don't check the HTML templates changed by each chunk for damage")
+	fs.BoolVar(&e.resume, "resume", false, "Skip the chunks the previous run in --output-dir completed; retry failed and pending ones")
+	fs.StringVar(&e.approval, "approval", "", "Wait for the plan to be approved before executing it: prompt (on the terminal) or file (bauer-approved in --output-dir)")
+	fs.StringVar(&e.approvalWebhook, "approval-webhook", "", "URL posted the plan of runs waiting for approval, e.g. a Slack incoming webhook")
+	fs.IntVar(&e.maxSuggestions, "max-suggestions", 0, "Stop runs applying more suggestions than this before anything is changed (0: no limit)")
+	fs.IntVar(&e.maxChunks, "max-chunks", 0, "Stop runs planning more chunks than this before any is executed (0: no limit)")
+	fs.StringVar(&e.maxCopilotTime, "max-copilot-duration", "", "Stop runs whose chunks executed for longer than this, e.g. 30m, before the next chunk")
+	fs.IntVar(&e.maxFiles, "max-files-modified", 0, "Don't push the changes of runs modifying more files than this (0: no limit)")
+	fs.IntVar(&e.maxLines, "max-lines-changed", 0, "Don't push the changes of runs adding and removing more lines than this (0: no limit)")
+	fs.StringVar(&e.unexpectedChanges, "unexpected-changes", "warn", "What runs changing files outside the pages of the document do: warn, or fail without pushing")
+	fs.StringVar(&e.protectedPaths, "protected-paths", "", "Revert changes chunks make to files matching these patterns (comma-separated, e.g. \"*.py,.github/**,package.json\")")
+	return e
+}
+
+func (e *executionFlags) apply(cfg *config.Config) {
+	cfg.Model = e.model
+	cfg.SummaryModel = e.summaryModel
+	cfg.Executor = e.executor
+	cfg.AllowedTools = config.SplitList(e.allowedTools)
+	cfg.ExcludedTools = config.SplitList(e.excludedTools)
+	cfg.DenyShell = e.denyShell
+	cfg.DenyNetwork = e.denyNetwork
+	cfg.RestrictWrites = e.restrictWrites
+	cfg.ChunkRetries = e.chunkRetries
+	cfg.FallbackModel = e.fallbackModel
+	cfg.ValidateCommand = e.validateCommand
+	cfg.ValidateRetries = e.validateRetries
+	cfg.SkipTemplateCheck = e.skipTemplateCheck
+	cfg.Resume = e.resume
+	cfg.Approval = e.approval
+	cfg.ApprovalWebhook = e.approvalWebhook
+	cfg.MaxSuggestions = e.maxSuggestions
+	cfg.MaxChunks = e.maxChunks
+	cfg.MaxCopilotDuration = e.maxCopilotTime
+	cfg.MaxFilesModified = e.maxFiles
+	cfg.MaxLinesChanged = e.maxLines
+	cfg.UnexpectedChanges = e.unexpectedChanges
+	cfg.ProtectedPaths = config.SplitList(e.protectedPaths)
+}
+
+// prFlags configure the repository, branch and pull request of the changes.
+type prFlags struct {
+	githubRepo        string
+	githubHost        string
+	branchPrefix      string
+	skipCodeOwners    bool
+	reviewers         string
+	reviewerMap       string
+	assignees         string
+	labels            string
+	milestone         string
+	screenshotServer  string
+	screenshotURL     string
+	screenshotBrowser string
+	commentOnDoc      bool
+}
+
+func addPRFlags(fs *flag.FlagSet) *prFlags {
+	p := &prFlags{}
+	fs.StringVar(&p.githubRepo, "github-repo", "", "GitHub repository (owner/repo or HTTPS URL)")
+	fs.StringVar(&p.githubHost, "github-host", "", "GitHub Enterprise Server host of --github-repo (default: from GITHUB_API_URL, else github.com)")
+	fs.StringVar(&p.branchPrefix, "branch-prefix", "bauer", "Branch naming prefix")
+	fs.BoolVar(&p.skipCodeOwners, "skip-code-owners", false, "Don't request reviews from CODEOWNERS of modified files")
+	fs.StringVar(&p.reviewers, "reviewers", "", "Request reviews of the PR from these GitHub users or org/teams (comma-separated)")
+	fs.StringVar(&p.reviewerMap, "reviewer-map", "", "JSON file mapping emails of document authors to GitHub handles, to request their reviews")
+	fs.StringVar(&p.assignees, "assignees", "", "Assign the PR to these GitHub users (comma-separated)")
+	fs.StringVar(&p.labels, "labels", "", "Add these labels to the PR (comma-separated)")
+	fs.StringVar(&p.milestone, "milestone", "", "Add the PR to this milestone, by name")
+	fs.StringVar(&p.screenshotServer, "screenshot-server", "", "Command starting the site's development server, to add before/after screenshots of the page to the PR (e.g. dotrun)")
+	fs.StringVar(&p.screenshotURL, "screenshot-url", screenshot.DefaultBaseURL, "Where the development server serves the site")
+	fs.StringVar(&p.screenshotBrowser, "screenshot-browser", "", "Chrome or Chromium binary taking the screenshots (default: found on PATH)")
+	fs.BoolVar(&p.commentOnDoc, "comment-on-doc", false, "Comment on the Google Doc with the PR link and the status of its suggestions")
+	return p
+}
+
+// cloneFlags configure the checkout of the repository by `bauer run`.
+type cloneFlags struct {
+	localRepoPath string
+	shallowClone  bool
+	sparsePaths   string
+}
+
+func addCloneFlags(fs *flag.FlagSet) *cloneFlags {
+	c := &cloneFlags{}
+	fs.StringVar(&c.localRepoPath, "local-repo-path", "", "Local path for cloned repository (default: named after the repository, in the temporary directory)")
+	fs.BoolVar(&c.shallowClone, "shallow-clone", false, "Clone only the latest commit of the repository")
+	fs.StringVar(&c.sparsePaths, "sparse-paths", "", "Only check out these directories of the repository (comma-separated, e.g. templates)")
+	return c
+}
+
+// addConfigFlag registers --config, the config file of the defaults of the
+// flags.
+func addConfigFlag(fs *flag.FlagSet) *string {
+	return fs.String("config", "", "Config file setting the defaults of the flags (default: $"+config.FileEnvVar+", else ./"+config.FileName+")")
+}
+
+// applyDefaults sets the flags of fs that weren't given on the command line
+// from the config files and the environment, so flags win over the
+// environment, and the environment over config files. The repository's
+// config file is read from the directory of the repoFlag flag, if any.
+func applyDefaults(fs *flag.FlagSet, configPath, repoFlag string) error {
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	defaults, err := config.LoadDefaults(configPath, "")
+	if err != nil {
+		return err
+	}
+	if f := fs.Lookup(repoFlag); f != nil {
+		repoDir := f.Value.String()
+		if d, ok := defaults[strings.ReplaceAll(repoFlag, "-", "_")]; ok && !given[repoFlag] {
+			repoDir = d.Value
+		}
+		if defaults, err = config.LoadDefaults(configPath, repoDir); err != nil {
+			return err
+		}
+	}
+
+	for name, d := range defaults {
+		flagName := config.Flag(name)
+		if given[flagName] || fs.Lookup(flagName) == nil {
+			continue
+		}
+		if err := fs.Set(flagName, d.Value); err != nil {
+			return fmt.Errorf("invalid %s from %s: %w", name, d.Source, err)
+		}
+	}
+	return nil
+}
+
+// addLogFlags registers the logging flags of the CLI, which logs text to
+// stderr by default.
+func addLogFlags(fs *flag.FlagSet) *logging.Options {
+	return logging.Flags(fs, logging.Options{Level: "info", Format: logging.FormatText, Output: logging.OutputStderr})
+}
+
+// stageConfig builds the configuration of a stage from its flag groups;
+// groups a command doesn't take are nil.
+func stageConfig(d *documentFlags, p *planFlags, e *executionFlags) *config.Config {
+	cfg := &config.Config{}
+	if d != nil {
+		d.apply(cfg)
+	}
+	if p != nil {
+		p.apply(cfg)
+	}
+	if e != nil {
+		e.apply(cfg)
+	}
+	return cfg
+}
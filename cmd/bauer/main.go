@@ -1,72 +1,388 @@
 package main
 
 import (
+	"bauer/internal/config"
 	"bauer/internal/github"
+	"bauer/internal/ledger"
+	"bauer/internal/logging"
 	"bauer/internal/orchestrator"
 	"bauer/internal/workflow"
 	"context"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
 func main() {
-	// Parse CLI flags
-	githubRepo := flag.String("github-repo", "", "GitHub repository (owner/repo or HTTPS URL)")
-	docID := flag.String("doc-id", "", "Google Doc ID")
-	credentialsPath := flag.String("credentials", "bau-test-creds.json", "Path to service account credentials JSON")
-	localRepoPath := flag.String("local-repo-path", "/tmp/ubuntu.com", "Local path for cloned repository")
-	dryRun := flag.Bool("dry-run", false, "Perform a dry run without creating PR")
-	outputDir := flag.String("output-dir", "bauer-output", "Output directory for Bauer results")
-	branchPrefix := flag.String("branch-prefix", "bauer", "Branch naming prefix")
+	// Flags without a command run the whole workflow, like `bauer run`
+	args := os.Args[1:]
+	run := runWorkflow
+	if len(args) > 0 {
+		if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			printUsage()
+			return
+		}
+		if cmd := command(args[0]); cmd != nil {
+			run, args = cmd, args[1:]
+		}
+	}
+	if err := run(args); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+}
 
-	flag.Parse()
+// command returns the subcommand called name, or nil.
+func command(name string) func([]string) error {
+	switch name {
+	case "run":
+		return runWorkflow
+	case "extract":
+		return runExtract
+	case "plan":
+		return runPlan
+	case "apply":
+		return runApply
+	case "pr":
+		return runPR
+	case "serve":
+		return runServe
+	case "auth":
+		return runAuth
+	case "config":
+		return runConfig
+	case "diff-runs":
+		return runDiffRuns
+	case "resolve":
+		return runResolve
+	case "watch":
+		return runWatch
+	case "rollback":
+		return runRollback
+	case "ci":
+		return runCI
+	}
+	return nil
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n\n\t%s <command> [flags]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Commands:\n\n")
+	fmt.Fprintf(os.Stderr, "\trun        Clone, extract, plan, apply and open the PR (the default without a command)\n")
+	fmt.Fprintf(os.Stderr, "\textract    Extract the suggestions of a document to %s\n", orchestrator.ExtractionResultFile)
+	fmt.Fprintf(os.Stderr, "\tplan       Write the chunk prompts and manifest, without applying them\n")
+	fmt.Fprintf(os.Stderr, "\tapply      Apply the suggestions to a local repository\n")
+	fmt.Fprintf(os.Stderr, "\tpr         Commit, push and open the PR of changes made by apply\n")
+	fmt.Fprintf(os.Stderr, "\tserve      Run the API server\n")
+	fmt.Fprintf(os.Stderr, "\tauth       Log in to, or out of, your Google account\n")
+	fmt.Fprintf(os.Stderr, "\tconfig     Check the config files and environment settings\n")
+	fmt.Fprintf(os.Stderr, "\tdiff-runs  Compare the extractions of two runs\n")
+	fmt.Fprintf(os.Stderr, "\tresolve    Accept the suggestions of a merged PR in the document\n")
+	fmt.Fprintf(os.Stderr, "\twatch      Run the workflow whenever a document gains suggestions\n")
+	fmt.Fprintf(os.Stderr, "\trollback   Close the PR and delete the branch of a run\n")
+	fmt.Fprintf(os.Stderr, "\tci         Run the workflow from GitHub Actions, for the document of the event\n\n")
+	fmt.Fprintf(os.Stderr, "Run %s <command> --help for the flags of a command.\n", os.Args[0])
+}
+
+// runWorkflow implements `bauer run`: the whole workflow, from cloning the
+// repository to the pull request, for one or more documents.
+func runWorkflow(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	pf := addPRFlags(fs)
+	d := addDocumentFlags(fs)
+	docList := fs.String("docs", "", "Comma-separated list, or file, of Google Doc IDs/URLs to process in one run")
+	singlePR := fs.Bool("single-pr", false, "Apply the --docs on one branch, a commit each, and open a single PR for all of them")
+	c := addCloneFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Perform a dry run without creating PR")
+	p := addPlanFlags(fs)
+	e := addExecutionFlags(fs)
+	logOptions := addLogFlags(fs)
+	configPath := addConfigFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s [run] --github-repo <repo> --doc-id <id> [flags]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := applyDefaults(fs, *configPath, "local-repo-path"); err != nil {
+		return err
+	}
 
 	// Validate required flags
-	if *githubRepo == "" {
-		fmt.Fprintf(os.Stderr, "ERROR: --github-repo is required\n")
-		os.Exit(1)
+	if err := d.resolve(false); err != nil {
+		return err
 	}
-	if *docID == "" {
-		fmt.Fprintf(os.Stderr, "ERROR: --doc-id is required\n")
-		os.Exit(1)
+	if pf.githubRepo == "" && len(d.siteTable) == 0 {
+		return fmt.Errorf("--github-repo, or --sites to find it by, is required")
+	}
+	if d.docID == "" && *docList == "" {
+		return fmt.Errorf("--doc-id or --docs is required")
+	}
+	if *singlePR && pf.githubRepo == "" {
+		return fmt.Errorf("--single-pr requires --github-repo")
 	}
 
+	docCfg := config.Config{DocID: d.docID}
+	if *docList != "" {
+		parsed, err := config.ParseDocList(*docList)
+		if err != nil {
+			return err
+		}
+		docCfg.DocIDs = parsed
+	}
+	docIDs, err := docCfg.Documents()
+	if err != nil {
+		return err
+	}
+
+	// Log files are named after the document, or the batch of documents
+	logRun := docIDs[0]
+	if len(docIDs) > 1 {
+		logRun = "batch"
+	}
+	closeLog, err := logging.Setup(*logOptions, logRun)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
 	fmt.Println(strings.Repeat("=", 80))
 	fmt.Println("Bauer - A tool to automate BAU tasks")
 	fmt.Println(strings.Repeat("=", 80))
 	fmt.Println()
 
 	// Create workflow input from CLI flags/config
-	ghToken, err := github.GetGitHubToken()
+	workflowInput, err := pf.workflowInput()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "WARNING: Could not get GitHub token: %v\n", err)
-		ghToken = ""
-	}
-
-	workflowInput := workflow.WorkflowInput{
-		GitHubRepo:    *githubRepo,
-		GitHubToken:   ghToken,
-		BranchPrefix:  *branchPrefix,
-		DocID:         *docID,
-		Credentials:   *credentialsPath,
-		LocalRepoPath: *localRepoPath,
-		DryRun:        *dryRun,
-		OutputDir:     *outputDir,
+		return err
 	}
+	cfg := stageConfig(d, p, e)
+	workflowInput.ShallowClone = c.shallowClone
+	workflowInput.SparsePaths = config.SplitList(c.sparsePaths)
+	workflowInput.DocID = docIDs[0]
+	workflowInput.LocalRepoPath = c.localRepoPath
+	workflowInput.DryRun = *dryRun
+	setWorkflowConfig(&workflowInput, cfg)
 
 	orch := orchestrator.NewOrchestrator()
 
+	// Several documents are processed one after the other, each with its own
+	// PR unless they go to a single one
+	if len(docIDs) > 1 {
+		execute := workflow.ExecuteBatch
+		if *singlePR {
+			execute = workflow.ExecuteCombined
+		}
+		batch, err := execute(context.Background(), workflowInput, docIDs, orch)
+		if err != nil {
+			return err
+		}
+		printBatchSummary(batch)
+		return nil
+	}
+
 	// Execute the complete workflow
 	result, err := workflow.ExecuteWorkflow(context.Background(), workflowInput, orch)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
-		os.Exit(1)
+		return err
+	}
+	printWorkflowResult(result)
+	return nil
+}
+
+// setWorkflowConfig sets the Bauer options of a workflow from cfg.
+func setWorkflowConfig(input *workflow.WorkflowInput, cfg *config.Config) {
+	input.Credentials = cfg.CredentialsPath
+	input.CredentialsMode = cfg.CredentialsMode
+	input.Resume = cfg.Resume
+	input.OutputDir = cfg.OutputDir
+	input.ChunkSize = cfg.ChunkSize
+	input.PageRefresh = cfg.PageRefresh
+	input.StaleCheck = cfg.StaleCheck
+	input.StateFile = cfg.StateFile
+	input.Reapply = cfg.Reapply
+	input.ChunkOrder = cfg.ChunkOrder
+	input.TemplateDir = cfg.TemplateDir
+	input.DirectApply = cfg.DirectApply
+	input.DirectThreshold = cfg.DirectThreshold
+	input.Triage = cfg.Triage
+	input.TriageRoutes = cfg.TriageRoutes
+	input.MaxChunkTokens = cfg.MaxChunkTokens
+	input.Model = cfg.Model
+	input.SummaryModel = cfg.SummaryModel
+	input.Executor = cfg.Executor
+	input.AllowedTools = cfg.AllowedTools
+	input.ExcludedTools = cfg.ExcludedTools
+	input.DenyShell = cfg.DenyShell
+	input.DenyNetwork = cfg.DenyNetwork
+	input.RestrictWrites = cfg.RestrictWrites
+	input.ChunkRetries = cfg.ChunkRetries
+	input.FallbackModel = cfg.FallbackModel
+	input.ValidateCommand = cfg.ValidateCommand
+	input.ValidateRetries = cfg.ValidateRetries
+	input.SkipTemplateCheck = cfg.SkipTemplateCheck
+	input.Approval = cfg.Approval
+	input.ApprovalWebhook = cfg.ApprovalWebhook
+	input.MaxSuggestions = cfg.MaxSuggestions
+	input.MaxChunks = cfg.MaxChunks
+	input.MaxCopilotDuration = cfg.MaxCopilotDuration
+	input.MaxFilesModified = cfg.MaxFilesModified
+	input.MaxLinesChanged = cfg.MaxLinesChanged
+	input.UnexpectedChanges = cfg.UnexpectedChanges
+	input.ProtectedPaths = cfg.ProtectedPaths
+	input.APIMaxAttempts = cfg.APIMaxAttempts
+	input.NoCache = cfg.NoCache
+	input.Sites = cfg.Sites
+	input.DumpRaw = cfg.DumpRaw
+	input.Replay = cfg.Replay
+	input.IncludeComments = cfg.IncludeComments
+	input.OnlyAuthors = cfg.OnlyAuthors
+	input.Since = cfg.Since
+	input.SuggestionIDs = cfg.SuggestionIDs
+	input.AnchorLength = cfg.AnchorLength
+	input.AnchorBoundary = cfg.AnchorBoundary
+	input.Normalize = cfg.Normalize
+	input.MergeSentences = cfg.MergeSentences
+	input.ConflictStrategy = cfg.ConflictStrategy
+	input.Source = cfg.Source
+	input.File = cfg.File
+	input.Before = cfg.Before
+	input.BeforeRevision = cfg.BeforeRevision
+	input.AfterRevision = cfg.AfterRevision
+	input.Tab = cfg.Tab
+}
+
+// workflowInput returns the GitHub and pull request options of a workflow,
+// with the GitHub token of the repository's host.
+func (p *prFlags) workflowInput() (workflow.WorkflowInput, error) {
+	var handles github.HandleMap
+	if p.reviewerMap != "" {
+		var err error
+		handles, err = github.LoadHandleMap(p.reviewerMap)
+		if err != nil {
+			return workflow.WorkflowInput{}, err
+		}
 	}
 
-	// Print results
+	ghToken, err := github.GetGitHubToken(githubHostOf(p.githubRepo, p.githubHost))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: Could not get GitHub token: %v\n", err)
+		ghToken = ""
+	}
+
+	return workflow.WorkflowInput{
+		GitHubRepo:   p.githubRepo,
+		GitHubToken:  ghToken,
+		GitHubHost:   p.githubHost,
+		BranchPrefix: p.branchPrefix,
+
+		SkipCodeOwnerReviews: p.skipCodeOwners,
+		Reviewers:            config.SplitList(p.reviewers),
+		ReviewerMap:          handles,
+		Assignees:            config.SplitList(p.assignees),
+		Labels:               config.SplitList(p.labels),
+		Milestone:            p.milestone,
+		CommentOnDoc:         p.commentOnDoc,
+		ScreenshotServer:     p.screenshotServer,
+		ScreenshotURL:        p.screenshotURL,
+		ScreenshotBrowser:    p.screenshotBrowser,
+	}, nil
+}
+
+// printWorkflowResult prints the outcome of a workflow run
+func printWorkflowResult(result *workflow.WorkflowOutput) {
 	fmt.Printf("Status: %s\n", result.Status)
 	fmt.Printf("Branch: %s\n", result.RepositoryInfo.BranchName)
-	fmt.Printf("PR: %s\n", result.FinalizationInfo.PullRequest.URL)
+	if result.FinalizationInfo.PullRequest.Updated {
+		fmt.Printf("PR: %s (updated)\n", result.FinalizationInfo.PullRequest.URL)
+	} else {
+		fmt.Printf("PR: %s\n", result.FinalizationInfo.PullRequest.URL)
+	}
+	if result.BauerResult.RunID != "" {
+		fmt.Printf("Run: %s\n", result.BauerResult.RunID)
+	}
+	if result.BauerResult.ReportPath != "" {
+		fmt.Printf("Report: %s\n", result.BauerResult.ReportPath)
+	}
+	fmt.Printf("Suggestions: %d\n", result.BauerResult.TotalSuggestions)
+	for _, status := range ledger.Statuses {
+		if count := result.BauerResult.SuggestionStatus[status]; count > 0 {
+			fmt.Printf("  %s: %d\n", status, count)
+		}
+	}
+	for _, entry := range result.BauerResult.Suggestions {
+		if entry.Status == ledger.StatusUnverified {
+			fmt.Printf("Needs review: %s (%s)\n", entry.SuggestionID, entry.Note)
+		}
+	}
+	if usage := result.BauerResult.Usage; usage != nil {
+		fmt.Printf("Token usage: %s\n", usage)
+		for _, chunk := range result.BauerResult.ChunkUsage {
+			fmt.Printf("  chunk %d: %s\n", chunk.ChunkNumber, chunk.Usage)
+		}
+	}
+	if stats := result.BauerResult.Stats; stats != nil && stats.Total > 0 {
+		fmt.Printf("Suggestion stats:\n%s", stats.Summary())
+	}
+	if validation := result.BauerResult.Validation; validation != nil && !validation.Passed {
+		fmt.Printf("Validation failed, changes not pushed: %s\n%s\n", validation.Command, validation.Output)
+	}
+	if damage := result.BauerResult.TemplateDamage; len(damage) > 0 {
+		fmt.Println("Templates damaged, changes not pushed:")
+		for _, d := range damage {
+			for _, problem := range d.Problems {
+				fmt.Printf("  chunk %d, %s: %s\n", d.Chunk, d.File, problem)
+			}
+		}
+	}
+	if len(result.FinalizationInfo.Reviewers) > 0 {
+		fmt.Printf("Reviewers: %s\n", strings.Join(result.FinalizationInfo.Reviewers, ", "))
+	}
+}
+
+// printBatchSummary prints one line per document of a batch run
+func printBatchSummary(batch *workflow.BatchOutput) {
+	fmt.Printf("Status: %s (%d documents)\n", batch.Status, len(batch.Documents))
+	if batch.SinglePR {
+		fmt.Printf("Branch: %s\n", batch.BranchName)
+		if batch.PullRequestURL != "" {
+			fmt.Printf("PR: %s\n", batch.PullRequestURL)
+		}
+	}
+	for _, doc := range batch.Documents {
+		fmt.Printf("  %s: %s, %d suggestions", doc.DocID, doc.Status, doc.TotalSuggestions)
+		if doc.PullRequestURL != "" && !batch.SinglePR {
+			fmt.Printf(", PR: %s", doc.PullRequestURL)
+		}
+		fmt.Println()
+		for _, e := range doc.Errors {
+			fmt.Printf("    error: %s\n", e)
+		}
+	}
+	fmt.Printf("Summary: %s\n", filepath.Join(batch.OutputDir, workflow.BatchSummaryFile))
+}
+
+// fileDocID derives a document ID from a file name, e.g. "Q3 copy.docx" -> "Q3-copy"
+func fileDocID(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// githubHostOf returns the host of the repository: the host of its URL, or
+// the --github-host one for "owner/repo"
+func githubHostOf(repo, host string) string {
+	parsed, err := github.ParseGitHubRepo(repo, github.ResolveHost(host))
+	if err != nil {
+		return github.ResolveHost(host)
+	}
+	return parsed.Host
 }
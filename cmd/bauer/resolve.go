@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bauer/internal/gdocs"
+	"bauer/internal/github"
+	"bauer/internal/ledger"
+	"bauer/internal/orchestrator"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// runResolve implements `bauer resolve --pr <url> [--ledger <path>]`.
+// Once the PR has merged, the suggestions it applied are accepted in the Google Doc.
+func runResolve(args []string) error {
+	fs := flag.NewFlagSet("resolve", flag.ExitOnError)
+	pr := fs.String("pr", "", "URL or number of the Bauer PR (required)")
+	ledgerPath := fs.String("ledger", "bauer-output", "Suggestion status ledger, or the output directory containing it")
+	credentialsPath := fs.String("credentials", "bau-test-creds.json", "Path to service account credentials JSON (needs edit access to the doc)")
+	credentialsMode := fs.String("credentials-mode", "file", "Where Google credentials come from: file, env, adc, workload-identity or user")
+	force := fs.Bool("force", false, "Resolve suggestions even if the PR isn't merged")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s resolve --pr <url> [--ledger <path>] [--credentials <path>]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *pr == "" {
+		fs.Usage()
+		return fmt.Errorf("--pr is required")
+	}
+
+	if !*force {
+		state, err := github.GetPRState(*pr)
+		if err != nil {
+			return err
+		}
+		if state != "MERGED" {
+			return fmt.Errorf("PR %s is not merged yet (state: %s)", *pr, state)
+		}
+	}
+
+	path := *ledgerPath
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, ledger.LedgerFile)
+	}
+	statusLedger, err := ledger.Load(path)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client, err := gdocs.NewWriteClient(ctx, gdocs.ClientOptions{
+		Mode:            gdocs.CredentialsMode(*credentialsMode),
+		CredentialsPath: *credentialsPath,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize Google Docs client: %w", err)
+	}
+
+	resolved, errs := orchestrator.ResolveMergedSuggestions(ctx, client, statusLedger)
+
+	// Save even on partial failure so resolved suggestions aren't accepted twice
+	if err := statusLedger.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Accepted %d suggestions in document %s\n", resolved, statusLedger.DocumentID)
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "  failed: %v\n", err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d suggestions could not be accepted", len(errs))
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bauer/internal/github"
+	"bauer/internal/ledger"
+	"bauer/internal/orchestrator"
+	"bauer/internal/state"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runRollback implements `bauer rollback --run <id>`, which undoes what a run
+// did on GitHub: its pull request is closed and its branch deleted. Runs that
+// updated the pull request of an earlier run aren't rolled back, as that
+// would undo the earlier run too; rolling back the run that opened it undoes
+// every run that went to it.
+func runRollback(args []string) error {
+	fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+	run := fs.String("run", "", "ID of the run, or its report or output directory (required)")
+	outputDir := fs.String("output-dir", "bauer-output", "Output directory the report of a run ID is looked up in")
+	localRepoPath := fs.String("local-repo-path", "", "Clone the run's branch is also deleted from")
+	reopen := fs.Bool("reopen", false, "Reopen the run's suggestions in its status ledger, for a later run to track")
+	stateFile := fs.String("state-file", defaultStateFile(), "State file the run's suggestions are forgotten in, for later runs to apply again")
+	dryRun := fs.Bool("dry-run", false, "Print what would be rolled back, without changing anything")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s rollback --run <id|report> [--local-repo-path <dir>] [--reopen]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *run == "" {
+		fs.Usage()
+		return fmt.Errorf("--run is required")
+	}
+
+	report, reportDir, err := findRunReport(*run, *outputDir)
+	if err != nil {
+		return err
+	}
+	git := report.Git
+	if git == nil || git.Branch == "" {
+		return fmt.Errorf("run %s made no branch to roll back", report.RunID)
+	}
+	if git.RolledBackAt != nil {
+		return fmt.Errorf("run %s was already rolled back at %s", report.RunID, git.RolledBackAt.Format(time.RFC3339))
+	}
+	if git.PullRequestUpdated {
+		return fmt.Errorf("run %s updated PR %s, which an earlier run opened: revert its commit on %s, or roll back the run that opened it",
+			report.RunID, git.PullRequestURL, git.Branch)
+	}
+	parts := strings.SplitN(git.Repository, "/", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid repository in report: %s", git.Repository)
+	}
+	host, owner, repo := parts[0], parts[1], parts[2]
+
+	if git.PullRequestURL != "" {
+		prState, err := github.GetPRState(git.PullRequestURL)
+		if err != nil {
+			return err
+		}
+		if prState == "MERGED" {
+			return fmt.Errorf("PR %s is merged; revert it instead", git.PullRequestURL)
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("Run %s of document %s would be rolled back:\n", report.RunID, report.DocumentID)
+		if git.PullRequestURL != "" {
+			fmt.Printf("  close PR %s\n", git.PullRequestURL)
+		}
+		if git.Pushed {
+			fmt.Printf("  delete branch %s of %s\n", git.Branch, git.Repository)
+		}
+		if *localRepoPath != "" {
+			fmt.Printf("  delete branch %s of %s\n", git.Branch, *localRepoPath)
+		}
+		if *reopen {
+			fmt.Printf("  reopen the suggestions of %s\n", filepath.Join(reportDir, ledger.LedgerFile))
+		}
+		if *stateFile != "" && git.PullRequestURL != "" {
+			fmt.Printf("  forget the suggestions of every run of PR %s in %s\n", git.PullRequestURL, *stateFile)
+		} else if *stateFile != "" {
+			fmt.Printf("  forget the suggestions of the run in %s\n", *stateFile)
+		}
+		return nil
+	}
+
+	// Each step is tried, so a failed one can be retried alone
+	var errs []error
+	if git.PullRequestURL != "" && git.PullRequestNumber > 0 {
+		comment := fmt.Sprintf("Closed by `bauer rollback` of run `%s`.", report.RunID)
+		if err := github.ClosePR(host, owner, repo, git.PullRequestNumber, comment); err != nil {
+			errs = append(errs, err)
+		} else {
+			fmt.Printf("Closed PR %s\n", git.PullRequestURL)
+		}
+	}
+	if git.Pushed {
+		if err := github.DeleteRemoteBranch(host, owner, repo, git.Branch); err != nil {
+			errs = append(errs, err)
+		} else {
+			fmt.Printf("Deleted branch %s of %s\n", git.Branch, git.Repository)
+		}
+	}
+	if *localRepoPath != "" {
+		if err := github.RemoveLocalBranch(*localRepoPath, git.Branch, git.BaseBranch); err != nil {
+			errs = append(errs, err)
+		} else {
+			fmt.Printf("Deleted branch %s of %s\n", git.Branch, *localRepoPath)
+		}
+	}
+	if *reopen {
+		ledgerPath := filepath.Join(reportDir, ledger.LedgerFile)
+		statusLedger, err := ledger.Load(ledgerPath)
+		if err == nil {
+			reopened := statusLedger.Reopen("reopened by rollback of run " + report.RunID)
+			err = statusLedger.Save(ledgerPath)
+			fmt.Printf("Reopened %d suggestions\n", reopened)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if *stateFile != "" {
+		store, err := state.Open(*stateFile)
+		if err == nil {
+			// Later runs that updated the PR go with it
+			forgotten := store.Reopen(report.DocumentID, report.RunID)
+			if git.PullRequestURL != "" {
+				forgotten += store.ReopenPullRequest(git.PullRequestURL)
+			}
+			err = store.Save()
+			fmt.Printf("Forgot %d suggestions in %s\n", forgotten, *stateFile)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("rollback of run %s incomplete: %w", report.RunID, errors.Join(errs...))
+	}
+
+	now := time.Now().UTC()
+	git.RolledBackAt = &now
+	return report.Save(reportDir)
+}
+
+// findRunReport returns the report of a run and the directory it's in. run is
+// a report file, the output directory of a run, or the ID of the run whose
+// report is in outputDir.
+func findRunReport(run, outputDir string) (*orchestrator.Report, string, error) {
+	if info, err := os.Stat(run); err == nil {
+		report, err := orchestrator.LoadReport(run)
+		if err != nil {
+			return nil, "", err
+		}
+		dir := run
+		if !info.IsDir() {
+			dir = filepath.Dir(run)
+		}
+		return report, dir, nil
+	}
+
+	report, err := orchestrator.LoadReport(outputDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("run %s not found: %w", run, err)
+	}
+	if report.RunID != run {
+		return nil, "", fmt.Errorf("run %s not found: the report in %s is of run %s", run, outputDir, report.RunID)
+	}
+	return report, outputDir, nil
+}
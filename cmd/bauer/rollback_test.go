@@ -0,0 +1,64 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bauer/internal/ledger"
+	"bauer/internal/orchestrator"
+	"bauer/internal/state"
+)
+
+func TestRollbackUpdatedPR(t *testing.T) {
+	dir := t.TempDir()
+	stateFile := filepath.Join(dir, "state.json")
+	store, err := state.Open(stateFile)
+	if err != nil {
+		t.Fatalf("state.Open() error = %v", err)
+	}
+	l := ledger.New("doc-1")
+	l.Set("s1", ledger.StatusApplied, "")
+	store.Update(l, "run-2")
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// The run updated the PR an earlier run opened
+	report := &orchestrator.Report{
+		RunID:      "run-2",
+		DocumentID: "doc-1",
+		Git: &orchestrator.GitReport{
+			Repository:         "github.com/o/r",
+			Branch:             "bauer/doc-1",
+			Pushed:             true,
+			PullRequestURL:     "https://github.com/o/r/pull/1",
+			PullRequestNumber:  1,
+			PullRequestUpdated: true,
+		},
+	}
+	if err := report.Save(dir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Refused before GitHub is reached, so nothing of the earlier run is
+	// closed or deleted
+	err = runRollback([]string{"--run", dir, "--state-file", stateFile})
+	if err == nil || !strings.Contains(err.Error(), "which an earlier run opened") {
+		t.Fatalf("runRollback() error = %v, want the updated PR refused", err)
+	}
+	store, err = state.Open(stateFile)
+	if err != nil {
+		t.Fatalf("state.Open() error = %v", err)
+	}
+	if store.Get("doc-1", "s1") == nil {
+		t.Error("Expected the suggestions of the refused run to stay recorded")
+	}
+	loaded, err := orchestrator.LoadReport(dir)
+	if err != nil {
+		t.Fatalf("LoadReport() error = %v", err)
+	}
+	if loaded.Git.RolledBackAt != nil {
+		t.Error("Expected the refused run not to be marked rolled back")
+	}
+}
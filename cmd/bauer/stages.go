@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bauer/cmd/app/server"
+	"bauer/internal/config"
+	"bauer/internal/ledger"
+	"bauer/internal/logging"
+	"bauer/internal/orchestrator"
+	"bauer/internal/workflow"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+)
+
+// The stage commands run one stage of the workflow each, handing over
+// through the files in the work and output directories: extract writes
+// bauer-doc-suggestions.json, which plan and apply take with --extraction,
+// and apply leaves the changes, ledger and report that pr publishes.
+
+// runExtract implements `bauer extract --doc-id <id>`.
+func runExtract(args []string) error {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	d := addDocumentFlags(fs)
+	outputDir := fs.String("output-dir", "bauer-output", "Output directory for the preview, snapshot and suggestion status")
+	logOptions := addLogFlags(fs)
+	configPath := addConfigFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s extract --doc-id <id> [--credentials <path>] [--output-dir <dir>]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "The suggestions are written to %s in the current directory.\n\n", orchestrator.ExtractionResultFile)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := applyDefaults(fs, *configPath, ""); err != nil {
+		return err
+	}
+	if err := d.resolve(true); err != nil {
+		fs.Usage()
+		return err
+	}
+
+	cfg := stageConfig(d, nil, nil)
+	cfg.OutputDir = *outputDir
+	closeLog, err := setupStage(cfg, *logOptions)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	result, err := orchestrator.NewOrchestrator().Extract(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	extraction := result.ExtractionResult
+	fmt.Printf("Document: %s (%s)\n", extraction.DocumentTitle, extraction.DocumentID)
+	fmt.Printf("Suggestions: %d at %d locations\n", len(extraction.ActionableSuggestions), len(extraction.GroupedSuggestions))
+	if stats := extraction.Stats; stats != nil && stats.Total > 0 {
+		fmt.Printf("Suggestion stats:\n%s", stats.Summary())
+	}
+	fmt.Printf("Extraction: %s\n", orchestrator.ExtractionResultFile)
+	return nil
+}
+
+// runPlan implements `bauer plan`, which writes the chunk prompts and
+// manifest of a document, or of an extraction, without applying them.
+func runPlan(args []string) error {
+	fs := flag.NewFlagSet("plan", flag.ExitOnError)
+	d := addDocumentFlags(fs)
+	extraction := fs.String("extraction", "", "Plan from this "+orchestrator.ExtractionResultFile+", e.g. of `bauer extract`, instead of extracting the document")
+	localRepoPath := fs.String("local-repo-path", ".", "Repository the files of the suggestions are found in")
+	p := addPlanFlags(fs)
+	logOptions := addLogFlags(fs)
+	configPath := addConfigFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s plan (--doc-id <id> | --extraction <file>) [--output-dir <dir>]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := applyDefaults(fs, *configPath, "local-repo-path"); err != nil {
+		return err
+	}
+	if err := d.resolve(*extraction == ""); err != nil {
+		fs.Usage()
+		return fmt.Errorf("%w, or --extraction", err)
+	}
+
+	cfg := stageConfig(d, p, nil)
+	cfg.Extraction = *extraction
+	cfg.TargetRepo = *localRepoPath
+	cfg.DryRun = true
+	closeLog, err := setupStage(cfg, *logOptions)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	result, err := orchestrator.NewOrchestrator().Execute(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Chunks: %d\n", len(result.Chunks))
+	for _, chunk := range result.Chunks {
+		fmt.Printf("  %s: %d locations, %d suggestions\n", chunk.Filename, chunk.LocationCount, len(chunk.SuggestionIDs))
+	}
+	printReportPath(cfg)
+	return nil
+}
+
+// runApply implements `bauer apply`, which applies the suggestions of a
+// document, or of an extraction, to a local repository without committing.
+func runApply(args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	d := addDocumentFlags(fs)
+	extraction := fs.String("extraction", "", "Apply this "+orchestrator.ExtractionResultFile+", e.g. of `bauer extract`, instead of extracting the document")
+	localRepoPath := fs.String("local-repo-path", ".", "Repository the changes are made in; --output-dir is relative to it")
+	p := addPlanFlags(fs)
+	e := addExecutionFlags(fs)
+	logOptions := addLogFlags(fs)
+	configPath := addConfigFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s apply (--doc-id <id> | --extraction <file>) [--local-repo-path <dir>]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := applyDefaults(fs, *configPath, "local-repo-path"); err != nil {
+		return err
+	}
+	if err := d.resolve(*extraction == ""); err != nil {
+		fs.Usage()
+		return fmt.Errorf("%w, or --extraction", err)
+	}
+
+	cfg := stageConfig(d, p, e)
+	cfg.Extraction = *extraction
+	cfg.WorkDir = *localRepoPath
+	cfg.TargetRepo = *localRepoPath
+	closeLog, err := setupStage(cfg, *logOptions)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	result, err := orchestrator.NewOrchestrator().Execute(ctx, cfg)
+	if result == nil {
+		return err
+	}
+
+	fmt.Printf("Suggestions: %d (%s)\n", len(result.Ledger.Entries), result.Ledger.Summary())
+	for _, entry := range result.Ledger.WithStatus(ledger.StatusUnverified) {
+		fmt.Printf("Needs review: %s (%s)\n", entry.SuggestionID, entry.Note)
+	}
+	if !result.Usage.IsZero() {
+		fmt.Printf("Token usage: %s\n", result.Usage)
+	}
+	if validation := result.Validation; validation != nil && !validation.Passed {
+		fmt.Printf("Validation failed: %s\n%s\n", validation.Command, validation.Output)
+	}
+	printReportPath(cfg)
+	return err
+}
+
+// runPR implements `bauer pr --github-repo <repo>`, which commits and pushes
+// the changes `bauer apply` made in a local repository and opens, or
+// updates, their pull request.
+func runPR(args []string) error {
+	fs := flag.NewFlagSet("pr", flag.ExitOnError)
+	pf := addPRFlags(fs)
+	localRepoPath := fs.String("local-repo-path", ".", "Repository holding the changes")
+	outputDir := fs.String("output-dir", "bauer-output", "Output directory of the apply run, relative to the repository")
+	extraction := fs.String("extraction", "", "Extraction of the apply run (default: "+orchestrator.ExtractionResultFile+" in the repository)")
+	credentialsPath := fs.String("credentials", "bau-test-creds.json", "Path to service account credentials JSON, with --comment-on-doc")
+	credentialsMode := fs.String("credentials-mode", "file", "Where Google credentials come from: file, env, adc, workload-identity or user")
+	dryRun := fs.Bool("dry-run", false, "Push the branch without creating the PR")
+	var sitesValue string
+	addSitesFlag(fs, &sitesValue)
+	logOptions := addLogFlags(fs)
+	configPath := addConfigFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s pr --github-repo <repo> [--local-repo-path <dir>] [--output-dir <dir>]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := applyDefaults(fs, *configPath, "local-repo-path"); err != nil {
+		return err
+	}
+	table, err := parseSites(sitesValue)
+	if err != nil {
+		return err
+	}
+	if pf.githubRepo == "" && len(table) == 0 {
+		fs.Usage()
+		return fmt.Errorf("--github-repo, or --sites to find it by, is required")
+	}
+
+	runOutputDir := *outputDir
+	if !filepath.IsAbs(runOutputDir) {
+		runOutputDir = filepath.Join(*localRepoPath, runOutputDir)
+	}
+	extractionFile := *extraction
+	if extractionFile == "" {
+		extractionFile = filepath.Join(*localRepoPath, orchestrator.ExtractionResultFile)
+	}
+	result, err := orchestrator.LoadResult(extractionFile, runOutputDir)
+	if err != nil {
+		return fmt.Errorf("failed to load the apply run: %w", err)
+	}
+
+	closeLog, err := logging.Setup(*logOptions, result.ExtractionResult.DocumentID)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	input, err := pf.workflowInput()
+	if err != nil {
+		return err
+	}
+	input.DocID = result.ExtractionResult.DocumentID
+	input.LocalRepoPath = *localRepoPath
+	input.OutputDir = *outputDir
+	input.Credentials = *credentialsPath
+	input.CredentialsMode = *credentialsMode
+	input.DryRun = *dryRun
+	input.Sites = table
+
+	output, err := workflow.PublishChanges(context.Background(), input, result)
+	if errors.Is(err, orchestrator.ErrValidationFailed) {
+		return fmt.Errorf("the changes failed validation and are not published: %w", err)
+	}
+	if errors.Is(err, orchestrator.ErrLimitExceeded) {
+		return fmt.Errorf("the run went over a limit and its changes are not published: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+	printWorkflowResult(output)
+	return nil
+}
+
+// runServe implements `bauer serve`, the API server; it takes the flags of
+// bauer-api.
+func runServe(args []string) error {
+	return server.Run(args)
+}
+
+// setupStage validates the configuration of a stage command and sets up its
+// logs, named after the document.
+func setupStage(cfg *config.Config, logOptions logging.Options) (func() error, error) {
+	if cfg.Extraction != "" {
+		abs, err := filepath.Abs(cfg.Extraction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve extraction path: %w", err)
+		}
+		cfg.Extraction = abs
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	run := cfg.DocID
+	if run == "" {
+		run = "extraction"
+	}
+	return logging.Setup(logOptions, run)
+}
+
+// printReportPath prints where the run report of a stage was written.
+func printReportPath(cfg *config.Config) {
+	resolved, err := cfg.Resolved()
+	if err != nil {
+		return
+	}
+	fmt.Printf("Report: %s\n", filepath.Join(resolved.OutputDir, orchestrator.ReportMarkdownFile))
+}
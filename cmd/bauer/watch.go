@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bauer/internal/gdocs"
+	"bauer/internal/github"
+	"bauer/internal/orchestrator"
+	"bauer/internal/watch"
+	"bauer/internal/workflow"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// runWatch implements `bauer watch --github-repo <repo> --doc-id <id>`.
+// The document is polled and the workflow runs whenever it gains new suggestions.
+func runWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	githubRepo := fs.String("github-repo", "", "GitHub repository (owner/repo or HTTPS URL) (required)")
+	githubHost := fs.String("github-host", "", "GitHub Enterprise Server host of --github-repo (default: from GITHUB_API_URL, else github.com)")
+	docID := fs.String("doc-id", "", "Google Doc ID or URL to watch (required)")
+	credentialsPath := fs.String("credentials", "bau-test-creds.json", "Path to service account credentials JSON")
+	credentialsMode := fs.String("credentials-mode", "file", "Where Google credentials come from: file, env, adc, workload-identity or user")
+	localRepoPath := fs.String("local-repo-path", "", "Local path for cloned repository (default: named after the repository, in the temporary directory)")
+	outputDir := fs.String("output-dir", "bauer-output", "Output directory for Bauer results")
+	branchPrefix := fs.String("branch-prefix", "bauer", "Branch naming prefix")
+	dryRun := fs.Bool("dry-run", false, "Perform dry runs without creating PRs")
+	interval := fs.Duration("interval", 5*time.Minute, "Time between polls")
+	statePath := fs.String("state", "", "State file keeping the last seen suggestions (default: <output-dir>/"+watch.StateFile+")")
+	once := fs.Bool("once", false, "Poll once and exit, e.g. when run from cron")
+	configPath := addConfigFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s watch --github-repo <repo> --doc-id <id> [--interval 5m] [--once]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := applyDefaults(fs, *configPath, "local-repo-path"); err != nil {
+		return err
+	}
+
+	if *githubRepo == "" || *docID == "" {
+		fs.Usage()
+		return fmt.Errorf("--github-repo and --doc-id are required")
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("--interval must be greater than 0")
+	}
+
+	id, err := gdocs.ParseDocumentID(*docID)
+	if err != nil {
+		return err
+	}
+	if *localRepoPath == "" {
+		*localRepoPath = workflow.DefaultLocalRepoPath(*githubRepo, *githubHost)
+	}
+	if *statePath == "" {
+		*statePath = filepath.Join(*outputDir, watch.StateFile)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := gdocs.NewClient(ctx, gdocs.ClientOptions{
+		Mode:            gdocs.CredentialsMode(*credentialsMode),
+		CredentialsPath: *credentialsPath,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize Google Docs client: %w", err)
+	}
+
+	ghToken, err := github.GetGitHubToken(githubHostOf(*githubRepo, *githubHost))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: Could not get GitHub token: %v\n", err)
+	}
+
+	orch := orchestrator.NewOrchestrator()
+	watcher := &watch.Watcher{
+		DocID:     id,
+		Interval:  *interval,
+		StatePath: *statePath,
+		Fetch:     client.FetchSuggestionIDs,
+		Run: func(ctx context.Context, docID string) error {
+			input := workflow.WorkflowInput{
+				GitHubRepo:   *githubRepo,
+				GitHubToken:  ghToken,
+				GitHubHost:   *githubHost,
+				BranchPrefix: *branchPrefix,
+				DocID:        docID,
+				Credentials:  *credentialsPath,
+				// Each run clones into its own directory, as the API does
+				LocalRepoPath:   fmt.Sprintf("%s-%d", *localRepoPath, time.Now().Unix()),
+				DryRun:          *dryRun,
+				OutputDir:       *outputDir,
+				CredentialsMode: *credentialsMode,
+			}
+			result, err := workflow.ExecuteWorkflow(ctx, input, orch)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Status: %s, PR: %s\n", result.Status, result.FinalizationInfo.PullRequest.URL)
+			return nil
+		},
+	}
+
+	if *once {
+		ran, err := watcher.Poll(ctx)
+		if err != nil {
+			return err
+		}
+		if !ran {
+			fmt.Println("No new suggestions")
+		}
+		return nil
+	}
+
+	fmt.Printf("Watching document %s every %s (Ctrl+C to stop)\n", id, *interval)
+	return watcher.Watch(ctx)
+}
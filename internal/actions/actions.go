@@ -0,0 +1,120 @@
+// Package actions runs Bauer from GitHub Actions: the document comes from the
+// event that triggered the workflow, and the outcome goes to the job summary
+// and the step outputs.
+package actions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"bauer/internal/gdocs"
+)
+
+// Event is the part of the webhook payload of the triggering event Bauer
+// reads: the inputs of a workflow_dispatch, or the issue of an issues or
+// issue_comment event.
+type Event struct {
+	Inputs map[string]any `json:"inputs"`
+	Issue  *struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		Body   string `json:"body"`
+	} `json:"issue"`
+}
+
+// docInputs are the names of the workflow inputs the document is read from.
+var docInputs = []string{"doc-id", "doc_id", "doc"}
+
+var (
+	docURLPattern  = regexp.MustCompile(`https://docs\.google\.com/document/(?:u/\d+/)?d/[a-zA-Z0-9_-]+`)
+	docLinePattern = regexp.MustCompile(`(?im)^\s*doc[-_ ]?id\s*:\s*(\S+)`)
+)
+
+// LoadEvent reads the event file, $GITHUB_EVENT_PATH in a workflow.
+func LoadEvent(path string) (*Event, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event file: %w", err)
+	}
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse event file %s: %w", path, err)
+	}
+	return &event, nil
+}
+
+// DocumentID returns the document of the event: a doc-id input, or the first
+// Google Docs URL or "Doc ID: <id>" line of the issue.
+func (e *Event) DocumentID() (string, error) {
+	for _, name := range docInputs {
+		if value, ok := e.Inputs[name].(string); ok && strings.TrimSpace(value) != "" {
+			return gdocs.ParseDocumentID(value)
+		}
+	}
+	if e.Issue != nil {
+		for _, text := range []string{e.Issue.Body, e.Issue.Title} {
+			if url := docURLPattern.FindString(text); url != "" {
+				return gdocs.ParseDocumentID(url)
+			}
+			if match := docLinePattern.FindStringSubmatch(text); match != nil {
+				return gdocs.ParseDocumentID(match[1])
+			}
+		}
+	}
+	return "", fmt.Errorf("no document in the event: set the doc-id input, or link the document in the issue")
+}
+
+// SetOutput sets a step output, appending it to $GITHUB_OUTPUT. It does
+// nothing outside of GitHub Actions.
+func SetOutput(name, value string) error {
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return nil
+	}
+	line := name + "=" + value + "\n"
+	if strings.Contains(value, "\n") {
+		delimiter, err := newDelimiter()
+		if err != nil {
+			return err
+		}
+		line = fmt.Sprintf("%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter)
+	}
+	return appendFile(path, line)
+}
+
+// WriteSummary appends markdown to the job summary, $GITHUB_STEP_SUMMARY. It
+// does nothing outside of GitHub Actions.
+func WriteSummary(markdown string) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+	return appendFile(path, markdown+"\n")
+}
+
+// newDelimiter returns a random heredoc delimiter, which values can't contain
+// by chance.
+func newDelimiter() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate output delimiter: %w", err)
+	}
+	return "ghadelimiter_" + hex.EncodeToString(b), nil
+}
+
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
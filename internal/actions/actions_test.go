@@ -0,0 +1,68 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEvent_DocumentID(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   string
+		want    string
+		wantErr bool
+	}{
+		{name: "dispatch input", event: `{"inputs": {"doc-id": "https://docs.google.com/document/d/abc123/edit"}}`, want: "abc123"},
+		{name: "underscore input", event: `{"inputs": {"doc_id": "abc123"}}`, want: "abc123"},
+		{name: "issue link", event: `{"issue": {"number": 4, "body": "Copy update for the homepage:\nhttps://docs.google.com/document/d/xyz_789/edit?usp=sharing"}}`, want: "xyz_789"},
+		{name: "issue line", event: `{"issue": {"title": "BAU", "body": "Doc ID: def456"}}`, want: "def456"},
+		{name: "nothing", event: `{"issue": {"body": "Please update the homepage"}}`, wantErr: true},
+		{name: "schedule", event: `{"schedule": "0 6 * * 1"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "event.json")
+			if err := os.WriteFile(path, []byte(tt.event), 0644); err != nil {
+				t.Fatal(err)
+			}
+			event, err := LoadEvent(path)
+			if err != nil {
+				t.Fatalf("LoadEvent() error = %v", err)
+			}
+			got, err := event.DocumentID()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DocumentID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DocumentID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITHUB_OUTPUT", path)
+
+	if err := SetOutput("pr_url", "https://github.com/o/r/pull/1"); err != nil {
+		t.Fatalf("SetOutput() error = %v", err)
+	}
+	if err := SetOutput("errors", "one\ntwo"); err != nil {
+		t.Fatalf("SetOutput() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 5 || lines[0] != "pr_url=https://github.com/o/r/pull/1" {
+		t.Fatalf("Unexpected outputs:\n%s", data)
+	}
+	delimiter := strings.TrimPrefix(lines[1], "errors<<")
+	if delimiter == lines[1] || lines[2] != "one" || lines[3] != "two" || lines[4] != delimiter {
+		t.Errorf("Unexpected multiline output:\n%s", data)
+	}
+}
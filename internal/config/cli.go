@@ -15,15 +15,48 @@ func Load() (*Config, error) {
 	// but standard `flag` usage usually assumes run once per process.
 
 	docID := flag.String("doc-id", "", "Google Doc ID to extract feedback from (required)")
+	docs := flag.String("docs", "", "Comma-separated list, or file, of Google Doc IDs/URLs to process in one run")
 	credentialsPath := flag.String("credentials", "", "Path to service account JSON (required)")
+	credentialsMode := flag.String("credentials-mode", "file", "Where Google credentials come from: file, env, adc, workload-identity or user (default: file)")
 	configFile := flag.String("config", "", "Path to JSON config file")
 	dryRun := flag.Bool("dry-run", false, "Run extraction and planning only; skip Copilot and PR creation")
+	resume := flag.Bool("resume", false, "Skip the chunks the previous run in --output-dir completed; retry failed and pending ones")
 	chunkSize := flag.Int("chunk-size", 0, "Total number of chunks to create (default: 1, or 5 if --page-refresh is set)")
 	pageRefresh := flag.Bool("page-refresh", false, "Use page refresh mode with page-refresh-instructions template (default chunk size: 5)")
 	outputDir := flag.String("output-dir", "bauer-output", "Directory for generated prompt files (default: bauer-output)")
 	model := flag.String("model", "gpt-5-mini-high", "Copilot model to use for sessions (default: gpt-5-mini-high)")
 	summaryModel := flag.String("summary-model", "gpt-5-mini-high", "Copilot model to use for summary session (default: gpt-5-mini-high)")
+	executorName := flag.String("executor", "copilot", "LLM backend that applies the chunks: copilot or openai (default: copilot)")
+	allowedTools := flag.String("allowed-tools", "", "Only let Copilot sessions use these tools (comma-separated, e.g. view,edit)")
+	excludedTools := flag.String("excluded-tools", "", "Remove these tools from Copilot sessions (comma-separated)")
+	denyShell := flag.Bool("deny-shell", false, "Refuse shell commands in Copilot sessions")
+	denyNetwork := flag.Bool("deny-network", false, "Refuse URL fetches and MCP server calls in Copilot sessions")
+	restrictWrites := flag.Bool("restrict-writes", false, "Refuse Copilot file writes outside the target repository")
+	chunkRetries := flag.Int("chunk-retries", 0, "Retries of a chunk after a Copilot session error or timeout (default: 0)")
+	fallbackModel := flag.String("fallback-model", "", "Copilot model to use for the final retry of a chunk (default: --model)")
+	validateCommand := flag.String("validate-command", "", "Command checking the changes in the target repository, e.g. \"yarn build\"")
+	validateRetries := flag.Int("validate-retries", 2, "Sessions asked to fix the errors of a failing --validate-command")
+	skipTemplateCheck := flag.Bool("skip-template-check", false, "Don't check the HTML templates changed by each chunk for damage")
 	targetRepo := flag.String("target-repo", "", "Path to target repository where tasks should be executed (default: current directory)")
+	chunkOrder := flag.String("chunk-order", "position", "Order locations into chunks by position, difficulty or churn (default: position)")
+	templateDir := flag.String("template-dir", "", "Directory of templates replacing the built-in prompts: instructions.md.tmpl, summary.md.tmpl")
+	staleCheck := flag.String("stale-check", "", "Flag suggestions that no longer match the published page: http or repo (default: disabled)")
+	apiMaxAttempts := flag.Int("api-max-attempts", 5, "Attempts per Google API call when rate limited or failing (default: 5)")
+	noCache := flag.Bool("no-cache", false, "Always fetch the full document instead of reusing the cached copy of an unchanged revision")
+	dumpRaw := flag.Bool("dump-raw", false, "Write the raw document JSON next to bauer-doc-suggestions.json")
+	replay := flag.String("replay", "", "Build the run from a raw document saved with --dump-raw instead of fetching it")
+	onlyAuthor := flag.String("only-author", "", "Only process suggestions by these authors (comma-separated names or emails)")
+	since := flag.String("since", "", "Only process suggestions made since this date (YYYY-MM-DD) or RFC 3339 time")
+	suggestionIDs := flag.String("suggestion-ids", "", "Only process these suggestions (comma-separated IDs)")
+	anchorLength := flag.Int("anchor-length", 0, "Length of the text around each suggestion used to locate it (default: 80)")
+	anchorBoundary := flag.String("anchor-boundary", "", "Extend anchors to whole words or sentences: word or sentence (default: exact length)")
+	normalize := flag.String("normalize", "", "Normalize anchors to match rendered HTML: comma-separated nfc, quotes, whitespace or all (default: none)")
+	mergeSentences := flag.Bool("merge-sentences", false, "Combine suggestions in the same sentence into a single replacement")
+	conflictStrategy := flag.String("conflict-strategy", "largest", "Which overlapping suggestion wins: largest, newest, fail or interactive (default: largest)")
+	includeComments := flag.Bool("include-comments", false, "Treat unresolved comments on quoted text as suggestions")
+	source := flag.String("source", "gdocs", "Where the document comes from: gdocs, docx or diff (default: gdocs)")
+	file := flag.String("file", "", "Path to the .docx file (with --source docx), or the new page version (with --source diff)")
+	before := flag.String("before", "", "Old page version to diff against --file: a path or git:<revision>:<path> (with --source diff)")
 
 	// Custom usage message
 	flag.Usage = func() {
@@ -39,21 +72,54 @@ func Load() (*Config, error) {
 		}{
 			{"--config", "<string>", "Path to JSON config file"},
 			{"--doc-id", "<string>", "Google Doc ID to extract feedback from (required)"},
+			{"--docs", "<string>", "Comma-separated list, or file, of Google Doc IDs/URLs to process in one run"},
 			{"--credentials", "<string>", "Path to service account JSON (required)"},
+			{"--credentials-mode", "<string>", "Where Google credentials come from: file, env, adc, workload-identity or user (default: file)"},
 			{"--dry-run", "", "Run extraction and planning only; skip Copilot and PR creation"},
+			{"--resume", "", "Skip the chunks the previous run in --output-dir completed; retry failed and pending ones"},
 			{"--page-refresh", "", "Use page refresh mode with page-refresh-instructions template"},
 			{"--chunk-size", "<int>", "Total number of chunks to create (default: 1, or 5 if --page-refresh is set)"},
 			{"--output-dir", "<string>", "Directory for generated prompt files (default: bauer-output)"},
 			{"--model", "<string>", "Copilot model to use for sessions (default: gpt-5-mini-high)"},
 			{"--summary-model", "<string>", "Copilot model to use for summary session (default: gpt-5-mini-high)"},
+			{"--executor", "<string>", "LLM backend that applies the chunks: copilot or openai (default: copilot)"},
+			{"--allowed-tools", "<string>", "Only let Copilot sessions use these tools (comma-separated, e.g. view,edit)"},
+			{"--excluded-tools", "<string>", "Remove these tools from Copilot sessions (comma-separated)"},
+			{"--deny-shell", "", "Refuse shell commands in Copilot sessions"},
+			{"--deny-network", "", "Refuse URL fetches and MCP server calls in Copilot sessions"},
+			{"--restrict-writes", "", "Refuse Copilot file writes outside the target repository"},
+			{"--chunk-retries", "<int>", "Retries of a chunk after a Copilot session error or timeout (default: 0)"},
+			{"--fallback-model", "<string>", "Copilot model to use for the final retry of a chunk (default: --model)"},
+			{"--validate-command", "<string>", "Command checking the changes in the target repository, e.g. \"yarn build\" (default: none)"},
+			{"--validate-retries", "<int>", "Sessions asked to fix the errors of a failing --validate-command (default: 2)"},
+			{"--skip-template-check", "", "Don't check the HTML templates changed by each chunk for damage"},
 			{"--target-repo", "<string>", "Path to target repository where tasks should be executed (default: current directory)"},
+			{"--chunk-order", "<string>", "Order locations into chunks by position, difficulty or churn (default: position)"},
+			{"--template-dir", "<string>", "Directory of templates replacing the built-in prompts: instructions.md.tmpl, summary.md.tmpl"},
+			{"--stale-check", "<string>", "Flag suggestions that no longer match the published page: http or repo (default: disabled)"},
+			{"--api-max-attempts", "<int>", "Attempts per Google API call when rate limited or failing (default: 5)"},
+			{"--no-cache", "", "Always fetch the full document instead of reusing the cached copy of an unchanged revision"},
+			{"--dump-raw", "", "Write the raw document JSON next to bauer-doc-suggestions.json"},
+			{"--replay", "<string>", "Build the run from a raw document saved with --dump-raw instead of fetching it"},
+			{"--only-author", "<string>", "Only process suggestions by these authors (comma-separated names or emails)"},
+			{"--since", "<string>", "Only process suggestions made since this date (YYYY-MM-DD) or RFC 3339 time"},
+			{"--suggestion-ids", "<string>", "Only process these suggestions (comma-separated IDs)"},
+			{"--anchor-length", "<int>", "Length of the text around each suggestion used to locate it (default: 80)"},
+			{"--anchor-boundary", "<string>", "Extend anchors to whole words or sentences: word or sentence (default: exact length)"},
+			{"--normalize", "<string>", "Normalize anchors to match rendered HTML: comma-separated nfc, quotes, whitespace or all (default: none)"},
+			{"--merge-sentences", "", "Combine suggestions in the same sentence into a single replacement"},
+			{"--conflict-strategy", "<string>", "Which overlapping suggestion wins: largest, newest, fail or interactive (default: largest)"},
+			{"--include-comments", "", "Treat unresolved comments on quoted text as suggestions"},
+			{"--source", "<string>", "Where the document comes from: gdocs, docx or diff (default: gdocs)"},
+			{"--file", "<string>", "Path to the .docx file (with --source docx), or the new page version (with --source diff)"},
+			{"--before", "<string>", "Old page version to diff against --file: a path or git:<revision>:<path> (with --source diff)"},
 		}
 
 		for _, f := range flags {
 			if f.typ != "" {
-				fmt.Fprintf(os.Stderr, "\t%-25s %s\n", f.name+" "+f.typ, f.desc)
+				fmt.Fprintf(os.Stderr, "\t%-28s %s\n", f.name+" "+f.typ, f.desc)
 			} else {
-				fmt.Fprintf(os.Stderr, "\t%-25s %s\n", f.name, f.desc)
+				fmt.Fprintf(os.Stderr, "\t%-28s %s\n", f.name, f.desc)
 			}
 		}
 
@@ -68,21 +134,63 @@ func Load() (*Config, error) {
 	}
 
 	// If no required flags are provided, show usage and exit
-	if *docID == "" && *credentialsPath == "" {
+	if *docID == "" && *docs == "" && *credentialsPath == "" && *file == "" && *replay == "" {
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	var docIDs []string
+	if *docs != "" {
+		parsed, err := ParseDocList(*docs)
+		if err != nil {
+			return nil, err
+		}
+		docIDs = parsed
+	}
+
 	cfg := &Config{
-		DocID:           *docID,
-		CredentialsPath: *credentialsPath,
-		DryRun:          *dryRun,
-		ChunkSize:       *chunkSize,
-		PageRefresh:     *pageRefresh,
-		OutputDir:       *outputDir,
-		Model:           *model,
-		SummaryModel:    *summaryModel,
-		TargetRepo:      *targetRepo,
+		DocID:             *docID,
+		DocIDs:            docIDs,
+		CredentialsPath:   *credentialsPath,
+		CredentialsMode:   *credentialsMode,
+		DryRun:            *dryRun,
+		Resume:            *resume,
+		ChunkSize:         *chunkSize,
+		PageRefresh:       *pageRefresh,
+		OutputDir:         *outputDir,
+		Model:             *model,
+		SummaryModel:      *summaryModel,
+		Executor:          *executorName,
+		AllowedTools:      SplitList(*allowedTools),
+		ExcludedTools:     SplitList(*excludedTools),
+		DenyShell:         *denyShell,
+		DenyNetwork:       *denyNetwork,
+		RestrictWrites:    *restrictWrites,
+		ChunkRetries:      *chunkRetries,
+		FallbackModel:     *fallbackModel,
+		ValidateCommand:   *validateCommand,
+		ValidateRetries:   *validateRetries,
+		SkipTemplateCheck: *skipTemplateCheck,
+		TargetRepo:        *targetRepo,
+		StaleCheck:        *staleCheck,
+		ChunkOrder:        *chunkOrder,
+		TemplateDir:       *templateDir,
+		IncludeComments:   *includeComments,
+		OnlyAuthors:       SplitList(*onlyAuthor),
+		Since:             *since,
+		SuggestionIDs:     SplitList(*suggestionIDs),
+		AnchorLength:      *anchorLength,
+		AnchorBoundary:    *anchorBoundary,
+		Normalize:         SplitList(*normalize),
+		MergeSentences:    *mergeSentences,
+		ConflictStrategy:  *conflictStrategy,
+		APIMaxAttempts:    *apiMaxAttempts,
+		NoCache:           *noCache,
+		DumpRaw:           *dumpRaw,
+		Replay:            *replay,
+		Source:            *source,
+		File:              *file,
+		Before:            *before,
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -1,10 +1,18 @@
 package config
 
 import (
+	"bauer/internal/docsource"
+	"bauer/internal/executor"
 	"bauer/internal/gdocs"
+	"bauer/internal/prompt"
+	"bauer/internal/sites"
+	"bauer/internal/triage"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 // Config holds the runtime configuration for BAU.
@@ -12,12 +20,49 @@ type Config struct {
 	// DocID is the Google Doc ID to extract feedback from.
 	DocID string `json:"doc_id"`
 
+	// DocIDs lists additional documents (IDs or URLs) to process in the same run.
+	DocIDs []string `json:"doc_ids"`
+
 	// CredentialsPath is the path to the Google Cloud service account JSON key file.
 	CredentialsPath string `json:"credentials"`
 
+	// CredentialsMode selects where Google credentials come from: "file"
+	// (default, CredentialsPath), "env", "adc", "workload-identity" or "user".
+	CredentialsMode string `json:"credentials_mode"`
+
+	// APIMaxAttempts is the number of attempts per Google API call before a
+	// rate-limited or failed call gives up. Default is 5 if not specified.
+	APIMaxAttempts int `json:"api_max_attempts"`
+
+	// NoCache fetches the full document on every run instead of reusing the
+	// cached copy of an unchanged revision.
+	NoCache bool `json:"no_cache"`
+
+	// DumpRaw writes the raw document JSON next to the extraction result.
+	DumpRaw bool `json:"dump_raw"`
+
+	// Replay builds the run from a raw document saved with DumpRaw (or a
+	// snapshot) instead of fetching it, without network access.
+	Replay string `json:"replay"`
+
+	// Extraction plans the run from the bauer-doc-suggestions.json of an
+	// earlier run, e.g. of `bauer extract`, instead of extracting the
+	// document again; its suggestions are taken as they are.
+	Extraction string `json:"extraction"`
+
+	// StateFile records the outcome of every suggestion across runs, so the
+	// suggestions earlier runs applied are skipped, unless Reapply is set.
+	// Empty disables it.
+	StateFile string `json:"state_file"`
+	Reapply   bool   `json:"reapply"`
+
 	// DryRun indicates if the tool should skip side-effect operations (Copilot CLI, PR creation).
 	DryRun bool `json:"dry_run"`
 
+	// Resume skips the chunks the previous run in OutputDir completed, as
+	// recorded in its chunk manifest, and executes only the others.
+	Resume bool `json:"resume"`
+
 	// ChunkSize is the total number of chunks to create from all locations.
 	// Default is 1 if not specified, or 5 if PageRefresh is true.
 	ChunkSize int `json:"chunk_size"`
@@ -27,7 +72,8 @@ type Config struct {
 	PageRefresh bool `json:"page_refresh"`
 
 	// OutputDir is the directory where generated prompt files will be saved.
-	// Default is "bauer-output" if not specified.
+	// Default is "bauer-output" if not specified. A relative path is
+	// resolved against WorkDir.
 	OutputDir string `json:"output_dir"`
 
 	// Model is the Copilot model to use for sessions.
@@ -38,9 +84,175 @@ type Config struct {
 	// Default is "gpt-5-mini-high" if not specified.
 	SummaryModel string `json:"summary_model"`
 
+	// Executor selects the LLM backend that applies the chunks: "copilot"
+	// (default) or "openai", any OpenAI-compatible chat completions API.
+	Executor string `json:"executor"`
+
+	// AllowedTools, when set, are the only tools Copilot sessions may use, e.g.
+	// view and edit; ExcludedTools are removed from the defaults otherwise.
+	AllowedTools  []string `json:"allowed_tools"`
+	ExcludedTools []string `json:"excluded_tools"`
+
+	// DenyShell refuses shell commands and DenyNetwork URL fetches and MCP
+	// calls in Copilot sessions. RestrictWrites refuses writes outside TargetRepo.
+	DenyShell      bool `json:"deny_shell"`
+	DenyNetwork    bool `json:"deny_network"`
+	RestrictWrites bool `json:"restrict_writes"`
+
+	// ChunkRetries is the number of times a chunk is retried after a session
+	// error or timeout. Default is 0 (no retries).
+	ChunkRetries int `json:"chunk_retries"`
+
+	// FallbackModel, when set, is used for the final retry of a chunk.
+	FallbackModel string `json:"fallback_model"`
+
+	// ValidateCommand, when set, checks the changes once the chunks are
+	// applied, e.g. "yarn build"; it runs with sh in TargetRepo. When it
+	// fails, up to ValidateRetries sessions are asked to fix the errors.
+	ValidateCommand string `json:"validate_command"`
+	ValidateRetries int    `json:"validate_retries"`
+
+	// SkipTemplateCheck disables checking the HTML templates each chunk
+	// changed for unbalanced tags, broken Jinja and deleted blocks.
+	SkipTemplateCheck bool `json:"skip_template_check"`
+
+	// Approval holds runs between planning and execution until their plan
+	// is approved: "prompt" asks on the terminal, "file" waits for a
+	// sentinel file in OutputDir. Empty executes the plan right away.
+	Approval string `json:"approval"`
+
+	// ApprovalWebhook, when set, is posted the plan of runs waiting for
+	// approval as {"text": ...}, e.g. a Slack incoming webhook.
+	ApprovalWebhook string `json:"approval_webhook"`
+
+	// Limits stop runs larger than expected before their changes are
+	// pushed: MaxSuggestions and MaxChunks are checked once the chunks are
+	// planned, MaxCopilotDuration (e.g. "30m") as the chunks execute, and
+	// MaxFilesModified and MaxLinesChanged once the chunks are applied.
+	// 0, or an empty duration, disables a limit.
+	MaxSuggestions     int    `json:"max_suggestions"`
+	MaxChunks          int    `json:"max_chunks"`
+	MaxCopilotDuration string `json:"max_copilot_duration"`
+	MaxFilesModified   int    `json:"max_files_modified"`
+	MaxLinesChanged    int    `json:"max_lines_changed"`
+
+	// UnexpectedChanges is what runs changing files other than the templates
+	// of the document's pages, and the files its suggestions were found in,
+	// do: "warn" (default) or "fail", which keeps the changes from being
+	// pushed like a failed validation.
+	UnexpectedChanges string `json:"unexpected_changes"`
+
+	// ProtectedPaths are gitignore-style patterns, e.g. "*.py" or
+	// ".github/**", of files chunks must not change; their changes are
+	// reverted after each chunk.
+	ProtectedPaths []string `json:"protected_paths"`
+
 	// TargetRepo is the path (relative or absolute) to the target repository
-	// where tasks should be executed. If not specified, uses the current directory.
+	// where tasks should be executed. If not specified, uses WorkDir.
 	TargetRepo string `json:"target_repo"`
+
+	// WorkDir is the directory the run works in: executor sessions and
+	// validation commands run there, and the run's files are written
+	// relative to it. Default is the current directory. Runs never change
+	// the process's directory, so several can run at once.
+	WorkDir string `json:"work_dir"`
+
+	// StaleCheck enables staleness detection against the published page before planning.
+	// "http" fetches the metadata URL, "repo" reads the template from TargetRepo.
+	// Empty disables the check.
+	StaleCheck string `json:"stale_check"`
+
+	// ChunkOrder is the strategy used to order locations into chunks:
+	// "position" (default), "difficulty" or "churn".
+	ChunkOrder string `json:"chunk_order"`
+
+	// DirectApply patches the suggestions whose text matches exactly once in
+	// their resolved file without Copilot, leaving the others to the chunks
+	DirectApply bool `json:"direct_apply"`
+
+	// DirectThreshold is the confidence, from 0 to 1, a match needs to be
+	// patched when the text of a suggestion differs from its file's; lower
+	// ones are left to the chunks. 0 is direct.DefaultThreshold.
+	DirectThreshold float64 `json:"direct_threshold"`
+
+	// Triage classifies the suggestions of copy updates by category and risk:
+	// "rules", or "llm" to have the rules reviewed by the OpenAI-compatible
+	// API of the openai executor. Empty disables triage.
+	Triage string `json:"triage"`
+
+	// TriageRoutes route the groups of a category or risk level, as
+	// "<category or risk>=<apply, review or skip>", e.g. "legal=review".
+	// Groups held for review are left out of the run.
+	TriageRoutes []string `json:"triage_routes"`
+
+	// MaxChunkTokens is the estimated size over which a chunk is flagged in
+	// the run report. 0 is prompt.DefaultMaxChunkTokens.
+	MaxChunkTokens int `json:"max_chunk_tokens"`
+
+	// TemplateDir holds Go templates that replace the built-in prompts:
+	// instructions.md.tmpl and summary.md.tmpl. Empty uses the built-in ones.
+	TemplateDir string `json:"template_dir"`
+
+	// OnlyAuthors keeps only the suggestions made by these authors (names or emails).
+	OnlyAuthors []string `json:"only_authors"`
+
+	// Since keeps only the suggestions made at or after this date (YYYY-MM-DD)
+	// or time (RFC 3339).
+	Since string `json:"since"`
+
+	// SuggestionIDs keeps only these suggestions.
+	SuggestionIDs []string `json:"suggestion_ids"`
+
+	// AnchorLength is the length of the text around each suggestion used to
+	// locate it; grouped suggestions get 1.5 times as much. Default is 80.
+	AnchorLength int `json:"anchor_length"`
+
+	// AnchorBoundary extends anchors to whole words ("word") or sentences
+	// ("sentence") so they don't cut words in half. Empty cuts at AnchorLength.
+	AnchorBoundary string `json:"anchor_boundary"`
+
+	// Normalize lists the normalization steps applied to anchors so they
+	// match rendered HTML: "nfc", "quotes", "whitespace" or "all". The raw
+	// anchors are kept next to the normalized ones.
+	Normalize []string `json:"normalize"`
+
+	// MergeSentences combines suggestions in the same sentence into a single
+	// replacement, so they can't be applied inconsistently.
+	MergeSentences bool `json:"merge_sentences"`
+
+	// ConflictStrategy picks which of two overlapping suggestions is applied:
+	// "largest" (default), "newest", "fail" or "interactive".
+	ConflictStrategy string `json:"conflict_strategy"`
+
+	// IncludeComments turns unresolved comments on quoted text into
+	// suggestions, for reviewers who leave feedback as comments.
+	IncludeComments bool `json:"include_comments"`
+
+	// Source is where the document comes from: "gdocs" (default), "docx",
+	// "diff" or "revisions".
+	Source string `json:"source"`
+
+	// File is the local document read when Source is "docx", or the new
+	// version of the page when Source is "diff".
+	File string `json:"file"`
+
+	// Before is the old version of the page when Source is "diff". Both
+	// versions are file paths or "git:<revision>:<path>".
+	Before string `json:"before"`
+
+	// BeforeRevision and AfterRevision are the revisions of the Google Doc
+	// compared when Source is "revisions" (see gdocs.SelectRevision); an
+	// empty AfterRevision is the latest.
+	BeforeRevision string `json:"before_revision"`
+	AfterRevision  string `json:"after_revision"`
+
+	// Tab is the ID or title of the only tab read of documents with tabs;
+	// empty reads every tab.
+	Tab string `json:"tab"`
+
+	// Sites map the pages of documents onto the repositories and templates
+	// serving them. Pages of no site have their templates in templates/.
+	Sites sites.Table `json:"sites"`
 }
 
 // Apply default config values
@@ -61,6 +273,12 @@ func (c *Config) ApplyDefaults() {
 	if c.SummaryModel == "" {
 		c.SummaryModel = "gpt-5-mini-high"
 	}
+	if c.ChunkOrder == "" {
+		c.ChunkOrder = prompt.OrderByPosition
+	}
+	if c.Executor == "" {
+		c.Executor = executor.ExecutorCopilot
+	}
 }
 
 // Validate checks if the configuration is valid.
@@ -69,16 +287,291 @@ func (c *Config) Validate() error {
 	// Apply defaults first
 	c.ApplyDefaults()
 
+	// Local files need neither a document ID nor Google credentials
+	switch c.Source {
+	case "", docsource.SourceGoogleDocs:
+	case docsource.SourceDocx:
+		if c.File == "" {
+			return errors.New("missing required field: file (required with source docx)")
+		}
+		if _, err := os.Stat(c.File); err != nil {
+			return fmt.Errorf("invalid file: %w", err)
+		}
+	case docsource.SourceDiff:
+		if c.Before == "" || c.File == "" {
+			return errors.New("missing required fields: before and file (required with source diff)")
+		}
+		for _, version := range []string{c.Before, c.File} {
+			if strings.HasPrefix(version, "git:") {
+				continue
+			}
+			if _, err := os.Stat(version); err != nil {
+				return fmt.Errorf("invalid file: %w", err)
+			}
+		}
+	case docsource.SourceRevisions:
+		if c.BeforeRevision == "" {
+			return errors.New("missing required field: before_revision (required with source revisions)")
+		}
+	default:
+		return fmt.Errorf("invalid source: %s (expected gdocs, docx, diff or revisions)", c.Source)
+	}
+	if c.Replay != "" {
+		if _, err := os.Stat(c.Replay); err != nil {
+			return fmt.Errorf("invalid replay file: %w", err)
+		}
+	}
+	if c.Extraction != "" {
+		if _, err := os.Stat(c.Extraction); err != nil {
+			return fmt.Errorf("invalid extraction file: %w", err)
+		}
+	}
+
 	// Validate required fields
-	if c.DocID == "" {
+	if c.DocID == "" && len(c.DocIDs) == 0 && !c.LocalSource() {
 		return errors.New("missing required field: doc_id")
 	}
+	for _, doc := range c.DocIDs {
+		if _, err := gdocs.ParseDocumentID(doc); err != nil {
+			return fmt.Errorf("invalid doc_ids entry: %w", err)
+		}
+	}
+
+	if err := c.ValidateOptions(); err != nil {
+		return err
+	}
+
+	if c.LocalSource() {
+		return nil
+	}
+	return ValidateCredentials(c.CredentialsMode, c.CredentialsPath)
+}
 
+// ValidateOptions checks the options of the configuration, leaving out the
+// document and its credentials, e.g. for the defaults of a config file.
+func (c *Config) ValidateOptions() error {
 	if c.ChunkSize <= 0 {
 		return errors.New("chunk_size must be greater than 0")
 	}
 
-	return ValidateCredentialsPath(c.CredentialsPath)
+	if err := executor.Validate(c.Executor); err != nil {
+		return fmt.Errorf("invalid executor: %w", err)
+	}
+	if c.ChunkRetries < 0 {
+		return errors.New("chunk_retries must not be negative")
+	}
+	if c.FallbackModel != "" && c.ChunkRetries == 0 {
+		return errors.New("fallback_model requires chunk_retries")
+	}
+	if c.ValidateRetries < 0 {
+		return errors.New("validate_retries must not be negative")
+	}
+	if c.Approval != "" && c.Approval != "prompt" && c.Approval != "file" {
+		return fmt.Errorf("invalid approval: %s (expected prompt or file)", c.Approval)
+	}
+	if c.MaxSuggestions < 0 || c.MaxChunks < 0 || c.MaxFilesModified < 0 || c.MaxLinesChanged < 0 {
+		return errors.New("max_suggestions, max_chunks, max_files_modified and max_lines_changed must not be negative")
+	}
+	if _, err := c.CopilotDurationLimit(); err != nil {
+		return err
+	}
+	if c.UnexpectedChanges != "" && c.UnexpectedChanges != "warn" && c.UnexpectedChanges != "fail" {
+		return fmt.Errorf("invalid unexpected_changes: %s (expected warn or fail)", c.UnexpectedChanges)
+	}
+
+	if c.APIMaxAttempts < 0 {
+		return errors.New("api_max_attempts must not be negative")
+	}
+
+	if c.StaleCheck != "" && c.StaleCheck != "http" && c.StaleCheck != "repo" {
+		return fmt.Errorf("invalid stale_check: %s (expected http or repo)", c.StaleCheck)
+	}
+
+	if c.AnchorLength < 0 {
+		return errors.New("anchor_length must not be negative")
+	}
+	if err := gdocs.ValidateAnchorBoundary(c.AnchorBoundary); err != nil {
+		return fmt.Errorf("invalid anchor_boundary: %w", err)
+	}
+	if _, err := gdocs.ParseNormalization(c.Normalize); err != nil {
+		return fmt.Errorf("invalid normalize: %w", err)
+	}
+	if err := gdocs.ValidateConflictStrategy(c.ConflictStrategy); err != nil {
+		return fmt.Errorf("invalid conflict_strategy: %w", err)
+	}
+
+	if _, err := c.SuggestionFilter(); err != nil {
+		return fmt.Errorf("invalid since: %w", err)
+	}
+
+	if c.DirectThreshold < 0 || c.DirectThreshold > 1 {
+		return errors.New("direct_threshold must be between 0 and 1")
+	}
+	if c.Triage != "" && c.Triage != triage.ModeRules && c.Triage != triage.ModeLLM {
+		return fmt.Errorf("invalid triage: %s (expected rules or llm)", c.Triage)
+	}
+	if _, err := triage.ParseRoutes(c.TriageRoutes); err != nil {
+		return fmt.Errorf("invalid triage_routes: %w", err)
+	}
+	if len(c.TriageRoutes) > 0 && c.Triage == "" {
+		return errors.New("triage_routes requires triage")
+	}
+	if c.MaxChunkTokens < 0 {
+		return errors.New("max_chunk_tokens must not be negative")
+	}
+
+	if err := prompt.ValidateOrderStrategy(c.ChunkOrder); err != nil {
+		return fmt.Errorf("invalid chunk_order: %w", err)
+	}
+	if c.TemplateDir != "" {
+		if _, err := prompt.LoadTemplates(c.TemplateDir); err != nil {
+			return fmt.Errorf("invalid template_dir: %w", err)
+		}
+	}
+	if err := c.Sites.Validate(); err != nil {
+		return fmt.Errorf("invalid sites: %w", err)
+	}
+	return nil
+}
+
+// Resolved returns a copy of the config with an absolute WorkDir, and
+// OutputDir and TargetRepo resolved against it.
+func (c *Config) Resolved() (*Config, error) {
+	resolved := *c
+	workDir := c.WorkDir
+	if workDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %w", err)
+		}
+		workDir = cwd
+	}
+	workDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve work directory: %w", err)
+	}
+	resolved.WorkDir = workDir
+	resolved.OutputDir = resolvePath(workDir, c.OutputDir)
+	resolved.TargetRepo = resolvePath(workDir, c.TargetRepo)
+	return &resolved, nil
+}
+
+// CopilotDurationLimit parses MaxCopilotDuration; 0 is no limit.
+func (c *Config) CopilotDurationLimit() (time.Duration, error) {
+	if c.MaxCopilotDuration == "" {
+		return 0, nil
+	}
+	limit, err := time.ParseDuration(c.MaxCopilotDuration)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("invalid max_copilot_duration: %s (expected a duration, e.g. 30m)", c.MaxCopilotDuration)
+	}
+	return limit, nil
+}
+
+// resolvePath joins a relative path to dir; empty paths resolve to dir.
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// LocalSource reports whether the document is read from local files rather
+// than Google Docs.
+func (c *Config) LocalSource() bool {
+	return c.Replay != "" || c.Extraction != "" || c.Source == docsource.SourceDocx || c.Source == docsource.SourceDiff
+}
+
+// SuggestionFilter returns the filter selected by OnlyAuthors, Since and SuggestionIDs.
+func (c *Config) SuggestionFilter() (gdocs.SuggestionFilter, error) {
+	filter := gdocs.SuggestionFilter{Authors: c.OnlyAuthors, IDs: c.SuggestionIDs}
+	if c.Since != "" {
+		since, err := gdocs.ParseSince(c.Since)
+		if err != nil {
+			return filter, err
+		}
+		filter.Since = since
+	}
+	return filter, nil
+}
+
+// Normalization returns the anchor normalization selected by Normalize.
+func (c *Config) Normalization() (gdocs.Normalization, error) {
+	return gdocs.ParseNormalization(c.Normalize)
+}
+
+// Documents returns the IDs of every document to process: DocID first, followed
+// by DocIDs, with URLs resolved to IDs and duplicates removed.
+func (c *Config) Documents() ([]string, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, doc := range append([]string{c.DocID}, c.DocIDs...) {
+		if doc == "" {
+			continue
+		}
+		id, err := gdocs.ParseDocumentID(doc)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// ParseDocList parses the value of --docs: either a path to a file with one
+// document ID or URL per line (blank lines and # comments are ignored), or a
+// comma-separated list.
+func ParseDocList(value string) ([]string, error) {
+	var entries []string
+	if info, err := os.Stat(value); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read docs file: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if idx := strings.Index(line, "#"); idx != -1 {
+				line = line[:idx]
+			}
+			entries = append(entries, line)
+		}
+	} else {
+		entries = strings.Split(value, ",")
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		id, err := gdocs.ParseDocumentID(entry)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 {
+		return nil, errors.New("no documents found in --docs")
+	}
+	return ids, nil
+}
+
+// ValidateCredentials checks that the credentials for mode are available.
+func ValidateCredentials(mode, path string) error {
+	parsed, err := gdocs.ParseCredentialsMode(mode)
+	if err != nil {
+		return err
+	}
+	if parsed == gdocs.CredentialsFile {
+		return ValidateCredentialsPath(path)
+	}
+	if err := (gdocs.ClientOptions{Mode: parsed, CredentialsPath: path}).Validate(); err != nil {
+		return fmt.Errorf("invalid %s credentials: %w", parsed, err)
+	}
+	return nil
 }
 
 func ValidateCredentialsPath(path string) error {
@@ -100,3 +593,14 @@ func ValidateCredentialsPath(path string) error {
 	}
 	return nil
 }
+
+// SplitList splits a comma-separated flag value, dropping blank entries.
+func SplitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
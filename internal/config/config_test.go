@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -201,3 +202,67 @@ func TestChunkSizeDefaults(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDocList(t *testing.T) {
+	tmpDir := t.TempDir()
+	docsFile := filepath.Join(tmpDir, "docs.txt")
+	content := "# weekly review docs\ndoc-a\n\nhttps://docs.google.com/document/d/doc-b/edit # hero copy\n"
+	if err := os.WriteFile(docsFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create docs file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Comma-separated list", value: "doc-a, https://docs.google.com/document/d/doc-b/edit", want: []string{"doc-a", "doc-b"}},
+		{name: "File", value: docsFile, want: []string{"doc-a", "doc-b"}},
+		{name: "Empty list", value: " , ", wantErr: true},
+		{name: "Invalid entry", value: "doc-a,not a doc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDocList(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDocList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ParseDocList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolved(t *testing.T) {
+	workDir := t.TempDir()
+	cfg := &Config{WorkDir: workDir, OutputDir: "bauer-output", TargetRepo: "/srv/site"}
+
+	got, err := cfg.Resolved()
+	if err != nil {
+		t.Fatalf("Resolved() error = %v", err)
+	}
+	if got.OutputDir != filepath.Join(workDir, "bauer-output") {
+		t.Errorf("Resolved() OutputDir = %s, want it in %s", got.OutputDir, workDir)
+	}
+	if got.TargetRepo != "/srv/site" {
+		t.Errorf("Resolved() TargetRepo = %s, want /srv/site", got.TargetRepo)
+	}
+	if cfg.OutputDir != "bauer-output" {
+		t.Errorf("Resolved() changed the original config")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = (&Config{}).Resolved()
+	if err != nil {
+		t.Fatalf("Resolved() error = %v", err)
+	}
+	if got.WorkDir != cwd || got.TargetRepo != cwd {
+		t.Errorf("Resolved() without a work directory = %s, %s, want %s", got.WorkDir, got.TargetRepo, cwd)
+	}
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config files set the defaults of the CLI flags. FileName is read from the
+// current directory, unless --config or FileEnvVar names another file, and
+// RepoFileName from the target repository, whose settings win. Environment
+// variables override both, and flags override everything.
+const (
+	FileName     = "bauer.yaml"
+	RepoFileName = ".bauer.yaml"
+	FileEnvVar   = "BAUER_CONFIG"
+)
+
+// Settings are the names of the settings of config files. Each sets the flag
+// of the same name, with dashes for underscores, e.g. chunk_size sets
+// --chunk-size, and can be set in the environment as BAUER_CHUNK_SIZE.
+var Settings = []string{
+	// Document
+	"credentials", "credentials_mode", "api_max_attempts",
+	"anchor_length", "anchor_boundary", "normalize", "merge_sentences",
+	"conflict_strategy", "include_comments", "stale_check", "state_file", "reapply",
+	// Chunking
+	"output_dir", "chunk_size", "page_refresh", "chunk_order", "template_dir",
+	"direct_apply", "direct_threshold", "max_chunk_tokens", "triage", "triage_routes",
+	// Executor
+	"model", "summary_model", "executor", "allowed_tools", "excluded_tools",
+	"deny_shell", "deny_network", "restrict_writes", "chunk_retries",
+	"fallback_model", "validate_command", "validate_retries", "skip_template_check",
+	"approval", "approval_webhook", "max_suggestions", "max_chunks",
+	"max_copilot_duration", "max_files_modified", "max_lines_changed", "unexpected_changes",
+	"protected_paths",
+	// Repository and pull request
+	"github_repo", "github_host", "local_repo_path", "shallow_clone", "sparse_paths",
+	"branch_prefix", "skip_code_owners", "reviewers", "reviewer_map", "assignees",
+	"labels", "milestone", "screenshot_server", "screenshot_url",
+	"screenshot_browser", "comment_on_doc", "sites",
+	// Logging
+	"log_level", "log_format", "log_output",
+}
+
+// RepoSettings are the settings RepoFileName may set: how the site's pages are
+// found, chunked and reviewed. The rest, e.g. the executor's tools, the
+// validation command and the limits of runs, are the operator's, which a
+// repository can't loosen.
+var RepoSettings = []string{
+	"anchor_length", "anchor_boundary", "normalize", "merge_sentences",
+	"conflict_strategy", "include_comments", "stale_check",
+	"chunk_size", "page_refresh", "chunk_order", "template_dir",
+	"direct_apply", "direct_threshold", "max_chunk_tokens", "triage",
+	"sparse_paths", "branch_prefix", "reviewers", "reviewer_map", "assignees",
+	"labels", "milestone", "sites",
+}
+
+// pathSettings are files and directories, resolved against the directory of
+// the config file setting them.
+var pathSettings = []string{"credentials", "template_dir", "reviewer_map", "sites"}
+
+// Default is the value of a setting, and where it was set.
+type Default struct {
+	Value  string
+	Source string
+}
+
+// Defaults are the values of settings, by name.
+type Defaults map[string]Default
+
+// Flag returns the name of the flag of a setting.
+func Flag(setting string) string {
+	return strings.ReplaceAll(setting, "_", "-")
+}
+
+// EnvVar returns the environment variable of a setting.
+func EnvVar(setting string) string {
+	return "BAUER_" + strings.ToUpper(setting)
+}
+
+// LoadFile reads the settings of a config file. Lists may be YAML lists or
+// comma-separated strings.
+func LoadFile(path string) (Defaults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	defaults := Defaults{}
+	var unknown []string
+	for name, value := range values {
+		if !slices.Contains(Settings, name) {
+			unknown = append(unknown, name)
+			continue
+		}
+		text, err := settingValue(value)
+		if list, ok := value.([]any); ok && name == "sites" {
+			// Sites are listed in the file itself, and passed on as JSON
+			var data []byte
+			data, err = json.Marshal(list)
+			text = string(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in %s: %w", name, path, err)
+		}
+		if slices.Contains(pathSettings, name) && text != "" && !strings.HasPrefix(text, "[") && !filepath.IsAbs(text) {
+			text = filepath.Join(filepath.Dir(path), text)
+		}
+		defaults[name] = Default{Value: text, Source: path}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown settings in %s: %s", path, strings.Join(unknown, ", "))
+	}
+	return defaults, nil
+}
+
+// settingValue formats a YAML value as the value of a flag.
+func settingValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			if _, ok := item.([]any); ok {
+				return "", errors.New("lists can't be nested")
+			}
+			if _, ok := item.(map[string]any); ok {
+				return "", errors.New("lists can't hold mappings")
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ","), nil
+	case map[string]any:
+		return "", errors.New("expected a value or a list, not a mapping")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// EnvDefaults returns the settings set in the environment.
+func EnvDefaults() Defaults {
+	defaults := Defaults{}
+	for _, name := range Settings {
+		if value, ok := os.LookupEnv(EnvVar(name)); ok {
+			defaults[name] = Default{Value: value, Source: EnvVar(name)}
+		}
+	}
+	return defaults
+}
+
+// LoadDefaults merges the settings of the config file at path, of
+// RepoFileName in repoDir and of the environment, each overriding the ones
+// before. An empty path reads FileEnvVar, or FileName when it exists; an
+// empty repoDir, or one without RepoFileName, is skipped. RepoFileName may
+// only set RepoSettings.
+func LoadDefaults(path, repoDir string) (Defaults, error) {
+	defaults := Defaults{}
+	repoFile := filepath.Join(repoDir, RepoFileName)
+	for _, file := range ConfigFiles(path, repoDir) {
+		fileDefaults, err := LoadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if repoDir != "" && file == repoFile {
+			if err := checkRepoSettings(file, fileDefaults); err != nil {
+				return nil, err
+			}
+		}
+		defaults.merge(fileDefaults)
+	}
+	defaults.merge(EnvDefaults())
+	return defaults, nil
+}
+
+// ConfigFiles returns the config files LoadDefaults reads, in order.
+func ConfigFiles(path, repoDir string) []string {
+	var files []string
+	if path == "" {
+		path = os.Getenv(FileEnvVar)
+	}
+	if path != "" {
+		files = append(files, path)
+	} else if _, err := os.Stat(FileName); err == nil {
+		files = append(files, FileName)
+	}
+	if repoDir != "" {
+		repoFile := filepath.Join(repoDir, RepoFileName)
+		if _, err := os.Stat(repoFile); err == nil {
+			files = append(files, repoFile)
+		}
+	}
+	return files
+}
+
+// checkRepoSettings rejects the settings of the repository's config file
+// outside RepoSettings.
+func checkRepoSettings(path string, defaults Defaults) error {
+	var denied []string
+	for name := range defaults {
+		if !slices.Contains(RepoSettings, name) {
+			denied = append(denied, name)
+		}
+	}
+	if len(denied) > 0 {
+		sort.Strings(denied)
+		return fmt.Errorf("settings %s can't be set by the repository, in %s; set them in %s or with flags instead",
+			strings.Join(denied, ", "), path, FileName)
+	}
+	return nil
+}
+
+func (d Defaults) merge(other Defaults) {
+	for name, value := range other {
+		d[name] = value
+	}
+}
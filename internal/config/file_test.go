@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, FileName)
+	writeFile(t, file, "model: gpt-5\nchunk_size: 3\nreviewers: [alice, bob]\ntemplate_dir: prompts\nchunk_order: churn\n")
+	repo := filepath.Join(dir, "repo")
+	writeFile(t, filepath.Join(repo, RepoFileName), "chunk_size: 4\nchunk_order: difficulty\n")
+	t.Setenv(EnvVar("chunk_order"), "position")
+
+	defaults, err := LoadDefaults(file, repo)
+	if err != nil {
+		t.Fatalf("LoadDefaults() error = %v", err)
+	}
+	want := Defaults{
+		"model":        {Value: "gpt-5", Source: file},
+		"chunk_size":   {Value: "4", Source: filepath.Join(repo, RepoFileName)},
+		"reviewers":    {Value: "alice,bob", Source: file},
+		"template_dir": {Value: filepath.Join(dir, "prompts"), Source: file},
+		"chunk_order":  {Value: "position", Source: "BAUER_CHUNK_ORDER"},
+	}
+	if diff := cmp.Diff(want, defaults); diff != "" {
+		t.Errorf("LoadDefaults() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadDefaults_RepoSettings(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, FileName)
+	writeFile(t, file, "validate_command: make test\ndeny_shell: true\n")
+	repo := filepath.Join(dir, "repo")
+	writeFile(t, filepath.Join(repo, RepoFileName), "chunk_size: 4\ndeny_shell: false\nvalidate_command: \"true\"\n")
+
+	// The repository can't loosen the operator's settings
+	_, err := LoadDefaults(file, repo)
+	if err == nil || !strings.Contains(err.Error(), "deny_shell, validate_command can't be set by the repository") {
+		t.Errorf("LoadDefaults() error = %v, want the repository's security settings rejected", err)
+	}
+
+	// The operator's file sets them all
+	if _, err := LoadDefaults(filepath.Join(repo, RepoFileName), ""); err != nil {
+		t.Errorf("LoadDefaults() of the operator's file error = %v", err)
+	}
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	for _, tt := range []struct {
+		content string
+		want    string
+	}{
+		{"modle: gpt-5\n", "unknown settings"},
+		{"reviewers: {alice: bob}\n", "not a mapping"},
+		{"model: [gpt-5\n", "failed to parse"},
+	} {
+		path := filepath.Join(t.TempDir(), FileName)
+		writeFile(t, path, tt.content)
+		if _, err := LoadFile(path); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("LoadFile(%q) error = %v, want %q", tt.content, err, tt.want)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"bauer/internal/progress"
+
 	copilot "github.com/github/copilot-sdk/go"
 )
 
@@ -54,6 +56,12 @@ func formatSummaryOutput(text string) string {
 type Client struct {
 	client *copilot.Client
 	cwd    string
+
+	// SummaryInstructions replace the built-in summary instructions when set
+	SummaryInstructions string
+
+	// Sandbox restricts the tools and permissions of sessions
+	Sandbox Sandbox
 }
 
 // NewClient creates and initializes a new Copilot client
@@ -97,6 +105,19 @@ func (c *Client) Start() error {
 	return nil
 }
 
+// Ping starts a Copilot CLI server in cwd, pings it and stops it, to check
+// Copilot is installed and reachable.
+func Ping(cwd string) error {
+	client, err := NewClient(cwd)
+	if err != nil {
+		return err
+	}
+	if err := client.Start(); err != nil {
+		return err
+	}
+	return client.Stop()
+}
+
 // Stop gracefully stops the Copilot CLI server
 func (c *Client) Stop() error {
 	slog.Info("Stopping Copilot client...")
@@ -112,19 +133,22 @@ func (c *Client) Stop() error {
 }
 
 // ExecuteChunk processes a single chunk prompt using a Copilot session and returns the output
-func (c *Client) ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber int, model string) (string, error) {
+// and the token usage of the session. The usage is estimated when Copilot reports none.
+func (c *Client) ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber int, model string) (string, Usage, error) {
 	slog.Info("Creating Copilot session",
 		slog.Int("chunk", chunkNumber),
 		slog.String("model", model),
 	)
 
 	// Create a session with streaming enabled
-	session, err := c.client.CreateSession(&copilot.SessionConfig{
+	sessionConfig := &copilot.SessionConfig{
 		Model:     model,
 		Streaming: true,
-	})
+	}
+	c.Sandbox.sessionConfig(sessionConfig)
+	session, err := c.client.CreateSession(sessionConfig)
 	if err != nil {
-		return "", fmt.Errorf("failed to create session for chunk %d: %w", chunkNumber, err)
+		return "", Usage{}, fmt.Errorf("failed to create session for chunk %d: %w", chunkNumber, err)
 	}
 	defer func() {
 		if err := session.Destroy(); err != nil {
@@ -135,11 +159,28 @@ func (c *Client) ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber
 		}
 	}()
 
+	// Events are logged as well as streamed; without a log the run goes on
+	events, err := openEventLog(EventLogPath(chunkPath, chunkNumber))
+	if err != nil {
+		slog.Warn("Session events will not be logged",
+			slog.Int("chunk", chunkNumber),
+			slog.String("error", err.Error()),
+		)
+	}
+	defer func() {
+		if err := events.Close(); err != nil {
+			slog.Error("Failed to close event log", slog.String("error", err.Error()))
+		}
+	}()
+
 	// Set up event handler to stream output
 	done := make(chan error, 1)
 	var fullOutput string
+	var usage Usage
+	reported := false
 
 	session.On(func(event copilot.SessionEvent) {
+		events.Write(event)
 		switch event.Type {
 		// TODO these 2 events should be only for debugging/verbose logging
 		case "assistant.message_delta":
@@ -147,6 +188,7 @@ func (c *Client) ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber
 			if event.Data.DeltaContent != nil {
 				fmt.Print(formatCopilotOutput(*event.Data.DeltaContent))
 				fullOutput += *event.Data.DeltaContent
+				progress.Report(ctx, progress.Event{Type: progress.EventDelta, Chunk: chunkNumber, Text: *event.Data.DeltaContent})
 			}
 
 		case "assistant.reasoning_delta":
@@ -187,15 +229,19 @@ func (c *Client) ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber
 
 		case "session.error":
 			// Session encountered an error
-			errMsg := fmt.Sprintf("session error for chunk %d", chunkNumber)
+			err := fmt.Errorf("%w for chunk %d", ErrSession, chunkNumber)
 			if event.Data.Error != nil {
-				errMsg = fmt.Sprintf("%s: %v", errMsg, event.Data.Error)
+				err = fmt.Errorf("%w: %v", err, event.Data.Error)
 			}
 			slog.Error("Session error",
 				slog.Int("chunk", chunkNumber),
-				slog.String("error", errMsg),
+				slog.String("error", err.Error()),
 			)
-			done <- fmt.Errorf("%s", errMsg)
+			done <- err
+
+		case "assistant.usage":
+			usage.Add(usageFromEvent(event.Data))
+			reported = true
 
 		case "assistant.tool_call":
 			// Log tool calls for visibility
@@ -212,7 +258,7 @@ func (c *Client) ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber
 	// Ensure the path is absolute for reliable access
 	absChunkPath, err := filepath.Abs(chunkPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to resolve chunk path: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to resolve chunk path: %w", err)
 	}
 
 	slog.Info("Sending prompt to Copilot",
@@ -220,8 +266,9 @@ func (c *Client) ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber
 		slog.String("file", absChunkPath),
 	)
 
+	prompt := fmt.Sprintf("Implement the changes described in @%s. Follow all instructions carefully and apply changes in order.", filepath.Base(chunkPath))
 	_, err = session.Send(copilot.MessageOptions{
-		Prompt: fmt.Sprintf("Implement the changes described in @%s. Follow all instructions carefully and apply changes in order.", filepath.Base(chunkPath)),
+		Prompt: prompt,
 		Attachments: []copilot.Attachment{
 			{
 				Type:        copilot.File,
@@ -231,23 +278,33 @@ func (c *Client) ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber
 		},
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to send message for chunk %d: %w", chunkNumber, err)
+		return "", Usage{}, fmt.Errorf("failed to send message for chunk %d: %w", chunkNumber, err)
+	}
+
+	// sessionUsage is the reported usage, or an estimate from the prompt with
+	// its attachment and the output
+	sessionUsage := func() Usage {
+		if reported {
+			return usage
+		}
+		content, _ := os.ReadFile(absChunkPath)
+		return EstimateUsage(prompt+string(content), fullOutput)
 	}
 
 	// Wait for completion with timeout
 	select {
 	case err := <-done:
 		if err != nil {
-			return "", err
+			return "", sessionUsage(), err
 		}
 		fmt.Println() // Add newline after streaming output
-		return fullOutput, nil
+		return fullOutput, sessionUsage(), nil
 
 	case <-time.After(15 * time.Minute):
-		return "", fmt.Errorf("chunk %d timed out after 15 minutes", chunkNumber)
+		return "", sessionUsage(), fmt.Errorf("chunk %d %w after 15 minutes", chunkNumber, ErrTimeout)
 
 	case <-ctx.Done():
-		return "", fmt.Errorf("chunk %d cancelled: %w", chunkNumber, ctx.Err())
+		return "", sessionUsage(), fmt.Errorf("chunk %d cancelled: %w", chunkNumber, ctx.Err())
 	}
 }
 
@@ -256,19 +313,24 @@ type ChunkOutput struct {
 	ChunkNumber int
 	Output      string
 	Duration    time.Duration
+
+	// Attempts are the sessions the chunk took, the last one successful
+	Attempts []Attempt
 }
 
-// GenerateSummary creates a summary session with all chunk outputs
-func (c *Client) GenerateSummary(ctx context.Context, outputs []ChunkOutput, model string) error {
+// GenerateSummary creates a summary session with all chunk outputs and returns its token usage
+func (c *Client) GenerateSummary(ctx context.Context, outputs []ChunkOutput, model string) (Usage, error) {
 	slog.Info("Creating summary session", slog.String("model", model))
 
 	// Create a session with streaming enabled
-	session, err := c.client.CreateSession(&copilot.SessionConfig{
+	sessionConfig := &copilot.SessionConfig{
 		Model:     model,
 		Streaming: true,
-	})
+	}
+	c.Sandbox.sessionConfig(sessionConfig)
+	session, err := c.client.CreateSession(sessionConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create summary session: %w", err)
+		return Usage{}, fmt.Errorf("failed to create summary session: %w", err)
 	}
 	defer func() {
 		if err := session.Destroy(); err != nil {
@@ -278,6 +340,9 @@ func (c *Client) GenerateSummary(ctx context.Context, outputs []ChunkOutput, mod
 
 	// Set up event handler
 	done := make(chan error, 1)
+	var summary string
+	var usage Usage
+	reported := false
 
 	session.On(func(event copilot.SessionEvent) {
 		switch event.Type {
@@ -294,6 +359,7 @@ func (c *Client) GenerateSummary(ctx context.Context, outputs []ChunkOutput, mod
 		case "assistant.message":
 			// Print final message in yellow for summary
 			if event.Data.Content != nil {
+				summary += *event.Data.Content
 				fmt.Println(formatSummaryOutput(*event.Data.Content))
 				slog.Debug("Summary response", slog.String("content", *event.Data.Content))
 			}
@@ -305,6 +371,10 @@ func (c *Client) GenerateSummary(ctx context.Context, outputs []ChunkOutput, mod
 				slog.Debug("Summary reasoning", slog.String("content", *event.Data.Content))
 			}
 
+		case "assistant.usage":
+			usage.Add(usageFromEvent(event.Data))
+			reported = true
+
 		case "session.idle":
 			slog.Info("Summary session completed")
 			done <- nil
@@ -320,7 +390,7 @@ func (c *Client) GenerateSummary(ctx context.Context, outputs []ChunkOutput, mod
 	})
 
 	// Build summary prompt
-	summaryPrompt := buildSummaryPrompt(outputs)
+	summaryPrompt := BuildSummaryPrompt(outputs, c.SummaryInstructions)
 
 	slog.Info("Sending summary prompt to Copilot")
 
@@ -328,30 +398,45 @@ func (c *Client) GenerateSummary(ctx context.Context, outputs []ChunkOutput, mod
 		Prompt: summaryPrompt,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to send summary message: %w", err)
+		return Usage{}, fmt.Errorf("failed to send summary message: %w", err)
+	}
+
+	sessionUsage := func() Usage {
+		if reported {
+			return usage
+		}
+		return EstimateUsage(summaryPrompt, summary)
 	}
 
 	// Wait for completion
 	select {
 	case err := <-done:
 		if err != nil {
-			return err
+			return sessionUsage(), err
 		}
 		fmt.Println() // Add newline after streaming output
-		return nil
+		return sessionUsage(), nil
 
 	case <-time.After(10 * time.Minute):
-		return fmt.Errorf("summary session timed out after 10 minutes")
+		return sessionUsage(), fmt.Errorf("summary session timed out after 10 minutes")
 
 	case <-ctx.Done():
-		return fmt.Errorf("summary session cancelled: %w", ctx.Err())
+		return sessionUsage(), fmt.Errorf("summary session cancelled: %w", ctx.Err())
 	}
 }
 
-// buildSummaryPrompt creates the prompt for the summary session
-func buildSummaryPrompt(outputs []ChunkOutput) string {
+// BuildSummaryPrompt creates the prompt for the summary session. Custom
+// instructions replace the built-in ones; the chunk outputs always follow.
+func BuildSummaryPrompt(outputs []ChunkOutput, instructions string) string {
 	var prompt strings.Builder
 
+	if instructions != "" {
+		prompt.WriteString(instructions)
+		prompt.WriteString("\n\n")
+		writeChunkOutputs(&prompt, outputs)
+		return prompt.String()
+	}
+
 	prompt.WriteString("# Summary Task\n\n")
 	prompt.WriteString("You have just processed multiple chunks of changes for a web project using Vanilla Framework.\n")
 	prompt.WriteString("Please provide a comprehensive summary of all the work completed.\n\n")
@@ -366,15 +451,19 @@ func buildSummaryPrompt(outputs []ChunkOutput) string {
 	prompt.WriteString("6. **Next Steps**: Recommended actions before creating a PR\n\n")
 	prompt.WriteString("Keep the summary concise but comprehensive. Focus on actionable information.\n\n")
 
+	writeChunkOutputs(&prompt, outputs)
+	return prompt.String()
+}
+
+// writeChunkOutputs appends the output of every chunk to the summary prompt
+func writeChunkOutputs(prompt *strings.Builder, outputs []ChunkOutput) {
 	prompt.WriteString("## Chunks Processed\n\n")
 
 	for _, output := range outputs {
-		fmt.Fprintf(&prompt, "### Chunk %d\n\n", output.ChunkNumber)
-		fmt.Fprintf(&prompt, "**Duration**: %s\n\n", output.Duration.Round(time.Millisecond))
+		fmt.Fprintf(prompt, "### Chunk %d\n\n", output.ChunkNumber)
+		fmt.Fprintf(prompt, "**Duration**: %s\n\n", output.Duration.Round(time.Millisecond))
 		prompt.WriteString("**Output**:\n```\n")
 		prompt.WriteString(output.Output)
 		prompt.WriteString("\n```\n\n")
 	}
-
-	return prompt.String()
 }
@@ -0,0 +1,68 @@
+package copilotcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	copilot "github.com/github/copilot-sdk/go"
+)
+
+// EventLogPath returns where the session events of a chunk are logged: next
+// to the chunk file, e.g. bauer-output/chunk-1.events.jsonl.
+func EventLogPath(chunkPath string, chunkNumber int) string {
+	return filepath.Join(filepath.Dir(chunkPath), fmt.Sprintf("chunk-%d.events.jsonl", chunkNumber))
+}
+
+// eventLog appends session events to a file as JSON lines, so failed runs can
+// be audited and the reasoning reviewed afterwards. Deltas are left out: the
+// complete messages they stream are logged too. Retries of a chunk append to
+// the same log.
+type eventLog struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func openEventLog(path string) (*eventLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	return &eventLog{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Write logs an event; failures are logged and don't interrupt the session.
+// A nil or closed log discards events.
+func (l *eventLog) Write(event copilot.SessionEvent) {
+	if l == nil || strings.HasSuffix(string(event.Type), "_delta") {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.enc == nil {
+		return
+	}
+	if err := l.enc.Encode(event); err != nil {
+		slog.Warn("Failed to write session event",
+			slog.String("event", string(event.Type)),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// Close closes the log file. Events of the session that arrive later, e.g.
+// after a timeout, are discarded.
+func (l *eventLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.enc = nil
+	return l.file.Close()
+}
@@ -0,0 +1,54 @@
+package copilotcli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	copilot "github.com/github/copilot-sdk/go"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEventLogPath(t *testing.T) {
+	got := EventLogPath(filepath.Join("bauer-output", "chunk-2-of-3.md"), 2)
+	if want := filepath.Join("bauer-output", "chunk-2.events.jsonl"); got != want {
+		t.Errorf("EventLogPath() = %q, want %q", got, want)
+	}
+}
+
+func TestEventLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chunk-1.events.jsonl")
+	content := "Done"
+
+	// A retry appends to the log of the first attempt
+	for range 2 {
+		events, err := openEventLog(path)
+		if err != nil {
+			t.Fatalf("openEventLog() error = %v", err)
+		}
+		events.Write(copilot.SessionEvent{Type: "assistant.message_delta", Data: copilot.Data{DeltaContent: &content}})
+		events.Write(copilot.SessionEvent{Type: "assistant.message", Data: copilot.Data{Content: &content}})
+		if err := events.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		events.Write(copilot.SessionEvent{Type: "session.idle"})
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event copilot.SessionEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid event line %q: %v", line, err)
+		}
+		types = append(types, string(event.Type))
+	}
+	if diff := cmp.Diff([]string{"assistant.message", "assistant.message"}, types); diff != "" {
+		t.Errorf("logged events mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,30 @@
+package copilotcli
+
+import (
+	"errors"
+	"time"
+)
+
+// Errors of a chunk session that are worth retrying: the session may succeed
+// on another attempt, unlike a missing chunk file or a stopped client. Every
+// executor wraps its session failures and timeouts in these.
+var (
+	ErrSession = errors.New("session error")
+	ErrTimeout = errors.New("timed out")
+)
+
+// Attempt is the outcome of one session of a chunk.
+type Attempt struct {
+	Number   int
+	Model    string
+	Duration time.Duration
+	// Error is empty for the attempt that succeeded
+	Error string
+	// Usage is the token usage of the session, including failed ones
+	Usage Usage
+}
+
+// Retryable reports whether a chunk that failed with err should be retried.
+func Retryable(err error) bool {
+	return errors.Is(err, ErrSession) || errors.Is(err, ErrTimeout)
+}
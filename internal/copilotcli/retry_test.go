@@ -0,0 +1,23 @@
+package copilotcli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("%w for chunk 1: overloaded", ErrSession), true},
+		{fmt.Errorf("chunk 1 %w after 15 minutes", ErrTimeout), true},
+		{errors.New("failed to resolve chunk path"), false},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.want {
+			t.Errorf("Retryable(%q) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package copilotcli
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	copilot "github.com/github/copilot-sdk/go"
+)
+
+// Sandbox restricts what Copilot sessions may do, e.g. to run unattended in CI.
+// The zero value leaves the Copilot CLI defaults in place.
+type Sandbox struct {
+	// AllowedTools, when set, are the only tools available to sessions;
+	// ExcludedTools are removed otherwise
+	AllowedTools  []string
+	ExcludedTools []string
+
+	// DenyShell refuses to run shell commands
+	DenyShell bool
+
+	// DenyNetwork refuses URL fetches and MCP server calls
+	DenyNetwork bool
+
+	// WritableRoot, when set, refuses file writes outside this directory
+	WritableRoot string
+}
+
+// sessionConfig applies the sandbox to a session configuration.
+func (s Sandbox) sessionConfig(config *copilot.SessionConfig) {
+	config.AvailableTools = s.AllowedTools
+	config.ExcludedTools = s.ExcludedTools
+	if s.DenyShell || s.DenyNetwork || s.WritableRoot != "" {
+		config.OnPermissionRequest = s.handlePermission
+	}
+}
+
+// handlePermission approves the requests the sandbox allows.
+func (s Sandbox) handlePermission(request copilot.PermissionRequest, invocation copilot.PermissionInvocation) (copilot.PermissionRequestResult, error) {
+	if reason := s.deny(request); reason != "" {
+		slog.Warn("Copilot permission denied",
+			slog.String("kind", request.Kind),
+			slog.String("reason", reason),
+		)
+		return copilot.PermissionRequestResult{Kind: "denied-by-rules"}, nil
+	}
+	return copilot.PermissionRequestResult{Kind: "approved"}, nil
+}
+
+// deny returns why the sandbox refuses a permission request, or "" to allow it.
+func (s Sandbox) deny(request copilot.PermissionRequest) string {
+	switch request.Kind {
+	case "shell":
+		if s.DenyShell {
+			return "shell commands are disabled"
+		}
+	case "url", "mcp":
+		if s.DenyNetwork {
+			return "network access is disabled"
+		}
+	case "write":
+		if s.WritableRoot == "" {
+			return ""
+		}
+		path := requestPath(request)
+		if path == "" {
+			return "write without a path"
+		}
+		if !within(s.WritableRoot, path) {
+			return fmt.Sprintf("%s is outside %s", path, s.WritableRoot)
+		}
+	}
+	return ""
+}
+
+// requestPath returns the file a permission request is about, if any.
+func requestPath(request copilot.PermissionRequest) string {
+	for _, key := range []string{"fileName", "path", "filePath"} {
+		if path, ok := request.Extra[key].(string); ok && path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// within reports whether path is inside root; relative paths are relative to root.
+func within(root, path string) bool {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	rel, err := filepath.Rel(root, filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
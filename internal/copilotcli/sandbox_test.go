@@ -0,0 +1,36 @@
+package copilotcli
+
+import (
+	"testing"
+
+	copilot "github.com/github/copilot-sdk/go"
+)
+
+func TestSandbox_Deny(t *testing.T) {
+	sandbox := Sandbox{DenyShell: true, DenyNetwork: true, WritableRoot: "/repo"}
+	tests := []struct {
+		kind  string
+		extra map[string]interface{}
+		deny  bool
+	}{
+		{"read", map[string]interface{}{"path": "/etc/hosts"}, false},
+		{"write", map[string]interface{}{"fileName": "/repo/templates/index.html"}, false},
+		{"write", map[string]interface{}{"fileName": "templates/index.html"}, false},
+		{"write", map[string]interface{}{"fileName": "/repo/../etc/hosts"}, true},
+		{"write", map[string]interface{}{"fileName": "/repository/index.html"}, true},
+		{"write", nil, true},
+		{"shell", map[string]interface{}{"fullCommandText": "ls"}, true},
+		{"url", map[string]interface{}{"url": "https://ubuntu.com"}, true},
+		{"mcp", nil, true},
+	}
+	for _, tt := range tests {
+		reason := sandbox.deny(copilot.PermissionRequest{Kind: tt.kind, Extra: tt.extra})
+		if (reason != "") != tt.deny {
+			t.Errorf("deny(%s %v) = %q, want denied %v", tt.kind, tt.extra, reason, tt.deny)
+		}
+	}
+
+	if reason := (Sandbox{}).deny(copilot.PermissionRequest{Kind: "shell"}); reason != "" {
+		t.Errorf("Empty sandbox denied shell: %q", reason)
+	}
+}
@@ -0,0 +1,97 @@
+package copilotcli
+
+import (
+	"fmt"
+
+	copilot "github.com/github/copilot-sdk/go"
+)
+
+// charsPerToken approximates the length of a token, for estimates.
+const charsPerToken = 4
+
+// Usage is the token usage of model calls.
+type Usage struct {
+	InputTokens      int `json:"input_tokens"`
+	OutputTokens     int `json:"output_tokens"`
+	CacheReadTokens  int `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+
+	// Cost is what the backend reports the calls cost, e.g. Copilot
+	// premium requests; zero when it reports none
+	Cost float64 `json:"cost,omitempty"`
+
+	// Estimated is true when some of the tokens were estimated from the
+	// length of the prompts and responses, as the backend reported no usage
+	Estimated bool `json:"estimated,omitempty"`
+}
+
+// Add adds the usage of other calls.
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheReadTokens += other.CacheReadTokens
+	u.CacheWriteTokens += other.CacheWriteTokens
+	u.Cost += other.Cost
+	u.Estimated = u.Estimated || other.Estimated
+}
+
+// Total is the number of input and output tokens.
+func (u Usage) Total() int {
+	return u.InputTokens + u.OutputTokens
+}
+
+// IsZero reports whether no usage was recorded.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// String renders the usage as "12000 tokens (10000 in, 2000 out), cost 1".
+func (u Usage) String() string {
+	s := fmt.Sprintf("%d tokens (%d in, %d out)", u.Total(), u.InputTokens, u.OutputTokens)
+	if u.Cost > 0 {
+		s += fmt.Sprintf(", cost %g", u.Cost)
+	}
+	if u.Estimated {
+		s += ", estimated"
+	}
+	return s
+}
+
+// EstimateUsage estimates the usage of a call from the length of its prompt
+// and response.
+func EstimateUsage(prompt, response string) Usage {
+	return Usage{
+		InputTokens:  (len(prompt) + charsPerToken - 1) / charsPerToken,
+		OutputTokens: (len(response) + charsPerToken - 1) / charsPerToken,
+		Estimated:    true,
+	}
+}
+
+// usageFromEvent reads the usage of an assistant.usage event.
+func usageFromEvent(data copilot.Data) Usage {
+	count := func(v *float64) int {
+		if v == nil {
+			return 0
+		}
+		return int(*v)
+	}
+	u := Usage{
+		InputTokens:      count(data.InputTokens),
+		OutputTokens:     count(data.OutputTokens),
+		CacheReadTokens:  count(data.CacheReadTokens),
+		CacheWriteTokens: count(data.CacheWriteTokens),
+	}
+	if data.Cost != nil {
+		u.Cost = *data.Cost
+	}
+	return u
+}
+
+// TotalUsage adds up the usage of every attempt of a chunk.
+func (o ChunkOutput) TotalUsage() Usage {
+	var total Usage
+	for _, attempt := range o.Attempts {
+		total.Add(attempt.Usage)
+	}
+	return total
+}
@@ -0,0 +1,230 @@
+// Package direct applies simple suggestions to the target repository without
+// Copilot: literal text changes whose anchors match exactly once in the
+// resolved file of their location, or match closely enough when whitespace,
+// entities or a few characters differ. The others are left for the chunks.
+package direct
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"bauer/internal/gdocs"
+)
+
+// PatchesFile is the name of the file listing the patches of a run, written
+// to the output directory.
+const PatchesFile = "bauer-direct-patches.json"
+
+// Patch is a suggestion applied as a literal replacement in a file.
+type Patch struct {
+	SuggestionIDs []string `json:"suggestion_ids"`
+	LocationID    string   `json:"location_id,omitempty"`
+
+	// File is relative to the root of the repository
+	File string `json:"file"`
+
+	// Old is the text replaced, with its anchors, and New the text replacing it
+	Old string `json:"old"`
+	New string `json:"new"`
+
+	// Match is how the text was found: MatchExact, MatchNormalized or
+	// MatchFuzzy, and Confidence its similarity with the document's, from 0
+	// to 1
+	Match      string  `json:"match"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Deferral is a suggestion whose text was found in its file, but not
+// similar enough to the document's to be patched; Copilot applies it.
+type Deferral struct {
+	SuggestionIDs []string `json:"suggestion_ids"`
+	LocationID    string   `json:"location_id,omitempty"`
+	File          string   `json:"file"`
+	Found         string   `json:"found"`
+	Confidence    float64  `json:"confidence"`
+}
+
+// Plan is the patches of a set of location groups, and the contents of the
+// files once patched.
+type Plan struct {
+	Patches  []Patch    `json:"patches"`
+	Deferred []Deferral `json:"deferred"`
+
+	// Threshold is the confidence patched fuzzy matches have at least
+	Threshold float64 `json:"threshold"`
+
+	// Remaining are the location groups without the patched suggestions;
+	// groups left without suggestions are dropped
+	Remaining []gdocs.LocationGroupedSuggestions `json:"-"`
+
+	files map[string]string
+}
+
+// NewPlan finds the suggestions of groups that can be applied directly to
+// the files of repoRoot: matches with a confidence of threshold at least, or
+// DefaultThreshold when it's 0. Suggestions of a file are patched in order,
+// each against the file as patched by those before it.
+func NewPlan(repoRoot string, groups []gdocs.LocationGroupedSuggestions, threshold float64) *Plan {
+	if threshold == 0 {
+		threshold = DefaultThreshold
+	}
+	plan := &Plan{Patches: []Patch{}, Deferred: []Deferral{}, Threshold: threshold, files: map[string]string{}}
+	for _, group := range groups {
+		remaining := group
+		remaining.Suggestions = nil
+		for _, sugg := range group.Suggestions {
+			if patch, ok := plan.patch(repoRoot, group, sugg); ok {
+				plan.Patches = append(plan.Patches, patch)
+				continue
+			}
+			remaining.Suggestions = append(remaining.Suggestions, sugg)
+		}
+		if len(remaining.Suggestions) > 0 {
+			plan.Remaining = append(plan.Remaining, remaining)
+		}
+	}
+	return plan
+}
+
+// patch replaces the text of a suggestion in the patched file of its group.
+func (p *Plan) patch(repoRoot string, group gdocs.LocationGroupedSuggestions, sugg gdocs.GroupedActionableSuggestion) (Patch, bool) {
+	if !Applicable(group, sugg) {
+		return Patch{}, false
+	}
+	content, ok := p.files[group.ResolvedFile]
+	if !ok {
+		data, err := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(group.ResolvedFile)))
+		if err != nil {
+			return Patch{}, false
+		}
+		content = string(data)
+	}
+
+	n, ok := literal(sugg)
+	if !ok {
+		return Patch{}, false
+	}
+	m, ok := find(content, n, p.Threshold)
+	if !ok {
+		return Patch{}, false
+	}
+	if m.confidence < p.Threshold {
+		p.Deferred = append(p.Deferred, Deferral{
+			SuggestionIDs: sugg.SuggestionIDs(),
+			LocationID:    group.LocationID,
+			File:          group.ResolvedFile,
+			Found:         content[m.start:m.end],
+			Confidence:    m.confidence,
+		})
+		return Patch{}, false
+	}
+	// Fuzzy matches mustn't change markup the document doesn't have
+	if strings.ContainsAny(content[m.changeStart:m.changeEnd], "<>{}") {
+		return Patch{}, false
+	}
+
+	patched := content[:m.changeStart] + sugg.Change.NewText + content[m.changeEnd:]
+	p.files[group.ResolvedFile] = patched
+	newEnd := m.end + len(patched) - len(content)
+	return Patch{
+		SuggestionIDs: sugg.SuggestionIDs(),
+		LocationID:    group.LocationID,
+		File:          group.ResolvedFile,
+		Old:           content[m.start:m.end],
+		New:           patched[m.start:newEnd],
+		Match:         m.kind,
+		Confidence:    m.confidence,
+	}, true
+}
+
+// Applicable reports whether a suggestion is a plain text change that can be
+// patched without judgement: no styles, links, paragraphs, moves or
+// metadata, in a location whose file is known and without reviewer comments.
+func Applicable(group gdocs.LocationGroupedSuggestions, sugg gdocs.GroupedActionableSuggestion) bool {
+	if group.ResolvedFile == "" || group.Location.InMetadata || len(group.Comments) > 0 {
+		return false
+	}
+	change := sugg.Change
+	switch change.Type {
+	case "insert", "delete", "replace":
+	default:
+		return false
+	}
+	if sugg.Stale || sugg.MovedFrom != nil || change.Paragraph || change.Style != nil ||
+		change.OriginalLinkURL != "" || change.NewLinkURL != "" || group.Location.Element != "" {
+		return false
+	}
+	// Markup and line breaks need to be written the way the template does
+	for _, text := range []string{change.OriginalText, change.NewText} {
+		if strings.ContainsAny(text, "<>&\n{}") {
+			return false
+		}
+	}
+	return true
+}
+
+// literal returns the text of a suggestion in its paragraph, with the
+// anchors around it.
+func literal(sugg gdocs.GroupedActionableSuggestion) (needle, bool) {
+	preceding := sugg.Anchor.PrecedingText
+	if idx := strings.LastIndex(preceding, "\n"); idx != -1 {
+		preceding = preceding[idx+1:]
+	}
+	following := sugg.Anchor.FollowingText
+	if idx := strings.Index(following, "\n"); idx != -1 {
+		following = following[:idx]
+	}
+	// An insertion must be anchored on at least one side
+	if sugg.Change.OriginalText == "" && (preceding == "" || following == "") {
+		return needle{}, false
+	}
+	return needle{
+		text:        preceding + sugg.Change.OriginalText + following,
+		changeStart: len(preceding),
+		changeEnd:   len(preceding) + len(sugg.Change.OriginalText),
+	}, true
+}
+
+// Files returns the files the plan changes, sorted.
+func (p *Plan) Files() []string {
+	files := make([]string, 0, len(p.files))
+	for _, patch := range p.Patches {
+		if !slices.Contains(files, patch.File) {
+			files = append(files, patch.File)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Apply writes the patched files to repoRoot.
+func (p *Plan) Apply(repoRoot string) error {
+	for _, file := range p.Files() {
+		path := filepath.Join(repoRoot, filepath.FromSlash(file))
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to patch %s: %w", file, err)
+		}
+		if err := os.WriteFile(path, []byte(p.files[file]), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to patch %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// Save writes the patches to path, for review.
+func (p *Plan) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal direct patches: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write direct patches: %w", err)
+	}
+	return nil
+}
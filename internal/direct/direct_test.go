@@ -0,0 +1,138 @@
+package direct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bauer/internal/gdocs"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func suggestion(id, changeType, preceding, original, replacement, following string) gdocs.GroupedActionableSuggestion {
+	return gdocs.GroupedActionableSuggestion{
+		ID:     id,
+		Anchor: gdocs.SuggestionAnchor{PrecedingText: preceding, FollowingText: following},
+		Change: gdocs.SuggestionChange{Type: changeType, OriginalText: original, NewText: replacement},
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "templates", "index.html")
+	if err := os.MkdirAll(filepath.Dir(page), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "<h1>Ubuntu for the enterprise</h1>\n<p>Secure and supported for ten years.</p>\n<p>Get Ubuntu Pro for free.</p>\n<p>Get Ubuntu Pro for free.</p>\n"
+	if err := os.WriteFile(page, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	groups := []gdocs.LocationGroupedSuggestions{
+		{LocationID: "loc-1", ResolvedFile: "templates/index.html", Suggestions: []gdocs.GroupedActionableSuggestion{
+			suggestion("s1", "replace", "Heading\nUbuntu for the ", "enterprise", "business", "\nSecure and"),
+			suggestion("s2", "insert", "Secure and supported for ", "", "up to ", "ten years."),
+			// Matches twice
+			suggestion("s3", "replace", "Get ", "Ubuntu Pro", "Pro", " for free."),
+			// Not a plain text change
+			{ID: "s4", Change: gdocs.SuggestionChange{Type: "style", Style: &gdocs.StyleDelta{}}},
+		}},
+		{LocationID: "loc-2", Suggestions: []gdocs.GroupedActionableSuggestion{
+			suggestion("s5", "delete", "Secure ", "and supported ", "", "for"),
+		}},
+	}
+
+	plan := NewPlan(dir, groups, 0)
+	want := []Patch{
+		{SuggestionIDs: []string{"s1"}, LocationID: "loc-1", File: "templates/index.html", Old: "Ubuntu for the enterprise", New: "Ubuntu for the business", Match: MatchExact, Confidence: 1},
+		{SuggestionIDs: []string{"s2"}, LocationID: "loc-1", File: "templates/index.html", Old: "Secure and supported for ten years.", New: "Secure and supported for up to ten years.", Match: MatchExact, Confidence: 1},
+	}
+	if diff := cmp.Diff(want, plan.Patches); diff != "" {
+		t.Errorf("NewPlan() patches mismatch (-want +got):\n%s", diff)
+	}
+	var remaining []string
+	for _, group := range plan.Remaining {
+		for _, sugg := range group.Suggestions {
+			remaining = append(remaining, group.LocationID+"/"+sugg.ID)
+		}
+	}
+	if diff := cmp.Diff([]string{"loc-1/s3", "loc-1/s4", "loc-2/s5"}, remaining); diff != "" {
+		t.Errorf("NewPlan() remaining mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := plan.Apply(dir); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	data, err := os.ReadFile(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantContent := "<h1>Ubuntu for the business</h1>\n<p>Secure and supported for up to ten years.</p>\n<p>Get Ubuntu Pro for free.</p>\n<p>Get Ubuntu Pro for free.</p>\n"
+	if diff := cmp.Diff(wantContent, string(data)); diff != "" {
+		t.Errorf("Apply() content mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFind(t *testing.T) {
+	content := "<p>Get&nbsp;the   latest\n    Ubuntu&rsquo;s features today.</p>\n<p>Ubuntu Pro keeps systems secure.</p>\n"
+	for _, tt := range []struct {
+		name       string
+		preceding  string
+		original   string
+		following  string
+		threshold  float64
+		wantKind   string
+		wantChange string
+		wantOK     bool
+	}{
+		{"exact", "Ubuntu Pro keeps ", "systems", " secure.", DefaultThreshold, MatchExact, "systems", true},
+		{"whitespace and entities", "Get the latest ", "Ubuntu’s", " features today.", DefaultThreshold, MatchNormalized, "Ubuntu&rsquo;s", true},
+		{"typo", "Ubuntu Pro keeps ", "system", " secure.", DefaultThreshold, MatchFuzzy, "systems", true},
+		{"not found", "Nothing ", "like", " this at all.", DefaultThreshold, "", "", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			n := needle{
+				text:        tt.preceding + tt.original + tt.following,
+				changeStart: len(tt.preceding),
+				changeEnd:   len(tt.preceding) + len(tt.original),
+			}
+			m, ok := find(content, n, tt.threshold)
+			if ok && m.confidence < tt.threshold {
+				ok = false
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("find() ok = %v (confidence %.2f), want %v", ok, m.confidence, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if m.kind != tt.wantKind {
+				t.Errorf("find() kind = %s, want %s", m.kind, tt.wantKind)
+			}
+			if got := content[m.changeStart:m.changeEnd]; got != tt.wantChange {
+				t.Errorf("find() change = %q, want %q", got, tt.wantChange)
+			}
+		})
+	}
+}
+
+func TestNewPlan_Deferred(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<p>Ubuntu Pro keeps systems secure.</p>\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	groups := []gdocs.LocationGroupedSuggestions{
+		{ResolvedFile: "index.html", Suggestions: []gdocs.GroupedActionableSuggestion{
+			suggestion("s1", "replace", "Ubuntu Pro keeps ", "system", "servers", " secure and safe."),
+		}},
+	}
+
+	plan := NewPlan(dir, groups, 0.95)
+	if len(plan.Patches) != 0 || len(plan.Deferred) != 1 || len(plan.Remaining) != 1 {
+		t.Fatalf("NewPlan() = %d patches, %d deferred, %d remaining, want 0, 1, 1", len(plan.Patches), len(plan.Deferred), len(plan.Remaining))
+	}
+	if got := plan.Deferred[0].Confidence; got >= 0.95 || got < 0.5 {
+		t.Errorf("NewPlan() deferred confidence = %.2f, want between 0.5 and 0.95", got)
+	}
+}
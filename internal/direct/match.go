@@ -0,0 +1,237 @@
+package direct
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultThreshold is the similarity a fuzzy match needs to be patched.
+const DefaultThreshold = 0.9
+
+// minConfidence is the similarity under which a match isn't reported at
+// all; matches between it and the threshold are deferred to Copilot.
+const minConfidence = 0.5
+
+// Kinds of matches, from the most to the least reliable.
+const (
+	MatchExact      = "exact"      // The text is in the file as is
+	MatchNormalized = "normalized" // Only whitespace, entities or quotes differ
+	MatchFuzzy      = "fuzzy"      // Some characters differ
+)
+
+// match is where the text of a suggestion is in a file.
+type match struct {
+	kind       string
+	confidence float64
+
+	// start and end are the byte offsets of the text of the suggestion with
+	// its anchors; changeStart and changeEnd of the text it changes
+	start, end             int
+	changeStart, changeEnd int
+}
+
+// needle is the text of a suggestion with its anchors, and where the text it
+// changes starts and ends in it.
+type needle struct {
+	text                   string
+	changeStart, changeEnd int
+}
+
+// find looks for a needle in content: as is, then with whitespace, entities
+// and quotes normalized, then allowing edits. It returns false when the best
+// match is less similar than minConfidence, or isn't the only one within
+// threshold.
+func find(content string, n needle, threshold float64) (match, bool) {
+	if strings.Count(content, n.text) == 1 {
+		start := strings.Index(content, n.text)
+		return match{
+			kind:        MatchExact,
+			confidence:  1,
+			start:       start,
+			end:         start + len(n.text),
+			changeStart: start + n.changeStart,
+			changeEnd:   start + n.changeEnd,
+		}, true
+	}
+
+	text := normalize(content)
+	pattern := normalize(n.text)
+	if len(pattern.runes) == 0 {
+		return match{}, false
+	}
+	maxDistance := int(float64(len(pattern.runes)) * (1 - minConfidence))
+	allowed := int(float64(len(pattern.runes)) * (1 - threshold))
+	distance, end, ambiguous := search(text.runes, pattern.runes, maxDistance, allowed)
+	if distance < 0 || ambiguous {
+		return match{}, false
+	}
+
+	// Align the pattern with the text it matched, to find where the change
+	// is in it
+	windowStart := max(0, end-len(pattern.runes)-distance)
+	window := text.runes[windowStart:end]
+	start, positions := align(window, pattern.runes)
+	changeStart := pattern.index(n.changeStart)
+	changeEnd := pattern.index(n.changeEnd)
+
+	m := match{
+		kind:        MatchNormalized,
+		confidence:  1 - float64(distance)/float64(len(pattern.runes)),
+		start:       text.offset(windowStart + start),
+		end:         text.offset(end),
+		changeStart: text.offset(windowStart + positions[changeStart]),
+		changeEnd:   text.offset(windowStart + positions[changeEnd]),
+	}
+	if distance > 0 {
+		m.kind = MatchFuzzy
+	}
+	return m, true
+}
+
+// search finds the end of the substring of text closest to pattern by edit
+// distance, in one pass (Sellers' algorithm). It returns -1 when no
+// substring is within maxDistance, and whether another one, not overlapping
+// it, is within allowed as well.
+func search(text, pattern []rune, maxDistance, allowed int) (distance, end int, ambiguous bool) {
+	m := len(pattern)
+	column := make([]int, m+1)
+	for i := range column {
+		column[i] = i
+	}
+	distance, end = -1, -1
+	var candidates []int
+	for j := 1; j <= len(text); j++ {
+		diagonal := column[0]
+		column[0] = 0
+		for i := 1; i <= m; i++ {
+			cost := 1
+			if pattern[i-1] == text[j-1] {
+				cost = 0
+			}
+			next := min(diagonal+cost, column[i]+1, column[i-1]+1)
+			diagonal = column[i]
+			column[i] = next
+		}
+		d := column[m]
+		if d <= allowed {
+			candidates = append(candidates, j)
+		}
+		if d <= maxDistance && (distance < 0 || d < distance) {
+			distance, end = d, j
+		}
+	}
+	for _, candidate := range candidates {
+		if candidate <= end-m || candidate >= end+m {
+			ambiguous = true
+		}
+	}
+	return distance, end, ambiguous
+}
+
+// align finds the alignment of pattern with the end of window closest by
+// edit distance. It returns where in window the alignment starts, and the
+// position in window of each position of pattern, the end included.
+func align(window, pattern []rune) (int, []int) {
+	m, w := len(pattern), len(window)
+	dist := make([][]int, m+1)
+	for i := range dist {
+		dist[i] = make([]int, w+1)
+		dist[i][0] = i
+	}
+	for i := 1; i <= m; i++ {
+		for j := 1; j <= w; j++ {
+			cost := 1
+			if pattern[i-1] == window[j-1] {
+				cost = 0
+			}
+			dist[i][j] = min(dist[i-1][j-1]+cost, dist[i-1][j]+1, dist[i][j-1]+1)
+		}
+	}
+
+	// Walk back from the end of both, preferring matches
+	positions := make([]int, m+1)
+	i, j := m, w
+	positions[m] = w
+	for i > 0 {
+		cost := 1
+		if j > 0 && pattern[i-1] == window[j-1] {
+			cost = 0
+		}
+		switch {
+		case j > 0 && dist[i][j] == dist[i-1][j-1]+cost:
+			i, j = i-1, j-1
+		case dist[i][j] == dist[i-1][j]+1:
+			i--
+		default:
+			j--
+			continue
+		}
+		positions[i] = j
+	}
+	return j, positions
+}
+
+// normalized is text with runs of whitespace collapsed to a space, entities
+// decoded and typographic quotes made straight, with the byte offset in the
+// original text of each rune.
+type normalized struct {
+	runes   []rune
+	offsets []int
+	length  int
+}
+
+var entityPattern = regexp.MustCompile(`^&(#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);`)
+
+var straightQuotes = map[rune]rune{
+	'‘': '\'', '’': '\'', '“': '"', '”': '"',
+}
+
+func normalize(s string) normalized {
+	n := normalized{length: len(s)}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == '&' {
+			if entity := entityPattern.FindString(s[i:]); entity != "" {
+				if decoded := []rune(html.UnescapeString(entity)); len(decoded) == 1 {
+					r, size = decoded[0], len(entity)
+				}
+			}
+		}
+		if q, ok := straightQuotes[r]; ok {
+			r = q
+		}
+		if unicode.IsSpace(r) {
+			if len(n.runes) > 0 && n.runes[len(n.runes)-1] == ' ' {
+				i += size
+				continue
+			}
+			r = ' '
+		}
+		n.runes = append(n.runes, r)
+		n.offsets = append(n.offsets, i)
+		i += size
+	}
+	return n
+}
+
+// offset returns the byte offset in the original text of a rune.
+func (n normalized) offset(index int) int {
+	if index >= len(n.offsets) {
+		return n.length
+	}
+	return n.offsets[index]
+}
+
+// index returns the rune at a byte offset of the original text, or the one
+// after it when it was collapsed.
+func (n normalized) index(offset int) int {
+	for i, o := range n.offsets {
+		if o >= offset {
+			return i
+		}
+	}
+	return len(n.offsets)
+}
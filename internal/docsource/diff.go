@@ -0,0 +1,373 @@
+package docsource
+
+import (
+	"bauer/internal/gdocs"
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// SourceDiff builds suggestions from the differences between two versions of a page.
+const SourceDiff = "diff"
+
+// gitPrefix marks a version read from git: "git:<revision>:<path>"
+const gitPrefix = "git:"
+
+var (
+	htmlDropPattern    = regexp.MustCompile(`(?is)<script.*?</script>|<style.*?</style>|<!--.*?-->|\{[%#].*?[%#]\}`)
+	htmlHeadingPattern = regexp.MustCompile(`(?i)<h([1-6])\b[^>]*>`)
+	htmlBreakPattern   = regexp.MustCompile(`(?i)</(p|h[1-6]|li|td|th|div|section|blockquote|dt|dd|figcaption|tr|ul|ol|table)>|<br\s*/?>`)
+	htmlTagPattern     = regexp.MustCompile(`<[^>]*>`)
+	mdHeadingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	mdListPattern      = regexp.MustCompile(`^\s*(?:[-*+]|\d+\.)\s+`)
+	spacePattern       = regexp.MustCompile(`\s+`)
+	tokenPattern       = regexp.MustCompile(`\S+|\s+`)
+)
+
+// DiffProvider turns the differences between two versions of a page (HTML or
+// Markdown files, or git revisions) into suggestions, so content diffs can
+// drive the same prompt and PR pipeline as Google Docs suggestions.
+type DiffProvider struct {
+	// Before and After are file paths, or "git:<revision>:<path>"
+	Before string
+	After  string
+
+	// Dir is the repository git revisions are read from; empty means the
+	// current directory
+	Dir string
+}
+
+// ProcessDocument diffs the two versions and groups the changes by location.
+// docID defaults to the name of the After file.
+func (p *DiffProvider) ProcessDocument(ctx context.Context, docID string) (*gdocs.ProcessingResult, error) {
+	doc, err := p.read(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	return gdocs.BuildProcessingResult(doc), nil
+}
+
+// FetchSuggestions returns the differences as suggestions.
+func (p *DiffProvider) FetchSuggestions(ctx context.Context, docID string) ([]gdocs.Suggestion, error) {
+	doc, err := p.read(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	return gdocs.ExtractSuggestions(doc), nil
+}
+
+// FetchComments returns nothing; diffs have no comments.
+func (p *DiffProvider) FetchComments(ctx context.Context, docID string) ([]gdocs.Comment, error) {
+	return nil, nil
+}
+
+func (p *DiffProvider) read(ctx context.Context, docID string) (*docs.Document, error) {
+	before, err := readVersion(ctx, p.Dir, p.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := readVersion(ctx, p.Dir, p.After)
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(strings.TrimPrefix(p.After, gitPrefix))
+	if docID == "" {
+		docID = name
+	}
+
+	doc := DiffDocument(parseBlocks(before, p.Before), parseBlocks(after, p.After))
+	doc.DocumentId = docID
+	doc.Title = name
+	return doc, nil
+}
+
+// readVersion reads a file, or a file at a git revision for
+// "git:<revision>:<path>" from the repository in dir.
+func readVersion(ctx context.Context, dir, spec string) (string, error) {
+	if rest, ok := strings.CutPrefix(spec, gitPrefix); ok {
+		cmd := exec.CommandContext(ctx, "git", "show", rest)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s from git: %w", rest, err)
+		}
+		return string(out), nil
+	}
+
+	data, err := os.ReadFile(spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", spec, err)
+	}
+	return string(data), nil
+}
+
+// block is a paragraph of a page; heading is its level, or 0 for body text.
+type block struct {
+	text    string
+	heading int
+}
+
+// parseBlocks splits a page into paragraphs, as Markdown for .md files and as HTML otherwise.
+func parseBlocks(content, name string) []block {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".md", ".markdown":
+		return parseMarkdownBlocks(content)
+	default:
+		return parseHTMLBlocks(content)
+	}
+}
+
+func parseHTMLBlocks(content string) []block {
+	content = htmlDropPattern.ReplaceAllString(content, " ")
+	// Mark block boundaries and headings before the remaining markup is stripped
+	content = htmlHeadingPattern.ReplaceAllString(content, "\x00\x01$1")
+	content = htmlBreakPattern.ReplaceAllString(content, "\x00")
+	content = htmlTagPattern.ReplaceAllString(content, " ")
+
+	var blocks []block
+	for _, part := range strings.Split(content, "\x00") {
+		b := block{}
+		if strings.HasPrefix(part, "\x01") && len(part) > 1 {
+			b.heading = int(part[1] - '0')
+			part = part[2:]
+		}
+		b.text = collapseSpace(html.UnescapeString(part))
+		if b.text != "" {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+func parseMarkdownBlocks(content string) []block {
+	var blocks []block
+	var paragraph []string
+	flush := func() {
+		if text := collapseSpace(strings.Join(paragraph, " ")); text != "" {
+			blocks = append(blocks, block{text: text})
+		}
+		paragraph = nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case mdHeadingPattern.MatchString(trimmed):
+			flush()
+			match := mdHeadingPattern.FindStringSubmatch(trimmed)
+			blocks = append(blocks, block{text: collapseSpace(match[2]), heading: len(match[1])})
+		case mdListPattern.MatchString(line):
+			flush()
+			paragraph = append(paragraph, mdListPattern.ReplaceAllString(line, ""))
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+	return blocks
+}
+
+func collapseSpace(text string) string {
+	text = strings.ReplaceAll(text, "\u00a0", " ")
+	return strings.TrimSpace(spacePattern.ReplaceAllString(text, " "))
+}
+
+// diffOp is an edit operation on a sequence element
+type diffOp int
+
+const (
+	opEqual diffOp = iota
+	opDelete
+	opInsert
+)
+
+// segment is a piece of text with the operation that produced it
+type segment struct {
+	text string
+	op   diffOp
+}
+
+// diffSequences returns the edit script turning a into b, using the longest
+// common subsequence. Deletions come before insertions at each change.
+func diffSequences(a, b []string) []segment {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var script []segment
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			script = append(script, segment{a[i], opEqual})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			script = append(script, segment{a[i], opDelete})
+			i++
+		default:
+			script = append(script, segment{b[j], opInsert})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		script = append(script, segment{a[i], opDelete})
+	}
+	for ; j < m; j++ {
+		script = append(script, segment{b[j], opInsert})
+	}
+	return script
+}
+
+// diffParagraph is a paragraph of the combined document
+type diffParagraph struct {
+	segments []segment
+	heading  int
+}
+
+// DiffDocument builds a document containing both versions, with the removed
+// text as suggested deletions and the added text as suggested insertions.
+// Paragraphs are matched first; changed paragraphs are then diffed word by word.
+func DiffDocument(before, after []block) *docs.Document {
+	blockTexts := func(blocks []block) []string {
+		texts := make([]string, len(blocks))
+		for i, b := range blocks {
+			texts[i] = fmt.Sprintf("%d|%s", b.heading, b.text)
+		}
+		return texts
+	}
+
+	var paragraphs []diffParagraph
+	var deleted, inserted []block
+	flush := func() {
+		// Changed paragraphs of the same kind are paired up and diffed word by
+		// word; the rest are removed or added as a whole
+		pairs := 0
+		for pairs < min(len(deleted), len(inserted)) && deleted[pairs].heading == inserted[pairs].heading {
+			pairs++
+		}
+		for k := 0; k < pairs; k++ {
+			segments := diffSequences(tokenPattern.FindAllString(deleted[k].text, -1), tokenPattern.FindAllString(inserted[k].text, -1))
+			segments = append(joinChanges(segments), segment{"\n", opEqual})
+			paragraphs = append(paragraphs, diffParagraph{segments: segments, heading: inserted[k].heading})
+		}
+		for _, b := range deleted[pairs:] {
+			paragraphs = append(paragraphs, diffParagraph{segments: []segment{{b.text + "\n", opDelete}}, heading: b.heading})
+		}
+		for _, b := range inserted[pairs:] {
+			paragraphs = append(paragraphs, diffParagraph{segments: []segment{{b.text + "\n", opInsert}}, heading: b.heading})
+		}
+		deleted, inserted = nil, nil
+	}
+
+	bi, ai := 0, 0
+	for _, seg := range diffSequences(blockTexts(before), blockTexts(after)) {
+		switch seg.op {
+		case opEqual:
+			flush()
+			paragraphs = append(paragraphs, diffParagraph{segments: []segment{{before[bi].text + "\n", opEqual}}, heading: before[bi].heading})
+			bi++
+			ai++
+		case opDelete:
+			deleted = append(deleted, before[bi])
+			bi++
+		case opInsert:
+			inserted = append(inserted, after[ai])
+			ai++
+		}
+	}
+	flush()
+
+	return buildDiffDocument(paragraphs)
+}
+
+// joinChanges turns whitespace between two changes into a change of its own,
+// so a rewritten phrase becomes one replacement rather than one per word.
+func joinChanges(segments []segment) []segment {
+	var joined []segment
+	for i, seg := range segments {
+		if seg.op == opEqual && strings.TrimSpace(seg.text) == "" && i > 0 && i < len(segments)-1 &&
+			segments[i-1].op != opEqual && segments[i+1].op != opEqual {
+			joined = append(joined, segment{seg.text, opDelete}, segment{seg.text, opInsert})
+			continue
+		}
+		joined = append(joined, seg)
+	}
+	return joined
+}
+
+// buildDiffDocument converts the combined paragraphs to the Docs API model.
+// Contiguous changes share a suggestion ID, so they're merged into one change.
+func buildDiffDocument(paragraphs []diffParagraph) *docs.Document {
+	doc := &docs.Document{Body: &docs.Body{}}
+	index := int64(1)
+	suggestions := 0
+	inChange := false
+
+	for _, p := range paragraphs {
+		elem := &docs.StructuralElement{StartIndex: index}
+		para := &docs.Paragraph{ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: "NORMAL_TEXT"}}
+		if p.heading > 0 {
+			para.ParagraphStyle.NamedStyleType = fmt.Sprintf("HEADING_%d", p.heading)
+		}
+
+		for _, seg := range p.segments {
+			run := &docs.TextRun{Content: seg.text}
+			if seg.op == opEqual {
+				inChange = false
+			} else {
+				if !inChange {
+					suggestions++
+					inChange = true
+				}
+				id := fmt.Sprintf("diff.%d", suggestions)
+				if seg.op == opDelete {
+					run.SuggestedDeletionIds = []string{id}
+				} else {
+					run.SuggestedInsertionIds = []string{id}
+				}
+			}
+
+			length := int64(len(seg.text))
+			if n := len(para.Elements); n > 0 && sameSuggestion(para.Elements[n-1].TextRun, run) {
+				last := para.Elements[n-1]
+				last.TextRun.Content += seg.text
+				last.EndIndex += length
+			} else {
+				para.Elements = append(para.Elements, &docs.ParagraphElement{
+					StartIndex: index,
+					EndIndex:   index + length,
+					TextRun:    run,
+				})
+			}
+			index += length
+		}
+
+		elem.Paragraph = para
+		elem.EndIndex = index
+		doc.Body.Content = append(doc.Body.Content, elem)
+	}
+
+	return doc
+}
@@ -0,0 +1,86 @@
+package docsource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testBeforeHTML = `<html><head><style>h1 { color: red; }</style></head>
+<body>
+  <h1>Ubuntu Server</h1>
+  <p>Ubuntu is fast and secure.</p>
+  <p>Get it <a href="/download">now</a>.</p>
+  <p>Old paragraph.</p>
+</body></html>`
+
+const testAfterHTML = `<html><body>
+  <h1>Ubuntu Server</h1>
+  <p>Ubuntu is quick and very secure.</p>
+  <p>Get it <a href="/download">now</a>.</p>
+  <h2>Support</h2>
+</body></html>`
+
+func TestDiffProvider(t *testing.T) {
+	dir := t.TempDir()
+	before := filepath.Join(dir, "before.html")
+	after := filepath.Join(dir, "after.html")
+	if err := os.WriteFile(before, []byte(testBeforeHTML), 0644); err != nil {
+		t.Fatalf("Failed to write before: %v", err)
+	}
+	if err := os.WriteFile(after, []byte(testAfterHTML), 0644); err != nil {
+		t.Fatalf("Failed to write after: %v", err)
+	}
+
+	provider := &DiffProvider{Before: before, After: after}
+	result, err := provider.ProcessDocument(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ProcessDocument() failed: %v", err)
+	}
+	if result.DocumentID != "after.html" {
+		t.Errorf("Expected document ID after.html, got %q", result.DocumentID)
+	}
+
+	byID := make(map[string]string)
+	for _, group := range result.GroupedSuggestions {
+		for _, sugg := range group.Suggestions {
+			byID[sugg.ID] = sugg.Change.Type + ":" + sugg.Change.OriginalText + "->" + sugg.Change.NewText
+			if sugg.ID == "diff.1" && group.Location.ParentHeading != "Ubuntu Server" {
+				t.Errorf("Expected diff.1 under Ubuntu Server, got %+v", group.Location)
+			}
+		}
+	}
+
+	want := map[string]string{
+		"diff.1": "replace:fast->quick",
+		"diff.2": "insert:->very ",
+		"diff.3": "replace:Old paragraph.\n->Support\n",
+	}
+	for id, change := range want {
+		if byID[id] != change {
+			t.Errorf("%s: expected %q, got %q", id, change, byID[id])
+		}
+	}
+	if len(byID) != len(want) {
+		t.Errorf("Expected %d suggestions, got %v", len(want), byID)
+	}
+}
+
+func TestParseMarkdownBlocks(t *testing.T) {
+	blocks := parseMarkdownBlocks("# Title\n\nFirst line\nsecond line.\n\n- One\n- Two\n")
+	want := []block{
+		{text: "Title", heading: 1},
+		{text: "First line second line."},
+		{text: "One"},
+		{text: "Two"},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("Expected %d blocks, got %+v", len(want), blocks)
+	}
+	for i := range want {
+		if blocks[i] != want[i] {
+			t.Errorf("Block %d: expected %+v, got %+v", i, want[i], blocks[i])
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package docsource abstracts where review feedback comes from, so sources
+// other than Google Docs can feed the pipeline without changes to the orchestrator.
+package docsource
+
+import (
+	"bauer/internal/gdocs"
+	"context"
+	"fmt"
+)
+
+// Source names accepted by Open.
+const (
+	SourceGoogleDocs = "gdocs"
+)
+
+// Provider fetches a document and its review feedback. Suggestions and comments
+// use the gdocs model, which every source maps onto.
+type Provider interface {
+	// ProcessDocument fetches the document and extracts its suggestions,
+	// grouped by location and ready for prompt generation.
+	ProcessDocument(ctx context.Context, docID string) (*gdocs.ProcessingResult, error)
+
+	// FetchSuggestions returns the raw suggestions in the document.
+	FetchSuggestions(ctx context.Context, docID string) ([]gdocs.Suggestion, error)
+
+	// FetchComments returns the comments on the document.
+	FetchComments(ctx context.Context, docID string) ([]gdocs.Comment, error)
+}
+
+// Options configures the provider returned by Open.
+type Options struct {
+	// Source selects the provider; empty means Google Docs
+	Source string
+
+	// CredentialsMode and CredentialsPath select the Google Docs credentials
+	CredentialsMode string
+	CredentialsPath string
+
+	// APIMaxAttempts is the number of attempts per Google API call (default: 5)
+	APIMaxAttempts int
+
+	// NoCache always fetches the full document instead of reusing the cached
+	// copy of an unchanged revision
+	NoCache bool
+
+	// File is the local file read by file-based sources such as docx. For the
+	// diff source it's the new version of the page.
+	File string
+
+	// Before is the old version of the page compared by the diff source
+	Before string
+
+	// BeforeRevision and AfterRevision are the revisions of the Google Doc
+	// compared by the revisions source; an empty AfterRevision is the latest
+	BeforeRevision string
+	AfterRevision  string
+
+	// Tab is the ID or title of the only tab read of documents with tabs
+	Tab string
+
+	// RepoDir is the repository git versions of the diff source are read
+	// from; empty means the current directory
+	RepoDir string
+
+	// Replay reads a saved raw document instead of any source
+	Replay string
+}
+
+// Open returns the provider for opts.Source.
+func Open(ctx context.Context, opts Options) (Provider, error) {
+	if opts.Replay != "" {
+		return &ReplayProvider{Path: opts.Replay}, nil
+	}
+
+	switch opts.Source {
+	case "", SourceGoogleDocs:
+		return newClient(ctx, opts)
+	case SourceRevisions:
+		if opts.BeforeRevision == "" {
+			return nil, fmt.Errorf("the %s source requires a before revision", SourceRevisions)
+		}
+		client, err := newClient(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &RevisionProvider{Client: client, Before: opts.BeforeRevision, After: opts.AfterRevision}, nil
+	case SourceDocx:
+		if opts.File == "" {
+			return nil, fmt.Errorf("the %s source requires a file", SourceDocx)
+		}
+		return &DocxProvider{Path: opts.File}, nil
+	case SourceDiff:
+		if opts.Before == "" || opts.File == "" {
+			return nil, fmt.Errorf("the %s source requires a before and an after version", SourceDiff)
+		}
+		return &DiffProvider{Before: opts.Before, After: opts.File, Dir: opts.RepoDir}, nil
+	default:
+		return nil, fmt.Errorf("unknown document source: %s", opts.Source)
+	}
+}
+
+// newClient returns the Google Docs client selected by opts.
+func newClient(ctx context.Context, opts Options) (*gdocs.Client, error) {
+	clientOpts := gdocs.ClientOptions{
+		Mode:            gdocs.CredentialsMode(opts.CredentialsMode),
+		CredentialsPath: opts.CredentialsPath,
+		Retry:           gdocs.RetryPolicy{MaxAttempts: opts.APIMaxAttempts},
+		Tab:             opts.Tab,
+	}
+	if !opts.NoCache {
+		cacheDir, err := gdocs.DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		clientOpts.CacheDir = cacheDir
+	}
+	client, err := gdocs.NewClient(ctx, clientOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Google Docs client: %w", err)
+	}
+	return client, nil
+}
+
+var (
+	_ Provider = (*gdocs.Client)(nil)
+	_ Provider = (*DocxProvider)(nil)
+	_ Provider = (*DiffProvider)(nil)
+	_ Provider = (*RevisionProvider)(nil)
+	_ Provider = (*ReplayProvider)(nil)
+)
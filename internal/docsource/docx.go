@@ -0,0 +1,413 @@
+package docsource
+
+import (
+	"archive/zip"
+	"bauer/internal/gdocs"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// SourceDocx reads tracked changes from a local Word file.
+const SourceDocx = "docx"
+
+// DocxProvider reads a .docx file and turns its tracked changes (w:ins and
+// w:del) into suggestions. The file is converted to the Docs API model so it
+// goes through the same extraction and grouping as a Google Doc.
+type DocxProvider struct {
+	Path string
+}
+
+// ProcessDocument reads the file and extracts its suggestions and comments.
+// docID is used as the document ID; it defaults to the file name.
+func (p *DocxProvider) ProcessDocument(ctx context.Context, docID string) (*gdocs.ProcessingResult, error) {
+	file, err := p.read(docID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := gdocs.BuildProcessingResult(file.doc)
+	gdocs.AddComments(result, file.comments)
+	gdocs.AddSuggestionAuthors(result, file.authors)
+	return result, nil
+}
+
+// FetchSuggestions returns the tracked changes in the file.
+func (p *DocxProvider) FetchSuggestions(ctx context.Context, docID string) ([]gdocs.Suggestion, error) {
+	file, err := p.read(docID)
+	if err != nil {
+		return nil, err
+	}
+	suggestions := gdocs.ExtractSuggestions(file.doc)
+	for i := range suggestions {
+		suggestions[i].SuggestionAuthor = file.authors[suggestions[i].ID]
+	}
+	return suggestions, nil
+}
+
+// FetchComments returns the comments in the file, with the text they refer to.
+func (p *DocxProvider) FetchComments(ctx context.Context, docID string) ([]gdocs.Comment, error) {
+	file, err := p.read(docID)
+	if err != nil {
+		return nil, err
+	}
+	return file.comments, nil
+}
+
+// docxFile is a converted docx file
+type docxFile struct {
+	doc      *docs.Document
+	comments []gdocs.Comment
+
+	// authors holds the author and date of each suggestion, by suggestion ID
+	authors map[string]gdocs.SuggestionAuthor
+}
+
+func (p *DocxProvider) read(docID string) (*docxFile, error) {
+	archive, err := zip.OpenReader(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open docx file: %w", err)
+	}
+	defer archive.Close()
+
+	body, err := readZipXML(&archive.Reader, "word/document.xml")
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, fmt.Errorf("invalid docx file %s: word/document.xml not found", p.Path)
+	}
+	commentsXML, err := readZipXML(&archive.Reader, "word/comments.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	if docID == "" {
+		docID = filepath.Base(p.Path)
+	}
+	file := convertDocx(body, commentsXML)
+	file.doc.DocumentId = docID
+	file.doc.Title = strings.TrimSuffix(filepath.Base(p.Path), filepath.Ext(p.Path))
+	return file, nil
+}
+
+// xmlNode is a generic XML element; WordprocessingML is walked by local name.
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []xmlNode  `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+func (n *xmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (n *xmlNode) child(name string) *xmlNode {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == name {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// readZipXML parses a file of the archive, or returns nil if it doesn't exist.
+func readZipXML(archive *zip.Reader, name string) (*xmlNode, error) {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		defer r.Close()
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		var node xmlNode
+		if err := xml.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		return &node, nil
+	}
+	return nil, nil
+}
+
+// docxChange is the tracked change a run belongs to
+type docxChange struct {
+	kind   string // "", "ins" or "del"
+	id     string
+	author string
+	date   string
+}
+
+// docxConverter builds a Docs API document from WordprocessingML. Indices are
+// assigned sequentially from 1, counting bytes like the rest of the pipeline.
+type docxConverter struct {
+	index int64
+
+	// Adjacent changes by the same author form one suggestion, so a deletion
+	// followed by an insertion becomes a replacement
+	lastChange *docxChange
+	lastID     string
+
+	openComments map[string]bool
+	quoted       map[string]*strings.Builder
+	authors      map[string]gdocs.SuggestionAuthor
+}
+
+// convertDocx converts a parsed word/document.xml (and optional word/comments.xml).
+func convertDocx(document, commentsXML *xmlNode) *docxFile {
+	c := &docxConverter{
+		index:        1,
+		openComments: make(map[string]bool),
+		quoted:       make(map[string]*strings.Builder),
+		authors:      make(map[string]gdocs.SuggestionAuthor),
+	}
+
+	doc := &docs.Document{Body: &docs.Body{}}
+	if body := document.child("body"); body != nil {
+		doc.Body.Content = c.content(body.Nodes)
+	}
+
+	var comments []gdocs.Comment
+	if commentsXML != nil {
+		for _, node := range commentsXML.Nodes {
+			if node.XMLName.Local != "comment" {
+				continue
+			}
+			id := node.attr("id")
+			comment := gdocs.Comment{
+				ID:          id,
+				Author:      node.attr("author"),
+				CreatedTime: node.attr("date"),
+				Content:     strings.TrimSpace(nodeText(node.Nodes)),
+			}
+			if quoted, ok := c.quoted[id]; ok {
+				comment.QuotedContent = quoted.String()
+			}
+			comments = append(comments, comment)
+		}
+	}
+
+	return &docxFile{doc: doc, comments: comments, authors: c.authors}
+}
+
+func (c *docxConverter) content(nodes []xmlNode) []*docs.StructuralElement {
+	var elements []*docs.StructuralElement
+	for i := range nodes {
+		switch nodes[i].XMLName.Local {
+		case "p":
+			elements = append(elements, c.paragraph(&nodes[i]))
+		case "tbl":
+			elements = append(elements, c.table(&nodes[i]))
+		case "sdt":
+			// Content controls wrap regular content
+			if sdtContent := nodes[i].child("sdtContent"); sdtContent != nil {
+				elements = append(elements, c.content(sdtContent.Nodes)...)
+			}
+		}
+	}
+	return elements
+}
+
+func (c *docxConverter) paragraph(p *xmlNode) *docs.StructuralElement {
+	elem := &docs.StructuralElement{StartIndex: c.index}
+	para := &docs.Paragraph{ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: "NORMAL_TEXT"}}
+	if pPr := p.child("pPr"); pPr != nil {
+		if style := pPr.child("pStyle"); style != nil {
+			para.ParagraphStyle.NamedStyleType = headingStyle(style.attr("val"))
+		}
+	}
+
+	c.lastChange = nil
+	c.runs(p.Nodes, docxChange{}, para)
+	// The paragraph mark
+	c.appendText(para, "\n", docxChange{})
+
+	elem.Paragraph = para
+	elem.EndIndex = c.index
+	return elem
+}
+
+// runs walks the runs of a paragraph, inside tracked changes and hyperlinks.
+func (c *docxConverter) runs(nodes []xmlNode, change docxChange, para *docs.Paragraph) {
+	for i := range nodes {
+		node := &nodes[i]
+		switch node.XMLName.Local {
+		case "r":
+			c.appendText(para, runText(node), change)
+		case "ins", "del":
+			c.runs(node.Nodes, docxChange{kind: node.XMLName.Local, id: node.attr("id"), author: node.attr("author"), date: node.attr("date")}, para)
+		case "hyperlink", "smartTag", "fldSimple":
+			c.runs(node.Nodes, change, para)
+		case "sdt":
+			if sdtContent := node.child("sdtContent"); sdtContent != nil {
+				c.runs(sdtContent.Nodes, change, para)
+			}
+		case "commentRangeStart":
+			c.openComments[node.attr("id")] = true
+		case "commentRangeEnd":
+			delete(c.openComments, node.attr("id"))
+		}
+	}
+}
+
+// appendText adds text as a run, merging it with the previous run when it
+// belongs to the same change.
+func (c *docxConverter) appendText(para *docs.Paragraph, text string, change docxChange) {
+	if text == "" {
+		return
+	}
+
+	if change.kind != "del" {
+		for id := range c.openComments {
+			if c.quoted[id] == nil {
+				c.quoted[id] = &strings.Builder{}
+			}
+			c.quoted[id].WriteString(text)
+		}
+	}
+
+	run := &docs.TextRun{Content: text}
+	if change.kind != "" {
+		id := c.suggestionID(change)
+		if change.kind == "ins" {
+			run.SuggestedInsertionIds = []string{id}
+		} else {
+			run.SuggestedDeletionIds = []string{id}
+		}
+		c.lastChange = &change
+	} else {
+		c.lastChange = nil
+	}
+
+	length := int64(len(text))
+	if n := len(para.Elements); n > 0 && sameSuggestion(para.Elements[n-1].TextRun, run) {
+		last := para.Elements[n-1]
+		last.TextRun.Content += text
+		last.EndIndex += length
+	} else {
+		para.Elements = append(para.Elements, &docs.ParagraphElement{
+			StartIndex: c.index,
+			EndIndex:   c.index + length,
+			TextRun:    run,
+		})
+	}
+	c.index += length
+}
+
+// suggestionID returns the ID of the suggestion a change belongs to, reusing
+// the previous change's ID when it directly precedes this one.
+func (c *docxConverter) suggestionID(change docxChange) string {
+	if c.lastChange != nil && c.lastChange.author == change.author && c.lastID != "" {
+		return c.lastID
+	}
+	c.lastID = "docx." + change.id
+	c.authors[c.lastID] = gdocs.SuggestionAuthor{Author: change.author, CreatedTime: change.date}
+	return c.lastID
+}
+
+func sameSuggestion(a, b *docs.TextRun) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	return strings.Join(a.SuggestedInsertionIds, ",") == strings.Join(b.SuggestedInsertionIds, ",") &&
+		strings.Join(a.SuggestedDeletionIds, ",") == strings.Join(b.SuggestedDeletionIds, ",")
+}
+
+func (c *docxConverter) table(tbl *xmlNode) *docs.StructuralElement {
+	elem := &docs.StructuralElement{StartIndex: c.index}
+	table := &docs.Table{}
+	c.index++
+
+	for i := range tbl.Nodes {
+		tr := &tbl.Nodes[i]
+		if tr.XMLName.Local != "tr" {
+			continue
+		}
+		row := &docs.TableRow{StartIndex: c.index}
+		c.index++
+		for j := range tr.Nodes {
+			tc := &tr.Nodes[j]
+			if tc.XMLName.Local != "tc" {
+				continue
+			}
+			cell := &docs.TableCell{StartIndex: c.index}
+			c.index++
+			cell.Content = c.content(tc.Nodes)
+			cell.EndIndex = c.index
+			row.TableCells = append(row.TableCells, cell)
+		}
+		row.EndIndex = c.index
+		table.TableRows = append(table.TableRows, row)
+		table.Columns = max(table.Columns, int64(len(row.TableCells)))
+	}
+	table.Rows = int64(len(table.TableRows))
+
+	elem.Table = table
+	elem.EndIndex = c.index
+	return elem
+}
+
+// runText returns the text of a run, including deleted text.
+func runText(r *xmlNode) string {
+	var b strings.Builder
+	for _, node := range r.Nodes {
+		switch node.XMLName.Local {
+		case "t", "delText":
+			b.WriteString(node.Text)
+		case "tab":
+			b.WriteString("\t")
+		case "br", "cr":
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// nodeText concatenates the text of all runs below nodes, one line per paragraph.
+func nodeText(nodes []xmlNode) string {
+	var b strings.Builder
+	for i := range nodes {
+		switch nodes[i].XMLName.Local {
+		case "r":
+			b.WriteString(runText(&nodes[i]))
+		case "p":
+			b.WriteString(nodeText(nodes[i].Nodes))
+			b.WriteString("\n")
+		default:
+			b.WriteString(nodeText(nodes[i].Nodes))
+		}
+	}
+	return b.String()
+}
+
+// headingStyle maps Word's built-in heading styles (e.g. "Heading1") to Docs
+// named styles.
+func headingStyle(styleID string) string {
+	id := strings.ToLower(strings.ReplaceAll(styleID, " ", ""))
+	if strings.HasPrefix(id, "heading") && len(id) == len("heading")+1 {
+		if level := id[len(id)-1]; level >= '1' && level <= '6' {
+			return "HEADING_" + string(level)
+		}
+	}
+	if id == "title" {
+		return "TITLE"
+	}
+	return "NORMAL_TEXT"
+}
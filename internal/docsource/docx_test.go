@@ -0,0 +1,124 @@
+package docsource
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testDocumentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    <w:p>
+      <w:pPr><w:pStyle w:val="Heading2"/></w:pPr>
+      <w:r><w:t>Overview</w:t></w:r>
+    </w:p>
+    <w:p>
+      <w:r><w:t xml:space="preserve">Ubuntu is </w:t></w:r>
+      <w:del w:id="1" w:author="Ana" w:date="2024-05-02T09:30:00Z"><w:r><w:delText>fast</w:delText></w:r></w:del>
+      <w:ins w:id="2" w:author="Ana"><w:r><w:t>quick</w:t></w:r></w:ins>
+      <w:commentRangeStart w:id="0"/>
+      <w:r><w:t xml:space="preserve"> and secure.</w:t></w:r>
+      <w:commentRangeEnd w:id="0"/>
+    </w:p>
+    <w:tbl>
+      <w:tr>
+        <w:tc><w:p><w:r><w:t>Cell</w:t></w:r><w:ins w:id="3" w:author="Ben"><w:r><w:t> text</w:t></w:r></w:ins></w:p></w:tc>
+      </w:tr>
+    </w:tbl>
+  </w:body>
+</w:document>`
+
+const testCommentsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:comment w:id="0" w:author="Ben" w:date="2024-05-01T10:00:00Z">
+    <w:p><w:r><w:t>Is this still true?</w:t></w:r></w:p>
+  </w:comment>
+</w:comments>`
+
+func writeTestDocx(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "copy review.docx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create docx: %v", err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		"word/document.xml": testDocumentXML,
+		"word/comments.xml": testCommentsXML,
+	} {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close docx: %v", err)
+	}
+	return path
+}
+
+func TestDocxProvider(t *testing.T) {
+	provider := &DocxProvider{Path: writeTestDocx(t)}
+	result, err := provider.ProcessDocument(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ProcessDocument() failed: %v", err)
+	}
+
+	if result.DocumentID != "copy review.docx" || result.DocumentTitle != "copy review" {
+		t.Errorf("Unexpected document ID/title: %q, %q", result.DocumentID, result.DocumentTitle)
+	}
+
+	byID := make(map[string]string)
+	for _, group := range result.GroupedSuggestions {
+		for _, sugg := range group.Suggestions {
+			byID[sugg.ID] = sugg.Change.Type + ":" + sugg.Change.OriginalText + "->" + sugg.Change.NewText
+			if sugg.ID == "docx.1" && group.Location.ParentHeading != "Overview" {
+				t.Errorf("Expected docx.1 under Overview, got %+v", group.Location)
+			}
+			if sugg.ID == "docx.3" && !group.Location.InTable {
+				t.Errorf("Expected docx.3 in a table, got %+v", group.Location)
+			}
+		}
+	}
+
+	// Adjacent deletion and insertion by the same author form one replacement
+	want := map[string]string{
+		"docx.1": "replace:fast->quick",
+		"docx.3": "insert:-> text",
+	}
+	for id, change := range want {
+		if byID[id] != change {
+			t.Errorf("%s: expected %q, got %q", id, change, byID[id])
+		}
+	}
+	if len(byID) != len(want) {
+		t.Errorf("Expected %d suggestions, got %v", len(want), byID)
+	}
+
+	for _, sugg := range result.ActionableSuggestions {
+		if sugg.ID == "docx.1" && (sugg.Author != "Ana" || sugg.CreatedTime != "2024-05-02T09:30:00Z") {
+			t.Errorf("Expected docx.1 by Ana, got %+v", sugg.SuggestionAuthor)
+		}
+		if sugg.ID == "docx.3" && sugg.Author != "Ben" {
+			t.Errorf("Expected docx.3 by Ben, got %+v", sugg.SuggestionAuthor)
+		}
+	}
+
+	if len(result.Comments) != 1 {
+		t.Fatalf("Expected 1 comment, got %d", len(result.Comments))
+	}
+	comment := result.Comments[0]
+	if comment.Content != "Is this still true?" || comment.QuotedContent != " and secure." || comment.Location == nil {
+		t.Errorf("Unexpected comment: %+v", comment)
+	}
+}
@@ -0,0 +1,40 @@
+package docsource
+
+import (
+	"bauer/internal/gdocs"
+	"context"
+)
+
+// ReplayProvider rebuilds a run from a saved raw document (--dump-raw output
+// or an archived snapshot) without calling the API, to reproduce extraction,
+// anchoring and grouping bugs offline.
+type ReplayProvider struct {
+	Path string
+}
+
+// ProcessDocument extracts suggestions from the saved document. docID is only
+// used when the saved document has no ID.
+func (p *ReplayProvider) ProcessDocument(ctx context.Context, docID string) (*gdocs.ProcessingResult, error) {
+	doc, err := gdocs.ReadRawDocument(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	if doc.DocumentId == "" {
+		doc.DocumentId = docID
+	}
+	return gdocs.BuildProcessingResult(doc), nil
+}
+
+// FetchSuggestions returns the suggestions in the saved document.
+func (p *ReplayProvider) FetchSuggestions(ctx context.Context, docID string) ([]gdocs.Suggestion, error) {
+	doc, err := gdocs.ReadRawDocument(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	return gdocs.ExtractSuggestions(doc), nil
+}
+
+// FetchComments returns nothing; comments aren't part of the raw document.
+func (p *ReplayProvider) FetchComments(ctx context.Context, docID string) ([]gdocs.Comment, error) {
+	return nil, nil
+}
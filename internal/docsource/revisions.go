@@ -0,0 +1,135 @@
+package docsource
+
+import (
+	"bauer/internal/gdocs"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/api/docs/v1"
+)
+
+// SourceRevisions builds suggestions from the differences between two
+// revisions of a Google Doc.
+const SourceRevisions = "revisions"
+
+// RevisionProvider exports two revisions of a Google Doc from its Drive
+// history and turns their differences into suggestions, for documents
+// reviewers edited directly instead of in Suggesting mode. Revisions are
+// selected as gdocs.SelectRevision describes.
+type RevisionProvider struct {
+	Client *gdocs.Client
+
+	// Before is the old revision; After is the new one, the latest when empty
+	Before string
+	After  string
+}
+
+// ProcessDocument diffs the two revisions and groups the changes by location.
+// Comments on the document are included as context, and the changes are
+// attributed to the editor of the revisions in between when there's only one.
+func (p *RevisionProvider) ProcessDocument(ctx context.Context, docID string) (*gdocs.ProcessingResult, error) {
+	var (
+		doc      *docs.Document
+		author   *gdocs.SuggestionAuthor
+		comments []gdocs.Comment
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() (err error) {
+		doc, author, err = p.read(gctx, docID)
+		return err
+	})
+	g.Go(func() (err error) {
+		comments, err = p.Client.FetchComments(gctx, docID)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	result := gdocs.BuildProcessingResult(doc)
+	gdocs.AddComments(result, comments)
+	if author != nil {
+		gdocs.AddSuggestionAuthors(result, suggestionAuthors(gdocs.ExtractSuggestions(doc), *author))
+	}
+	return result, nil
+}
+
+// FetchSuggestions returns the differences as suggestions.
+func (p *RevisionProvider) FetchSuggestions(ctx context.Context, docID string) ([]gdocs.Suggestion, error) {
+	doc, author, err := p.read(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	suggestions := gdocs.ExtractSuggestions(doc)
+	if author != nil {
+		for i := range suggestions {
+			suggestions[i].SuggestionAuthor = *author
+		}
+	}
+	return suggestions, nil
+}
+
+// FetchComments returns the comments on the document.
+func (p *RevisionProvider) FetchComments(ctx context.Context, docID string) ([]gdocs.Comment, error) {
+	return p.Client.FetchComments(ctx, docID)
+}
+
+func (p *RevisionProvider) read(ctx context.Context, docID string) (*docs.Document, *gdocs.SuggestionAuthor, error) {
+	revisions, err := p.Client.FetchRevisions(ctx, docID)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := gdocs.SelectRevision(revisions, p.After)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid after revision: %w", err)
+	}
+	// The old revision is selected as of the new one, so "head~1" is the
+	// revision just before it
+	before, err := gdocs.SelectRevision(revisions[:after+1], p.Before)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid before revision: %w", err)
+	}
+	if before == after {
+		return nil, nil, fmt.Errorf("before revision %q is the after revision %s", p.Before, revisions[after].ID)
+	}
+	slog.Info("Comparing revisions",
+		slog.String("doc_id", docID),
+		slog.String("before", revisions[before].ID),
+		slog.String("after", revisions[after].ID),
+	)
+
+	beforeHTML, err := p.Client.ExportRevision(ctx, revisions[before])
+	if err != nil {
+		return nil, nil, err
+	}
+	afterHTML, err := p.Client.ExportRevision(ctx, revisions[after])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := p.Client.Drive.Files.Get(docID).
+		Fields("name").
+		SupportsAllDrives(true).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch document name: %w", err)
+	}
+
+	doc := DiffDocument(parseHTMLBlocks(beforeHTML), parseHTMLBlocks(afterHTML))
+	doc.DocumentId = docID
+	doc.Title = file.Name
+	doc.RevisionId = revisions[after].ID
+	return doc, gdocs.RevisionsAuthor(revisions[before+1 : after+1]), nil
+}
+
+// suggestionAuthors attributes every suggestion to author.
+func suggestionAuthors(suggestions []gdocs.Suggestion, author gdocs.SuggestionAuthor) map[string]gdocs.SuggestionAuthor {
+	authors := make(map[string]gdocs.SuggestionAuthor, len(suggestions))
+	for _, sugg := range suggestions {
+		authors[sugg.ID] = author
+	}
+	return authors
+}
@@ -0,0 +1,83 @@
+// Package executor abstracts the LLM backend that applies chunk prompts to the
+// target repository, so backends other than the Copilot CLI can run them
+// without changes to the orchestrator.
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"bauer/internal/copilotcli"
+)
+
+// Executor names accepted by Open.
+const (
+	ExecutorCopilot = "copilot"
+	ExecutorOpenAI  = "openai"
+)
+
+// Executor runs chunk prompts against the repository in its working directory.
+type Executor interface {
+	// Start prepares the backend; Stop releases it.
+	Start() error
+	Stop() error
+
+	// ExecuteChunk applies the chunk prompt at chunkPath and returns the
+	// model's output and token usage; the usage is returned for failed
+	// sessions too. Session failures and timeouts wrap copilotcli.ErrSession
+	// and copilotcli.ErrTimeout, so they can be retried.
+	ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber int, model string) (string, copilotcli.Usage, error)
+
+	// GenerateSummary summarises the outputs of all chunks and returns the
+	// token usage of doing so.
+	GenerateSummary(ctx context.Context, outputs []copilotcli.ChunkOutput, model string) (copilotcli.Usage, error)
+}
+
+// Options configures the executor returned by Open.
+type Options struct {
+	// Name selects the executor; empty means Copilot
+	Name string
+
+	// Cwd is the repository the chunks are applied to
+	Cwd string
+
+	// SummaryInstructions replace the built-in summary instructions when set
+	SummaryInstructions string
+
+	// Sandbox restricts Copilot sessions. The OpenAI executor is always
+	// confined to Cwd, without shell or network access.
+	Sandbox copilotcli.Sandbox
+}
+
+// Open returns the executor for opts.Name.
+func Open(opts Options) (Executor, error) {
+	switch opts.Name {
+	case "", ExecutorCopilot:
+		client, err := copilotcli.NewClient(opts.Cwd)
+		if err != nil {
+			return nil, err
+		}
+		client.SummaryInstructions = opts.SummaryInstructions
+		client.Sandbox = opts.Sandbox
+		return client, nil
+	case ExecutorOpenAI:
+		return NewOpenAIExecutor(opts.Cwd, opts.SummaryInstructions), nil
+	default:
+		return nil, fmt.Errorf("unknown executor: %s", opts.Name)
+	}
+}
+
+// Validate checks an executor name.
+func Validate(name string) error {
+	switch name {
+	case "", ExecutorCopilot, ExecutorOpenAI:
+		return nil
+	default:
+		return fmt.Errorf("unknown executor %q (expected %s or %s)", name, ExecutorCopilot, ExecutorOpenAI)
+	}
+}
+
+var (
+	_ Executor = (*copilotcli.Client)(nil)
+	_ Executor = (*OpenAIExecutor)(nil)
+)
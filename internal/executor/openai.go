@@ -0,0 +1,476 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"bauer/internal/copilotcli"
+)
+
+const (
+	// defaultOpenAIBaseURL is used when OPENAI_BASE_URL is not set
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+	// chunkTimeout matches the timeout of Copilot sessions
+	chunkTimeout = 15 * time.Minute
+
+	// maxTurns bounds the tool loop of a chunk
+	maxTurns = 60
+
+	// maxReadSize is the largest file returned by read_file
+	maxReadSize = 256 << 10
+
+	// maxSearchResults bounds the matches returned by search
+	maxSearchResults = 50
+)
+
+const openAISystemPrompt = `You apply copy changes to a website repository.
+Use the tools to find, read and edit files. Paths are relative to the repository root.
+You have no shell or network access. Edit files with replace_in_file where possible, and
+only change what the instructions ask for. When you are done, reply with a short summary
+of the changes, ending with the JSON report the instructions ask for.`
+
+// OpenAIExecutor applies chunks with an OpenAI-compatible chat completions
+// API, e.g. OpenAI or a local server such as Ollama or vLLM. The model edits
+// the repository through a small set of file tools confined to Root; it has
+// no shell or network access.
+type OpenAIExecutor struct {
+	// BaseURL and APIKey default to OPENAI_BASE_URL and OPENAI_API_KEY
+	BaseURL string
+	APIKey  string
+
+	// Root is the repository the file tools are confined to
+	Root string
+
+	// SummaryInstructions replace the built-in summary instructions when set
+	SummaryInstructions string
+
+	HTTPClient *http.Client
+}
+
+// NewOpenAIExecutor returns an executor for the repository at root, configured
+// from the environment.
+func NewOpenAIExecutor(root, summaryInstructions string) *OpenAIExecutor {
+	return &OpenAIExecutor{
+		BaseURL:             cmp.Or(os.Getenv("OPENAI_BASE_URL"), defaultOpenAIBaseURL),
+		APIKey:              os.Getenv("OPENAI_API_KEY"),
+		Root:                root,
+		SummaryInstructions: summaryInstructions,
+		HTTPClient:          &http.Client{},
+	}
+}
+
+// Start checks the executor is configured. Local servers need no API key.
+func (e *OpenAIExecutor) Start() error {
+	if e.Root == "" {
+		root, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %w", err)
+		}
+		e.Root = root
+	}
+	root, err := filepath.Abs(e.Root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve repository path: %w", err)
+	}
+	e.Root = root
+	if e.APIKey == "" && e.BaseURL == defaultOpenAIBaseURL {
+		return errors.New("OPENAI_API_KEY is not set")
+	}
+	slog.Info("OpenAI executor ready", slog.String("base_url", e.BaseURL), slog.String("root", e.Root))
+	return nil
+}
+
+// Stop does nothing; every request is independent.
+func (e *OpenAIExecutor) Stop() error {
+	return nil
+}
+
+// ExecuteChunk sends the chunk prompt and runs the tool calls the model makes
+// until it replies without any.
+func (e *OpenAIExecutor) ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber int, model string) (string, copilotcli.Usage, error) {
+	content, err := os.ReadFile(chunkPath)
+	if err != nil {
+		return "", copilotcli.Usage{}, fmt.Errorf("failed to read chunk %d: %w", chunkNumber, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, chunkTimeout)
+	defer cancel()
+
+	slog.Info("Sending prompt to OpenAI",
+		slog.Int("chunk", chunkNumber),
+		slog.String("model", model),
+		slog.String("file", chunkPath),
+	)
+
+	messages := []chatMessage{
+		{Role: "system", Content: openAISystemPrompt},
+		{Role: "user", Content: fmt.Sprintf("Implement the changes described in %s. Follow all instructions carefully and apply changes in order.\n\n%s", filepath.Base(chunkPath), content)},
+	}
+
+	var output strings.Builder
+	var usage copilotcli.Usage
+	for turn := 0; turn < maxTurns; turn++ {
+		reply, turnUsage, err := e.complete(ctx, model, messages, fileTools)
+		usage.Add(turnUsage)
+		if err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", usage, fmt.Errorf("chunk %d %w after %s", chunkNumber, copilotcli.ErrTimeout, chunkTimeout)
+			}
+			return "", usage, fmt.Errorf("chunk %d: %w", chunkNumber, err)
+		}
+		messages = append(messages, reply)
+		if reply.Content != "" {
+			fmt.Println(reply.Content)
+			output.WriteString(reply.Content)
+			output.WriteString("\n")
+		}
+		if len(reply.ToolCalls) == 0 {
+			slog.Info("Session completed", slog.Int("chunk", chunkNumber), slog.Int("turns", turn+1))
+			return output.String(), usage, nil
+		}
+		for _, call := range reply.ToolCalls {
+			slog.Debug("Tool called",
+				slog.Int("chunk", chunkNumber),
+				slog.String("tool", call.Function.Name),
+			)
+			messages = append(messages, chatMessage{
+				Role:       "tool",
+				ToolCallID: call.ID,
+				Content:    e.runTool(call.Function.Name, call.Function.Arguments),
+			})
+		}
+	}
+	return "", usage, fmt.Errorf("%w for chunk %d: no reply after %d turns", copilotcli.ErrSession, chunkNumber, maxTurns)
+}
+
+// GenerateSummary asks the model to summarise the chunk outputs.
+func (e *OpenAIExecutor) GenerateSummary(ctx context.Context, outputs []copilotcli.ChunkOutput, model string) (copilotcli.Usage, error) {
+	slog.Info("Creating summary session", slog.String("model", model))
+	reply, usage, err := e.complete(ctx, model, []chatMessage{
+		{Role: "user", Content: copilotcli.BuildSummaryPrompt(outputs, e.SummaryInstructions)},
+	}, nil)
+	if err != nil {
+		return usage, fmt.Errorf("failed to generate summary: %w", err)
+	}
+	fmt.Println(reply.Content)
+	return usage, nil
+}
+
+// Ask sends a single prompt, without tools, and returns the reply.
+func (e *OpenAIExecutor) Ask(ctx context.Context, model, prompt string) (string, copilotcli.Usage, error) {
+	reply, usage, err := e.complete(ctx, model, []chatMessage{{Role: "user", Content: prompt}}, nil)
+	if err != nil {
+		return "", usage, err
+	}
+	return reply.Content, usage, nil
+}
+
+type chatMessage struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []toolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+type toolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type tool struct {
+	Type     string       `json:"type"`
+	Function toolFunction `json:"function"`
+}
+
+type toolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Tools    []tool        `json:"tools,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// complete sends one chat completions request and returns the reply with the
+// token usage of the request, estimated when the server reports none. Server
+// errors and rate limits wrap copilotcli.ErrSession so they are retried;
+// other errors are not.
+func (e *OpenAIExecutor) complete(ctx context.Context, model string, messages []chatMessage, tools []tool) (chatMessage, copilotcli.Usage, error) {
+	reply, usage, err := e.send(ctx, model, messages, tools)
+	if err == nil && usage.IsZero() {
+		request, _ := json.Marshal(messages)
+		response, _ := json.Marshal(reply)
+		usage = copilotcli.EstimateUsage(string(request), string(response))
+	}
+	return reply, usage, err
+}
+
+func (e *OpenAIExecutor) send(ctx context.Context, model string, messages []chatMessage, tools []tool) (chatMessage, copilotcli.Usage, error) {
+	body, err := json.Marshal(chatRequest{Model: model, Messages: messages, Tools: tools})
+	if err != nil {
+		return chatMessage{}, copilotcli.Usage{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(e.BaseURL, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return chatMessage{}, copilotcli.Usage{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	}
+
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return chatMessage{}, copilotcli.Usage{}, fmt.Errorf("%w: %v", copilotcli.ErrSession, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return chatMessage{}, copilotcli.Usage{}, fmt.Errorf("%w: failed to read response: %v", copilotcli.ErrSession, err)
+	}
+
+	var parsed chatResponse
+	if err := json.Unmarshal(data, &parsed); err != nil && resp.StatusCode == http.StatusOK {
+		return chatMessage{}, copilotcli.Usage{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		message := strings.TrimSpace(string(data))
+		if parsed.Error != nil {
+			message = parsed.Error.Message
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return chatMessage{}, copilotcli.Usage{}, fmt.Errorf("%w: %s: %s", copilotcli.ErrSession, resp.Status, message)
+		}
+		return chatMessage{}, copilotcli.Usage{}, fmt.Errorf("chat completion failed: %s: %s", resp.Status, message)
+	}
+	if len(parsed.Choices) == 0 {
+		return chatMessage{}, copilotcli.Usage{}, fmt.Errorf("%w: empty response", copilotcli.ErrSession)
+	}
+	var usage copilotcli.Usage
+	if parsed.Usage != nil {
+		usage = copilotcli.Usage{InputTokens: parsed.Usage.PromptTokens, OutputTokens: parsed.Usage.CompletionTokens}
+	}
+	return parsed.Choices[0].Message, usage, nil
+}
+
+// fileTools are the only tools the model is given.
+var fileTools = []tool{
+	newTool("list_files", "List the files and directories in a directory of the repository.",
+		map[string]string{"path": "Directory relative to the repository root, e.g. \".\" or \"templates\""}),
+	newTool("search", "Find the lines of repository files containing the given text, to locate what to change.",
+		map[string]string{"text": "Exact text to search for"}),
+	newTool("read_file", "Read a file of the repository.",
+		map[string]string{"path": "File path relative to the repository root"}),
+	newTool("replace_in_file", "Replace text that occurs exactly once in a file.",
+		map[string]string{"path": "File path relative to the repository root", "old_text": "Exact text to replace", "new_text": "Replacement text"}),
+	newTool("write_file", "Create or overwrite a file of the repository.",
+		map[string]string{"path": "File path relative to the repository root", "content": "Full content of the file"}),
+}
+
+func newTool(name, description string, params map[string]string) tool {
+	properties := make(map[string]any)
+	for param, desc := range params {
+		properties[param] = map[string]string{"type": "string", "description": desc}
+	}
+	required := slices.Sorted(maps.Keys(params))
+	return tool{Type: "function", Function: toolFunction{
+		Name:        name,
+		Description: description,
+		Parameters:  map[string]any{"type": "object", "properties": properties, "required": required},
+	}}
+}
+
+type toolArgs struct {
+	Path    string `json:"path"`
+	Text    string `json:"text"`
+	OldText string `json:"old_text"`
+	NewText string `json:"new_text"`
+	Content string `json:"content"`
+}
+
+// runTool runs a tool call and returns its result for the model. Failures are
+// reported to the model rather than failing the chunk, so it can recover.
+func (e *OpenAIExecutor) runTool(name, arguments string) string {
+	var args toolArgs
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return fmt.Sprintf("error: invalid arguments: %v", err)
+	}
+	result, err := e.tool(name, args)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return result
+}
+
+func (e *OpenAIExecutor) tool(name string, args toolArgs) (string, error) {
+	if name == "search" {
+		return e.search(args.Text)
+	}
+	path, err := e.resolve(args.Path)
+	if err != nil {
+		return "", err
+	}
+	switch name {
+	case "list_files":
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return "", err
+		}
+		var names []string
+		for _, entry := range entries {
+			if entry.IsDir() {
+				names = append(names, entry.Name()+"/")
+			} else {
+				names = append(names, entry.Name())
+			}
+		}
+		return strings.Join(names, "\n"), nil
+	case "read_file":
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		if info.Size() > maxReadSize {
+			return "", fmt.Errorf("%s is too large to read (%d bytes)", args.Path, info.Size())
+		}
+		data, err := os.ReadFile(path)
+		return string(data), err
+	case "replace_in_file":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		if args.OldText == "" {
+			return "", errors.New("old_text is empty")
+		}
+		switch count := strings.Count(string(data), args.OldText); count {
+		case 0:
+			return "", fmt.Errorf("old_text not found in %s", args.Path)
+		case 1:
+		default:
+			return "", fmt.Errorf("old_text occurs %d times in %s; include more surrounding text", count, args.Path)
+		}
+		updated := strings.Replace(string(data), args.OldText, args.NewText, 1)
+		if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+			return "", err
+		}
+		return "replaced", nil
+	case "write_file":
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, []byte(args.Content), 0644); err != nil {
+			return "", err
+		}
+		return "written", nil
+	default:
+		return "", fmt.Errorf("unknown tool %s", name)
+	}
+}
+
+// resolve returns the absolute path of a repository path, refusing paths
+// outside the repository and inside .git.
+func (e *OpenAIExecutor) resolve(path string) (string, error) {
+	if path == "" {
+		path = "."
+	}
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(e.Root, path)
+	}
+	rel, err := filepath.Rel(e.Root, filepath.Clean(abs))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the repository", path)
+	}
+	if rel == ".git" || strings.HasPrefix(rel, ".git"+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not accessible", path)
+	}
+	// Symlinks must not lead out of the repository either
+	if real, err := filepath.EvalSymlinks(abs); err == nil {
+		root, _ := filepath.EvalSymlinks(e.Root)
+		if rel, err := filepath.Rel(root, real); err != nil || strings.HasPrefix(rel, "..") {
+			return "", fmt.Errorf("%s is outside the repository", path)
+		}
+	}
+	return abs, nil
+}
+
+// search returns the lines of text files containing text, as path:line: content.
+func (e *OpenAIExecutor) search(text string) (string, error) {
+	if text == "" {
+		return "", errors.New("text is empty")
+	}
+	var matches []string
+	err := filepath.WalkDir(e.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || len(matches) >= maxSearchResults {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != e.Root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.Size() > maxReadSize {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer file.Close()
+		rel, _ := filepath.Rel(e.Root, path)
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64<<10), maxReadSize)
+		for line := 1; scanner.Scan() && len(matches) < maxSearchResults; line++ {
+			if strings.Contains(scanner.Text(), text) {
+				matches = append(matches, fmt.Sprintf("%s:%d: %s", rel, line, strings.TrimSpace(scanner.Text())))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "no matches", nil
+	}
+	return strings.Join(matches, "\n"), nil
+}
@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIExecutor_ExecuteChunk(t *testing.T) {
+	root := t.TempDir()
+	page := filepath.Join(root, "templates", "aws.html")
+	if err := os.MkdirAll(filepath.Dir(page), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(page, []byte("<h1>Ubuntu on AWS</h1>\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	chunk := filepath.Join(t.TempDir(), "chunk-1.md")
+	if err := os.WriteFile(chunk, []byte("Replace \"Ubuntu on AWS\" with \"Ubuntu on Amazon Web Services\""), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The model searches for the text, replaces it, then replies
+	replies := []string{
+		`{"role":"assistant","content":"","tool_calls":[{"id":"1","type":"function","function":{"name":"search","arguments":"{\"text\":\"Ubuntu on AWS\"}"}}]}`,
+		`{"role":"assistant","content":"","tool_calls":[{"id":"2","type":"function","function":{"name":"replace_in_file","arguments":"{\"path\":\"templates/aws.html\",\"old_text\":\"Ubuntu on AWS\",\"new_text\":\"Ubuntu on Amazon Web Services\"}"}},{"id":"3","type":"function","function":{"name":"read_file","arguments":"{\"path\":\"../outside.txt\"}"}}]}`,
+		`{"role":"assistant","content":"STATUS suggest.a applied"}`,
+	}
+	var requests []chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		requests = append(requests, req)
+		// Only the first response reports its usage
+		usage := ""
+		if len(requests) == 1 {
+			usage = `,"usage":{"prompt_tokens":100,"completion_tokens":10}`
+		}
+		w.Write([]byte(`{"choices":[{"message":` + replies[len(requests)-1] + `}]` + usage + `}`))
+	}))
+	defer server.Close()
+
+	e := &OpenAIExecutor{BaseURL: server.URL, Root: root, HTTPClient: server.Client()}
+	if err := e.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	output, usage, err := e.ExecuteChunk(context.Background(), chunk, 1, "local-model")
+	if err != nil {
+		t.Fatalf("ExecuteChunk() error = %v", err)
+	}
+	if usage.InputTokens <= 100 || usage.OutputTokens <= 10 || !usage.Estimated {
+		t.Errorf("ExecuteChunk() usage = %+v, want reported and estimated tokens", usage)
+	}
+	if !strings.Contains(output, "STATUS suggest.a applied") {
+		t.Errorf("ExecuteChunk() output = %q", output)
+	}
+
+	data, err := os.ReadFile(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "<h1>Ubuntu on Amazon Web Services</h1>\n" {
+		t.Errorf("Page not edited: %q", data)
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("Got %d requests, want 3", len(requests))
+	}
+	toolResults := requests[2].Messages[len(requests[2].Messages)-2:]
+	if toolResults[0].Content != "replaced" || !strings.Contains(toolResults[1].Content, "outside the repository") {
+		t.Errorf("Unexpected tool results: %+v", toolResults)
+	}
+	if search := requests[1].Messages[len(requests[1].Messages)-1].Content; search != "templates/aws.html:1: <h1>Ubuntu on AWS</h1>" {
+		t.Errorf("Unexpected search result: %q", search)
+	}
+}
+
+func TestOpenAIExecutor_Resolve(t *testing.T) {
+	e := &OpenAIExecutor{Root: t.TempDir()}
+	for _, path := range []string{"../etc/passwd", "/etc/passwd", ".git/config", "templates/../../x"} {
+		if _, err := e.resolve(path); err == nil {
+			t.Errorf("resolve(%q) succeeded, want an error", path)
+		}
+	}
+	for _, path := range []string{"", ".", "templates/index.html", filepath.Join(e.Root, "a.md")} {
+		if _, err := e.resolve(path); err != nil {
+			t.Errorf("resolve(%q) error = %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"bauer/internal/copilotcli"
+)
+
+// ErrInvalidReport is returned for output that fails validation, e.g. without
+// the report the prompt asks for. It is retried like a session error.
+var ErrInvalidReport = errors.New("invalid chunk report")
+
+// RetryPolicy controls how failed chunk sessions are retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+
+	// FallbackModel, when set, is used for the final retry instead of the
+	// chunk's model
+	FallbackModel string
+}
+
+// model returns the model of the given attempt, starting at 1.
+func (p RetryPolicy) model(attempt int, model string) string {
+	if p.FallbackModel != "" && p.MaxRetries > 0 && attempt == p.MaxRetries+1 {
+		return p.FallbackModel
+	}
+	return model
+}
+
+// ExecuteWithRetry executes a chunk, retrying session errors, timeouts and
+// output that validate (when not nil) rejects, as the policy allows. The
+// outcome of every attempt is returned, including when all of them failed.
+func ExecuteWithRetry(ctx context.Context, e Executor, chunkPath string, chunkNumber int, model string, policy RetryPolicy, validate func(output string) error) (string, []copilotcli.Attempt, error) {
+	var attempts []copilotcli.Attempt
+	for n := 1; ; n++ {
+		attemptModel := policy.model(n, model)
+		start := time.Now()
+		output, usage, err := e.ExecuteChunk(ctx, chunkPath, chunkNumber, attemptModel)
+		if err == nil && validate != nil {
+			if verr := validate(output); verr != nil {
+				err = fmt.Errorf("%w for chunk %d: %v", ErrInvalidReport, chunkNumber, verr)
+			}
+		}
+
+		attempt := copilotcli.Attempt{Number: n, Model: attemptModel, Duration: time.Since(start), Usage: usage}
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+		attempts = append(attempts, attempt)
+
+		if err == nil {
+			return output, attempts, nil
+		}
+		retryable := copilotcli.Retryable(err) || errors.Is(err, ErrInvalidReport)
+		if n > policy.MaxRetries || !retryable || ctx.Err() != nil {
+			return "", attempts, fmt.Errorf("failed after %d attempt(s): %w", n, err)
+		}
+		slog.Warn("Retrying chunk",
+			slog.Int("chunk", chunkNumber),
+			slog.Int("attempt", n),
+			slog.String("error", err.Error()),
+			slog.String("next_model", policy.model(n+1, model)),
+		)
+	}
+}
@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"bauer/internal/copilotcli"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeExecutor fails with the given errors before succeeding.
+type fakeExecutor struct {
+	errs   []error
+	models []string
+}
+
+func (f *fakeExecutor) Start() error { return nil }
+func (f *fakeExecutor) Stop() error  { return nil }
+func (f *fakeExecutor) GenerateSummary(ctx context.Context, outputs []copilotcli.ChunkOutput, model string) (copilotcli.Usage, error) {
+	return copilotcli.Usage{}, nil
+}
+
+func (f *fakeExecutor) ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber int, model string) (string, copilotcli.Usage, error) {
+	f.models = append(f.models, model)
+	usage := copilotcli.Usage{InputTokens: 100, OutputTokens: 10}
+	if len(f.models) <= len(f.errs) {
+		return "", usage, f.errs[len(f.models)-1]
+	}
+	return "done", usage, nil
+}
+
+func TestExecuteWithRetry(t *testing.T) {
+	sessionErr := fmt.Errorf("%w for chunk 1: overloaded", copilotcli.ErrSession)
+	tests := []struct {
+		name       string
+		errs       []error
+		policy     RetryPolicy
+		wantModels []string
+		wantErr    bool
+	}{
+		{"success", nil, RetryPolicy{MaxRetries: 2}, []string{"mini"}, false},
+		{"no retries", []error{sessionErr}, RetryPolicy{}, []string{"mini"}, true},
+		{"retried", []error{sessionErr}, RetryPolicy{MaxRetries: 2}, []string{"mini", "mini"}, false},
+		{"fallback on final retry", []error{sessionErr, sessionErr}, RetryPolicy{MaxRetries: 2, FallbackModel: "large"}, []string{"mini", "mini", "large"}, false},
+		{"all attempts fail", []error{sessionErr, sessionErr}, RetryPolicy{MaxRetries: 1, FallbackModel: "large"}, []string{"mini", "large"}, true},
+		{"not retryable", []error{errors.New("failed to resolve chunk path")}, RetryPolicy{MaxRetries: 2}, []string{"mini"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeExecutor{errs: tt.errs}
+			output, attempts, err := ExecuteWithRetry(context.Background(), fake, "chunk-1.md", 1, "mini", tt.policy, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExecuteWithRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && output != "done" {
+				t.Errorf("ExecuteWithRetry() output = %q", output)
+			}
+			if diff := cmp.Diff(tt.wantModels, fake.models); diff != "" {
+				t.Errorf("Models mismatch (-want +got):\n%s", diff)
+			}
+			if len(attempts) != len(tt.wantModels) {
+				t.Errorf("Got %d attempts, want %d", len(attempts), len(tt.wantModels))
+			}
+			if last := attempts[len(attempts)-1]; (last.Error != "") != tt.wantErr {
+				t.Errorf("Unexpected last attempt: %+v", last)
+			}
+		})
+	}
+}
+
+func TestExecuteWithRetry_Validate(t *testing.T) {
+	fake := &fakeExecutor{}
+	validations := 0
+	validate := func(output string) error {
+		validations++
+		if validations == 1 {
+			return errors.New("missing report")
+		}
+		return nil
+	}
+	_, attempts, err := ExecuteWithRetry(context.Background(), fake, "chunk-1.md", 1, "mini", RetryPolicy{MaxRetries: 1}, validate)
+	if err != nil {
+		t.Fatalf("ExecuteWithRetry() error = %v", err)
+	}
+	if len(attempts) != 2 || attempts[0].Error == "" {
+		t.Errorf("Unexpected attempts: %+v", attempts)
+	}
+	// Failed attempts count towards the usage of the chunk
+	output := copilotcli.ChunkOutput{Attempts: attempts}
+	if got := output.TotalUsage(); got.Total() != 220 {
+		t.Errorf("TotalUsage() = %+v, want 220 tokens", got)
+	}
+
+	_, _, err = ExecuteWithRetry(context.Background(), &fakeExecutor{}, "chunk-1.md", 1, "mini", RetryPolicy{}, func(string) error {
+		return errors.New("missing report")
+	})
+	if !errors.Is(err, ErrInvalidReport) {
+		t.Errorf("ExecuteWithRetry() error = %v, want ErrInvalidReport", err)
+	}
+}
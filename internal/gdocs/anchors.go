@@ -0,0 +1,289 @@
+package gdocs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Anchor boundaries: where anchors may end.
+const (
+	// AnchorBoundaryNone cuts anchors at exactly the anchor length (default)
+	AnchorBoundaryNone = ""
+	// AnchorBoundaryWord extends anchors to the nearest whole word
+	AnchorBoundaryWord = "word"
+	// AnchorBoundarySentence extends anchors to the nearest sentence or line end
+	AnchorBoundarySentence = "sentence"
+)
+
+// Default anchor lengths of atomic and grouped suggestions, in bytes.
+const (
+	DefaultAnchorLength        = 80
+	DefaultGroupedAnchorLength = 120
+)
+
+// maxBoundaryExtension is how far an anchor may grow to reach a boundary.
+const maxBoundaryExtension = 200
+
+// AnchorOptions controls the anchors of suggestions.
+type AnchorOptions struct {
+	// Length is the anchor length of atomic suggestions; grouped suggestions
+	// get 1.5 times as much context (default: 80 and 120)
+	Length int
+
+	// Boundary extends anchors so they don't cut words or sentences in half
+	Boundary string
+}
+
+// ValidateAnchorBoundary checks an anchor boundary mode.
+func ValidateAnchorBoundary(boundary string) error {
+	switch boundary {
+	case AnchorBoundaryNone, AnchorBoundaryWord, AnchorBoundarySentence:
+		return nil
+	}
+	return fmt.Errorf("unknown anchor boundary %q (expected word or sentence)", boundary)
+}
+
+func (o AnchorOptions) lengths() (atomic, grouped int) {
+	if o.Length <= 0 {
+		return DefaultAnchorLength, DefaultGroupedAnchorLength
+	}
+	return o.Length, o.Length * 3 / 2
+}
+
+// ApplyAnchorOptions rebuilds the anchors and verification texts of the
+// suggestions in result with the given length and boundary.
+func ApplyAnchorOptions(result *ProcessingResult, opts AnchorOptions) {
+	if result.Structure == nil {
+		return
+	}
+	atomic, grouped := opts.lengths()
+
+	for i := range result.ActionableSuggestions {
+		sugg := &result.ActionableSuggestions[i]
+		segment := result.Structure.segmentStructure(sugg.Location.SegmentID)
+		before, after := anchorText(segment, sugg.Position.StartIndex, sugg.Position.EndIndex, atomic, opts.Boundary)
+		reanchor(&sugg.Anchor, &sugg.Verification, before, after)
+	}
+	for i := range result.GroupedSuggestions {
+		group := &result.GroupedSuggestions[i]
+		segment := result.Structure.segmentStructure(group.Location.SegmentID)
+		for j := range group.Suggestions {
+			sugg := &group.Suggestions[j]
+			// Single suggestions keep their atomic anchors when grouped
+			length := grouped
+			if sugg.AtomicCount <= 1 {
+				length = atomic
+			}
+			before, after := anchorText(segment, sugg.Position.StartIndex, sugg.Position.EndIndex, length, opts.Boundary)
+			reanchor(&sugg.Anchor, &sugg.Verification, before, after)
+		}
+	}
+}
+
+// reanchor replaces an anchor, and the anchor text around the verification
+// texts that were built from it.
+func reanchor(anchor *SuggestionAnchor, verification *SuggestionVerification, before, after string) {
+	rewrite := func(text string) string {
+		if !strings.HasPrefix(text, anchor.PrecedingText) || !strings.HasSuffix(text, anchor.FollowingText) ||
+			len(text) < len(anchor.PrecedingText)+len(anchor.FollowingText) {
+			// Not built from the anchor, e.g. alt text
+			return text
+		}
+		middle := text[len(anchor.PrecedingText) : len(text)-len(anchor.FollowingText)]
+		return before + middle + after
+	}
+	verification.TextBeforeChange = rewrite(verification.TextBeforeChange)
+	verification.TextAfterChange = rewrite(verification.TextAfterChange)
+	anchor.PrecedingText = before
+	anchor.FollowingText = after
+}
+
+// anchorText returns the text around a range, extended to the boundary.
+func anchorText(structure *DocumentStructure, startIndex, endIndex int64, length int, boundary string) (before, after string) {
+	if boundary == AnchorBoundaryNone {
+		return getTextAround(structure, startIndex, endIndex, length)
+	}
+	before, after = getTextAround(structure, startIndex, endIndex, length+maxBoundaryExtension)
+	return extendBefore(before, length, boundary), extendAfter(after, length, boundary)
+}
+
+// extendBefore keeps the last length bytes of text, moved back to the start
+// of a word or sentence.
+func extendBefore(text string, length int, boundary string) string {
+	if len(text) <= length {
+		return text
+	}
+	cut := len(text) - length
+	if boundary == AnchorBoundarySentence {
+		if i := lastSentenceEnd(text[:cut]); i >= 0 {
+			return strings.TrimLeft(text[i:], " ")
+		}
+	}
+	// Back to the start of the word the cut falls in
+	for cut > 0 && !isAnchorSpace(text[cut-1]) {
+		cut--
+	}
+	return text[cut:]
+}
+
+// extendAfter keeps the first length bytes of text, moved forward to the end
+// of a word or sentence.
+func extendAfter(text string, length int, boundary string) string {
+	if len(text) <= length {
+		return text
+	}
+	cut := length
+	if boundary == AnchorBoundarySentence {
+		if i := nextSentenceEnd(text, cut); i >= 0 {
+			return text[:i]
+		}
+	}
+	for cut < len(text) && !isAnchorSpace(text[cut]) {
+		cut++
+	}
+	return text[:cut]
+}
+
+// lastSentenceEnd returns the index just after the last sentence end in text, or -1.
+func lastSentenceEnd(text string) int {
+	for i := len(text) - 1; i >= 0; i-- {
+		if text[i] == '\n' {
+			return i + 1
+		}
+		if isSentencePunct(text[i]) && i+1 < len(text) && isAnchorSpace(text[i+1]) {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// nextSentenceEnd returns the index just after the first sentence end at or after from, or -1.
+func nextSentenceEnd(text string, from int) int {
+	for i := max(from-1, 0); i < len(text); i++ {
+		if text[i] == '\n' {
+			return i + 1
+		}
+		if isSentencePunct(text[i]) && (i+1 == len(text) || isAnchorSpace(text[i+1])) {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func isSentencePunct(c byte) bool {
+	return c == '.' || c == '!' || c == '?'
+}
+
+func isAnchorSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t'
+}
+
+// maxUniqueAnchorLength caps how far EnsureUniqueAnchors widens an anchor.
+const maxUniqueAnchorLength = 1000
+
+// EnsureUniqueAnchors checks that the preceding, original and following text
+// of each suggestion occurs exactly once in the document text, and widens
+// anchors that match in several places until they don't. AnchorUnique records
+// the outcome; anchors that stay ambiguous, e.g. in repeated boilerplate, are
+// left as they were. It returns the number of ambiguous suggestions.
+func EnsureUniqueAnchors(result *ProcessingResult, boundary string) int {
+	if result.Structure == nil {
+		return 0
+	}
+	ambiguous := 0
+
+	for i := range result.ActionableSuggestions {
+		sugg := &result.ActionableSuggestions[i]
+		segment := result.Structure.segmentStructure(sugg.Location.SegmentID)
+		sugg.AnchorUnique = ensureUniqueAnchor(segment, &sugg.Anchor, &sugg.Verification, sugg.Change.OriginalText,
+			sugg.Position.StartIndex, sugg.Position.EndIndex, boundary)
+		if !sugg.AnchorUnique {
+			ambiguous++
+		}
+	}
+	for i := range result.GroupedSuggestions {
+		group := &result.GroupedSuggestions[i]
+		segment := result.Structure.segmentStructure(group.Location.SegmentID)
+		for j := range group.Suggestions {
+			sugg := &group.Suggestions[j]
+			sugg.AnchorUnique = ensureUniqueAnchor(segment, &sugg.Anchor, &sugg.Verification, sugg.Change.OriginalText,
+				sugg.Position.StartIndex, sugg.Position.EndIndex, boundary)
+		}
+	}
+	return ambiguous
+}
+
+// ensureUniqueAnchor widens an ambiguous anchor, doubling its length until it
+// is unique or can't grow any further.
+func ensureUniqueAnchor(segment *DocumentStructure, anchor *SuggestionAnchor, verification *SuggestionVerification,
+	original string, startIndex, endIndex int64, boundary string) bool {
+	if anchorUnique(segment, anchor.PrecedingText, anchor.FollowingText, original, startIndex, endIndex) {
+		return true
+	}
+
+	length := max(len(anchor.PrecedingText), len(anchor.FollowingText), DefaultAnchorLength/2)
+	lastBefore, lastAfter := anchor.PrecedingText, anchor.FollowingText
+	for length < maxUniqueAnchorLength {
+		length = min(length*2, maxUniqueAnchorLength)
+		before, after := anchorText(segment, startIndex, endIndex, length, boundary)
+		if before == lastBefore && after == lastAfter {
+			// Reached both ends of the text
+			break
+		}
+		if anchorUnique(segment, before, after, original, startIndex, endIndex) {
+			reanchor(anchor, verification, before, after)
+			return true
+		}
+		lastBefore, lastAfter = before, after
+	}
+	return false
+}
+
+// anchorUnique reports whether before + original + after matches exactly one
+// place in the text. The suggestion's own place counts even when suggested
+// text there (e.g. an insertion) keeps it from matching literally.
+func anchorUnique(segment *DocumentStructure, before, after, original string, startIndex, endIndex int64) bool {
+	needle := before + original + after
+	if needle == "" {
+		return false
+	}
+	matches := countOccurrences(segment.FullText, needle, 2)
+	if before+textBetween(segment, startIndex, endIndex)+after != needle {
+		matches++
+	}
+	return matches == 1
+}
+
+// countOccurrences counts the possibly overlapping occurrences of needle in
+// text, stopping at limit.
+func countOccurrences(text, needle string, limit int) int {
+	count := 0
+	for i := 0; count < limit; count++ {
+		j := strings.Index(text[i:], needle)
+		if j < 0 {
+			break
+		}
+		i += j + 1
+	}
+	return count
+}
+
+// textBetween returns the text from startIndex to endIndex.
+func textBetween(structure *DocumentStructure, startIndex, endIndex int64) string {
+	var b strings.Builder
+	for _, elem := range structure.TextElements {
+		if elem.EndIndex <= startIndex || elem.StartIndex >= endIndex {
+			continue
+		}
+		if elem.Object != "" {
+			b.WriteString(elem.Text)
+			continue
+		}
+		from := byteOffset(elem.Text, startIndex-elem.StartIndex)
+		to := byteOffset(elem.Text, endIndex-elem.StartIndex)
+		if from < to {
+			b.WriteString(elem.Text[from:to])
+		}
+	}
+	return b.String()
+}
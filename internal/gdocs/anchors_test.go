@@ -0,0 +1,99 @@
+package gdocs
+
+import "testing"
+
+func TestAnchorText(t *testing.T) {
+	text := "First sentence here. Second one is longer. Change target then more words follow. Last."
+	structure := &DocumentStructure{
+		TextElements: []TextElementWithPosition{{Text: text, StartIndex: 0, EndIndex: int64(len(text))}},
+	}
+	start := int64(len("First sentence here. Second one is longer. "))
+	end := start + int64(len("Change target"))
+
+	tests := []struct {
+		boundary   string
+		wantBefore string
+		wantAfter  string
+	}{
+		{AnchorBoundaryNone, "s longer. ", " then more"},
+		{AnchorBoundaryWord, "is longer. ", " then more"},
+		{AnchorBoundarySentence, "Second one is longer. ", " then more words follow."},
+	}
+	for _, tt := range tests {
+		before, after := anchorText(structure, start, end, 10, tt.boundary)
+		if before != tt.wantBefore || after != tt.wantAfter {
+			t.Errorf("%q: expected %q / %q, got %q / %q", tt.boundary, tt.wantBefore, tt.wantAfter, before, after)
+		}
+	}
+}
+
+func TestApplyAnchorOptions(t *testing.T) {
+	text := "Ubuntu is the modern, open source operating system."
+	structure := &DocumentStructure{
+		TextElements: []TextElementWithPosition{{Text: text, StartIndex: 0, EndIndex: int64(len(text))}},
+	}
+	sugg := ActionableSuggestion{
+		ID:           "s1",
+		Anchor:       SuggestionAnchor{PrecedingText: "the ", FollowingText: ", open"},
+		Change:       SuggestionChange{Type: "delete", OriginalText: "modern"},
+		Verification: SuggestionVerification{TextBeforeChange: "the modern, open", TextAfterChange: "the , open"},
+	}
+	sugg.Position.StartIndex = 14
+	sugg.Position.EndIndex = 20
+	result := &ProcessingResult{ActionableSuggestions: []ActionableSuggestion{sugg}, Structure: structure}
+
+	ApplyAnchorOptions(result, AnchorOptions{Length: 7, Boundary: AnchorBoundaryWord})
+
+	got := result.ActionableSuggestions[0]
+	if got.Anchor.PrecedingText != "is the " || got.Anchor.FollowingText != ", open source" {
+		t.Errorf("Unexpected anchor: %+v", got.Anchor)
+	}
+	if got.Verification.TextBeforeChange != "is the modern, open source" || got.Verification.TextAfterChange != "is the , open source" {
+		t.Errorf("Unexpected verification: %+v", got.Verification)
+	}
+}
+
+func TestEnsureUniqueAnchors(t *testing.T) {
+	text := "Get started today. Read more. Pricing plans. Read more. Contact us."
+	structure := &DocumentStructure{
+		FullText:     text,
+		TextElements: []TextElementWithPosition{{Text: text, StartIndex: 0, EndIndex: int64(len(text))}},
+	}
+	// Deletes the second "Read more"
+	start := int64(len("Get started today. Read more. Pricing plans. "))
+	sugg := ActionableSuggestion{
+		ID:           "s1",
+		Anchor:       SuggestionAnchor{PrecedingText: ". ", FollowingText: ". "},
+		Change:       SuggestionChange{Type: "delete", OriginalText: "Read more"},
+		Verification: SuggestionVerification{TextBeforeChange: ". Read more. ", TextAfterChange: ". . "},
+	}
+	sugg.Position.StartIndex = start
+	sugg.Position.EndIndex = start + int64(len("Read more"))
+	// Inserts text at the end, where the anchors only match once
+	insert := ActionableSuggestion{
+		ID:     "s2",
+		Anchor: SuggestionAnchor{PrecedingText: "Contact us", FollowingText: "."},
+		Change: SuggestionChange{Type: "insert", NewText: " today"},
+	}
+	insert.Position.StartIndex = int64(len(text)) - 1
+	insert.Position.EndIndex = int64(len(text)) - 1
+	result := &ProcessingResult{ActionableSuggestions: []ActionableSuggestion{sugg, insert}, Structure: structure}
+
+	if ambiguous := EnsureUniqueAnchors(result, AnchorBoundaryWord); ambiguous != 0 {
+		t.Errorf("Expected no ambiguous anchors, got %d", ambiguous)
+	}
+
+	got := result.ActionableSuggestions[0]
+	if !got.AnchorUnique {
+		t.Error("Expected the widened anchor to be unique")
+	}
+	if got.Anchor.PrecedingText != "Get started today. Read more. Pricing plans. " || got.Anchor.FollowingText != ". Contact us." {
+		t.Errorf("Unexpected anchor: %+v", got.Anchor)
+	}
+	if got.Verification.TextAfterChange != got.Anchor.PrecedingText+got.Anchor.FollowingText {
+		t.Errorf("Expected the verification to use the widened anchor, got %+v", got.Verification)
+	}
+	if !result.ActionableSuggestions[1].AnchorUnique || result.ActionableSuggestions[1].Anchor.PrecedingText != "Contact us" {
+		t.Errorf("Expected the unique insert anchor to be kept, got %+v", result.ActionableSuggestions[1])
+	}
+}
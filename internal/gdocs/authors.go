@@ -0,0 +1,99 @@
+package gdocs
+
+import (
+	"context"
+	"fmt"
+)
+
+// AddSuggestionAuthors sets the author of the suggestions in result by suggestion ID.
+func AddSuggestionAuthors(result *ProcessingResult, authors map[string]SuggestionAuthor) {
+	if len(authors) == 0 {
+		return
+	}
+	for i := range result.ActionableSuggestions {
+		sugg := &result.ActionableSuggestions[i]
+		if author, ok := authors[sugg.ID]; ok {
+			sugg.SuggestionAuthor = author
+		}
+	}
+	for i := range result.GroupedSuggestions {
+		group := &result.GroupedSuggestions[i]
+		for j := range group.Suggestions {
+			sugg := &group.Suggestions[j]
+			if author, ok := authors[sugg.ID]; ok {
+				sugg.SuggestionAuthor = author
+			}
+		}
+	}
+}
+
+// FetchSuggestionAuthor infers who made the suggestions in a document from
+// its Drive revision history. The Docs API doesn't say who made a
+// suggestion, so this only returns an author when it's unambiguous: the one
+// editor other than the owners (the usual single reviewer), or the owner
+// when nobody else edited the document. It returns nil otherwise. Revisions
+// don't map to suggestions, so CreatedTime is left empty.
+func (c *Client) FetchSuggestionAuthor(ctx context.Context, docID string) (*SuggestionAuthor, error) {
+	file, err := c.Drive.Files.Get(docID).
+		Fields("owners(displayName, emailAddress)").
+		SupportsAllDrives(true).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch document owners: %w", err)
+	}
+	owners := make(map[string]bool)
+	for _, owner := range file.Owners {
+		owners[owner.EmailAddress] = true
+	}
+
+	// Editors other than the owners
+	editors := make(map[string]*SuggestionAuthor)
+	var owner *SuggestionAuthor
+	pageToken := ""
+	for {
+		req := c.Drive.Revisions.List(docID).
+			Fields("nextPageToken, revisions(lastModifyingUser(displayName, emailAddress))").
+			PageSize(1000).
+			Context(ctx)
+		if pageToken != "" {
+			req = req.PageToken(pageToken)
+		}
+		resp, err := req.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch revisions: %w", err)
+		}
+		for _, rev := range resp.Revisions {
+			user := rev.LastModifyingUser
+			if user == nil || user.EmailAddress == "" {
+				// Anonymous or deleted users make the history ambiguous
+				return nil, nil
+			}
+			author := &SuggestionAuthor{Author: user.DisplayName, AuthorEmail: user.EmailAddress}
+			if owners[user.EmailAddress] {
+				if owner == nil {
+					owner = author
+				}
+				continue
+			}
+			if editors[user.EmailAddress] == nil {
+				editors[user.EmailAddress] = author
+			}
+		}
+
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	switch len(editors) {
+	case 0:
+		return owner, nil
+	case 1:
+		for _, editor := range editors {
+			return editor, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,78 @@
+package gdocs
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// DocumentCache keeps fetched documents on disk, keyed by document and
+// revision ID, so repeated runs against an unchanged document only ask the
+// API for its revision ID. Storing a new revision removes the older ones.
+type DocumentCache struct {
+	Dir string
+}
+
+// DefaultCacheDir returns <user cache dir>/bauer/documents.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "bauer", "documents"), nil
+}
+
+func (c *DocumentCache) path(docID, revisionID string) string {
+	return filepath.Join(c.Dir, cacheKey(docID)+"@"+cacheKey(revisionID)+".json.gz")
+}
+
+// cacheKey makes an ID safe to use in a file name.
+func cacheKey(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+// Load returns the cached document for the revision, if there is one.
+func (c *DocumentCache) Load(docID, revisionID string) (*docs.Document, bool) {
+	if revisionID == "" {
+		return nil, false
+	}
+	doc, err := ReadDocumentSnapshot(c.path(docID, revisionID))
+	if err != nil {
+		return nil, false
+	}
+	return doc, true
+}
+
+// Store caches a document under its revision ID and drops other revisions of it.
+func (c *DocumentCache) Store(docID string, doc *docs.Document) error {
+	if doc.RevisionId == "" {
+		return nil
+	}
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	path := c.path(docID, doc.RevisionId)
+	if err := WriteDocumentSnapshot(path, doc); err != nil {
+		return err
+	}
+
+	stale, _ := filepath.Glob(filepath.Join(c.Dir, cacheKey(docID)+"@*.json.gz"))
+	for _, old := range stale {
+		if old != path {
+			if err := os.Remove(old); err != nil {
+				slog.Warn("Failed to remove stale cached document", slog.String("path", old), slog.String("error", err.Error()))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package gdocs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+func TestDocumentCache(t *testing.T) {
+	cache := &DocumentCache{Dir: t.TempDir()}
+
+	if _, ok := cache.Load("doc-1", "rev-1"); ok {
+		t.Fatal("Expected an empty cache")
+	}
+
+	if err := cache.Store("doc-1", &docs.Document{DocumentId: "doc-1", RevisionId: "rev-1", Title: "First"}); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	doc, ok := cache.Load("doc-1", "rev-1")
+	if !ok || doc.Title != "First" {
+		t.Fatalf("Expected the cached revision, got %v, %v", doc, ok)
+	}
+
+	// A new revision replaces the old one
+	if err := cache.Store("doc-1", &docs.Document{DocumentId: "doc-1", RevisionId: "rev/2", Title: "Second"}); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	if _, ok := cache.Load("doc-1", "rev-1"); ok {
+		t.Error("Expected the old revision to be invalidated")
+	}
+	if doc, ok := cache.Load("doc-1", "rev/2"); !ok || doc.Title != "Second" {
+		t.Errorf("Expected the new revision, got %v, %v", doc, ok)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(cache.Dir, "*"))
+	if len(files) != 1 {
+		t.Errorf("Expected one cached file, got %v", files)
+	}
+}
@@ -2,7 +2,11 @@ package gdocs
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
 )
 
 // FetchComments fetches all comments from the document using Drive API.
@@ -31,6 +35,7 @@ func (c *Client) FetchComments(ctx context.Context, docID string) ([]Comment, er
 				CreatedTime:  c.CreatedTime,
 				ModifiedTime: c.ModifiedTime,
 				Resolved:     c.Resolved,
+				Anchor:       c.Anchor,
 			}
 
 			if c.Author != nil {
@@ -66,3 +71,246 @@ func (c *Client) FetchComments(ctx context.Context, docID string) ([]Comment, er
 
 	return comments, nil
 }
+
+// PostComment adds a comment to the whole document, not anchored to any text,
+// and returns its ID. Requires a client created with NewCommentClient.
+func (c *Client) PostComment(ctx context.Context, docID, content string) (string, error) {
+	comment, err := c.Drive.Comments.Create(docID, &drive.Comment{Content: content}).
+		Fields("id").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to post comment: %w", err)
+	}
+	return comment.Id, nil
+}
+
+// UnresolvedComments returns the comments that haven't been resolved in the document.
+func UnresolvedComments(comments []Comment) []Comment {
+	var open []Comment
+	for _, comment := range comments {
+		if !comment.Resolved {
+			open = append(open, comment)
+		}
+	}
+	return open
+}
+
+// driveAnchor is the JSON form of a Drive comment anchor. Text regions give
+// the character offset ("o") and length ("l") of the commented range.
+type driveAnchor struct {
+	Regions []struct {
+		Text *struct {
+			Offset int64 `json:"o"`
+			Length int64 `json:"l"`
+		} `json:"txt"`
+	} `json:"a"`
+}
+
+// ResolveCommentAnchors places comments in the document body and sets their
+// Location. Comments created in the Docs editor have an opaque anchor (e.g.
+// "kix.abc123") that can't be decoded, so their quoted content is located in
+// the body text instead. Returns the number of comments resolved.
+func ResolveCommentAnchors(comments []Comment, structure *DocumentStructure, metadata []MetadataTable) int {
+	resolved := 0
+	for i := range comments {
+		start, end, ok := resolveCommentRange(comments[i], structure)
+		if !ok {
+			continue
+		}
+		location := locateRange(structure, metadata, start, end)
+		comments[i].Location = &location
+		comments[i].StartIndex = start
+		comments[i].EndIndex = end
+		resolved++
+	}
+	return resolved
+}
+
+// resolveCommentRange returns the body range a comment refers to.
+func resolveCommentRange(comment Comment, structure *DocumentStructure) (start, end int64, ok bool) {
+	var anchor driveAnchor
+	if err := json.Unmarshal([]byte(comment.Anchor), &anchor); err == nil {
+		for _, region := range anchor.Regions {
+			if region.Text == nil {
+				continue
+			}
+			offset, length := int(region.Text.Offset), int(region.Text.Length)
+			// Only trust offsets that still point at the quoted text
+			if offset < 0 || length <= 0 || offset+length > len(structure.FullText) {
+				continue
+			}
+			if comment.QuotedContent != "" && structure.FullText[offset:offset+length] != comment.QuotedContent {
+				continue
+			}
+			return textRangeToIndices(structure, offset, length)
+		}
+	}
+
+	if comment.QuotedContent == "" {
+		return 0, 0, false
+	}
+	offset := strings.Index(structure.FullText, comment.QuotedContent)
+	if offset == -1 {
+		return 0, 0, false
+	}
+	return textRangeToIndices(structure, offset, len(comment.QuotedContent))
+}
+
+// textRangeToIndices converts a byte offset into the full text of the document
+// to document indices, using the text elements the full text was built from.
+func textRangeToIndices(structure *DocumentStructure, offset, length int) (start, end int64, ok bool) {
+	textOffset := 0
+	found := false
+	for _, elem := range structure.TextElements {
+		elemEnd := textOffset + len(elem.Text)
+		if !found && offset < elemEnd {
+			start = elem.StartIndex + utf16Len(elem.Text[:offset-textOffset])
+			found = true
+		}
+		if found && offset+length <= elemEnd {
+			return start, elem.StartIndex + utf16Len(elem.Text[:offset+length-textOffset]), true
+		}
+		textOffset = elemEnd
+	}
+	return 0, 0, false
+}
+
+// locateRange describes where a body range is in the document.
+func locateRange(structure *DocumentStructure, metadata []MetadataTable, start, end int64) SuggestionLocation {
+	location := SuggestionLocation{Section: "Body"}
+	if table := metadataTableAt(metadata, start, end); table != nil {
+		location.InMetadata = true
+		location.MetadataTable = table.Title
+	} else {
+		location.PageURL = findPageURL(structure, start)
+	}
+	location.ParentHeading, location.HeadingLevel = findParentHeading(structure, start)
+	if table := findTableLocation(structure, start); table != nil {
+		location.InTable = true
+		location.Table = table
+	}
+	return location
+}
+
+// AttachComments adds resolved comments to the location group they belong to.
+// Returns the comments that don't share a location with any suggestion.
+func AttachComments(groups []LocationGroupedSuggestions, comments []Comment) []Comment {
+	groupIndex := make(map[locationKey]int, len(groups))
+	for i, group := range groups {
+		groupIndex[getLocationKey(group.Location)] = i
+	}
+
+	var unattached []Comment
+	for _, comment := range comments {
+		if comment.Location == nil {
+			unattached = append(unattached, comment)
+			continue
+		}
+		i, ok := groupIndex[getLocationKey(*comment.Location)]
+		if !ok {
+			unattached = append(unattached, comment)
+			continue
+		}
+		groups[i].Comments = append(groups[i].Comments, comment)
+	}
+	return unattached
+}
+
+// CommentInstructionPrefix prefixes the IDs of suggestions built from comments,
+// which can't be accepted in the document like tracked suggestions.
+const CommentInstructionPrefix = "comment."
+
+// IsCommentInstruction reports whether a suggestion ID belongs to a comment instruction.
+func IsCommentInstruction(suggestionID string) bool {
+	return strings.HasPrefix(suggestionID, CommentInstructionPrefix)
+}
+
+// BuildCommentInstructions converts unresolved comments on quoted body text
+// into actionable suggestions of type "comment_instruction". Comments must have
+// been placed with ResolveCommentAnchors first; others are skipped.
+func BuildCommentInstructions(comments []Comment, structure *DocumentStructure) []ActionableSuggestion {
+	var instructions []ActionableSuggestion
+
+	for _, comment := range comments {
+		if comment.Resolved || comment.QuotedContent == "" || comment.Location == nil {
+			continue
+		}
+
+		precedingText, followingText := getTextAround(structure, comment.StartIndex, comment.EndIndex, DefaultAnchorLength)
+		as := ActionableSuggestion{
+			ID: CommentInstructionPrefix + comment.ID,
+			Anchor: SuggestionAnchor{
+				PrecedingText: precedingText,
+				FollowingText: followingText,
+			},
+			Change: SuggestionChange{
+				Type:         "comment_instruction",
+				OriginalText: comment.QuotedContent,
+				Instruction:  commentInstruction(comment),
+			},
+			// The outcome depends on how the instruction is interpreted
+			Verification: SuggestionVerification{
+				TextBeforeChange: precedingText + comment.QuotedContent + followingText,
+			},
+			Location: *comment.Location,
+			SuggestionAuthor: SuggestionAuthor{
+				Author:      comment.Author,
+				AuthorEmail: comment.AuthorEmail,
+				CreatedTime: comment.CreatedTime,
+			},
+		}
+		as.Position.StartIndex = comment.StartIndex
+		as.Position.EndIndex = comment.EndIndex
+
+		instructions = append(instructions, as)
+	}
+
+	return instructions
+}
+
+// commentInstruction joins a comment with its replies, which often refine the request.
+func commentInstruction(comment Comment) string {
+	parts := []string{comment.Content}
+	for _, reply := range comment.Replies {
+		switch {
+		case reply.Content == "":
+		case reply.Author != "":
+			parts = append(parts, reply.Author+": "+reply.Content)
+		default:
+			parts = append(parts, reply.Content)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// IncludeCommentInstructions adds unresolved comments as actionable suggestions
+// and regroups the result. Comments that become instructions are no longer
+// attached to their location as context. Returns the number of instructions added.
+func IncludeCommentInstructions(result *ProcessingResult) int {
+	if result.Structure == nil {
+		return 0
+	}
+
+	instructions := BuildCommentInstructions(result.Comments, result.Structure)
+	if len(instructions) == 0 {
+		return 0
+	}
+
+	converted := make(map[string]bool, len(instructions))
+	for _, as := range instructions {
+		converted[strings.TrimPrefix(as.ID, CommentInstructionPrefix)] = true
+	}
+	var remaining []Comment
+	for _, comment := range UnresolvedComments(result.Comments) {
+		if !converted[comment.ID] {
+			remaining = append(remaining, comment)
+		}
+	}
+
+	result.ActionableSuggestions = append(result.ActionableSuggestions, instructions...)
+	result.GroupedSuggestions = GroupActionableSuggestions(result.ActionableSuggestions, result.Structure)
+	AttachComments(result.GroupedSuggestions, remaining)
+
+	return len(instructions)
+}
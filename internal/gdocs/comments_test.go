@@ -0,0 +1,92 @@
+package gdocs
+
+import "testing"
+
+func buildCommentTestStructure() *DocumentStructure {
+	return &DocumentStructure{
+		Headings: []DocumentHeading{
+			{ID: "heading-1", Text: "Overview", Level: 2, StartIndex: 1, EndIndex: 10},
+		},
+		TextElements: []TextElementWithPosition{
+			{ID: "text-1", Text: "Overview\n", StartIndex: 1, EndIndex: 10},
+			{ID: "text-2", Text: "Ubuntu is ", StartIndex: 10, EndIndex: 20},
+			{ID: "text-3", Text: "fast and secure.\n", StartIndex: 20, EndIndex: 37},
+		},
+		FullText: "Overview\nUbuntu is fast and secure.\n",
+	}
+}
+
+func TestResolveCommentAnchors(t *testing.T) {
+	structure := buildCommentTestStructure()
+	comments := []Comment{
+		// Opaque Docs anchor: located through the quoted content, across text elements
+		{ID: "c1", Anchor: "kix.abc", QuotedContent: "is fast"},
+		// Drive text region anchor
+		{ID: "c2", Anchor: `{"r":"head","a":[{"txt":{"o":29,"l":6}}]}`, QuotedContent: "secure"},
+		{ID: "c3", Anchor: "kix.def", QuotedContent: "not in the document"},
+		{ID: "c4", Anchor: "kix.ghi"},
+	}
+
+	if got := ResolveCommentAnchors(comments, structure, nil); got != 2 {
+		t.Fatalf("Expected 2 resolved comments, got %d", got)
+	}
+
+	if c := comments[0]; c.Location == nil || c.StartIndex != 17 || c.EndIndex != 24 || c.Location.ParentHeading != "Overview" {
+		t.Errorf("Unexpected resolution for quoted comment: %+v", c)
+	}
+	if c := comments[1]; c.Location == nil || c.StartIndex != 29 || c.EndIndex != 35 {
+		t.Errorf("Unexpected resolution for anchored comment: %+v", c)
+	}
+	if comments[2].Location != nil || comments[3].Location != nil {
+		t.Errorf("Expected unmatched comments to stay unresolved: %+v, %+v", comments[2], comments[3])
+	}
+}
+
+func TestAttachComments(t *testing.T) {
+	location := SuggestionLocation{Section: "Body", ParentHeading: "Overview", HeadingLevel: 2}
+	other := SuggestionLocation{Section: "Body", ParentHeading: "Pricing", HeadingLevel: 2}
+	groups := []LocationGroupedSuggestions{{Location: location}}
+
+	unattached := AttachComments(groups, []Comment{
+		{ID: "c1", Location: &location},
+		{ID: "c2", Location: &other},
+		{ID: "c3"},
+	})
+
+	if len(groups[0].Comments) != 1 || groups[0].Comments[0].ID != "c1" {
+		t.Errorf("Expected c1 to be attached, got %+v", groups[0].Comments)
+	}
+	if len(unattached) != 2 {
+		t.Errorf("Expected 2 unattached comments, got %d", len(unattached))
+	}
+}
+
+func TestIncludeCommentInstructions(t *testing.T) {
+	structure := buildCommentTestStructure()
+	comments := []Comment{
+		{ID: "c1", Content: "Say quick instead", QuotedContent: "fast", Replies: []Reply{{Author: "Ana", Content: "and keep secure"}}},
+		{ID: "c2", Content: "Already done", QuotedContent: "secure", Resolved: true},
+		{ID: "c3", Content: "General remark"},
+	}
+	ResolveCommentAnchors(comments, structure, nil)
+
+	result := &ProcessingResult{Comments: comments, Structure: structure}
+	if got := IncludeCommentInstructions(result); got != 1 {
+		t.Fatalf("Expected 1 comment instruction, got %d", got)
+	}
+
+	as := result.ActionableSuggestions[0]
+	if as.ID != "comment.c1" || !IsCommentInstruction(as.ID) {
+		t.Errorf("Unexpected instruction ID: %s", as.ID)
+	}
+	if as.Change.Type != "comment_instruction" || as.Change.OriginalText != "fast" || as.Change.Instruction != "Say quick instead\nAna: and keep secure" {
+		t.Errorf("Unexpected instruction change: %+v", as.Change)
+	}
+	if as.Anchor.PrecedingText != "Overview\nUbuntu is " || as.Anchor.FollowingText != " and secure.\n" {
+		t.Errorf("Unexpected instruction anchor: %+v", as.Anchor)
+	}
+
+	if len(result.GroupedSuggestions) != 1 || len(result.GroupedSuggestions[0].Comments) != 0 {
+		t.Errorf("Expected the instruction to be grouped without duplicating the comment: %+v", result.GroupedSuggestions)
+	}
+}
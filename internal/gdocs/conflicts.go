@@ -0,0 +1,236 @@
+package gdocs
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Conflict reasons.
+const (
+	// ConflictNested is a suggestion made inside the range of another one,
+	// e.g. an edit to text that another reviewer suggested
+	ConflictNested = "nested"
+	// ConflictOverlap is a suggestion that partly overlaps another one
+	ConflictOverlap = "overlap"
+)
+
+// ConflictReport lists the suggestions dropped because they overlap others.
+type ConflictReport struct {
+	Dropped []DroppedSuggestion `json:"dropped"`
+}
+
+// DroppedSuggestion is a suggestion that was superseded by an overlapping one.
+type DroppedSuggestion struct {
+	ID string `json:"id"`
+
+	// SupersededBy is the ID of the suggestion that was kept instead
+	SupersededBy string `json:"superseded_by"`
+
+	// Reason is ConflictNested or ConflictOverlap
+	Reason string `json:"reason"`
+
+	Location SuggestionLocation `json:"location"`
+	Change   SuggestionChange   `json:"change"`
+
+	// SuggestionAuthor is who made the dropped suggestion, when known
+	SuggestionAuthor
+}
+
+// conflicting reports whether a suggestion edits text, and so can't be
+// applied together with another edit of the same text. Style and paragraph
+// changes compose with text edits; moves and comment instructions span
+// other places.
+func conflicting(group LocationGroupedSuggestions, sugg GroupedActionableSuggestion) bool {
+	if group.Location.InMetadata || sugg.MovedFrom != nil || sugg.Position.StartIndex >= sugg.Position.EndIndex {
+		return false
+	}
+	switch sugg.Change.Type {
+	case "style", "paragraph", "move", "comment_instruction":
+		return false
+	}
+	return true
+}
+
+// Conflict strategies: how the winner of overlapping suggestions is chosen.
+const (
+	// ConflictLargest keeps the suggestion with the largest range (default)
+	ConflictLargest = "largest"
+	// ConflictNewest keeps the most recent suggestion, falling back to the
+	// largest when either creation time is unknown
+	ConflictNewest = "newest"
+	// ConflictFail stops the run at the first conflict
+	ConflictFail = "fail"
+	// ConflictInteractive asks the operator to pick the winner of each conflict
+	ConflictInteractive = "interactive"
+)
+
+// ValidateConflictStrategy checks a conflict strategy; empty means largest.
+func ValidateConflictStrategy(strategy string) error {
+	switch strategy {
+	case "", ConflictLargest, ConflictNewest, ConflictFail, ConflictInteractive:
+		return nil
+	}
+	return fmt.Errorf("unknown conflict strategy %q (expected largest, newest, fail or interactive)", strategy)
+}
+
+// ConflictChooser decides between a kept suggestion and another one that
+// overlaps it. It returns true to keep other instead of kept.
+type ConflictChooser func(location SuggestionLocation, kept, other GroupedActionableSuggestion) (keepOther bool, err error)
+
+// ChooseLargest keeps the suggestion with the largest range, or the kept one
+// when both are as large.
+func ChooseLargest(_ SuggestionLocation, kept, other GroupedActionableSuggestion) (bool, error) {
+	return rangeSize(other) > rangeSize(kept), nil
+}
+
+// ChooseNewest keeps the most recently created suggestion.
+func ChooseNewest(location SuggestionLocation, kept, other GroupedActionableSuggestion) (bool, error) {
+	keptTime, err1 := time.Parse(time.RFC3339, kept.CreatedTime)
+	otherTime, err2 := time.Parse(time.RFC3339, other.CreatedTime)
+	if err1 != nil || err2 != nil || keptTime.Equal(otherTime) {
+		return ChooseLargest(location, kept, other)
+	}
+	return otherTime.After(keptTime), nil
+}
+
+// ChooseFail fails on any conflict.
+func ChooseFail(_ SuggestionLocation, kept, other GroupedActionableSuggestion) (bool, error) {
+	return false, fmt.Errorf("suggestions %s and %s overlap", kept.ID, other.ID)
+}
+
+// ConflictStrategyChooser returns the chooser of a non-interactive strategy.
+func ConflictStrategyChooser(strategy string) (ConflictChooser, error) {
+	switch strategy {
+	case "", ConflictLargest:
+		return ChooseLargest, nil
+	case ConflictNewest:
+		return ChooseNewest, nil
+	case ConflictFail:
+		return ChooseFail, nil
+	}
+	return nil, fmt.Errorf("no chooser for conflict strategy %q", strategy)
+}
+
+func rangeSize(sugg GroupedActionableSuggestion) int64 {
+	return sugg.Position.EndIndex - sugg.Position.StartIndex
+}
+
+// ResolveGroupedConflicts finds grouped suggestions whose ranges overlap and
+// keeps one of each overlapping pair, chosen by choose, since applying both
+// would edit the same text twice. Suggestions are considered largest first.
+// Every dropped suggestion is listed in result.ConflictReport with the
+// suggestion that superseded it. Errors from choose stop the resolution and
+// leave result unchanged.
+func ResolveGroupedConflicts(result *ProcessingResult, choose ConflictChooser) (*ConflictReport, error) {
+	type candidate struct {
+		group, index int
+		sugg         *GroupedActionableSuggestion
+	}
+	var candidates []candidate
+	for gi := range result.GroupedSuggestions {
+		group := &result.GroupedSuggestions[gi]
+		for si := range group.Suggestions {
+			if conflicting(*group, group.Suggestions[si]) {
+				candidates = append(candidates, candidate{gi, si, &group.Suggestions[si]})
+			}
+		}
+	}
+
+	// Largest ranges first, then in document order
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(
+			cmp.Compare(rangeSize(*b.sugg), rangeSize(*a.sugg)),
+			cmp.Compare(a.sugg.Position.StartIndex, b.sugg.Position.StartIndex),
+			cmp.Compare(a.sugg.ID, b.sugg.ID),
+		)
+	})
+
+	dropped := make(map[[2]int]DroppedSuggestion)
+	droppedIDs := make(map[string]bool)
+	drop := func(loser, winner candidate) {
+		l, w := loser.sugg, winner.sugg
+		reason := ConflictOverlap
+		if w.Position.StartIndex <= l.Position.StartIndex && l.Position.EndIndex <= w.Position.EndIndex {
+			reason = ConflictNested
+		}
+		dropped[[2]int{loser.group, loser.index}] = DroppedSuggestion{
+			ID:               l.ID,
+			SupersededBy:     w.ID,
+			Reason:           reason,
+			Location:         result.GroupedSuggestions[loser.group].Location,
+			Change:           l.Change,
+			SuggestionAuthor: l.SuggestionAuthor,
+		}
+		droppedIDs[l.ID] = true
+	}
+
+	var kept []candidate
+	for _, c := range candidates {
+		location := result.GroupedSuggestions[c.group].Location
+		keep := true
+		for i := 0; i < len(kept); {
+			k := kept[i]
+			if k.sugg.ID == c.sugg.ID ||
+				result.GroupedSuggestions[k.group].Location.SegmentID != location.SegmentID ||
+				k.sugg.Position.StartIndex >= c.sugg.Position.EndIndex ||
+				c.sugg.Position.StartIndex >= k.sugg.Position.EndIndex {
+				i++
+				continue
+			}
+
+			keepOther, err := choose(location, *k.sugg, *c.sugg)
+			if err != nil {
+				return nil, err
+			}
+			if !keepOther {
+				drop(c, k)
+				keep = false
+				break
+			}
+			drop(k, c)
+			kept = slices.Delete(kept, i, i+1)
+		}
+		if keep {
+			kept = append(kept, c)
+		}
+	}
+
+	report := &ConflictReport{Dropped: []DroppedSuggestion{}}
+	if len(dropped) > 0 {
+		report.Dropped = removeDropped(result, dropped, droppedIDs)
+	}
+	result.ConflictReport = report
+	return report, nil
+}
+
+// removeDropped removes dropped grouped suggestions, by group and index, and
+// the atomic suggestions of IDs that have no grouped suggestion left. It
+// returns the dropped suggestions in document order.
+func removeDropped(result *ProcessingResult, dropped map[[2]int]DroppedSuggestion, droppedIDs map[string]bool) []DroppedSuggestion {
+	var removed []DroppedSuggestion
+	var groups []LocationGroupedSuggestions
+	for gi, group := range result.GroupedSuggestions {
+		var suggestions []GroupedActionableSuggestion
+		for si, sugg := range group.Suggestions {
+			if d, ok := dropped[[2]int{gi, si}]; ok {
+				removed = append(removed, d)
+				continue
+			}
+			suggestions = append(suggestions, sugg)
+			// Still applied in another place
+			delete(droppedIDs, sugg.ID)
+		}
+		if len(suggestions) > 0 {
+			group.Suggestions = suggestions
+			groups = append(groups, group)
+		}
+	}
+	result.GroupedSuggestions = groups
+
+	result.ActionableSuggestions = slices.DeleteFunc(result.ActionableSuggestions, func(sugg ActionableSuggestion) bool {
+		return droppedIDs[sugg.ID]
+	})
+	return removed
+}
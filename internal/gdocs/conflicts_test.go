@@ -0,0 +1,92 @@
+package gdocs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func groupedAt(id, changeType string, start, end int64) GroupedActionableSuggestion {
+	sugg := GroupedActionableSuggestion{ID: id, Change: SuggestionChange{Type: changeType}, AtomicCount: 1}
+	sugg.Position.StartIndex = start
+	sugg.Position.EndIndex = end
+	return sugg
+}
+
+func TestResolveGroupedConflicts(t *testing.T) {
+	location := SuggestionLocation{Section: "Body", ParentHeading: "Pricing"}
+	result := &ProcessingResult{
+		ActionableSuggestions: []ActionableSuggestion{{ID: "outer"}, {ID: "inner"}, {ID: "partial"}, {ID: "bold"}, {ID: "later"}},
+		GroupedSuggestions: []LocationGroupedSuggestions{{
+			Location: location,
+			Suggestions: []GroupedActionableSuggestion{
+				groupedAt("outer", "replace", 10, 40),
+				groupedAt("inner", "delete", 15, 20),
+				groupedAt("partial", "insert", 35, 45),
+				groupedAt("bold", "style", 12, 18),
+				groupedAt("later", "insert", 50, 55),
+			},
+		}},
+	}
+	result.GroupedSuggestions[0].Suggestions[1].Author = "Reviewer"
+
+	report, err := ResolveGroupedConflicts(result, ChooseLargest)
+	if err != nil {
+		t.Fatalf("ResolveGroupedConflicts() failed: %v", err)
+	}
+
+	want := []DroppedSuggestion{
+		{ID: "inner", SupersededBy: "outer", Reason: ConflictNested, Location: location,
+			Change: SuggestionChange{Type: "delete"}, SuggestionAuthor: SuggestionAuthor{Author: "Reviewer"}},
+		{ID: "partial", SupersededBy: "outer", Reason: ConflictOverlap, Location: location,
+			Change: SuggestionChange{Type: "insert"}},
+	}
+	if diff := cmp.Diff(want, report.Dropped); diff != "" {
+		t.Errorf("Dropped mismatch (-want +got):\n%s", diff)
+	}
+	if result.ConflictReport != report {
+		t.Error("Expected the report to be attached to the result")
+	}
+
+	var kept []string
+	for _, sugg := range result.GroupedSuggestions[0].Suggestions {
+		kept = append(kept, sugg.ID)
+	}
+	if diff := cmp.Diff([]string{"outer", "bold", "later"}, kept); diff != "" {
+		t.Errorf("Kept suggestions mismatch (-want +got):\n%s", diff)
+	}
+	if len(result.ActionableSuggestions) != 3 {
+		t.Errorf("Expected the dropped atomic suggestions to be removed, got %d", len(result.ActionableSuggestions))
+	}
+}
+
+func TestResolveGroupedConflicts_Strategies(t *testing.T) {
+	newResult := func() *ProcessingResult {
+		older := groupedAt("older", "replace", 10, 40)
+		older.CreatedTime = "2024-05-01T10:00:00Z"
+		newer := groupedAt("newer", "delete", 15, 20)
+		newer.CreatedTime = "2024-05-02T10:00:00Z"
+		return &ProcessingResult{GroupedSuggestions: []LocationGroupedSuggestions{{
+			Suggestions: []GroupedActionableSuggestion{older, newer},
+		}}}
+	}
+
+	report, err := ResolveGroupedConflicts(newResult(), ChooseNewest)
+	if err != nil {
+		t.Fatalf("ResolveGroupedConflicts() failed: %v", err)
+	}
+	if len(report.Dropped) != 1 || report.Dropped[0].ID != "older" || report.Dropped[0].SupersededBy != "newer" {
+		t.Errorf("Expected the older suggestion to be dropped, got %+v", report.Dropped)
+	}
+	if report.Dropped[0].Reason != ConflictOverlap {
+		t.Errorf("Expected an overlap, got %q", report.Dropped[0].Reason)
+	}
+
+	result := newResult()
+	if _, err := ResolveGroupedConflicts(result, ChooseFail); err == nil {
+		t.Error("Expected an error with the fail strategy")
+	}
+	if len(result.GroupedSuggestions[0].Suggestions) != 2 || result.ConflictReport != nil {
+		t.Error("Expected the result to be unchanged after an error")
+	}
+}
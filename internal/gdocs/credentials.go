@@ -1,9 +1,12 @@
 package gdocs
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+
+	"golang.org/x/oauth2/google"
 )
 
 // ServiceAccountCredentials represents the structure of a Google service account JSON key file.
@@ -30,6 +33,11 @@ func ValidateCredentialsFile(path string) error {
 		return fmt.Errorf("credentials file is empty: %s", path)
 	}
 
+	return ValidateCredentialsJSON(data)
+}
+
+// ValidateCredentialsJSON checks that a service account key contains the required fields.
+func ValidateCredentialsJSON(data []byte) error {
 	// Parse JSON
 	var creds ServiceAccountCredentials
 	if err := json.Unmarshal(data, &creds); err != nil {
@@ -59,3 +67,197 @@ func ValidateCredentialsFile(path string) error {
 
 	return nil
 }
+
+// CredentialsMode selects where Google credentials are loaded from.
+type CredentialsMode string
+
+const (
+	// CredentialsFile reads a service account key file (the default)
+	CredentialsFile CredentialsMode = "file"
+
+	// CredentialsEnv reads a service account key from the BAUER_GOOGLE_CREDENTIALS
+	// environment variable, e.g. from a CI secret
+	CredentialsEnv CredentialsMode = "env"
+
+	// CredentialsADC uses Application Default Credentials: the file named by
+	// GOOGLE_APPLICATION_CREDENTIALS, gcloud's user credentials, or the
+	// metadata server when running on Google Cloud
+	CredentialsADC CredentialsMode = "adc"
+
+	// CredentialsWorkloadIdentity uses a Workload Identity Federation
+	// configuration (an external_account file), e.g. for GitHub Actions OIDC
+	CredentialsWorkloadIdentity CredentialsMode = "workload-identity"
+)
+
+// CredentialsEnvVar holds the inline service account JSON used by CredentialsEnv.
+const CredentialsEnvVar = "BAUER_GOOGLE_CREDENTIALS"
+
+// applicationCredentialsEnvVar names a credentials file, as used by Application Default Credentials.
+const applicationCredentialsEnvVar = "GOOGLE_APPLICATION_CREDENTIALS"
+
+// ParseCredentialsMode validates a credentials mode name; empty means CredentialsFile.
+func ParseCredentialsMode(name string) (CredentialsMode, error) {
+	switch mode := CredentialsMode(name); mode {
+	case "":
+		return CredentialsFile, nil
+	case CredentialsFile, CredentialsEnv, CredentialsADC, CredentialsWorkloadIdentity, CredentialsUser:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown credentials mode: %s (expected file, env, adc, workload-identity or user)", name)
+	}
+}
+
+// ClientOptions configures how a Client authenticates.
+type ClientOptions struct {
+	// Mode selects where credentials come from; empty means CredentialsFile
+	Mode CredentialsMode
+
+	// CredentialsPath is the service account key for CredentialsFile, and the
+	// Workload Identity Federation configuration for CredentialsWorkloadIdentity
+	// (default: GOOGLE_APPLICATION_CREDENTIALS)
+	CredentialsPath string
+
+	// Retry controls retries of rate-limited and failed API calls
+	Retry RetryPolicy
+
+	// CacheDir keeps fetched documents between runs (see DocumentCache);
+	// empty disables caching
+	CacheDir string
+
+	// Tab fetches only the tab of this ID or title of documents with tabs
+	Tab string
+}
+
+// Validate checks that the credentials for the selected mode are available,
+// without contacting Google.
+func (o ClientOptions) Validate() error {
+	mode, err := ParseCredentialsMode(string(o.Mode))
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case CredentialsFile:
+		if o.CredentialsPath == "" {
+			return fmt.Errorf("missing credentials file")
+		}
+		return ValidateCredentialsFile(o.CredentialsPath)
+	case CredentialsEnv:
+		data := os.Getenv(CredentialsEnvVar)
+		if data == "" {
+			return fmt.Errorf("%s is not set", CredentialsEnvVar)
+		}
+		return ValidateCredentialsJSON([]byte(data))
+	case CredentialsWorkloadIdentity:
+		_, err := o.workloadIdentityConfig()
+		return err
+	case CredentialsUser:
+		_, err := loadUserCredentials()
+		return err
+	}
+	return nil
+}
+
+// ResolveCredentials loads credentials for scopes from the source selected by opts.
+func ResolveCredentials(ctx context.Context, opts ClientOptions, scopes ...string) (*google.Credentials, error) {
+	mode, err := ParseCredentialsMode(string(opts.Mode))
+	if err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case CredentialsEnv:
+		data := os.Getenv(CredentialsEnvVar)
+		if data == "" {
+			return nil, fmt.Errorf("%s is not set", CredentialsEnvVar)
+		}
+		return serviceAccountCredentials(ctx, []byte(data), scopes)
+	case CredentialsADC:
+		creds, err := google.FindDefaultCredentials(ctx, scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find application default credentials: %w", err)
+		}
+		return creds, nil
+	case CredentialsWorkloadIdentity:
+		data, err := opts.workloadIdentityConfig()
+		if err != nil {
+			return nil, err
+		}
+		creds, err := google.CredentialsFromJSON(ctx, data, scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load workload identity credentials: %w", err)
+		}
+		return creds, nil
+	case CredentialsUser:
+		// The scopes were granted at login
+		data, err := loadUserCredentials()
+		if err != nil {
+			return nil, err
+		}
+		creds, err := google.CredentialsFromJSON(ctx, data, scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load user credentials: %w", err)
+		}
+		return creds, nil
+	default:
+		// Read service account credentials
+		data, err := os.ReadFile(opts.CredentialsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service account file: %w", err)
+		}
+		return serviceAccountCredentials(ctx, data, scopes)
+	}
+}
+
+// CheckCredentials loads the credentials selected by opts and fetches a token
+// with them, to check they're still valid, e.g. before a run or from a health
+// check.
+func CheckCredentials(ctx context.Context, opts ClientOptions) error {
+	credentials, err := ResolveCredentials(ctx, opts, "https://www.googleapis.com/auth/documents.readonly")
+	if err != nil {
+		return err
+	}
+	if _, err := credentials.TokenSource.Token(); err != nil {
+		return fmt.Errorf("failed to fetch Google token: %w", err)
+	}
+	return nil
+}
+
+func serviceAccountCredentials(ctx context.Context, data []byte, scopes []string) (*google.Credentials, error) {
+	config, err := google.JWTConfigFromJSON(data, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWT config: %w", err)
+	}
+	return &google.Credentials{
+		TokenSource: config.TokenSource(ctx),
+		JSON:        data,
+	}, nil
+}
+
+// workloadIdentityConfig reads the Workload Identity Federation configuration
+// and checks that it is one; service account keys are rejected so the mode
+// can't silently fall back to a long-lived key.
+func (o ClientOptions) workloadIdentityConfig() ([]byte, error) {
+	path := o.CredentialsPath
+	if path == "" {
+		path = os.Getenv(applicationCredentialsEnvVar)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("workload identity requires a configuration file (--credentials or %s)", applicationCredentialsEnvVar)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workload identity configuration: %w", err)
+	}
+	var config struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse workload identity configuration: %w", err)
+	}
+	if config.Type != "external_account" {
+		return nil, fmt.Errorf("%s is not a workload identity configuration (type %q, expected external_account)", path, config.Type)
+	}
+	return data, nil
+}
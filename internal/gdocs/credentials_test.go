@@ -0,0 +1,53 @@
+package gdocs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClientOptions_Validate(t *testing.T) {
+	dir := t.TempDir()
+	serviceAccount := `{"type":"service_account","project_id":"p","private_key":"k","client_email":"bauer@p.iam.gserviceaccount.com","token_uri":"https://oauth2.googleapis.com/token"}`
+	keyFile := filepath.Join(dir, "key.json")
+	wifFile := filepath.Join(dir, "wif.json")
+	if err := os.WriteFile(keyFile, []byte(serviceAccount), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(wifFile, []byte(`{"type":"external_account","audience":"//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/p/providers/gh"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    ClientOptions
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "file", opts: ClientOptions{CredentialsPath: keyFile}},
+		{name: "file missing", opts: ClientOptions{Mode: CredentialsFile}, wantErr: true},
+		{name: "env", opts: ClientOptions{Mode: CredentialsEnv}, env: map[string]string{CredentialsEnvVar: serviceAccount}},
+		{name: "env unset", opts: ClientOptions{Mode: CredentialsEnv}, env: map[string]string{CredentialsEnvVar: ""}, wantErr: true},
+		{name: "adc", opts: ClientOptions{Mode: CredentialsADC}},
+		{name: "workload identity", opts: ClientOptions{Mode: CredentialsWorkloadIdentity, CredentialsPath: wifFile}},
+		{
+			name: "workload identity from environment",
+			opts: ClientOptions{Mode: CredentialsWorkloadIdentity},
+			env:  map[string]string{applicationCredentialsEnvVar: wifFile},
+		},
+		{name: "workload identity rejects keys", opts: ClientOptions{Mode: CredentialsWorkloadIdentity, CredentialsPath: keyFile}, wantErr: true},
+		{name: "unknown mode", opts: ClientOptions{Mode: "token"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			err := tt.opts.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
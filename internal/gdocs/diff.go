@@ -0,0 +1,150 @@
+package gdocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// SuggestionDiffEntry describes a single suggestion that differs between two runs.
+type SuggestionDiffEntry struct {
+	// ID is the Google Docs suggestion ID shared by both runs
+	ID string `json:"id"`
+
+	// Location is taken from the most recent run the suggestion appears in
+	Location SuggestionLocation `json:"location"`
+
+	// Before is the suggestion as seen in the older run (nil for added suggestions)
+	Before *GroupedActionableSuggestion `json:"before,omitempty"`
+
+	// After is the suggestion as seen in the newer run (nil for removed suggestions)
+	After *GroupedActionableSuggestion `json:"after,omitempty"`
+}
+
+// RunDiff reports how the suggestions of a single document changed between two runs.
+type RunDiff struct {
+	DocumentID     string                `json:"document_id"`
+	Added          []SuggestionDiffEntry `json:"added"`
+	Removed        []SuggestionDiffEntry `json:"removed"`
+	Changed        []SuggestionDiffEntry `json:"changed"`
+	UnchangedCount int                   `json:"unchanged_count"`
+}
+
+// LoadProcessingResult reads a ProcessingResult previously written to disk
+// (e.g. bauer-doc-suggestions.json).
+func LoadProcessingResult(path string) (*ProcessingResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read processing result: %w", err)
+	}
+
+	var result ProcessingResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse processing result %s: %w", path, err)
+	}
+
+	return &result, nil
+}
+
+// DiffProcessingResults compares the grouped suggestions of two runs of the same document.
+// Suggestions are matched by ID. A suggestion is reported as changed when its
+// change (type, original text or new text) differs; anchor-only differences are
+// ignored since accepting nearby suggestions shifts every anchor around them.
+func DiffProcessingResults(before, after *ProcessingResult) (*RunDiff, error) {
+	if before == nil || after == nil {
+		return nil, fmt.Errorf("both processing results are required")
+	}
+
+	if before.DocumentID != "" && after.DocumentID != "" && before.DocumentID != after.DocumentID {
+		return nil, fmt.Errorf("runs belong to different documents: %s and %s", before.DocumentID, after.DocumentID)
+	}
+
+	diff := &RunDiff{
+		DocumentID: after.DocumentID,
+		Added:      []SuggestionDiffEntry{},
+		Removed:    []SuggestionDiffEntry{},
+		Changed:    []SuggestionDiffEntry{},
+	}
+	if diff.DocumentID == "" {
+		diff.DocumentID = before.DocumentID
+	}
+
+	beforeIndex, beforeKeys := indexGroupedSuggestions(before.GroupedSuggestions)
+	afterIndex, afterKeys := indexGroupedSuggestions(after.GroupedSuggestions)
+
+	for _, key := range afterKeys {
+		newEntry := afterIndex[key]
+		oldEntry, existed := beforeIndex[key]
+		if !existed {
+			diff.Added = append(diff.Added, SuggestionDiffEntry{
+				ID:       newEntry.suggestion.ID,
+				Location: newEntry.location,
+				After:    newEntry.suggestion,
+			})
+			continue
+		}
+
+		if !reflect.DeepEqual(oldEntry.suggestion.Change, newEntry.suggestion.Change) {
+			diff.Changed = append(diff.Changed, SuggestionDiffEntry{
+				ID:       newEntry.suggestion.ID,
+				Location: newEntry.location,
+				Before:   oldEntry.suggestion,
+				After:    newEntry.suggestion,
+			})
+			continue
+		}
+
+		diff.UnchangedCount++
+	}
+
+	for _, key := range beforeKeys {
+		if _, stillExists := afterIndex[key]; stillExists {
+			continue
+		}
+		oldEntry := beforeIndex[key]
+		diff.Removed = append(diff.Removed, SuggestionDiffEntry{
+			ID:       oldEntry.suggestion.ID,
+			Location: oldEntry.location,
+			Before:   oldEntry.suggestion,
+		})
+	}
+
+	return diff, nil
+}
+
+// indexedSuggestion pairs a grouped suggestion with the location of its group.
+type indexedSuggestion struct {
+	suggestion *GroupedActionableSuggestion
+	location   SuggestionLocation
+}
+
+// indexGroupedSuggestions flattens location groups into a map keyed by suggestion ID.
+// Non-contiguous parts sharing an ID get an ordinal suffix so they stay distinct.
+// The returned keys preserve document order.
+func indexGroupedSuggestions(groups []LocationGroupedSuggestions) (map[string]indexedSuggestion, []string) {
+	index := make(map[string]indexedSuggestion)
+	var keys []string
+	seen := make(map[string]int)
+
+	for gi := range groups {
+		for si := range groups[gi].Suggestions {
+			sugg := &groups[gi].Suggestions[si]
+			key := sugg.ID
+			if n := seen[sugg.ID]; n > 0 {
+				key = fmt.Sprintf("%s#%d", sugg.ID, n)
+			}
+			seen[sugg.ID]++
+
+			index[key] = indexedSuggestion{suggestion: sugg, location: groups[gi].Location}
+			keys = append(keys, key)
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return index[keys[i]].suggestion.Position.StartIndex < index[keys[j]].suggestion.Position.StartIndex
+	})
+
+	return index, keys
+}
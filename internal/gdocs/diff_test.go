@@ -0,0 +1,74 @@
+package gdocs
+
+import (
+	"testing"
+)
+
+func makeDiffSuggestion(id string, start int64, change SuggestionChange) GroupedActionableSuggestion {
+	sugg := GroupedActionableSuggestion{
+		ID:          id,
+		Change:      change,
+		AtomicCount: 1,
+	}
+	sugg.Position.StartIndex = start
+	sugg.Position.EndIndex = start + int64(len(change.OriginalText)+len(change.NewText))
+	return sugg
+}
+
+func TestDiffProcessingResults(t *testing.T) {
+	before := &ProcessingResult{
+		DocumentID: "doc-1",
+		GroupedSuggestions: []LocationGroupedSuggestions{
+			{
+				Location: SuggestionLocation{Section: "Body", ParentHeading: "Intro"},
+				Suggestions: []GroupedActionableSuggestion{
+					makeDiffSuggestion("kept", 10, SuggestionChange{Type: "insert", NewText: "new"}),
+					makeDiffSuggestion("edited", 20, SuggestionChange{Type: "replace", OriginalText: "a", NewText: "b"}),
+					makeDiffSuggestion("gone", 30, SuggestionChange{Type: "delete", OriginalText: "old"}),
+				},
+			},
+		},
+	}
+
+	after := &ProcessingResult{
+		DocumentID: "doc-1",
+		GroupedSuggestions: []LocationGroupedSuggestions{
+			{
+				Location: SuggestionLocation{Section: "Body", ParentHeading: "Intro"},
+				Suggestions: []GroupedActionableSuggestion{
+					makeDiffSuggestion("kept", 12, SuggestionChange{Type: "insert", NewText: "new"}),
+					makeDiffSuggestion("edited", 22, SuggestionChange{Type: "replace", OriginalText: "a", NewText: "c"}),
+					makeDiffSuggestion("fresh", 40, SuggestionChange{Type: "insert", NewText: "hello"}),
+				},
+			},
+		},
+	}
+
+	diff, err := DiffProcessingResults(before, after)
+	if err != nil {
+		t.Fatalf("DiffProcessingResults() failed: %v", err)
+	}
+
+	if len(diff.Added) != 1 || diff.Added[0].ID != "fresh" {
+		t.Errorf("Expected 'fresh' to be added, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != "gone" {
+		t.Errorf("Expected 'gone' to be removed, got %+v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].ID != "edited" {
+		t.Fatalf("Expected 'edited' to be changed, got %+v", diff.Changed)
+	}
+	if diff.Changed[0].Before.Change.NewText != "b" || diff.Changed[0].After.Change.NewText != "c" {
+		t.Errorf("Changed entry has wrong before/after: %+v", diff.Changed[0])
+	}
+	if diff.UnchangedCount != 1 {
+		t.Errorf("Expected 1 unchanged suggestion, got %d", diff.UnchangedCount)
+	}
+}
+
+func TestDiffProcessingResults_DifferentDocuments(t *testing.T) {
+	_, err := DiffProcessingResults(&ProcessingResult{DocumentID: "a"}, &ProcessingResult{DocumentID: "b"})
+	if err == nil {
+		t.Error("Expected error when comparing runs of different documents")
+	}
+}
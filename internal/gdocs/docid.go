@@ -0,0 +1,35 @@
+package gdocs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	docURLPattern = regexp.MustCompile(`/document/(?:u/\d+/)?d/([a-zA-Z0-9_-]+)`)
+	docIDPattern  = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// ParseDocumentID accepts either a bare document ID or a Google Docs URL
+// (e.g. https://docs.google.com/document/d/<id>/edit) and returns the document ID.
+func ParseDocumentID(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", fmt.Errorf("empty document ID")
+	}
+
+	if match := docURLPattern.FindStringSubmatch(input); match != nil {
+		return match[1], nil
+	}
+
+	if !docIDPattern.MatchString(input) {
+		return "", fmt.Errorf("invalid document ID or URL: %s", input)
+	}
+	return input, nil
+}
+
+// DocumentURL returns the URL of a document, for linking back to it.
+func DocumentURL(docID string) string {
+	return "https://docs.google.com/document/d/" + docID + "/edit"
+}
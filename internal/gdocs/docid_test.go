@@ -0,0 +1,29 @@
+package gdocs
+
+import "testing"
+
+func TestParseDocumentID(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "1AbC-d_9", want: "1AbC-d_9"},
+		{input: "  1AbC-d_9\n", want: "1AbC-d_9"},
+		{input: "https://docs.google.com/document/d/1AbC-d_9/edit?tab=t.0", want: "1AbC-d_9"},
+		{input: "https://docs.google.com/document/u/1/d/1AbC-d_9/edit", want: "1AbC-d_9"},
+		{input: "", wantErr: true},
+		{input: "https://example.com/page", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDocumentID(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDocumentID(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDocumentID(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
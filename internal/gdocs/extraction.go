@@ -4,191 +4,136 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
 	"strings"
 
 	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/googleapi"
 )
 
-// FetchDocument fetches the document with suggestions inline.
+// FetchDocument fetches the document with suggestions inline, with the content
+// of all its tabs (see flattenTabs), or only of c.Tab when it's set. With a
+// cache, only the revision ID is fetched when the document hasn't changed.
 func (c *Client) FetchDocument(ctx context.Context, docID string) (*docs.Document, error) {
-	// Use SUGGESTIONS_INLINE to see suggestions marked in the content
+	if c.Cache == nil {
+		return c.fetchDocument(ctx, docID)
+	}
+
+	current, err := c.Docs.Documents.Get(docID).Fields("revisionId").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch document revision: %w", err)
+	}
+	// Each tab is cached on its own
+	cacheID := docID
+	if c.Tab != "" {
+		cacheID += "#" + c.Tab
+	}
+	if doc, ok := c.Cache.Load(cacheID, current.RevisionId); ok {
+		slog.Info("Using cached document", slog.String("doc_id", docID), slog.String("revision_id", current.RevisionId))
+		return doc, nil
+	}
+
+	doc, err := c.fetchDocument(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Cache.Store(cacheID, doc); err != nil {
+		slog.Warn("Failed to cache document", slog.String("error", err.Error()))
+	}
+	return doc, nil
+}
+
+func (c *Client) fetchDocument(ctx context.Context, docID string) (*docs.Document, error) {
+	// Use SUGGESTIONS_INLINE to see suggestions marked in the content, and
+	// only ask for the fields extraction uses
 	doc, err := c.Docs.Documents.Get(docID).
 		SuggestionsViewMode("SUGGESTIONS_INLINE").
+		IncludeTabsContent(true).
+		Fields(googleapi.Field(tabsFields)).
 		Context(ctx).
 		Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch document: %w", err)
 	}
+	// Tabs can't be fetched one at a time, but the others are dropped as
+	// soon as the response is read
+	if c.Tab != "" {
+		if err := selectTab(doc, c.Tab); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	}
+	flattenTabs(doc)
 	return doc, nil
 }
 
 // ExtractSuggestions walks through the document content and extracts all suggestions.
-// TODO this and all sub functions can be made concurrent for speed
-// TODO add recursion depth control on this and sub functions
+// Use ExtractDocument when the document structure is needed as well.
 func ExtractSuggestions(doc *docs.Document) []Suggestion {
-	var suggestions []Suggestion
-
-	if doc.Body != nil {
-		for _, elem := range doc.Body.Content {
-			processStructuralElement(elem, &suggestions)
-		}
-	}
-
-	for _, header := range doc.Headers {
-		if header.Content != nil {
-			for _, elem := range header.Content {
-				processStructuralElement(elem, &suggestions)
-			}
-		}
-	}
-
-	for _, footer := range doc.Footers {
-		if footer.Content != nil {
-			for _, elem := range footer.Content {
-				processStructuralElement(elem, &suggestions)
-			}
-		}
-	}
-
+	suggestions, _ := ExtractDocument(doc, DefaultExtractionWorkers)
 	return suggestions
 }
 
 // BuildDocumentStructure builds a comprehensive structure of the document.
-// TODO this should be combined with ExtractSuggestions to avoid multiple traversals of the same document
+// Use ExtractDocument when the suggestions are needed as well.
 func BuildDocumentStructure(doc *docs.Document) *DocumentStructure {
-	structure := &DocumentStructure{
+	_, structure := ExtractDocument(doc, DefaultExtractionWorkers)
+	return structure
+}
+
+// buildSegmentStructure collects the text of a segment with its own index space
+// (e.g. a footnote). Only text, and placeholders for non-text elements, is
+// collected, which is enough to build anchors.
+func buildSegmentStructure(doc *docs.Document, content []*docs.StructuralElement) *DocumentStructure {
+	segment := &DocumentStructure{
 		Headings:     []DocumentHeading{},
 		Tables:       []TableRange{},
 		TextElements: []TextElementWithPosition{},
 	}
 
 	var fullTextBuilder strings.Builder
-
-	if doc.Body == nil || doc.Body.Content == nil {
-		return structure
-	}
-
-	var lastParagraphText string
-	var textElementCounter int
-	var tableCounter int
-	var headingCounter int
-
-	for _, elem := range doc.Body.Content {
-		// Extract headings
-		if heading := extractHeading(elem, headingCounter+1); heading != nil {
-			headingCounter++
-			structure.Headings = append(structure.Headings, *heading)
+	forEachParagraphElement(content, func(paraElem *docs.ParagraphElement) {
+		text, ok := objectElementAt(doc, paraElem)
+		if paraElem.TextRun != nil {
+			text, ok = textElementAt(paraElem), true
 		}
-
-		// Extract all text elements with positions (including from headings)
-		if elem.Paragraph != nil {
-			var paraText strings.Builder
-			for _, paraElem := range elem.Paragraph.Elements {
-				if paraElem.TextRun != nil {
-					textElementCounter++
-					structure.TextElements = append(structure.TextElements, TextElementWithPosition{
-						ID:         fmt.Sprintf("text-%d", textElementCounter),
-						Text:       paraElem.TextRun.Content,
-						StartIndex: paraElem.StartIndex,
-						EndIndex:   paraElem.EndIndex,
-					})
-					fullTextBuilder.WriteString(paraElem.TextRun.Content)
-					paraText.WriteString(paraElem.TextRun.Content)
-				}
-			}
-			lastParagraphText = strings.TrimSpace(paraText.String())
+		if !ok {
+			return
 		}
+		text.ID = fmt.Sprintf("text-%d", len(segment.TextElements)+1)
+		segment.TextElements = append(segment.TextElements, text)
+		fullTextBuilder.WriteString(text.Text)
+	})
+	segment.FullText = fullTextBuilder.String()
 
-		// Extract table structure
-		if elem.Table != nil {
-			tableCounter++
-			tableRange := TableRange{
-				ID:            fmt.Sprintf("table-%d", tableCounter),
-				Title:         lastParagraphText,
-				StartIndex:    elem.StartIndex,
-				EndIndex:      elem.EndIndex,
-				RowRanges:     []RowRange{},
-				ColumnHeaders: []string{},
-			}
-
-			for rowIdx, row := range elem.Table.TableRows {
-				rowRange := RowRange{
-					StartIndex: row.StartIndex,
-					EndIndex:   row.EndIndex,
-					CellRanges: []CellRange{},
-				}
-
-				for _, cell := range row.TableCells {
-					cellText := extractCellText(cell)
-					firstLine := cellText
-					if idx := strings.Index(cellText, "\n"); idx != -1 {
-						firstLine = cellText[:idx]
-					}
-					if len(firstLine) > 50 {
-						firstLine = firstLine[:50] + "..."
-					}
-
-					cellRange := CellRange{
-						StartIndex: cell.StartIndex,
-						EndIndex:   cell.EndIndex,
-						Text:       cellText,
-						FirstLine:  firstLine,
-					}
-					rowRange.CellRanges = append(rowRange.CellRanges, cellRange)
-
-					if rowIdx == 0 {
-						tableRange.ColumnHeaders = append(tableRange.ColumnHeaders, firstLine)
-					}
-
-					for _, cellContent := range cell.Content {
-						if cellContent.Paragraph != nil {
-							for _, paraElem := range cellContent.Paragraph.Elements {
-								if paraElem.TextRun != nil {
-									textElementCounter++
-									structure.TextElements = append(structure.TextElements, TextElementWithPosition{
-										ID:         fmt.Sprintf("text-%d", textElementCounter),
-										Text:       paraElem.TextRun.Content,
-										StartIndex: paraElem.StartIndex,
-										EndIndex:   paraElem.EndIndex,
-									})
-									fullTextBuilder.WriteString(paraElem.TextRun.Content)
-								}
-							}
-						}
-					}
-				}
-				tableRange.RowRanges = append(tableRange.RowRanges, rowRange)
-			}
-			structure.Tables = append(structure.Tables, tableRange)
-		}
+	return segment
+}
 
-		if elem.Paragraph == nil {
-			lastParagraphText = ""
-		}
+// segmentStructure returns the structure that holds the text of a segment,
+// falling back to the body for unknown or empty segment IDs.
+func (s *DocumentStructure) segmentStructure(segmentID string) *DocumentStructure {
+	if segment, ok := s.Segments[segmentID]; ok && segmentID != "" {
+		return segment
 	}
-
-	structure.FullText = fullTextBuilder.String()
-	return structure
+	return s
 }
 
 // BuildActionableSuggestions converts raw suggestions into actionable suggestions with full context.
-func BuildActionableSuggestions(suggestions []Suggestion, structure *DocumentStructure, metadata *MetadataTable) []ActionableSuggestion {
+func BuildActionableSuggestions(suggestions []Suggestion, structure *DocumentStructure, metadata []MetadataTable) []ActionableSuggestion {
 	actionable := make([]ActionableSuggestion, 0, len(suggestions))
-	const anchorLength = 80
 
 	for _, sugg := range suggestions {
-		// TODO we need to mention the exact style change, this is currently not helpful at all
-		// and breaks the model's ability to correctly verify other related changes.
-		// Potentially can detect only certain change styles like bold/italic/underline.
-		// This needs to be revisited later, with special processing for each style change,
-		// correct integration - or even total separation - from other change types.
-		// For now, skip all style changes completely.
-		if sugg.Type == "text_style_change" {
+		// Style changes are only useful when we can say exactly what changes;
+		// otherwise they break the model's ability to verify related changes.
+		// Changes to unsupported properties (e.g. font family) are skipped.
+		if sugg.Type == "text_style_change" && sugg.Style.IsEmpty() {
 			continue
 		}
 
 		as := ActionableSuggestion{
-			ID: sugg.ID,
+			ID:               sugg.ID,
+			SuggestionAuthor: sugg.SuggestionAuthor,
 		}
 
 		as.Position.StartIndex = sugg.StartIndex
@@ -198,18 +143,47 @@ func BuildActionableSuggestions(suggestions []Suggestion, structure *DocumentStr
 			Section: "Body",
 		}
 
-		if metadata != nil && sugg.StartIndex >= metadata.TableStartIndex && sugg.EndIndex <= metadata.TableEndIndex {
-			as.Location.InMetadata = true
+		// Suggestions in headers, footers and footnotes are anchored in the
+		// segment's own text. Footnotes take their heading from where they're
+		// referenced in the body; headers and footers have no heading.
+		headingPosition := sugg.StartIndex
+		segment := structure
+		if sugg.SegmentID != "" {
+			as.Location.Section = sugg.Section
+			as.Location.SegmentID = sugg.SegmentID
+			segment = structure.segmentStructure(sugg.SegmentID)
+			headingPosition = -1
+			if pos, ok := structure.FootnoteReferences[sugg.SegmentID]; ok {
+				headingPosition = pos
+			}
+		}
+
+		if segment == structure {
+			if table := metadataTableAt(metadata, sugg.StartIndex, sugg.EndIndex); table != nil {
+				as.Location.InMetadata = true
+				as.Location.MetadataTable = table.Title
+			}
+		}
+
+		if tab := tabAt(structure.Tabs, headingPosition); tab != nil {
+			as.Location.Tab = tab.Title
 		}
 
-		parentHeading, headingLevel := findParentHeading(structure, sugg.StartIndex)
+		parentHeading, headingLevel := findParentHeading(structure, headingPosition)
 		// if sugg.ID == "suggest.r3eqy31u1iac" {
 		// 	fmt.Printf("\n\n SUSPECT \n\n PARENT: %v -- level: %v \n\n", parentHeading, headingLevel)
 		// }
 		as.Location.ParentHeading = parentHeading
 		as.Location.HeadingLevel = headingLevel
+		if !as.Location.InMetadata && headingPosition >= 0 {
+			as.Location.PageURL = findPageURL(structure, headingPosition)
+		}
 
-		tableLoc := findTableLocation(structure, sugg.StartIndex)
+		if segment == structure {
+			as.Location.List = findListLocation(structure, sugg.StartIndex)
+		}
+
+		tableLoc := findTableLocation(segment, sugg.StartIndex)
 		if tableLoc != nil {
 			as.Location.InTable = true
 			as.Location.Table = tableLoc
@@ -218,7 +192,7 @@ func BuildActionableSuggestions(suggestions []Suggestion, structure *DocumentStr
 		// 	fmt.Printf("\n\n SUSPECT 1 \n\n TABLE LOC:\n %v \n\n ", tableLoc)
 		// }
 
-		precedingText, followingText := getTextAround(structure, sugg.StartIndex, sugg.EndIndex, anchorLength)
+		precedingText, followingText := getTextAround(segment, sugg.StartIndex, sugg.EndIndex, DefaultAnchorLength)
 		// if sugg.ID == "suggest.r3eqy31u1iac" {
 		// 	fmt.Printf("\n\n SUSPECT 2 \n\n PRECEDING:\n %v \n\n --FOLLOWING:\n\n %v \n\n", precedingText, followingText)
 		// }
@@ -226,6 +200,7 @@ func BuildActionableSuggestions(suggestions []Suggestion, structure *DocumentStr
 			PrecedingText: precedingText,
 			FollowingText: followingText,
 		}
+		as.Location.NearObject = nearObject(segment, sugg.StartIndex, sugg.EndIndex, DefaultAnchorLength)
 
 		switch sugg.Type {
 		case "insertion":
@@ -233,6 +208,9 @@ func BuildActionableSuggestions(suggestions []Suggestion, structure *DocumentStr
 				Type:         "insert",
 				OriginalText: "",
 				NewText:      sugg.Content,
+				NewLinkURL:   sugg.LinkURL,
+				NamedStyle:   sugg.NamedStyle,
+				Paragraph:    sugg.Paragraph,
 			}
 			as.Verification = SuggestionVerification{
 				TextBeforeChange: precedingText + followingText,
@@ -241,15 +219,77 @@ func BuildActionableSuggestions(suggestions []Suggestion, structure *DocumentStr
 
 		case "deletion":
 			as.Change = SuggestionChange{
-				Type:         "delete",
+				Type:            "delete",
+				OriginalText:    sugg.Content,
+				NewText:         "",
+				OriginalLinkURL: sugg.LinkURL,
+				NamedStyle:      sugg.NamedStyle,
+				Paragraph:       sugg.Paragraph,
+			}
+			as.Verification = SuggestionVerification{
+				TextBeforeChange: precedingText + sugg.Content + followingText,
+				TextAfterChange:  precedingText + followingText,
+			}
+
+		case "text_style_change":
+			// The text stays the same, only its formatting changes
+			as.Change = SuggestionChange{
+				Type:         "style",
+				OriginalText: sugg.Content,
+				NewText:      sugg.Content,
+				Style:        sugg.Style,
+			}
+			as.Verification = SuggestionVerification{
+				TextBeforeChange: precedingText + sugg.Content + followingText,
+				TextAfterChange:  precedingText + sugg.Content + followingText,
+			}
+
+		case "table_row_insert", "table_column_insert":
+			// Content holds the cells of the new row or column, separated by " | "
+			as.Change = SuggestionChange{
+				Type:    sugg.Type,
+				NewText: sugg.Content,
+			}
+			as.Verification = SuggestionVerification{
+				TextBeforeChange: precedingText + followingText,
+				TextAfterChange:  precedingText + sugg.Content + followingText,
+			}
+
+		case "table_row_delete", "table_column_delete":
+			as.Change = SuggestionChange{
+				Type:         sugg.Type,
 				OriginalText: sugg.Content,
-				NewText:      "",
 			}
 			as.Verification = SuggestionVerification{
 				TextBeforeChange: precedingText + sugg.Content + followingText,
 				TextAfterChange:  precedingText + followingText,
 			}
 
+		case "paragraph_change":
+			// The text stays the same, only its paragraph style changes
+			as.Change = SuggestionChange{
+				Type:         "paragraph",
+				OriginalText: sugg.Content,
+				NewText:      sugg.Content,
+				NamedStyle:   sugg.NamedStyle,
+			}
+			as.Verification = SuggestionVerification{
+				TextBeforeChange: precedingText + sugg.Content + followingText,
+				TextAfterChange:  precedingText + sugg.Content + followingText,
+			}
+
+		case "alt_text_change":
+			as.Location.Element = "image_alt_text"
+			as.Change = SuggestionChange{
+				Type:         "replace",
+				OriginalText: sugg.OriginalContent,
+				NewText:      sugg.Content,
+			}
+			as.Verification = SuggestionVerification{
+				TextBeforeChange: sugg.OriginalContent,
+				TextAfterChange:  sugg.Content,
+			}
+
 		default:
 			// Skip unknown suggestion types
 			slog.Warn("Unknown suggestion type encountered",
@@ -265,53 +305,75 @@ func BuildActionableSuggestions(suggestions []Suggestion, structure *DocumentStr
 	return actionable
 }
 
-// ExtractMetadataTable extracts the metadata table from the beginning of the document.
+// ExtractMetadataTable extracts the metadata table from the beginning of the
+// document, which describes the page.
 func ExtractMetadataTable(doc *docs.Document) *MetadataTable {
-	if doc.Body == nil || doc.Body.Content == nil {
+	tables := ExtractMetadataTables(doc)
+	if len(tables) == 0 {
 		return nil
 	}
+	return &tables[0]
+}
 
-	var firstTable *docs.Table
-	var tableStartIndex, tableEndIndex int64
+// ExtractMetadataTables extracts every metadata table of the document body: the
+// tables whose first cell is "Metadata", or ends with it (e.g. "SEO metadata").
+func ExtractMetadataTables(doc *docs.Document) []MetadataTable {
+	if doc.Body == nil || doc.Body.Content == nil {
+		return nil
+	}
 
+	var tables []MetadataTable
+	var title string
 	for _, elem := range doc.Body.Content {
-		if elem.Table != nil {
-			firstTable = elem.Table
-			tableStartIndex = elem.StartIndex
-			tableEndIndex = elem.EndIndex
-			break
+		if elem.Paragraph != nil {
+			if text := strings.TrimSpace(paragraphText(elem.Paragraph)); text != "" {
+				title = text
+			}
+			continue
+		}
+		if elem.Table == nil {
+			continue
 		}
+		if metadata := parseMetadataTable(elem.Table, title); metadata != nil {
+			metadata.TableStartIndex = elem.StartIndex
+			metadata.TableEndIndex = elem.EndIndex
+			tables = append(tables, *metadata)
+		}
+		// Paragraphs before another table don't title the next one
+		title = ""
 	}
+	return tables
+}
 
-	if firstTable == nil {
+// parseMetadataTable reads the key-value pairs of a metadata table, or returns
+// nil when the table isn't one.
+func parseMetadataTable(table *docs.Table, title string) *MetadataTable {
+	// Validate that this is a metadata table by checking the first row, first column
+	if len(table.TableRows) == 0 || len(table.TableRows[0].TableCells) == 0 {
 		return nil
 	}
-
-	// Validate that this is a metadata table by checking the first row, first column
-	if len(firstTable.TableRows) > 0 && len(firstTable.TableRows[0].TableCells) > 0 {
-		firstCellText := extractCellText(firstTable.TableRows[0].TableCells[0])
-		if !strings.EqualFold(firstCellText, "Metadata") {
-			return nil
-		}
-	} else {
+	header := strings.TrimSpace(extractCellText(table.TableRows[0].TableCells[0]))
+	if !strings.HasSuffix(strings.ToLower(header), "metadata") {
 		return nil
 	}
+	if title == "" && !strings.EqualFold(header, "Metadata") {
+		title = header
+	}
 
 	metadata := &MetadataTable{
-		Raw:             make(map[string]string),
-		TableStartIndex: tableStartIndex,
-		TableEndIndex:   tableEndIndex,
+		Title: title,
+		Raw:   make(map[string]string),
 	}
 
-	for _, row := range firstTable.TableRows {
-		if len(row.TableCells) < 2 {
+	for i, row := range table.TableRows {
+		if i == 0 || len(row.TableCells) < 2 {
 			continue
 		}
 
 		key := extractCellText(row.TableCells[0])
 		value := extractCellText(row.TableCells[1])
 
-		if key == "" || strings.EqualFold(key, "Metadata") {
+		if key == "" {
 			continue
 		}
 
@@ -334,10 +396,21 @@ func ExtractMetadataTable(doc *docs.Document) *MetadataTable {
 	return metadata
 }
 
+// metadataTableAt returns the metadata table a range is in, or nil.
+func metadataTableAt(tables []MetadataTable, start, end int64) *MetadataTable {
+	for i := range tables {
+		if start >= tables[i].TableStartIndex && end <= tables[i].TableEndIndex {
+			return &tables[i]
+		}
+	}
+	return nil
+}
+
 // Helper functions
 
 // processStructuralElement recursively processes a structural element (paragraph, table, TOC)
 // to find and extract suggestions.
+// TODO add recursion depth control on this and sub functions
 func processStructuralElement(elem *docs.StructuralElement, suggestions *[]Suggestion) {
 	if elem == nil {
 		return
@@ -361,16 +434,104 @@ func processParagraph(para *docs.Paragraph, suggestions *[]Suggestion) {
 	if para == nil {
 		return
 	}
+	first := len(*suggestions)
 	for _, paraElem := range para.Elements {
 		processParagraphElement(paraElem, suggestions)
 	}
+	markWholeParagraph(para, (*suggestions)[first:])
+
+	if len(para.Elements) == 0 {
+		return
+	}
+	start := para.Elements[0].StartIndex
+	end := para.Elements[len(para.Elements)-1].EndIndex
+	for _, suggID := range sortedKeys(para.SuggestedParagraphStyleChanges) {
+		change := para.SuggestedParagraphStyleChanges[suggID]
+		// Only named style changes (e.g. to a heading) are extracted; spacing,
+		// alignment and indentation don't translate to the page's markup
+		if change.ParagraphStyleSuggestionState == nil || !change.ParagraphStyleSuggestionState.NamedStyleTypeSuggested || change.ParagraphStyle == nil {
+			continue
+		}
+		*suggestions = append(*suggestions, Suggestion{
+			ID:         suggID,
+			Type:       "paragraph_change",
+			Content:    paragraphText(para),
+			StartIndex: start,
+			EndIndex:   end,
+			NamedStyle: &StyleProperty{Before: namedStyle(para.ParagraphStyle), After: change.ParagraphStyle.NamedStyleType},
+		})
+	}
+}
+
+// markWholeParagraph flags the insertions or deletions that cover every run of
+// a paragraph, including its newline, as paragraph insertions or deletions.
+func markWholeParagraph(para *docs.Paragraph, suggestions []Suggestion) {
+	for _, typ := range []string{"insertion", "deletion"} {
+		var ids []string
+		for i, paraElem := range para.Elements {
+			tr := paraElem.TextRun
+			if tr == nil {
+				ids = nil
+				break
+			}
+			runIDs := tr.SuggestedInsertionIds
+			if typ == "deletion" {
+				runIDs = tr.SuggestedDeletionIds
+			}
+			if i == 0 {
+				ids = runIDs
+			} else {
+				ids = intersect(ids, runIDs)
+			}
+		}
+		for i := range suggestions {
+			if suggestions[i].Type == typ && slices.Contains(ids, suggestions[i].ID) {
+				suggestions[i].Paragraph = true
+				suggestions[i].NamedStyle = &StyleProperty{After: namedStyle(para.ParagraphStyle)}
+				if typ == "deletion" {
+					suggestions[i].NamedStyle = &StyleProperty{Before: namedStyle(para.ParagraphStyle)}
+				}
+			}
+		}
+	}
+}
+
+func intersect(a, b []string) []string {
+	var result []string
+	for _, s := range a {
+		if slices.Contains(b, s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func namedStyle(style *docs.ParagraphStyle) string {
+	if style == nil {
+		return ""
+	}
+	return style.NamedStyleType
+}
+
+// paragraphText returns the text of a paragraph without its trailing newline.
+func paragraphText(para *docs.Paragraph) string {
+	var text strings.Builder
+	for _, paraElem := range para.Elements {
+		if paraElem.TextRun != nil {
+			text.WriteString(paraElem.TextRun.Content)
+		}
+	}
+	return strings.TrimSuffix(text.String(), "\n")
 }
 
 // processTable iterates through table rows and cells to extract suggestions recursively.
+// Rows and columns that are suggested in or out as a whole become a single
+// "table_row_*" or "table_column_*" suggestion instead of one per cell.
 func processTable(table *docs.Table, suggestions *[]Suggestion) {
 	if table == nil {
 		return
 	}
+	first := len(*suggestions)
 	for _, row := range table.TableRows {
 		for _, cell := range row.TableCells {
 			for _, cellContent := range cell.Content {
@@ -378,6 +539,107 @@ func processTable(table *docs.Table, suggestions *[]Suggestion) {
 			}
 		}
 	}
+
+	var structural []Suggestion
+	// Cells covered by each structural suggestion, and the IDs of suggested rows
+	covered := make(map[string][]*docs.TableCell)
+	rowIDs := make(map[string]bool)
+	for _, row := range table.TableRows {
+		content := make([]string, len(row.TableCells))
+		for i, cell := range row.TableCells {
+			content[i] = extractCellText(cell)
+		}
+		structural = appendTableSuggestions(structural, "table_row", row.SuggestedInsertionIds, row.SuggestedDeletionIds,
+			strings.Join(content, " | "), row.TableCells)
+		for _, id := range slices.Concat(row.SuggestedInsertionIds, row.SuggestedDeletionIds) {
+			covered[id] = append(covered[id], row.TableCells...)
+			rowIDs[id] = true
+		}
+	}
+	for col := range columnCount(table) {
+		cells := columnCells(table, col)
+		if cells == nil {
+			continue
+		}
+		// Cells of suggested rows carry the row's ID too, so those are skipped
+		columnIDs := func(ids func(*docs.TableCell) []string) []string {
+			var common []string
+			for _, id := range ids(cells[0]) {
+				if rowIDs[id] {
+					continue
+				}
+				if !slices.ContainsFunc(cells, func(cell *docs.TableCell) bool { return !slices.Contains(ids(cell), id) }) {
+					common = append(common, id)
+				}
+			}
+			return common
+		}
+		insertionIDs := columnIDs(func(cell *docs.TableCell) []string { return cell.SuggestedInsertionIds })
+		deletionIDs := columnIDs(func(cell *docs.TableCell) []string { return cell.SuggestedDeletionIds })
+		if len(insertionIDs)+len(deletionIDs) == 0 {
+			continue
+		}
+
+		content := make([]string, len(cells))
+		for i, cell := range cells {
+			content[i] = extractCellText(cell)
+		}
+		structural = appendTableSuggestions(structural, "table_column", insertionIDs, deletionIDs,
+			strings.Join(content, " | "), cells)
+		for _, id := range slices.Concat(insertionIDs, deletionIDs) {
+			covered[id] = append(covered[id], cells...)
+		}
+	}
+	if len(structural) == 0 {
+		return
+	}
+
+	// Drop the text suggestions that are part of a suggested row or column
+	kept := slices.DeleteFunc(slices.Clone((*suggestions)[first:]), func(sugg Suggestion) bool {
+		return slices.ContainsFunc(covered[sugg.ID], func(cell *docs.TableCell) bool {
+			return sugg.StartIndex >= cell.StartIndex && sugg.EndIndex <= cell.EndIndex
+		})
+	})
+	*suggestions = append(append((*suggestions)[:first], kept...), structural...)
+}
+
+// appendTableSuggestions records the suggested insertions and deletions of a
+// table row or column (kind), spanning its cells.
+func appendTableSuggestions(suggestions []Suggestion, kind string, insertionIDs, deletionIDs []string, content string, cells []*docs.TableCell) []Suggestion {
+	if len(cells) == 0 {
+		return suggestions
+	}
+	start, end := cells[0].StartIndex, cells[len(cells)-1].EndIndex
+	for _, suggID := range insertionIDs {
+		suggestions = append(suggestions, Suggestion{ID: suggID, Type: kind + "_insert", Content: content, StartIndex: start, EndIndex: end})
+	}
+	for _, suggID := range deletionIDs {
+		suggestions = append(suggestions, Suggestion{ID: suggID, Type: kind + "_delete", Content: content, StartIndex: start, EndIndex: end})
+	}
+	return suggestions
+}
+
+// columnCells returns the cells of a column, or nil if a row doesn't have it.
+func columnCells(table *docs.Table, col int) []*docs.TableCell {
+	cells := make([]*docs.TableCell, 0, len(table.TableRows))
+	for _, row := range table.TableRows {
+		if col >= len(row.TableCells) {
+			return nil
+		}
+		cells = append(cells, row.TableCells[col])
+	}
+	if len(cells) == 0 {
+		return nil
+	}
+	return cells
+}
+
+func columnCount(table *docs.Table) int {
+	count := 0
+	for _, row := range table.TableRows {
+		count = max(count, len(row.TableCells))
+	}
+	return count
 }
 
 // processParagraphElement inspects a single paragraph element (TextRun) for suggested insertions,
@@ -394,6 +656,7 @@ func processParagraphElement(paraElem *docs.ParagraphElement, suggestions *[]Sug
 					Content:    tr.Content,
 					StartIndex: paraElem.StartIndex,
 					EndIndex:   paraElem.EndIndex,
+					LinkURL:    runLink(tr),
 				})
 			}
 		}
@@ -406,22 +669,166 @@ func processParagraphElement(paraElem *docs.ParagraphElement, suggestions *[]Sug
 					Content:    tr.Content,
 					StartIndex: paraElem.StartIndex,
 					EndIndex:   paraElem.EndIndex,
+					LinkURL:    runLink(tr),
 				})
 			}
 		}
 
 		if tr.SuggestedTextStyleChanges != nil {
-			for suggID := range tr.SuggestedTextStyleChanges {
+			for _, suggID := range sortedKeys(tr.SuggestedTextStyleChanges) {
 				*suggestions = append(*suggestions, Suggestion{
 					ID:         suggID,
 					Type:       "text_style_change",
 					Content:    tr.Content,
 					StartIndex: paraElem.StartIndex,
 					EndIndex:   paraElem.EndIndex,
+					LinkURL:    runLink(tr),
+					Style:      buildStyleDelta(tr.TextStyle, tr.SuggestedTextStyleChanges[suggID]),
 				})
 			}
 		}
 	}
+
+	// Non-text elements can be suggested in or out as a whole. Their content is a
+	// placeholder so the change still shows up in the prompt.
+	if obj := paraElem.InlineObjectElement; obj != nil {
+		appendElementSuggestions(paraElem, Suggestion{Element: "inline_object", ObjectID: obj.InlineObjectId},
+			obj.SuggestedInsertionIds, obj.SuggestedDeletionIds, suggestions)
+	}
+	if eq := paraElem.Equation; eq != nil {
+		appendElementSuggestions(paraElem, Suggestion{Element: "equation", Content: "[equation]"},
+			eq.SuggestedInsertionIds, eq.SuggestedDeletionIds, suggestions)
+	}
+	if ref := paraElem.FootnoteReference; ref != nil {
+		appendElementSuggestions(paraElem, Suggestion{Element: "footnote_reference", Content: "[footnote " + ref.FootnoteNumber + "]"},
+			ref.SuggestedInsertionIds, ref.SuggestedDeletionIds, suggestions)
+	}
+}
+
+// runLink returns the link target of a text run, or "" if it isn't linked.
+func runLink(tr *docs.TextRun) string {
+	if tr.TextStyle == nil {
+		return ""
+	}
+	return formatLink(tr.TextStyle.Link)
+}
+
+// appendElementSuggestions records the suggested insertions and deletions of a
+// non-text paragraph element, using template for the element details.
+func appendElementSuggestions(paraElem *docs.ParagraphElement, template Suggestion, insertionIDs, deletionIDs []string, suggestions *[]Suggestion) {
+	for _, suggID := range insertionIDs {
+		sugg := template
+		sugg.ID = suggID
+		sugg.Type = "insertion"
+		sugg.StartIndex = paraElem.StartIndex
+		sugg.EndIndex = paraElem.EndIndex
+		*suggestions = append(*suggestions, sugg)
+	}
+	for _, suggID := range deletionIDs {
+		sugg := template
+		sugg.ID = suggID
+		sugg.Type = "deletion"
+		sugg.StartIndex = paraElem.StartIndex
+		sugg.EndIndex = paraElem.EndIndex
+		*suggestions = append(*suggestions, sugg)
+	}
+}
+
+// extractAltTextChanges finds suggested alt text changes on the images in content.
+// The Docs API keeps these on the document's inline objects rather than in the content.
+func extractAltTextChanges(doc *docs.Document, content []*docs.StructuralElement) []Suggestion {
+	var suggestions []Suggestion
+
+	forEachParagraphElement(content, func(paraElem *docs.ParagraphElement) {
+		if paraElem.InlineObjectElement == nil {
+			return
+		}
+		obj, ok := doc.InlineObjects[paraElem.InlineObjectElement.InlineObjectId]
+		if !ok {
+			return
+		}
+
+		current := inlineObjectAltText(obj.InlineObjectProperties)
+		for _, suggID := range sortedKeys(obj.SuggestedInlineObjectPropertiesChanges) {
+			change := obj.SuggestedInlineObjectPropertiesChanges[suggID]
+			state := change.InlineObjectPropertiesSuggestionState
+			if state == nil || state.EmbeddedObjectSuggestionState == nil {
+				continue
+			}
+			if !state.EmbeddedObjectSuggestionState.DescriptionSuggested && !state.EmbeddedObjectSuggestionState.TitleSuggested {
+				continue
+			}
+
+			suggestions = append(suggestions, Suggestion{
+				ID:              suggID,
+				Type:            "alt_text_change",
+				Content:         inlineObjectAltText(change.InlineObjectProperties),
+				OriginalContent: current,
+				Element:         "inline_object",
+				ObjectID:        paraElem.InlineObjectElement.InlineObjectId,
+				StartIndex:      paraElem.StartIndex,
+				EndIndex:        paraElem.EndIndex,
+			})
+		}
+	})
+
+	return suggestions
+}
+
+// inlineObjectAltText returns the alt text of an image: its description, or its title.
+func inlineObjectAltText(props *docs.InlineObjectProperties) string {
+	if props == nil || props.EmbeddedObject == nil {
+		return ""
+	}
+	if props.EmbeddedObject.Description != "" {
+		return props.EmbeddedObject.Description
+	}
+	return props.EmbeddedObject.Title
+}
+
+// describeInlineObject returns the placeholder used for an inline object in suggestion content.
+func describeInlineObject(doc *docs.Document, objectID string) string {
+	if obj, ok := doc.InlineObjects[objectID]; ok {
+		if alt := inlineObjectAltText(obj.InlineObjectProperties); alt != "" {
+			return "[image: " + alt + "]"
+		}
+	}
+	return "[image]"
+}
+
+// forEachParagraphElement calls fn for every paragraph element in content,
+// including those nested in tables and tables of contents.
+func forEachParagraphElement(content []*docs.StructuralElement, fn func(*docs.ParagraphElement)) {
+	for _, elem := range content {
+		if elem == nil {
+			continue
+		}
+		if elem.Paragraph != nil {
+			for _, paraElem := range elem.Paragraph.Elements {
+				fn(paraElem)
+			}
+		}
+		if elem.Table != nil {
+			for _, row := range elem.Table.TableRows {
+				for _, cell := range row.TableCells {
+					forEachParagraphElement(cell.Content, fn)
+				}
+			}
+		}
+		if elem.TableOfContents != nil {
+			forEachParagraphElement(elem.TableOfContents.Content, fn)
+		}
+	}
+}
+
+// sortedKeys returns the keys of a map in sorted order, for deterministic output.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // extractHeading attempts to extract heading info from a structural element.
@@ -498,7 +905,12 @@ func findParentHeading(structure *DocumentStructure, position int64) (string, in
 	var parentHeading string
 	var headingLevel int
 
+	// Headings of the tabs before don't carry over
+	tab := tabAt(structure.Tabs, position)
 	for _, heading := range structure.Headings {
+		if tab != nil && heading.StartIndex < tab.StartIndex {
+			continue
+		}
 		if heading.StartIndex < position {
 			parentHeading = heading.Text
 			headingLevel = heading.Level
@@ -549,6 +961,22 @@ func findTableLocation(structure *DocumentStructure, position int64) *TableLocat
 	return nil
 }
 
+// findListLocation returns the list item containing a position, or nil if it isn't in a list.
+func findListLocation(structure *DocumentStructure, position int64) *ListLocation {
+	for _, item := range structure.Lists {
+		if position >= item.StartIndex && position < item.EndIndex {
+			return &ListLocation{
+				ListID:       item.ListID,
+				NestingLevel: item.NestingLevel,
+				Ordinal:      item.Ordinal,
+				Ordered:      item.Ordered,
+				ItemText:     item.Text,
+			}
+		}
+	}
+	return nil
+}
+
 // getTextAround extracts text before and after a given position.
 // Handles partial text extraction from elements that span the positions.
 // The anchorLength parameter controls how much context to include.
@@ -556,45 +984,44 @@ func getTextAround(structure *DocumentStructure, startIndex, endIndex int64, anc
 	var beforeBuilder strings.Builder
 	var afterBuilder strings.Builder
 
+	afterDone := false
+	tab := tabAt(structure.Tabs, startIndex)
 	for _, elem := range structure.TextElements {
+		// Anchors stay in the tab of the range
+		if tab != nil && (elem.EndIndex <= tab.StartIndex || elem.StartIndex >= tab.EndIndex) {
+			continue
+		}
+
+		// Non-text elements aren't text on the page, so anchors stop at them
+		if elem.Object != "" {
+			if elem.EndIndex <= startIndex {
+				beforeBuilder.Reset()
+			} else if elem.StartIndex >= endIndex {
+				afterDone = true
+			}
+			continue
+		}
+
 		// Text before startIndex
 		if elem.EndIndex <= startIndex {
 			beforeBuilder.WriteString(elem.Text)
 		} else if elem.StartIndex < startIndex {
 			// Element spans the start position - extract the portion before startIndex
-			charsToTake := startIndex - elem.StartIndex
-			if charsToTake > 0 && charsToTake <= int64(len(elem.Text)) {
-				beforeBuilder.WriteString(elem.Text[:charsToTake])
-			}
+			beforeBuilder.WriteString(elem.Text[:byteOffset(elem.Text, startIndex-elem.StartIndex)])
 		}
 
 		// Text after endIndex
+		if afterDone {
+			continue
+		}
 		if elem.StartIndex >= endIndex {
 			afterBuilder.WriteString(elem.Text)
 		} else if elem.EndIndex > endIndex {
 			// Element spans the end position - extract the portion after endIndex
-			offsetIntoElement := endIndex - elem.StartIndex
-			if offsetIntoElement >= 0 && offsetIntoElement < int64(len(elem.Text)) {
-				afterBuilder.WriteString(elem.Text[offsetIntoElement:])
-			}
+			afterBuilder.WriteString(elem.Text[byteOffset(elem.Text, endIndex-elem.StartIndex):])
 		}
 	}
 
-	beforeText := beforeBuilder.String()
-	afterText := afterBuilder.String()
-
-	// Truncate to anchor length
-	if len(beforeText) > anchorLength {
-		before = beforeText[len(beforeText)-anchorLength:]
-	} else {
-		before = beforeText
-	}
-
-	if len(afterText) > anchorLength {
-		after = afterText[:anchorLength]
-	} else {
-		after = afterText
-	}
-
-	return before, after
+	// Truncate to anchor length, counted like document indices
+	return lastUnits(beforeBuilder.String(), anchorLength), firstUnits(afterBuilder.String(), anchorLength)
 }
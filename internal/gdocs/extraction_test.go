@@ -1,8 +1,10 @@
 package gdocs
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"google.golang.org/api/docs/v1"
 )
 
@@ -233,6 +235,51 @@ func TestExtractMetadataTable(t *testing.T) {
 	}
 }
 
+func TestExtractMetadataTables(t *testing.T) {
+	table := func(start, end int64, header, key, value string) *docs.StructuralElement {
+		return &docs.StructuralElement{StartIndex: start, EndIndex: end, Table: &docs.Table{
+			TableRows: []*docs.TableRow{
+				{TableCells: []*docs.TableCell{{Content: createContent(header)}, {Content: createContent("")}}},
+				{TableCells: []*docs.TableCell{{Content: createContent(key)}, {Content: createContent(value)}}},
+			},
+		}}
+	}
+	paragraph := func(start, end int64, text string) *docs.StructuralElement {
+		return &docs.StructuralElement{StartIndex: start, EndIndex: end, Paragraph: &docs.Paragraph{
+			Elements: []*docs.ParagraphElement{{TextRun: &docs.TextRun{Content: text}}},
+		}}
+	}
+	doc := &docs.Document{Body: &docs.Body{Content: []*docs.StructuralElement{
+		table(1, 30, "Metadata", "Page title", "Ubuntu on AWS"),
+		paragraph(30, 35, "SEO\n"),
+		paragraph(35, 36, "\n"),
+		table(36, 60, "Metadata", "Keywords", "ubuntu, aws"),
+		paragraph(60, 66, "Hero\n"),
+		table(66, 80, "Image", "hero.png", "Hero image"),
+		table(80, 110, "Localization metadata", "Languages", "en, fr"),
+	}}}
+
+	tables := ExtractMetadataTables(doc)
+	var titles []string
+	for _, table := range tables {
+		titles = append(titles, table.Title)
+	}
+	if diff := cmp.Diff([]string{"", "SEO", "Localization metadata"}, titles); diff != "" {
+		t.Errorf("Titles mismatch (-want +got):\n%s", diff)
+	}
+	if got := ExtractMetadataTable(doc); got == nil || got.PageTitle != "Ubuntu on AWS" {
+		t.Errorf("ExtractMetadataTable() = %+v, want the first table", got)
+	}
+
+	location := locateRange(&DocumentStructure{}, tables, 40, 45)
+	if !location.InMetadata || location.MetadataTable != "SEO" {
+		t.Errorf("Expected the SEO metadata table, got %+v", location)
+	}
+	if location := locateRange(&DocumentStructure{}, tables, 70, 75); location.InMetadata {
+		t.Errorf("Expected a table that isn't metadata, got %+v", location)
+	}
+}
+
 func TestBuildDocumentStructure(t *testing.T) {
 	doc := &docs.Document{
 		Body: &docs.Body{
@@ -600,6 +647,41 @@ func TestGetTextAround(t *testing.T) {
 			wantAfter:    "Start text",
 			description:  "Insertion at very start of document",
 		},
+		{
+			name: "stops at non-text elements",
+			structure: &DocumentStructure{
+				TextElements: []TextElementWithPosition{
+					{ID: "text-1", Text: "Caption ", StartIndex: 0, EndIndex: 8},
+					{ID: "text-2", Text: ObjectPlaceholder, StartIndex: 8, EndIndex: 9, Object: "image"},
+					{ID: "text-3", Text: "Hello world", StartIndex: 9, EndIndex: 20},
+					{ID: "text-4", Text: ObjectPlaceholder, StartIndex: 20, EndIndex: 21, Object: "equation"},
+					{ID: "text-5", Text: " more", StartIndex: 21, EndIndex: 26},
+				},
+			},
+			startIndex:   14,
+			endIndex:     14,
+			anchorLength: 80,
+			wantBefore:   "Hello",
+			wantAfter:    " world",
+			description:  "Text across an image isn't contiguous on the page",
+		},
+		{
+			name: "emoji and CJK text",
+			structure: &DocumentStructure{
+				// 🚀 is two UTF-16 code units, each CJK character one
+				TextElements: []TextElementWithPosition{
+					{ID: "text-1", Text: "Launch 🚀 now ", StartIndex: 0, EndIndex: 14},
+					{ID: "text-2", Text: "快速部署", StartIndex: 14, EndIndex: 18},
+					{ID: "text-3", Text: " 🎉 done", StartIndex: 18, EndIndex: 26},
+				},
+			},
+			startIndex:   16,
+			endIndex:     18,
+			anchorLength: 12,
+			wantBefore:   "ch 🚀 now 快速",
+			wantAfter:    " 🎉 done",
+			description:  "Offsets count UTF-16 code units, not bytes",
+		},
 		{
 			name: "position at document end",
 			structure: &DocumentStructure{
@@ -678,3 +760,412 @@ func TestGetTextAround(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractSuggestions_FootnotesAndInlineObjects(t *testing.T) {
+	doc := &docs.Document{
+		Body: &docs.Body{
+			Content: []*docs.StructuralElement{
+				{
+					StartIndex: 1,
+					EndIndex:   20,
+					Paragraph: &docs.Paragraph{
+						Elements: []*docs.ParagraphElement{
+							{StartIndex: 1, EndIndex: 11, TextRun: &docs.TextRun{Content: "See image "}},
+							{StartIndex: 11, EndIndex: 12, InlineObjectElement: &docs.InlineObjectElement{InlineObjectId: "img-1", SuggestedInsertionIds: []string{"ins-img"}}},
+							{StartIndex: 12, EndIndex: 13, InlineObjectElement: &docs.InlineObjectElement{InlineObjectId: "img-2"}},
+							{StartIndex: 13, EndIndex: 14, Equation: &docs.Equation{SuggestedDeletionIds: []string{"del-eq"}}},
+							{StartIndex: 14, EndIndex: 15, FootnoteReference: &docs.FootnoteReference{FootnoteId: "fn-1", FootnoteNumber: "1"}},
+							{StartIndex: 15, EndIndex: 20, TextRun: &docs.TextRun{Content: "end.\n"}},
+						},
+					},
+				},
+			},
+		},
+		InlineObjects: map[string]docs.InlineObject{
+			"img-1": {InlineObjectProperties: &docs.InlineObjectProperties{EmbeddedObject: &docs.EmbeddedObject{Description: "Ubuntu logo"}}},
+			"img-2": {
+				InlineObjectProperties: &docs.InlineObjectProperties{EmbeddedObject: &docs.EmbeddedObject{Description: "Old alt"}},
+				SuggestedInlineObjectPropertiesChanges: map[string]docs.SuggestedInlineObjectProperties{
+					"alt-1": {
+						InlineObjectProperties: &docs.InlineObjectProperties{EmbeddedObject: &docs.EmbeddedObject{Description: "New alt"}},
+						InlineObjectPropertiesSuggestionState: &docs.InlineObjectPropertiesSuggestionState{
+							EmbeddedObjectSuggestionState: &docs.EmbeddedObjectSuggestionState{DescriptionSuggested: true},
+						},
+					},
+				},
+			},
+		},
+		Footnotes: map[string]docs.Footnote{
+			"fn-1": {
+				FootnoteId: "fn-1",
+				Content: []*docs.StructuralElement{
+					{
+						StartIndex: 0,
+						EndIndex:   18,
+						Paragraph: &docs.Paragraph{
+							Elements: []*docs.ParagraphElement{
+								{StartIndex: 0, EndIndex: 8, TextRun: &docs.TextRun{Content: "Source: "}},
+								{StartIndex: 8, EndIndex: 18, TextRun: &docs.TextRun{Content: "Canonical", SuggestedInsertionIds: []string{"ins-fn"}}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	suggestions := ExtractSuggestions(doc)
+	byID := make(map[string]Suggestion)
+	for _, s := range suggestions {
+		byID[s.ID] = s
+	}
+
+	if s := byID["ins-img"]; s.Type != "insertion" || s.Element != "inline_object" || s.Content != "[image: Ubuntu logo]" {
+		t.Errorf("Unexpected image insertion: %+v", s)
+	}
+	if s := byID["del-eq"]; s.Type != "deletion" || s.Element != "equation" {
+		t.Errorf("Unexpected equation deletion: %+v", s)
+	}
+	if s := byID["alt-1"]; s.Type != "alt_text_change" || s.OriginalContent != "Old alt" || s.Content != "New alt" {
+		t.Errorf("Unexpected alt text change: %+v", s)
+	}
+	if s := byID["ins-fn"]; s.Section != "Footnote" || s.SegmentID != "fn-1" {
+		t.Errorf("Unexpected footnote suggestion: %+v", s)
+	}
+
+	// Non-text elements keep their index in the text
+	structure := BuildDocumentStructure(doc)
+	if want := "See image " + strings.Repeat(ObjectPlaceholder, 4) + "end.\n"; structure.FullText != want {
+		t.Errorf("FullText = %q, want %q", structure.FullText, want)
+	}
+
+	// Footnote anchors come from the footnote text, not the body
+	actionable := BuildActionableSuggestions(suggestions, structure, nil)
+	for _, as := range actionable {
+		switch as.ID {
+		case "ins-img":
+			if as.Anchor.PrecedingText != "See image " || as.Anchor.FollowingText != "" || as.Location.NearObject != "image" {
+				t.Errorf("Unexpected image suggestion: %+v", as)
+			}
+		case "ins-fn":
+			if as.Anchor.PrecedingText != "Source: " || as.Location.Section != "Footnote" {
+				t.Errorf("Unexpected footnote suggestion: %+v", as)
+			}
+		case "alt-1":
+			if as.Location.Element != "image_alt_text" || as.Change.Type != "replace" || as.Change.NewText != "New alt" {
+				t.Errorf("Unexpected alt text suggestion: %+v", as)
+			}
+		}
+	}
+	if len(actionable) != 4 {
+		t.Errorf("Expected 4 actionable suggestions, got %d", len(actionable))
+	}
+}
+
+func TestExtractSuggestions_StyleDelta(t *testing.T) {
+	doc := &docs.Document{
+		Body: &docs.Body{
+			Content: []*docs.StructuralElement{
+				{
+					StartIndex: 1,
+					EndIndex:   22,
+					Paragraph: &docs.Paragraph{
+						Elements: []*docs.ParagraphElement{
+							{StartIndex: 1, EndIndex: 8, TextRun: &docs.TextRun{Content: "Ubuntu "}},
+							{
+								StartIndex: 8,
+								EndIndex:   22,
+								TextRun: &docs.TextRun{
+									Content:   "for the cloud\n",
+									TextStyle: &docs.TextStyle{Italic: true},
+									SuggestedTextStyleChanges: map[string]docs.SuggestedTextStyle{
+										"style-1": {
+											TextStyle: &docs.TextStyle{Bold: true, Link: &docs.Link{Url: "https://ubuntu.com/cloud"}},
+											TextStyleSuggestionState: &docs.TextStyleSuggestionState{
+												BoldSuggested:   true,
+												ItalicSuggested: true,
+												LinkSuggested:   true,
+											},
+										},
+										// Font family changes can't be described, so the suggestion is dropped
+										"style-2": {
+											TextStyle:                &docs.TextStyle{},
+											TextStyleSuggestionState: &docs.TextStyleSuggestionState{WeightedFontFamilySuggested: true},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	actionable := BuildActionableSuggestions(ExtractSuggestions(doc), BuildDocumentStructure(doc), nil)
+	if len(actionable) != 1 {
+		t.Fatalf("Expected 1 actionable suggestion, got %d", len(actionable))
+	}
+
+	change := actionable[0].Change
+	if change.Type != "style" || change.OriginalText != "for the cloud\n" || change.NewText != change.OriginalText {
+		t.Errorf("Unexpected style change: %+v", change)
+	}
+
+	want := &StyleDelta{
+		Bold:   &StyleProperty{Before: "", After: "true"},
+		Italic: &StyleProperty{Before: "true", After: ""},
+		Link:   &StyleProperty{Before: "", After: "https://ubuntu.com/cloud"},
+	}
+	if diff := cmp.Diff(want, change.Style); diff != "" {
+		t.Errorf("Style mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExtractSuggestions_HeadersAndFooters(t *testing.T) {
+	paragraph := func(elements ...*docs.ParagraphElement) []*docs.StructuralElement {
+		return []*docs.StructuralElement{{Paragraph: &docs.Paragraph{Elements: elements}}}
+	}
+
+	doc := &docs.Document{
+		Body: &docs.Body{Content: paragraph(
+			&docs.ParagraphElement{StartIndex: 1, EndIndex: 8, TextRun: &docs.TextRun{Content: "Ubuntu "}},
+			&docs.ParagraphElement{StartIndex: 8, EndIndex: 12, TextRun: &docs.TextRun{Content: "Pro\n", SuggestedInsertionIds: []string{"ins-body"}}},
+		)},
+		Headers: map[string]docs.Header{
+			"kix.header1": {Content: paragraph(
+				&docs.ParagraphElement{StartIndex: 0, EndIndex: 10, TextRun: &docs.TextRun{Content: "Canonical "}},
+				&docs.ParagraphElement{StartIndex: 10, EndIndex: 14, TextRun: &docs.TextRun{Content: "Ltd\n", SuggestedInsertionIds: []string{"ins-header"}}},
+			)},
+		},
+		Footers: map[string]docs.Footer{
+			"kix.footer1": {Content: paragraph(
+				&docs.ParagraphElement{StartIndex: 0, EndIndex: 7, TextRun: &docs.TextRun{Content: "© 2024", SuggestedDeletionIds: []string{"del-footer"}}},
+			)},
+		},
+	}
+
+	suggestions, structure := ExtractDocument(doc, 2)
+	byID := make(map[string]Suggestion)
+	for _, s := range suggestions {
+		byID[s.ID] = s
+	}
+	if s := byID["ins-body"]; s.Section != "" || s.SegmentID != "" {
+		t.Errorf("Unexpected body suggestion: %+v", s)
+	}
+	if s := byID["ins-header"]; s.Section != "Header" || s.SegmentID != "kix.header1" {
+		t.Errorf("Unexpected header suggestion: %+v", s)
+	}
+	if s := byID["del-footer"]; s.Section != "Footer" || s.SegmentID != "kix.footer1" {
+		t.Errorf("Unexpected footer suggestion: %+v", s)
+	}
+
+	actionable := BuildActionableSuggestions(suggestions, structure, nil)
+	groups := GroupActionableSuggestions(actionable, structure)
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 location groups, got %d", len(groups))
+	}
+	for i, want := range []string{"Body", "Header", "Footer"} {
+		if groups[i].Location.Section != want {
+			t.Errorf("Group %d: expected section %s, got %s", i, want, groups[i].Location.Section)
+		}
+	}
+
+	// Header anchors come from the header text, not the body
+	if got := groups[1].Suggestions[0].Anchor.PrecedingText; got != "Canonical " {
+		t.Errorf("Expected header anchor %q, got %q", "Canonical ", got)
+	}
+}
+
+func TestBuildActionableSuggestions_Lists(t *testing.T) {
+	item := func(start int64, text string, level int64, suggestionID string) *docs.StructuralElement {
+		end := start + int64(len(text)) + 1
+		run := &docs.TextRun{Content: text + "\n"}
+		if suggestionID != "" {
+			run.SuggestedInsertionIds = []string{suggestionID}
+		}
+		return &docs.StructuralElement{
+			StartIndex: start,
+			EndIndex:   end,
+			Paragraph: &docs.Paragraph{
+				Bullet:   &docs.Bullet{ListId: "kix.list1", NestingLevel: level},
+				Elements: []*docs.ParagraphElement{{StartIndex: start, EndIndex: end, TextRun: run}},
+			},
+		}
+	}
+
+	doc := &docs.Document{
+		Body: &docs.Body{Content: []*docs.StructuralElement{
+			headingElement(1, 10, "HEADING_2", "Features"),
+			item(10, "Fast", 0, ""),
+			item(15, "Nested", 1, ""),
+			item(22, "Secure", 0, ""),
+			item(29, "Open", 0, "ins-third"),
+		}},
+		Lists: map[string]docs.List{
+			"kix.list1": {ListProperties: &docs.ListProperties{NestingLevels: []*docs.NestingLevel{{GlyphType: "DECIMAL"}, {GlyphSymbol: "●"}}}},
+		},
+	}
+
+	suggestions, structure := ExtractDocument(doc, 1)
+	var ordinals []int
+	for _, item := range structure.Lists {
+		ordinals = append(ordinals, item.Ordinal)
+	}
+	if diff := cmp.Diff([]int{1, 1, 2, 3}, ordinals); diff != "" {
+		t.Errorf("Ordinals mismatch (-want +got):\n%s", diff)
+	}
+	if structure.Lists[1].Ordered || !structure.Lists[0].Ordered {
+		t.Errorf("Expected a numbered top level and bulleted nested level, got %+v", structure.Lists)
+	}
+
+	actionable := BuildActionableSuggestions(suggestions, structure, nil)
+	if len(actionable) != 1 {
+		t.Fatalf("Expected 1 suggestion, got %d", len(actionable))
+	}
+	loc := actionable[0].Location
+	want := &ListLocation{ListID: "kix.list1", Ordinal: 3, Ordered: true, ItemText: "Open"}
+	if loc.ParentHeading != "Features" {
+		t.Errorf("Expected parent heading Features, got %q", loc.ParentHeading)
+	}
+	if diff := cmp.Diff(want, loc.List); diff != "" {
+		t.Errorf("List location mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGroupActionableSuggestions_LinkURLs(t *testing.T) {
+	link := func(url string) *docs.TextStyle {
+		return &docs.TextStyle{Link: &docs.Link{Url: url}}
+	}
+	doc := &docs.Document{
+		Body: &docs.Body{Content: []*docs.StructuralElement{{
+			StartIndex: 1,
+			EndIndex:   40,
+			Paragraph: &docs.Paragraph{Elements: []*docs.ParagraphElement{
+				{StartIndex: 1, EndIndex: 7, TextRun: &docs.TextRun{Content: "Visit "}},
+				{StartIndex: 7, EndIndex: 17, TextRun: &docs.TextRun{Content: "the store", TextStyle: link("https://old.example.com"), SuggestedDeletionIds: []string{"cta"}}},
+				{StartIndex: 17, EndIndex: 24, TextRun: &docs.TextRun{Content: "our shop", TextStyle: link("https://shop.example.com"), SuggestedInsertionIds: []string{"cta"}}},
+				{StartIndex: 24, EndIndex: 40, TextRun: &docs.TextRun{Content: " today.\n"}},
+			}},
+		}}},
+	}
+
+	suggestions, structure := ExtractDocument(doc, 1)
+	groups := GroupActionableSuggestions(BuildActionableSuggestions(suggestions, structure, nil), structure)
+	if len(groups) != 1 || len(groups[0].Suggestions) != 1 {
+		t.Fatalf("Expected one grouped suggestion, got %+v", groups)
+	}
+	change := groups[0].Suggestions[0].Change
+	if change.Type != "replace" || change.OriginalLinkURL != "https://old.example.com" || change.NewLinkURL != "https://shop.example.com" {
+		t.Errorf("Expected a replacement with both links, got %+v", change)
+	}
+	if structure.TextElements[1].LinkURL != "https://old.example.com" {
+		t.Errorf("Expected the text element to keep its link, got %+v", structure.TextElements[1])
+	}
+}
+
+func TestExtractSuggestions_ParagraphChanges(t *testing.T) {
+	doc := &docs.Document{
+		Body: &docs.Body{Content: []*docs.StructuralElement{
+			{
+				StartIndex: 1,
+				EndIndex:   10,
+				Paragraph: &docs.Paragraph{
+					ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: "NORMAL_TEXT"},
+					Elements: []*docs.ParagraphElement{
+						{StartIndex: 1, EndIndex: 10, TextRun: &docs.TextRun{Content: "Features\n"}},
+					},
+					SuggestedParagraphStyleChanges: map[string]docs.SuggestedParagraphStyle{
+						"to-h3": {
+							ParagraphStyle:                &docs.ParagraphStyle{NamedStyleType: "HEADING_3"},
+							ParagraphStyleSuggestionState: &docs.ParagraphStyleSuggestionState{NamedStyleTypeSuggested: true},
+						},
+						"spacing": {
+							ParagraphStyle:                &docs.ParagraphStyle{},
+							ParagraphStyleSuggestionState: &docs.ParagraphStyleSuggestionState{SpaceAboveSuggested: true},
+						},
+					},
+				},
+			},
+			{
+				StartIndex: 10,
+				EndIndex:   24,
+				Paragraph: &docs.Paragraph{
+					ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: "HEADING_2"},
+					Elements: []*docs.ParagraphElement{
+						{StartIndex: 10, EndIndex: 14, TextRun: &docs.TextRun{Content: "New ", SuggestedInsertionIds: []string{"new-para"}}},
+						{StartIndex: 14, EndIndex: 24, TextRun: &docs.TextRun{Content: "section\n", SuggestedInsertionIds: []string{"new-para"}, TextStyle: &docs.TextStyle{Bold: true}}},
+					},
+				},
+			},
+		}},
+	}
+
+	suggestions, structure := ExtractDocument(doc, 1)
+	groups := GroupActionableSuggestions(BuildActionableSuggestions(suggestions, structure, nil), structure)
+
+	byID := make(map[string]SuggestionChange)
+	for _, group := range groups {
+		for _, sugg := range group.Suggestions {
+			byID[sugg.ID] = sugg.Change
+		}
+	}
+	if len(byID) != 2 {
+		t.Fatalf("Expected 2 suggestions, got %v", byID)
+	}
+
+	heading := byID["to-h3"]
+	if heading.Type != "paragraph" || heading.OriginalText != "Features" {
+		t.Errorf("Unexpected paragraph change: %+v", heading)
+	}
+	if diff := cmp.Diff(&StyleProperty{Before: "NORMAL_TEXT", After: "HEADING_3"}, heading.NamedStyle); diff != "" {
+		t.Errorf("Named style mismatch (-want +got):\n%s", diff)
+	}
+
+	inserted := byID["new-para"]
+	if inserted.Type != "insert" || !inserted.Paragraph || inserted.NamedStyle == nil || inserted.NamedStyle.After != "HEADING_2" {
+		t.Errorf("Expected a whole HEADING_2 paragraph insertion, got %+v", inserted)
+	}
+}
+
+func TestExtractSuggestions_TableRowsAndColumns(t *testing.T) {
+	cell := func(start int64, text string, ins, del []string) *docs.TableCell {
+		end := start + int64(len(text)) + 2
+		return &docs.TableCell{
+			StartIndex:            start,
+			EndIndex:              end,
+			SuggestedInsertionIds: ins,
+			SuggestedDeletionIds:  del,
+			Content: []*docs.StructuralElement{{
+				StartIndex: start + 1,
+				EndIndex:   end,
+				Paragraph: &docs.Paragraph{Elements: []*docs.ParagraphElement{{
+					StartIndex: start + 1,
+					EndIndex:   end,
+					TextRun:    &docs.TextRun{Content: text + "\n", SuggestedInsertionIds: ins, SuggestedDeletionIds: del},
+				}}},
+			}},
+		}
+	}
+	// A deleted second column, and a new last row
+	rowNew := []string{"row-new"}
+	colDel := []string{"col-del"}
+	table := &docs.Table{TableRows: []*docs.TableRow{
+		{StartIndex: 2, EndIndex: 14, TableCells: []*docs.TableCell{cell(3, "Plan", nil, nil), cell(9, "Old", nil, colDel)}},
+		{StartIndex: 14, EndIndex: 27, TableCells: []*docs.TableCell{cell(15, "Free", nil, nil), cell(21, "Yes", nil, colDel)}},
+		{StartIndex: 27, EndIndex: 40, SuggestedInsertionIds: rowNew, TableCells: []*docs.TableCell{cell(28, "Pro", rowNew, nil), cell(33, "No", rowNew, colDel)}},
+	}}
+	doc := &docs.Document{Body: &docs.Body{Content: []*docs.StructuralElement{{StartIndex: 1, EndIndex: 40, Table: table}}}}
+
+	got := make(map[string]string)
+	for _, sugg := range ExtractSuggestions(doc) {
+		got[sugg.ID+":"+sugg.Type] = sugg.Content
+	}
+	want := map[string]string{
+		"row-new:table_row_insert":    "Pro | No",
+		"col-del:table_column_delete": "Old | Yes | No",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Suggestions mismatch (-want +got):\n%s", diff)
+	}
+}
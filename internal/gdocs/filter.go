@@ -0,0 +1,115 @@
+package gdocs
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// SuggestionFilter narrows a run to some of a document's suggestions. Empty
+// fields don't filter; a suggestion must match every field that is set.
+type SuggestionFilter struct {
+	// Authors matches a suggestion's author name or email, case-insensitively
+	Authors []string
+
+	// Since keeps suggestions created at or after this time. Suggestions
+	// without a known creation time are kept.
+	Since time.Time
+
+	// IDs keeps only these suggestion IDs
+	IDs []string
+
+	// ExcludeIDs drops these suggestion IDs, e.g. those applied by earlier runs
+	ExcludeIDs []string
+}
+
+// Empty reports whether the filter keeps every suggestion.
+func (f SuggestionFilter) Empty() bool {
+	return len(f.Authors) == 0 && f.Since.IsZero() && len(f.IDs) == 0 && len(f.ExcludeIDs) == 0
+}
+
+// ParseSince parses a --since value: an RFC 3339 timestamp or a YYYY-MM-DD date.
+func ParseSince(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (expected YYYY-MM-DD or RFC 3339)", value)
+	}
+	return t, nil
+}
+
+func (f SuggestionFilter) match(id string, author SuggestionAuthor) bool {
+	if len(f.IDs) > 0 && !containsFold(f.IDs, id) {
+		return false
+	}
+	if len(f.ExcludeIDs) > 0 && containsFold(f.ExcludeIDs, id) {
+		return false
+	}
+	if len(f.Authors) > 0 && !containsFold(f.Authors, author.Author) && !containsFold(f.Authors, author.AuthorEmail) {
+		return false
+	}
+	if !f.Since.IsZero() && author.CreatedTime != "" {
+		created, err := time.Parse(time.RFC3339, author.CreatedTime)
+		if err == nil && created.Before(f.Since) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterSuggestions removes the suggestions that don't match the filter from
+// the result, dropping location groups left without suggestions. It returns
+// the number of suggestions kept.
+func FilterSuggestions(result *ProcessingResult, filter SuggestionFilter) int {
+	if filter.Empty() {
+		return len(result.ActionableSuggestions)
+	}
+
+	var kept []ActionableSuggestion
+	undated := 0
+	for _, sugg := range result.ActionableSuggestions {
+		if !filter.match(sugg.ID, sugg.SuggestionAuthor) {
+			continue
+		}
+		if !filter.Since.IsZero() && sugg.CreatedTime == "" {
+			undated++
+		}
+		kept = append(kept, sugg)
+	}
+	result.ActionableSuggestions = kept
+
+	var groups []LocationGroupedSuggestions
+	for _, group := range result.GroupedSuggestions {
+		var suggestions []GroupedActionableSuggestion
+		for _, sugg := range group.Suggestions {
+			if filter.match(sugg.ID, sugg.SuggestionAuthor) {
+				suggestions = append(suggestions, sugg)
+			}
+		}
+		if len(suggestions) > 0 {
+			group.Suggestions = suggestions
+			groups = append(groups, group)
+		}
+	}
+	result.GroupedSuggestions = groups
+
+	if undated > 0 {
+		slog.Warn("Kept suggestions without a creation time", slog.Int("count", undated))
+	}
+	return len(kept)
+}
@@ -0,0 +1,71 @@
+package gdocs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterSuggestions(t *testing.T) {
+	ana := SuggestionAuthor{Author: "Ana", AuthorEmail: "ana@example.com", CreatedTime: "2024-05-02T09:00:00Z"}
+	ben := SuggestionAuthor{Author: "Ben", CreatedTime: "2024-04-01T09:00:00Z"}
+	newResult := func() *ProcessingResult {
+		return &ProcessingResult{
+			ActionableSuggestions: []ActionableSuggestion{
+				{ID: "s1", SuggestionAuthor: ana},
+				{ID: "s2", SuggestionAuthor: ben},
+				{ID: "s3"},
+			},
+			GroupedSuggestions: []LocationGroupedSuggestions{
+				{Suggestions: []GroupedActionableSuggestion{{ID: "s1", SuggestionAuthor: ana}, {ID: "s2", SuggestionAuthor: ben}}},
+				{Suggestions: []GroupedActionableSuggestion{{ID: "s3"}}},
+			},
+		}
+	}
+	since, _ := ParseSince("2024-05-01")
+
+	tests := []struct {
+		name   string
+		filter SuggestionFilter
+		want   []string
+		groups int
+	}{
+		{name: "no filter", filter: SuggestionFilter{}, want: []string{"s1", "s2", "s3"}, groups: 2},
+		{name: "author email", filter: SuggestionFilter{Authors: []string{"ANA@example.com"}}, want: []string{"s1"}, groups: 1},
+		{name: "since keeps undated", filter: SuggestionFilter{Since: since}, want: []string{"s1", "s3"}, groups: 2},
+		{name: "ids", filter: SuggestionFilter{IDs: []string{"s2", "s3"}}, want: []string{"s2", "s3"}, groups: 2},
+		{name: "exclude ids", filter: SuggestionFilter{ExcludeIDs: []string{"s3"}}, want: []string{"s1", "s2"}, groups: 1},
+		{name: "combined", filter: SuggestionFilter{Authors: []string{"Ben"}, Since: since}, want: nil, groups: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newResult()
+			kept := FilterSuggestions(result, tt.filter)
+
+			var ids []string
+			for _, sugg := range result.ActionableSuggestions {
+				ids = append(ids, sugg.ID)
+			}
+			if kept != len(tt.want) || len(ids) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, ids)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, ids)
+				}
+			}
+			if len(result.GroupedSuggestions) != tt.groups {
+				t.Errorf("Expected %d groups, got %d", tt.groups, len(result.GroupedSuggestions))
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	if got, err := ParseSince("2024-05-01T10:00:00+02:00"); err != nil || !got.Equal(time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected result: %v, %v", got, err)
+	}
+	if _, err := ParseSince("last week"); err == nil {
+		t.Error("Expected an error for an invalid time")
+	}
+}
@@ -1,7 +1,11 @@
 package gdocs
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -15,8 +19,8 @@ func GroupActionableSuggestions(suggestions []ActionableSuggestion, structure *D
 	}
 
 	// First, group suggestions by location
-	locationGroups := make(map[string][]ActionableSuggestion)
-	locationMap := make(map[string]SuggestionLocation) // Track the actual location object
+	locationGroups := make(map[locationKey][]ActionableSuggestion)
+	locationMap := make(map[locationKey]SuggestionLocation) // Track the actual location object
 
 	for _, sugg := range suggestions {
 		locationKey := getLocationKey(sugg.Location)
@@ -36,12 +40,16 @@ func GroupActionableSuggestions(suggestions []ActionableSuggestion, structure *D
 		})
 
 		result = append(result, LocationGroupedSuggestions{
+			LocationID:  locationKey.ID(),
+			LocationKey: locationKey.String(),
 			Location:    locationMap[locationKey],
 			Suggestions: groupedSuggestions,
 		})
 	}
 
-	// Sort location groups by the first suggestion's position in each group
+	// Sort location groups by the first suggestion's position in each group.
+	// Segments such as headers, footers and footnotes have their own index space
+	// and come after the body.
 	sort.Slice(result, func(i, j int) bool {
 		if len(result[i].Suggestions) == 0 {
 			return false
@@ -49,12 +57,94 @@ func GroupActionableSuggestions(suggestions []ActionableSuggestion, structure *D
 		if len(result[j].Suggestions) == 0 {
 			return true
 		}
+		if oi, oj := sectionOrder[result[i].Location.Section], sectionOrder[result[j].Location.Section]; oi != oj {
+			return oi < oj
+		}
+		if result[i].Location.SegmentID != result[j].Location.SegmentID {
+			return result[i].Location.SegmentID < result[j].Location.SegmentID
+		}
 		return result[i].Suggestions[0].Position.StartIndex < result[j].Suggestions[0].Position.StartIndex
 	})
 
+	return detectMoves(result)
+}
+
+// detectMoves turns a deletion and an insertion of the same text with the same
+// suggestion ID, in different places, into a single "move" change at the
+// insertion, dropping location groups left empty.
+func detectMoves(groups []LocationGroupedSuggestions) []LocationGroupedSuggestions {
+	type ref struct{ group, index int }
+	deletions := make(map[string][]ref)
+	insertions := make(map[string][]ref)
+	for i, group := range groups {
+		for j, sugg := range group.Suggestions {
+			switch sugg.Change.Type {
+			case "delete":
+				deletions[sugg.ID] = append(deletions[sugg.ID], ref{i, j})
+			case "insert":
+				insertions[sugg.ID] = append(insertions[sugg.ID], ref{i, j})
+			}
+		}
+	}
+
+	moved := make(map[ref]bool)
+	for id, dels := range deletions {
+		ins := insertions[id]
+		if len(dels) != 1 || len(ins) != 1 {
+			continue
+		}
+		from := groups[dels[0].group].Suggestions[dels[0].index]
+		to := &groups[ins[0].group].Suggestions[ins[0].index]
+		text := strings.TrimSpace(from.Change.OriginalText)
+		if text == "" || text != strings.TrimSpace(to.Change.NewText) {
+			continue
+		}
+
+		to.Change = SuggestionChange{
+			Type:            "move",
+			OriginalText:    from.Change.OriginalText,
+			NewText:         to.Change.NewText,
+			OriginalLinkURL: from.Change.OriginalLinkURL,
+			NewLinkURL:      to.Change.NewLinkURL,
+			NamedStyle:      to.Change.NamedStyle,
+			Paragraph:       from.Change.Paragraph && to.Change.Paragraph,
+		}
+		to.MovedFrom = &MoveSource{
+			Location: groups[dels[0].group].Location,
+			Anchor:   from.Anchor,
+		}
+		to.AtomicChanges = append(append([]SuggestionChange{}, from.AtomicChanges...), to.AtomicChanges...)
+		to.AtomicCount += from.AtomicCount
+		moved[dels[0]] = true
+	}
+	if len(moved) == 0 {
+		return groups
+	}
+
+	var result []LocationGroupedSuggestions
+	for i, group := range groups {
+		var suggestions []GroupedActionableSuggestion
+		for j, sugg := range group.Suggestions {
+			if !moved[ref{i, j}] {
+				suggestions = append(suggestions, sugg)
+			}
+		}
+		if len(suggestions) > 0 {
+			group.Suggestions = suggestions
+			result = append(result, group)
+		}
+	}
 	return result
 }
 
+// sectionOrder is the order location groups of each section appear in
+var sectionOrder = map[string]int{
+	"Body":     0,
+	"Header":   1,
+	"Footer":   2,
+	"Footnote": 3,
+}
+
 // groupSuggestionsByID groups suggestions by their ID and merges contiguous atomic operations.
 // Suggestions with the same ID that are contiguous in position are merged into a single
 // GroupedActionableSuggestion. Non-contiguous suggestions with the same ID are kept separate.
@@ -99,27 +189,97 @@ func groupSuggestionsByID(suggestions []ActionableSuggestion, structure *Documen
 	return grouped
 }
 
-// getLocationKey creates a unique key for a location to enable grouping.
-// Two locations are considered the same if they share the same section, heading, and table context.
-func getLocationKey(loc SuggestionLocation) string {
-	key := loc.Section
+// locationKey identifies a location for grouping. Two locations are the same
+// if they share the same section, heading, table and list context.
+type locationKey struct {
+	Section      string
+	SegmentID    string
+	Heading      string
+	HeadingLevel int
+	TableID      string
+	TableTitle   string
+	InMetadata   bool
+	Metadata     string
+	ListID       string
+	ListLevel    int
+	ListItem     int
+	PageURL      string
+	Tab          string
+}
 
+// getLocationKey returns the key of a location.
+func getLocationKey(loc SuggestionLocation) locationKey {
+	key := locationKey{
+		Section:    loc.Section,
+		SegmentID:  loc.SegmentID,
+		InMetadata: loc.InMetadata,
+		Metadata:   loc.MetadataTable,
+		PageURL:    loc.PageURL,
+		Tab:        loc.Tab,
+	}
 	if loc.ParentHeading != "" {
-		key += "|heading:" + loc.ParentHeading + "|level:" + string(rune(loc.HeadingLevel))
+		key.Heading = loc.ParentHeading
+		key.HeadingLevel = loc.HeadingLevel
 	}
-
 	if loc.InTable && loc.Table != nil {
-		key += "|table:" + loc.Table.TableID
-		if loc.Table.TableTitle != "" {
-			key += "|title:" + loc.Table.TableTitle
+		key.TableID = loc.Table.TableID
+		key.TableTitle = loc.Table.TableTitle
+	}
+	if loc.List != nil {
+		key.ListID = loc.List.ListID
+		key.ListLevel = loc.List.NestingLevel
+		key.ListItem = loc.List.Ordinal
+	}
+	return key
+}
+
+// String renders the key for debugging, e.g. "Body|heading:Pricing|level:2".
+// Text fields are quoted when needed, so different keys never render the same.
+func (k locationKey) String() string {
+	var b strings.Builder
+	b.WriteString(quoteKeyField(k.Section))
+	if k.Tab != "" {
+		b.WriteString("|tab:" + quoteKeyField(k.Tab))
+	}
+	if k.SegmentID != "" {
+		b.WriteString("|segment:" + quoteKeyField(k.SegmentID))
+	}
+	if k.Heading != "" {
+		fmt.Fprintf(&b, "|heading:%s|level:%d", quoteKeyField(k.Heading), k.HeadingLevel)
+	}
+	if k.TableID != "" {
+		b.WriteString("|table:" + quoteKeyField(k.TableID))
+		if k.TableTitle != "" {
+			b.WriteString("|title:" + quoteKeyField(k.TableTitle))
 		}
 	}
+	if k.InMetadata {
+		b.WriteString("|metadata:true")
+		if k.Metadata != "" {
+			b.WriteString("|metadata_table:" + quoteKeyField(k.Metadata))
+		}
+	}
+	if k.ListID != "" {
+		fmt.Fprintf(&b, "|list:%s|level:%d|item:%d", quoteKeyField(k.ListID), k.ListLevel, k.ListItem)
+	}
+	if k.PageURL != "" {
+		b.WriteString("|page:" + quoteKeyField(k.PageURL))
+	}
+	return b.String()
+}
 
-	if loc.InMetadata {
-		key += "|metadata:true"
+// quoteKeyField quotes a key field that contains separators or quotes.
+func quoteKeyField(s string) string {
+	if strings.ContainsAny(s, `|:"`) || strconv.Quote(s) != `"`+s+`"` {
+		return strconv.Quote(s)
 	}
+	return s
+}
 
-	return key
+// ID returns a short stable hash of the key, e.g. "loc-3f2a9c1b7d4e".
+func (k locationKey) ID() string {
+	sum := sha256.Sum256([]byte(k.String()))
+	return "loc-" + hex.EncodeToString(sum[:6])
 }
 
 // areContiguous checks if suggestions are adjacent or overlapping in position.
@@ -161,8 +321,9 @@ func convertSingleSuggestion(sugg ActionableSuggestion) GroupedActionableSuggest
 			StartIndex: sugg.Position.StartIndex,
 			EndIndex:   sugg.Position.EndIndex,
 		},
-		AtomicChanges: []SuggestionChange{sugg.Change},
-		AtomicCount:   1,
+		AtomicChanges:    []SuggestionChange{sugg.Change},
+		AtomicCount:      1,
+		SuggestionAuthor: sugg.SuggestionAuthor,
 	}
 }
 
@@ -176,8 +337,7 @@ func mergeSuggestions(id string, suggestions []ActionableSuggestion, structure *
 	last := suggestions[len(suggestions)-1]
 
 	// Extract anchors with increased length (120 chars) for better context
-	const groupedAnchorLength = 120
-	precedingText, followingText := getTextAround(structure, first.Position.StartIndex, last.Position.EndIndex, groupedAnchorLength)
+	precedingText, followingText := getTextAround(structure.segmentStructure(first.Location.SegmentID), first.Position.StartIndex, last.Position.EndIndex, DefaultGroupedAnchorLength)
 
 	// Collect atomic changes
 	atomicChanges := make([]SuggestionChange, len(suggestions))
@@ -221,31 +381,69 @@ func mergeSuggestions(id string, suggestions []ActionableSuggestion, structure *
 			StartIndex: first.Position.StartIndex,
 			EndIndex:   last.Position.EndIndex,
 		},
-		AtomicChanges: atomicChanges,
-		AtomicCount:   len(suggestions),
+		AtomicChanges:    atomicChanges,
+		AtomicCount:      len(suggestions),
+		SuggestionAuthor: first.SuggestionAuthor,
 	}
 }
 
 // mergeChanges combines multiple atomic changes into a single net change.
 // Handles sequences like: insert "Build " + delete "Y" + insert "y" -> replace "Y" with "Build y"
 func mergeChanges(suggestions []ActionableSuggestion) SuggestionChange {
+	// Several rows or columns suggested together keep their table change type
+	if first := suggestions[0].Change.Type; strings.HasPrefix(first, "table_") {
+		var originalParts, newParts []string
+		for _, sugg := range suggestions {
+			if sugg.Change.Type != first {
+				break
+			}
+			originalParts = append(originalParts, sugg.Change.OriginalText)
+			newParts = append(newParts, sugg.Change.NewText)
+		}
+		if len(newParts) == len(suggestions) {
+			return SuggestionChange{
+				Type:         first,
+				OriginalText: strings.TrimSpace(strings.Join(originalParts, "\n")),
+				NewText:      strings.TrimSpace(strings.Join(newParts, "\n")),
+			}
+		}
+	}
+
 	var originalParts []string
 	var newParts []string
 	hasInsertions := false
 	hasDeletions := false
+	var style *StyleDelta
+	var namedStyle *StyleProperty
+	var originalLink, newLink string
+	paragraph := false
 
 	// Process each atomic change in order
 	for _, sugg := range suggestions {
+		if namedStyle == nil {
+			namedStyle = sugg.Change.NamedStyle
+		}
+		paragraph = paragraph || sugg.Change.Paragraph
+
 		switch sugg.Change.Type {
 		case "insert":
 			hasInsertions = true
 			newParts = append(newParts, sugg.Change.NewText)
+			if newLink == "" {
+				newLink = sugg.Change.NewLinkURL
+			}
 		case "delete":
 			hasDeletions = true
 			originalParts = append(originalParts, sugg.Change.OriginalText)
-		case "style":
+			if originalLink == "" {
+				originalLink = sugg.Change.OriginalLinkURL
+			}
+		case "style", "paragraph":
 			// Style changes don't affect text content
 			// Keep the text in both original and new
+			if style == nil {
+				style = sugg.Change.Style
+			}
 			if sugg.Change.OriginalText != "" {
 				originalParts = append(originalParts, sugg.Change.OriginalText)
 				newParts = append(newParts, sugg.Change.OriginalText)
@@ -264,11 +462,19 @@ func mergeChanges(suggestions []ActionableSuggestion) SuggestionChange {
 		changeType = "delete"
 	} else if !hasDeletions && !hasInsertions {
 		changeType = "style"
+		if style == nil && namedStyle != nil {
+			changeType = "paragraph"
+		}
 	}
 
 	return SuggestionChange{
-		Type:         changeType,
-		OriginalText: originalText,
-		NewText:      newText,
+		Type:            changeType,
+		OriginalText:    originalText,
+		NewText:         newText,
+		OriginalLinkURL: originalLink,
+		NewLinkURL:      newLink,
+		NamedStyle:      namedStyle,
+		Paragraph:       paragraph,
+		Style:           style,
 	}
 }
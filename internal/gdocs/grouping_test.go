@@ -774,6 +774,70 @@ func TestGroupActionableSuggestions_DifferentLocations(t *testing.T) {
 	}
 }
 
+// TestGroupActionableSuggestions_Move tests that a deletion and an insertion of the
+// same text with the same ID, under different headings, become a single move
+func TestGroupActionableSuggestions_Move(t *testing.T) {
+	structure := &DocumentStructure{
+		TextElements: []TextElementWithPosition{
+			{ID: "text-1", Text: "Intro. Ubuntu is free. More intro.", StartIndex: 0, EndIndex: 34},
+			{ID: "text-2", Text: "Pricing details.", StartIndex: 100, EndIndex: 116},
+		},
+	}
+
+	at := func(start, end int64) struct {
+		StartIndex int64 `json:"start_index"`
+		EndIndex   int64 `json:"end_index"`
+	} {
+		return struct {
+			StartIndex int64 `json:"start_index"`
+			EndIndex   int64 `json:"end_index"`
+		}{StartIndex: start, EndIndex: end}
+	}
+	suggestions := []ActionableSuggestion{
+		{
+			ID:       "suggest.move",
+			Change:   SuggestionChange{Type: "delete", OriginalText: "Ubuntu is free. "},
+			Anchor:   SuggestionAnchor{PrecedingText: "Intro. ", FollowingText: "More intro."},
+			Location: SuggestionLocation{Section: "Body", ParentHeading: "Overview", HeadingLevel: 2},
+			Position: at(7, 23),
+		},
+		{
+			ID:       "suggest.move",
+			Change:   SuggestionChange{Type: "insert", NewText: " Ubuntu is free."},
+			Location: SuggestionLocation{Section: "Body", ParentHeading: "Pricing", HeadingLevel: 2},
+			Position: at(116, 116),
+		},
+		{
+			ID:       "suggest.other",
+			Change:   SuggestionChange{Type: "delete", OriginalText: "details"},
+			Location: SuggestionLocation{Section: "Body", ParentHeading: "Pricing", HeadingLevel: 2},
+			Position: at(108, 115),
+		},
+	}
+
+	result := GroupActionableSuggestions(suggestions, structure)
+
+	// The Overview group only held the deletion, so it's dropped
+	if len(result) != 1 || result[0].Location.ParentHeading != "Pricing" {
+		t.Fatalf("Expected only the Pricing location, got %+v", result)
+	}
+	var move *GroupedActionableSuggestion
+	for i := range result[0].Suggestions {
+		if result[0].Suggestions[i].ID == "suggest.move" {
+			move = &result[0].Suggestions[i]
+		}
+	}
+	if move == nil || move.Change.Type != "move" {
+		t.Fatalf("Expected a move change, got %+v", result[0].Suggestions)
+	}
+	if move.MovedFrom == nil || move.MovedFrom.Location.ParentHeading != "Overview" || move.MovedFrom.Anchor.PrecedingText != "Intro. " {
+		t.Errorf("Expected the move to come from Overview, got %+v", move.MovedFrom)
+	}
+	if move.AtomicCount != 2 {
+		t.Errorf("Expected 2 atomic changes, got %d", move.AtomicCount)
+	}
+}
+
 // TestGroupSuggestionsByID_EmptyInput tests handling of empty input
 func TestGroupSuggestionsByID_EmptyInput(t *testing.T) {
 	structure := &DocumentStructure{
@@ -1386,3 +1450,24 @@ func TestMergeChanges(t *testing.T) {
 func containsText(text, substr string) bool {
 	return len(text) > 0 && len(substr) > 0 && (text == substr || strings.Contains(text, substr))
 }
+
+func TestGetLocationKey(t *testing.T) {
+	plain := SuggestionLocation{Section: "Body", ParentHeading: "Pricing", HeadingLevel: 2}
+	key := getLocationKey(plain)
+	if got := key.String(); got != "Body|heading:Pricing|level:2" {
+		t.Errorf("Unexpected key: %q", got)
+	}
+	if key.ID() != getLocationKey(plain).ID() || len(key.ID()) != len("loc-")+12 {
+		t.Errorf("Expected a stable 12 digit ID, got %q", key.ID())
+	}
+
+	// A heading containing separators must not render like another key
+	tricky := SuggestionLocation{Section: "Body", ParentHeading: "Pricing|level:2|table:t1", HeadingLevel: 3}
+	table := SuggestionLocation{Section: "Body", ParentHeading: "Pricing", HeadingLevel: 2, InTable: true, Table: &TableLocation{TableID: "t1"}}
+	if getLocationKey(tricky).String() == getLocationKey(table).String() {
+		t.Errorf("Keys collide: %q", getLocationKey(tricky).String())
+	}
+	if getLocationKey(tricky).ID() == getLocationKey(table).ID() {
+		t.Error("Location IDs collide")
+	}
+}
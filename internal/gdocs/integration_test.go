@@ -29,13 +29,17 @@ func TestFullExtractionIntegration(t *testing.T) {
 	suggestions := ExtractSuggestions(&doc)
 
 	// Step B: Extract Metadata
-	metadata := ExtractMetadataTable(&doc)
+	metadataTables := ExtractMetadataTables(&doc)
+	var metadata *MetadataTable
+	if len(metadataTables) > 0 {
+		metadata = &metadataTables[0]
+	}
 
 	// Step C: Build Document Structure
 	docStructure := BuildDocumentStructure(&doc)
 
 	// Step D: Build Actionable Suggestions
-	actionableSuggestions := BuildActionableSuggestions(suggestions, docStructure, metadata)
+	actionableSuggestions := BuildActionableSuggestions(suggestions, docStructure, metadataTables)
 
 	// Construct the result object
 	// Note: We are mocking comments as empty since the fixture is only for the Docs API response
@@ -137,9 +141,9 @@ func TestMetadataSuggestionsSurviveProcessingFlow(t *testing.T) {
 		t.Fatalf("Expected at least 2 suggestions, got %d", len(suggestions))
 	}
 
-	metadata := ExtractMetadataTable(doc)
-	if metadata == nil {
-		t.Fatal("Expected metadata to be extracted, got nil")
+	metadata := ExtractMetadataTables(doc)
+	if len(metadata) == 0 {
+		t.Fatal("Expected metadata to be extracted, got none")
 	}
 
 	docStructure := BuildDocumentStructure(doc)
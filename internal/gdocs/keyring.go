@@ -0,0 +1,75 @@
+package gdocs
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// The login is stored in the OS keyring through the platform's CLI: security
+// on macOS and secret-tool (libsecret) on Linux.
+const (
+	keyringService = "bauer"
+	keyringAccount = "google"
+)
+
+// KeyringAvailable reports whether logins can be stored in the OS keyring,
+// i.e. whether a supported keyring CLI is installed.
+func KeyringAvailable() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		_, err := exec.LookPath("security")
+		return err == nil
+	case "linux":
+		_, err := exec.LookPath("secret-tool")
+		return err == nil
+	default:
+		return false
+	}
+}
+
+func keyringSet(secret []byte) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "add-generic-password", "-U", "-s", keyringService, "-a", keyringAccount, "-w", string(secret))
+	case "linux":
+		cmd = exec.Command("secret-tool", "store", "--label=Bauer Google login", "service", keyringService, "account", keyringAccount)
+		cmd.Stdin = bytes.NewReader(secret)
+	default:
+		return fmt.Errorf("no keyring support on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func keyringGet() ([]byte, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", keyringAccount, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "account", keyringAccount)
+	default:
+		return nil, fmt.Errorf("no keyring support on %s", runtime.GOOS)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(out), nil
+}
+
+// keyringDelete removes the login; a login that isn't there is ignored.
+func keyringDelete() {
+	switch runtime.GOOS {
+	case "darwin":
+		exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", keyringAccount).Run()
+	case "linux":
+		exec.Command("secret-tool", "clear", "service", keyringService, "account", keyringAccount).Run()
+	}
+}
@@ -0,0 +1,132 @@
+package gdocs
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalization steps, as given to --normalize.
+const (
+	// NormalizeNFC composes characters to Unicode NFC, so "é" written as "e"
+	// and a combining accent matches the single character
+	NormalizeNFC = "nfc"
+	// NormalizeQuotes folds curly quotes and apostrophes to straight ones
+	NormalizeQuotes = "quotes"
+	// NormalizeWhitespace turns non-breaking spaces, tabs and line breaks
+	// into spaces and collapses runs of them
+	NormalizeWhitespace = "whitespace"
+	// NormalizeAll enables every step
+	NormalizeAll = "all"
+)
+
+// Normalization makes document text comparable with the text of rendered
+// HTML, which differs in whitespace, quotes and Unicode composition.
+type Normalization struct {
+	NFC        bool
+	Quotes     bool
+	Whitespace bool
+}
+
+// ParseNormalization parses normalization steps: nfc, quotes, whitespace or all.
+func ParseNormalization(steps []string) (Normalization, error) {
+	var n Normalization
+	for _, step := range steps {
+		switch strings.ToLower(strings.TrimSpace(step)) {
+		case NormalizeNFC:
+			n.NFC = true
+		case NormalizeQuotes:
+			n.Quotes = true
+		case NormalizeWhitespace:
+			n.Whitespace = true
+		case NormalizeAll:
+			n = Normalization{NFC: true, Quotes: true, Whitespace: true}
+		case "":
+		default:
+			return n, fmt.Errorf("unknown normalization %q (expected nfc, quotes, whitespace or all)", step)
+		}
+	}
+	return n, nil
+}
+
+// Enabled reports whether any step is enabled.
+func (n Normalization) Enabled() bool {
+	return n.NFC || n.Quotes || n.Whitespace
+}
+
+var quoteReplacer = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'", "′", "'",
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`, "″", `"`,
+	"«", `"`, "»", `"`,
+)
+
+// Apply normalizes text. Leading and trailing whitespace is collapsed but not
+// trimmed, since anchors are joined to the text around them.
+func (n Normalization) Apply(text string) string {
+	if n.NFC {
+		text = norm.NFC.String(text)
+	}
+	if n.Quotes {
+		text = quoteReplacer.Replace(text)
+	}
+	if n.Whitespace {
+		text = collapseWhitespace(text)
+	}
+	return text
+}
+
+// collapseWhitespace replaces each run of whitespace, including non-breaking
+// spaces and the vertical tabs Docs uses for line breaks, with one space.
+func collapseWhitespace(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	space := false
+	for _, r := range text {
+		if unicode.IsSpace(r) || r == ' ' || r == ' ' || r == ' ' {
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// NormalizeAnchors fills in the normalized anchors, original text and
+// verification texts of the suggestions in result, next to the raw ones.
+func NormalizeAnchors(result *ProcessingResult, n Normalization) {
+	if !n.Enabled() {
+		return
+	}
+	for i := range result.ActionableSuggestions {
+		sugg := &result.ActionableSuggestions[i]
+		n.normalizeSuggestion(&sugg.Anchor, &sugg.Change, &sugg.Verification)
+	}
+	for i := range result.GroupedSuggestions {
+		group := &result.GroupedSuggestions[i]
+		for j := range group.Suggestions {
+			sugg := &group.Suggestions[j]
+			n.normalizeSuggestion(&sugg.Anchor, &sugg.Change, &sugg.Verification)
+			if sugg.MovedFrom != nil {
+				n.normalizeAnchor(&sugg.MovedFrom.Anchor)
+			}
+		}
+	}
+}
+
+func (n Normalization) normalizeSuggestion(anchor *SuggestionAnchor, change *SuggestionChange, verification *SuggestionVerification) {
+	n.normalizeAnchor(anchor)
+	change.NormalizedOriginalText = n.Apply(change.OriginalText)
+	verification.NormalizedTextBeforeChange = n.Apply(verification.TextBeforeChange)
+	verification.NormalizedTextAfterChange = n.Apply(verification.TextAfterChange)
+}
+
+func (n Normalization) normalizeAnchor(anchor *SuggestionAnchor) {
+	anchor.NormalizedPrecedingText = n.Apply(anchor.PrecedingText)
+	anchor.NormalizedFollowingText = n.Apply(anchor.FollowingText)
+}
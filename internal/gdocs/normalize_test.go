@@ -0,0 +1,52 @@
+package gdocs
+
+import "testing"
+
+func TestNormalization(t *testing.T) {
+	text := "It’s  “café” now\v"
+	tests := []struct {
+		steps []string
+		want  string
+	}{
+		{nil, text},
+		{[]string{"quotes"}, "It's  \"café\" now\v"},
+		{[]string{"whitespace"}, "It’s “café” now "},
+		{[]string{"all"}, "It's \"café\" now "},
+	}
+	for _, tt := range tests {
+		n, err := ParseNormalization(tt.steps)
+		if err != nil {
+			t.Fatalf("ParseNormalization(%v) failed: %v", tt.steps, err)
+		}
+		if got := n.Apply(text); got != tt.want {
+			t.Errorf("%v: expected %q, got %q", tt.steps, tt.want, got)
+		}
+	}
+
+	if _, err := ParseNormalization([]string{"smart"}); err == nil {
+		t.Error("Expected an error for an unknown step")
+	}
+}
+
+func TestNormalizeAnchors(t *testing.T) {
+	sugg := ActionableSuggestion{
+		Anchor:       SuggestionAnchor{PrecedingText: "Ubuntu’s ", FollowingText: " desktop"},
+		Change:       SuggestionChange{Type: "replace", OriginalText: "“free”", NewText: "open"},
+		Verification: SuggestionVerification{TextBeforeChange: "Ubuntu’s “free” desktop", TextAfterChange: "Ubuntu’s open desktop"},
+	}
+	result := &ProcessingResult{ActionableSuggestions: []ActionableSuggestion{sugg}}
+
+	NormalizeAnchors(result, Normalization{Quotes: true, Whitespace: true})
+
+	got := result.ActionableSuggestions[0]
+	if got.Anchor.PrecedingText != "Ubuntu’s " || got.Anchor.NormalizedPrecedingText != "Ubuntu's " ||
+		got.Anchor.NormalizedFollowingText != " desktop" {
+		t.Errorf("Unexpected anchor: %+v", got.Anchor)
+	}
+	if got.Change.NormalizedOriginalText != `"free"` {
+		t.Errorf("Unexpected normalized original text: %q", got.Change.NormalizedOriginalText)
+	}
+	if got.Verification.NormalizedTextAfterChange != "Ubuntu's open desktop" {
+		t.Errorf("Unexpected verification: %+v", got.Verification)
+	}
+}
@@ -0,0 +1,217 @@
+package gdocs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// CredentialsUser uses the user's own Google account, authorized once with
+// `bauer auth login`, for documents that can't be shared with a service account.
+const CredentialsUser CredentialsMode = "user"
+
+// UserScopes are requested at login. They include edit access so the same
+// login works for `bauer resolve`, and Drive access to comment on documents.
+var UserScopes = []string{
+	"https://www.googleapis.com/auth/documents",
+	"https://www.googleapis.com/auth/drive",
+}
+
+// userCredentials is the stored login, in the "authorized_user" format used by
+// gcloud so it can be loaded with google.CredentialsFromJSON.
+type userCredentials struct {
+	Type         string `json:"type"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// DefaultTokenFile returns where the login is stored when the keyring isn't
+// used: <user config dir>/bauer/token.json
+func DefaultTokenFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user config directory: %w", err)
+	}
+	return filepath.Join(dir, "bauer", "token.json"), nil
+}
+
+// LoginOptions configures the OAuth consent flow.
+type LoginOptions struct {
+	// ClientSecretsPath is the OAuth client ("Desktop app") JSON downloaded from
+	// the Google Cloud console
+	ClientSecretsPath string
+
+	// UseKeyring stores the login in the OS keyring instead of DefaultTokenFile
+	UseKeyring bool
+
+	// OpenBrowser opens the consent page; the URL is always printed as well
+	OpenBrowser func(url string) error
+}
+
+// Login runs the OAuth user-consent flow: the consent page is opened in a
+// browser, the authorization code is received on a loopback redirect, and the
+// resulting refresh token is stored. It returns where the login was stored.
+func Login(ctx context.Context, opts LoginOptions) (string, error) {
+	secrets, err := os.ReadFile(opts.ClientSecretsPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read OAuth client file: %w", err)
+	}
+	config, err := google.ConfigFromJSON(secrets, UserScopes...)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse OAuth client file: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("failed to start OAuth callback listener: %w", err)
+	}
+	defer listener.Close()
+	config.RedirectURL = fmt.Sprintf("http://%s/callback", listener.Addr())
+
+	state, err := randomState()
+	if err != nil {
+		return "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+	// Forcing the consent screen makes Google return a refresh token every time
+	authURL := config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce, oauth2.S256ChallengeOption(verifier))
+
+	codes := make(chan string, 1)
+	errs := make(chan error, 1)
+	server := &http.Server{Handler: callbackHandler(state, codes, errs)}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Printf("Open this URL to authorize Bauer:\n\n%s\n\n", authURL)
+	if opts.OpenBrowser != nil {
+		if err := opts.OpenBrowser(authURL); err != nil {
+			fmt.Printf("Could not open a browser: %v\n", err)
+		}
+	}
+
+	var code string
+	select {
+	case code = <-codes:
+	case err := <-errs:
+		return "", err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if token.RefreshToken == "" {
+		return "", errors.New("google did not return a refresh token")
+	}
+
+	data, err := json.Marshal(userCredentials{
+		Type:         "authorized_user",
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		RefreshToken: token.RefreshToken,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode credentials: %w", err)
+	}
+
+	if opts.UseKeyring {
+		if err := keyringSet(data); err != nil {
+			return "", fmt.Errorf("failed to store credentials in the keyring: %w", err)
+		}
+		return "OS keyring", nil
+	}
+
+	path, err := DefaultTokenFile()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create token directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write token file: %w", err)
+	}
+	return path, nil
+}
+
+// callbackHandler receives the OAuth redirect and checks its state.
+func callbackHandler(state string, codes chan<- string, errs chan<- error) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("state") != state:
+			http.Error(w, "Invalid state", http.StatusBadRequest)
+			return
+		case query.Get("error") != "":
+			fmt.Fprintln(w, "Authorization failed. You can close this tab.")
+			errs <- fmt.Errorf("authorization failed: %s", query.Get("error"))
+		case query.Get("code") == "":
+			http.Error(w, "Missing authorization code", http.StatusBadRequest)
+			return
+		default:
+			fmt.Fprintln(w, "Bauer is authorized. You can close this tab.")
+			codes <- query.Get("code")
+		}
+	})
+	return mux
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate OAuth state: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Logout removes the stored login from the keyring and the token file.
+func Logout() error {
+	tokenFile, err := DefaultTokenFile()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(tokenFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove token file: %w", err)
+	}
+	if KeyringAvailable() {
+		keyringDelete()
+	}
+	return nil
+}
+
+// loadUserCredentials returns the stored login, from the keyring if it's
+// there and from DefaultTokenFile otherwise.
+func loadUserCredentials() ([]byte, error) {
+	if KeyringAvailable() {
+		if data, err := keyringGet(); err == nil && len(data) > 0 {
+			return data, nil
+		}
+	}
+
+	tokenFile, err := DefaultTokenFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(tokenFile)
+	if os.IsNotExist(err) {
+		return nil, errors.New("not logged in; run `bauer auth login` first")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	return data, nil
+}
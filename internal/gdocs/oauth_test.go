@@ -0,0 +1,49 @@
+package gdocs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCallbackHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantCode   string
+		wantErr    bool
+	}{
+		{name: "code", query: "?state=s1&code=abc", wantStatus: http.StatusOK, wantCode: "abc"},
+		{name: "wrong state", query: "?state=other&code=abc", wantStatus: http.StatusBadRequest},
+		{name: "denied", query: "?state=s1&error=access_denied", wantStatus: http.StatusOK, wantErr: true},
+		{name: "missing code", query: "?state=s1", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes := make(chan string, 1)
+			errs := make(chan error, 1)
+			rec := httptest.NewRecorder()
+			callbackHandler("s1", codes, errs).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/callback"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			select {
+			case code := <-codes:
+				if code != tt.wantCode {
+					t.Errorf("Expected code %q, got %q", tt.wantCode, code)
+				}
+			case err := <-errs:
+				if !tt.wantErr {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			default:
+				if tt.wantCode != "" || tt.wantErr {
+					t.Errorf("Expected a code or an error")
+				}
+			}
+		})
+	}
+}
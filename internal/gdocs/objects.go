@@ -0,0 +1,82 @@
+package gdocs
+
+import (
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// ObjectPlaceholder stands for each index of a non-text element (an image,
+// drawing, equation, ...) in the text of a document, so offsets into the
+// text follow the document indices.
+const ObjectPlaceholder = "\uFFFC"
+
+// objectElementAt returns the placeholder text element of a non-text
+// paragraph element, and false for text runs.
+func objectElementAt(doc *docs.Document, paraElem *docs.ParagraphElement) (TextElementWithPosition, bool) {
+	object := objectKind(doc, paraElem)
+	if object == "" || paraElem.EndIndex <= paraElem.StartIndex {
+		return TextElementWithPosition{}, false
+	}
+	return TextElementWithPosition{
+		Text:       strings.Repeat(ObjectPlaceholder, int(paraElem.EndIndex-paraElem.StartIndex)),
+		StartIndex: paraElem.StartIndex,
+		EndIndex:   paraElem.EndIndex,
+		Object:     object,
+	}, true
+}
+
+// objectKind names the non-text element of a paragraph element, or returns
+// "" for text runs.
+func objectKind(doc *docs.Document, paraElem *docs.ParagraphElement) string {
+	switch {
+	case paraElem.InlineObjectElement != nil:
+		if obj, ok := doc.InlineObjects[paraElem.InlineObjectElement.InlineObjectId]; ok &&
+			obj.InlineObjectProperties != nil && obj.InlineObjectProperties.EmbeddedObject != nil &&
+			obj.InlineObjectProperties.EmbeddedObject.EmbeddedDrawingProperties != nil {
+			return "drawing"
+		}
+		return "image"
+	case paraElem.Equation != nil:
+		return "equation"
+	case paraElem.FootnoteReference != nil:
+		return "footnote_reference"
+	case paraElem.HorizontalRule != nil:
+		return "horizontal_rule"
+	case paraElem.PageBreak != nil:
+		return "page_break"
+	case paraElem.ColumnBreak != nil:
+		return "column_break"
+	case paraElem.AutoText != nil:
+		return "auto_text"
+	case paraElem.Person != nil:
+		return "person"
+	case paraElem.RichLink != nil:
+		return "rich_link"
+	}
+	return ""
+}
+
+// nearObject returns the kind of the non-text element closest to a range,
+// when it's within distance indices of it and so cuts its anchors short.
+func nearObject(structure *DocumentStructure, startIndex, endIndex int64, distance int) string {
+	nearest, gap := "", int64(distance)
+	for _, elem := range structure.TextElements {
+		if elem.Object == "" {
+			continue
+		}
+		var d int64
+		switch {
+		case elem.EndIndex <= startIndex:
+			d = startIndex - elem.EndIndex
+		case elem.StartIndex >= endIndex:
+			d = elem.StartIndex - endIndex
+		default:
+			continue
+		}
+		if d < gap {
+			nearest, gap = elem.Object, d
+		}
+	}
+	return nearest
+}
@@ -0,0 +1,121 @@
+package gdocs
+
+import (
+	"math"
+	"regexp"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// pageURLPattern matches a page URL annotation, e.g. "Page URL: ubuntu.com/aws".
+var pageURLPattern = regexp.MustCompile(`(?i)^\s*(?:page\s+)?url\s*:\s*(\S+)\s*$`)
+
+// PageSection is a part of a document that covers its own page, marked by a
+// "Page URL:" paragraph under its heading. Documents that cover several pages
+// list a URL under each of them.
+type PageSection struct {
+	URL string `json:"url"`
+
+	// Heading is the heading the URL is listed under, if any
+	Heading string `json:"heading,omitempty"`
+
+	// StartIndex and EndIndex span the heading's section
+	StartIndex int64 `json:"start_index"`
+	EndIndex   int64 `json:"end_index"`
+}
+
+// ExtractPageSections finds the page URL annotations in the body of a
+// document. Each one applies to the section of the heading above it, up to
+// the next heading of the same or a higher level. Annotations before the
+// first heading apply to the whole document.
+func ExtractPageSections(doc *docs.Document, structure *DocumentStructure) []PageSection {
+	if doc.Body == nil {
+		return nil
+	}
+
+	var pages []PageSection
+	for _, elem := range doc.Body.Content {
+		if elem.Paragraph == nil {
+			continue
+		}
+		match := pageURLPattern.FindStringSubmatch(paragraphText(elem.Paragraph))
+		if match == nil {
+			continue
+		}
+
+		page := PageSection{URL: match[1], EndIndex: math.MaxInt64}
+		parent := -1
+		for i, heading := range structure.Headings {
+			if heading.StartIndex >= elem.StartIndex {
+				break
+			}
+			parent = i
+		}
+		if parent >= 0 {
+			heading := structure.Headings[parent]
+			page.Heading, page.StartIndex = heading.Text, heading.StartIndex
+			for _, next := range structure.Headings[parent+1:] {
+				if next.Level <= heading.Level {
+					page.EndIndex = next.StartIndex
+					break
+				}
+			}
+		}
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// findPageURL returns the URL of the innermost page section containing
+// position, or "" when the position is in none.
+func findPageURL(structure *DocumentStructure, position int64) string {
+	url := ""
+	start := int64(-1)
+	for _, page := range structure.Pages {
+		if position >= page.StartIndex && position < page.EndIndex && page.StartIndex > start {
+			url, start = page.URL, page.StartIndex
+		}
+	}
+	return url
+}
+
+// PageGroups are the location groups of one page.
+type PageGroups struct {
+	URL    string
+	Groups []LocationGroupedSuggestions
+}
+
+// GroupsByPage splits location groups by the page they apply to (see
+// PageURL), in order of first appearance, keeping the order of the groups.
+// There is always at least one page. The groups share their suggestions
+// with groups.
+func (r *ProcessingResult) GroupsByPage(groups []LocationGroupedSuggestions) []PageGroups {
+	var pages []PageGroups
+	index := make(map[string]int)
+	for _, group := range groups {
+		url := r.PageURL(group)
+		i, ok := index[url]
+		if !ok {
+			i = len(pages)
+			index[url] = i
+			pages = append(pages, PageGroups{URL: url})
+		}
+		pages[i].Groups = append(pages[i].Groups, group)
+	}
+	if len(pages) == 0 {
+		pages = append(pages, PageGroups{URL: r.PageURL(LocationGroupedSuggestions{})})
+	}
+	return pages
+}
+
+// PageURL returns the URL of the page a location group applies to: its page
+// section's URL, or the metadata table's.
+func (r *ProcessingResult) PageURL(group LocationGroupedSuggestions) string {
+	if group.Location.PageURL != "" {
+		return group.Location.PageURL
+	}
+	if r.Metadata != nil {
+		return r.Metadata.SuggestedUrl
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package gdocs
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/docs/v1"
+)
+
+func textElement(start, end int64, text string, insertionIDs ...string) *docs.StructuralElement {
+	return &docs.StructuralElement{
+		StartIndex: start,
+		EndIndex:   end,
+		Paragraph: &docs.Paragraph{
+			Elements: []*docs.ParagraphElement{
+				{StartIndex: start, EndIndex: end, TextRun: &docs.TextRun{Content: text, SuggestedInsertionIds: insertionIDs}},
+			},
+		},
+	}
+}
+
+func TestPageSections(t *testing.T) {
+	doc := &docs.Document{
+		DocumentId: "doc",
+		Body: &docs.Body{
+			Content: []*docs.StructuralElement{
+				headingElement(1, 5, "HEADING_1", "AWS"),
+				textElement(5, 30, "Page URL: ubuntu.com/aws\n"),
+				textElement(30, 40, "Run it "),
+				textElement(40, 44, "now ", "ins-aws"),
+				textElement(44, 50, "today\n"),
+				headingElement(50, 60, "HEADING_2", "Pricing"),
+				textElement(60, 70, "Free\n", "ins-pricing"),
+				headingElement(70, 77, "HEADING_1", "Azure"),
+				textElement(77, 100, "url: ubuntu.com/azure\n"),
+				textElement(100, 110, "Certified\n", "ins-azure"),
+			},
+		},
+	}
+
+	result := BuildProcessingResult(doc)
+
+	wantPages := []PageSection{
+		{URL: "ubuntu.com/aws", Heading: "AWS", StartIndex: 1, EndIndex: 70},
+		{URL: "ubuntu.com/azure", Heading: "Azure", StartIndex: 70, EndIndex: math.MaxInt64},
+	}
+	if diff := cmp.Diff(wantPages, result.Pages); diff != "" {
+		t.Errorf("Pages mismatch (-want +got):\n%s", diff)
+	}
+
+	got := make(map[string][]string)
+	for _, page := range result.GroupsByPage(result.GroupedSuggestions) {
+		for _, group := range page.Groups {
+			for _, sugg := range group.Suggestions {
+				got[page.URL] = append(got[page.URL], sugg.ID)
+			}
+		}
+	}
+	want := map[string][]string{
+		"ubuntu.com/aws":   {"ins-aws", "ins-pricing"},
+		"ubuntu.com/azure": {"ins-azure"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GroupsByPage() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/api/docs/v1"
 )
 
 // ProcessingResult contains all extracted data from a Google Doc.
@@ -11,48 +14,126 @@ type ProcessingResult struct {
 	DocumentTitle         string                       `json:"document_title"`
 	DocumentID            string                       `json:"document_id"`
 	Metadata              *MetadataTable               `json:"metadata,omitempty"`
+	MetadataTables        []MetadataTable              `json:"metadata_tables,omitempty"`
 	ActionableSuggestions []ActionableSuggestion       `json:"actionable_suggestions"`
 	GroupedSuggestions    []LocationGroupedSuggestions `json:"grouped_suggestions"`
 	Comments              []Comment                    `json:"comments"`
+
+	// Pages are the page sections of a document that covers several pages
+	Pages []PageSection `json:"pages,omitempty"`
+
+	// Sections holds whole-section content; only populated in page refresh mode
+	Sections []DocumentSection `json:"sections,omitempty"`
+
+	// ConflictReport lists the suggestions dropped by ResolveGroupedConflicts
+	ConflictReport *ConflictReport `json:"conflict_report,omitempty"`
+
+	// Stats summarises the suggestions, see ComputeSuggestionStats
+	Stats *SuggestionStats `json:"stats,omitempty"`
+
+	// Document is the raw API response the result was built from (not serialized)
+	Document *docs.Document `json:"-"`
+
+	// Structure is the document structure used to locate suggestions (not serialized)
+	Structure *DocumentStructure `json:"-"`
 }
 
 // ProcessDocument fetches a document and extracts all relevant information.
-// It orchestrates the fetching, extraction, and structuring of data.
+// It orchestrates the fetching, extraction, and structuring of data. The
+// document, its comments and its revision history are fetched concurrently.
 func (c *Client) ProcessDocument(ctx context.Context, docID string) (*ProcessingResult, error) {
 	slog.Info("Fetching document content...", slog.String("doc_id", docID))
 	fmt.Printf("Fetching document %s...\n", docID)
 
-	doc, err := c.FetchDocument(ctx, docID)
-	if err != nil {
-		slog.Error("Failed to fetch document", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to fetch document: %w", err)
-	}
-
-	slog.Info("Document fetched successfully",
-		slog.String("title", doc.Title),
-		slog.String("document_id", doc.DocumentId),
+	var (
+		result   *ProcessingResult
+		comments []Comment
+		author   *SuggestionAuthor
 	)
-	fmt.Printf("Successfully fetched document: %s\n", doc.Title)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		doc, err := c.FetchDocument(gctx, docID)
+		if err != nil {
+			slog.Error("Failed to fetch document", slog.String("error", err.Error()))
+			return fmt.Errorf("failed to fetch document: %w", err)
+		}
 
-	// Extract Suggestions
-	suggestions := ExtractSuggestions(doc)
-	slog.Info("Suggestions extracted", slog.Int("count", len(suggestions)))
+		slog.Info("Document fetched successfully",
+			slog.String("title", doc.Title),
+			slog.String("document_id", doc.DocumentId),
+		)
+		fmt.Printf("Successfully fetched document: %s\n", doc.Title)
+
+		result = BuildProcessingResult(doc)
+		return nil
+	})
+	// Comments are context only, so failing to fetch them isn't fatal
+	g.Go(func() error {
+		var err error
+		comments, err = c.FetchComments(gctx, docID)
+		if err != nil {
+			slog.Warn("Failed to fetch comments", slog.String("error", err.Error()))
+		}
+		return nil
+	})
+	// Credit the suggestions' author when the revision history makes it clear
+	g.Go(func() error {
+		var err error
+		author, err = c.FetchSuggestionAuthor(gctx, docID)
+		if err != nil {
+			slog.Warn("Failed to fetch suggestion authors", slog.String("error", err.Error()))
+			author = nil
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
 
-	// Extract Metadata
-	metadata := ExtractMetadataTable(doc)
-	if metadata != nil {
-		slog.Info("Metadata table extracted", slog.Int("field_count", len(metadata.Raw)))
+	// Place the comments next to the suggestions at the same location
+	AddComments(result, comments)
+
+	if author != nil {
+		authors := make(map[string]SuggestionAuthor)
+		for _, sugg := range result.ActionableSuggestions {
+			authors[sugg.ID] = *author
+		}
+		AddSuggestionAuthors(result, authors)
+		slog.Info("Suggestion author inferred from revisions", slog.String("author", author.Author))
 	}
 
-	// Build Document Structure
-	docStructure := BuildDocumentStructure(doc)
+	return result, nil
+}
+
+// BuildProcessingResult extracts suggestions, metadata and structure from a
+// fetched document, and groups the suggestions by location. Other document
+// sources convert their documents to the Docs API model to reuse it.
+func BuildProcessingResult(doc *docs.Document) *ProcessingResult {
+	// Extract Suggestions and build Document Structure in one pass
+	suggestions, docStructure := ExtractDocument(doc, DefaultExtractionWorkers)
+	slog.Info("Suggestions extracted", slog.Int("count", len(suggestions)))
 	slog.Info("Document structure built",
 		slog.Int("headings", len(docStructure.Headings)),
 		slog.Int("tables", len(docStructure.Tables)),
 	)
 
+	// Extract Metadata; the first table describes the page
+	metadataTables := ExtractMetadataTables(doc)
+	var metadata *MetadataTable
+	if len(metadataTables) > 0 {
+		metadata = &metadataTables[0]
+		slog.Info("Metadata tables extracted",
+			slog.Int("count", len(metadataTables)),
+			slog.Int("field_count", len(metadata.Raw)),
+		)
+	}
+	docStructure.Pages = ExtractPageSections(doc, docStructure)
+	if len(docStructure.Pages) > 0 {
+		slog.Info("Page sections extracted", slog.Int("page_count", len(docStructure.Pages)))
+	}
+
 	// Build Actionable Suggestions
-	actionableSuggestions := BuildActionableSuggestions(suggestions, docStructure, metadata)
+	actionableSuggestions := BuildActionableSuggestions(suggestions, docStructure, metadataTables)
 	slog.Info("Extracted actionable suggestions", slog.Int("field_count", len(actionableSuggestions)))
 
 	// Group Actionable Suggestions
@@ -63,8 +144,52 @@ func (c *Client) ProcessDocument(ctx context.Context, docID string) (*Processing
 		DocumentTitle:         doc.Title,
 		DocumentID:            doc.DocumentId,
 		Metadata:              metadata,
+		MetadataTables:        metadataTables,
+		Pages:                 docStructure.Pages,
 		ActionableSuggestions: actionableSuggestions,
 		GroupedSuggestions:    groupedSuggestions,
-		Comments:              nil,
-	}, nil
+		Document:              doc,
+		Structure:             docStructure,
+	}
+}
+
+// AddComments anchors comments in the document and attaches the unresolved
+// ones to the location groups they belong to.
+func AddComments(result *ProcessingResult, comments []Comment) {
+	resolved := ResolveCommentAnchors(comments, result.Structure, result.MetadataTables)
+	AttachComments(result.GroupedSuggestions, UnresolvedComments(comments))
+	result.Comments = comments
+	slog.Info("Comments fetched",
+		slog.Int("count", len(comments)),
+		slog.Int("anchored", resolved),
+	)
+}
+
+// FetchSuggestionIDs fetches a document and returns its revision ID and the
+// sorted IDs of its open suggestions. It's a cheaper check than ProcessDocument
+// for detecting whether a document has new suggestions.
+func (c *Client) FetchSuggestionIDs(ctx context.Context, docID string) (string, []string, error) {
+	doc, err := c.FetchDocument(ctx, docID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to fetch document: %w", err)
+	}
+	return doc.RevisionId, SuggestionIDs(ExtractSuggestions(doc)), nil
+}
+
+// SuggestionIDs returns the sorted, unique IDs of suggestions.
+func SuggestionIDs(suggestions []Suggestion) []string {
+	seen := make(map[string]bool)
+	for _, sugg := range suggestions {
+		seen[sugg.ID] = true
+	}
+	return sortedKeys(seen)
+}
+
+// FetchSuggestions fetches a document and returns its suggestions.
+func (c *Client) FetchSuggestions(ctx context.Context, docID string) ([]Suggestion, error) {
+	doc, err := c.FetchDocument(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch document: %w", err)
+	}
+	return ExtractSuggestions(doc), nil
 }
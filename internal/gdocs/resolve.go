@@ -0,0 +1,117 @@
+package gdocs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// The Docs API has no request to accept or reject a suggestion, but edits made
+// through the API are applied directly rather than as suggestions. Accepting or
+// rejecting is therefore done by deleting the suggested runs and, where the text
+// has to stay, re-inserting it as regular content. Re-inserted text takes the
+// style of the surrounding text.
+
+// AcceptSuggestion applies a suggestion to the document, as if accepted in the editor.
+// Requires a client created with NewWriteClient.
+func (c *Client) AcceptSuggestion(ctx context.Context, docID, suggestionID string) error {
+	return c.resolveSuggestion(ctx, docID, suggestionID, true)
+}
+
+// RejectSuggestion discards a suggestion, as if rejected in the editor.
+// Requires a client created with NewWriteClient.
+func (c *Client) RejectSuggestion(ctx context.Context, docID, suggestionID string) error {
+	return c.resolveSuggestion(ctx, docID, suggestionID, false)
+}
+
+func (c *Client) resolveSuggestion(ctx context.Context, docID, suggestionID string, accept bool) error {
+	doc, err := c.FetchDocument(ctx, docID)
+	if err != nil {
+		return err
+	}
+
+	requests, err := BuildResolveRequests(doc, suggestionID, accept)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Docs.Documents.BatchUpdate(docID, &docs.BatchUpdateDocumentRequest{
+		Requests: requests,
+		WriteControl: &docs.WriteControl{
+			// Fail rather than edit the wrong text if the document changed since it was fetched
+			RequiredRevisionId: doc.RevisionId,
+		},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to resolve suggestion %s: %w", suggestionID, err)
+	}
+
+	return nil
+}
+
+// BuildResolveRequests builds the batchUpdate requests that accept (or reject) a
+// suggestion in the document body. Requests are ordered from the end of the
+// document backwards so earlier indices stay valid as runs are edited.
+func BuildResolveRequests(doc *docs.Document, suggestionID string, accept bool) ([]*docs.Request, error) {
+	var bodySuggestions []Suggestion
+	if doc.Body != nil {
+		for _, elem := range doc.Body.Content {
+			processStructuralElement(elem, &bodySuggestions)
+		}
+	}
+
+	var runs []Suggestion
+	for _, sugg := range bodySuggestions {
+		if sugg.ID != suggestionID {
+			continue
+		}
+		if sugg.Type == "text_style_change" {
+			return nil, fmt.Errorf("suggestion %s is a style change, which can't be resolved through the API", suggestionID)
+		}
+		runs = append(runs, sugg)
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("suggestion %s not found in document body", suggestionID)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartIndex > runs[j].StartIndex
+	})
+
+	// Indices of flattened tabs are written back relative to their tab
+	tabs := tabRanges(doc)
+	var requests []*docs.Request
+	for _, run := range runs {
+		var tabID string
+		if tab := tabAt(tabs, run.StartIndex); tab != nil {
+			tabID = tab.ID
+			run.StartIndex -= tab.StartIndex
+			run.EndIndex -= tab.StartIndex
+		}
+
+		// Accepted insertions and rejected deletions keep their text
+		keepText := (run.Type == "insertion") == accept
+		if keepText && run.Element != "" {
+			return nil, fmt.Errorf("suggestion %s changes a %s, which can't be re-inserted through the API", suggestionID, strings.ReplaceAll(run.Element, "_", " "))
+		}
+
+		requests = append(requests, &docs.Request{
+			DeleteContentRange: &docs.DeleteContentRangeRequest{
+				Range: &docs.Range{StartIndex: run.StartIndex, EndIndex: run.EndIndex, TabId: tabID},
+			},
+		})
+		if keepText {
+			requests = append(requests, &docs.Request{
+				InsertText: &docs.InsertTextRequest{
+					Location: &docs.Location{Index: run.StartIndex, TabId: tabID},
+					Text:     run.Content,
+				},
+			})
+		}
+	}
+
+	return requests, nil
+}
@@ -0,0 +1,78 @@
+package gdocs
+
+import (
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+func buildResolveTestDocument() *docs.Document {
+	return &docs.Document{
+		Body: &docs.Body{
+			Content: []*docs.StructuralElement{
+				{
+					StartIndex: 1,
+					EndIndex:   30,
+					Paragraph: &docs.Paragraph{
+						Elements: []*docs.ParagraphElement{
+							{StartIndex: 1, EndIndex: 10, TextRun: &docs.TextRun{Content: "Ubuntu is "}},
+							{StartIndex: 10, EndIndex: 14, TextRun: &docs.TextRun{Content: "old ", SuggestedDeletionIds: []string{"s1"}}},
+							{StartIndex: 14, EndIndex: 18, TextRun: &docs.TextRun{Content: "new ", SuggestedInsertionIds: []string{"s1"}}},
+							{StartIndex: 18, EndIndex: 30, TextRun: &docs.TextRun{Content: "and fast.\n", SuggestedTextStyleChanges: map[string]docs.SuggestedTextStyle{"s2": {}}}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildResolveRequests_Accept(t *testing.T) {
+	requests, err := BuildResolveRequests(buildResolveTestDocument(), "s1", true)
+	if err != nil {
+		t.Fatalf("BuildResolveRequests() failed: %v", err)
+	}
+
+	// Insertion run first (highest index): delete, then re-insert as plain text.
+	// Deletion run second: delete only.
+	if len(requests) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(requests))
+	}
+	if r := requests[0].DeleteContentRange; r == nil || r.Range.StartIndex != 14 || r.Range.EndIndex != 18 {
+		t.Errorf("Expected first request to delete the suggested insertion, got %+v", requests[0])
+	}
+	if r := requests[1].InsertText; r == nil || r.Location.Index != 14 || r.Text != "new " {
+		t.Errorf("Expected second request to re-insert the accepted text, got %+v", requests[1])
+	}
+	if r := requests[2].DeleteContentRange; r == nil || r.Range.StartIndex != 10 || r.Range.EndIndex != 14 {
+		t.Errorf("Expected third request to delete the suggested deletion, got %+v", requests[2])
+	}
+}
+
+func TestBuildResolveRequests_Reject(t *testing.T) {
+	requests, err := BuildResolveRequests(buildResolveTestDocument(), "s1", false)
+	if err != nil {
+		t.Fatalf("BuildResolveRequests() failed: %v", err)
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(requests))
+	}
+	if requests[0].DeleteContentRange == nil || requests[1].DeleteContentRange == nil {
+		t.Errorf("Expected both runs to be deleted, got %+v, %+v", requests[0], requests[1])
+	}
+	if r := requests[2].InsertText; r == nil || r.Location.Index != 10 || r.Text != "old " {
+		t.Errorf("Expected the rejected deletion to be restored, got %+v", requests[2])
+	}
+}
+
+func TestBuildResolveRequests_Errors(t *testing.T) {
+	doc := buildResolveTestDocument()
+
+	if _, err := BuildResolveRequests(doc, "missing", true); err == nil {
+		t.Error("Expected error for unknown suggestion")
+	}
+	if _, err := BuildResolveRequests(doc, "s2", true); err == nil {
+		t.Error("Expected error for style-only suggestion")
+	}
+}
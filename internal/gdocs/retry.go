@@ -0,0 +1,215 @@
+package gdocs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the API after too many
+// consecutive requests have failed.
+var ErrCircuitOpen = errors.New("google API circuit breaker open after repeated failures")
+
+// RetryPolicy controls how Docs and Drive API calls are retried. Zero values use the defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts per request (default: 5)
+	MaxAttempts int
+
+	// BaseDelay is the first backoff, doubled on each attempt up to MaxDelay
+	// (defaults: 1s and 30s). A Retry-After header takes precedence.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// BreakerThreshold consecutive failed requests open the circuit for
+	// BreakerCooldown (defaults: 5 and 1m)
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 5
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = time.Second
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 30 * time.Second
+	}
+	if p.BreakerThreshold <= 0 {
+		p.BreakerThreshold = 5
+	}
+	if p.BreakerCooldown <= 0 {
+		p.BreakerCooldown = time.Minute
+	}
+	return p
+}
+
+// retryTransport retries rate-limited and failed requests with exponential
+// backoff, and stops calling the API while the circuit breaker is open.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+
+	// sleep waits between attempts; replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func newRetryTransport(base http.RoundTripper, policy RetryPolicy) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{base: base, policy: policy.withDefaults(), sleep: sleepContext}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.checkCircuit(); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.Body != nil {
+			// The body was consumed by the previous attempt
+			if req.GetBody == nil {
+				return nil, fmt.Errorf("cannot retry request to %s: body can't be replayed", req.URL.Host)
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to replay request body: %w", err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		if !retryable(resp, err) || req.Context().Err() != nil {
+			t.recordResult(err == nil && resp.StatusCode < 500)
+			return resp, err
+		}
+		if attempt >= t.policy.MaxAttempts {
+			t.recordResult(false)
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+		slog.Warn("Retrying Google API request",
+			slog.String("url", req.URL.Path),
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.String("reason", retryReason(resp, err)),
+		)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable reports whether a request failed transiently: a network error,
+// rate limiting (429, or 403 with a rate limit reason as Drive returns) or a server error.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusForbidden:
+		return rateLimited(resp)
+	}
+	return false
+}
+
+// rateLimited checks a 403 response for a rate limit reason. The body is
+// restored so callers can still read the error.
+func rateLimited(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(body, []byte("rateLimitExceeded")) || bytes.Contains(body, []byte("userRateLimitExceeded"))
+}
+
+func retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
+
+// backoff returns the delay before the next attempt: Retry-After when the
+// server sends it, exponential backoff with jitter otherwise.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if after := resp.Header.Get("Retry-After"); after != "" {
+			if seconds, err := strconv.Atoi(after); err == nil && seconds >= 0 {
+				return time.Duration(seconds) * time.Second
+			}
+			if at, err := http.ParseTime(after); err == nil {
+				return max(time.Until(at), 0)
+			}
+		}
+	}
+
+	delay := t.policy.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > t.policy.MaxDelay {
+		delay = t.policy.MaxDelay
+	}
+	// Up to 50% jitter so parallel requests don't retry in lockstep
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func (t *retryTransport) checkCircuit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if time.Now().Before(t.openUntil) {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+func (t *retryTransport) recordResult(ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ok {
+		t.failures = 0
+		return
+	}
+	t.failures++
+	if t.failures >= t.policy.BreakerThreshold {
+		t.openUntil = time.Now().Add(t.policy.BreakerCooldown)
+		t.failures = 0
+		slog.Error("Too many failed Google API requests, pausing calls",
+			slog.Duration("cooldown", t.policy.BreakerCooldown),
+		)
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
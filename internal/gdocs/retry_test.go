@@ -0,0 +1,123 @@
+package gdocs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []int
+		retryAfter   string
+		body         string
+		wantStatus   int
+		wantAttempts int
+		wantDelays   []time.Duration
+	}{
+		{name: "success", responses: []int{200}, wantStatus: 200, wantAttempts: 1},
+		{name: "retries server errors", responses: []int{503, 500, 200}, wantStatus: 200, wantAttempts: 3},
+		{name: "honors Retry-After", responses: []int{429, 200}, retryAfter: "7", wantStatus: 200, wantAttempts: 2, wantDelays: []time.Duration{7 * time.Second}},
+		{name: "rate limited 403", responses: []int{403, 200}, body: `{"error":{"errors":[{"reason":"userRateLimitExceeded"}]}}`, wantStatus: 200, wantAttempts: 2},
+		{name: "permission denied", responses: []int{403}, body: `{"error":{"errors":[{"reason":"forbidden"}]}}`, wantStatus: 403, wantAttempts: 1},
+		{name: "gives up", responses: []int{503, 503, 503}, wantStatus: 503, wantAttempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.responses[min(attempts, len(tt.responses)-1)]
+				attempts++
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			var delays []time.Duration
+			transport := newRetryTransport(http.DefaultTransport, RetryPolicy{MaxAttempts: 3})
+			transport.sleep = func(ctx context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if err != nil {
+				t.Fatalf("Get() failed: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus || attempts != tt.wantAttempts {
+				t.Errorf("Expected status %d after %d attempts, got %d after %d", tt.wantStatus, tt.wantAttempts, resp.StatusCode, attempts)
+			}
+			if tt.wantDelays != nil && (len(delays) != len(tt.wantDelays) || delays[0] != tt.wantDelays[0]) {
+				t.Errorf("Expected delays %v, got %v", tt.wantDelays, delays)
+			}
+			if resp.StatusCode == 403 && string(body) != tt.body {
+				t.Errorf("Expected the 403 body to be readable, got %q", body)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_CircuitBreaker(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	transport := newRetryTransport(http.DefaultTransport, RetryPolicy{MaxAttempts: 1, BreakerThreshold: 2})
+	client := &http.Client{Transport: transport}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	_, err := client.Get(server.URL)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected no request while the circuit is open, got %d attempts", attempts)
+	}
+}
+
+func TestRetryTransport_ReplaysBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	transport := newRetryTransport(http.DefaultTransport, RetryPolicy{})
+	transport.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	resp, err := (&http.Client{Transport: transport}).Post(server.URL, "application/json", strings.NewReader(`{"requests":[]}`))
+	if err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(bodies) != 2 || bodies[1] != `{"requests":[]}` {
+		t.Errorf("Expected the body to be sent twice, got %q", bodies)
+	}
+}
@@ -0,0 +1,162 @@
+package gdocs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Revision is a version of a document in its Drive history.
+type Revision struct {
+	ID           string
+	ModifiedTime time.Time
+
+	// KeepForever is set on the versions named in the Docs version history
+	KeepForever bool
+
+	// Author is who last modified the revision
+	Author SuggestionAuthor
+
+	// exportURL is where the revision is exported as HTML
+	exportURL string
+}
+
+// FetchRevisions returns the revisions of a document, oldest first.
+func (c *Client) FetchRevisions(ctx context.Context, docID string) ([]Revision, error) {
+	var revisions []Revision
+	pageToken := ""
+	for {
+		req := c.Drive.Revisions.List(docID).
+			Fields("nextPageToken, revisions(id, modifiedTime, keepForever, exportLinks, lastModifyingUser(displayName, emailAddress))").
+			PageSize(1000).
+			Context(ctx)
+		if pageToken != "" {
+			req = req.PageToken(pageToken)
+		}
+		resp, err := req.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch revisions: %w", err)
+		}
+		for _, rev := range resp.Revisions {
+			revision := Revision{
+				ID:          rev.Id,
+				KeepForever: rev.KeepForever,
+				exportURL:   rev.ExportLinks["text/html"],
+			}
+			if t, err := time.Parse(time.RFC3339, rev.ModifiedTime); err == nil {
+				revision.ModifiedTime = t
+			}
+			if user := rev.LastModifyingUser; user != nil {
+				revision.Author = SuggestionAuthor{Author: user.DisplayName, AuthorEmail: user.EmailAddress}
+			}
+			revisions = append(revisions, revision)
+		}
+
+		pageToken = resp.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("document %s has no revisions", docID)
+	}
+	return revisions, nil
+}
+
+// SelectRevision returns the index of the revision named by spec, of
+// revisions ordered oldest first:
+//   - "" or "head": the latest revision
+//   - "head~N": the Nth revision before the latest
+//   - "first": the oldest revision
+//   - "named": the latest version named in the version history
+//   - a date (YYYY-MM-DD) or RFC 3339 time: the revision current at that time
+//   - anything else: the revision of that ID
+func SelectRevision(revisions []Revision, spec string) (int, error) {
+	if len(revisions) == 0 {
+		return 0, fmt.Errorf("no revision matches %q", spec)
+	}
+	head := len(revisions) - 1
+
+	switch {
+	case spec == "" || spec == "head":
+		return head, nil
+	case spec == "first":
+		return 0, nil
+	case spec == "named":
+		for i := head; i >= 0; i-- {
+			if revisions[i].KeepForever {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("no named version in the version history")
+	case strings.HasPrefix(spec, "head~"):
+		n, err := strconv.Atoi(strings.TrimPrefix(spec, "head~"))
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid revision %q (expected head~<number>)", spec)
+		}
+		if n > head {
+			return 0, fmt.Errorf("revision %q is before the first revision", spec)
+		}
+		return head - n, nil
+	}
+
+	for i, rev := range revisions {
+		if rev.ID == spec {
+			return i, nil
+		}
+	}
+	if at, err := ParseSince(spec); err == nil {
+		for i := head; i >= 0; i-- {
+			if !revisions[i].ModifiedTime.After(at) {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("no revision at or before %s", spec)
+	}
+	return 0, fmt.Errorf("no revision %q", spec)
+}
+
+// ExportRevision returns a revision of a Google Doc as HTML.
+func (c *Client) ExportRevision(ctx context.Context, rev Revision) (string, error) {
+	if rev.exportURL == "" {
+		return "", fmt.Errorf("revision %s can't be exported as HTML", rev.ID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rev.exportURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to export revision %s: %w", rev.ID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to export revision %s: %s", rev.ID, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read revision %s: %w", rev.ID, err)
+	}
+	return string(body), nil
+}
+
+// RevisionsAuthor returns who made the revisions, when one person made them
+// all, or nil.
+func RevisionsAuthor(revisions []Revision) *SuggestionAuthor {
+	var author *SuggestionAuthor
+	for _, rev := range revisions {
+		if rev.Author.AuthorEmail == "" {
+			return nil
+		}
+		if author == nil {
+			author = &rev.Author
+		} else if author.AuthorEmail != rev.Author.AuthorEmail {
+			return nil
+		}
+	}
+	return author
+}
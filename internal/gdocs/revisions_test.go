@@ -0,0 +1,68 @@
+package gdocs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSelectRevision(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return parsed
+	}
+	revisions := []Revision{
+		{ID: "1", ModifiedTime: at("2024-05-01T09:00:00Z")},
+		{ID: "7", ModifiedTime: at("2024-05-02T09:00:00Z"), KeepForever: true},
+		{ID: "12", ModifiedTime: at("2024-05-03T09:00:00Z")},
+		{ID: "15", ModifiedTime: at("2024-05-04T09:00:00Z")},
+	}
+
+	tests := []struct {
+		spec    string
+		want    int
+		wantErr bool
+	}{
+		{spec: "", want: 3},
+		{spec: "head", want: 3},
+		{spec: "head~1", want: 2},
+		{spec: "head~4", wantErr: true},
+		{spec: "first", want: 0},
+		{spec: "named", want: 1},
+		{spec: "12", want: 2},
+		{spec: "2024-05-03", want: 1},
+		{spec: "2024-05-03T10:00:00Z", want: 2},
+		{spec: "2024-04-01", wantErr: true},
+		{spec: "99", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := SelectRevision(revisions, tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectRevision() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("SelectRevision() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := SelectRevision(revisions[2:], "named"); err == nil {
+		t.Error("Expected an error without a named version")
+	}
+}
+
+func TestRevisionsAuthor(t *testing.T) {
+	ana := SuggestionAuthor{Author: "Ana", AuthorEmail: "ana@example.com"}
+	ben := SuggestionAuthor{Author: "Ben", AuthorEmail: "ben@example.com"}
+
+	if got := RevisionsAuthor([]Revision{{Author: ana}, {Author: ana}}); got == nil || *got != ana {
+		t.Errorf("RevisionsAuthor() = %v, want %v", got, ana)
+	}
+	if got := RevisionsAuthor([]Revision{{Author: ana}, {Author: ben}}); got != nil {
+		t.Errorf("RevisionsAuthor() = %v, want nil", got)
+	}
+	if got := RevisionsAuthor([]Revision{{Author: ana}, {}}); got != nil {
+		t.Errorf("RevisionsAuthor() = %v, want nil for an anonymous editor", got)
+	}
+}
@@ -0,0 +1,182 @@
+package gdocs
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// DefaultSectionLevel is the deepest heading level that starts a new section.
+const DefaultSectionLevel = 2
+
+// DocumentSection is a heading-delimited part of the document rendered as Markdown.
+// Sections are used by page refresh mode, where whole sections are rebuilt
+// instead of applying anchored micro-edits.
+type DocumentSection struct {
+	ID           string `json:"id"`
+	Heading      string `json:"heading"`
+	HeadingLevel int    `json:"heading_level"`
+	StartIndex   int64  `json:"start_index"`
+	EndIndex     int64  `json:"end_index"`
+
+	// Markdown is the section content with all suggestions accepted
+	Markdown string `json:"markdown"`
+
+	// SuggestionIDs lists the suggestions that fall inside this section
+	SuggestionIDs []string `json:"suggestion_ids,omitempty"`
+}
+
+// ExtractSections splits the document body into sections at every heading of
+// level maxLevel or above, rendering each one as Markdown. Suggested insertions
+// are included and suggested deletions are dropped, so each section reflects
+// the document as it will read once all suggestions are accepted.
+// Metadata tables are skipped as they aren't page content.
+func ExtractSections(doc *docs.Document, metadata []MetadataTable, suggestions []ActionableSuggestion, maxLevel int) []DocumentSection {
+	sections := []DocumentSection{}
+	if doc.Body == nil || doc.Body.Content == nil {
+		return sections
+	}
+	if maxLevel <= 0 {
+		maxLevel = DefaultSectionLevel
+	}
+
+	var current *DocumentSection
+	var body strings.Builder
+
+	flush := func(endIndex int64) {
+		if current == nil {
+			return
+		}
+		current.EndIndex = endIndex
+		current.Markdown = strings.TrimSpace(body.String())
+		if current.Markdown != "" {
+			sections = append(sections, *current)
+		}
+		body.Reset()
+		current = nil
+	}
+
+	for _, elem := range doc.Body.Content {
+		if elem.Table != nil && metadataTableAt(metadata, elem.StartIndex, elem.EndIndex) != nil {
+			continue
+		}
+
+		if heading := extractHeading(elem, 0); heading != nil && heading.Level <= maxLevel {
+			flush(elem.StartIndex)
+			current = &DocumentSection{
+				Heading:      renderParagraphText(elem.Paragraph, false),
+				HeadingLevel: heading.Level,
+				StartIndex:   elem.StartIndex,
+			}
+		}
+
+		if current == nil {
+			current = &DocumentSection{StartIndex: elem.StartIndex}
+		}
+
+		if md := renderStructuralElement(elem); md != "" {
+			body.WriteString(md)
+			body.WriteString("\n\n")
+		}
+		current.EndIndex = elem.EndIndex
+	}
+	if current != nil {
+		flush(current.EndIndex)
+	}
+
+	for i := range sections {
+		sections[i].ID = fmt.Sprintf("section-%d", i+1)
+		seen := make(map[string]bool)
+		for _, sugg := range suggestions {
+			if sugg.Position.StartIndex >= sections[i].StartIndex && sugg.Position.StartIndex < sections[i].EndIndex && !seen[sugg.ID] {
+				seen[sugg.ID] = true
+				sections[i].SuggestionIDs = append(sections[i].SuggestionIDs, sugg.ID)
+			}
+		}
+	}
+
+	return sections
+}
+
+// renderStructuralElement renders a paragraph or table as Markdown.
+func renderStructuralElement(elem *docs.StructuralElement) string {
+	switch {
+	case elem.Paragraph != nil:
+		text := renderParagraphText(elem.Paragraph, true)
+		if text == "" {
+			return ""
+		}
+		if heading := extractHeading(elem, 0); heading != nil {
+			return strings.Repeat("#", heading.Level) + " " + text
+		}
+		if elem.Paragraph.Bullet != nil {
+			return strings.Repeat("  ", int(elem.Paragraph.Bullet.NestingLevel)) + "- " + text
+		}
+		return text
+
+	case elem.Table != nil:
+		return renderTable(elem.Table)
+	}
+	return ""
+}
+
+// renderTable renders a table as a Markdown table, using the first row as header.
+func renderTable(table *docs.Table) string {
+	var sb strings.Builder
+	for rowIdx, row := range table.TableRows {
+		cells := make([]string, 0, len(row.TableCells))
+		for _, cell := range row.TableCells {
+			var parts []string
+			for _, content := range cell.Content {
+				if content.Paragraph != nil {
+					if text := renderParagraphText(content.Paragraph, true); text != "" {
+						parts = append(parts, text)
+					}
+				}
+			}
+			cells = append(cells, strings.ReplaceAll(strings.Join(parts, "<br>"), "|", "\\|"))
+		}
+
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if rowIdx == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", len(cells)) + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// renderParagraphText returns the accepted text of a paragraph, optionally with
+// inline Markdown formatting (bold, italic, links).
+func renderParagraphText(para *docs.Paragraph, withFormatting bool) string {
+	var sb strings.Builder
+	for _, paraElem := range para.Elements {
+		if paraElem.TextRun == nil || len(paraElem.TextRun.SuggestedDeletionIds) > 0 {
+			continue
+		}
+
+		text := strings.ReplaceAll(paraElem.TextRun.Content, "\n", "")
+		if !withFormatting || strings.TrimSpace(text) == "" || paraElem.TextRun.TextStyle == nil {
+			sb.WriteString(text)
+			continue
+		}
+
+		// Keep surrounding whitespace outside the markers
+		trimmed := strings.TrimSpace(text)
+		lead := text[:strings.Index(text, trimmed)]
+		trail := text[len(lead)+len(trimmed):]
+
+		style := paraElem.TextRun.TextStyle
+		if style.Bold {
+			trimmed = "**" + trimmed + "**"
+		}
+		if style.Italic {
+			trimmed = "*" + trimmed + "*"
+		}
+		if style.Link != nil && style.Link.Url != "" {
+			trimmed = "[" + trimmed + "](" + style.Link.Url + ")"
+		}
+		sb.WriteString(lead + trimmed + trail)
+	}
+	return strings.TrimSpace(sb.String())
+}
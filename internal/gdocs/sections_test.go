@@ -0,0 +1,105 @@
+package gdocs
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+func headingElement(start, end int64, style, text string) *docs.StructuralElement {
+	return &docs.StructuralElement{
+		StartIndex: start,
+		EndIndex:   end,
+		Paragraph: &docs.Paragraph{
+			ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: style},
+			Elements: []*docs.ParagraphElement{
+				{StartIndex: start, EndIndex: end, TextRun: &docs.TextRun{Content: text + "\n"}},
+			},
+		},
+	}
+}
+
+func TestExtractSections(t *testing.T) {
+	doc := &docs.Document{
+		Body: &docs.Body{
+			Content: []*docs.StructuralElement{
+				{
+					StartIndex: 1,
+					EndIndex:   10,
+					Paragraph: &docs.Paragraph{
+						Elements: []*docs.ParagraphElement{
+							{StartIndex: 1, EndIndex: 10, TextRun: &docs.TextRun{Content: "Intro text\n"}},
+						},
+					},
+				},
+				headingElement(10, 20, "HEADING_1", "Features"),
+				{
+					StartIndex: 20,
+					EndIndex:   60,
+					Paragraph: &docs.Paragraph{
+						Elements: []*docs.ParagraphElement{
+							{StartIndex: 20, EndIndex: 25, TextRun: &docs.TextRun{Content: "Fast ", TextStyle: &docs.TextStyle{Bold: true}}},
+							{StartIndex: 25, EndIndex: 30, TextRun: &docs.TextRun{Content: "slow ", SuggestedDeletionIds: []string{"del-1"}}},
+							{StartIndex: 30, EndIndex: 40, TextRun: &docs.TextRun{Content: "docs", TextStyle: &docs.TextStyle{Link: &docs.Link{Url: "https://ubuntu.com/docs"}}}},
+							{StartIndex: 40, EndIndex: 60, TextRun: &docs.TextRun{Content: " and more\n", SuggestedInsertionIds: []string{"ins-1"}}},
+						},
+					},
+				},
+				{
+					StartIndex: 60,
+					EndIndex:   70,
+					Paragraph: &docs.Paragraph{
+						Bullet: &docs.Bullet{NestingLevel: 1},
+						Elements: []*docs.ParagraphElement{
+							{StartIndex: 60, EndIndex: 70, TextRun: &docs.TextRun{Content: "Nested\n"}},
+						},
+					},
+				},
+				headingElement(70, 80, "HEADING_3", "Details"),
+				headingElement(80, 90, "HEADING_2", "Pricing"),
+				{
+					StartIndex: 90,
+					EndIndex:   120,
+					Table: &docs.Table{
+						TableRows: []*docs.TableRow{
+							{TableCells: []*docs.TableCell{{Content: createContent("Plan")}, {Content: createContent("Price")}}},
+							{TableCells: []*docs.TableCell{{Content: createContent("Pro")}, {Content: createContent("Free")}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	suggestions := []ActionableSuggestion{{ID: "del-1"}, {ID: "ins-1"}}
+	suggestions[0].Position.StartIndex = 25
+	suggestions[1].Position.StartIndex = 40
+
+	sections := ExtractSections(doc, nil, suggestions, DefaultSectionLevel)
+
+	if len(sections) != 3 {
+		t.Fatalf("Expected 3 sections, got %d: %+v", len(sections), sections)
+	}
+
+	if sections[0].Heading != "" || sections[0].Markdown != "Intro text" {
+		t.Errorf("Unexpected intro section: %+v", sections[0])
+	}
+
+	features := sections[1]
+	if features.Heading != "Features" || features.HeadingLevel != 1 {
+		t.Errorf("Unexpected features heading: %q level %d", features.Heading, features.HeadingLevel)
+	}
+	wantMarkdown := "# Features\n\n**Fast** [docs](https://ubuntu.com/docs) and more\n\n  - Nested\n\n### Details"
+	if features.Markdown != wantMarkdown {
+		t.Errorf("Features markdown =\n%s\nwant\n%s", features.Markdown, wantMarkdown)
+	}
+	if strings.Join(features.SuggestionIDs, ",") != "del-1,ins-1" {
+		t.Errorf("Features suggestion IDs = %v", features.SuggestionIDs)
+	}
+
+	pricing := sections[2]
+	if pricing.ID != "section-3" || !strings.Contains(pricing.Markdown, "| Plan | Price |\n| --- | --- |\n| Pro | Free |") {
+		t.Errorf("Unexpected pricing section: %+v", pricing)
+	}
+}
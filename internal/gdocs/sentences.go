@@ -0,0 +1,146 @@
+package gdocs
+
+import "strings"
+
+// SuggestionIDs returns the ID of the suggestion followed by the IDs merged into it.
+func (s GroupedActionableSuggestion) SuggestionIDs() []string {
+	return append([]string{s.ID}, s.MergedIDs...)
+}
+
+// sentenceMergeable reports whether a suggestion only changes text, so it
+// can be combined with its neighbours into one replacement.
+func sentenceMergeable(sugg GroupedActionableSuggestion) bool {
+	if sugg.MovedFrom != nil || sugg.Change.Paragraph {
+		return false
+	}
+	switch sugg.Change.Type {
+	case "insert", "delete", "replace":
+		return true
+	}
+	return false
+}
+
+// MergeSentences combines the suggestions of each location that fall in the
+// same sentence, even when they have different IDs, into a single replace
+// operation. Applied one by one, such suggestions each carry anchors that
+// the others change. The merged suggestion keeps the ID of the first one and
+// lists the others in MergedIDs. It returns the number of suggestions merged
+// away.
+func MergeSentences(result *ProcessingResult) int {
+	if result.Structure == nil {
+		return 0
+	}
+	merged := 0
+	for i := range result.GroupedSuggestions {
+		group := &result.GroupedSuggestions[i]
+		if group.Location.InMetadata {
+			continue
+		}
+		segment := result.Structure.segmentStructure(group.Location.SegmentID)
+
+		var suggestions []GroupedActionableSuggestion
+		var run []GroupedActionableSuggestion
+		flush := func() {
+			if len(run) > 1 {
+				suggestions = append(suggestions, mergeSentence(run, segment))
+				merged += len(run) - 1
+			} else {
+				suggestions = append(suggestions, run...)
+			}
+			run = nil
+		}
+		for _, sugg := range group.Suggestions {
+			if !sentenceMergeable(sugg) {
+				flush()
+				suggestions = append(suggestions, sugg)
+				continue
+			}
+			if len(run) > 0 && !sameSentence(segment, run[len(run)-1], sugg) {
+				flush()
+			}
+			run = append(run, sugg)
+		}
+		flush()
+		group.Suggestions = suggestions
+	}
+	return merged
+}
+
+// sameSentence reports whether next follows prev in the same sentence, with
+// no sentence end in the unchanged text between them.
+func sameSentence(segment *DocumentStructure, prev, next GroupedActionableSuggestion) bool {
+	if next.Position.StartIndex < prev.Position.EndIndex {
+		// Overlapping suggestions are left for conflict resolution
+		return false
+	}
+	between := textBetween(segment, prev.Position.EndIndex, next.Position.StartIndex)
+	if strings.ContainsAny(between, "\n\v") {
+		return false
+	}
+	return lastSentenceEnd(between+" ") < 0 && !endsSentence(prev.Change.OriginalText) && !endsSentence(prev.Change.NewText)
+}
+
+// endsSentence reports whether changed text ends a sentence of its own, so
+// the text after it starts a new one.
+func endsSentence(text string) bool {
+	text = strings.TrimRight(text, " ")
+	return text != "" && isSentencePunct(text[len(text)-1])
+}
+
+// mergeSentence combines suggestions in the same sentence, in position
+// order, into one replacement of the text from the first to the last.
+func mergeSentence(run []GroupedActionableSuggestion, segment *DocumentStructure) GroupedActionableSuggestion {
+	first := run[0]
+	last := run[len(run)-1]
+
+	var original, replacement strings.Builder
+	var atomicChanges []SuggestionChange
+	atomicCount := 0
+	change := SuggestionChange{Type: "replace"}
+	for i, sugg := range run {
+		if i > 0 {
+			gap := textBetween(segment, run[i-1].Position.EndIndex, sugg.Position.StartIndex)
+			original.WriteString(gap)
+			replacement.WriteString(gap)
+		}
+		original.WriteString(sugg.Change.OriginalText)
+		replacement.WriteString(sugg.Change.NewText)
+		if change.OriginalLinkURL == "" {
+			change.OriginalLinkURL = sugg.Change.OriginalLinkURL
+		}
+		if change.NewLinkURL == "" {
+			change.NewLinkURL = sugg.Change.NewLinkURL
+		}
+		atomicChanges = append(atomicChanges, sugg.AtomicChanges...)
+		atomicCount += sugg.AtomicCount
+	}
+	change.OriginalText = original.String()
+	change.NewText = replacement.String()
+
+	precedingText, followingText := getTextAround(segment, first.Position.StartIndex, last.Position.EndIndex, DefaultGroupedAnchorLength)
+	sugg := GroupedActionableSuggestion{
+		ID: first.ID,
+		Anchor: SuggestionAnchor{
+			PrecedingText: precedingText,
+			FollowingText: followingText,
+		},
+		Change: change,
+		Verification: SuggestionVerification{
+			TextBeforeChange: precedingText + change.OriginalText + followingText,
+			TextAfterChange:  precedingText + change.NewText + followingText,
+		},
+		AtomicChanges:    atomicChanges,
+		AtomicCount:      atomicCount,
+		SuggestionAuthor: first.SuggestionAuthor,
+	}
+	sugg.Position.StartIndex = first.Position.StartIndex
+	sugg.Position.EndIndex = last.Position.EndIndex
+	for _, other := range run[1:] {
+		sugg.MergedIDs = append(sugg.MergedIDs, other.SuggestionIDs()...)
+		if other.SuggestionAuthor != first.SuggestionAuthor {
+			// Made by several reviewers
+			sugg.SuggestionAuthor = SuggestionAuthor{}
+		}
+	}
+	return sugg
+}
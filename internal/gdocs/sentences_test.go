@@ -0,0 +1,60 @@
+package gdocs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMergeSentences(t *testing.T) {
+	// "Ubuntu is a fast OS. It is free." with "fast" -> "quick", "OS" -> "system"
+	// and "free" -> "open" suggested separately
+	text := "Ubuntu is a fastquick OSsystem. It is freeopen."
+	structure := &DocumentStructure{
+		FullText:     text,
+		TextElements: []TextElementWithPosition{{Text: text, StartIndex: 0, EndIndex: int64(len(text))}},
+	}
+	replace := func(id, original, replacement string) GroupedActionableSuggestion {
+		start := int64(strings.Index(text, original+replacement))
+		sugg := groupedAt(id, "replace", start, start+int64(len(original+replacement)))
+		sugg.Change.OriginalText = original
+		sugg.Change.NewText = replacement
+		sugg.AtomicChanges = []SuggestionChange{{Type: "delete", OriginalText: original}, {Type: "insert", NewText: replacement}}
+		sugg.AtomicCount = 2
+		return sugg
+	}
+	result := &ProcessingResult{
+		Structure: structure,
+		GroupedSuggestions: []LocationGroupedSuggestions{{
+			Suggestions: []GroupedActionableSuggestion{
+				replace("a", "fast", "quick"),
+				replace("b", "OS", "system"),
+				replace("c", "free", "open"),
+			},
+		}},
+	}
+
+	if merged := MergeSentences(result); merged != 1 {
+		t.Errorf("Expected one suggestion to be merged, got %d", merged)
+	}
+
+	suggestions := result.GroupedSuggestions[0].Suggestions
+	if len(suggestions) != 2 {
+		t.Fatalf("Expected 2 suggestions, got %d", len(suggestions))
+	}
+	got := suggestions[0]
+	want := SuggestionChange{Type: "replace", OriginalText: "fast OS", NewText: "quick system"}
+	if diff := cmp.Diff(want, got.Change); diff != "" {
+		t.Errorf("Change mismatch (-want +got):\n%s", diff)
+	}
+	if got.ID != "a" || !cmp.Equal(got.MergedIDs, []string{"b"}) || got.AtomicCount != 4 {
+		t.Errorf("Unexpected merged suggestion: %s %v %d", got.ID, got.MergedIDs, got.AtomicCount)
+	}
+	if got.Verification.TextBeforeChange != "Ubuntu is a fast OS. It is freeopen." {
+		t.Errorf("Unexpected verification: %q", got.Verification.TextBeforeChange)
+	}
+	if suggestions[1].ID != "c" || suggestions[1].MergedIDs != nil {
+		t.Errorf("Expected the next sentence to stay separate, got %+v", suggestions[1])
+	}
+}
@@ -3,9 +3,9 @@ package gdocs
 import (
 	"context"
 	"fmt"
-	"os"
+	"net/http"
 
-	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2"
 	"google.golang.org/api/docs/v1"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/option"
@@ -15,29 +15,56 @@ import (
 type Client struct {
 	Docs  *docs.Service
 	Drive *drive.Service
-}
 
-// NewClient creates a new Google Docs and Drive client using the provided credentials file.
-func NewClient(ctx context.Context, credentialsPath string) (*Client, error) {
-	// Read service account credentials
-	credentials, err := os.ReadFile(credentialsPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read service account file: %w", err)
-	}
+	// Cache, if set, keeps fetched documents between runs
+	Cache *DocumentCache
+
+	// Tab, if set, is the ID or title of the only tab of documents fetched
+	Tab string
 
+	// httpClient is the authenticated client of both services, for requests
+	// they have no call for, such as revision exports
+	httpClient *http.Client
+}
+
+// NewClient creates a new Google Docs and Drive client using the credentials selected by opts.
+func NewClient(ctx context.Context, opts ClientOptions) (*Client, error) {
 	// Scopes for both Docs and Drive
-	scopes := []string{
+	return newClient(ctx, opts, []string{
 		"https://www.googleapis.com/auth/documents.readonly",
 		"https://www.googleapis.com/auth/drive.readonly",
-	}
+	})
+}
 
-	config, err := google.JWTConfigFromJSON(credentials, scopes...)
+// NewWriteClient creates a client that can also edit documents, e.g. to accept
+// or reject suggestions. The service account needs edit access to the document.
+func NewWriteClient(ctx context.Context, opts ClientOptions) (*Client, error) {
+	return newClient(ctx, opts, []string{
+		"https://www.googleapis.com/auth/documents",
+		"https://www.googleapis.com/auth/drive.readonly",
+	})
+}
+
+// NewCommentClient creates a client that can also comment on documents, e.g.
+// to link the PR of a run. The service account needs comment access to the
+// document.
+func NewCommentClient(ctx context.Context, opts ClientOptions) (*Client, error) {
+	return newClient(ctx, opts, []string{
+		"https://www.googleapis.com/auth/documents.readonly",
+		"https://www.googleapis.com/auth/drive",
+	})
+}
+
+func newClient(ctx context.Context, opts ClientOptions, scopes []string) (*Client, error) {
+	credentials, err := ResolveCredentials(ctx, opts, scopes...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create JWT config: %w", err)
+		return nil, err
 	}
 
-	// Create a single HTTP client with the JWT config
-	httpClient := config.Client(ctx)
+	// Create a single HTTP client shared by both services; transient errors
+	// and rate limiting are retried rather than failing the run
+	httpClient := oauth2.NewClient(ctx, credentials.TokenSource)
+	httpClient.Transport = newRetryTransport(httpClient.Transport, opts.Retry)
 
 	// Initialize Docs service
 	docsService, err := docs.NewService(ctx, option.WithHTTPClient(httpClient))
@@ -51,8 +78,14 @@ func NewClient(ctx context.Context, credentialsPath string) (*Client, error) {
 		return nil, fmt.Errorf("failed to create drive service: %w", err)
 	}
 
-	return &Client{
-		Docs:  docsService,
-		Drive: driveService,
-	}, nil
+	client := &Client{
+		Docs:       docsService,
+		Drive:      driveService,
+		httpClient: httpClient,
+	}
+	if opts.CacheDir != "" {
+		client.Cache = &DocumentCache{Dir: opts.CacheDir}
+	}
+	client.Tab = opts.Tab
+	return client, nil
 }
@@ -0,0 +1,104 @@
+package gdocs
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// SnapshotFile is the name of the compressed raw document archived with each run.
+const SnapshotFile = "bauer-doc-snapshot.json.gz"
+
+// RawDocumentFile is the readable raw document written with --dump-raw.
+const RawDocumentFile = "bauer-doc-raw.json"
+
+// WriteDocumentSnapshot stores the raw Documents.Get response as gzip-compressed JSON.
+// Snapshots let failed extractions be replayed offline and serve as regression fixtures.
+func WriteDocumentSnapshot(path string, doc *docs.Document) error {
+	if doc == nil {
+		return fmt.Errorf("no document to snapshot")
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	gz.Name = doc.DocumentId + ".json"
+
+	if err := json.NewEncoder(gz).Encode(doc); err != nil {
+		gz.Close()
+		return fmt.Errorf("failed to encode document snapshot: %w", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish document snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// ReadDocumentSnapshot loads a document written by WriteDocumentSnapshot.
+func ReadDocumentSnapshot(path string) (*docs.Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+	defer gz.Close()
+
+	var doc docs.Document
+	if err := json.NewDecoder(gz).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode document snapshot: %w", err)
+	}
+
+	return &doc, nil
+}
+
+// WriteRawDocument stores the raw Documents.Get response as indented JSON,
+// for inspecting the document when debugging anchors and grouping.
+func WriteRawDocument(path string, doc *docs.Document) error {
+	if doc == nil {
+		return fmt.Errorf("no document to dump")
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode raw document: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write raw document: %w", err)
+	}
+	return nil
+}
+
+// ReadRawDocument loads a document written by WriteRawDocument, or a
+// compressed snapshot when the file ends in .gz.
+func ReadRawDocument(path string) (*docs.Document, error) {
+	if strings.HasSuffix(path, ".gz") {
+		return ReadDocumentSnapshot(path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read raw document: %w", err)
+	}
+	var doc docs.Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode raw document: %w", err)
+	}
+	// Documents saved straight from the API may still have their tabs
+	flattenTabs(&doc)
+	return &doc, nil
+}
@@ -0,0 +1,79 @@
+package gdocs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+func TestDocumentSnapshotRoundTrip(t *testing.T) {
+	doc := &docs.Document{
+		DocumentId: "doc-123",
+		Title:      "Snapshot test",
+		RevisionId: "rev-1",
+		Body: &docs.Body{
+			Content: []*docs.StructuralElement{
+				paragraphWithText(1, 12, "Hello world", "ins-1"),
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), SnapshotFile)
+	if err := WriteDocumentSnapshot(path, doc); err != nil {
+		t.Fatalf("WriteDocumentSnapshot() failed: %v", err)
+	}
+
+	got, err := ReadDocumentSnapshot(path)
+	if err != nil {
+		t.Fatalf("ReadDocumentSnapshot() failed: %v", err)
+	}
+
+	if got.DocumentId != doc.DocumentId || got.Title != doc.Title || got.RevisionId != doc.RevisionId {
+		t.Errorf("Snapshot metadata mismatch: got %s/%s/%s", got.DocumentId, got.Title, got.RevisionId)
+	}
+
+	suggestions := ExtractSuggestions(got)
+	if len(suggestions) != 1 || suggestions[0].ID != "ins-1" {
+		t.Errorf("Expected replayed snapshot to yield suggestion ins-1, got %+v", suggestions)
+	}
+}
+
+func TestWriteDocumentSnapshot_NilDocument(t *testing.T) {
+	if err := WriteDocumentSnapshot(filepath.Join(t.TempDir(), SnapshotFile), nil); err == nil {
+		t.Error("Expected error for nil document")
+	}
+}
+
+func TestRawDocumentRoundTrip(t *testing.T) {
+	doc := &docs.Document{
+		DocumentId: "doc-123",
+		Title:      "Raw test",
+		Body: &docs.Body{
+			Content: []*docs.StructuralElement{
+				paragraphWithText(1, 12, "Hello world", "ins-1"),
+			},
+		},
+	}
+
+	dir := t.TempDir()
+	rawPath := filepath.Join(dir, RawDocumentFile)
+	snapshotPath := filepath.Join(dir, SnapshotFile)
+	if err := WriteRawDocument(rawPath, doc); err != nil {
+		t.Fatalf("WriteRawDocument() failed: %v", err)
+	}
+	if err := WriteDocumentSnapshot(snapshotPath, doc); err != nil {
+		t.Fatalf("WriteDocumentSnapshot() failed: %v", err)
+	}
+
+	// Both the readable dump and the compressed snapshot can be replayed
+	for _, path := range []string{rawPath, snapshotPath} {
+		got, err := ReadRawDocument(path)
+		if err != nil {
+			t.Fatalf("ReadRawDocument(%s) failed: %v", path, err)
+		}
+		if suggestions := ExtractSuggestions(got); got.Title != doc.Title || len(suggestions) != 1 {
+			t.Errorf("%s: expected the document back, got %q with %d suggestions", path, got.Title, len(suggestions))
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package gdocs
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// SuggestionStats summarises the suggestions of a run, so teams can report
+// on review volume. Counts are of grouped suggestions.
+type SuggestionStats struct {
+	Total int `json:"total"`
+
+	ByChangeType map[string]int `json:"by_change_type"`
+	BySection    map[string]int `json:"by_section"`
+
+	// ByTable counts suggestions in tables, by table title or ID
+	ByTable map[string]int `json:"by_table,omitempty"`
+
+	// AverageChangeSize is the average length of the original and new text
+	// of a suggestion, in characters
+	AverageChangeSize float64 `json:"average_change_size"`
+
+	// ConflictsResolved is the number of suggestions dropped for overlapping others
+	ConflictsResolved int `json:"conflicts_resolved"`
+
+	// Metadata is the number of suggestions to the metadata table
+	Metadata int `json:"metadata"`
+
+	// Filtered is the number of suggestions left out by the suggestion filter
+	Filtered int `json:"filtered"`
+}
+
+// ComputeSuggestionStats counts the grouped suggestions of result, and sets
+// result.Stats. filtered is the number of suggestions the filter removed.
+func ComputeSuggestionStats(result *ProcessingResult, filtered int) *SuggestionStats {
+	stats := &SuggestionStats{
+		ByChangeType: make(map[string]int),
+		BySection:    make(map[string]int),
+		ByTable:      make(map[string]int),
+		Filtered:     filtered,
+	}
+	if result.ConflictReport != nil {
+		stats.ConflictsResolved = len(result.ConflictReport.Dropped)
+	}
+
+	size := 0
+	for _, group := range result.GroupedSuggestions {
+		for _, sugg := range group.Suggestions {
+			stats.Total++
+			stats.ByChangeType[sugg.Change.Type]++
+			stats.BySection[group.Location.Section]++
+			if group.Location.InTable && group.Location.Table != nil {
+				table := cmp.Or(group.Location.Table.TableTitle, group.Location.Table.TableID)
+				stats.ByTable[table]++
+			}
+			if group.Location.InMetadata {
+				stats.Metadata++
+			}
+			size += utf8.RuneCountInString(sugg.Change.OriginalText) + utf8.RuneCountInString(sugg.Change.NewText)
+		}
+	}
+	if stats.Total > 0 {
+		stats.AverageChangeSize = float64(size) / float64(stats.Total)
+	}
+
+	result.Stats = stats
+	return stats
+}
+
+// Summary renders the stats as indented lines for the CLI summary.
+func (s *SuggestionStats) Summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "  by change type: %s\n", formatCounts(s.ByChangeType))
+	fmt.Fprintf(&sb, "  by section: %s\n", formatCounts(s.BySection))
+	if len(s.ByTable) > 0 {
+		fmt.Fprintf(&sb, "  by table: %s\n", formatCounts(s.ByTable))
+	}
+	fmt.Fprintf(&sb, "  average change size: %.1f characters\n", s.AverageChangeSize)
+	if s.Metadata > 0 {
+		fmt.Fprintf(&sb, "  metadata: %d\n", s.Metadata)
+	}
+	if s.ConflictsResolved > 0 {
+		fmt.Fprintf(&sb, "  conflicts resolved: %d\n", s.ConflictsResolved)
+	}
+	if s.Filtered > 0 {
+		fmt.Fprintf(&sb, "  filtered out: %d\n", s.Filtered)
+	}
+	return sb.String()
+}
+
+// formatCounts renders counts as "replace: 3, insert: 1", most frequent first.
+func formatCounts(counts map[string]int) string {
+	keys := slices.SortedFunc(maps.Keys(counts), func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s: %d", key, counts[key])
+	}
+	return strings.Join(parts, ", ")
+}
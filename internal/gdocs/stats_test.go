@@ -0,0 +1,50 @@
+package gdocs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestComputeSuggestionStats(t *testing.T) {
+	replace := groupedAt("a", "replace", 1, 5)
+	replace.Change.OriginalText = "old"
+	replace.Change.NewText = "newer"
+	insert := groupedAt("b", "insert", 10, 12)
+	insert.Change.NewText = "é!"
+	result := &ProcessingResult{
+		GroupedSuggestions: []LocationGroupedSuggestions{
+			{
+				Location:    SuggestionLocation{Section: "Body", InTable: true, Table: &TableLocation{TableID: "table-1", TableTitle: "Hero"}},
+				Suggestions: []GroupedActionableSuggestion{replace, insert},
+			},
+			{
+				Location:    SuggestionLocation{Section: "Body", InMetadata: true},
+				Suggestions: []GroupedActionableSuggestion{groupedAt("c", "replace", 20, 30)},
+			},
+		},
+		ConflictReport: &ConflictReport{Dropped: []DroppedSuggestion{{ID: "d"}}},
+	}
+
+	stats := ComputeSuggestionStats(result, 2)
+
+	want := &SuggestionStats{
+		Total:             3,
+		ByChangeType:      map[string]int{"replace": 2, "insert": 1},
+		BySection:         map[string]int{"Body": 3},
+		ByTable:           map[string]int{"Hero": 2},
+		AverageChangeSize: 10.0 / 3,
+		ConflictsResolved: 1,
+		Metadata:          1,
+		Filtered:          2,
+	}
+	if diff := cmp.Diff(want, stats); diff != "" {
+		t.Errorf("Stats mismatch (-want +got):\n%s", diff)
+	}
+	if result.Stats != stats {
+		t.Error("Expected the stats to be attached to the result")
+	}
+	if got := formatCounts(stats.ByChangeType); got != "replace: 2, insert: 1" {
+		t.Errorf("Unexpected counts: %q", got)
+	}
+}
@@ -0,0 +1,153 @@
+package gdocs
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// buildStyleDelta compares the current style of a text run with a suggested
+// style change. Only the properties flagged in the suggestion state are
+// included; nil is returned when none of them can be described.
+func buildStyleDelta(current *docs.TextStyle, suggested docs.SuggestedTextStyle) *StyleDelta {
+	if current == nil {
+		current = &docs.TextStyle{}
+	}
+	next := suggested.TextStyle
+	if next == nil {
+		next = &docs.TextStyle{}
+	}
+	state := suggested.TextStyleSuggestionState
+	if state == nil {
+		return nil
+	}
+
+	delta := &StyleDelta{}
+	if state.BoldSuggested {
+		delta.Bold = styleProperty(formatBool(current.Bold), formatBool(next.Bold))
+	}
+	if state.ItalicSuggested {
+		delta.Italic = styleProperty(formatBool(current.Italic), formatBool(next.Italic))
+	}
+	if state.UnderlineSuggested {
+		delta.Underline = styleProperty(formatBool(current.Underline), formatBool(next.Underline))
+	}
+	if state.StrikethroughSuggested {
+		delta.Strikethrough = styleProperty(formatBool(current.Strikethrough), formatBool(next.Strikethrough))
+	}
+	if state.LinkSuggested {
+		delta.Link = styleProperty(formatLink(current.Link), formatLink(next.Link))
+	}
+	if state.FontSizeSuggested {
+		delta.FontSize = styleProperty(formatDimension(current.FontSize), formatDimension(next.FontSize))
+	}
+	if state.ForegroundColorSuggested {
+		delta.ForegroundColor = styleProperty(formatColor(current.ForegroundColor), formatColor(next.ForegroundColor))
+	}
+	if state.BackgroundColorSuggested {
+		delta.BackgroundColor = styleProperty(formatColor(current.BackgroundColor), formatColor(next.BackgroundColor))
+	}
+
+	if delta.IsEmpty() {
+		return nil
+	}
+	return delta
+}
+
+// IsEmpty reports whether the delta has no changed properties.
+func (d *StyleDelta) IsEmpty() bool {
+	return d == nil || len(d.properties()) == 0
+}
+
+// String describes the delta for humans and prompts, e.g.
+// `bold: "" -> "true", link: "" -> "https://ubuntu.com"`.
+func (d *StyleDelta) String() string {
+	if d == nil {
+		return ""
+	}
+	var parts []string
+	for _, p := range d.properties() {
+		parts = append(parts, fmt.Sprintf("%s: %q -> %q", p.name, p.value.Before, p.value.After))
+	}
+	return strings.Join(parts, ", ")
+}
+
+type namedStyleProperty struct {
+	name  string
+	value *StyleProperty
+}
+
+// properties returns the set properties in a stable order
+func (d *StyleDelta) properties() []namedStyleProperty {
+	all := []namedStyleProperty{
+		{"bold", d.Bold},
+		{"italic", d.Italic},
+		{"underline", d.Underline},
+		{"strikethrough", d.Strikethrough},
+		{"link", d.Link},
+		{"font_size", d.FontSize},
+		{"foreground_color", d.ForegroundColor},
+		{"background_color", d.BackgroundColor},
+	}
+	set := all[:0]
+	for _, p := range all {
+		if p.value != nil {
+			set = append(set, p)
+		}
+	}
+	return set
+}
+
+// styleProperty returns nil when the suggestion doesn't actually change the value
+func styleProperty(before, after string) *StyleProperty {
+	if before == after {
+		return nil
+	}
+	return &StyleProperty{Before: before, After: after}
+}
+
+func formatBool(v bool) string {
+	if v {
+		return "true"
+	}
+	return ""
+}
+
+func formatLink(link *docs.Link) string {
+	switch {
+	case link == nil:
+		return ""
+	case link.Url != "":
+		return link.Url
+	case link.HeadingId != "":
+		return "#" + link.HeadingId
+	case link.BookmarkId != "":
+		return "#" + link.BookmarkId
+	}
+	return ""
+}
+
+func formatDimension(dim *docs.Dimension) string {
+	if dim == nil || dim.Magnitude == 0 {
+		return ""
+	}
+	unit := strings.ToLower(dim.Unit)
+	if unit == "" {
+		unit = "pt"
+	}
+	return fmt.Sprintf("%g%s", dim.Magnitude, unit)
+}
+
+// formatColor renders a color as #rrggbb
+func formatColor(color *docs.OptionalColor) string {
+	if color == nil || color.Color == nil || color.Color.RgbColor == nil {
+		return ""
+	}
+	rgb := color.Color.RgbColor
+	channel := func(v float64) int {
+		return int(math.Round(v * 255))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", channel(rgb.Red), channel(rgb.Green), channel(rgb.Blue))
+}
@@ -0,0 +1,208 @@
+package gdocs
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// documentTabFields are the parts of a document, or of a tab, extraction
+// uses. Styles, named ranges and positioned objects are left out of the
+// response, which keeps large documents to a fraction of their full size.
+const documentTabFields = "body,headers,footers,footnotes,lists,inlineObjects"
+
+// maxTabNesting is how deeply the Docs editor nests tabs.
+const maxTabNesting = 3
+
+// tabsFields is the field mask of a document fetched with the content of all
+// its tabs.
+var tabsFields = "documentId,title,revisionId,tabs(" + tabFields(maxTabNesting) + ")"
+
+func tabFields(depth int) string {
+	fields := "tabProperties,documentTab(" + documentTabFields + ")"
+	if depth > 1 {
+		fields += ",childTabs(" + tabFields(depth-1) + ")"
+	}
+	return fields
+}
+
+// walkTabs calls fn for each tab, parents before their child tabs.
+func walkTabs(tabs []*docs.Tab, fn func(*docs.Tab)) {
+	for _, tab := range tabs {
+		fn(tab)
+		walkTabs(tab.ChildTabs, fn)
+	}
+}
+
+// findTab returns the tab of the ID or title (case-insensitive).
+func findTab(doc *docs.Document, tab string) (*docs.Tab, error) {
+	var found *docs.Tab
+	walkTabs(doc.Tabs, func(t *docs.Tab) {
+		if found != nil || t.TabProperties == nil {
+			return
+		}
+		if t.TabProperties.TabId == tab || strings.EqualFold(t.TabProperties.Title, tab) {
+			found = t
+		}
+	})
+	if found == nil {
+		return nil, fmt.Errorf("document %s has no tab %q", doc.DocumentId, tab)
+	}
+	return found, nil
+}
+
+// tabRangesName names the named ranges flattenTabs records the tabs in: one
+// range per tab, named after the tab's title, with the tab's ID.
+const tabRangesName = "bauer.tabs"
+
+// flattenTabs moves the content of the tabs of a document into the fields of
+// a document fetched without its tabs, one tab after the other, so
+// extraction reads them like a single document. Each tab has its own
+// indices, which are shifted past the tabs before it; the range of each tab
+// is recorded under tabRangesName to tell them apart. Headers, footers,
+// footnotes, lists and inline objects have IDs unique to the document and are
+// merged.
+func flattenTabs(doc *docs.Document) {
+	var tabs []*docs.Tab
+	walkTabs(doc.Tabs, func(t *docs.Tab) {
+		if t.DocumentTab != nil {
+			tabs = append(tabs, t)
+		}
+	})
+	if len(tabs) == 0 {
+		return
+	}
+
+	doc.Body = &docs.Body{}
+	doc.Headers = make(map[string]docs.Header)
+	doc.Footers = make(map[string]docs.Footer)
+	doc.Footnotes = make(map[string]docs.Footnote)
+	doc.Lists = make(map[string]docs.List)
+	doc.InlineObjects = make(map[string]docs.InlineObject)
+	ranges := docs.NamedRanges{Name: tabRangesName}
+
+	var offset int64
+	for _, t := range tabs {
+		content := t.DocumentTab
+		start := offset
+		if content.Body != nil {
+			shiftIndices(content.Body.Content, offset)
+			doc.Body.Content = append(doc.Body.Content, content.Body.Content...)
+			if n := len(content.Body.Content); n > 0 {
+				offset = content.Body.Content[n-1].EndIndex
+			}
+		}
+		maps.Copy(doc.Headers, content.Headers)
+		maps.Copy(doc.Footers, content.Footers)
+		maps.Copy(doc.Footnotes, content.Footnotes)
+		maps.Copy(doc.Lists, content.Lists)
+		maps.Copy(doc.InlineObjects, content.InlineObjects)
+
+		if t.TabProperties != nil {
+			ranges.NamedRanges = append(ranges.NamedRanges, &docs.NamedRange{
+				Name:         t.TabProperties.Title,
+				NamedRangeId: t.TabProperties.TabId,
+				Ranges:       []*docs.Range{{StartIndex: start, EndIndex: offset, TabId: t.TabProperties.TabId}},
+			})
+		}
+	}
+
+	// A single tab is the document as it was before tabs
+	if len(tabs) > 1 {
+		doc.NamedRanges = map[string]docs.NamedRanges{tabRangesName: ranges}
+	}
+	doc.Tabs = nil
+}
+
+// shiftIndices adds offset to the indices of content.
+func shiftIndices(content []*docs.StructuralElement, offset int64) {
+	if offset == 0 {
+		return
+	}
+	for _, elem := range content {
+		if elem == nil {
+			continue
+		}
+		elem.StartIndex += offset
+		elem.EndIndex += offset
+		if elem.Paragraph != nil {
+			for _, paraElem := range elem.Paragraph.Elements {
+				paraElem.StartIndex += offset
+				paraElem.EndIndex += offset
+			}
+		}
+		if elem.Table != nil {
+			for _, row := range elem.Table.TableRows {
+				row.StartIndex += offset
+				row.EndIndex += offset
+				for _, cell := range row.TableCells {
+					cell.StartIndex += offset
+					cell.EndIndex += offset
+					shiftIndices(cell.Content, offset)
+				}
+			}
+		}
+		if elem.TableOfContents != nil {
+			shiftIndices(elem.TableOfContents.Content, offset)
+		}
+	}
+}
+
+// TabRange is the range of a tab in a document flattened by flattenTabs.
+type TabRange struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	StartIndex int64  `json:"start_index"`
+	EndIndex   int64  `json:"end_index"`
+}
+
+// tabRanges returns the ranges of the tabs of a document with several tabs.
+func tabRanges(doc *docs.Document) []TabRange {
+	var ranges []TabRange
+	for _, r := range doc.NamedRanges[tabRangesName].NamedRanges {
+		if len(r.Ranges) == 0 {
+			continue
+		}
+		ranges = append(ranges, TabRange{
+			ID:         r.NamedRangeId,
+			Title:      r.Name,
+			StartIndex: r.Ranges[0].StartIndex,
+			EndIndex:   r.Ranges[0].EndIndex,
+		})
+	}
+	return ranges
+}
+
+// tabAt returns the tab that index is in, or nil.
+func tabAt(tabs []TabRange, index int64) *TabRange {
+	for i := range tabs {
+		if index >= tabs[i].StartIndex && index < tabs[i].EndIndex {
+			return &tabs[i]
+		}
+	}
+	return nil
+}
+
+// selectTab keeps only the content of a tab, in the fields of a document
+// fetched without its tabs, so extraction reads it like any document and the
+// other tabs can be freed.
+func selectTab(doc *docs.Document, tab string) error {
+	t, err := findTab(doc, tab)
+	if err != nil {
+		return err
+	}
+	content := t.DocumentTab
+	if content == nil {
+		content = &docs.DocumentTab{}
+	}
+	doc.Body = content.Body
+	doc.Headers = content.Headers
+	doc.Footers = content.Footers
+	doc.Footnotes = content.Footnotes
+	doc.Lists = content.Lists
+	doc.InlineObjects = content.InlineObjects
+	doc.Tabs = nil
+	return nil
+}
@@ -0,0 +1,129 @@
+package gdocs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/docs/v1"
+)
+
+func TestSelectTab(t *testing.T) {
+	tabBody := func(text string) *docs.DocumentTab {
+		return &docs.DocumentTab{Body: &docs.Body{Content: []*docs.StructuralElement{{
+			Paragraph: &docs.Paragraph{Elements: []*docs.ParagraphElement{{TextRun: &docs.TextRun{Content: text}}}},
+		}}}}
+	}
+	newDoc := func() *docs.Document {
+		return &docs.Document{
+			DocumentId: "doc-1",
+			Tabs: []*docs.Tab{
+				{TabProperties: &docs.TabProperties{TabId: "t.0", Title: "Homepage"}, DocumentTab: tabBody("home\n"),
+					ChildTabs: []*docs.Tab{
+						{TabProperties: &docs.TabProperties{TabId: "t.1", Title: "Pricing"}, DocumentTab: tabBody("pricing\n")},
+					}},
+			},
+		}
+	}
+
+	tests := []struct {
+		tab     string
+		want    string
+		wantErr bool
+	}{
+		{tab: "t.0", want: "home\n"},
+		{tab: "pricing", want: "pricing\n"},
+		{tab: "t.9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tab, func(t *testing.T) {
+			doc := newDoc()
+			err := selectTab(doc, tt.tab)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectTab() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if doc.Tabs != nil {
+				t.Error("Expected the other tabs to be dropped")
+			}
+			if got := BuildDocumentStructure(doc).FullText; got != tt.want {
+				t.Errorf("FullText = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTabsFields(t *testing.T) {
+	// Tabs nest three levels deep
+	if got := strings.Count(tabsFields, "childTabs("); got != maxTabNesting-1 {
+		t.Errorf("tabsFields has %d levels of child tabs, want %d: %s", got, maxTabNesting-1, tabsFields)
+	}
+	if got := strings.Count(tabsFields, "documentTab("+documentTabFields+")"); got != maxTabNesting {
+		t.Errorf("tabsFields has the content of %d levels of tabs, want %d: %s", got, maxTabNesting, tabsFields)
+	}
+}
+
+func TestFlattenTabs(t *testing.T) {
+	paragraph := func(style string, runs ...*docs.TextRun) *docs.StructuralElement {
+		elem := &docs.StructuralElement{Paragraph: &docs.Paragraph{ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: style}}}
+		for _, run := range runs {
+			elem.Paragraph.Elements = append(elem.Paragraph.Elements, &docs.ParagraphElement{TextRun: run})
+		}
+		return elem
+	}
+	at := func(elem *docs.StructuralElement, start, end int64) *docs.StructuralElement {
+		elem.StartIndex, elem.EndIndex = start, end
+		for _, pe := range elem.Paragraph.Elements {
+			pe.StartIndex = start
+			pe.EndIndex = start + int64(len(pe.TextRun.Content))
+			start = pe.EndIndex
+		}
+		return elem
+	}
+
+	doc := &docs.Document{
+		DocumentId: "doc-1",
+		Tabs: []*docs.Tab{
+			{TabProperties: &docs.TabProperties{TabId: "t.0", Title: "Homepage"}, DocumentTab: &docs.DocumentTab{Body: &docs.Body{Content: []*docs.StructuralElement{
+				at(paragraph("HEADING_1", &docs.TextRun{Content: "Home\n"}), 1, 6),
+				at(paragraph("NORMAL_TEXT", &docs.TextRun{Content: "Welcome\n"}), 6, 14),
+			}}}},
+			{TabProperties: &docs.TabProperties{TabId: "t.1", Title: "Pricing"}, DocumentTab: &docs.DocumentTab{Body: &docs.Body{Content: []*docs.StructuralElement{
+				at(paragraph("NORMAL_TEXT",
+					&docs.TextRun{Content: "Plans "},
+					&docs.TextRun{Content: "cheap", SuggestedInsertionIds: []string{"sugg-1"}},
+					&docs.TextRun{Content: "\n"},
+				), 1, 13),
+			}}}},
+		},
+	}
+	flattenTabs(doc)
+
+	suggestions, structure := ExtractDocument(doc, 1)
+	want := []TabRange{
+		{ID: "t.0", Title: "Homepage", StartIndex: 0, EndIndex: 14},
+		{ID: "t.1", Title: "Pricing", StartIndex: 14, EndIndex: 27},
+	}
+	if diff := cmp.Diff(want, structure.Tabs); diff != "" {
+		t.Errorf("Tabs mismatch (-want +got):\n%s", diff)
+	}
+	if len(suggestions) != 1 || suggestions[0].StartIndex != 21 {
+		t.Fatalf("Expected the suggestion at index 21, got %+v", suggestions)
+	}
+
+	actionable := BuildActionableSuggestions(suggestions, structure, nil)
+	location := actionable[0].Location
+	if location.Tab != "Pricing" {
+		t.Errorf("Tab = %q, want %q", location.Tab, "Pricing")
+	}
+	// The heading and text of the first tab aren't carried over
+	if location.ParentHeading != "" {
+		t.Errorf("ParentHeading = %q, want none", location.ParentHeading)
+	}
+	if got := actionable[0].Anchor.PrecedingText; got != "Plans " {
+		t.Errorf("PrecedingText = %q, want %q", got, "Plans ")
+	}
+}
@@ -0,0 +1,359 @@
+package gdocs
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// DefaultExtractionWorkers is the number of workers ExtractSuggestions and
+// BuildDocumentStructure use to traverse a document.
+var DefaultExtractionWorkers = runtime.NumCPU()
+
+// elementResult is what a single top-level body element contributes to the
+// suggestions and the document structure. Counters (heading, table and text
+// IDs) and table titles depend on the elements before it, so they're assigned
+// when the results are merged in document order.
+type elementResult struct {
+	suggestions    []Suggestion
+	altTextChanges []Suggestion
+	heading        *DocumentHeading
+	listItem       *ListRange // Ordinals are assigned when merging
+	paragraphText  *string    // Trimmed paragraph text; nil when the element isn't a paragraph
+	textElements   []TextElementWithPosition
+	table          *TableRange
+	footnoteRefs   map[string]int64
+}
+
+// segmentResult holds the suggestions and structure of a segment with its own
+// index space (header, footer or footnote).
+type segmentResult struct {
+	suggestions    []Suggestion
+	altTextChanges []Suggestion
+	structure      *DocumentStructure
+}
+
+// ExtractDocument extracts all suggestions and builds the document structure in
+// a single traversal. Top-level body elements and segments are processed by a
+// pool of at most workers goroutines; results are merged in document order, so
+// the output doesn't depend on the number of workers.
+func ExtractDocument(doc *docs.Document, workers int) ([]Suggestion, *DocumentStructure) {
+	var body []*docs.StructuralElement
+	if doc.Body != nil {
+		body = doc.Body.Content
+	}
+	headerIDs := sortedKeys(doc.Headers)
+	footerIDs := sortedKeys(doc.Footers)
+	footnoteIDs := sortedKeys(doc.Footnotes)
+
+	elements := make([]elementResult, len(body))
+	headers := make([]segmentResult, len(headerIDs))
+	footers := make([]segmentResult, len(footerIDs))
+	footnotes := make([]segmentResult, len(footnoteIDs))
+
+	var tasks []func()
+	for i, elem := range body {
+		tasks = append(tasks, func() { elements[i] = processBodyElement(doc, elem) })
+	}
+	for i, id := range headerIDs {
+		tasks = append(tasks, func() { headers[i] = processSegment(doc, doc.Headers[id].Content) })
+	}
+	for i, id := range footerIDs {
+		tasks = append(tasks, func() { footers[i] = processSegment(doc, doc.Footers[id].Content) })
+	}
+	for i, id := range footnoteIDs {
+		tasks = append(tasks, func() { footnotes[i] = processSegment(doc, doc.Footnotes[id].Content) })
+	}
+	runTasks(tasks, workers)
+
+	// Suggestions: body, body alt text, then headers, footers and footnotes
+	var suggestions []Suggestion
+	for _, elem := range elements {
+		suggestions = append(suggestions, elem.suggestions...)
+	}
+	for _, elem := range elements {
+		suggestions = append(suggestions, elem.altTextChanges...)
+	}
+	suggestions = appendSegmentSuggestions(suggestions, "Header", headerIDs, headers)
+	suggestions = appendSegmentSuggestions(suggestions, "Footer", footerIDs, footers)
+	suggestions = appendSegmentSuggestions(suggestions, "Footnote", footnoteIDs, footnotes)
+
+	// Describe inserted/deleted images by their alt text
+	for i := range suggestions {
+		if suggestions[i].Element == "inline_object" && suggestions[i].Type != "alt_text_change" {
+			suggestions[i].Content = describeInlineObject(doc, suggestions[i].ObjectID)
+		}
+	}
+
+	structure := mergeElementResults(elements)
+	if doc.Body != nil && doc.Body.Content != nil {
+		// Headers, footers and footnotes are indexed separately from the body
+		structure.Segments = make(map[string]*DocumentStructure)
+		for i, id := range headerIDs {
+			structure.Segments[id] = headers[i].structure
+		}
+		for i, id := range footerIDs {
+			structure.Segments[id] = footers[i].structure
+		}
+		for i, id := range footnoteIDs {
+			structure.Segments[id] = footnotes[i].structure
+		}
+	}
+	structure.Tabs = tabRanges(doc)
+
+	return suggestions, structure
+}
+
+// runTasks runs tasks on a pool of at most workers goroutines and waits for them.
+func runTasks(tasks []func(), workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(tasks) {
+		workers = len(tasks)
+	}
+
+	queue := make(chan func())
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range queue {
+				task()
+			}
+		}()
+	}
+	for _, task := range tasks {
+		queue <- task
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// processBodyElement extracts the suggestions and structure of a top-level body element.
+func processBodyElement(doc *docs.Document, elem *docs.StructuralElement) elementResult {
+	var result elementResult
+
+	processStructuralElement(elem, &result.suggestions)
+	content := []*docs.StructuralElement{elem}
+	result.altTextChanges = extractAltTextChanges(doc, content)
+
+	forEachParagraphElement(content, func(paraElem *docs.ParagraphElement) {
+		if paraElem.FootnoteReference == nil {
+			return
+		}
+		if result.footnoteRefs == nil {
+			result.footnoteRefs = make(map[string]int64)
+		}
+		result.footnoteRefs[paraElem.FootnoteReference.FootnoteId] = paraElem.StartIndex
+	})
+
+	// Heading IDs are numbered when merging
+	result.heading = extractHeading(elem, 0)
+
+	// Extract all text elements with positions (including from headings)
+	if elem.Paragraph != nil {
+		var paraText strings.Builder
+		for _, paraElem := range elem.Paragraph.Elements {
+			if paraElem.TextRun != nil {
+				result.textElements = append(result.textElements, textElementAt(paraElem))
+				paraText.WriteString(paraElem.TextRun.Content)
+			} else if object, ok := objectElementAt(doc, paraElem); ok {
+				result.textElements = append(result.textElements, object)
+			}
+		}
+		text := strings.TrimSpace(paraText.String())
+		result.paragraphText = &text
+		result.listItem = extractListItem(doc, elem, text)
+	}
+
+	// Extract table structure
+	if elem.Table != nil {
+		tableRange := &TableRange{
+			StartIndex:    elem.StartIndex,
+			EndIndex:      elem.EndIndex,
+			RowRanges:     []RowRange{},
+			ColumnHeaders: []string{},
+		}
+
+		for rowIdx, row := range elem.Table.TableRows {
+			rowRange := RowRange{
+				StartIndex: row.StartIndex,
+				EndIndex:   row.EndIndex,
+				CellRanges: []CellRange{},
+			}
+
+			for _, cell := range row.TableCells {
+				cellText := extractCellText(cell)
+				firstLine := cellText
+				if idx := strings.Index(cellText, "\n"); idx != -1 {
+					firstLine = cellText[:idx]
+				}
+				if len(firstLine) > 50 {
+					firstLine = firstLine[:50] + "..."
+				}
+
+				rowRange.CellRanges = append(rowRange.CellRanges, CellRange{
+					StartIndex: cell.StartIndex,
+					EndIndex:   cell.EndIndex,
+					Text:       cellText,
+					FirstLine:  firstLine,
+				})
+
+				if rowIdx == 0 {
+					tableRange.ColumnHeaders = append(tableRange.ColumnHeaders, firstLine)
+				}
+
+				for _, cellContent := range cell.Content {
+					if cellContent.Paragraph != nil {
+						for _, paraElem := range cellContent.Paragraph.Elements {
+							if paraElem.TextRun != nil {
+								result.textElements = append(result.textElements, textElementAt(paraElem))
+							} else if object, ok := objectElementAt(doc, paraElem); ok {
+								result.textElements = append(result.textElements, object)
+							}
+						}
+					}
+				}
+			}
+			tableRange.RowRanges = append(tableRange.RowRanges, rowRange)
+		}
+		result.table = tableRange
+	}
+
+	return result
+}
+
+// processSegment extracts the suggestions and structure of a header, footer or footnote.
+func processSegment(doc *docs.Document, content []*docs.StructuralElement) segmentResult {
+	var result segmentResult
+	for _, elem := range content {
+		processStructuralElement(elem, &result.suggestions)
+	}
+	result.altTextChanges = extractAltTextChanges(doc, content)
+	result.structure = buildSegmentStructure(doc, content)
+	return result
+}
+
+// appendSegmentSuggestions appends the suggestions of each segment, tagged with
+// the section and segment ID since their indices are relative to the segment.
+func appendSegmentSuggestions(suggestions []Suggestion, section string, ids []string, segments []segmentResult) []Suggestion {
+	for i, segment := range segments {
+		segmentSuggestions := append(segment.suggestions, segment.altTextChanges...)
+		for j := range segmentSuggestions {
+			segmentSuggestions[j].Section = section
+			segmentSuggestions[j].SegmentID = ids[i]
+		}
+		suggestions = append(suggestions, segmentSuggestions...)
+	}
+	return suggestions
+}
+
+// mergeElementResults combines the per-element results into the body structure,
+// numbering headings, tables and text elements in document order.
+func mergeElementResults(elements []elementResult) *DocumentStructure {
+	structure := &DocumentStructure{
+		Headings:     []DocumentHeading{},
+		Tables:       []TableRange{},
+		TextElements: []TextElementWithPosition{},
+		Lists:        []ListRange{},
+	}
+	if len(elements) == 0 {
+		return structure
+	}
+
+	var fullTextBuilder strings.Builder
+	var lastParagraphText string
+	structure.FootnoteReferences = make(map[string]int64)
+	// Item counts of each list, by nesting level
+	listCounters := make(map[string][]int)
+
+	for _, elem := range elements {
+		if elem.heading != nil {
+			heading := *elem.heading
+			heading.ID = fmt.Sprintf("heading-%d", len(structure.Headings)+1)
+			structure.Headings = append(structure.Headings, heading)
+		}
+
+		for _, text := range elem.textElements {
+			text.ID = fmt.Sprintf("text-%d", len(structure.TextElements)+1)
+			structure.TextElements = append(structure.TextElements, text)
+			fullTextBuilder.WriteString(text.Text)
+		}
+
+		if elem.paragraphText != nil {
+			lastParagraphText = *elem.paragraphText
+		}
+
+		if elem.listItem != nil {
+			item := *elem.listItem
+			counters := listCounters[item.ListID]
+			for len(counters) <= item.NestingLevel {
+				counters = append(counters, 0)
+			}
+			// A new item resets the count of the levels nested below it
+			counters = counters[:item.NestingLevel+1]
+			counters[item.NestingLevel]++
+			listCounters[item.ListID] = counters
+			item.Ordinal = counters[item.NestingLevel]
+			structure.Lists = append(structure.Lists, item)
+		}
+
+		if elem.table != nil {
+			table := *elem.table
+			table.ID = fmt.Sprintf("table-%d", len(structure.Tables)+1)
+			table.Title = lastParagraphText
+			structure.Tables = append(structure.Tables, table)
+		}
+
+		if elem.paragraphText == nil {
+			lastParagraphText = ""
+		}
+
+		for id, index := range elem.footnoteRefs {
+			structure.FootnoteReferences[id] = index
+		}
+	}
+
+	structure.FullText = fullTextBuilder.String()
+	return structure
+}
+
+// extractListItem returns the list item of a bulleted or numbered paragraph,
+// or nil if the paragraph isn't in a list.
+func extractListItem(doc *docs.Document, elem *docs.StructuralElement, text string) *ListRange {
+	bullet := elem.Paragraph.Bullet
+	if bullet == nil {
+		return nil
+	}
+
+	item := &ListRange{
+		ListID:       bullet.ListId,
+		NestingLevel: int(bullet.NestingLevel),
+		Text:         text,
+		StartIndex:   elem.StartIndex,
+		EndIndex:     elem.EndIndex,
+	}
+	// Numbered levels have a glyph type (DECIMAL, ALPHA, ...); bulleted ones a glyph symbol
+	if list, ok := doc.Lists[bullet.ListId]; ok && list.ListProperties != nil {
+		levels := list.ListProperties.NestingLevels
+		if item.NestingLevel < len(levels) {
+			glyph := levels[item.NestingLevel].GlyphType
+			item.Ordered = glyph != "" && glyph != "GLYPH_TYPE_UNSPECIFIED" && glyph != "NONE"
+		}
+	}
+	return item
+}
+
+func textElementAt(paraElem *docs.ParagraphElement) TextElementWithPosition {
+	return TextElementWithPosition{
+		Text:       paraElem.TextRun.Content,
+		StartIndex: paraElem.StartIndex,
+		EndIndex:   paraElem.EndIndex,
+		LinkURL:    runLink(paraElem.TextRun),
+	}
+}
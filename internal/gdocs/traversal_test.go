@@ -0,0 +1,68 @@
+package gdocs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// loadLargeDocument builds a large document by repeating the body of the API
+// response fixture. Indices repeat, which doesn't matter for traversal.
+func loadLargeDocument(tb testing.TB, copies int) *docs.Document {
+	tb.Helper()
+
+	docJSON, err := os.ReadFile(filepath.Join("..", "..", "test_fixtures", "doc_api_response.json"))
+	if err != nil {
+		tb.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	var doc docs.Document
+	if err := json.Unmarshal(docJSON, &doc); err != nil {
+		tb.Fatalf("Failed to unmarshal fixture: %v", err)
+	}
+
+	content := doc.Body.Content
+	doc.Body.Content = nil
+	for i := 0; i < copies; i++ {
+		doc.Body.Content = append(doc.Body.Content, content...)
+	}
+	return &doc
+}
+
+func TestExtractDocument_DeterministicAcrossWorkers(t *testing.T) {
+	doc := loadLargeDocument(t, 20)
+
+	wantSuggestions, wantStructure := ExtractDocument(doc, 1)
+	if len(wantSuggestions) == 0 || len(wantStructure.Tables) == 0 {
+		t.Fatalf("Expected suggestions and tables in the fixture, got %d and %d", len(wantSuggestions), len(wantStructure.Tables))
+	}
+
+	for _, workers := range []int{2, 8, 64} {
+		suggestions, structure := ExtractDocument(doc, workers)
+		if !reflect.DeepEqual(wantSuggestions, suggestions) {
+			t.Errorf("Suggestions with %d workers differ from a sequential traversal", workers)
+		}
+		if !reflect.DeepEqual(wantStructure, structure) {
+			t.Errorf("Structure with %d workers differs from a sequential traversal", workers)
+		}
+	}
+}
+
+func BenchmarkExtractDocument(b *testing.B) {
+	doc := loadLargeDocument(b, 500)
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ExtractDocument(doc, 1)
+		}
+	})
+	b.Run("worker-pool", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ExtractDocument(doc, DefaultExtractionWorkers)
+		}
+	})
+}
@@ -2,10 +2,68 @@ package gdocs
 
 type Suggestion struct {
 	ID         string `json:"id"`
-	Type       string `json:"type"` // "insertion", "deletion", or "text_style_change"
+	Type       string `json:"type"` // "insertion", "deletion", "text_style_change", "paragraph_change" or "alt_text_change"
 	Content    string `json:"content"`
 	StartIndex int64  `json:"start_index"`
 	EndIndex   int64  `json:"end_index"`
+
+	// OriginalContent is the content before the change, for changes that aren't
+	// an insertion or deletion of text (e.g. the previous alt text of an image)
+	OriginalContent string `json:"original_content,omitempty"`
+
+	// Element is set for suggestions on non-text elements: "inline_object",
+	// "equation" or "footnote_reference". Content is then a placeholder.
+	Element  string `json:"element,omitempty"`
+	ObjectID string `json:"object_id,omitempty"` // Inline object ID for "inline_object" elements
+
+	// Section and SegmentID identify suggestions outside the body: "Header",
+	// "Footer" or "Footnote" and the segment's ID. Indices are relative to that segment.
+	Section   string `json:"section,omitempty"`
+	SegmentID string `json:"segment_id,omitempty"`
+
+	// Style is the formatting change of a "text_style_change" suggestion
+	Style *StyleDelta `json:"style,omitempty"`
+
+	// LinkURL is the link target of the suggested text, if it's linked
+	LinkURL string `json:"link_url,omitempty"`
+
+	// NamedStyle is the paragraph style (e.g. "HEADING_3") before and after a
+	// "paragraph_change", or of a whole paragraph that is inserted or deleted
+	NamedStyle *StyleProperty `json:"named_style,omitempty"`
+
+	// Paragraph is true when an insertion or deletion covers a whole paragraph
+	Paragraph bool `json:"paragraph,omitempty"`
+
+	SuggestionAuthor
+}
+
+// SuggestionAuthor records who made a suggestion and when, where the source
+// provides it. The Docs API doesn't, so Google Docs suggestions are only
+// attributed when the revision history shows a single editor.
+type SuggestionAuthor struct {
+	Author      string `json:"author,omitempty"`
+	AuthorEmail string `json:"author_email,omitempty"`
+	CreatedTime string `json:"created_time,omitempty"`
+}
+
+// StyleProperty is the value of a style property before and after a suggestion.
+// An empty value means the property is unset (e.g. not bold, no link).
+type StyleProperty struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// StyleDelta lists the style properties changed by a suggestion. Only the
+// properties the suggestion touches are set.
+type StyleDelta struct {
+	Bold            *StyleProperty `json:"bold,omitempty"`
+	Italic          *StyleProperty `json:"italic,omitempty"`
+	Underline       *StyleProperty `json:"underline,omitempty"`
+	Strikethrough   *StyleProperty `json:"strikethrough,omitempty"`
+	Link            *StyleProperty `json:"link,omitempty"`
+	FontSize        *StyleProperty `json:"font_size,omitempty"`
+	ForegroundColor *StyleProperty `json:"foreground_color,omitempty"`
+	BackgroundColor *StyleProperty `json:"background_color,omitempty"`
 }
 
 // DocumentHeading represents a heading in the document with its position.
@@ -36,8 +94,24 @@ type SuggestionLocation struct {
 	ParentHeading string         `json:"parent_heading,omitempty"` // Nearest heading above
 	HeadingLevel  int            `json:"heading_level,omitempty"`  // Level of parent heading (1-6)
 	InTable       bool           `json:"in_table"`
-	Table         *TableLocation `json:"table,omitempty"` // Table details if in a table
-	InMetadata    bool           `json:"in_metadata"`     // True if in the metadata table
+	Table         *TableLocation `json:"table,omitempty"`          // Table details if in a table
+	InMetadata    bool           `json:"in_metadata"`              // True if in the metadata table
+	SegmentID     string         `json:"segment_id,omitempty"`     // Header, footer or footnote ID when outside the body
+	Element       string         `json:"element,omitempty"`        // "image_alt_text" for alt text changes
+	List          *ListLocation  `json:"list,omitempty"`           // List item details if in a bulleted or numbered list
+	PageURL       string         `json:"page_url,omitempty"`       // URL of the page section, in documents that cover several pages
+	NearObject    string         `json:"near_object,omitempty"`    // Non-text element next to the suggestion, e.g. "image", which its anchors stop at
+	Tab           string         `json:"tab,omitempty"`            // Title of the tab, in documents with several tabs
+	MetadataTable string         `json:"metadata_table,omitempty"` // Title of the metadata table, when InMetadata
+}
+
+// ListLocation describes the list item a suggestion is in.
+type ListLocation struct {
+	ListID       string `json:"list_id"`
+	NestingLevel int    `json:"nesting_level"` // 0 for top-level items
+	Ordinal      int    `json:"ordinal"`       // Position among the items at this level (1-based)
+	Ordered      bool   `json:"ordered"`       // True for numbered lists
+	ItemText     string `json:"item_text"`     // Text of the list item
 }
 
 // SuggestionAnchor contains the exact text before and after a suggestion.
@@ -53,18 +127,48 @@ type SuggestionAnchor struct {
 	// For insertions: text after where new content should be inserted.
 	// For deletions: text after the content to be deleted.
 	FollowingText string `json:"following_text"`
+
+	// NormalizedPrecedingText and NormalizedFollowingText are the anchors
+	// after normalization (see Normalization), for matching rendered HTML
+	NormalizedPrecedingText string `json:"normalized_preceding_text,omitempty"`
+	NormalizedFollowingText string `json:"normalized_following_text,omitempty"`
 }
 
 // SuggestionChange describes exactly what text change should be made.
 type SuggestionChange struct {
-	// Type is the operation: "insert", "delete", or "replace"
+	// Type is the operation: "insert", "delete", "replace", "style",
+	// "paragraph" for paragraph style changes, "move" for text deleted in one
+	// place and inserted elsewhere, or "comment_instruction" for reviewer
+	// comments that ask for a change
 	Type string `json:"type"`
 
 	// OriginalText is the text currently in the document (empty for pure insertions)
 	OriginalText string `json:"original_text,omitempty"`
 
+	// NormalizedOriginalText is OriginalText after normalization
+	NormalizedOriginalText string `json:"normalized_original_text,omitempty"`
+
 	// NewText is the text that should replace/be inserted (empty for pure deletions)
 	NewText string `json:"new_text,omitempty"`
+
+	// OriginalLinkURL and NewLinkURL are the link targets of the original and
+	// new text, when it's linked
+	OriginalLinkURL string `json:"original_link_url,omitempty"`
+	NewLinkURL      string `json:"new_link_url,omitempty"`
+
+	// NamedStyle is the paragraph style before and after a "paragraph"
+	// change, e.g. NORMAL_TEXT to HEADING_3. For whole paragraphs that are
+	// inserted or deleted it's the style of the paragraph.
+	NamedStyle *StyleProperty `json:"named_style,omitempty"`
+
+	// Paragraph is true when the change inserts or deletes whole paragraphs
+	Paragraph bool `json:"paragraph,omitempty"`
+
+	// Style describes the formatting change for "style" changes
+	Style *StyleDelta `json:"style,omitempty"`
+
+	// Instruction is the reviewer's comment for "comment_instruction" changes
+	Instruction string `json:"instruction,omitempty"`
 }
 
 // SuggestionVerification shows the before/after state for validation.
@@ -74,6 +178,11 @@ type SuggestionVerification struct {
 
 	// TextAfterChange shows what the text should look like after applying the suggestion
 	TextAfterChange string `json:"text_after_change"`
+
+	// NormalizedTextBeforeChange and NormalizedTextAfterChange are the
+	// verification texts after normalization
+	NormalizedTextBeforeChange string `json:"normalized_text_before_change,omitempty"`
+	NormalizedTextAfterChange  string `json:"normalized_text_after_change,omitempty"`
 }
 
 // ActionableSuggestion provides all context needed for an LLM to find and apply a suggestion.
@@ -95,6 +204,10 @@ type ActionableSuggestion struct {
 	// Anchor contains exact text before/after for locating where to apply the change
 	Anchor SuggestionAnchor `json:"anchor"`
 
+	// AnchorUnique is true when the anchor and original text occur exactly
+	// once in the document text, so matching it is unambiguous
+	AnchorUnique bool `json:"anchor_unique"`
+
 	// Change describes exactly what modification to make
 	Change SuggestionChange `json:"change"`
 
@@ -109,6 +222,9 @@ type ActionableSuggestion struct {
 		StartIndex int64 `json:"start_index"`
 		EndIndex   int64 `json:"end_index"`
 	} `json:"position"`
+
+	// SuggestionAuthor is who made the suggestion, when known
+	SuggestionAuthor
 }
 
 // GroupedActionableSuggestion represents one or more atomic suggestions that belong together.
@@ -123,6 +239,10 @@ type GroupedActionableSuggestion struct {
 	// Uses larger context (120 chars) to account for multi-part changes
 	Anchor SuggestionAnchor `json:"anchor"`
 
+	// AnchorUnique is true when the anchor and original text occur exactly
+	// once in the document text, so matching it is unambiguous
+	AnchorUnique bool `json:"anchor_unique"`
+
 	// Change describes the complete, merged modification to make
 	Change SuggestionChange `json:"change"`
 
@@ -140,17 +260,54 @@ type GroupedActionableSuggestion struct {
 
 	// AtomicCount indicates how many operations were merged (1 for non-grouped suggestions)
 	AtomicCount int `json:"atomic_count"`
+
+	// MergedIDs are the IDs of other suggestions in the same sentence that
+	// were merged into this one (see MergeSentences)
+	MergedIDs []string `json:"merged_ids,omitempty"`
+
+	// Stale is true when the anchors could not be found in the latest published page
+	Stale bool `json:"stale,omitempty"`
+
+	// MovedFrom is where the text of a "move" change is deleted; Anchor is
+	// where it's inserted
+	MovedFrom *MoveSource `json:"moved_from,omitempty"`
+
+	// SuggestionAuthor is who made the suggestion, when known
+	SuggestionAuthor
+}
+
+// MoveSource is the original place of moved text.
+type MoveSource struct {
+	Location SuggestionLocation `json:"location"`
+	Anchor   SuggestionAnchor   `json:"anchor"`
 }
 
 // LocationGroupedSuggestions represents suggestions grouped first by location, then by suggestion ID.
 // This structure makes it easier to process suggestions in a logical order - handling all
 // suggestions in one location before moving to the next.
 type LocationGroupedSuggestions struct {
+	// LocationID is a stable identifier of the location, a hash of LocationKey
+	LocationID string `json:"location_id,omitempty"`
+
+	// LocationKey is the key suggestions were grouped by, for debugging
+	LocationKey string `json:"location_key,omitempty"`
+
+	// ResolvedFile is the file of the target repository that contains the
+	// anchors of these suggestions, relative to its root, when one was found
+	ResolvedFile string `json:"resolved_file,omitempty"`
+
+	// Patterns are the Vanilla patterns around the anchors in ResolvedFile,
+	// outermost first, e.g. "hero" or "tabs"
+	Patterns []string `json:"patterns,omitempty"`
+
 	// Location provides contextual metadata for this group
 	Location SuggestionLocation `json:"location"`
 
 	// Suggestions contains all grouped suggestions for this location
 	Suggestions []GroupedActionableSuggestion `json:"suggestions"`
+
+	// Comments are the reviewer comments anchored at this location
+	Comments []Comment `json:"comments,omitempty"`
 }
 
 // DocumentStructure holds the parsed structure of the document for context lookups
@@ -159,6 +316,32 @@ type DocumentStructure struct {
 	Tables       []TableRange              `json:"tables"`
 	FullText     string                    `json:"full_text"`     // Complete document text
 	TextElements []TextElementWithPosition `json:"text_elements"` // All text with positions
+	Lists        []ListRange               `json:"lists"`         // Bulleted and numbered list items
+
+	// Segments holds the text of segments with their own index space (headers,
+	// footers and footnotes), by segment ID
+	Segments map[string]*DocumentStructure `json:"-"`
+
+	// FootnoteReferences maps footnote IDs to the body index of their reference
+	FootnoteReferences map[string]int64 `json:"-"`
+
+	// Pages are the sections of a document that covers several pages
+	Pages []PageSection `json:"pages,omitempty"`
+
+	// Tabs are the ranges of the tabs of a document with several tabs
+	Tabs []TabRange `json:"tabs,omitempty"`
+}
+
+// ListRange represents a list item paragraph in the document. The ordinal
+// restarts under each item of the level above, so nested items count from 1.
+type ListRange struct {
+	ListID       string `json:"list_id"`
+	NestingLevel int    `json:"nesting_level"`
+	Ordinal      int    `json:"ordinal"`
+	Ordered      bool   `json:"ordered"`
+	Text         string `json:"text"`
+	StartIndex   int64  `json:"start_index"`
+	EndIndex     int64  `json:"end_index"`
 }
 
 // TableRange represents a table's position in the document
@@ -192,6 +375,11 @@ type TextElementWithPosition struct {
 	Text       string `json:"text"`
 	StartIndex int64  `json:"start_index"`
 	EndIndex   int64  `json:"end_index"`
+	LinkURL    string `json:"link_url,omitempty"` // Link target if the text is linked
+
+	// Object is the kind of non-text element, e.g. "image", when Text is
+	// ObjectPlaceholder
+	Object string `json:"object,omitempty"`
 }
 
 // Comment represents a comment on the document (from Drive API)
@@ -206,6 +394,15 @@ type Comment struct {
 	Resolved        bool     `json:"resolved"`
 	Replies         []Reply  `json:"replies,omitempty"`
 	MentionedEmails []string `json:"mentioned_emails,omitempty"`
+
+	// Anchor is the raw Drive anchor of the comment
+	Anchor string `json:"anchor,omitempty"`
+
+	// Location, StartIndex and EndIndex place the comment in the document body.
+	// Location is nil when the comment couldn't be resolved to a range.
+	Location   *SuggestionLocation `json:"location,omitempty"`
+	StartIndex int64               `json:"start_index,omitempty"`
+	EndIndex   int64               `json:"end_index,omitempty"`
 }
 
 // Reply represents a reply to a comment
@@ -217,9 +414,11 @@ type Reply struct {
 	CreatedTime string `json:"created_time"`
 }
 
-// MetadataTable represents the metadata table at the beginning of a document.
-// This table contains key-value pairs with document metadata such as title,
-// description, and other custom fields defined by the document template.
+// MetadataTable represents a metadata table of a document. The first one, at
+// the beginning of the document, contains key-value pairs with page metadata
+// such as title, description, and other custom fields defined by the document
+// template. Some templates have more, e.g. for SEO, the hero section or
+// localization, each titled by the paragraph before it.
 //
 // Structure expected:
 //
@@ -228,6 +427,10 @@ type Reply struct {
 //	| Page description (160 chars max)  | Ubuntu is the operating system...  |
 //	| ...                               | ...                                |
 type MetadataTable struct {
+	// Title is the text of the paragraph before the table, e.g. "SEO", or
+	// its header when that names it, e.g. "SEO metadata"
+	Title string `json:"title,omitempty"`
+
 	// Raw contains all key-value pairs from the metadata table
 	Raw map[string]string `json:"raw"`
 
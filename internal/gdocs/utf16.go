@@ -0,0 +1,53 @@
+package gdocs
+
+import "unicode/utf8"
+
+// Document indices count UTF-16 code units, while Go strings are sliced by
+// bytes: text with emoji or outside the Latin alphabet has to be mapped from
+// one to the other before it's sliced.
+
+// utf16Len returns the length of text in UTF-16 code units.
+func utf16Len(text string) int64 {
+	var n int64
+	for _, r := range text {
+		n += utf16RuneLen(r)
+	}
+	return n
+}
+
+// byteOffset returns the byte offset of the UTF-16 offset units into text,
+// clamped to the text. An offset inside a surrogate pair stays before the
+// character, so text is never split inside one.
+func byteOffset(text string, units int64) int {
+	if units <= 0 {
+		return 0
+	}
+	var n int64
+	for i, r := range text {
+		n += utf16RuneLen(r)
+		if n > units {
+			return i
+		}
+		if n == units {
+			return i + utf8.RuneLen(r)
+		}
+	}
+	return len(text)
+}
+
+// lastUnits returns the end of text that is at most units UTF-16 code units long.
+func lastUnits(text string, units int) string {
+	return text[byteOffset(text, utf16Len(text)-int64(units)):]
+}
+
+// firstUnits returns the start of text that is at most units UTF-16 code units long.
+func firstUnits(text string, units int) string {
+	return text[:byteOffset(text, int64(units))]
+}
+
+func utf16RuneLen(r rune) int64 {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
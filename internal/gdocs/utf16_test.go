@@ -0,0 +1,40 @@
+package gdocs
+
+import "testing"
+
+func TestByteOffset(t *testing.T) {
+	tests := []struct {
+		text  string
+		units int64
+		want  int
+	}{
+		{text: "hello", units: 2, want: 2},
+		{text: "日本語", units: 2, want: 6},
+		{text: "a😀b", units: 3, want: 5},
+		// Inside the surrogate pair of 😀
+		{text: "a😀b", units: 2, want: 1},
+		{text: "a😀b", units: 10, want: 6},
+		{text: "a😀b", units: -1, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := byteOffset(tt.text, tt.units); got != tt.want {
+			t.Errorf("byteOffset(%q, %d) = %d, want %d", tt.text, tt.units, got, tt.want)
+		}
+	}
+}
+
+func TestTextBetween_UTF16(t *testing.T) {
+	structure := &DocumentStructure{
+		TextElements: []TextElementWithPosition{
+			{Text: "Olá 👋 ", StartIndex: 1, EndIndex: 8},
+			{Text: "世界\n", StartIndex: 8, EndIndex: 11},
+		},
+	}
+	if got := textBetween(structure, 5, 10); got != "👋 世界" {
+		t.Errorf("textBetween() = %q, want %q", got, "👋 世界")
+	}
+	if got := utf16Len("Olá 👋 "); got != 7 {
+		t.Errorf("utf16Len() = %d, want 7", got)
+	}
+}
@@ -0,0 +1,88 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PublishFiles commits files to a branch of the remote, next to the files the
+// branch already holds, and returns the commit. The checkout and the index
+// of the local repository are left alone. It's how PR attachments such as
+// screenshots are uploaded, since GitHub has no API to attach images to
+// comments. files maps paths in the branch to local files.
+func PublishFiles(localPath, branch string, files map[string]string, message string) (string, error) {
+	index, err := os.CreateTemp("", "bauer-index-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	index.Close()
+	// Git expects the index to be missing or valid, not empty
+	os.Remove(index.Name())
+	defer os.Remove(index.Name())
+	env := []string{"GIT_INDEX_FILE=" + index.Name()}
+
+	// Keep the files of earlier runs
+	parent := ""
+	remote, err := runGit(localPath, "ls-remote", "--heads", "origin", branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up branch %s: %w", branch, err)
+	}
+	if strings.TrimSpace(remote) != "" {
+		ref := "refs/bauer/" + branch
+		if _, err := runGit(localPath, "fetch", "origin", "+refs/heads/"+branch+":"+ref); err != nil {
+			return "", fmt.Errorf("failed to fetch branch %s: %w", branch, err)
+		}
+		if _, err := runGitEnv(localPath, env, "read-tree", ref); err != nil {
+			return "", fmt.Errorf("failed to read branch %s: %w", branch, err)
+		}
+		parent = ref
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		source, err := filepath.Abs(files[path])
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", files[path], err)
+		}
+		blob, err := runGit(localPath, "hash-object", "-w", source)
+		if err != nil {
+			return "", fmt.Errorf("failed to store %s: %w", files[path], err)
+		}
+		info := fmt.Sprintf("100644,%s,%s", strings.TrimSpace(blob), filepath.ToSlash(path))
+		if _, err := runGitEnv(localPath, env, "update-index", "--add", "--cacheinfo", info); err != nil {
+			return "", fmt.Errorf("failed to add %s: %w", path, err)
+		}
+	}
+
+	tree, err := runGitEnv(localPath, env, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to write tree: %w", err)
+	}
+	args := []string{"commit-tree", strings.TrimSpace(tree), "-m", message}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	commit, err := runGit(localPath, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to commit files: %w", err)
+	}
+	commit = strings.TrimSpace(commit)
+
+	if _, err := runGit(localPath, "push", "origin", commit+":refs/heads/"+branch); err != nil {
+		return "", fmt.Errorf("failed to push branch %s: %w", branch, err)
+	}
+	return commit, nil
+}
+
+// FileURL returns the URL that serves a file of a commit as is, e.g. to show
+// an image published with PublishFiles in a PR comment.
+func FileURL(host, owner, repo, commit, path string) string {
+	return fmt.Sprintf("https://%s/%s/%s/blob/%s/%s?raw=true", ResolveHost(host), owner, repo, commit, path)
+}
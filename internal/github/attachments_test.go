@@ -0,0 +1,76 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPublishFiles(t *testing.T) {
+	origin := newOrigin(t)
+	local := filepath.Join(t.TempDir(), "repo")
+	if err := CloneOrUpdateRepo(&Repository{HTTPURL: origin}, local, CloneOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// Uncommitted changes of the run must be left alone
+	if err := os.WriteFile(filepath.Join(local, "index.html"), []byte("<h1>Ubuntu Pro</h1>\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	shot := filepath.Join(t.TempDir(), "index-after.png")
+	if err := os.WriteFile(shot, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	first, err := PublishFiles(local, "bauer/screenshots", map[string]string{"run1/index-after.png": shot}, "First run")
+	if err != nil {
+		t.Fatalf("PublishFiles() error = %v", err)
+	}
+	second, err := PublishFiles(local, "bauer/screenshots", map[string]string{"run2/index-after.png": shot}, "Second run")
+	if err != nil {
+		t.Fatalf("PublishFiles() on existing branch error = %v", err)
+	}
+
+	files, err := runGit(origin, "ls-tree", "-r", "--name-only", "bauer/screenshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files != "run1/index-after.png\nrun2/index-after.png\n" {
+		t.Errorf("Published files = %q, want those of both runs", files)
+	}
+	if parent, _ := runGit(origin, "rev-parse", second+"^"); strings.TrimSpace(parent) != first {
+		t.Errorf("Parent of second commit = %q, want %q", parent, first)
+	}
+	if status, _ := GetStatus(local); status != " M index.html\n" {
+		t.Errorf("Status after publishing = %q, want only the run's change", status)
+	}
+}
+
+func TestStashChanges(t *testing.T) {
+	origin := newOrigin(t)
+	local := filepath.Join(t.TempDir(), "repo")
+	if err := CloneOrUpdateRepo(&Repository{HTTPURL: origin}, local, CloneOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if stashed, err := StashChanges(local); err != nil || stashed {
+		t.Errorf("StashChanges() without changes = %v, %v", stashed, err)
+	}
+
+	path := filepath.Join(local, "index.html")
+	if err := os.WriteFile(path, []byte("<h1>Ubuntu Pro</h1>\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if stashed, err := StashChanges(local); err != nil || !stashed {
+		t.Fatalf("StashChanges() = %v, %v", stashed, err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "<h1>Ubuntu</h1>\n" {
+		t.Errorf("index.html while stashed = %q", content)
+	}
+	if err := RestoreChanges(local); err != nil {
+		t.Fatalf("RestoreChanges() error = %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "<h1>Ubuntu Pro</h1>\n" {
+		t.Errorf("index.html after restoring = %q", content)
+	}
+}
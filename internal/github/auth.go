@@ -7,8 +7,19 @@ import (
 	"strings"
 )
 
-// GetGitHubToken retrieves a GitHub token from environment variables or gh CLI
-func GetGitHubToken() (string, error) {
+// GetGitHubToken retrieves a GitHub token for host (github.com when empty)
+// from environment variables or gh CLI. GitHub Enterprise Server tokens are
+// read from GH_ENTERPRISE_TOKEN or GITHUB_ENTERPRISE_TOKEN first.
+func GetGitHubToken(host string) (string, error) {
+	host = ResolveHost(host)
+	if isEnterpriseHost(host) {
+		if token := os.Getenv("GH_ENTERPRISE_TOKEN"); token != "" {
+			return token, nil
+		}
+		if token := os.Getenv("GITHUB_ENTERPRISE_TOKEN"); token != "" {
+			return token, nil
+		}
+	}
 	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
 		return token, nil
 	}
@@ -17,7 +28,7 @@ func GetGitHubToken() (string, error) {
 	}
 
 	// Get token from gh CLI config
-	cmd := exec.Command("gh", "auth", "token")
+	cmd := exec.Command("gh", "auth", "token", "--hostname", host)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("failed to get GitHub token from gh CLI: %w", err)
@@ -31,16 +42,18 @@ func GetGitHubToken() (string, error) {
 	return token, nil
 }
 
-// ValidateGitHubAuth checks if GitHub authentication is configured
-func ValidateGitHubAuth() error {
+// ValidateGitHubAuth checks if GitHub authentication is configured for host
+func ValidateGitHubAuth(host string) error {
+	host = ResolveHost(host)
+
 	// Get token
-	_, err := GetGitHubToken()
+	_, err := GetGitHubToken(host)
 	if err != nil {
 		return fmt.Errorf("GitHub authentication not configured: %w", err)
 	}
 
 	// Authenticate token
-	cmd := exec.Command("gh", "auth", "status")
+	cmd := exec.Command("gh", "auth", "status", "--hostname", host)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to verify GitHub authentication: %w, output: %s", err, output)
@@ -49,8 +62,9 @@ func ValidateGitHubAuth() error {
 	return nil
 }
 
-// SetupGitHubAuth configures GitHub authentication for the current shell session
-func SetupGitHubAuth(token string) error {
+// SetupGitHubAuth configures GitHub authentication for the current shell session.
+// For hosts other than github.com, gh is pointed at the host with GH_HOST.
+func SetupGitHubAuth(token, host string) error {
 	if token == "" {
 		return fmt.Errorf("token cannot be empty")
 	}
@@ -65,6 +79,18 @@ func SetupGitHubAuth(token string) error {
 		return fmt.Errorf("failed to set GH_TOKEN: %w", err)
 	}
 
+	if host == "" || host == DefaultHost {
+		return nil
+	}
+	if err := os.Setenv("GH_HOST", host); err != nil {
+		return fmt.Errorf("failed to set GH_HOST: %w", err)
+	}
+	if isEnterpriseHost(host) {
+		if err := os.Setenv("GH_ENTERPRISE_TOKEN", token); err != nil {
+			return fmt.Errorf("failed to set GH_ENTERPRISE_TOKEN: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,192 @@
+package github
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// codeOwnersLocations are the paths GitHub checks for a CODEOWNERS file, in order
+var codeOwnersLocations = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// CodeOwnersRule is a single pattern line from a CODEOWNERS file
+type CodeOwnersRule struct {
+	Pattern string
+	Owners  []string
+	regex   *regexp.Regexp
+}
+
+// LoadCodeOwners reads and parses the CODEOWNERS file of a repository.
+// Returns nil rules (and no error) when the repository has no CODEOWNERS file.
+func LoadCodeOwners(localPath string) ([]CodeOwnersRule, error) {
+	for _, location := range codeOwnersLocations {
+		data, err := os.ReadFile(filepath.Join(localPath, location))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", location, err)
+		}
+		return ParseCodeOwners(string(data)), nil
+	}
+	return nil, nil
+}
+
+// ParseCodeOwners parses the contents of a CODEOWNERS file.
+// Comments, blank lines and invalid patterns are skipped.
+func ParseCodeOwners(content string) []CodeOwnersRule {
+	var rules []CodeOwnersRule
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		regex, err := codeOwnersPatternToRegex(fields[0])
+		if err != nil {
+			continue
+		}
+
+		rules = append(rules, CodeOwnersRule{
+			Pattern: fields[0],
+			Owners:  fields[1:],
+			regex:   regex,
+		})
+	}
+
+	return rules
+}
+
+// OwnersFor returns the owners of a repository-relative path.
+// As on GitHub, the last matching rule takes precedence.
+func OwnersFor(rules []CodeOwnersRule, path string) []string {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].regex.MatchString(path) {
+			return rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// MatchPattern reports whether a repository-relative path matches a
+// gitignore-style pattern, as CODEOWNERS patterns match, e.g. "*.py" or
+// ".github/**".
+func MatchPattern(pattern, path string) bool {
+	regex, err := codeOwnersPatternToRegex(pattern)
+	if err != nil {
+		return false
+	}
+	return regex.MatchString(strings.TrimPrefix(filepath.ToSlash(path), "/"))
+}
+
+// ResolveFileOwners maps each file to its owners, omitting unowned files
+func ResolveFileOwners(rules []CodeOwnersRule, files []string) map[string][]string {
+	owners := make(map[string][]string)
+	for _, file := range files {
+		if fileOwners := OwnersFor(rules, file); len(fileOwners) > 0 {
+			owners[file] = fileOwners
+		}
+	}
+	return owners
+}
+
+// ReviewersFromOwners converts CODEOWNERS owners into gh reviewer arguments.
+// "@user" and "@org/team" become "user" and "org/team"; email owners are skipped
+// since they can't be requested as reviewers directly.
+func ReviewersFromOwners(fileOwners map[string][]string) []string {
+	seen := make(map[string]bool)
+	var reviewers []string
+	for _, owners := range fileOwners {
+		for _, owner := range owners {
+			if !strings.HasPrefix(owner, "@") {
+				continue
+			}
+			reviewer := strings.TrimPrefix(owner, "@")
+			if !seen[reviewer] {
+				seen[reviewer] = true
+				reviewers = append(reviewers, reviewer)
+			}
+		}
+	}
+	sort.Strings(reviewers)
+	return reviewers
+}
+
+// GetChangedFiles lists files changed on the current branch compared to baseBranch
+func GetChangedFiles(localPath, baseBranch string) ([]string, error) {
+	output, err := runGit(localPath, "diff", "--name-only", baseBranch+"...HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			files = append(files, trimmed)
+		}
+	}
+	return files, nil
+}
+
+// codeOwnersPatternToRegex converts a gitignore-style CODEOWNERS pattern into a regex
+func codeOwnersPatternToRegex(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	// Patterns containing a slash are relative to the repository root
+	if strings.Contains(pattern, "/") {
+		anchored = true
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				// "**/" matches zero or more directories, "**" matches anything
+				if i+2 < len(pattern) && pattern[i+2] == '/' {
+					sb.WriteString("(?:.*/)?")
+					i += 2
+				} else {
+					sb.WriteString(".*")
+					i++
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	prefix := "^(?:.*/)?"
+	if anchored {
+		prefix = "^"
+	}
+	// A match on a directory also owns everything below it
+	suffix := "(?:/.*)?$"
+	if dirOnly {
+		suffix = "/.*$"
+	}
+
+	return regexp.Compile(prefix + sb.String() + suffix)
+}
@@ -0,0 +1,54 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOwnersFor(t *testing.T) {
+	rules := ParseCodeOwners(`
+# Default owners
+*                       @canonical/web
+
+*.md                    docs@example.com
+/templates/             @canonical/content # inline comment
+templates/legal/        @canonical/legal
+static/**/*.svg         @designer
+navigation.yaml         @canonical/nav @lead
+`)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"webapp/app.py", []string{"@canonical/web"}},
+		{"README.md", []string{"docs@example.com"}},
+		{"templates/desktop/index.html", []string{"@canonical/content"}},
+		{"other/templates/index.html", []string{"@canonical/web"}},
+		{"templates/legal/terms.html", []string{"@canonical/legal"}},
+		{"static/img/logos/ubuntu.svg", []string{"@designer"}},
+		{"static/ubuntu.svg", []string{"@designer"}},
+		{"config/navigation.yaml", []string{"@canonical/nav", "@lead"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, OwnersFor(rules, tt.path)); diff != "" {
+				t.Errorf("OwnersFor(%q) mismatch (-want +got):\n%s", tt.path, diff)
+			}
+		})
+	}
+}
+
+func TestReviewersFromOwners(t *testing.T) {
+	fileOwners := map[string][]string{
+		"templates/a.html": {"@canonical/content", "docs@example.com"},
+		"templates/b.html": {"@canonical/content", "@lead"},
+	}
+
+	want := []string{"canonical/content", "lead"}
+	if diff := cmp.Diff(want, ReviewersFromOwners(fileOwners)); diff != "" {
+		t.Errorf("ReviewersFromOwners() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,90 @@
+package github
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrGitNotInstalled is returned when the git binary can't be found, e.g. in
+// a minimal container image.
+var ErrGitNotInstalled = errors.New("git not installed")
+
+// runGit runs a git command in dir and returns its output. Git never
+// prompts for credentials, so a missing token fails instead of hanging.
+// Errors include the command and its output.
+func runGit(dir string, args ...string) (string, error) {
+	return runGitEnv(dir, nil, args...)
+}
+
+// runGitEnv is runGit with extra environment variables, e.g. GIT_INDEX_FILE.
+func runGitEnv(dir string, env []string, args ...string) (string, error) {
+	path, err := exec.LookPath("git")
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrGitNotInstalled, err)
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Dir = dir
+	cmd.Env = append(append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), authEnv()...), env...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("git %s: %w, output: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+var (
+	gitTokensMu sync.Mutex
+	gitTokens   = map[string]string{}
+)
+
+// setGitAuth makes the git operations of this package send token to host
+// over HTTPS. The token is kept in memory: neither written to disk nor set in
+// the environment of the process, which other commands would inherit.
+// Setting the token of a host again replaces it; an empty token removes it.
+func setGitAuth(token, host string) {
+	gitTokensMu.Lock()
+	defer gitTokensMu.Unlock()
+	if token == "" {
+		delete(gitTokens, host)
+		return
+	}
+	gitTokens[host] = token
+}
+
+// gitToken returns the token of host, or "".
+func gitToken(host string) string {
+	gitTokensMu.Lock()
+	defer gitTokensMu.Unlock()
+	return gitTokens[host]
+}
+
+// authEnv returns the GIT_CONFIG_* environment variables of a git command
+// sending the tokens of setGitAuth, after the ones already in the
+// environment.
+func authEnv() []string {
+	gitTokensMu.Lock()
+	defer gitTokensMu.Unlock()
+	if len(gitTokens) == 0 {
+		return nil
+	}
+	count, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	var env []string
+	for _, host := range slices.Sorted(maps.Keys(gitTokens)) {
+		header := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + gitTokens[host]))
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=http.https://%s/.extraheader", count, host),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=AUTHORIZATION: basic %s", count, header),
+		)
+		count++
+	}
+	return append(env, "GIT_CONFIG_COUNT="+strconv.Itoa(count))
+}
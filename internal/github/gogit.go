@@ -0,0 +1,342 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// Clone, fetch, pull, branch, commit and push run natively with go-git, so they
+// work without the git binary, e.g. in a minimal container image. Whatever
+// go-git can't do, e.g. sparse checkouts and partial clones, or fails at,
+// runs the git binary instead, when it's installed.
+
+// errNotNative is returned by the native operations on repositories go-git
+// can't work with.
+var errNotNative = errors.New("not supported by go-git")
+
+// remoteName is the remote repositories are cloned from.
+const remoteName = "origin"
+
+// withFallback runs native, and fallback when native fails and git is
+// installed. ErrNoChanges isn't a failure.
+func withFallback(op string, native, fallback func() error) error {
+	err := native()
+	if err == nil || errors.Is(err, ErrNoChanges) {
+		return err
+	}
+	if _, lookErr := exec.LookPath("git"); lookErr != nil && !errors.Is(err, errNotNative) {
+		return err
+	}
+	slog.Debug("go-git failed, running git", slog.String("op", op), slog.String("error", err.Error()))
+	return fallback()
+}
+
+// openNative opens the repository at dir with go-git, unless it's a sparse
+// checkout or a partial clone, whose missing files and objects go-git would
+// take for deletions.
+func openNative(dir string) (*git.Repository, error) {
+	r, err := git.PlainOpen(dir)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := r.Config()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Raw.Section("core").Option("sparseCheckout") == "true" ||
+		cfg.Raw.Section("extensions").Option("partialClone") != "" {
+		return nil, errNotNative
+	}
+	return r, nil
+}
+
+// nativeAuth returns the token auth of the host of rawURL, nil for hosts
+// without a token and remotes other than HTTPS.
+func nativeAuth(rawURL string) transport.AuthMethod {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" {
+		return nil
+	}
+	token := gitToken(u.Host)
+	if token == "" {
+		return nil
+	}
+	return &http.BasicAuth{Username: "x-access-token", Password: token}
+}
+
+// remoteAuth returns the token auth of the remote of r.
+func remoteAuth(r *git.Repository) (transport.AuthMethod, error) {
+	remote, err := r.Remote(remoteName)
+	if err != nil {
+		return nil, err
+	}
+	if urls := remote.Config().URLs; len(urls) > 0 {
+		return nativeAuth(urls[0]), nil
+	}
+	return nil, nil
+}
+
+// cloneNative clones rawURL into localPath. A failed clone is removed, for
+// the git binary to try again.
+func cloneNative(rawURL, localPath string, shallow bool) error {
+	opts := &git.CloneOptions{URL: rawURL, RemoteName: remoteName, Auth: nativeAuth(rawURL)}
+	if shallow {
+		opts.Depth = 1
+		opts.SingleBranch = true
+	}
+	r, err := git.PlainClone(localPath, false, opts)
+	if err != nil {
+		os.RemoveAll(localPath)
+		return err
+	}
+
+	// Recorded as git does, for the default branch to be found
+	head, err := r.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return nil
+	}
+	originHead := plumbing.NewSymbolicReference(
+		plumbing.NewRemoteHEADReferenceName(remoteName),
+		plumbing.NewRemoteReferenceName(remoteName, head.Name().Short()),
+	)
+	return r.Storer.SetReference(originHead)
+}
+
+// fetchNative fetches refspecs from the remote of localPath, or the ones of
+// its configuration when there are none.
+func fetchNative(localPath string, shallow bool, refspecs ...config.RefSpec) error {
+	r, err := openNative(localPath)
+	if err != nil {
+		return err
+	}
+	return fetchRepo(r, shallow, refspecs...)
+}
+
+func fetchRepo(r *git.Repository, shallow bool, refspecs ...config.RefSpec) error {
+	auth, err := remoteAuth(r)
+	if err != nil {
+		return err
+	}
+	opts := &git.FetchOptions{RemoteName: remoteName, RefSpecs: refspecs, Auth: auth}
+	if shallow {
+		opts.Depth = 1
+	}
+	if err := r.Fetch(opts); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+	return nil
+}
+
+// pullNative fast-forwards the current branch to branchName of the remote,
+// as fetched last.
+func pullNative(localPath, branchName string) error {
+	r, err := openNative(localPath)
+	if err != nil {
+		return err
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+	auth, err := remoteAuth(r)
+	if err != nil {
+		return err
+	}
+	err = w.Pull(&git.PullOptions{
+		RemoteName:    remoteName,
+		ReferenceName: plumbing.NewBranchReferenceName(branchName),
+		Auth:          auth,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+	return nil
+}
+
+// checkoutBranch points branchName at hash and checks it out. Uncommitted
+// changes fail it.
+func checkoutBranch(r *git.Repository, branchName string, hash plumbing.Hash) error {
+	name := plumbing.NewBranchReferenceName(branchName)
+	if err := r.Storer.SetReference(plumbing.NewHashReference(name, hash)); err != nil {
+		return err
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+	return w.Checkout(&git.CheckoutOptions{Branch: name})
+}
+
+// createFeatureBranchNative fetches the remote and checks out branchName at
+// its default branch.
+func createFeatureBranchNative(localPath, branchName string) error {
+	r, err := openNative(localPath)
+	if err != nil {
+		return err
+	}
+	if err := fetchRepo(r, false); err != nil {
+		return err
+	}
+	base, err := r.Reference(plumbing.NewRemoteReferenceName(remoteName, defaultBranchOf(r)), true)
+	if err != nil {
+		return err
+	}
+	return checkoutBranch(r, branchName, base.Hash())
+}
+
+// checkoutNewBranchNative points branchName at HEAD and makes it the current
+// branch; the worktree is left as it is.
+func checkoutNewBranchNative(localPath, branchName string) error {
+	r, err := openNative(localPath)
+	if err != nil {
+		return err
+	}
+	head, err := r.Head()
+	if err != nil {
+		return err
+	}
+	name := plumbing.NewBranchReferenceName(branchName)
+	if err := r.Storer.SetReference(plumbing.NewHashReference(name, head.Hash())); err != nil {
+		return err
+	}
+	return r.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, name))
+}
+
+// checkoutRemoteBranchNative fetches branchName from the remote and checks
+// it out.
+func checkoutRemoteBranchNative(localPath, branchName string) error {
+	r, err := openNative(localPath)
+	if err != nil {
+		return err
+	}
+	remoteRef := plumbing.NewRemoteReferenceName(remoteName, branchName)
+	refspec := config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branchName), remoteRef))
+	if err := fetchRepo(r, false, refspec); err != nil {
+		return err
+	}
+	ref, err := r.Reference(remoteRef, true)
+	if err != nil {
+		return err
+	}
+	return checkoutBranch(r, branchName, ref.Hash())
+}
+
+// commitNative stages every change but the run's own files and commits them.
+func commitNative(localPath, message string) error {
+	r, err := openNative(localPath)
+	if err != nil {
+		return err
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+	status, err := w.Status()
+	if err != nil {
+		return err
+	}
+	for path, s := range status {
+		if isRunArtifact(path) {
+			if s.Staging != git.Unmodified && s.Staging != git.Untracked {
+				return fmt.Errorf("run artifact %s is staged", path)
+			}
+			continue
+		}
+		if s.Worktree == git.Unmodified {
+			continue
+		}
+		if _, err := w.Add(path); err != nil {
+			return err
+		}
+	}
+
+	if status, err = w.Status(); err != nil {
+		return err
+	}
+	staged := false
+	for _, s := range status {
+		if s.Staging != git.Unmodified && s.Staging != git.Untracked {
+			staged = true
+			break
+		}
+	}
+	if !staged {
+		return ErrNoChanges
+	}
+	_, err = w.Commit(message, &git.CommitOptions{
+		Author:    envSignature("AUTHOR"),
+		Committer: envSignature("COMMITTER"),
+	})
+	return err
+}
+
+// envSignature returns the identity of GIT_<role>_NAME and GIT_<role>_EMAIL,
+// as git reads them, or nil for go-git to read the git config.
+func envSignature(role string) *object.Signature {
+	name, email := os.Getenv("GIT_"+role+"_NAME"), os.Getenv("GIT_"+role+"_EMAIL")
+	if name == "" || email == "" {
+		return nil
+	}
+	return &object.Signature{Name: name, Email: email, When: time.Now()}
+}
+
+// pushNative pushes branchName to the remote. A remote branch is replaced
+// only while it's where it was last fetched, as with --force-with-lease.
+func pushNative(localPath, branchName string) error {
+	r, err := openNative(localPath)
+	if err != nil {
+		return err
+	}
+	auth, err := remoteAuth(r)
+	if err != nil {
+		return err
+	}
+	name := plumbing.NewBranchReferenceName(branchName)
+	opts := &git.PushOptions{
+		RemoteName: remoteName,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:%s", name, name))},
+		Auth:       auth,
+	}
+	// Without a remote branch fetched, only a new branch can be pushed
+	if _, err := r.Reference(plumbing.NewRemoteReferenceName(remoteName, branchName), true); err == nil {
+		opts.ForceWithLease = &git.ForceWithLease{}
+	}
+	if err := r.Push(opts); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+	return nil
+}
+
+// defaultBranchOf returns the branch origin/HEAD points at, else main when
+// the remote has it, else master.
+func defaultBranchOf(r *git.Repository) string {
+	if ref, err := r.Reference(plumbing.NewRemoteHEADReferenceName(remoteName), false); err == nil && ref.Type() == plumbing.SymbolicReference {
+		prefix := "refs/remotes/" + remoteName + "/"
+		if branch, ok := strings.CutPrefix(ref.Target().String(), prefix); ok {
+			return branch
+		}
+	}
+	if _, err := r.Reference(plumbing.NewRemoteReferenceName(remoteName, "main"), false); err == nil {
+		return "main"
+	}
+	return "master"
+}
+
+// isRunArtifact reports whether a path of the repository is a file a run
+// writes itself, which isn't committed.
+func isRunArtifact(path string) bool {
+	return path == "bauer-doc-suggestions.json" || strings.HasPrefix(path, "bauer-output/")
+}
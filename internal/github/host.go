@@ -0,0 +1,50 @@
+package github
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultHost is the host of github.com repositories.
+const DefaultHost = "github.com"
+
+// ResolveHost returns the GitHub host to work with: host when set, e.g.
+// "github.example.com" or "https://github.example.com", otherwise the host of
+// the GITHUB_API_URL environment variable (set in GitHub Actions, e.g.
+// "https://github.example.com/api/v3"), otherwise github.com.
+func ResolveHost(host string) string {
+	if host == "" {
+		host = os.Getenv("GITHUB_API_URL")
+	}
+	if host == "" {
+		return DefaultHost
+	}
+	if strings.Contains(host, "://") {
+		if u, err := url.Parse(host); err == nil && u.Host != "" {
+			host = u.Host
+		}
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "/"))
+	// The github.com API is served from its own host
+	if host == "api."+DefaultHost {
+		return DefaultHost
+	}
+	return host
+}
+
+// isEnterpriseHost reports whether host is a GitHub Enterprise Server, whose
+// gh CLI token is read from GH_ENTERPRISE_TOKEN rather than GH_TOKEN.
+// GitHub Enterprise Cloud hosts (*.ghe.com) use GH_TOKEN like github.com.
+func isEnterpriseHost(host string) bool {
+	return host != DefaultHost && !strings.HasSuffix(host, ".ghe.com")
+}
+
+// repoArg is how gh's --repo flag names a repository: hosts other than
+// github.com are given explicitly.
+func repoArg(host, owner, repo string) string {
+	if host == "" || host == DefaultHost {
+		return owner + "/" + repo
+	}
+	return host + "/" + owner + "/" + repo
+}
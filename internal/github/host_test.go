@@ -0,0 +1,60 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolveHost(t *testing.T) {
+	tests := []struct {
+		host   string
+		apiURL string
+		want   string
+	}{
+		{"", "", "github.com"},
+		{"github.example.com", "", "github.example.com"},
+		{"https://GitHub.example.com/", "", "github.example.com"},
+		{"", "https://github.example.com/api/v3", "github.example.com"},
+		{"", "https://api.github.com", "github.com"},
+		{"github.example.com", "https://api.github.com", "github.example.com"},
+	}
+	for _, tt := range tests {
+		t.Setenv("GITHUB_API_URL", tt.apiURL)
+		if got := ResolveHost(tt.host); got != tt.want {
+			t.Errorf("ResolveHost(%q) with GITHUB_API_URL=%q = %q, want %q", tt.host, tt.apiURL, got, tt.want)
+		}
+	}
+}
+
+func TestParseGitHubRepo(t *testing.T) {
+	tests := []struct {
+		input string
+		host  string
+		want  Repository
+	}{
+		{"canonical/ubuntu.com", "", Repository{Host: "github.com", Owner: "canonical", Name: "ubuntu.com", HTTPURL: "https://github.com/canonical/ubuntu.com.git"}},
+		{"canonical/ubuntu.com", "github.example.com", Repository{Host: "github.example.com", Owner: "canonical", Name: "ubuntu.com", HTTPURL: "https://github.example.com/canonical/ubuntu.com.git"}},
+		{"https://github.com/canonical/ubuntu.com.git", "github.example.com", Repository{Host: "github.com", Owner: "canonical", Name: "ubuntu.com", HTTPURL: "https://github.com/canonical/ubuntu.com.git"}},
+		{"git@github.example.com:web/site.git", "", Repository{Host: "github.example.com", Owner: "web", Name: "site", HTTPURL: "https://github.example.com/web/site.git"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseGitHubRepo(tt.input, tt.host)
+		if err != nil {
+			t.Fatalf("ParseGitHubRepo(%q) error = %v", tt.input, err)
+		}
+		if diff := cmp.Diff(tt.want, *got); diff != "" {
+			t.Errorf("ParseGitHubRepo(%q) mismatch (-want +got):\n%s", tt.input, diff)
+		}
+	}
+
+	for _, input := range []string{"https://github.example.com/web", "ubuntu.com", "git@:web/site"} {
+		if _, err := ParseGitHubRepo(input, ""); err == nil {
+			t.Errorf("ParseGitHubRepo(%q) expected an error", input)
+		}
+	}
+
+	if got := repoArg("github.example.com", "web", "site"); got != "github.example.com/web/site" {
+		t.Errorf("repoArg() = %q", got)
+	}
+}
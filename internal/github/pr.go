@@ -1,10 +1,12 @@
 package github
 
 import (
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 )
 
@@ -18,6 +20,10 @@ type CreatePROptions struct {
 	Labels     []string
 	Assignees  []string
 	Reviewers  []string
+	Milestone  string
+
+	// Host is the GitHub host of the repository; empty means github.com
+	Host string
 }
 
 // CreatePR creates a pull request using gh CLI
@@ -38,7 +44,7 @@ func CreatePR(owner, repo string, opts CreatePROptions) (string, error) {
 
 	args := []string{
 		"pr", "create",
-		"--repo", fmt.Sprintf("%s/%s", owner, repo),
+		"--repo", repoArg(opts.Host, owner, repo),
 		"--head", opts.HeadBranch,
 		"--base", opts.BaseBranch,
 		"--title", opts.Title,
@@ -64,6 +70,10 @@ func CreatePR(owner, repo string, opts CreatePROptions) (string, error) {
 		args = append(args, "--reviewer", reviewer)
 	}
 
+	if opts.Milestone != "" {
+		args = append(args, "--milestone", opts.Milestone)
+	}
+
 	cmd := exec.Command("gh", args...)
 	
 	// Log token availability for debugging
@@ -85,12 +95,16 @@ func CreatePR(owner, repo string, opts CreatePROptions) (string, error) {
 
 	// Extract PR URL from output
 	// Output may contain warnings, so look for the URL pattern
+	host := opts.Host
+	if host == "" {
+		host = DefaultHost
+	}
 	outputStr := string(output)
 	lines := strings.Split(outputStr, "\n")
 	var prURL string
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "https://github.com/") {
+		if strings.HasPrefix(trimmed, "https://"+host+"/") {
 			prURL = trimmed
 			break
 		}
@@ -110,10 +124,10 @@ func GetPRURL(owner, repo, prNumber string) string {
 
 // PRStatus describes the status of a pull request
 type PRStatus struct {
-	Number int
-	State  string // "OPEN", "CLOSED", "MERGED"
-	Title  string
-	URL    string
+	Number int    `json:"number"`
+	State  string `json:"state"` // "OPEN", "CLOSED", "MERGED"
+	Title  string `json:"title"`
+	URL    string `json:"url"`
 }
 
 // GetPRInfo retrieves information about a pull request
@@ -188,3 +202,66 @@ func GetBranchStatus(localPath, branchName string) (*BranchStatus, error) {
 
 	return status, nil
 }
+
+// GetPRState returns the state of a pull request ("OPEN", "CLOSED" or "MERGED").
+// pr can be a PR URL, number or branch name.
+func GetPRState(pr string) (string, error) {
+	cmd := exec.Command("gh", "pr", "view", pr, "--json", "state", "--jq", ".state")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to get PR state: %w, output: %s", err, output)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// FindOpenPR returns the open pull request whose head is branch, or nil if
+// there is none.
+func FindOpenPR(host, owner, repo, branch string) (*PRStatus, error) {
+	cmd := exec.Command("gh", "pr", "list",
+		"--repo", repoArg(host, owner, repo),
+		"--head", branch,
+		"--state", "open",
+		"--json", "number,state,title,url",
+		"--limit", "1",
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	var prs []PRStatus
+	if err := json.Unmarshal(output, &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse pull requests: %w", err)
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	return &prs[0], nil
+}
+
+// AppendPRBody adds section to the end of the body of a pull request.
+func AppendPRBody(host, owner, repo string, number int, section string) error {
+	target := []string{strconv.Itoa(number), "--repo", repoArg(host, owner, repo)}
+
+	view := exec.Command("gh", append(append([]string{"pr", "view"}, target...), "--json", "body", "--jq", ".body")...)
+	body, err := view.Output()
+	if err != nil {
+		return fmt.Errorf("failed to get PR body: %w", err)
+	}
+
+	newBody := strings.TrimRight(string(body), "\n") + "\n\n" + section
+	edit := exec.Command("gh", append(append([]string{"pr", "edit"}, target...), "--body", newBody)...)
+	if output, err := edit.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to update PR body: %w, output: %s", err, output)
+	}
+	return nil
+}
+
+// CommentPR adds a comment to the pull request at prURL.
+func CommentPR(prURL, body string) error {
+	cmd := exec.Command("gh", "pr", "comment", prURL, "--body", body)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to comment on PR: %w, output: %s", err, output)
+	}
+	return nil
+}
@@ -1,14 +1,22 @@
 package github
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/go-git/go-git/v5"
 )
 
+// ErrNoChanges is returned by CommitChanges when there is nothing to commit.
+var ErrNoChanges = errors.New("no changes to commit")
+
 type Repository struct {
+	// Host is the GitHub host of the repository, e.g. github.com
+	Host      string
 	Owner     string
 	Name      string
 	LocalPath string
@@ -17,26 +25,33 @@ type Repository struct {
 
 // ParseGitHubRepo parses a GitHub repo string in various formats
 // Supports: "owner/repo", "https://github.com/owner/repo", "git@github.com:owner/repo.git"
-func ParseGitHubRepo(input string) (*Repository, error) {
+// URLs may name any host, e.g. a GitHub Enterprise Server; "owner/repo" is on
+// host, or github.com when host is empty.
+func ParseGitHubRepo(input, host string) (*Repository, error) {
 	var owner, name string
+	if host == "" {
+		host = DefaultHost
+	}
 
 	// Handle HTTPS URL
-	if strings.HasPrefix(input, "https://github.com/") {
-		parts := strings.TrimPrefix(input, "https://github.com/")
+	if rest, ok := strings.CutPrefix(input, "https://"); ok {
+		urlHost, parts, _ := strings.Cut(rest, "/")
 		parts = strings.TrimSuffix(parts, ".git")
 		segments := strings.Split(parts, "/")
-		if len(segments) < 2 {
+		if urlHost == "" || len(segments) < 2 {
 			return nil, fmt.Errorf("invalid GitHub URL: %s", input)
 		}
+		host = strings.ToLower(urlHost)
 		owner, name = segments[0], segments[1]
-	} else if strings.HasPrefix(input, "git@github.com:") {
+	} else if rest, ok := strings.CutPrefix(input, "git@"); ok {
 		// Handle SSH URL
-		parts := strings.TrimPrefix(input, "git@github.com:")
+		sshHost, parts, _ := strings.Cut(rest, ":")
 		parts = strings.TrimSuffix(parts, ".git")
 		segments := strings.Split(parts, "/")
-		if len(segments) < 2 {
+		if sshHost == "" || len(segments) < 2 {
 			return nil, fmt.Errorf("invalid GitHub SSH URL: %s", input)
 		}
+		host = strings.ToLower(sshHost)
 		owner, name = segments[0], segments[1]
 	} else if strings.Contains(input, "/") && !strings.Contains(input, "://") {
 		// Handle "owner/repo" format
@@ -50,14 +65,25 @@ func ParseGitHubRepo(input string) (*Repository, error) {
 	}
 
 	return &Repository{
+		Host:    host,
 		Owner:   owner,
 		Name:    name,
-		HTTPURL: fmt.Sprintf("https://github.com/%s/%s.git", owner, name),
+		HTTPURL: fmt.Sprintf("https://%s/%s/%s.git", host, owner, name),
 	}, nil
 }
 
+// CloneOptions limit how much of a repository is cloned.
+type CloneOptions struct {
+	// Shallow clones and fetches only the latest commit
+	Shallow bool
+
+	// SparsePaths, when set, are the only directories checked out (besides
+	// the files at the root), e.g. "templates"; other files are not downloaded
+	SparsePaths []string
+}
+
 // CloneOrUpdateRepo clones or updates a repository at the specified local path
-func CloneOrUpdateRepo(repo *Repository, localPath string) error {
+func CloneOrUpdateRepo(repo *Repository, localPath string, opts CloneOptions) error {
 	info, err := os.Stat(localPath)
 
 	// If path doesn't exist, clone
@@ -66,9 +92,29 @@ func CloneOrUpdateRepo(repo *Repository, localPath string) error {
 			return fmt.Errorf("failed to create parent directory: %w", err)
 		}
 
-		cmd := exec.Command("git", "clone", repo.HTTPURL, localPath)
-		if output, err := cmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to clone repo: %w, output: %s", err, output)
+		native := func() error {
+			// Sparse checkouts are left to git
+			if len(opts.SparsePaths) > 0 {
+				return errNotNative
+			}
+			return cloneNative(repo.HTTPURL, localPath, opts.Shallow)
+		}
+		err := withFallback("clone", native, func() error {
+			args := []string{"clone"}
+			if opts.Shallow {
+				args = append(args, "--depth", "1")
+			}
+			if len(opts.SparsePaths) > 0 {
+				args = append(args, "--filter=blob:none", "--sparse")
+			}
+			_, err := runGit("", append(args, repo.HTTPURL, localPath)...)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to clone repo: %w", err)
+		}
+		if err := setSparsePaths(localPath, opts.SparsePaths); err != nil {
+			return err
 		}
 		repo.LocalPath = localPath
 		return nil
@@ -85,17 +131,29 @@ func CloneOrUpdateRepo(repo *Repository, localPath string) error {
 
 	// If directory exists and is a git repo, pull latest
 	if isGitRepo(localPath) {
-		cmd := exec.Command("git", "fetch", "origin")
-		cmd.Dir = localPath
-		if output, err := cmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to fetch from remote: %w, output: %s", err, output)
+		err := withFallback("fetch", func() error { return fetchNative(localPath, opts.Shallow) }, func() error {
+			args := []string{"fetch", "origin"}
+			if opts.Shallow {
+				args = append(args, "--depth", "1")
+			}
+			_, err := runGit(localPath, args...)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fetch from remote: %w", err)
+		}
+		if err := setSparsePaths(localPath, opts.SparsePaths); err != nil {
+			return err
 		}
 
-		cmd = exec.Command("git", "pull", "origin", getDefaultBranch(localPath))
-		cmd.Dir = localPath
-		if _, err := cmd.CombinedOutput(); err != nil {
+		defaultBranch := getDefaultBranch(localPath)
+		err = withFallback("pull", func() error { return pullNative(localPath, defaultBranch) }, func() error {
+			_, err := runGit(localPath, "pull", "origin", defaultBranch)
+			return err
+		})
+		if err != nil {
 			// Non-fatal: might be on a different branch
-			fmt.Printf("Warning: failed to pull latest: %v\n", err)
+			slog.Warn("Failed to pull latest", slog.String("branch", defaultBranch), slog.String("error", err.Error()))
 		}
 		repo.LocalPath = localPath
 		return nil
@@ -110,62 +168,73 @@ func GetDefaultBranch(localPath string) (string, error) {
 	return name, nil
 }
 
-// CreateFeatureBranch creates a new feature branch and checks it out
+// CreateFeatureBranch creates a feature branch from the latest default
+// branch and checks it out. A local branch of the same name, e.g. left by a
+// previous run, is reset.
 func CreateFeatureBranch(localPath, branchName string) error {
-	// Checkout to default branch
-	defaultBranch := getDefaultBranch(localPath)
-	cmd := exec.Command("git", "checkout", defaultBranch)
-	cmd.Dir = localPath
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to checkout to %s: %w, output: %s", defaultBranch, err, output)
-	}
+	native := func() error { return createFeatureBranchNative(localPath, branchName) }
+	return withFallback("branch", native, func() error {
+		// Checkout to default branch
+		defaultBranch := getDefaultBranch(localPath)
+		if _, err := runGit(localPath, "checkout", defaultBranch); err != nil {
+			return fmt.Errorf("failed to checkout to %s: %w", defaultBranch, err)
+		}
 
-	// Pull latest changes
-	cmd = exec.Command("git", "pull", "origin", defaultBranch)
-	cmd.Dir = localPath
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to pull latest from %s: %w, output: %s", defaultBranch, err, output)
-	}
+		// Pull latest changes
+		if _, err := runGit(localPath, "pull", "origin", defaultBranch); err != nil {
+			return fmt.Errorf("failed to pull latest from %s: %w", defaultBranch, err)
+		}
 
-	// Create new branch
-	cmd = exec.Command("git", "checkout", "-b", branchName)
-	cmd.Dir = localPath
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to create branch %s: %w, output: %s", branchName, err, output)
-	}
+		// Create new branch
+		if _, err := runGit(localPath, "checkout", "-B", branchName); err != nil {
+			return fmt.Errorf("failed to create branch %s: %w", branchName, err)
+		}
+		return nil
+	})
+}
 
+// CheckoutNewBranch creates a branch at the current commit and checks it
+// out, keeping uncommitted changes. A local branch of the same name is reset.
+func CheckoutNewBranch(localPath, branchName string) error {
+	err := withFallback("branch", func() error { return checkoutNewBranchNative(localPath, branchName) }, func() error {
+		_, err := runGit(localPath, "checkout", "-B", branchName)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branchName, err)
+	}
 	return nil
 }
 
 // GetCurrentBranch returns the current branch name
 func GetCurrentBranch(localPath string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = localPath
-	output, err := cmd.CombinedOutput()
+	output, err := runGit(localPath, "rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(output), nil
 }
 
 // GetStatus returns git status in machine-readable format
 func GetStatus(localPath string) (string, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = localPath
-	output, err := cmd.CombinedOutput()
+	output, err := runGit(localPath, "status", "--porcelain")
 	if err != nil {
 		return "", fmt.Errorf("failed to get git status: %w", err)
 	}
-	return string(output), nil
+	return output, nil
 }
 
 // CommitChanges stages all changes and commits with a message
 func CommitChanges(localPath, message string) error {
+	native := func() error { return commitNative(localPath, message) }
+	return withFallback("commit", native, func() error { return commitChanges(localPath, message) })
+}
+
+// commitChanges is CommitChanges with the git binary.
+func commitChanges(localPath, message string) error {
 	// Stage all changes
-	cmd := exec.Command("git", "add", ".")
-	cmd.Dir = localPath
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to stage changes: %w, output: %s", err, output)
+	if _, err := runGit(localPath, "add", "."); err != nil {
+		return fmt.Errorf("failed to stage changes: %w", err)
 	}
 
 	// Exclude specific files from commit
@@ -174,10 +243,8 @@ func CommitChanges(localPath, message string) error {
 		"bauer-output/",
 	}
 	for _, file := range excludeFiles {
-		cmd := exec.Command("git", "reset", "HEAD", file)
-		cmd.Dir = localPath
 		// Ignore error if file doesn't exist
-		cmd.CombinedOutput()
+		runGit(localPath, "reset", "HEAD", file)
 	}
 
 	// Check if there are changes to commit
@@ -186,41 +253,89 @@ func CommitChanges(localPath, message string) error {
 		return err
 	}
 	if strings.TrimSpace(status) == "" {
-		return fmt.Errorf("no changes to commit")
+		return ErrNoChanges
 	}
 
 	// Commit
-	cmd = exec.Command("git", "commit", "-m", message)
-	cmd.Dir = localPath
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to commit changes: %w, output: %s", err, output)
+	if _, err := runGit(localPath, "commit", "-m", message); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
 	}
 
 	return nil
 }
 
-// PushBranch pushes the specified branch to remote
+// CheckoutRemoteBranch checks out the latest commit of a branch on the remote,
+// e.g. the branch of an open pull request, discarding any local branch of the
+// same name.
+func CheckoutRemoteBranch(localPath, branchName string) error {
+	native := func() error { return checkoutRemoteBranchNative(localPath, branchName) }
+	return withFallback("fetch", native, func() error {
+		ref := "refs/remotes/origin/" + branchName
+		if _, err := runGit(localPath, "fetch", "origin", "+refs/heads/"+branchName+":"+ref); err != nil {
+			return fmt.Errorf("failed to fetch branch %s: %w", branchName, err)
+		}
+		if _, err := runGit(localPath, "checkout", "-B", branchName, ref); err != nil {
+			return fmt.Errorf("failed to checkout branch %s: %w", branchName, err)
+		}
+		return nil
+	})
+}
+
+// PushBranch pushes the specified branch to remote. Feature branches belong
+// to Bauer, so a branch of the same name left by a closed pull request is
+// replaced, unless it changed since it was last fetched.
 func PushBranch(localPath, branchName string) error {
-	cmd := exec.Command("git", "push", "origin", branchName)
-	cmd.Dir = localPath
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to push branch %s: %w, output: %s", branchName, err, output)
+	err := withFallback("push", func() error { return pushNative(localPath, branchName) }, func() error {
+		_, err := runGit(localPath, "push", "--force-with-lease", "origin", branchName)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to push branch %s: %w", branchName, err)
+	}
+	return nil
+}
+
+// StashChanges sets the changes to tracked files aside, e.g. to look at the
+// repository as it was before a run, and reports whether there were any.
+// Untracked files stay.
+func StashChanges(localPath string) (bool, error) {
+	before, _ := runGit(localPath, "rev-parse", "-q", "--verify", "refs/stash")
+	if _, err := runGit(localPath, "stash", "push", "-m", "bauer"); err != nil {
+		return false, fmt.Errorf("failed to stash changes: %w", err)
+	}
+	after, _ := runGit(localPath, "rev-parse", "-q", "--verify", "refs/stash")
+	return after != before, nil
+}
+
+// RestoreChanges brings back the changes set aside by StashChanges.
+func RestoreChanges(localPath string) error {
+	if _, err := runGit(localPath, "stash", "pop", "--index"); err != nil {
+		return fmt.Errorf("failed to restore stashed changes: %w", err)
 	}
 	return nil
 }
 
 // DeleteLocalBranch deletes a local branch (without force)
 func DeleteLocalBranch(localPath, branchName string) error {
-	cmd := exec.Command("git", "branch", "-d", branchName)
-	cmd.Dir = localPath
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to delete local branch %s: %w, output: %s", branchName, err, output)
+	if _, err := runGit(localPath, "branch", "-d", branchName); err != nil {
+		return fmt.Errorf("failed to delete local branch %s: %w", branchName, err)
 	}
 	return nil
 }
 
 // Helper functions
 
+// setSparsePaths limits the checkout to paths; no paths leaves it as is.
+func setSparsePaths(localPath string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	if _, err := runGit(localPath, append([]string{"sparse-checkout", "set", "--cone"}, paths...)...); err != nil {
+		return fmt.Errorf("failed to set sparse checkout paths: %w", err)
+	}
+	return nil
+}
+
 func isGitRepo(path string) bool {
 	gitDir := filepath.Join(path, ".git")
 	info, err := os.Stat(gitDir)
@@ -228,22 +343,21 @@ func isGitRepo(path string) bool {
 }
 
 func getDefaultBranch(localPath string) string {
+	// Refs are read alike in sparse checkouts and partial clones
+	if r, err := git.PlainOpen(localPath); err == nil {
+		return defaultBranchOf(r)
+	}
+
 	// Get branch from origin/HEAD
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
-	cmd.Dir = localPath
-	output, err := cmd.CombinedOutput()
-	if err == nil {
-		ref := strings.TrimSpace(string(output))
+	if ref, err := runGit(localPath, "symbolic-ref", "refs/remotes/origin/HEAD"); err == nil {
 		// Format: "refs/remotes/origin/main" or "refs/remotes/origin/master"
-		parts := strings.Split(ref, "/")
+		parts := strings.Split(strings.TrimSpace(ref), "/")
 		if len(parts) > 0 {
 			return parts[len(parts)-1]
 		}
 	}
 
-	cmd = exec.Command("git", "rev-parse", "--verify", "origin/main")
-	cmd.Dir = localPath
-	if err := cmd.Run(); err == nil {
+	if _, err := runGit(localPath, "rev-parse", "--verify", "origin/main"); err == nil {
 		return "main"
 	}
 
@@ -0,0 +1,53 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// HandleMap maps the emails of document authors to their GitHub handles, so
+// that the people who made the suggestions review the PR. Emails are matched
+// regardless of case.
+type HandleMap map[string]string
+
+// LoadHandleMap reads a JSON object of emails to GitHub handles, e.g.
+// {"jane@example.com": "jane-doe"}.
+func LoadHandleMap(path string) (HandleMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reviewer map: %w", err)
+	}
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse reviewer map: %w", err)
+	}
+	return NewHandleMap(raw), nil
+}
+
+// NewHandleMap normalizes a map of emails to handles: emails are lowercased
+// and a leading "@" is removed from handles.
+func NewHandleMap(raw map[string]string) HandleMap {
+	m := make(HandleMap, len(raw))
+	for email, handle := range raw {
+		if handle = strings.TrimPrefix(strings.TrimSpace(handle), "@"); handle != "" {
+			m[strings.ToLower(strings.TrimSpace(email))] = handle
+		}
+	}
+	return m
+}
+
+// Handles returns the handles of emails, in order and without duplicates.
+// Emails without a handle are skipped.
+func (m HandleMap) Handles(emails []string) []string {
+	var handles []string
+	for _, email := range emails {
+		handle, ok := m[strings.ToLower(strings.TrimSpace(email))]
+		if ok && !slices.Contains(handles, handle) {
+			handles = append(handles, handle)
+		}
+	}
+	return handles
+}
@@ -0,0 +1,30 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHandleMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviewers.json")
+	content := `{"Jane@Example.com": "@jane-doe", "john@example.com": "johnny", "blank@example.com": " "}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	handles, err := LoadHandleMap(path)
+	if err != nil {
+		t.Fatalf("LoadHandleMap() error = %v", err)
+	}
+	got := handles.Handles([]string{"jane@example.com", "unknown@example.com", "JOHN@example.com", "Jane@Example.com", "blank@example.com"})
+	if diff := cmp.Diff([]string{"jane-doe", "johnny"}, got); diff != "" {
+		t.Errorf("Handles() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := LoadHandleMap(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadHandleMap() of missing file succeeded")
+	}
+}
@@ -0,0 +1,50 @@
+package github
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// ClosePR closes a pull request, with a comment saying why.
+func ClosePR(host, owner, repo string, number int, comment string) error {
+	cmd := exec.Command("gh", "pr", "close", strconv.Itoa(number),
+		"--repo", repoArg(host, owner, repo),
+		"--comment", comment,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to close PR #%d: %w, output: %s", number, err, output)
+	}
+	return nil
+}
+
+// DeleteRemoteBranch deletes a branch of a GitHub repository, without a
+// local clone.
+func DeleteRemoteBranch(host, owner, repo, branchName string) error {
+	args := []string{"api", "--method", "DELETE", fmt.Sprintf("repos/%s/%s/git/refs/heads/%s", owner, repo, branchName)}
+	if host != "" && host != DefaultHost {
+		args = append(args, "--hostname", host)
+	}
+	if output, err := exec.Command("gh", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete remote branch %s: %w, output: %s", branchName, err, output)
+	}
+	return nil
+}
+
+// RemoveLocalBranch deletes a local branch, merged or not, checking out
+// baseBranch first when it's the current branch.
+func RemoveLocalBranch(localPath, branchName, baseBranch string) error {
+	current, err := GetCurrentBranch(localPath)
+	if err != nil {
+		return err
+	}
+	if current == branchName {
+		if _, err := runGit(localPath, "checkout", baseBranch); err != nil {
+			return fmt.Errorf("failed to checkout %s: %w", baseBranch, err)
+		}
+	}
+	if _, err := runGit(localPath, "branch", "-D", branchName); err != nil {
+		return fmt.Errorf("failed to delete local branch %s: %w", branchName, err)
+	}
+	return nil
+}
@@ -3,6 +3,9 @@ package github
 import (
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -12,6 +15,27 @@ type GitHubSetupInput struct {
 	GitHubToken   string
 	BranchPrefix  string
 	LocalRepoPath string
+
+	// GitHubHost is the host of GitHub Enterprise Server repositories given
+	// as "owner/repo"; see ResolveHost
+	GitHubHost string
+
+	// Clone limits the clone of the repository, e.g. for servers that clone
+	// for every job
+	Clone CloneOptions
+
+	// Resume continues on the branch of the previous run, when the local
+	// repository is still on one, instead of creating a new branch
+	Resume bool
+
+	// DocID keys the feature branch, so that later runs of the same document
+	// find the pull request of earlier ones; see FeatureBranchName
+	DocID string
+
+	// UseLocal takes the local repository as it is, e.g. with the changes of
+	// `bauer apply`: it isn't cloned or updated, and the feature branch is
+	// created from its current commit, keeping the changes
+	UseLocal bool
 }
 
 // GitHubSetupOutput represents the result of GitHub setup phase
@@ -21,6 +45,19 @@ type GitHubSetupOutput struct {
 	BranchName    string
 	DefaultBranch string
 	CurrentBranch string
+
+	// ExistingPR is the open pull request of the branch, from an earlier run
+	// of the document, or nil
+	ExistingPR *PRStatus
+}
+
+// FeatureBranchName returns the feature branch of a document. Runs without a
+// document ID get a new branch every time.
+func FeatureBranchName(prefix, docID string) string {
+	if docID == "" {
+		return fmt.Sprintf("%s/doc-suggestions-%d", prefix, time.Now().Unix())
+	}
+	return fmt.Sprintf("%s/doc-suggestions-%s", prefix, docID)
 }
 
 // SetupGitHubPhase performs Phase 1: GitHub Setup
@@ -34,24 +71,34 @@ func SetupGitHubPhase(input GitHubSetupInput) (*GitHubSetupOutput, error) {
 	}
 	logger.Info("github setup: gh CLI detected")
 
-	// Setup GitHub authentication with provided token
-	if err := SetupGitHubAuth(input.GitHubToken); err != nil {
-		return nil, fmt.Errorf("failed to setup GitHub auth: %w", err)
-	}
-	logger.Info("github setup: authentication configured")
-
 	// Parse repository
-	repo, err := ParseGitHubRepo(input.GitHubRepo)
+	repo, err := ParseGitHubRepo(input.GitHubRepo, ResolveHost(input.GitHubHost))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse GitHub repo: %w", err)
 	}
-	logger.Info("github setup: parsed repo", "owner", repo.Owner, "repo", repo.Name)
+	logger.Info("github setup: parsed repo", "host", repo.Host, "owner", repo.Owner, "repo", repo.Name)
+
+	// Setup GitHub authentication with provided token, for gh and git
+	if err := SetupGitHubAuth(input.GitHubToken, repo.Host); err != nil {
+		return nil, fmt.Errorf("failed to setup GitHub auth: %w", err)
+	}
+	setGitAuth(input.GitHubToken, repo.Host)
+	logger.Info("github setup: authentication configured")
 
 	// Clone/update repository
-	if err := CloneOrUpdateRepo(repo, input.LocalRepoPath); err != nil {
+	if input.UseLocal {
+		if !isGitRepo(input.LocalRepoPath) {
+			return nil, fmt.Errorf("not a git repository: %s", input.LocalRepoPath)
+		}
+		repo.LocalPath = input.LocalRepoPath
+	} else if err := CloneOrUpdateRepo(repo, input.LocalRepoPath, input.Clone); err != nil {
 		return nil, fmt.Errorf("failed to clone/update repo: %w", err)
 	}
-	logger.Info("github setup: repository ready", "local_path", input.LocalRepoPath)
+	logger.Info("github setup: repository ready",
+		"local_path", input.LocalRepoPath,
+		"shallow", input.Clone.Shallow,
+		"sparse_paths", input.Clone.SparsePaths,
+	)
 
 	// Get default branch
 	defaultBranch, err := GetDefaultBranch(input.LocalRepoPath)
@@ -60,25 +107,61 @@ func SetupGitHubPhase(input GitHubSetupInput) (*GitHubSetupOutput, error) {
 	}
 	logger.Info("github setup: default branch detected", "branch", defaultBranch)
 
-	// Create feature branch
-	branchName := fmt.Sprintf("%s/doc-suggestions-%d", input.BranchPrefix, time.Now().Unix())
-	if err := CreateFeatureBranch(input.LocalRepoPath, branchName); err != nil {
-		return nil, fmt.Errorf("failed to create feature branch: %w", err)
-	}
-	logger.Info("github setup: feature branch created", "branch", branchName)
-
 	// Get current branch
 	currentBranch, err := GetCurrentBranch(input.LocalRepoPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current branch: %w", err)
 	}
 
+	branchName := currentBranch
+	resuming := input.Resume && strings.HasPrefix(currentBranch, input.BranchPrefix+"/")
+	if !resuming {
+		branchName = FeatureBranchName(input.BranchPrefix, input.DocID)
+	}
+
+	// An open PR of an earlier run of the document is updated rather than
+	// duplicated; failing to look it up only risks a duplicate
+	var existingPR *PRStatus
+	if resuming || input.DocID != "" {
+		existingPR, err = FindOpenPR(repo.Host, repo.Owner, repo.Name, branchName)
+		if err != nil {
+			logger.Warn("github setup: failed to look up existing PR", "branch", branchName, "error", err)
+			existingPR = nil
+		}
+	}
+
+	// Create feature branch, unless resuming on the previous run's one or
+	// continuing the branch of the existing PR
+	switch {
+	case resuming:
+		logger.Info("github setup: resuming on feature branch", "branch", branchName)
+	case existingPR != nil:
+		if err := CheckoutRemoteBranch(input.LocalRepoPath, branchName); err != nil {
+			return nil, fmt.Errorf("failed to checkout branch of PR #%d: %w", existingPR.Number, err)
+		}
+		logger.Info("github setup: continuing branch of existing PR", "branch", branchName, "url", existingPR.URL)
+		currentBranch = branchName
+	case input.UseLocal:
+		if err := CheckoutNewBranch(input.LocalRepoPath, branchName); err != nil {
+			return nil, fmt.Errorf("failed to create feature branch: %w", err)
+		}
+		logger.Info("github setup: feature branch created from the local repository", "branch", branchName)
+		currentBranch = branchName
+	default:
+		if err := CreateFeatureBranch(input.LocalRepoPath, branchName); err != nil {
+			return nil, fmt.Errorf("failed to create feature branch: %w", err)
+		}
+		logger.Info("github setup: feature branch created", "branch", branchName)
+		currentBranch = branchName
+	}
+
 	output := &GitHubSetupOutput{
 		Repo:          repo,
 		LocalPath:     input.LocalRepoPath,
 		BranchName:    branchName,
 		DefaultBranch: defaultBranch,
 		CurrentBranch: currentBranch,
+		ExistingPR:    existingPR,
 	}
 
 	logger.Info("github setup: phase complete",
@@ -96,6 +179,7 @@ type GitHubFinalizationInput struct {
 	LocalRepoPath string
 	BranchName    string
 	DefaultBranch string
+	Host          string
 	Owner         string
 	Repo          string
 	CommitMessage string
@@ -103,6 +187,32 @@ type GitHubFinalizationInput struct {
 	PRTitle       string
 	PRBody        string
 	Labels        []string
+	Assignees     []string
+	Milestone     string
+
+	// Reviewers are requested on the PR, in addition to code owners
+	Reviewers []string
+
+	// RequestCodeOwnerReviews requests reviews from the CODEOWNERS of modified files
+	RequestCodeOwnerReviews bool
+
+	// Pages lists the files of each page, for documents that cover several
+	// pages; the PR lists the changed files under their page
+	Pages []PageFiles
+
+	// ExistingPR is the open PR of the branch; its body gets a section for
+	// the run instead of a new PR being created
+	ExistingPR *PRStatus
+
+	// Committed skips committing, for changes already committed, e.g. one
+	// commit per document of a single-PR batch
+	Committed bool
+}
+
+// PageFiles are the files a page is expected to be built from.
+type PageFiles struct {
+	URL   string
+	Files []string
 }
 
 // GitHubFinalizationOutput represents the result of GitHub finalization phase
@@ -113,9 +223,15 @@ type GitHubFinalizationOutput struct {
 		URL    string
 		Number int
 		Title  string
+
+		// Updated is true when an existing PR was updated rather than created
+		Updated bool
 	}
-	Errors   []string
-	Warnings []string
+	// FileOwners maps each modified file to its CODEOWNERS owners
+	FileOwners map[string][]string
+	Reviewers  []string
+	Errors     []string
+	Warnings   []string
 }
 
 // FinalizeGitHubPhase performs Phase 3: GitHub Finalization
@@ -135,7 +251,9 @@ func FinalizeGitHubPhase(input GitHubFinalizationInput) (*GitHubFinalizationOutp
 	}
 
 	// 3.2 Commit changes (if there are any)
-	if status != "" {
+	if input.Committed {
+		logger.Info("github finalize: changes already committed")
+	} else if status != "" {
 		if err := CommitChanges(input.LocalRepoPath, input.CommitMessage); err != nil {
 			output.Errors = append(output.Errors, fmt.Sprintf("failed to commit changes: %v", err))
 			logger.Warn("github finalize: failed to commit", "error", err)
@@ -156,17 +274,75 @@ func FinalizeGitHubPhase(input GitHubFinalizationInput) (*GitHubFinalizationOutp
 	output.BranchPushed = true
 	logger.Info("github finalize: branch pushed", "branch", input.BranchName)
 
-	// 3.4 Create PR (only if not dry run)
-	if !input.DryRun && output.BranchPushed {
+	// 3.4 Resolve code owners of the modified files
+	fileOwners, err := resolveChangedFileOwners(input.LocalRepoPath, input.DefaultBranch)
+	if err != nil {
+		output.Warnings = append(output.Warnings, fmt.Sprintf("failed to resolve code owners: %v", err))
+		logger.Warn("github finalize: failed to resolve code owners", "error", err)
+	}
+	output.FileOwners = fileOwners
+	output.Reviewers = append([]string{}, input.Reviewers...)
+	if input.RequestCodeOwnerReviews {
+		for _, reviewer := range ReviewersFromOwners(fileOwners) {
+			if !slices.Contains(output.Reviewers, reviewer) {
+				output.Reviewers = append(output.Reviewers, reviewer)
+			}
+		}
+	}
+	if len(fileOwners) > 0 {
+		logger.Info("github finalize: code owners resolved",
+			"owned_files", len(fileOwners),
+			"reviewers", output.Reviewers,
+		)
+	}
+
+	// 3.5 Create or update PR (only if not dry run)
+	if !input.DryRun && output.BranchPushed && input.ExistingPR != nil {
+		existing := input.ExistingPR
+		section := formatRunSection(time.Now(), input.PRBody+formatCodeOwners(fileOwners))
+		if err := AppendPRBody(input.Host, input.Owner, input.Repo, existing.Number, section); err != nil {
+			output.Errors = append(output.Errors, fmt.Sprintf("failed to update PR #%d: %v", existing.Number, err))
+			logger.Warn("github finalize: failed to update PR", "number", existing.Number, "error", err)
+		} else {
+			logger.Info("github finalize: PR updated", "url", existing.URL)
+		}
+		output.PullRequest.URL = existing.URL
+		output.PullRequest.Number = existing.Number
+		output.PullRequest.Title = existing.Title
+		output.PullRequest.Updated = true
+	} else if !input.DryRun && output.BranchPushed {
+		pageSection := ""
+		if len(input.Pages) > 0 {
+			changed, err := GetChangedFiles(input.LocalRepoPath, input.DefaultBranch)
+			if err != nil {
+				output.Warnings = append(output.Warnings, fmt.Sprintf("failed to list changed files by page: %v", err))
+				logger.Warn("github finalize: failed to list changed files", "error", err)
+			} else {
+				pageSection = formatPageFiles(input.Pages, changed)
+			}
+		}
+
 		prOpts := CreatePROptions{
 			Title:      input.PRTitle,
-			Body:       input.PRBody,
+			Body:       input.PRBody + pageSection + formatCodeOwners(fileOwners),
 			HeadBranch: input.BranchName,
 			BaseBranch: input.DefaultBranch,
 			Labels:     input.Labels,
+			Assignees:  input.Assignees,
+			Reviewers:  output.Reviewers,
+			Milestone:  input.Milestone,
+			Host:       input.Host,
 		}
 
 		prURL, err := CreatePR(input.Owner, input.Repo, prOpts)
+		if err != nil && len(prOpts.Reviewers) > 0 {
+			// Reviewers may be unknown or lack access; don't lose the PR over it
+			output.Warnings = append(output.Warnings, fmt.Sprintf("failed to request reviews: %v", err))
+			logger.Warn("github finalize: retrying PR creation without reviewers", "error", err)
+			prOpts.Reviewers = nil
+			output.Reviewers = nil
+			prURL, err = CreatePR(input.Owner, input.Repo, prOpts)
+		}
 		if err != nil {
 			output.Errors = append(output.Errors, fmt.Sprintf("failed to create PR: %v", err))
 			logger.Warn("github finalize: failed to create PR", "error", err)
@@ -184,3 +360,85 @@ func FinalizeGitHubPhase(input GitHubFinalizationInput) (*GitHubFinalizationOutp
 
 	return output, nil
 }
+
+// resolveChangedFileOwners maps the files changed on the branch to their CODEOWNERS owners.
+// Returns nil when the repository has no CODEOWNERS file.
+func resolveChangedFileOwners(localPath, baseBranch string) (map[string][]string, error) {
+	rules, err := LoadCodeOwners(localPath)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+
+	files, err := GetChangedFiles(localPath, baseBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	return ResolveFileOwners(rules, files), nil
+}
+
+// formatPageFiles renders the changed files under the page they belong to as
+// a PR body section. Files of no page are listed last.
+func formatPageFiles(pages []PageFiles, changed []string) string {
+	if len(changed) == 0 {
+		return ""
+	}
+
+	listed := make(map[string]bool)
+	var sb strings.Builder
+	sb.WriteString("\n\n### Pages\n")
+	for _, page := range pages {
+		sb.WriteString(fmt.Sprintf("\n**%s**\n\n", page.URL))
+		found := false
+		for _, file := range changed {
+			if slices.Contains(page.Files, file) {
+				sb.WriteString(fmt.Sprintf("- `%s`\n", file))
+				listed[file] = true
+				found = true
+			}
+		}
+		if !found {
+			sb.WriteString("- No files changed\n")
+		}
+	}
+
+	var other []string
+	for _, file := range changed {
+		if !listed[file] {
+			other = append(other, file)
+		}
+	}
+	if len(other) > 0 {
+		sb.WriteString("\n**Other files**\n\n")
+		for _, file := range other {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", file))
+		}
+	}
+	return sb.String()
+}
+
+// formatRunSection renders the PR body of a later run of the document as a
+// section to append to the existing PR.
+func formatRunSection(at time.Time, body string) string {
+	return fmt.Sprintf("---\n\n## Run of %s\n\n%s", at.UTC().Format("2006-01-02 15:04 UTC"), strings.TrimSpace(body))
+}
+
+// formatCodeOwners renders the file owners as a PR body section
+func formatCodeOwners(fileOwners map[string][]string) string {
+	if len(fileOwners) == 0 {
+		return ""
+	}
+
+	files := make([]string, 0, len(fileOwners))
+	for file := range fileOwners {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var sb strings.Builder
+	sb.WriteString("\n\n### Code owners\n\n")
+	for _, file := range files {
+		sb.WriteString(fmt.Sprintf("- `%s`: %s\n", file, strings.Join(fileOwners[file], " ")))
+	}
+	return sb.String()
+}
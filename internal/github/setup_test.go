@@ -0,0 +1,26 @@
+package github
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeatureBranchName(t *testing.T) {
+	if got := FeatureBranchName("bauer", "1AbC-d_E"); got != "bauer/doc-suggestions-1AbC-d_E" {
+		t.Errorf("FeatureBranchName() = %q, want bauer/doc-suggestions-1AbC-d_E", got)
+	}
+	// Without a document every run gets its own branch
+	if got := FeatureBranchName("bauer", ""); !strings.HasPrefix(got, "bauer/doc-suggestions-") || got == "bauer/doc-suggestions-" {
+		t.Errorf("FeatureBranchName() without document = %q", got)
+	}
+}
+
+func TestFormatRunSection(t *testing.T) {
+	at := time.Date(2026, 10, 16, 9, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	got := formatRunSection(at, "Automated copy update changes from Bauer\n\n")
+	want := "---\n\n## Run of 2026-10-16 07:30 UTC\n\nAutomated copy update changes from Bauer"
+	if got != want {
+		t.Errorf("formatRunSection() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,184 @@
+// Package htmlcheck finds damage to HTML templates, such as unbalanced tags,
+// broken Jinja statements and deleted {% block %} markers, so changes that
+// break a page are caught before they are pushed.
+package htmlcheck
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Problem kinds.
+const (
+	KindUnclosedTag     = "unclosed-tag"
+	KindUnexpectedEnd   = "unexpected-end-tag"
+	KindUnterminated    = "unterminated-jinja"
+	KindUnbalancedJinja = "unbalanced-jinja"
+	KindDeletedBlock    = "deleted-block"
+)
+
+// Problem is a single defect of a template.
+type Problem struct {
+	Kind    string `json:"kind"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+
+	// key identifies the problem regardless of where it is, to tell problems
+	// a change introduced from those the template already had
+	key string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// voidElements have no end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "keygen": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// optionalEndElements may be left open; they are closed by their parent.
+var optionalEndElements = map[string]bool{
+	"body": true, "colgroup": true, "dd": true, "dt": true, "head": true, "html": true,
+	"li": true, "optgroup": true, "option": true, "p": true, "rb": true, "rp": true,
+	"rt": true, "tbody": true, "td": true, "tfoot": true, "th": true, "thead": true, "tr": true,
+}
+
+// Check returns the problems of a template: its Jinja statements, then its
+// HTML tags, checked with the Jinja removed.
+func Check(content string) []Problem {
+	statements, problems := scanJinja(content)
+	problems = append(problems, checkStatements(statements)...)
+	problems = append(problems, checkTags(stripJinja(content))...)
+	return problems
+}
+
+// Compare returns the problems after has that before didn't, and a problem
+// for each {% block %} of before that after lost. Problems before already had
+// aren't reported, so templates that were never balanced don't block changes.
+func Compare(before, after string) []Problem {
+	known := make(map[string]int)
+	for _, problem := range Check(before) {
+		known[problem.key]++
+	}
+	var problems []Problem
+	for _, problem := range Check(after) {
+		if known[problem.key] > 0 {
+			known[problem.key]--
+			continue
+		}
+		problems = append(problems, problem)
+	}
+
+	remaining := make(map[string]int)
+	for _, name := range Blocks(after) {
+		remaining[name]++
+	}
+	for _, name := range Blocks(before) {
+		if remaining[name] > 0 {
+			remaining[name]--
+			continue
+		}
+		problems = append(problems, Problem{
+			Kind:    KindDeletedBlock,
+			Message: fmt.Sprintf("{%% block %s %%} was removed", name),
+			key:     KindDeletedBlock + ":" + name,
+		})
+	}
+	return problems
+}
+
+// Blocks returns the names of the {% block %} statements of a template, in order.
+func Blocks(content string) []string {
+	statements, _ := scanJinja(content)
+	var names []string
+	for _, stmt := range statements {
+		if stmt.tag == "block" && stmt.arg != "" {
+			names = append(names, stmt.arg)
+		}
+	}
+	return names
+}
+
+// checkTags reports end tags without a start tag, and start tags left open.
+func checkTags(content string) []Problem {
+	type openTag struct {
+		name string
+		line int
+	}
+	var problems []Problem
+	var stack []openTag
+	line := 1
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				problems = append(problems, Problem{
+					Kind:    KindUnexpectedEnd,
+					Line:    line,
+					Message: fmt.Sprintf("failed to parse HTML: %v", z.Err()),
+					key:     "parse",
+				})
+			}
+			break
+		}
+		tokenLine := line
+		line += strings.Count(string(z.Raw()), "\n")
+
+		name, _ := z.TagName()
+		tag := string(name)
+		switch tt {
+		case html.StartTagToken:
+			if !voidElements[tag] {
+				stack = append(stack, openTag{tag, tokenLine})
+			}
+		case html.EndTagToken:
+			if voidElements[tag] {
+				continue
+			}
+			i := len(stack) - 1
+			for i >= 0 && stack[i].name != tag {
+				i--
+			}
+			if i < 0 {
+				problems = append(problems, Problem{
+					Kind:    KindUnexpectedEnd,
+					Line:    tokenLine,
+					Message: fmt.Sprintf("</%s> closes no open <%s>", tag, tag),
+					key:     KindUnexpectedEnd + ":" + tag,
+				})
+				continue
+			}
+			for _, open := range stack[i+1:] {
+				if !optionalEndElements[open.name] {
+					problems = append(problems, unclosed(open.name, open.line, "before </"+tag+">"))
+				}
+			}
+			stack = stack[:i]
+		}
+	}
+	for _, open := range stack {
+		if !optionalEndElements[open.name] {
+			problems = append(problems, unclosed(open.name, open.line, "at the end of the file"))
+		}
+	}
+	return problems
+}
+
+func unclosed(tag string, line int, where string) Problem {
+	return Problem{
+		Kind:    KindUnclosedTag,
+		Line:    line,
+		Message: fmt.Sprintf("<%s> is not closed %s", tag, where),
+		key:     KindUnclosedTag + ":" + tag,
+	}
+}
@@ -0,0 +1,111 @@
+package htmlcheck
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func messages(problems []Problem) []string {
+	var out []string
+	for _, problem := range problems {
+		out = append(out, problem.String())
+	}
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name: "balanced",
+			content: `{% extends "base.html" %}
+{% block content %}
+<div class="p-strip">
+  <p>Intro
+  <ul><li>One<li>Two</ul>
+  <img src="{{ image }}" alt="">
+  {% if show %}<a href="{{ url }}">Link</a>{% else %}<br>{% endif %}
+  {% raw %}{{ not jinja {% if %}{% endraw %}
+  {# a comment #}
+</div>
+{% endblock %}`,
+		},
+		{
+			name:    "unclosed tag",
+			content: "<div>\n<span>Text\n</div>",
+			want:    []string{"line 2: <span> is not closed before </div>"},
+		},
+		{
+			name:    "unexpected end tag",
+			content: "<div>Text</div>\n</strong>",
+			want:    []string{"line 2: </strong> closes no open <strong>"},
+		},
+		{
+			name:    "tag open at the end",
+			content: "<section>\n<h2>Title</h2>",
+			want:    []string{"line 1: <section> is not closed at the end of the file"},
+		},
+		{
+			name:    "unclosed jinja statement",
+			content: "{% block content %}\n{% if x %}\n{% endblock %}",
+			want:    []string{"line 2: {% if %} is not closed before {% endblock %}"},
+		},
+		{
+			name:    "stray else",
+			content: "{% block a %}{% else %}{% endblock %}",
+			want:    []string{"line 1: {% else %} outside of an {% if %} or {% for %}"},
+		},
+		{
+			name:    "unterminated expression",
+			content: "<p>Hello {{ name </p>",
+			want:    []string{"line 1: {{ is not closed with }}"},
+		},
+		{
+			name:    "set without end",
+			content: "{% set x = 1 %}{% set y %}text{% endset %}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, messages(Check(tt.content))); diff != "" {
+				t.Errorf("Check() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// The stray </span> was already there; only the new problems count
+	before := "{% block title %}Title{% endblock %}\n{% block content %}<div>Old copy</div></span>{% endblock %}"
+	tests := []struct {
+		name  string
+		after string
+		want  []string
+	}{
+		{
+			name:  "copy changed",
+			after: "{% block title %}Title{% endblock %}\n{% block content %}<div>New <strong>copy</strong></div></span>{% endblock %}",
+		},
+		{
+			name:  "tag broken",
+			after: "{% block title %}Title{% endblock %}\n{% block content %}<div>New <strong>copy</div></span>{% endblock %}",
+			want:  []string{"line 2: <strong> is not closed before </div>"},
+		},
+		{
+			name:  "block deleted",
+			after: "{% block content %}<div>New copy</div></span>{% endblock %}",
+			want:  []string{"{% block title %} was removed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, messages(Compare(before, tt.after))); diff != "" {
+				t.Errorf("Compare() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package htmlcheck
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// jinjaDelimiters map the openers of Jinja expressions, statements and
+// comments to their closers.
+var jinjaDelimiters = map[string]string{
+	"{{": "}}",
+	"{%": "%}",
+	"{#": "#}",
+}
+
+// pairedStatements are the Jinja statements closed by an end<tag> statement.
+// {% set %} is only paired in its block form, without "=".
+var pairedStatements = map[string]bool{
+	"autoescape": true, "block": true, "call": true, "filter": true, "for": true,
+	"if": true, "macro": true, "set": true, "trans": true, "with": true,
+}
+
+var endRawPattern = regexp.MustCompile(`\{%[-+]?\s*endraw\s*[-+]?%\}`)
+
+// statement is a {% ... %} statement of a template.
+type statement struct {
+	tag  string
+	arg  string
+	line int
+}
+
+// jinjaSpan is where a Jinja construct is in a template.
+type jinjaSpan struct {
+	start, end int
+}
+
+// scanJinja returns the statements of a template, and a problem when a
+// construct isn't terminated; the rest of the template is then not scanned.
+func scanJinja(content string) ([]statement, []Problem) {
+	statements, _, problems := scanJinjaSpans(content)
+	return statements, problems
+}
+
+func scanJinjaSpans(content string) ([]statement, []jinjaSpan, []Problem) {
+	var statements []statement
+	var spans []jinjaSpan
+	for pos := 0; pos < len(content); {
+		i := strings.Index(content[pos:], "{")
+		if i < 0 || pos+i+1 >= len(content) {
+			break
+		}
+		start := pos + i
+		closer, ok := jinjaDelimiters[content[start:start+2]]
+		if !ok {
+			pos = start + 1
+			continue
+		}
+		line := 1 + strings.Count(content[:start], "\n")
+		j := strings.Index(content[start+2:], closer)
+		if j < 0 {
+			opener := content[start : start+2]
+			return statements, spans, []Problem{{
+				Kind:    KindUnterminated,
+				Line:    line,
+				Message: fmt.Sprintf("%s is not closed with %s", opener, closer),
+				key:     KindUnterminated + ":" + opener,
+			}}
+		}
+		end := start + 2 + j + len(closer)
+		spans = append(spans, jinjaSpan{start, end})
+		pos = end
+		if closer != "%}" {
+			continue
+		}
+
+		fields := strings.Fields(strings.Trim(content[start+2:end-2], "-+"))
+		if len(fields) == 0 {
+			continue
+		}
+		stmt := statement{tag: fields[0], line: line}
+		if len(fields) > 1 {
+			stmt.arg = fields[1]
+		}
+		if stmt.tag == "set" && strings.Contains(content[start:end], "=") {
+			// {% set x = y %} has no end
+			stmt.tag = "set="
+		}
+		statements = append(statements, stmt)
+
+		// The content of {% raw %} is not Jinja
+		if stmt.tag == "raw" {
+			loc := endRawPattern.FindStringIndex(content[pos:])
+			if loc == nil {
+				return statements, spans, []Problem{{
+					Kind:    KindUnbalancedJinja,
+					Line:    line,
+					Message: "{% raw %} is not closed with {% endraw %}",
+					key:     KindUnbalancedJinja + ":raw",
+				}}
+			}
+			spans = append(spans, jinjaSpan{pos, pos + loc[1]})
+			pos += loc[1]
+		}
+	}
+	return statements, spans, nil
+}
+
+// checkStatements reports statements that aren't balanced by their end<tag>,
+// and {% elif %} and {% else %} outside of the statements they continue.
+func checkStatements(statements []statement) []Problem {
+	var problems []Problem
+	var stack []statement
+	for _, stmt := range statements {
+		switch {
+		case pairedStatements[stmt.tag]:
+			stack = append(stack, stmt)
+		case stmt.tag == "elif" || stmt.tag == "else":
+			if len(stack) == 0 || (stack[len(stack)-1].tag != "if" && stack[len(stack)-1].tag != "for") {
+				problems = append(problems, Problem{
+					Kind:    KindUnbalancedJinja,
+					Line:    stmt.line,
+					Message: fmt.Sprintf("{%% %s %%} outside of an {%% if %%} or {%% for %%}", stmt.tag),
+					key:     KindUnbalancedJinja + ":" + stmt.tag,
+				})
+			}
+		case strings.HasPrefix(stmt.tag, "end") && stmt.tag != "endraw":
+			tag := strings.TrimPrefix(stmt.tag, "end")
+			i := len(stack) - 1
+			for i >= 0 && stack[i].tag != tag {
+				i--
+			}
+			if i < 0 {
+				problems = append(problems, Problem{
+					Kind:    KindUnbalancedJinja,
+					Line:    stmt.line,
+					Message: fmt.Sprintf("{%% %s %%} closes no open {%% %s %%}", stmt.tag, tag),
+					key:     KindUnbalancedJinja + ":" + stmt.tag,
+				})
+				continue
+			}
+			for _, open := range stack[i+1:] {
+				problems = append(problems, unclosedStatement(open, "before {% "+stmt.tag+" %}"))
+			}
+			stack = stack[:i]
+		}
+	}
+	for _, open := range stack {
+		problems = append(problems, unclosedStatement(open, "at the end of the file"))
+	}
+	return problems
+}
+
+func unclosedStatement(open statement, where string) Problem {
+	return Problem{
+		Kind:    KindUnbalancedJinja,
+		Line:    open.line,
+		Message: fmt.Sprintf("{%% %s %%} is not closed %s", open.tag, where),
+		key:     KindUnbalancedJinja + ":" + open.tag,
+	}
+}
+
+// stripJinja blanks out the Jinja constructs of a template, keeping its line
+// breaks, so the HTML around them can be parsed.
+func stripJinja(content string) string {
+	_, spans, _ := scanJinjaSpans(content)
+	if len(spans) == 0 {
+		return content
+	}
+	out := []byte(content)
+	for _, span := range spans {
+		for i := span.start; i < span.end; i++ {
+			if out[i] != '\n' {
+				out[i] = ' '
+			}
+		}
+	}
+	return string(out)
+}
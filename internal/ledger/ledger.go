@@ -0,0 +1,274 @@
+// Package ledger tracks the status of every Google Docs suggestion as it moves
+// through the pipeline, so any individual suggestion can be traced to its outcome.
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LedgerFile is the name of the suggestion status ledger written with each run's artifacts.
+const LedgerFile = "bauer-suggestion-status.json"
+
+// Status is the pipeline stage a suggestion has reached.
+type Status string
+
+const (
+	StatusExtracted  Status = "extracted"
+	StatusGrouped    Status = "grouped"
+	StatusSuperseded Status = "superseded" // Dropped in favour of an overlapping suggestion
+	StatusHeld       Status = "held"       // Awaiting approval, per the triage routes
+	StatusChunked    Status = "chunked"
+	StatusApplied    Status = "applied"
+	StatusFailed     Status = "failed"
+	StatusSkipped    Status = "skipped"
+	StatusVerified   Status = "verified"
+	StatusUnverified Status = "unverified" // Applied, but the expected text wasn't found in the modified files
+	StatusMerged     Status = "merged"
+	StatusDone       Status = "done" // Applied by an earlier run, per the state file
+)
+
+// Statuses lists every status in pipeline order.
+var Statuses = []Status{
+	StatusExtracted,
+	StatusGrouped,
+	StatusSuperseded,
+	StatusHeld,
+	StatusChunked,
+	StatusApplied,
+	StatusFailed,
+	StatusSkipped,
+	StatusVerified,
+	StatusUnverified,
+	StatusMerged,
+	StatusDone,
+}
+
+// reportPattern matches the status lines Copilot is asked to print after each chunk,
+// e.g. "STATUS suggest.abc123 applied updated hero title".
+var reportPattern = regexp.MustCompile("(?m)^[\\s>*`-]*STATUS\\s+`?([^\\s`]+)`?\\s+(applied|failed|skipped)\\b[`:\\s-]*(.*)$")
+
+// Transition records a single status change.
+type Transition struct {
+	Status Status    `json:"status"`
+	At     time.Time `json:"at"`
+	Note   string    `json:"note,omitempty"`
+}
+
+// Entry is the status record of a single suggestion.
+type Entry struct {
+	SuggestionID string       `json:"suggestion_id"`
+	Status       Status       `json:"status"`
+	Chunk        int          `json:"chunk,omitempty"`
+	Note         string       `json:"note,omitempty"`
+	Author       string       `json:"author,omitempty"`
+	History      []Transition `json:"history"`
+}
+
+// Ledger holds the status of every suggestion of a document, in extraction order.
+type Ledger struct {
+	DocumentID string   `json:"document_id"`
+	Entries    []*Entry `json:"entries"`
+
+	index map[string]*Entry
+}
+
+// New creates an empty ledger for a document.
+func New(documentID string) *Ledger {
+	return &Ledger{
+		DocumentID: documentID,
+		Entries:    []*Entry{},
+		index:      make(map[string]*Entry),
+	}
+}
+
+// Load reads a ledger written by Save.
+func Load(path string) (*Ledger, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ledger: %w", err)
+	}
+
+	l := New("")
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("failed to parse ledger: %w", err)
+	}
+	for _, entry := range l.Entries {
+		l.index[entry.SuggestionID] = entry
+	}
+	return l, nil
+}
+
+// Save writes the ledger as indented JSON.
+func (l *Ledger) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ledger: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write ledger: %w", err)
+	}
+	return nil
+}
+
+// Get returns the entry of a suggestion, or nil if it isn't tracked.
+func (l *Ledger) Get(id string) *Entry {
+	return l.index[id]
+}
+
+// Set moves a suggestion to a new status, tracking it if it's new.
+// Setting the status a suggestion already has is a no-op.
+func (l *Ledger) Set(id string, status Status, note string) {
+	entry := l.index[id]
+	if entry == nil {
+		entry = &Entry{SuggestionID: id}
+		l.index[id] = entry
+		l.Entries = append(l.Entries, entry)
+	}
+	if entry.Status == status && entry.Note == note {
+		return
+	}
+
+	entry.Status = status
+	entry.Note = note
+	entry.History = append(entry.History, Transition{
+		Status: status,
+		At:     time.Now(),
+		Note:   note,
+	})
+}
+
+// SetChunk records which chunk a suggestion was sent in and marks it chunked.
+func (l *Ledger) SetChunk(id string, chunk int) {
+	l.Set(id, StatusChunked, "")
+	l.index[id].Chunk = chunk
+}
+
+// Reopen moves the suggestions a run went on to chunk, apply or give up on
+// back to grouped, e.g. when the run's changes are rolled back, so a later
+// run tracks them again. It returns the number of suggestions reopened.
+func (l *Ledger) Reopen(note string) int {
+	reopened := 0
+	for _, entry := range l.Entries {
+		switch entry.Status {
+		case StatusChunked, StatusApplied, StatusFailed, StatusSkipped, StatusVerified, StatusUnverified:
+			l.Set(entry.SuggestionID, StatusGrouped, note)
+			entry.Chunk = 0
+			reopened++
+		}
+	}
+	return reopened
+}
+
+// Counts returns the number of suggestions currently in each status.
+func (l *Ledger) Counts() map[Status]int {
+	counts := make(map[Status]int)
+	for _, entry := range l.Entries {
+		counts[entry.Status]++
+	}
+	return counts
+}
+
+// Summary renders the counts as "applied: 3, failed: 1", in pipeline order.
+func (l *Ledger) Summary() string {
+	counts := l.Counts()
+	var parts []string
+	for _, status := range Statuses {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", status, counts[status]))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// WithStatus returns the entries currently in a status, in extraction order.
+func (l *Ledger) WithStatus(status Status) []*Entry {
+	var entries []*Entry
+	for _, entry := range l.Entries {
+		if entry.Status == status {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Authors returns the names of the suggestions' authors, in order of first appearance.
+func (l *Ledger) Authors() []string {
+	seen := make(map[string]bool)
+	var authors []string
+	for _, entry := range l.Entries {
+		if entry.Author != "" && !seen[entry.Author] {
+			seen[entry.Author] = true
+			authors = append(authors, entry.Author)
+		}
+	}
+	return authors
+}
+
+// Markdown renders the ledger as a PR body section: a summary line, followed by
+// the suggestions that need attention.
+func (l *Ledger) Markdown() string {
+	if len(l.Entries) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### Suggestion status\n\n")
+	fmt.Fprintf(&sb, "%d suggestions (%s)\n", len(l.Entries), l.Summary())
+	if authors := l.Authors(); len(authors) > 0 {
+		fmt.Fprintf(&sb, "\nSuggested by %s\n", strings.Join(authors, ", "))
+	}
+
+	// Changes that couldn't be verified are listed first, as they may be missing from the PR
+	if unverified := l.WithStatus(StatusUnverified); len(unverified) > 0 {
+		fmt.Fprintf(&sb, "\n**%d applied suggestions could not be verified**, review them before merging:\n\n", len(unverified))
+		for _, entry := range unverified {
+			fmt.Fprintf(&sb, "- `%s`: %s\n", entry.SuggestionID, entry.Note)
+		}
+	}
+
+	var attention []*Entry
+	for _, entry := range l.Entries {
+		if entry.Status == StatusFailed || entry.Status == StatusSkipped || entry.Status == StatusChunked ||
+			entry.Status == StatusSuperseded || entry.Status == StatusHeld {
+			attention = append(attention, entry)
+		}
+	}
+	if len(attention) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString("\n| Suggestion | Status | Note |\n| --- | --- | --- |\n")
+	for _, entry := range attention {
+		note := entry.Note
+		if entry.Status == StatusChunked && note == "" {
+			note = "no outcome reported"
+		}
+		fmt.Fprintf(&sb, "| `%s` | %s | %s |\n", entry.SuggestionID, entry.Status, strings.ReplaceAll(note, "|", "\\|"))
+	}
+	return sb.String()
+}
+
+// ReportLine is a single outcome reported by Copilot for a suggestion.
+type ReportLine struct {
+	SuggestionID string
+	Status       Status
+	Note         string
+}
+
+// ParseReport extracts the per-suggestion STATUS lines from Copilot's output.
+func ParseReport(output string) []ReportLine {
+	var lines []ReportLine
+	for _, match := range reportPattern.FindAllStringSubmatch(output, -1) {
+		lines = append(lines, ReportLine{
+			SuggestionID: match[1],
+			Status:       Status(match[2]),
+			Note:         strings.TrimSpace(strings.Trim(match[3], "`")),
+		})
+	}
+	return lines
+}
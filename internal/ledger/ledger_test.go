@@ -0,0 +1,138 @@
+package ledger
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseReport(t *testing.T) {
+	output := "Processed 3 locations.\n" +
+		"STATUS suggest.abc applied updated hero title\n" +
+		"- `STATUS suggest.def failed` anchor text not found\n" +
+		"  STATUS suggest.ghi skipped - stale\n" +
+		"The STATUS of everything else is fine.\n"
+
+	want := []ReportLine{
+		{SuggestionID: "suggest.abc", Status: StatusApplied, Note: "updated hero title"},
+		{SuggestionID: "suggest.def", Status: StatusFailed, Note: "anchor text not found"},
+		{SuggestionID: "suggest.ghi", Status: StatusSkipped, Note: "stale"},
+	}
+
+	if diff := cmp.Diff(want, ParseReport(output)); diff != "" {
+		t.Errorf("ParseReport() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLedger(t *testing.T) {
+	l := New("doc-1")
+	l.Set("a", StatusExtracted, "")
+	l.Set("b", StatusExtracted, "")
+	l.Set("a", StatusGrouped, "")
+	l.SetChunk("a", 2)
+	l.Set("a", StatusApplied, "done")
+	l.Set("a", StatusApplied, "done")
+
+	entry := l.Get("a")
+	if entry.Status != StatusApplied || entry.Chunk != 2 {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+	if len(entry.History) != 4 {
+		t.Errorf("Expected 4 transitions, got %d", len(entry.History))
+	}
+
+	wantCounts := map[Status]int{StatusApplied: 1, StatusExtracted: 1}
+	if diff := cmp.Diff(wantCounts, l.Counts()); diff != "" {
+		t.Errorf("Counts() mismatch (-want +got):\n%s", diff)
+	}
+	if got := l.Summary(); got != "extracted: 1, applied: 1" {
+		t.Errorf("Summary() = %q", got)
+	}
+
+	path := filepath.Join(t.TempDir(), LedgerFile)
+	if err := l.Save(path); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if loaded.DocumentID != "doc-1" || loaded.Get("a") == nil || loaded.Get("a").Status != StatusApplied {
+		t.Errorf("Loaded ledger doesn't match saved one: %+v", loaded)
+	}
+}
+
+func TestLedger_Reopen(t *testing.T) {
+	l := New("doc-1")
+	l.Set("applied", StatusApplied, "")
+	l.Set("failed", StatusFailed, "anchor not found")
+	l.Set("superseded", StatusSuperseded, "")
+	l.SetChunk("chunked", 1)
+
+	if got := l.Reopen("rolled back"); got != 3 {
+		t.Errorf("Reopen() = %d, want 3", got)
+	}
+	want := map[Status]int{StatusGrouped: 3, StatusSuperseded: 1}
+	if diff := cmp.Diff(want, l.Counts()); diff != "" {
+		t.Errorf("Counts() mismatch (-want +got):\n%s", diff)
+	}
+	if entry := l.Get("chunked"); entry.Chunk != 0 || entry.Note != "rolled back" {
+		t.Errorf("Reopen() left entry %+v", entry)
+	}
+}
+
+func TestLedgerMarkdown(t *testing.T) {
+	l := New("doc-1")
+	l.Set("ok", StatusVerified, "")
+	l.Set("bad", StatusFailed, "anchor | missing")
+	l.SetChunk("quiet", 1)
+	l.Set("missing", StatusUnverified, "expected text not found in index.html")
+	l.Get("ok").Author = "Ana"
+	l.Get("bad").Author = "Ben"
+	l.Get("quiet").Author = "Ana"
+
+	md := l.Markdown()
+	for _, want := range []string{
+		"4 suggestions (chunked: 1, failed: 1, verified: 1, unverified: 1)",
+		"**1 applied suggestions could not be verified**",
+		"- `missing`: expected text not found in index.html",
+		"Suggested by Ana, Ben",
+		"| `bad` | failed | anchor \\| missing |",
+		"| `quiet` | chunked | no outcome reported |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "`ok`") {
+		t.Errorf("Markdown() should not list verified suggestions:\n%s", md)
+	}
+}
+
+func TestParseChunkReport(t *testing.T) {
+	output := "Updated the hero.\n\n" +
+		"```json\n[{\"location_id\": \"loc-1\"}]\n```\n\n" +
+		"```json\n{\n  \"files_modified\": [\"templates/aws.html\"],\n  \"applied\": [\"suggest.abc\"],\n" +
+		"  \"skipped\": [{\"id\": \"suggest.def\", \"reason\": \"stale\"}]\n}\n```\n"
+
+	report, err := ParseChunkReport(output)
+	if err != nil {
+		t.Fatalf("ParseChunkReport() error = %v", err)
+	}
+	want := []ReportLine{
+		{SuggestionID: "suggest.abc", Status: StatusApplied},
+		{SuggestionID: "suggest.def", Status: StatusSkipped, Note: "stale"},
+	}
+	if diff := cmp.Diff(want, report.Lines()); diff != "" {
+		t.Errorf("Lines() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"templates/aws.html"}, report.FilesModified); diff != "" {
+		t.Errorf("FilesModified mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := ParseChunkReport("STATUS suggest.abc applied"); err != ErrNoReport {
+		t.Errorf("ParseChunkReport() without a report error = %v, want ErrNoReport", err)
+	}
+}
@@ -0,0 +1,60 @@
+package ledger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrNoReport is returned when an output doesn't end with a JSON chunk report.
+var ErrNoReport = errors.New("no JSON report in output")
+
+// jsonBlockPattern matches fenced JSON code blocks.
+var jsonBlockPattern = regexp.MustCompile("(?s)```json[ \\t]*\\n(.*?)\\n[ \\t]*```")
+
+// ChunkReport is the JSON report Copilot ends the output of each chunk with.
+type ChunkReport struct {
+	FilesModified []string            `json:"files_modified"`
+	Applied       []string            `json:"applied"`
+	Skipped       []SkippedSuggestion `json:"skipped"`
+}
+
+// SkippedSuggestion is a suggestion Copilot could not apply.
+type SkippedSuggestion struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// ParseChunkReport returns the last JSON code block of output that is a chunk
+// report, i.e. has an "applied" list.
+func ParseChunkReport(output string) (*ChunkReport, error) {
+	blocks := jsonBlockPattern.FindAllStringSubmatch(output, -1)
+	for i := len(blocks) - 1; i >= 0; i-- {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(blocks[i][1]), &fields); err != nil {
+			continue
+		}
+		if _, ok := fields["applied"]; !ok {
+			continue
+		}
+		report := &ChunkReport{}
+		if err := json.Unmarshal([]byte(blocks[i][1]), report); err != nil {
+			return nil, fmt.Errorf("failed to parse chunk report: %w", err)
+		}
+		return report, nil
+	}
+	return nil, ErrNoReport
+}
+
+// Lines returns the outcome of each suggestion in the report.
+func (r *ChunkReport) Lines() []ReportLine {
+	var lines []ReportLine
+	for _, id := range r.Applied {
+		lines = append(lines, ReportLine{SuggestionID: id, Status: StatusApplied})
+	}
+	for _, skipped := range r.Skipped {
+		lines = append(lines, ReportLine{SuggestionID: skipped.ID, Status: StatusSkipped, Note: skipped.Reason})
+	}
+	return lines
+}
@@ -0,0 +1,164 @@
+// Package locate finds the files of the target repository that suggestions
+// apply to, by searching them for the suggestions' anchor text, so Copilot
+// doesn't have to guess a file from the page URL.
+package locate
+
+import (
+	"cmp"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"bauer/internal/gdocs"
+	"bauer/internal/staleness"
+)
+
+const (
+	// minProbeLength is the shortest anchor text searched for; shorter text,
+	// e.g. a single word, matches too many files to tell them apart
+	minProbeLength = 8
+
+	// maxFileSize skips generated or vendored files that can't be page copy
+	maxFileSize = 1 << 20
+
+	// maxFiles bounds the search when the target repository is not a site,
+	// e.g. when run from the wrong directory
+	maxFiles = 20000
+)
+
+// searchedExtensions are the files that can hold page copy.
+var searchedExtensions = map[string]bool{
+	".html":   true,
+	".jinja":  true,
+	".jinja2": true,
+	".j2":     true,
+	".md":     true,
+	".txt":    true,
+	".yaml":   true,
+	".yml":    true,
+}
+
+// skippedDirs are never searched, besides hidden directories.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// Resolver searches the text files of a repository for suggestion anchors.
+type Resolver struct {
+	root          string
+	normalization gdocs.Normalization
+	files         []file
+}
+
+type file struct {
+	path string // relative to root, with forward slashes
+	text string // visible text, normalized
+}
+
+// Candidate is a file that contains some of a location's anchors.
+type Candidate struct {
+	Path    string
+	Matches int
+}
+
+// NewResolver reads the text files of the repository at root. Their visible
+// text and the anchors are both normalized with n before matching.
+func NewResolver(root string, n gdocs.Normalization) (*Resolver, error) {
+	r := &Resolver{root: root, normalization: n}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || skippedDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !searchedExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		if len(r.files) >= maxFiles {
+			return fmt.Errorf("more than %d files in %s", maxFiles, root)
+		}
+
+		info, err := d.Info()
+		if err != nil || info.Size() > maxFileSize {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		r.files = append(r.files, file{
+			path: filepath.ToSlash(rel),
+			text: n.Apply(staleness.PageText(string(content))),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search repository: %w", err)
+	}
+	return r, nil
+}
+
+// Candidates ranks the files that contain the anchors of a location's
+// suggestions by the number of suggestions they match, most first. Ties are
+// broken by path.
+func (r *Resolver) Candidates(group gdocs.LocationGroupedSuggestions) []Candidate {
+	var probes []string
+	for _, sugg := range group.Suggestions {
+		probe := r.normalization.Apply(staleness.AnchorProbe(sugg))
+		if len(probe) >= minProbeLength {
+			probes = append(probes, probe)
+		}
+	}
+	if len(probes) == 0 {
+		return nil
+	}
+
+	var candidates []Candidate
+	for _, f := range r.files {
+		matches := 0
+		for _, probe := range probes {
+			if strings.Contains(f.text, probe) {
+				matches++
+			}
+		}
+		if matches > 0 {
+			candidates = append(candidates, Candidate{Path: f.path, Matches: matches})
+		}
+	}
+	slices.SortFunc(candidates, func(a, b Candidate) int {
+		return cmp.Or(cmp.Compare(b.Matches, a.Matches), cmp.Compare(a.Path, b.Path))
+	})
+	return candidates
+}
+
+// ResolveGroups sets the ResolvedFile of each location group to its best
+// candidate. Metadata groups and groups no file matches are left unresolved.
+// It returns the number of groups resolved.
+func (r *Resolver) ResolveGroups(groups []gdocs.LocationGroupedSuggestions) int {
+	resolved := 0
+	for i := range groups {
+		if groups[i].Location.InMetadata {
+			continue
+		}
+		candidates := r.Candidates(groups[i])
+		if len(candidates) == 0 {
+			continue
+		}
+		groups[i].ResolvedFile = candidates[0].Path
+		resolved++
+	}
+	return resolved
+}
@@ -0,0 +1,79 @@
+package locate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"bauer/internal/gdocs"
+)
+
+func TestResolveGroups(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"templates/index.html":          `<h1>Ubuntu on AWS</h1><p>Run it today.</p>`,
+		"templates/aws/index.html":      `<h1>Ubuntu on AWS</h1><p>Run it today.</p><p>Certified &ldquo;images&rdquo; for every region.</p>`,
+		"templates/azure.html":          `<p>Ubuntu on Azure</p>`,
+		"node_modules/pkg/readme.md":    `Ubuntu on AWS. Run it today. Certified "images" for every region.`,
+		".git/COMMIT_EDITMSG":           `Ubuntu on AWS`,
+		"static/js/main.js":             `// Ubuntu on AWS`,
+		"templates/aws/_partial.jinja2": `{% block x %}Nothing relevant{% endblock %}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	groups := []gdocs.LocationGroupedSuggestions{
+		{
+			Location: gdocs.SuggestionLocation{Section: "Body"},
+			Suggestions: []gdocs.GroupedActionableSuggestion{
+				{ID: "a", Change: gdocs.SuggestionChange{Type: "replace", OriginalText: "Ubuntu on AWS", NewText: "Ubuntu on Amazon"}},
+				{ID: "b", Change: gdocs.SuggestionChange{Type: "delete", OriginalText: `Certified "images"`}},
+			},
+		},
+		{
+			Location: gdocs.SuggestionLocation{Section: "Body"},
+			Suggestions: []gdocs.GroupedActionableSuggestion{
+				{ID: "c", Change: gdocs.SuggestionChange{Type: "delete", OriginalText: "Not on any page"}},
+			},
+		},
+		{
+			Location: gdocs.SuggestionLocation{Section: "Body", InMetadata: true},
+			Suggestions: []gdocs.GroupedActionableSuggestion{
+				{ID: "d", Change: gdocs.SuggestionChange{Type: "replace", OriginalText: "Ubuntu on Azure"}},
+			},
+		},
+	}
+
+	resolver, err := NewResolver(root, gdocs.Normalization{Quotes: true})
+	if err != nil {
+		t.Fatalf("NewResolver() error = %v", err)
+	}
+
+	wantCandidates := []Candidate{
+		{Path: "templates/aws/index.html", Matches: 2},
+		{Path: "templates/index.html", Matches: 1},
+	}
+	if diff := cmp.Diff(wantCandidates, resolver.Candidates(groups[0])); diff != "" {
+		t.Errorf("Candidates() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := resolver.ResolveGroups(groups); got != 1 {
+		t.Errorf("ResolveGroups() = %d, want 1", got)
+	}
+	var got []string
+	for _, group := range groups {
+		got = append(got, group.ResolvedFile)
+	}
+	if diff := cmp.Diff([]string{"templates/aws/index.html", "", ""}, got); diff != "" {
+		t.Errorf("ResolvedFile mismatch (-want +got):\n%s", diff)
+	}
+}
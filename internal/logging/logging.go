@@ -0,0 +1,190 @@
+// Package logging sets up the structured logger of the commands: its level,
+// format and destination, and the naming of per-run log files.
+package logging
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Formats of log records.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Destinations of log records. Both writes to stdout and a file.
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+	OutputBoth   = "both"
+)
+
+// Options configure a logger.
+type Options struct {
+	// Level is debug, info, warn or error
+	Level string
+
+	// Format is json or text
+	Format string
+
+	// Output is stdout, stderr, file or both (stdout and a file)
+	Output string
+
+	// File is the path of the log file; empty names it after the run,
+	// see RunFile
+	File string
+}
+
+// Flags registers the logging flags on fs, with defaults, and returns the
+// options they set.
+func Flags(fs *flag.FlagSet, defaults Options) *Options {
+	o := &Options{}
+	fs.StringVar(&o.Level, "log-level", defaults.Level, "Level of the logs: debug, info, warn or error")
+	fs.StringVar(&o.Format, "log-format", defaults.Format, "Format of the logs: json or text")
+	fs.StringVar(&o.Output, "log-output", defaults.Output, "Where logs are written: stdout, stderr, file or both (stdout and a file)")
+	fs.StringVar(&o.File, "log-file", defaults.File, "Log file, with --log-output file or both (default: named after the run and its start time)")
+	return o
+}
+
+// Validate checks the level, format and destination.
+func (o Options) Validate() error {
+	if _, err := o.level(); err != nil {
+		return err
+	}
+	switch o.Format {
+	case FormatJSON, FormatText:
+	default:
+		return fmt.Errorf("invalid log format: %q (must be json or text)", o.Format)
+	}
+	switch o.Output {
+	case OutputStdout, OutputStderr, OutputFile, OutputBoth:
+	default:
+		return fmt.Errorf("invalid log output: %q (must be stdout, stderr, file or both)", o.Output)
+	}
+	return nil
+}
+
+func (o Options) level() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(o.Level)); err != nil {
+		return level, fmt.Errorf("invalid log level: %q (must be debug, info, warn or error)", o.Level)
+	}
+	return level, nil
+}
+
+// unsafeName matches what doesn't belong in a file name, e.g. the slashes
+// of a document URL.
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// RunFile names the log file of a run, e.g. of a document, after it and
+// when it started: bauer-<name>-<20060102T150405Z>.log.
+func RunFile(name string, start time.Time) string {
+	name = strings.Trim(unsafeName.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		return fmt.Sprintf("bauer-%s.log", start.UTC().Format("20060102T150405Z"))
+	}
+	return fmt.Sprintf("bauer-%s-%s.log", name, start.UTC().Format("20060102T150405Z"))
+}
+
+// New returns a logger for o. Logs written to a file go to o.File, or else to
+// RunFile(run, now) in the current directory. The returned function closes
+// the file, if any.
+func New(o Options, run string) (*slog.Logger, func() error, error) {
+	if err := o.Validate(); err != nil {
+		return nil, nil, err
+	}
+	level, _ := o.level()
+
+	var writers []io.Writer
+	switch o.Output {
+	case OutputStdout, OutputBoth:
+		writers = append(writers, os.Stdout)
+	case OutputStderr:
+		writers = append(writers, os.Stderr)
+	}
+	closeFile := func() error { return nil }
+	if o.Output == OutputFile || o.Output == OutputBoth {
+		path := o.File
+		if path == "" {
+			path = RunFile(run, time.Now())
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		writers = append(writers, f)
+		closeFile = f.Close
+	}
+
+	handlers := make([]slog.Handler, 0, len(writers))
+	for _, w := range writers {
+		opts := &slog.HandlerOptions{Level: level}
+		if o.Format == FormatJSON {
+			handlers = append(handlers, slog.NewJSONHandler(w, opts))
+		} else {
+			handlers = append(handlers, slog.NewTextHandler(w, opts))
+		}
+	}
+	if len(handlers) == 1 {
+		return slog.New(handlers[0]), closeFile, nil
+	}
+	return slog.New(multiHandler(handlers)), closeFile, nil
+}
+
+// Setup makes the logger of o the default one; see New.
+func Setup(o Options, run string) (func() error, error) {
+	logger, closeFile, err := New(o, run)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(logger)
+	return closeFile, nil
+}
+
+// multiHandler writes each record with all of its handlers.
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, h := range m {
+		if h.Enabled(ctx, record.Level) {
+			errs = append(errs, h.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunFile(t *testing.T) {
+	start := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	for _, tt := range []struct {
+		name string
+		want string
+	}{
+		{"doc-a_1", "bauer-doc-a_1-20260304T050607Z.log"},
+		{"https://docs.google.com/document/d/doc-a", "bauer-https_docs.google.com_document_d_doc-a-20260304T050607Z.log"},
+		{"", "bauer-20260304T050607Z.log"},
+	} {
+		if got := RunFile(tt.name, start); got != tt.want {
+			t.Errorf("RunFile(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.log")
+	logger, closeFile, err := New(Options{Level: "warn", Format: FormatJSON, Output: OutputFile, File: path}, "doc-a")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	logger.Info("skipped")
+	logger.Warn("kept", "doc_id", "doc-a")
+	if err := closeFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("log file has %d records, want 1:\n%s", len(lines), data)
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("log record isn't JSON: %v", err)
+	}
+	if record["msg"] != "kept" || record["doc_id"] != "doc-a" {
+		t.Errorf("log record = %v, want the warning", record)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, o := range []Options{
+		{Level: "verbose", Format: FormatText, Output: OutputStdout},
+		{Level: "info", Format: "xml", Output: OutputStdout},
+		{Level: "info", Format: FormatText, Output: "syslog"},
+	} {
+		if err := o.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", o)
+		}
+	}
+	if err := (Options{Level: "debug", Format: FormatJSON, Output: OutputBoth}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
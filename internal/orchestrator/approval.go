@@ -0,0 +1,247 @@
+package orchestrator
+
+import (
+	"bauer/internal/config"
+	"bauer/internal/direct"
+	"bauer/internal/preview"
+	"bauer/internal/progress"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Approval modes of config.Config.Approval.
+const (
+	// ApprovalPrompt asks on the terminal
+	ApprovalPrompt = "prompt"
+	// ApprovalFile waits for ApprovedFile or RejectedFile in the output directory
+	ApprovalFile = "file"
+)
+
+// Sentinel files of ApprovalFile, created in the output directory of the run.
+// A rejection's reason can be written to RejectedFile.
+const (
+	ApprovedFile = "bauer-approved"
+	RejectedFile = "bauer-rejected"
+)
+
+// approvalPollInterval is how often ApprovalFile looks for its files.
+var approvalPollInterval = 2 * time.Second
+
+// webhookTimeout bounds the request announcing a plan to the approval webhook.
+const webhookTimeout = 10 * time.Second
+
+// ErrRejected is returned by Execute for runs whose plan was rejected at the
+// approval gate. Nothing was executed; the chunks are in the output directory.
+var ErrRejected = errors.New("plan rejected")
+
+// ApprovalRequest is the plan of a run waiting for approval.
+type ApprovalRequest struct {
+	DocumentID    string
+	DocumentTitle string
+	Chunks        int
+	Suggestions   int
+
+	// DirectPatches are the patches applied without Copilot, once approved;
+	// the patches themselves are listed in direct.PatchesFile
+	DirectPatches int
+
+	// OutputDir has the chunk prompts, and PreviewPath the HTML preview of
+	// the suggestions
+	OutputDir   string
+	PreviewPath string
+}
+
+// Summary describes the plan in a sentence.
+func (r ApprovalRequest) Summary() string {
+	title := r.DocumentTitle
+	if title == "" {
+		title = r.DocumentID
+	}
+	if r.DirectPatches > 0 {
+		return fmt.Sprintf("Bauer is ready to apply %d suggestions of %q in %d chunks and %d direct patches.", r.Suggestions, title, r.Chunks, r.DirectPatches)
+	}
+	return fmt.Sprintf("Bauer is ready to apply %d suggestions of %q in %d chunks.", r.Suggestions, title, r.Chunks)
+}
+
+// Approver decides whether the plan of a run is executed.
+type Approver interface {
+	// Instructions tell people how to approve or reject the plan
+	Instructions(request ApprovalRequest) string
+	// Await blocks until the plan is approved (nil), rejected (an error
+	// wrapping ErrRejected) or ctx is done
+	Await(ctx context.Context, request ApprovalRequest) error
+}
+
+type approverKey struct{}
+
+// WithApprover returns ctx with the approver of its runs, e.g. the API's for
+// jobs submitted with require_approval. It takes precedence over
+// config.Config.Approval.
+func WithApprover(ctx context.Context, approver Approver) context.Context {
+	return context.WithValue(ctx, approverKey{}, approver)
+}
+
+// approverFor returns the approver of a run, or nil when its plan is
+// executed without approval.
+func approverFor(ctx context.Context, cfg *config.Config) Approver {
+	if approver, ok := ctx.Value(approverKey{}).(Approver); ok && approver != nil {
+		return approver
+	}
+	switch cfg.Approval {
+	case ApprovalPrompt:
+		return promptApprover{in: os.Stdin, out: os.Stderr}
+	case ApprovalFile:
+		return fileApprover{dir: cfg.OutputDir}
+	}
+	return nil
+}
+
+// awaitApproval holds a run between planning and execution until its plan is
+// approved, announcing it to cfg.ApprovalWebhook when set.
+func awaitApproval(ctx context.Context, cfg *config.Config, approver Approver, request ApprovalRequest) error {
+	reportStage(ctx, StageApproval)
+	instructions := approver.Instructions(request)
+	progress.Report(ctx, progress.Event{Type: progress.EventApprovalRequested, Message: request.Summary() + " " + instructions})
+	slog.Info("Waiting for approval",
+		slog.Int("chunks", request.Chunks),
+		slog.Int("suggestions", request.Suggestions),
+		slog.String("preview_file", request.PreviewPath),
+		slog.String("instructions", instructions),
+	)
+	if cfg.ApprovalWebhook != "" {
+		if err := postApprovalRequest(ctx, cfg.ApprovalWebhook, request, instructions); err != nil {
+			// People can still approve; the webhook only tells them to
+			slog.Warn("Failed to post approval request", slog.String("error", err.Error()))
+		}
+	}
+
+	waitStart := time.Now()
+	if err := approver.Await(ctx, request); err != nil {
+		slog.Warn("Plan not approved", slog.String("error", err.Error()))
+		return err
+	}
+	slog.Info("Plan approved", slog.Duration("waited", time.Since(waitStart)))
+	return nil
+}
+
+// postApprovalRequest posts the plan to a webhook as {"text": ...}, the body
+// Slack incoming webhooks take.
+func postApprovalRequest(ctx context.Context, url string, request ApprovalRequest, instructions string) error {
+	text := request.Summary() + "\n" + instructions
+	if request.PreviewPath != "" {
+		text += "\nPreview: " + request.PreviewPath
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode approval request: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create approval request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post approval request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("approval webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// promptApprover asks on the terminal.
+type promptApprover struct {
+	in  io.Reader
+	out io.Writer
+}
+
+func (p promptApprover) Instructions(request ApprovalRequest) string {
+	return "Answer y on the terminal to execute the chunks."
+}
+
+func (p promptApprover) Await(ctx context.Context, request ApprovalRequest) error {
+	fmt.Fprintf(p.out, "\n%s\n", request.Summary())
+	if request.PreviewPath != "" {
+		fmt.Fprintf(p.out, "Preview: %s\n", request.PreviewPath)
+	}
+	fmt.Fprintf(p.out, "Chunks: %s\n", request.OutputDir)
+	if request.DirectPatches > 0 {
+		fmt.Fprintf(p.out, "Direct patches: %s\n", filepath.Join(request.OutputDir, direct.PatchesFile))
+	}
+	fmt.Fprint(p.out, "Execute the chunks? [y/N] ")
+
+	answer := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(p.in).ReadString('\n')
+		answer <- strings.ToLower(strings.TrimSpace(line))
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case a := <-answer:
+		if a == "y" || a == "yes" {
+			return nil
+		}
+		return fmt.Errorf("%w on the terminal", ErrRejected)
+	}
+}
+
+// fileApprover waits for ApprovedFile or RejectedFile in dir.
+type fileApprover struct {
+	dir string
+}
+
+func (f fileApprover) Instructions(request ApprovalRequest) string {
+	return fmt.Sprintf("Create %s to execute the chunks, or %s to stop the run.",
+		filepath.Join(f.dir, ApprovedFile), filepath.Join(f.dir, RejectedFile))
+}
+
+func (f fileApprover) Await(ctx context.Context, request ApprovalRequest) error {
+	approved, rejected := filepath.Join(f.dir, ApprovedFile), filepath.Join(f.dir, RejectedFile)
+	// Files of an earlier run don't decide this one
+	os.Remove(approved)
+	os.Remove(rejected)
+
+	ticker := time.NewTicker(approvalPollInterval)
+	defer ticker.Stop()
+	for {
+		if reason, err := os.ReadFile(rejected); err == nil {
+			if text := strings.TrimSpace(string(reason)); text != "" {
+				return fmt.Errorf("%w: %s", ErrRejected, text)
+			}
+			return ErrRejected
+		}
+		if _, err := os.Stat(approved); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// writtenPreview returns the preview of a run, or "" when it wasn't written.
+func writtenPreview(cfg *config.Config) string {
+	path := filepath.Join(cfg.OutputDir, preview.PreviewFile)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
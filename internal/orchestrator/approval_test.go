@@ -0,0 +1,62 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bauer/internal/config"
+)
+
+func TestAwaitApproval(t *testing.T) {
+	approvalPollInterval = 5 * time.Millisecond
+	request := ApprovalRequest{DocumentTitle: "Pricing", Chunks: 2, Suggestions: 5}
+
+	var posted string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		posted = body["text"]
+	}))
+	defer webhook.Close()
+
+	dir := t.TempDir()
+	cfg := &config.Config{OutputDir: dir, Approval: ApprovalFile, ApprovalWebhook: webhook.URL}
+	approver := approverFor(context.Background(), cfg)
+	// A decision of an earlier run is ignored
+	os.WriteFile(filepath.Join(dir, RejectedFile), nil, 0644)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		os.WriteFile(filepath.Join(dir, ApprovedFile), nil, 0644)
+	}()
+	if err := awaitApproval(context.Background(), cfg, approver, request); err != nil {
+		t.Fatalf("awaitApproval() error = %v", err)
+	}
+	if !strings.Contains(posted, `5 suggestions of "Pricing" in 2 chunks`) || !strings.Contains(posted, ApprovedFile) {
+		t.Errorf("webhook text = %q", posted)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		os.WriteFile(filepath.Join(dir, RejectedFile), []byte("wrong page\n"), 0644)
+	}()
+	err := fileApprover{dir: dir}.Await(context.Background(), request)
+	if !errors.Is(err, ErrRejected) || !strings.HasSuffix(err.Error(), ": wrong page") {
+		t.Errorf("Await() of a rejected plan error = %v", err)
+	}
+
+	prompt := promptApprover{in: strings.NewReader("n\n"), out: &strings.Builder{}}
+	if err := prompt.Await(context.Background(), request); !errors.Is(err, ErrRejected) {
+		t.Errorf("Await() answered n error = %v, want ErrRejected", err)
+	}
+	if approverFor(context.Background(), &config.Config{}) != nil {
+		t.Error("approverFor() without approval returned an approver")
+	}
+}
@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"bauer/internal/gdocs"
+)
+
+// conflictChooser returns the chooser of a conflict strategy. The interactive
+// strategy asks on in and out.
+func conflictChooser(strategy string, in io.Reader, out io.Writer) (gdocs.ConflictChooser, error) {
+	if strategy == gdocs.ConflictInteractive {
+		return promptConflicts(in, out), nil
+	}
+	return gdocs.ConflictStrategyChooser(strategy)
+}
+
+// promptConflicts asks the operator which of two overlapping suggestions to keep.
+func promptConflicts(in io.Reader, out io.Writer) gdocs.ConflictChooser {
+	reader := bufio.NewReader(in)
+	return func(location gdocs.SuggestionLocation, kept, other gdocs.GroupedActionableSuggestion) (bool, error) {
+		where := location.Section
+		if location.ParentHeading != "" {
+			where += " > " + location.ParentHeading
+		}
+		fmt.Fprintf(out, "\nOverlapping suggestions in %s:\n", where)
+		fmt.Fprintf(out, "  1) %s\n", describeSuggestion(kept))
+		fmt.Fprintf(out, "  2) %s\n", describeSuggestion(other))
+
+		for {
+			fmt.Fprint(out, "Keep which suggestion? [1/2]: ")
+			line, err := reader.ReadString('\n')
+			switch strings.TrimSpace(line) {
+			case "1":
+				return false, nil
+			case "2":
+				return true, nil
+			}
+			if err != nil {
+				return false, fmt.Errorf("failed to read conflict choice: %w", err)
+			}
+		}
+	}
+}
+
+// describeSuggestion summarises a suggestion on one line, e.g.
+// `abc (replace by Ana): "old" -> "new"`.
+func describeSuggestion(sugg gdocs.GroupedActionableSuggestion) string {
+	by := ""
+	if sugg.Author != "" {
+		by = " by " + sugg.Author
+	}
+	return fmt.Sprintf("%s (%s%s): %q -> %q", sugg.ID, sugg.Change.Type, by, sugg.Change.OriginalText, sugg.Change.NewText)
+}
@@ -0,0 +1,231 @@
+package orchestrator
+
+import (
+	"bauer/internal/config"
+	"bauer/internal/gdocs"
+	"bytes"
+	"cmp"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Policies of config.Config.UnexpectedChanges.
+const (
+	UnexpectedWarn = "warn"
+	UnexpectedFail = "fail"
+)
+
+// FileDiff is how a file of the target repository changed since its last
+// commit.
+type FileDiff struct {
+	Path    string `json:"path"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+
+	// Binary files have no line counts
+	Binary bool `json:"binary,omitempty"`
+}
+
+// DiffStats are the changes a run left in the target repository.
+type DiffStats struct {
+	Files   []FileDiff `json:"files"`
+	Added   int        `json:"added"`
+	Removed int        `json:"removed"`
+
+	// Unexpected are the changed files that are neither the template of a
+	// page of the document nor a file its suggestions were found in
+	Unexpected []string `json:"unexpected,omitempty"`
+
+	// Blocked is set when the unexpected files failed the run
+	Blocked bool `json:"blocked,omitempty"`
+}
+
+// collectDiffStats returns the changes of the target repository of a run, with
+// the files it wasn't expected to change, or why they couldn't be listed,
+// e.g. outside git.
+func collectDiffStats(cfg *config.Config, dir string, result *gdocs.ProcessingResult) (*DiffStats, error) {
+	root, err := gitRoot(dir)
+	if err != nil {
+		slog.Warn("Diff stats not collected", slog.String("error", err.Error()))
+		return nil, err
+	}
+	diffs, err := workingTreeDiff(root, runArtifacts(cfg)...)
+	if err != nil {
+		slog.Warn("Diff stats not collected", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	stats := &DiffStats{Files: diffs}
+	for _, diff := range diffs {
+		stats.Added += diff.Added
+		stats.Removed += diff.Removed
+	}
+	// Without a file to expect, e.g. pages whose templates weren't found,
+	// every change is as expected as the next
+	if expected := expectedFiles(cfg, root, result); len(expected) > 0 {
+		for _, diff := range diffs {
+			if !slices.Contains(expected, diff.Path) {
+				stats.Unexpected = append(stats.Unexpected, diff.Path)
+			}
+		}
+	}
+	slog.Info("Changes of the run",
+		slog.Int("files", len(stats.Files)),
+		slog.Int("added", stats.Added),
+		slog.Int("removed", stats.Removed),
+		slog.Int("unexpected_files", len(stats.Unexpected)),
+	)
+	return stats, nil
+}
+
+// checkUnexpectedChanges applies the UnexpectedChanges policy to the files a
+// run changed unexpectedly: they are logged, and with UnexpectedFail fail the
+// run like a failed validation.
+func checkUnexpectedChanges(cfg *config.Config, stats *DiffStats) error {
+	if stats == nil || len(stats.Unexpected) == 0 {
+		return nil
+	}
+	for _, path := range stats.Unexpected {
+		slog.Warn("File changed outside the pages of the document", slog.String("file", path))
+	}
+	if cmp.Or(cfg.UnexpectedChanges, UnexpectedWarn) != UnexpectedFail {
+		return nil
+	}
+	stats.Blocked = true
+	return fmt.Errorf("%w: unexpected files changed: %s", ErrValidationFailed, strings.Join(stats.Unexpected, ", "))
+}
+
+// expectedFiles returns the files of the repository at root a run may change,
+// relative to it: the templates of the pages of the document and the files
+// its suggestions were found in.
+func expectedFiles(cfg *config.Config, root string, result *gdocs.ProcessingResult) []string {
+	repoRoot := cmp.Or(cfg.TargetRepo, cfg.WorkDir)
+	var paths []string
+	for _, page := range result.GroupsByPage(result.GroupedSuggestions) {
+		if page.URL == "" {
+			continue
+		}
+		if template, err := cfg.Sites.TemplatePath(repoRoot, page.URL); err == nil {
+			paths = append(paths, template)
+		}
+	}
+	for _, group := range result.GroupedSuggestions {
+		if group.ResolvedFile != "" {
+			paths = append(paths, filepath.Join(repoRoot, filepath.FromSlash(group.ResolvedFile)))
+		}
+	}
+
+	var files []string
+	for _, path := range paths {
+		if rel, ok := relativeTo(root, path); ok && !slices.Contains(files, rel) {
+			files = append(files, rel)
+		}
+	}
+	return files
+}
+
+// gitRoot returns the top level of the git repository of dir.
+func gitRoot(dir string) (string, error) {
+	out, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// relativeTo returns path relative to root, with forward slashes, and whether
+// it is inside root.
+func relativeTo(root, path string) (string, bool) {
+	// The top level git reports has its symbolic links resolved
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// workingTreeDiff returns the changes of the git repository at root since
+// its last commit, from `git diff --numstat`; new files count as added lines.
+// Files at or under the excluded paths, e.g. the run's output directory, are
+// left out.
+func workingTreeDiff(root string, exclude ...string) ([]FileDiff, error) {
+	var excluded []string
+	for _, path := range exclude {
+		if rel, ok := relativeTo(root, path); ok {
+			excluded = append(excluded, rel)
+		}
+	}
+	skip := func(path string) bool {
+		for _, prefix := range excluded {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	numstat, err := gitOutput(root, "diff", "HEAD", "--numstat", "--no-renames", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var diffs []FileDiff
+	for _, entry := range strings.Split(numstat, "\x00") {
+		fields := strings.SplitN(entry, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		diff := FileDiff{Path: fields[2]}
+		if skip(diff.Path) {
+			continue
+		}
+		if fields[0] == "-" {
+			diff.Binary = true
+		} else {
+			diff.Added, _ = strconv.Atoi(fields[0])
+			diff.Removed, _ = strconv.Atoi(fields[1])
+		}
+		diffs = append(diffs, diff)
+	}
+
+	untracked, err := gitOutput(root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range strings.Split(untracked, "\x00") {
+		if path == "" || skip(path) {
+			continue
+		}
+		diff := FileDiff{Path: path}
+		content, err := os.ReadFile(filepath.Join(root, path))
+		switch {
+		case err != nil:
+		case bytes.IndexByte(content, 0) != -1:
+			diff.Binary = true
+		default:
+			diff.Added = bytes.Count(content, []byte("\n"))
+			if len(content) > 0 && content[len(content)-1] != '\n' {
+				diff.Added++
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// runArtifacts are the files a run writes itself, which aren't changes to
+// the site: its output directory, extraction and raw document.
+func runArtifacts(cfg *config.Config) []string {
+	return []string{
+		cfg.OutputDir,
+		filepath.Join(cfg.WorkDir, ExtractionResultFile),
+		filepath.Join(cfg.WorkDir, gdocs.RawDocumentFile),
+	}
+}
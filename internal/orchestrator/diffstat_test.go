@@ -0,0 +1,73 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bauer/internal/config"
+	"bauer/internal/gdocs"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCollectDiffStats(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Bauer")
+	t.Setenv("GIT_AUTHOR_EMAIL", "bauer@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Bauer")
+	t.Setenv("GIT_COMMITTER_EMAIL", "bauer@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	dir := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("templates/index.html", "<h1>Ubuntu</h1>\n<p>Old copy</p>\n<p>Footer</p>\n")
+	for _, args := range [][]string{{"init"}, {"add", "."}, {"commit", "-m", "Initial commit"}} {
+		if _, err := gitOutput(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("templates/index.html", "<h1>Ubuntu</h1>\n<p>New copy</p>\n<p>Footer</p>\n")
+	write("templates/pricing.html", "<h1>Pricing</h1>\n<p>Free</p>")
+	// The artifacts of the run aren't changes
+	write("bauer-output/chunk-1.md", "prompt\n")
+	write(ExtractionResultFile, "{}\n")
+
+	cfg := &config.Config{TargetRepo: dir, WorkDir: dir, OutputDir: filepath.Join(dir, "bauer-output")}
+	result := &gdocs.ProcessingResult{
+		GroupedSuggestions: []gdocs.LocationGroupedSuggestions{{ResolvedFile: "templates/index.html"}},
+	}
+	stats, err := collectDiffStats(cfg, dir, result)
+	if err != nil {
+		t.Fatalf("collectDiffStats() error = %v", err)
+	}
+	want := &DiffStats{
+		Files: []FileDiff{
+			{Path: "templates/index.html", Added: 1, Removed: 1},
+			{Path: "templates/pricing.html", Added: 2},
+		},
+		Added:      3,
+		Removed:    1,
+		Unexpected: []string{"templates/pricing.html"},
+	}
+	if diff := cmp.Diff(want, stats); diff != "" {
+		t.Errorf("collectDiffStats() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := checkUnexpectedChanges(cfg, stats); err != nil || stats.Blocked {
+		t.Errorf("checkUnexpectedChanges(warn) = %v, blocked %t, want nil", err, stats.Blocked)
+	}
+	cfg.UnexpectedChanges = UnexpectedFail
+	if err := checkUnexpectedChanges(cfg, stats); !errors.Is(err, ErrValidationFailed) || !stats.Blocked {
+		t.Errorf("checkUnexpectedChanges(fail) = %v, blocked %t, want ErrValidationFailed", err, stats.Blocked)
+	}
+}
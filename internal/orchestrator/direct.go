@@ -0,0 +1,96 @@
+package orchestrator
+
+import (
+	"bauer/internal/config"
+	"bauer/internal/direct"
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+)
+
+// planDirectPatches plans the suggestions simple enough to be patched
+// without Copilot, and returns the result the chunks are planned from, with
+// the rest. Nothing is patched until applyDirectPatches, once the plan is
+// approved and within the run's limits.
+func planDirectPatches(cfg *config.Config, result *gdocs.ProcessingResult) (*gdocs.ProcessingResult, *direct.Plan) {
+	plan := direct.NewPlan(directRoot(cfg), result.GroupedSuggestions, cfg.DirectThreshold)
+	patchesPath := filepath.Join(cfg.OutputDir, direct.PatchesFile)
+	if err := plan.Save(patchesPath); err != nil {
+		// The list is for review; the run doesn't depend on it
+		slog.Warn("Failed to write direct patches", slog.String("error", err.Error()))
+	}
+	if len(plan.Deferred) > 0 {
+		slog.Info("Suggestions deferred to Copilot for their matches' confidence",
+			slog.Int("count", len(plan.Deferred)),
+			slog.Float64("threshold", plan.Threshold),
+		)
+	}
+	if len(plan.Patches) == 0 {
+		return result, plan
+	}
+	slog.Info("Suggestions to patch directly",
+		slog.Int("patches", len(plan.Patches)),
+		slog.Int("files", len(plan.Files())),
+		slog.Int("remaining_locations", len(plan.Remaining)),
+		slog.String("patches_file", patchesPath),
+	)
+
+	remaining := *result
+	remaining.GroupedSuggestions = plan.Remaining
+	return &remaining, plan
+}
+
+// directPatchesChunk is the chunk number the template damage and policy
+// violations of the direct patches are recorded under.
+const directPatchesChunk = 0
+
+// applyDirectPatches applies the patches of a plan and marks their
+// suggestions applied. The patches are held to the template checks and
+// protected paths as chunks are: patches to protected paths are reverted,
+// and their suggestions failed.
+func applyDirectPatches(cfg *config.Config, plan *direct.Plan, statusLedger *ledger.Ledger, guard *templateGuard, protected *pathPolicy) error {
+	if plan == nil || len(plan.Patches) == 0 {
+		return nil
+	}
+	if err := plan.Apply(directRoot(cfg)); err != nil {
+		return fmt.Errorf("failed to apply direct patches: %w", err)
+	}
+	// Reverted before the templates are checked, as after chunks
+	if protected != nil {
+		protected.check(directPatchesChunk)
+	}
+	if guard != nil {
+		guard.check(directPatchesChunk)
+	}
+
+	reverted := map[string]string{}
+	for _, violation := range protected.found() {
+		if violation.Chunk == directPatchesChunk {
+			reverted[violation.File] = violation.Pattern
+		}
+	}
+	for _, patch := range plan.Patches {
+		for _, id := range patch.SuggestionIDs {
+			if pattern, ok := reverted[patch.File]; ok {
+				statusLedger.Set(id, ledger.StatusFailed, fmt.Sprintf("direct patch to %s reverted: protected by %s", patch.File, pattern))
+				continue
+			}
+			statusLedger.Set(id, ledger.StatusApplied, "applied directly to "+patch.File)
+		}
+	}
+	slog.Info("Suggestions applied directly",
+		slog.Int("patches", len(plan.Patches)),
+		slog.Int("files", len(plan.Files())),
+	)
+	return nil
+}
+
+// directRoot is the repository direct patches are applied to.
+func directRoot(cfg *config.Config) string {
+	if cfg.TargetRepo == "" {
+		return "."
+	}
+	return cfg.TargetRepo
+}
@@ -0,0 +1,106 @@
+package orchestrator
+
+import (
+	"bauer/internal/config"
+	"bauer/internal/gdocs"
+	"bauer/internal/prompt"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrLimitExceeded is returned by Execute for runs over one of the limits of
+// their configuration, e.g. config.Config.MaxLinesChanged. Their changes
+// aren't pushed; the run report has what they did up to then.
+var ErrLimitExceeded = errors.New("run limit exceeded")
+
+// limitExceeded is the error of a limit a run went over.
+type limitExceeded struct {
+	limit string
+}
+
+func (e *limitExceeded) Error() string { return ErrLimitExceeded.Error() + ": " + e.limit }
+func (e *limitExceeded) Unwrap() error { return ErrLimitExceeded }
+
+// limitError returns the error of a limit a run went over, and logs it.
+func limitError(format string, args ...any) error {
+	err := &limitExceeded{limit: fmt.Sprintf(format, args...)}
+	slog.Error("Run limit exceeded", slog.String("limit", err.limit))
+	return err
+}
+
+// exceededLimit describes the limit err is about, or returns "".
+func exceededLimit(err error) string {
+	var exceeded *limitExceeded
+	if errors.As(err, &exceeded) {
+		return exceeded.limit
+	}
+	return ""
+}
+
+// checkSuggestionLimit checks the suggestions a run would apply against
+// MaxSuggestions.
+func checkSuggestionLimit(cfg *config.Config, groups []gdocs.LocationGroupedSuggestions) error {
+	if cfg.MaxSuggestions == 0 {
+		return nil
+	}
+	suggestions := 0
+	for _, group := range groups {
+		suggestions += len(group.Suggestions)
+	}
+	if suggestions > cfg.MaxSuggestions {
+		return limitError("%d suggestions to apply, over the limit of %d", suggestions, cfg.MaxSuggestions)
+	}
+	return nil
+}
+
+// checkChunkLimit checks the chunks of a run against MaxChunks.
+func checkChunkLimit(cfg *config.Config, chunks []prompt.ChunkResult) error {
+	if cfg.MaxChunks > 0 && len(chunks) > cfg.MaxChunks {
+		return limitError("%d chunks planned, over the limit of %d", len(chunks), cfg.MaxChunks)
+	}
+	return nil
+}
+
+// checkCopilotLimit checks the time chunks have been executing against
+// MaxCopilotDuration, before the next chunk starts.
+func checkCopilotLimit(cfg *config.Config, elapsed time.Duration, nextChunk, totalChunks int) error {
+	// Validated before the document was processed
+	limit, _ := cfg.CopilotDurationLimit()
+	if limit > 0 && elapsed > limit {
+		return limitError("chunks executed for %s, over the limit of %s; stopped before chunk %d of %d",
+			elapsed.Round(time.Second), limit, nextChunk, totalChunks)
+	}
+	return nil
+}
+
+// copilotBudget returns the context of a chunk session, which ends when the
+// MaxCopilotDuration of chunks executed since start runs out.
+func copilotBudget(ctx context.Context, cfg *config.Config, start time.Time) (context.Context, context.CancelFunc) {
+	limit, _ := cfg.CopilotDurationLimit()
+	if limit == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, start.Add(limit))
+}
+
+// checkChangeLimits checks the changes of the target repository against
+// MaxFilesModified and MaxLinesChanged. Changes that couldn't be listed, for
+// diffErr, fail the check: they might be over the limits.
+func checkChangeLimits(cfg *config.Config, stats *DiffStats, diffErr error) error {
+	if cfg.MaxFilesModified == 0 && cfg.MaxLinesChanged == 0 {
+		return nil
+	}
+	if diffErr != nil {
+		return limitError("changes couldn't be checked against the limits: %v", diffErr)
+	}
+	if cfg.MaxFilesModified > 0 && len(stats.Files) > cfg.MaxFilesModified {
+		return limitError("%d files modified, over the limit of %d", len(stats.Files), cfg.MaxFilesModified)
+	}
+	if lines := stats.Added + stats.Removed; cfg.MaxLinesChanged > 0 && lines > cfg.MaxLinesChanged {
+		return limitError("%d lines changed, over the limit of %d", lines, cfg.MaxLinesChanged)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"bauer/internal/config"
+	"bauer/internal/copilotcli"
+	"bauer/internal/gdocs"
+	"bauer/internal/prompt"
+)
+
+func TestCheckChangeLimits(t *testing.T) {
+	stats := &DiffStats{
+		Files: []FileDiff{
+			{Path: "templates/index.html", Added: 1, Removed: 1},
+			{Path: "templates/pricing.html", Added: 2},
+		},
+		Added:   3,
+		Removed: 1,
+	}
+	for _, tt := range []struct {
+		files, lines int
+		want         string
+	}{
+		{files: 2, lines: 4},
+		{files: 1, want: "2 files modified, over the limit of 1"},
+		{lines: 3, want: "4 lines changed, over the limit of 3"},
+	} {
+		cfg := &config.Config{MaxFilesModified: tt.files, MaxLinesChanged: tt.lines}
+		err := checkChangeLimits(cfg, stats, nil)
+		if got := exceededLimit(err); got != tt.want {
+			t.Errorf("checkChangeLimits(files %d, lines %d) = %q, want %q", tt.files, tt.lines, got, tt.want)
+		}
+		if tt.want != "" && !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("checkChangeLimits() error = %v, want ErrLimitExceeded", err)
+		}
+	}
+
+	// Changes that couldn't be listed might be over the limits
+	cfg := &config.Config{MaxLinesChanged: 500}
+	err := checkChangeLimits(cfg, nil, errors.New("not a git repository"))
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("checkChangeLimits() without changes error = %v, want ErrLimitExceeded", err)
+	}
+	if err := checkChangeLimits(&config.Config{}, nil, errors.New("not a git repository")); err != nil {
+		t.Errorf("checkChangeLimits() without limits error = %v, want nil", err)
+	}
+}
+
+// stallingExecutor runs sessions until their context ends.
+type stallingExecutor struct{ fixingExecutor }
+
+func (s *stallingExecutor) ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber int, model string) (string, copilotcli.Usage, error) {
+	<-ctx.Done()
+	return "", copilotcli.Usage{}, ctx.Err()
+}
+
+func TestExecuteChunksCopilotBudget(t *testing.T) {
+	chunks := []prompt.ChunkResult{
+		{ChunkNumber: 1, Filename: "chunk-1.md", Content: "first"},
+		{ChunkNumber: 2, Filename: "chunk-2.md", Content: "second"},
+	}
+	manifest := prompt.NewManifest("doc", chunks)
+	cfg := &config.Config{OutputDir: t.TempDir(), MaxCopilotDuration: "50ms"}
+
+	// The session is cut off when the budget runs out, not after it ends
+	outputs, _, err := executeCopilotChunks(context.Background(), chunks, cfg, &stallingExecutor{}, manifest, &gdocs.ProcessingResult{}, nil, nil)
+	if want := "stopped during chunk 1 of 2"; !errors.Is(err, ErrLimitExceeded) || !strings.Contains(exceededLimit(err), want) {
+		t.Fatalf("executeCopilotChunks() error = %v, want a limit stopped during chunk 1", err)
+	}
+	if len(outputs) != 0 {
+		t.Errorf("executeCopilotChunks() outputs = %d, want 0", len(outputs))
+	}
+}
@@ -0,0 +1,37 @@
+package orchestrator
+
+import (
+	"bauer/internal/config"
+	"bauer/internal/prompt"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// ErrInvalidChunks is returned when generated chunks fail the lint, before
+// any is executed: sessions given a broken prompt only waste their time.
+var ErrInvalidChunks = errors.New("invalid chunks")
+
+// lintChunks checks the chunks before they're executed, logging the issues.
+// The error wraps ErrInvalidChunks when any issue is an error.
+func lintChunks(cfg *config.Config, chunks []prompt.ChunkResult) ([]prompt.LintIssue, error) {
+	issues := prompt.LintChunks(chunks, cfg.MaxChunkTokens)
+	for _, issue := range issues {
+		attrs := []any{slog.Int("chunk_number", issue.Chunk), slog.String("issue", issue.Message)}
+		if issue.Severity == prompt.LintError {
+			slog.Error("Invalid chunk", attrs...)
+		} else {
+			slog.Warn("Chunk lint warning", attrs...)
+		}
+	}
+	errs := prompt.LintErrors(issues)
+	if len(errs) == 0 {
+		return issues, nil
+	}
+	messages := make([]string, len(errs))
+	for i, issue := range errs {
+		messages[i] = fmt.Sprintf("chunk %d: %s", issue.Chunk, issue.Message)
+	}
+	return issues, fmt.Errorf("%w: %s", ErrInvalidChunks, strings.Join(messages, "; "))
+}
@@ -3,24 +3,61 @@ package orchestrator
 import (
 	"bauer/internal/config"
 	"bauer/internal/copilotcli"
+	"bauer/internal/direct"
+	"bauer/internal/docsource"
+	"bauer/internal/executor"
 	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"bauer/internal/locate"
+	"bauer/internal/preview"
+	"bauer/internal/progress"
 	"bauer/internal/prompt"
+	"bauer/internal/staleness"
+	"bauer/internal/triage"
+	"bauer/internal/vanilla"
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
+// ExtractionResultFile is the file the full ProcessingResult of a run is
+// written to, in the run's work directory.
+const ExtractionResultFile = "bauer-doc-suggestions.json"
+
 // OrchestrationResult contains all outputs from the orchestration flow.
 type OrchestrationResult struct {
 	// Extraction
 	ExtractionResult   *gdocs.ProcessingResult
 	ExtractionDuration time.Duration
 
+	// Only populated when a staleness check was requested
+	StalenessReport *staleness.Report
+
+	// Ledger tracks the status of every suggestion through the pipeline
+	Ledger *ledger.Ledger
+
+	// NewSuggestions are the suggestions no earlier run recorded in the
+	// state file, when earlier runs recorded others; see config.Config.StateFile
+	NewSuggestions []string
+
+	// DirectPlan is the suggestions patched without Copilot, with
+	// config.Config.DirectApply
+	DirectPlan *direct.Plan
+
+	// Triage is the classification of the suggestions, with config.Config.Triage
+	Triage *triage.Report
+
 	// Prompt generation
 	Chunks       []prompt.ChunkResult
+	ChunkLint    []prompt.LintIssue
+	Manifest     *prompt.Manifest
 	PlanDuration time.Duration
 
 	// Only populated if not dry run
@@ -28,9 +65,36 @@ type OrchestrationResult struct {
 	CopilotDuration time.Duration
 	SummaryDuration time.Duration
 
+	// Usage is the token usage of the chunks, retries included, the
+	// validation fixes and the summary; each output has the usage of its chunk
+	Usage copilotcli.Usage
+
+	// Validation is how the changes were validated, when a validation
+	// command was given
+	Validation *ValidationResult
+
+	// TemplateDamage is the damage chunks did to HTML templates; any blocks
+	// the changes like a failed validation
+	TemplateDamage []TemplateDamage
+
+	// PolicyViolations are the changes chunks made to protected paths,
+	// which were reverted
+	PolicyViolations []PolicyViolation
+
+	// Diff is the changes the run left in the target repository
+	Diff *DiffStats
+
+	// LimitExceeded describes the limit of the configuration the run went
+	// over, which blocks its changes; see ErrLimitExceeded
+	LimitExceeded string
+
 	// Metadata
 	TotalDuration time.Duration
 	DryRun        bool
+
+	// Report consolidates the result, as written to ReportFile and
+	// ReportMarkdownFile
+	Report *Report
 }
 
 // Orchestrator defines the interface for executing the BAU orchestration flow.
@@ -38,6 +102,12 @@ type Orchestrator interface {
 	Execute(ctx context.Context, cfg *config.Config) (*OrchestrationResult, error)
 }
 
+// Extractor extracts the suggestions of a document, without planning or
+// applying them; the extraction is written to the work directory.
+type Extractor interface {
+	Extract(ctx context.Context, cfg *config.Config) (*OrchestrationResult, error)
+}
+
 // DefaultOrchestrator is the standard implementation of the Orchestrator interface.
 type DefaultOrchestrator struct{}
 
@@ -51,125 +121,232 @@ func NewOrchestrator() *DefaultOrchestrator {
 // Returns: OrchestrationResult and error
 func (o *DefaultOrchestrator) Execute(ctx context.Context, cfg *config.Config) (*OrchestrationResult, error) {
 	startTime := time.Now()
-
-	// 1. Initialize GDocs Client and extract from doc
-	extractionStart := time.Now()
-	gdocsClient, err := gdocs.NewClient(ctx, cfg.CredentialsPath)
+	cfg, err := cfg.Resolved()
 	if err != nil {
-		slog.Error("Failed to initialize Google Docs client",
-			slog.String("error", err.Error()),
-			slog.String("credentials_path", cfg.CredentialsPath),
-		)
-		return nil, fmt.Errorf("failed to initialize Google Docs client: %w", err)
+		return nil, err
 	}
 
-	// 2. Process Document
-	result, err := gdocsClient.ProcessDocument(ctx, cfg.DocID)
+	extracted, err := extract(ctx, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to process document: %w", err)
+		return nil, err
 	}
-	extractionDuration := time.Since(extractionStart)
-
-	// 3. Write extraction result to file
-	outputJSON, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		slog.Error("Failed to marshal output", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to generate output JSON: %w", err)
-	}
-	outputFile := "bauer-doc-suggestions.json"
-	err = os.WriteFile(outputFile, outputJSON, 0644)
-	if err != nil {
-		slog.Error("Failed to write output file", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to write output file: %w", err)
-	}
-	slog.Info("Extraction complete",
-		slog.String("output_file", outputFile),
-		slog.Duration("extraction_duration", extractionDuration),
-	)
+	result, statusLedger := extracted.ExtractionResult, extracted.Ledger
+	stalenessReport, extractionDuration := extracted.StalenessReport, extracted.ExtractionDuration
 
 	// 4. Initialize Prompt Engine
+	reportStage(ctx, StagePlanning)
 	planStart := time.Now()
 	engine, err := prompt.NewEngine(cfg.PageRefresh)
 	if err != nil {
 		slog.Error("Failed to initialize prompt engine", slog.String("error", err.Error()))
 		return nil, fmt.Errorf("failed to initialize prompt engine: %w", err)
 	}
+	engine.Order = cfg.ChunkOrder
+	if cfg.TemplateDir != "" {
+		engine.Templates, err = prompt.LoadTemplates(cfg.TemplateDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+		}
+		slog.Info("Custom prompt templates loaded", slog.String("template_dir", cfg.TemplateDir))
+	}
+
+	// Groups held or skipped by triage are left out of the chunks
+	planned := result
+	var triageReport *triage.Report
+	if cfg.Triage != "" && !cfg.PageRefresh {
+		planned, triageReport, err = triageSuggestions(ctx, cfg, result, statusLedger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Runs over the suggestion limit patch nothing, but their chunks are
+	// planned for the report
+	limitErr := checkSuggestionLimit(cfg, planned.GroupedSuggestions)
+
+	// Simple suggestions are patched directly, and left out of the chunks;
+	// the patches are applied with the chunks, once approved
+	var directPlan *direct.Plan
+	if cfg.DirectApply && !cfg.PageRefresh && limitErr == nil {
+		planned, directPlan = planDirectPatches(cfg, planned)
+	}
+	directPatches := 0
+	if directPlan != nil {
+		directPatches = len(directPlan.Patches)
+	}
 
 	// 5. Generate Prompts from Chunks
-	totalLocations := len(result.GroupedSuggestions)
+	totalLocations := len(planned.GroupedSuggestions)
 	slog.Info("Generating prompts",
 		slog.Int("total_locations", totalLocations),
 		slog.Int("chunk_size", cfg.ChunkSize),
+		slog.String("chunk_order", cfg.ChunkOrder),
 	)
-	chunks, err := engine.GenerateAllChunks(
-		result,
-		cfg.ChunkSize,
-		cfg.OutputDir,
-	)
+	var chunks []prompt.ChunkResult
+	if cfg.PageRefresh {
+		chunks, err = engine.GenerateSectionChunks(result, cfg.ChunkSize, cfg.OutputDir)
+	} else {
+		chunks, err = engine.GenerateAllChunks(planned, cfg.ChunkSize, cfg.OutputDir)
+	}
 	if err != nil {
 		slog.Error("Failed to generate prompts", slog.String("error", err.Error()))
 		return nil, fmt.Errorf("failed to generate prompts: %w", err)
 	}
 
 	planDuration := time.Since(planStart)
+	if deps := engine.Dependencies; deps != nil && len(deps.Components) > 0 {
+		slog.Info("Locations with overlapping anchors kept in the same chunk",
+			slog.Int("groups", len(deps.Components)),
+			slog.Int("overlaps", len(deps.Edges)),
+			slog.String("report", filepath.Join(cfg.OutputDir, prompt.DependencyFile)),
+		)
+	}
 
 	for _, chunk := range chunks {
+		for _, id := range chunk.SuggestionIDs {
+			statusLedger.SetChunk(id, chunk.ChunkNumber)
+		}
 		slog.Info("Generated chunk",
 			slog.Int("chunk_number", chunk.ChunkNumber),
 			slog.String("filename", chunk.Filename),
+			slog.String("page_url", chunk.PageURL),
 			slog.Int("location_count", chunk.LocationCount),
 		)
 	}
 
-	// If dry run, return early
-	if cfg.DryRun {
+	manifest := prompt.NewManifest(result.DocumentID, chunks)
+	if cfg.Resume {
+		resumeManifest(cfg, manifest)
+	}
+	saveManifest(cfg, manifest)
+	chunkLint, lintErr := lintChunks(cfg, chunks)
+	if limitErr == nil {
+		limitErr = checkChunkLimit(cfg, chunks)
+	}
+
+	// Runs requiring approval wait for it before anything is patched or
+	// executed
+	var approvalErr error
+	if approver := approverFor(ctx, cfg); approver != nil && !cfg.DryRun && lintErr == nil && limitErr == nil && (len(chunks) > 0 || directPatches > 0) {
+		suggestions := 0
+		for _, chunk := range chunks {
+			suggestions += len(chunk.SuggestionIDs)
+		}
+		if directPlan != nil {
+			for _, patch := range directPlan.Patches {
+				suggestions += len(patch.SuggestionIDs)
+			}
+		}
+		approvalErr = awaitApproval(ctx, cfg, approver, ApprovalRequest{
+			DocumentID:    result.DocumentID,
+			DocumentTitle: result.DocumentTitle,
+			Chunks:        len(chunks),
+			DirectPatches: directPatches,
+			Suggestions:   suggestions,
+			OutputDir:     cfg.OutputDir,
+			PreviewPath:   writtenPreview(cfg),
+		})
+	}
+
+	// If dry run, or the chunks are invalid, over a limit or weren't
+	// approved, return early; dry runs report them without failing
+	if cfg.DryRun || lintErr != nil || limitErr != nil || approvalErr != nil {
 		totalDuration := time.Since(startTime)
+		saveLedger(cfg, statusLedger)
 
-		return &OrchestrationResult{
+		dryRunResult := &OrchestrationResult{
 			ExtractionResult:   result,
 			ExtractionDuration: extractionDuration,
+			StalenessReport:    stalenessReport,
+			Ledger:             statusLedger,
+			NewSuggestions:     extracted.NewSuggestions,
+			DirectPlan:         directPlan,
+			Triage:             triageReport,
 			Chunks:             chunks,
+			ChunkLint:          chunkLint,
+			Manifest:           manifest,
 			PlanDuration:       planDuration,
 			CopilotOutputs:     []copilotcli.ChunkOutput{},
 			CopilotDuration:    0,
 			SummaryDuration:    0,
 			TotalDuration:      totalDuration,
-			DryRun:             true,
-		}, nil
+			DryRun:             cfg.DryRun,
+			LimitExceeded:      exceededLimit(limitErr),
+		}
+		dryRunResult.Report = NewReport(dryRunResult)
+		saveReport(cfg, dryRunResult.Report)
+		if cfg.DryRun {
+			return dryRunResult, nil
+		}
+		return dryRunResult, cmp.Or(lintErr, limitErr, approvalErr)
+	}
+
+	// 6. Execute via the selected executor (Copilot SDK by default)
+	reportStage(ctx, StageExecution)
+	cwd := cfg.WorkDir
+
+	// Templates are checked after the direct patches and each chunk, to know
+	// which of them damaged them
+	var guard *templateGuard
+	if !cfg.SkipTemplateCheck {
+		guard, err = newTemplateGuard(cwd)
+		if err != nil {
+			slog.Warn("Template check disabled", slog.String("error", err.Error()))
+		}
+	}
+	var protected *pathPolicy
+	if len(cfg.ProtectedPaths) > 0 {
+		protected, err = newPathPolicy(cwd, cfg.ProtectedPaths)
+		if err != nil {
+			slog.Warn("Protected paths not enforced", slog.String("error", err.Error()))
+		}
+	}
+	if err := applyDirectPatches(cfg, directPlan, statusLedger, guard, protected); err != nil {
+		return nil, err
 	}
 
-	// 6. Execute via Copilot SDK
-	cwd, err := os.Getwd()
+	summaryInstructions, err := engine.Templates.RenderSummary(prompt.SummaryData{
+		DocumentTitle: result.DocumentTitle,
+		ChunkCount:    len(chunks),
+	})
 	if err != nil {
-		slog.Error("Failed to get working directory", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to get working directory: %w", err)
+		return nil, fmt.Errorf("failed to render summary template: %w", err)
 	}
 
-	slog.Info("Initializing Copilot client", slog.String("cwd", cwd))
-	copilotClient, err := copilotcli.NewClient(cwd)
+	slog.Info("Initializing executor", slog.String("executor", cfg.Executor), slog.String("cwd", cwd))
+	chunkExecutor, err := executor.Open(executor.Options{
+		Name:                cfg.Executor,
+		Cwd:                 cwd,
+		SummaryInstructions: summaryInstructions,
+		Sandbox:             sandbox(cfg, cwd),
+	})
 	if err != nil {
-		slog.Error("Failed to create Copilot client", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to create Copilot client: %w", err)
+		slog.Error("Failed to create executor", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to create executor: %w", err)
 	}
 
-	// Start the Copilot CLI server once
-	if err := copilotClient.Start(); err != nil {
-		// Attempt to stop the client if Start failed
-		if stopErr := copilotClient.Stop(); stopErr != nil {
-			slog.Error("Failed to stop Copilot client after start failure", slog.String("error", stopErr.Error()))
+	// Start the executor (e.g. the Copilot CLI server) once
+	if err := chunkExecutor.Start(); err != nil {
+		// Attempt to stop the executor if Start failed
+		if stopErr := chunkExecutor.Stop(); stopErr != nil {
+			slog.Error("Failed to stop executor after start failure", slog.String("error", stopErr.Error()))
 		}
-		slog.Error("Failed to start Copilot", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to start Copilot: %w", err)
+		slog.Error("Failed to start executor", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to start %s executor: %w", cfg.Executor, err)
 	}
 	defer func() {
-		if err := copilotClient.Stop(); err != nil {
-			slog.Error("Failed to stop Copilot client", slog.String("error", err.Error()))
+		if err := chunkExecutor.Stop(); err != nil {
+			slog.Error("Failed to stop executor", slog.String("error", err.Error()))
 		}
 	}()
 
-	// Execute chunks via Copilot SDK
-	chunkOutputs, copilotDuration, err := executeCopilotChunks(ctx, chunks, cfg, copilotClient)
-	if err != nil {
+	// Execute chunks
+	// Runs over the Copilot time limit go on with the chunks they executed,
+	// for the report
+	chunkOutputs, copilotDuration, err := executeCopilotChunks(ctx, chunks, cfg, chunkExecutor, manifest, result, guard, protected)
+	if errors.Is(err, ErrLimitExceeded) {
+		limitErr = err
+	} else if err != nil {
 		slog.Error("Copilot execution failed", slog.String("error", err.Error()))
 		return nil, fmt.Errorf("copilot execution failed: %w", err)
 	}
@@ -179,12 +356,42 @@ func (o *DefaultOrchestrator) Execute(ctx context.Context, cfg *config.Config) (
 		slog.Duration("total_duration", copilotDuration),
 	)
 
+	var usage copilotcli.Usage
+	for _, output := range chunkOutputs {
+		usage.Add(output.TotalUsage())
+	}
+
+	// Validate, and fix, the changes before they are verified, as fixes may
+	// touch the changed text. A failed validation is returned with the result.
+	// Damaged templates aren't left to the validation command to fix, as the
+	// chunk that damaged them may have dropped copy too.
+	var validation *ValidationResult
+	validationErr := guard.err()
+	if cfg.ValidateCommand != "" && validationErr == nil && limitErr == nil {
+		reportStage(ctx, StageValidation)
+		var fixUsage copilotcli.Usage
+		validation, fixUsage, validationErr = validateChanges(ctx, cfg, chunkExecutor, cwd, len(chunks)+1, guard, protected)
+		usage.Add(fixUsage)
+	}
+	diffStats, diffErr := collectDiffStats(cfg, cwd, result)
+	if limitErr == nil {
+		limitErr = checkChangeLimits(cfg, diffStats, diffErr)
+	}
+	validationErr = cmp.Or(validationErr, checkUnexpectedChanges(cfg, diffStats))
+
+	recordChunkOutcomes(statusLedger, chunkOutputs, result)
+	verifyAppliedSuggestions(ctx, cfg, result, statusLedger)
+	verifyChunkChanges(cfg, chunks, chunkOutputs, result, statusLedger)
+
 	// 7. Generate summary if multiple chunks
 	summaryDuration := time.Duration(0)
-	if len(chunks) > 1 {
+	if len(chunks) > 1 && limitErr == nil {
+		reportStage(ctx, StageSummary)
 		summaryStart := time.Now()
 
-		if err := copilotClient.GenerateSummary(ctx, chunkOutputs, cfg.SummaryModel); err != nil {
+		summaryUsage, err := chunkExecutor.GenerateSummary(ctx, chunkOutputs, cfg.SummaryModel)
+		usage.Add(summaryUsage)
+		if err != nil {
 			slog.Error("Summary generation failed", slog.String("error", err.Error()))
 			// Summary failure is not fatal; continue with results
 		} else {
@@ -196,26 +403,275 @@ func (o *DefaultOrchestrator) Execute(ctx context.Context, cfg *config.Config) (
 	}
 
 	totalDuration := time.Since(startTime)
+	saveLedger(cfg, statusLedger)
+	slog.Info("Token usage", slog.String("usage", usage.String()))
 
-	return &OrchestrationResult{
+	orchestrationResult := &OrchestrationResult{
 		ExtractionResult:   result,
 		ExtractionDuration: extractionDuration,
+		StalenessReport:    stalenessReport,
+		Ledger:             statusLedger,
+		NewSuggestions:     extracted.NewSuggestions,
+		DirectPlan:         directPlan,
+		Triage:             triageReport,
 		Chunks:             chunks,
+		ChunkLint:          chunkLint,
+		Manifest:           manifest,
 		PlanDuration:       planDuration,
 		CopilotOutputs:     chunkOutputs,
 		CopilotDuration:    copilotDuration,
 		SummaryDuration:    summaryDuration,
+		Usage:              usage,
+		Validation:         validation,
+		TemplateDamage:     guard.found(),
+		PolicyViolations:   protected.found(),
+		Diff:               diffStats,
 		TotalDuration:      totalDuration,
 		DryRun:             false,
+		LimitExceeded:      exceededLimit(limitErr),
+	}
+	orchestrationResult.Report = NewReport(orchestrationResult)
+	saveReport(cfg, orchestrationResult.Report)
+	saveState(cfg, statusLedger, orchestrationResult.Report.RunID)
+	return orchestrationResult, cmp.Or(limitErr, validationErr)
+}
+
+// Extract runs the extraction stage alone: the suggestions of the document
+// are extracted, filtered and grouped, and written to ExtractionResultFile
+// with the preview and snapshot, for a later run to plan from; see
+// config.Config.Extraction.
+func (o *DefaultOrchestrator) Extract(ctx context.Context, cfg *config.Config) (*OrchestrationResult, error) {
+	startTime := time.Now()
+	cfg, err := cfg.Resolved()
+	if err != nil {
+		return nil, err
+	}
+	extracted, err := extract(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	saveLedger(cfg, extracted.Ledger)
+	extracted.TotalDuration = time.Since(startTime)
+	return extracted, nil
+}
+
+// extract extracts the document, or loads the extraction of an earlier run,
+// and starts the status ledger. Only the extraction fields of the result are set.
+func extract(ctx context.Context, cfg *config.Config) (*OrchestrationResult, error) {
+	if cfg.Extraction != "" {
+		return loadExtraction(ctx, cfg)
+	}
+
+	// 1. Initialize the document source and extract from doc
+	reportStage(ctx, StageExtraction)
+	extractionStart := time.Now()
+	provider, err := docsource.Open(ctx, docsource.Options{
+		Source:          cfg.Source,
+		CredentialsMode: cfg.CredentialsMode,
+		CredentialsPath: cfg.CredentialsPath,
+		APIMaxAttempts:  cfg.APIMaxAttempts,
+		NoCache:         cfg.NoCache,
+		File:            cfg.File,
+		Before:          cfg.Before,
+		BeforeRevision:  cfg.BeforeRevision,
+		AfterRevision:   cfg.AfterRevision,
+		Tab:             cfg.Tab,
+		Replay:          cfg.Replay,
+		RepoDir:         cfg.TargetRepo,
+	})
+	if err != nil {
+		slog.Error("Failed to initialize document source",
+			slog.String("error", err.Error()),
+			slog.String("credentials_path", cfg.CredentialsPath),
+		)
+		return nil, err
+	}
+
+	// 2. Process Document
+	result, err := provider.ProcessDocument(ctx, cfg.DocID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process document: %w", err)
+	}
+	if cfg.IncludeComments {
+		added := gdocs.IncludeCommentInstructions(result)
+		slog.Info("Comment instructions included", slog.Int("count", added))
+	}
+	filter, err := cfg.SuggestionFilter()
+	if err != nil {
+		return nil, fmt.Errorf("invalid suggestion filter: %w", err)
+	}
+	filtered := 0
+	if !filter.Empty() {
+		total := len(result.ActionableSuggestions)
+		kept := gdocs.FilterSuggestions(result, filter)
+		filtered = total - kept
+		slog.Info("Suggestions filtered", slog.Int("kept", kept), slog.Int("total", total))
+	}
+	prior := skipApplied(cfg, result)
+	if cfg.MergeSentences {
+		merged := gdocs.MergeSentences(result)
+		slog.Info("Suggestions merged by sentence", slog.Int("count", merged))
+	}
+	if cfg.AnchorLength != 0 || cfg.AnchorBoundary != "" {
+		gdocs.ApplyAnchorOptions(result, gdocs.AnchorOptions{Length: cfg.AnchorLength, Boundary: cfg.AnchorBoundary})
+	}
+	if ambiguous := gdocs.EnsureUniqueAnchors(result, cfg.AnchorBoundary); ambiguous > 0 {
+		slog.Warn("Suggestions with ambiguous anchors", slog.Int("count", ambiguous))
+	}
+	normalization, err := cfg.Normalization()
+	if err != nil {
+		return nil, fmt.Errorf("invalid normalization: %w", err)
+	}
+	gdocs.NormalizeAnchors(result, normalization)
+	choose, err := conflictChooser(cfg.ConflictStrategy, os.Stdin, os.Stdout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid conflict strategy: %w", err)
+	}
+	conflicts, err := gdocs.ResolveGroupedConflicts(result, choose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve conflicting suggestions: %w", err)
+	}
+	gdocs.ComputeSuggestionStats(result, filtered)
+	resolveFiles(cfg, result, normalization)
+	extractionDuration := time.Since(extractionStart)
+	statusLedger := newStatusLedger(result)
+	recordConflicts(statusLedger, conflicts)
+	prior.recordDone(statusLedger, result.DocumentID)
+
+	// Flag suggestions that no longer match the published page
+	var stalenessReport *staleness.Report
+	if cfg.StaleCheck != "" {
+		stalenessReport = checkStaleness(ctx, cfg, result)
+	}
+
+	// Page refresh rebuilds whole sections rather than applying anchored edits
+	if cfg.PageRefresh && result.Document != nil {
+		result.Sections = gdocs.ExtractSections(result.Document, result.MetadataTables, result.ActionableSuggestions, gdocs.DefaultSectionLevel)
+		slog.Info("Document sections extracted", slog.Int("section_count", len(result.Sections)))
+	}
+
+	// Archive the raw document with the run's artifacts for offline replay
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		slog.Error("Failed to create output directory", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	snapshotPath := filepath.Join(cfg.OutputDir, gdocs.SnapshotFile)
+	if err := gdocs.WriteDocumentSnapshot(snapshotPath, result.Document); err != nil {
+		// Snapshots are a debugging aid; never fail the run over one
+		slog.Warn("Failed to archive document snapshot", slog.String("error", err.Error()))
+	} else {
+		slog.Info("Document snapshot archived", slog.String("snapshot_file", snapshotPath))
+	}
+
+	// 3. Write extraction result to file
+	outputFile, err := writeExtraction(cfg, result)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DumpRaw {
+		rawFile := filepath.Join(cfg.WorkDir, gdocs.RawDocumentFile)
+		if err := gdocs.WriteRawDocument(rawFile, result.Document); err != nil {
+			slog.Warn("Failed to dump raw document", slog.String("error", err.Error()))
+		} else {
+			slog.Info("Raw document dumped", slog.String("raw_file", rawFile))
+		}
+	}
+	previewPath := filepath.Join(cfg.OutputDir, preview.PreviewFile)
+	if err := preview.Write(previewPath, result); err != nil {
+		// The preview is for people; the run doesn't depend on it
+		slog.Warn("Failed to write preview", slog.String("error", err.Error()))
+	} else {
+		slog.Info("Preview written", slog.String("preview_file", previewPath))
+	}
+	slog.Info("Extraction complete",
+		slog.String("output_file", outputFile),
+		slog.Duration("extraction_duration", extractionDuration),
+	)
+
+	return &OrchestrationResult{
+		ExtractionResult:   result,
+		ExtractionDuration: extractionDuration,
+		StalenessReport:    stalenessReport,
+		Ledger:             statusLedger,
+		NewSuggestions:     prior.newSuggestions(),
+	}, nil
+}
+
+// loadExtraction starts a run from the extraction of an earlier one. Its
+// locations are resolved again, against the target repository of this run.
+func loadExtraction(ctx context.Context, cfg *config.Config) (*OrchestrationResult, error) {
+	reportStage(ctx, StageExtraction)
+	extractionStart := time.Now()
+	result, err := gdocs.LoadProcessingResult(cfg.Extraction)
+	if err != nil {
+		return nil, err
+	}
+	normalization, err := cfg.Normalization()
+	if err != nil {
+		return nil, fmt.Errorf("invalid normalization: %w", err)
+	}
+	prior := skipApplied(cfg, result)
+	resolveFiles(cfg, result, normalization)
+	statusLedger := newStatusLedger(result)
+	if result.ConflictReport != nil {
+		recordConflicts(statusLedger, result.ConflictReport)
+	}
+	prior.recordDone(statusLedger, result.DocumentID)
+
+	var stalenessReport *staleness.Report
+	if cfg.StaleCheck != "" {
+		stalenessReport = checkStaleness(ctx, cfg, result)
+	}
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	// The extraction is kept with the run, for `bauer pr` to find
+	if _, err := writeExtraction(cfg, result); err != nil {
+		return nil, err
+	}
+	extractionDuration := time.Since(extractionStart)
+	slog.Info("Extraction loaded",
+		slog.String("extraction_file", cfg.Extraction),
+		slog.Int("total_locations", len(result.GroupedSuggestions)),
+	)
+
+	return &OrchestrationResult{
+		ExtractionResult:   result,
+		ExtractionDuration: extractionDuration,
+		StalenessReport:    stalenessReport,
+		Ledger:             statusLedger,
+		NewSuggestions:     prior.newSuggestions(),
 	}, nil
 }
 
-// executeCopilotChunks executes each chunk via the Copilot SDK and returns outputs
+// writeExtraction writes the extraction result to ExtractionResultFile in the
+// work directory and returns its path.
+func writeExtraction(cfg *config.Config, result *gdocs.ProcessingResult) (string, error) {
+	outputJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		slog.Error("Failed to marshal output", slog.String("error", err.Error()))
+		return "", fmt.Errorf("failed to generate output JSON: %w", err)
+	}
+	outputFile := filepath.Join(cfg.WorkDir, ExtractionResultFile)
+	if err := os.WriteFile(outputFile, outputJSON, 0644); err != nil {
+		slog.Error("Failed to write output file", slog.String("error", err.Error()))
+		return "", fmt.Errorf("failed to write output file: %w", err)
+	}
+	return outputFile, nil
+}
+
+// executeCopilotChunks executes each chunk with the executor and returns outputs.
+// Each output must end with a valid chunk report. The chunk manifest is saved
+// as each chunk starts and finishes.
 func executeCopilotChunks(
 	ctx context.Context,
 	chunks []prompt.ChunkResult,
 	cfg *config.Config,
-	client *copilotcli.Client,
+	client executor.Executor,
+	manifest *prompt.Manifest,
+	result *gdocs.ProcessingResult,
+	guard *templateGuard,
+	protected *pathPolicy,
 ) ([]copilotcli.ChunkOutput, time.Duration, error) {
 	executionStart := time.Now()
 
@@ -223,6 +679,28 @@ func executeCopilotChunks(
 	totalChunks := len(chunks)
 
 	for i, chunk := range chunks {
+		if entry := manifest.Get(chunk.ChunkNumber); entry != nil && entry.Status == prompt.ChunkCompleted {
+			slog.Info("Skipping chunk completed by a previous run",
+				slog.Int("chunk_number", chunk.ChunkNumber),
+				slog.Int("chunk_count", totalChunks),
+			)
+			outputs = append(outputs, copilotcli.ChunkOutput{
+				ChunkNumber: chunk.ChunkNumber,
+				Output:      entry.Output,
+			})
+			continue
+		}
+		if stopRequested(ctx) {
+			slog.Warn("Run stopped; resume it to execute the remaining chunks",
+				slog.Int("next_chunk", chunk.ChunkNumber),
+				slog.Int("chunk_count", totalChunks),
+			)
+			return nil, 0, fmt.Errorf("%w: stopped before chunk %d of %d", ErrStopped, chunk.ChunkNumber, totalChunks)
+		}
+		if err := checkCopilotLimit(cfg, time.Since(executionStart), chunk.ChunkNumber, totalChunks); err != nil {
+			return outputs, time.Since(executionStart), err
+		}
+
 		chunkStart := time.Now()
 
 		slog.Info("Executing chunk",
@@ -230,29 +708,389 @@ func executeCopilotChunks(
 			slog.Int("chunk_count", totalChunks),
 		)
 
+		manifest.SetStatus(chunk.ChunkNumber, prompt.ChunkRunning, "")
+		saveManifest(cfg, manifest)
+		progress.Report(ctx, progress.Event{Type: progress.EventChunkStarted, Chunk: chunk.ChunkNumber, TotalChunks: totalChunks})
+
+		// The attempts of this run are logged afresh
+		if err := os.Remove(copilotcli.EventLogPath(chunk.Filename, chunk.ChunkNumber)); err != nil && !os.IsNotExist(err) {
+			slog.Warn("Failed to remove previous event log", slog.String("error", err.Error()))
+		}
+
 		// Execute the chunk
-		output, err := client.ExecuteChunk(ctx, chunk.Filename, chunk.ChunkNumber, cfg.Model)
+		policy := executor.RetryPolicy{MaxRetries: cfg.ChunkRetries, FallbackModel: cfg.FallbackModel}
+		chunkCtx, cancel := copilotBudget(ctx, cfg, executionStart)
+		output, attempts, err := executor.ExecuteWithRetry(chunkCtx, client, chunk.Filename, chunk.ChunkNumber, cfg.Model, policy, chunkReportValidator(chunk, result))
+		budgetSpent := chunkCtx.Err() != nil && ctx.Err() == nil
+		cancel()
 		if err != nil {
+			manifest.SetStatus(chunk.ChunkNumber, prompt.ChunkFailed, err.Error())
+			saveManifest(cfg, manifest)
+			progress.Report(ctx, progress.Event{Type: progress.EventChunkFailed, Chunk: chunk.ChunkNumber, TotalChunks: totalChunks, Message: err.Error()})
+			if budgetSpent {
+				// The session was cut off midway; what it changed is left
+				// for the report, but not to a protected path
+				if protected != nil {
+					protected.check(chunk.ChunkNumber)
+				}
+				limit, _ := cfg.CopilotDurationLimit()
+				return outputs, time.Since(executionStart), limitError("chunks executed for %s, over the limit of %s; stopped during chunk %d of %d",
+					time.Since(executionStart).Round(time.Second), limit, chunk.ChunkNumber, totalChunks)
+			}
 			return nil, 0, fmt.Errorf("failed to execute chunk %d: %w", chunk.ChunkNumber, err)
 		}
 
 		chunkDuration := time.Since(chunkStart)
+		// Reverted before the templates are checked, so their damage isn't
+		// the chunk's
+		if protected != nil {
+			protected.check(chunk.ChunkNumber)
+		}
+		if guard != nil {
+			guard.check(chunk.ChunkNumber)
+		}
+		manifest.SetStatus(chunk.ChunkNumber, prompt.ChunkCompleted, "")
+		manifest.Get(chunk.ChunkNumber).Output = output
+		saveManifest(cfg, manifest)
+		progress.Report(ctx, progress.Event{Type: progress.EventChunkCompleted, Chunk: chunk.ChunkNumber, TotalChunks: totalChunks})
 
 		// Collect output
 		outputs = append(outputs, copilotcli.ChunkOutput{
 			ChunkNumber: chunk.ChunkNumber,
 			Output:      output,
 			Duration:    chunkDuration,
+			Attempts:    attempts,
 		})
 
 		slog.Info("Chunk executed successfully",
 			slog.Int("chunk", chunk.ChunkNumber),
 			slog.Int("completed", i+1),
 			slog.Int("total", totalChunks),
+			slog.Int("attempts", len(attempts)),
 			slog.Duration("duration", chunkDuration),
+			slog.Int("tokens", outputs[len(outputs)-1].TotalUsage().Total()),
 		)
 	}
 
 	totalDuration := time.Since(executionStart)
 	return outputs, totalDuration, nil
 }
+
+// checkStaleness marks suggestions whose anchors are missing from the published page.
+// Documents that cover several pages are checked against each page.
+// Failures are logged and ignored so an unreachable page never blocks a run.
+func checkStaleness(ctx context.Context, cfg *config.Config, result *gdocs.ProcessingResult) *staleness.Report {
+	repoRoot := cfg.TargetRepo
+	if repoRoot == "" {
+		repoRoot = "."
+	}
+
+	// Validated before the document was processed
+	normalization, _ := cfg.Normalization()
+
+	var report *staleness.Report
+	var locations []string
+	for _, page := range result.GroupsByPage(result.GroupedSuggestions) {
+		pageText, location, err := staleness.LoadPageText(ctx, cfg.StaleCheck, page.URL, repoRoot, cfg.Sites)
+		if err != nil {
+			slog.Warn("Skipping staleness check",
+				slog.String("source", cfg.StaleCheck),
+				slog.String("page_url", page.URL),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		pageReport := staleness.MarkStale(page.Groups, pageText, normalization)
+		if report == nil {
+			report = pageReport
+		} else {
+			report.Checked += pageReport.Checked
+			report.StaleIDs = append(report.StaleIDs, pageReport.StaleIDs...)
+		}
+		locations = append(locations, location)
+	}
+	if report == nil {
+		return nil
+	}
+	location := strings.Join(locations, ", ")
+	report.Source = cfg.StaleCheck
+	report.Location = location
+	report.StaleCount = len(report.StaleIDs)
+
+	if report.StaleCount > 0 {
+		slog.Warn("Stale suggestions detected",
+			slog.Int("stale_count", report.StaleCount),
+			slog.Int("checked", report.Checked),
+			slog.String("location", location),
+		)
+		fmt.Printf("WARNING: %d of %d suggestions no longer match %s\n", report.StaleCount, report.Checked, location)
+	} else {
+		slog.Info("No stale suggestions detected",
+			slog.Int("checked", report.Checked),
+			slog.String("location", location),
+		)
+	}
+
+	return report
+}
+
+// resolveFiles records the repository file each location group applies to,
+// found by searching the target repository for the suggestions' anchors.
+// Failures are logged and ignored; Copilot falls back to the page URL.
+func resolveFiles(cfg *config.Config, result *gdocs.ProcessingResult, normalization gdocs.Normalization) {
+	repoRoot := cfg.TargetRepo
+	if repoRoot == "" {
+		repoRoot = "."
+	}
+
+	resolver, err := locate.NewResolver(repoRoot, normalization)
+	if err != nil {
+		slog.Warn("Skipping file resolution", slog.String("error", err.Error()))
+		return
+	}
+	resolved := resolver.ResolveGroups(result.GroupedSuggestions)
+	slog.Info("Location files resolved",
+		slog.Int("resolved", resolved),
+		slog.Int("total_locations", len(result.GroupedSuggestions)),
+	)
+
+	detected := vanilla.DetectGroups(repoRoot, result.GroupedSuggestions)
+	slog.Info("Vanilla patterns detected", slog.Int("locations", detected))
+}
+
+// newStatusLedger starts a ledger with every extracted suggestion, marking
+// those that made it into a location group as grouped.
+func newStatusLedger(result *gdocs.ProcessingResult) *ledger.Ledger {
+	statusLedger := ledger.New(result.DocumentID)
+	for _, sugg := range result.ActionableSuggestions {
+		statusLedger.Set(sugg.ID, ledger.StatusExtracted, "")
+		if sugg.Author != "" {
+			statusLedger.Get(sugg.ID).Author = sugg.Author
+		}
+	}
+	for _, group := range result.GroupedSuggestions {
+		for _, sugg := range group.Suggestions {
+			for _, id := range sugg.SuggestionIDs() {
+				statusLedger.Set(id, ledger.StatusGrouped, "")
+			}
+		}
+	}
+	return statusLedger
+}
+
+// recordConflicts marks the suggestions dropped for overlapping others as
+// superseded, so reviewers can see their edit was subsumed.
+func recordConflicts(statusLedger *ledger.Ledger, report *gdocs.ConflictReport) {
+	for _, dropped := range report.Dropped {
+		statusLedger.Set(dropped.ID, ledger.StatusSuperseded, fmt.Sprintf("%s in `%s`", dropped.Reason, dropped.SupersededBy))
+		if dropped.Author != "" {
+			statusLedger.Get(dropped.ID).Author = dropped.Author
+		}
+	}
+	if len(report.Dropped) > 0 {
+		slog.Warn("Overlapping suggestions superseded", slog.Int("count", len(report.Dropped)))
+		fmt.Printf("WARNING: %d suggestions overlap others and were superseded; see %s\n", len(report.Dropped), ledger.LedgerFile)
+	}
+}
+
+// chunkReportValidator returns the check of a chunk's output: it must end with
+// a JSON report in which every suggestion of the chunk was applied, except
+// stale ones, which may be skipped. Merged suggestions are reported under the
+// suggestion they were merged into.
+func chunkReportValidator(chunk prompt.ChunkResult, result *gdocs.ProcessingResult) func(string) error {
+	primary := make(map[string]string)
+	stale := make(map[string]bool)
+	for _, group := range result.GroupedSuggestions {
+		for _, sugg := range group.Suggestions {
+			for _, id := range sugg.SuggestionIDs() {
+				primary[id] = sugg.ID
+			}
+			stale[sugg.ID] = sugg.Stale
+		}
+	}
+
+	return func(output string) error {
+		report, err := ledger.ParseChunkReport(output)
+		if err != nil {
+			return err
+		}
+		applied := make(map[string]bool)
+		for _, id := range report.Applied {
+			applied[id] = true
+		}
+		skipped := make(map[string]bool)
+		for _, s := range report.Skipped {
+			skipped[s.ID] = true
+		}
+
+		var unapplied []string
+		seen := make(map[string]bool)
+		for _, id := range chunk.SuggestionIDs {
+			id = cmp.Or(primary[id], id)
+			if seen[id] || applied[id] || (skipped[id] && stale[id]) {
+				continue
+			}
+			seen[id] = true
+			unapplied = append(unapplied, id)
+		}
+		if len(unapplied) > 0 {
+			return fmt.Errorf("suggestions not applied: %s", strings.Join(unapplied, ", "))
+		}
+		slog.Info("Chunk report",
+			slog.Int("chunk", chunk.ChunkNumber),
+			slog.Int("applied", len(report.Applied)),
+			slog.Int("skipped", len(report.Skipped)),
+			slog.Any("files_modified", report.FilesModified),
+		)
+		return nil
+	}
+}
+
+// recordChunkOutcomes applies the outcomes Copilot reported for each chunk: its
+// JSON report, or the STATUS lines of outputs from before the JSON report.
+// Suggestions without a reported outcome stay chunked. Suggestions merged into
+// another one share its outcome.
+func recordChunkOutcomes(statusLedger *ledger.Ledger, outputs []copilotcli.ChunkOutput, result *gdocs.ProcessingResult) {
+	merged := make(map[string][]string)
+	for _, group := range result.GroupedSuggestions {
+		for _, sugg := range group.Suggestions {
+			if len(sugg.MergedIDs) > 0 {
+				merged[sugg.ID] = sugg.MergedIDs
+			}
+		}
+	}
+
+	for _, output := range outputs {
+		var lines []ledger.ReportLine
+		if report, err := ledger.ParseChunkReport(output.Output); err == nil {
+			lines = report.Lines()
+		} else {
+			lines = ledger.ParseReport(output.Output)
+		}
+		for _, line := range lines {
+			if statusLedger.Get(line.SuggestionID) == nil {
+				slog.Warn("Copilot reported status for unknown suggestion",
+					slog.String("suggestion_id", line.SuggestionID),
+					slog.Int("chunk", output.ChunkNumber),
+				)
+				continue
+			}
+			statusLedger.Set(line.SuggestionID, line.Status, line.Note)
+			for _, id := range merged[line.SuggestionID] {
+				statusLedger.Set(id, line.Status, fmt.Sprintf("merged into `%s`", line.SuggestionID))
+			}
+		}
+	}
+}
+
+// verifyAppliedSuggestions marks applied suggestions as verified when their new
+// text can be found in the target page template. Deletions and metadata changes
+// can't be confirmed this way and stay applied.
+func verifyAppliedSuggestions(ctx context.Context, cfg *config.Config, result *gdocs.ProcessingResult, statusLedger *ledger.Ledger) {
+	repoRoot := cfg.TargetRepo
+	if repoRoot == "" {
+		repoRoot = "."
+	}
+
+	for _, page := range result.GroupsByPage(result.GroupedSuggestions) {
+		pageText, location, err := staleness.LoadPageText(ctx, staleness.SourceRepo, page.URL, repoRoot, cfg.Sites)
+		if err != nil {
+			slog.Warn("Skipping suggestion verification",
+				slog.String("page_url", page.URL),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		verified := 0
+		for _, group := range page.Groups {
+			if group.Location.InMetadata {
+				continue
+			}
+			for _, sugg := range group.Suggestions {
+				entry := statusLedger.Get(sugg.ID)
+				if entry == nil || entry.Status != ledger.StatusApplied {
+					continue
+				}
+				newText := staleness.PageText(sugg.Change.NewText)
+				if newText != "" && strings.Contains(pageText, newText) {
+					statusLedger.Set(sugg.ID, ledger.StatusVerified, "new text found in "+location)
+					verified++
+				}
+			}
+		}
+
+		slog.Info("Suggestion verification complete",
+			slog.Int("verified", verified),
+			slog.String("location", location),
+		)
+	}
+}
+
+// saveLedger writes the status ledger next to the run's other artifacts.
+func saveLedger(cfg *config.Config, statusLedger *ledger.Ledger) {
+	path := filepath.Join(cfg.OutputDir, ledger.LedgerFile)
+	if err := statusLedger.Save(path); err != nil {
+		slog.Warn("Failed to write suggestion status ledger", slog.String("error", err.Error()))
+		return
+	}
+	slog.Info("Suggestion status ledger written",
+		slog.String("ledger_file", path),
+		slog.String("summary", statusLedger.Summary()),
+	)
+}
+
+// resumeManifest marks the chunks completed by the previous run in the output
+// directory, so only its failed and pending chunks are executed again.
+func resumeManifest(cfg *config.Config, manifest *prompt.Manifest) {
+	path := filepath.Join(cfg.OutputDir, prompt.ManifestFile)
+	previous, err := prompt.LoadManifest(path)
+	if err != nil {
+		slog.Warn("No chunk manifest to resume from; executing all chunks", slog.String("error", err.Error()))
+		return
+	}
+	if previous.DocumentID != manifest.DocumentID {
+		slog.Warn("Chunk manifest is for another document; executing all chunks",
+			slog.String("manifest_document_id", previous.DocumentID),
+		)
+		return
+	}
+	resumed := manifest.Resume(previous)
+	slog.Info("Resuming previous run",
+		slog.Int("completed_chunks", resumed),
+		slog.Int("remaining_chunks", len(manifest.Chunks)-resumed),
+	)
+}
+
+// sandbox returns the restrictions of Copilot sessions selected by cfg.
+func sandbox(cfg *config.Config, cwd string) copilotcli.Sandbox {
+	s := copilotcli.Sandbox{
+		AllowedTools:  cfg.AllowedTools,
+		ExcludedTools: cfg.ExcludedTools,
+		DenyShell:     cfg.DenyShell,
+		DenyNetwork:   cfg.DenyNetwork,
+	}
+	if cfg.RestrictWrites {
+		s.WritableRoot = cwd
+	}
+	return s
+}
+
+// saveManifest writes the chunk manifest next to the chunks. Failures are
+// logged: the manifest records a run but isn't needed to complete it.
+func saveManifest(cfg *config.Config, manifest *prompt.Manifest) {
+	path := filepath.Join(cfg.OutputDir, prompt.ManifestFile)
+	if err := manifest.Save(path); err != nil {
+		slog.Warn("Failed to write chunk manifest", slog.String("error", err.Error()))
+	}
+}
+
+// saveReport writes the run report next to the run's other artifacts.
+func saveReport(cfg *config.Config, report *Report) {
+	if err := report.Save(cfg.OutputDir); err != nil {
+		slog.Warn("Failed to write run report", slog.String("error", err.Error()))
+		return
+	}
+	slog.Info("Run report written", slog.String("report_file", filepath.Join(cfg.OutputDir, ReportFile)))
+}
@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bauer/internal/config"
+	"bauer/internal/copilotcli"
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"bauer/internal/prompt"
+)
+
+func TestChunkReportValidator(t *testing.T) {
+	result := &gdocs.ProcessingResult{
+		GroupedSuggestions: []gdocs.LocationGroupedSuggestions{{
+			Suggestions: []gdocs.GroupedActionableSuggestion{
+				{ID: "suggest.a", MergedIDs: []string{"suggest.b"}},
+				{ID: "suggest.c", Stale: true},
+				{ID: "suggest.d"},
+			},
+		}},
+	}
+	chunk := prompt.ChunkResult{ChunkNumber: 1, SuggestionIDs: []string{"suggest.a", "suggest.b", "suggest.c", "suggest.d"}}
+	validate := chunkReportValidator(chunk, result)
+
+	tests := []struct {
+		name    string
+		output  string
+		wantErr string
+	}{
+		{"all applied", "```json\n{\"applied\": [\"suggest.a\", \"suggest.c\", \"suggest.d\"]}\n```", ""},
+		{"stale skipped", "```json\n{\"applied\": [\"suggest.a\", \"suggest.d\"], \"skipped\": [{\"id\": \"suggest.c\", \"reason\": \"stale\"}]}\n```", ""},
+		{"skipped", "```json\n{\"applied\": [\"suggest.a\", \"suggest.c\"], \"skipped\": [{\"id\": \"suggest.d\", \"reason\": \"not found\"}]}\n```", "suggestions not applied: suggest.d"},
+		{"unreported", "```json\n{\"applied\": [\"suggest.d\"]}\n```", "suggestions not applied: suggest.a, suggest.c"},
+		{"missing report", "STATUS suggest.a applied", "no JSON report"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(tt.output)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyChunkChanges(t *testing.T) {
+	root := t.TempDir()
+	page := "<h1>Fast   and <em>secure</em>.</h1>\n<meta name=\"description\" content=\"Ubuntu for “everyone”\">\n"
+	if err := os.WriteFile(filepath.Join(root, "index.html"), []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	verification := func(text string) gdocs.SuggestionVerification {
+		return gdocs.SuggestionVerification{TextAfterChange: text}
+	}
+	result := &gdocs.ProcessingResult{
+		GroupedSuggestions: []gdocs.LocationGroupedSuggestions{{
+			ResolvedFile: "index.html",
+			Suggestions: []gdocs.GroupedActionableSuggestion{
+				{ID: "suggest.heading", Verification: verification("Fast and secure.")},
+				{ID: "suggest.meta", Verification: verification(`Ubuntu for "everyone"`)},
+				{ID: "suggest.missing", Verification: verification("Slow and insecure."), MergedIDs: []string{"suggest.merged"}},
+				{ID: "suggest.skipped", Verification: verification("Slow")},
+			},
+		}},
+	}
+	chunks := []prompt.ChunkResult{{ChunkNumber: 1, SuggestionIDs: []string{"suggest.heading", "suggest.meta", "suggest.missing", "suggest.skipped"}}}
+	outputs := []copilotcli.ChunkOutput{{ChunkNumber: 1, Output: "```json\n{\"files_modified\": [\"index.html\"], \"applied\": []}\n```"}}
+
+	statusLedger := ledger.New("doc-1")
+	for _, id := range []string{"suggest.heading", "suggest.meta", "suggest.missing", "suggest.merged"} {
+		statusLedger.Set(id, ledger.StatusApplied, "")
+	}
+	statusLedger.Set("suggest.skipped", ledger.StatusSkipped, "")
+
+	cfg := &config.Config{TargetRepo: root, Normalize: []string{"quotes"}}
+	verifyChunkChanges(cfg, chunks, outputs, result, statusLedger)
+
+	want := map[string]ledger.Status{
+		"suggest.heading": ledger.StatusVerified,
+		"suggest.meta":    ledger.StatusVerified,
+		"suggest.missing": ledger.StatusUnverified,
+		"suggest.merged":  ledger.StatusUnverified,
+		"suggest.skipped": ledger.StatusSkipped,
+	}
+	for id, status := range want {
+		if got := statusLedger.Get(id).Status; got != status {
+			t.Errorf("status of %s = %s, want %s", id, got, status)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"bauer/internal/github"
+)
+
+// PolicyViolation is a change a chunk made to a protected path, which was
+// reverted. Chunk 0 is the direct patches.
+type PolicyViolation struct {
+	Chunk   int    `json:"chunk"`
+	File    string `json:"file"`
+	Pattern string `json:"pattern"`
+
+	// Error is why the change couldn't be reverted, when it couldn't
+	Error string `json:"error,omitempty"`
+}
+
+// pathPolicy reverts the changes each chunk makes to the files matching
+// config.Config.ProtectedPaths. Changes made before the run are left alone.
+type pathPolicy struct {
+	root     string
+	patterns []string
+
+	// before are the paths changed before the run
+	before     map[string]bool
+	violations []PolicyViolation
+}
+
+// newPathPolicy returns the policy of patterns for the git repository dir is
+// in.
+func newPathPolicy(dir string, patterns []string) (*pathPolicy, error) {
+	root, err := gitRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	p := &pathPolicy{root: root, patterns: patterns, before: make(map[string]bool)}
+	changes, err := p.changes()
+	if err != nil {
+		return nil, err
+	}
+	for path := range changes {
+		p.before[path] = true
+	}
+	return p, nil
+}
+
+// check reverts the protected paths changed since the run started and
+// records them as the chunk's violations.
+func (p *pathPolicy) check(chunkNumber int) {
+	changes, err := p.changes()
+	if err != nil {
+		slog.Warn("Failed to check protected paths", slog.Int("chunk", chunkNumber), slog.String("error", err.Error()))
+		return
+	}
+	for _, path := range slices.Sorted(maps.Keys(changes)) {
+		status := changes[path]
+		if p.before[path] {
+			continue
+		}
+		pattern := p.match(path)
+		if pattern == "" {
+			continue
+		}
+		violation := PolicyViolation{Chunk: chunkNumber, File: path, Pattern: pattern}
+		if err := p.revert(path, status); err != nil {
+			violation.Error = err.Error()
+			slog.Error("Failed to revert change to protected path",
+				slog.Int("chunk", chunkNumber),
+				slog.String("file", path),
+				slog.String("error", err.Error()),
+			)
+		} else {
+			slog.Warn("Reverted change to protected path",
+				slog.Int("chunk", chunkNumber),
+				slog.String("file", path),
+				slog.String("pattern", pattern),
+			)
+		}
+		p.violations = append(p.violations, violation)
+	}
+}
+
+// found returns the violations so far; nil for a nil policy.
+func (p *pathPolicy) found() []PolicyViolation {
+	if p == nil {
+		return nil
+	}
+	return p.violations
+}
+
+// match returns the first pattern path matches, or "".
+func (p *pathPolicy) match(path string) string {
+	for _, pattern := range p.patterns {
+		if github.MatchPattern(pattern, path) {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// revert restores a path to its content at HEAD, removing new files.
+func (p *pathPolicy) revert(path, status string) error {
+	switch {
+	case status == "??":
+		if err := os.Remove(filepath.Join(p.root, path)); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	case status[0] == 'A':
+		if _, err := gitOutput(p.root, "rm", "--force", "--quiet", "--", path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	default:
+		if _, err := gitOutput(p.root, "checkout", "HEAD", "--", path); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// changes maps the paths changed in the working tree or index, relative to
+// the repository root, to their status. Both paths of renames are listed.
+func (p *pathPolicy) changes() (map[string]string, error) {
+	out, err := gitOutput(p.root, "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	changes := make(map[string]string)
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		status, path := entry[:2], entry[3:]
+		if (status[0] == 'R' || status[0] == 'C') && i+1 < len(entries) {
+			// The original path follows renames and copies
+			i++
+			changes[entries[i]] = " M"
+			status = "A "
+		}
+		changes[path] = status
+	}
+	return changes, nil
+}
@@ -0,0 +1,172 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bauer/internal/config"
+	"bauer/internal/direct"
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPathPolicy(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Bauer")
+	t.Setenv("GIT_AUTHOR_EMAIL", "bauer@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Bauer")
+	t.Setenv("GIT_COMMITTER_EMAIL", "bauer@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	dir := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(path string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			return ""
+		}
+		return string(content)
+	}
+	write("templates/index.html", "<h1>Ubuntu</h1>\n")
+	write("package.json", "{}\n")
+	write(".github/workflows/ci.yaml", "on: push\n")
+	write("scripts/build.py", "print()\n")
+	for _, args := range [][]string{{"init"}, {"add", "."}, {"commit", "-m", "Initial commit"}} {
+		if _, err := gitOutput(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Changed before the run, so left alone
+	write("scripts/build.py", "print('local')\n")
+	policy, err := newPathPolicy(dir, []string{"*.py", ".github/**", "package.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write("templates/index.html", "<h1>Ubuntu Pro</h1>\n")
+	write("package.json", `{"name": "site"}`+"\n")
+	write(".github/workflows/deploy.yaml", "on: release\n")
+	write("scripts/build.py", "print('chunk')\n")
+	policy.check(1)
+
+	want := []PolicyViolation{
+		{Chunk: 1, File: ".github/workflows/deploy.yaml", Pattern: ".github/**"},
+		{Chunk: 1, File: "package.json", Pattern: "package.json"},
+	}
+	if diff := cmp.Diff(want, policy.found()); diff != "" {
+		t.Errorf("found() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Validation fix sessions are held to the policy as chunks are
+	fixer := &fixingExecutor{dir: dir, fixesNeeded: 1, edit: func() { write("package.json", `{"name": "fix"}`+"\n") }}
+	cfg := &config.Config{
+		ValidateCommand: "test -f fixed || { echo 'missing fixed'; exit 1; }",
+		ValidateRetries: 1,
+		OutputDir:       t.TempDir(),
+	}
+	if _, _, err := validateChanges(context.Background(), cfg, fixer, dir, 2, nil, policy); err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, PolicyViolation{Chunk: 2, File: "package.json", Pattern: "package.json"})
+	if diff := cmp.Diff(want, policy.found()); diff != "" {
+		t.Errorf("found() after validation fixes mismatch (-want +got):\n%s", diff)
+	}
+
+	for path, content := range map[string]string{
+		"package.json":                  "{}\n",
+		".github/workflows/deploy.yaml": "",
+		"templates/index.html":          "<h1>Ubuntu Pro</h1>\n",
+		"scripts/build.py":              "print('chunk')\n",
+	} {
+		if got := read(path); got != content {
+			t.Errorf("%s = %q, want %q", path, got, content)
+		}
+	}
+}
+
+func TestApplyDirectPatchesProtected(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Bauer")
+	t.Setenv("GIT_AUTHOR_EMAIL", "bauer@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Bauer")
+	t.Setenv("GIT_COMMITTER_EMAIL", "bauer@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"templates/index.html":       "<p>Get Ubuntu Pro for free.</p>\n",
+		"templates/legal/terms.html": "<p>Terms apply to Ubuntu Pro.</p>\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"init"}, {"add", "."}, {"commit", "-m", "Initial commit"}} {
+		if _, err := gitOutput(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	replace := func(id, preceding, original, replacement, following string) gdocs.GroupedActionableSuggestion {
+		return gdocs.GroupedActionableSuggestion{
+			ID:     id,
+			Anchor: gdocs.SuggestionAnchor{PrecedingText: preceding, FollowingText: following},
+			Change: gdocs.SuggestionChange{Type: "replace", OriginalText: original, NewText: replacement},
+		}
+	}
+	plan := direct.NewPlan(dir, []gdocs.LocationGroupedSuggestions{
+		{LocationID: "loc-1", ResolvedFile: "templates/index.html", Suggestions: []gdocs.GroupedActionableSuggestion{
+			replace("s1", "Get ", "Ubuntu Pro", "Ubuntu Pro+", " for free."),
+		}},
+		{LocationID: "loc-2", ResolvedFile: "templates/legal/terms.html", Suggestions: []gdocs.GroupedActionableSuggestion{
+			replace("s2", "Terms apply to ", "Ubuntu Pro", "Ubuntu Pro+", "."),
+		}},
+	}, 0)
+	if len(plan.Patches) != 2 {
+		t.Fatalf("NewPlan() patches = %d, want 2", len(plan.Patches))
+	}
+
+	// The guards are taken before the patches, so their changes are the run's
+	policy, err := newPathPolicy(dir, []string{"templates/legal/**"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	guard, err := newTemplateGuard(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statusLedger := ledger.New("doc")
+	cfg := &config.Config{TargetRepo: dir}
+	if err := applyDirectPatches(cfg, plan, statusLedger, guard, policy); err != nil {
+		t.Fatalf("applyDirectPatches() error = %v", err)
+	}
+
+	want := []PolicyViolation{{Chunk: directPatchesChunk, File: "templates/legal/terms.html", Pattern: "templates/legal/**"}}
+	if diff := cmp.Diff(want, policy.found()); diff != "" {
+		t.Errorf("found() mismatch (-want +got):\n%s", diff)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "templates/legal/terms.html")); string(content) != files["templates/legal/terms.html"] {
+		t.Errorf("Protected file = %q, want it reverted", content)
+	}
+	for id, status := range map[string]ledger.Status{"s1": ledger.StatusApplied, "s2": ledger.StatusFailed} {
+		if got := statusLedger.Get(id); got == nil || got.Status != status {
+			t.Errorf("Ledger status of %s = %v, want %s", id, got, status)
+		}
+	}
+}
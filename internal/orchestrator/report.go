@@ -0,0 +1,414 @@
+package orchestrator
+
+import (
+	"bauer/internal/copilotcli"
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"bauer/internal/prompt"
+	"bauer/internal/triage"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Files of the run report, in the output directory.
+const (
+	ReportFile         = "bauer-report.json"
+	ReportMarkdownFile = "bauer-report.md"
+)
+
+// Report consolidates what a run did, to attach to tickets or pull requests.
+type Report struct {
+	// RunID identifies the run, e.g. for `bauer rollback`
+	RunID string `json:"run_id,omitempty"`
+
+	DocumentID    string    `json:"document_id"`
+	DocumentTitle string    `json:"document_title"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	DryRun        bool      `json:"dry_run"`
+
+	Stats     *gdocs.SuggestionStats `json:"stats,omitempty"`
+	Conflicts *gdocs.ConflictReport  `json:"conflicts,omitempty"`
+	Manifest  *prompt.Manifest       `json:"manifest,omitempty"`
+
+	// ChunkLint is the issues found in the chunks before they were executed
+	ChunkLint []prompt.LintIssue `json:"chunk_lint,omitempty"`
+
+	// DirectPatches counts the suggestions patched without Copilot
+	DirectPatches int `json:"direct_patches,omitempty"`
+
+	// Triage is the classification of the suggestions, when triaged
+	Triage *triage.Report `json:"triage,omitempty"`
+
+	// NewSuggestions are the suggestions no earlier run of the document saw
+	NewSuggestions []string `json:"new_suggestions,omitempty"`
+
+	// Suggestions counts the suggestions by status; NeedsReview lists those
+	// that failed, were skipped or dropped, or whose changes couldn't be verified
+	Suggestions map[ledger.Status]int `json:"suggestions,omitempty"`
+	NeedsReview []*ledger.Entry       `json:"needs_review,omitempty"`
+
+	Validation     *ValidationResult `json:"validation,omitempty"`
+	TemplateDamage []TemplateDamage  `json:"template_damage,omitempty"`
+
+	// LimitExceeded is the limit the run went over, which kept its changes
+	// from being pushed
+	LimitExceeded string `json:"limit_exceeded,omitempty"`
+
+	// PolicyViolations are the changes chunks made to protected paths,
+	// which were reverted
+	PolicyViolations []PolicyViolation `json:"policy_violations,omitempty"`
+
+	// Diff is the changes the run left in the target repository
+	Diff *DiffStats `json:"diff,omitempty"`
+
+	Timings ReportTimings     `json:"timings"`
+	Usage   *copilotcli.Usage `json:"usage,omitempty"`
+
+	// Git is set by the workflow, once the changes are committed
+	Git *GitReport `json:"git,omitempty"`
+}
+
+// ReportTimings are the durations of the stages of a run.
+type ReportTimings struct {
+	Extraction time.Duration `json:"extraction"`
+	Plan       time.Duration `json:"plan"`
+	Copilot    time.Duration `json:"copilot"`
+	Summary    time.Duration `json:"summary"`
+	Total      time.Duration `json:"total"`
+}
+
+// GitReport is where the changes of a run went.
+type GitReport struct {
+	Repository    string `json:"repository"`
+	Branch        string `json:"branch"`
+	BaseBranch    string `json:"base_branch,omitempty"`
+	CommitMessage string `json:"commit_message,omitempty"`
+	Pushed        bool   `json:"pushed"`
+
+	PullRequestURL     string `json:"pull_request_url,omitempty"`
+	PullRequestNumber  int    `json:"pull_request_number,omitempty"`
+	PullRequestUpdated bool   `json:"pull_request_updated,omitempty"`
+
+	// RolledBackAt is when the branch and pull request were removed by
+	// `bauer rollback`
+	RolledBackAt *time.Time `json:"rolled_back_at,omitempty"`
+}
+
+// NewReport builds the report of a run from its result.
+func NewReport(result *OrchestrationResult) *Report {
+	report := &Report{
+		GeneratedAt:      time.Now().UTC(),
+		DryRun:           result.DryRun,
+		Manifest:         result.Manifest,
+		ChunkLint:        result.ChunkLint,
+		Validation:       result.Validation,
+		TemplateDamage:   result.TemplateDamage,
+		LimitExceeded:    result.LimitExceeded,
+		Diff:             result.Diff,
+		PolicyViolations: result.PolicyViolations,
+		Timings: ReportTimings{
+			Extraction: result.ExtractionDuration,
+			Plan:       result.PlanDuration,
+			Copilot:    result.CopilotDuration,
+			Summary:    result.SummaryDuration,
+			Total:      result.TotalDuration,
+		},
+	}
+	if extraction := result.ExtractionResult; extraction != nil {
+		report.RunID = newRunID(extraction.DocumentID, report.GeneratedAt)
+		report.DocumentID = extraction.DocumentID
+		report.DocumentTitle = extraction.DocumentTitle
+		report.Stats = extraction.Stats
+		report.Conflicts = extraction.ConflictReport
+	}
+	report.NewSuggestions = result.NewSuggestions
+	report.Triage = result.Triage
+	if result.DirectPlan != nil {
+		report.DirectPatches = len(result.DirectPlan.Patches)
+	}
+	if result.Ledger != nil {
+		report.Suggestions = result.Ledger.Counts()
+		for _, entry := range result.Ledger.Entries {
+			switch entry.Status {
+			case ledger.StatusFailed, ledger.StatusSkipped, ledger.StatusSuperseded, ledger.StatusHeld, ledger.StatusUnverified:
+				report.NeedsReview = append(report.NeedsReview, entry)
+			}
+		}
+	}
+	if !result.Usage.IsZero() {
+		usage := result.Usage
+		report.Usage = &usage
+	}
+	return report
+}
+
+// newRunID returns the ID of a run of a document started at, e.g.
+// "20261016-150405-1a2b3c4d".
+func newRunID(docID string, at time.Time) string {
+	if len(docID) > 8 {
+		docID = docID[:8]
+	}
+	return at.Format("20060102-150405") + "-" + docID
+}
+
+// LoadReport reads the report written by Save, from its file or the
+// directory containing it.
+func LoadReport(path string) (*Report, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, ReportFile)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+	return &report, nil
+}
+
+// Save writes the report to dir as ReportFile and ReportMarkdownFile.
+func (r *Report) Save(dir string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ReportFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ReportMarkdownFile), []byte(r.Markdown()), 0644); err != nil {
+		return fmt.Errorf("failed to write markdown report: %w", err)
+	}
+	return nil
+}
+
+// Markdown renders the report for people to read.
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+	title := r.DocumentTitle
+	if title == "" {
+		title = r.DocumentID
+	}
+	fmt.Fprintf(&sb, "# Bauer report: %s\n\n", title)
+	if r.RunID != "" {
+		fmt.Fprintf(&sb, "- Run: `%s`\n", r.RunID)
+	}
+	fmt.Fprintf(&sb, "- Document: `%s`\n", r.DocumentID)
+	fmt.Fprintf(&sb, "- Generated: %s\n", r.GeneratedAt.Format(time.RFC3339))
+	if r.DryRun {
+		sb.WriteString("- Dry run: no changes were made\n")
+	}
+	if r.LimitExceeded != "" {
+		fmt.Fprintf(&sb, "- Limit exceeded: %s; the changes were not pushed\n", r.LimitExceeded)
+	}
+
+	if r.Git != nil {
+		sb.WriteString("\n## Changes\n\n")
+		fmt.Fprintf(&sb, "- Repository: %s\n", r.Git.Repository)
+		if r.Git.BaseBranch != "" {
+			fmt.Fprintf(&sb, "- Branch: `%s` (from `%s`)\n", r.Git.Branch, r.Git.BaseBranch)
+		} else {
+			fmt.Fprintf(&sb, "- Branch: `%s`\n", r.Git.Branch)
+		}
+		fmt.Fprintf(&sb, "- Pushed: %t\n", r.Git.Pushed)
+		if r.Git.PullRequestURL != "" {
+			action := "opened"
+			if r.Git.PullRequestUpdated {
+				action = "updated"
+			}
+			fmt.Fprintf(&sb, "- Pull request: [#%d](%s) (%s)\n", r.Git.PullRequestNumber, r.Git.PullRequestURL, action)
+		}
+		if r.Git.RolledBackAt != nil {
+			fmt.Fprintf(&sb, "- Rolled back: %s\n", r.Git.RolledBackAt.Format(time.RFC3339))
+		}
+	}
+
+	if r.Stats != nil {
+		sb.WriteString("\n## Suggestions\n\n")
+		fmt.Fprintf(&sb, "%d suggestions", r.Stats.Total)
+		if r.Stats.ConflictsResolved > 0 || r.Stats.Filtered > 0 {
+			fmt.Fprintf(&sb, " (%d dropped for conflicts, %d filtered out)", r.Stats.ConflictsResolved, r.Stats.Filtered)
+		}
+		sb.WriteString("\n")
+		if len(r.Suggestions) > 0 {
+			if r.DirectPatches > 0 {
+				fmt.Fprintf(&sb, "\n%d of them patched directly, without Copilot\n", r.DirectPatches)
+			}
+			sb.WriteString("\n| Status | Count |\n| --- | --- |\n")
+			for _, status := range ledger.Statuses {
+				if count := r.Suggestions[status]; count > 0 {
+					fmt.Fprintf(&sb, "| %s | %d |\n", status, count)
+				}
+			}
+		}
+	}
+
+	if r.Triage != nil {
+		if triageSection := r.Triage.Markdown(); triageSection != "" {
+			fmt.Fprintf(&sb, "\n## Triage\n\n%s", triageSection)
+		}
+	}
+
+	if len(r.NewSuggestions) > 0 {
+		sb.WriteString("\n## New since the last run\n\n")
+		for _, id := range r.NewSuggestions {
+			fmt.Fprintf(&sb, "- `%s`\n", id)
+		}
+	}
+
+	if len(r.NeedsReview) > 0 {
+		sb.WriteString("\n## Needs review\n\n| Suggestion | Status | Note |\n| --- | --- | --- |\n")
+		for _, entry := range r.NeedsReview {
+			fmt.Fprintf(&sb, "| `%s` | %s | %s |\n", entry.SuggestionID, entry.Status, markdownCell(entry.Note))
+		}
+	}
+
+	if r.Conflicts != nil && len(r.Conflicts.Dropped) > 0 {
+		sb.WriteString("\n## Conflicts\n\n| Dropped | Kept | Reason |\n| --- | --- | --- |\n")
+		for _, dropped := range r.Conflicts.Dropped {
+			fmt.Fprintf(&sb, "| `%s` | `%s` | %s |\n", dropped.ID, dropped.SupersededBy, dropped.Reason)
+		}
+	}
+
+	if r.Manifest != nil && len(r.Manifest.Chunks) > 0 {
+		sb.WriteString("\n## Chunks\n\n| Chunk | Suggestions | Status | Error |\n| --- | --- | --- | --- |\n")
+		for _, chunk := range r.Manifest.Chunks {
+			fmt.Fprintf(&sb, "| %d | %d | %s | %s |\n", chunk.ChunkNumber, len(chunk.SuggestionIDs), chunk.Status, markdownCell(chunk.Error))
+		}
+	}
+
+	if len(r.ChunkLint) > 0 {
+		sb.WriteString("\n## Chunk lint\n\n| Chunk | Severity | Issue |\n| --- | --- | --- |\n")
+		for _, issue := range r.ChunkLint {
+			fmt.Fprintf(&sb, "| %d | %s | %s |\n", issue.Chunk, issue.Severity, markdownCell(issue.Message))
+		}
+	}
+
+	if r.Validation != nil || len(r.TemplateDamage) > 0 {
+		sb.WriteString("\n## Validation\n\n")
+		if r.Validation != nil {
+			result := "passed"
+			if !r.Validation.Passed {
+				result = "failed"
+			}
+			fmt.Fprintf(&sb, "- `%s` %s (fix sessions: %d)\n", r.Validation.Command, result, r.Validation.Fixes)
+		}
+		for _, damage := range r.TemplateDamage {
+			fmt.Fprintf(&sb, "- Chunk %d damaged `%s` (%d problems)\n", damage.Chunk, damage.File, len(damage.Problems))
+		}
+	}
+
+	if len(r.PolicyViolations) > 0 {
+		sb.WriteString("\n## Policy violations\n\nChanges to protected paths, reverted after their chunk; chunk 0 is the direct patches.\n\n| Chunk | File | Pattern | Error |\n| --- | --- | --- | --- |\n")
+		for _, violation := range r.PolicyViolations {
+			fmt.Fprintf(&sb, "| %d | `%s` | `%s` | %s |\n", violation.Chunk, violation.File, violation.Pattern, markdownCell(violation.Error))
+		}
+	}
+
+	if r.Diff != nil && len(r.Diff.Files) > 0 {
+		fmt.Fprintf(&sb, "\n## Diff\n\n%d files, +%d -%d\n", len(r.Diff.Files), r.Diff.Added, r.Diff.Removed)
+		if len(r.Diff.Unexpected) > 0 {
+			outcome := "allowed"
+			if r.Diff.Blocked {
+				outcome = "the changes were not pushed"
+			}
+			fmt.Fprintf(&sb, "\n%d files changed outside the pages of the document (%s)\n", len(r.Diff.Unexpected), outcome)
+		}
+		sb.WriteString("\n| File | Added | Removed | Expected |\n| --- | --- | --- | --- |\n")
+		for _, file := range r.Diff.Files {
+			added, removed := strconv.Itoa(file.Added), strconv.Itoa(file.Removed)
+			if file.Binary {
+				added, removed = "binary", "binary"
+			}
+			expected := "yes"
+			if slices.Contains(r.Diff.Unexpected, file.Path) {
+				expected = "no"
+			}
+			fmt.Fprintf(&sb, "| `%s` | %s | %s | %s |\n", file.Path, added, removed, expected)
+		}
+	}
+
+	sb.WriteString("\n## Timings\n\n")
+	fmt.Fprintf(&sb, "- Extraction: %s\n", r.Timings.Extraction.Round(time.Millisecond))
+	fmt.Fprintf(&sb, "- Planning: %s\n", r.Timings.Plan.Round(time.Millisecond))
+	if !r.DryRun {
+		fmt.Fprintf(&sb, "- Copilot: %s\n", r.Timings.Copilot.Round(time.Millisecond))
+		fmt.Fprintf(&sb, "- Summary: %s\n", r.Timings.Summary.Round(time.Millisecond))
+	}
+	fmt.Fprintf(&sb, "- Total: %s\n", r.Timings.Total.Round(time.Millisecond))
+
+	if r.Usage != nil {
+		fmt.Fprintf(&sb, "\n## Usage\n\n%s\n", r.Usage)
+	}
+	return sb.String()
+}
+
+// LoadResult rebuilds the result of an earlier run from its artifacts: its
+// extraction file, and the status ledger and report in its output directory,
+// e.g. to open the pull request of changes applied by `bauer apply`. The
+// chunks and their Copilot outputs come from the report's manifest.
+func LoadResult(extractionFile, outputDir string) (*OrchestrationResult, error) {
+	extraction, err := gdocs.LoadProcessingResult(extractionFile)
+	if err != nil {
+		return nil, err
+	}
+	statusLedger, err := ledger.Load(filepath.Join(outputDir, ledger.LedgerFile))
+	if err != nil {
+		return nil, err
+	}
+	report, err := LoadReport(filepath.Join(outputDir, ReportFile))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OrchestrationResult{
+		ExtractionResult:   extraction,
+		ExtractionDuration: report.Timings.Extraction,
+		Ledger:             statusLedger,
+		Manifest:           report.Manifest,
+		PlanDuration:       report.Timings.Plan,
+		CopilotDuration:    report.Timings.Copilot,
+		SummaryDuration:    report.Timings.Summary,
+		Validation:         report.Validation,
+		TemplateDamage:     report.TemplateDamage,
+		LimitExceeded:      report.LimitExceeded,
+		PolicyViolations:   report.PolicyViolations,
+		Diff:               report.Diff,
+		TotalDuration:      report.Timings.Total,
+		DryRun:             report.DryRun,
+		Triage:             report.Triage,
+		Report:             report,
+	}
+	if report.Usage != nil {
+		result.Usage = *report.Usage
+	}
+	if report.Manifest != nil {
+		for _, chunk := range report.Manifest.Chunks {
+			result.Chunks = append(result.Chunks, prompt.ChunkResult{
+				ChunkNumber:   chunk.ChunkNumber,
+				Filename:      chunk.Filename,
+				LocationCount: len(chunk.Locations),
+				SuggestionIDs: chunk.SuggestionIDs,
+				LocationIDs:   chunk.Locations,
+				PageURL:       chunk.PageURL,
+			})
+			if chunk.Status == prompt.ChunkCompleted {
+				result.CopilotOutputs = append(result.CopilotOutputs, copilotcli.ChunkOutput{ChunkNumber: chunk.ChunkNumber, Output: chunk.Output})
+			}
+		}
+	}
+	return result, nil
+}
+
+// markdownCell escapes text for a table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
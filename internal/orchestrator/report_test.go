@@ -0,0 +1,141 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bauer/internal/copilotcli"
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"bauer/internal/prompt"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReport(t *testing.T) {
+	statusLedger := ledger.New("doc-a")
+	statusLedger.Set("s1", ledger.StatusApplied, "")
+	statusLedger.Set("s2", ledger.StatusFailed, "anchor | not found")
+	statusLedger.Set("s3", ledger.StatusUnverified, "text missing")
+
+	report := NewReport(&OrchestrationResult{
+		ExtractionResult: &gdocs.ProcessingResult{
+			DocumentID:    "doc-a",
+			DocumentTitle: "Pricing",
+			Stats:         &gdocs.SuggestionStats{Total: 3},
+		},
+		Ledger:        statusLedger,
+		Usage:         copilotcli.Usage{InputTokens: 100, OutputTokens: 20},
+		Validation:    &ValidationResult{Command: "make test", Passed: true, Fixes: 1},
+		TotalDuration: 90 * time.Second,
+	})
+	report.Git = &GitReport{
+		Repository:        "github.com/o/r",
+		Branch:            "bauer/doc-a",
+		BaseBranch:        "main",
+		Pushed:            true,
+		PullRequestURL:    "https://github.com/o/r/pull/7",
+		PullRequestNumber: 7,
+	}
+
+	var needsReview []string
+	for _, entry := range report.NeedsReview {
+		needsReview = append(needsReview, entry.SuggestionID)
+	}
+	if diff := cmp.Diff([]string{"s2", "s3"}, needsReview); diff != "" {
+		t.Errorf("NeedsReview mismatch (-want +got):\n%s", diff)
+	}
+
+	dir := t.TempDir()
+	if err := report.Save(dir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ReportFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Report
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("report isn't JSON: %v", err)
+	}
+	if saved.DocumentID != "doc-a" || saved.Git.PullRequestNumber != 7 || saved.Usage.InputTokens != 100 {
+		t.Errorf("saved report = %+v", saved)
+	}
+	loaded, err := LoadReport(dir)
+	if err != nil {
+		t.Fatalf("LoadReport() error = %v", err)
+	}
+	if !strings.HasSuffix(loaded.RunID, "-doc-a") || loaded.RunID != report.RunID {
+		t.Errorf("LoadReport() run ID = %q, want %q", loaded.RunID, report.RunID)
+	}
+
+	markdown, err := os.ReadFile(filepath.Join(dir, ReportMarkdownFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Bauer report: Pricing",
+		"- Pull request: [#7](https://github.com/o/r/pull/7) (opened)",
+		"| `s2` | failed | anchor \\| not found |",
+		"- `make test` passed (fix sessions: 1)",
+		"- Total: 1m30s",
+		"120 tokens (100 in, 20 out)",
+	} {
+		if !strings.Contains(string(markdown), want) {
+			t.Errorf("markdown report is missing %q:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestLoadResult(t *testing.T) {
+	dir := t.TempDir()
+	extractionFile := filepath.Join(dir, ExtractionResultFile)
+	extraction := &gdocs.ProcessingResult{DocumentID: "doc-a", DocumentTitle: "Pricing"}
+	data, err := json.Marshal(extraction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(extractionFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	statusLedger := ledger.New("doc-a")
+	statusLedger.Set("s1", ledger.StatusApplied, "")
+	if err := statusLedger.Save(filepath.Join(dir, ledger.LedgerFile)); err != nil {
+		t.Fatal(err)
+	}
+	manifest := &prompt.Manifest{DocumentID: "doc-a", Chunks: []*prompt.ManifestChunk{
+		{ChunkNumber: 1, Filename: "chunk-1.md", Locations: []string{"l1"}, SuggestionIDs: []string{"s1"}, Status: prompt.ChunkCompleted, Output: "done"},
+		{ChunkNumber: 2, Filename: "chunk-2.md", Locations: []string{"l2"}, SuggestionIDs: []string{"s2"}, Status: prompt.ChunkFailed},
+	}}
+	report := NewReport(&OrchestrationResult{
+		ExtractionResult: extraction,
+		Ledger:           statusLedger,
+		Manifest:         manifest,
+		Usage:            copilotcli.Usage{InputTokens: 100},
+		TotalDuration:    time.Minute,
+	})
+	if err := report.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := LoadResult(extractionFile, dir)
+	if err != nil {
+		t.Fatalf("LoadResult() error = %v", err)
+	}
+	if result.ExtractionResult.DocumentTitle != "Pricing" || result.Usage.InputTokens != 100 || result.TotalDuration != time.Minute {
+		t.Errorf("LoadResult() = %+v", result)
+	}
+	if got := result.Ledger.Get("s1"); got == nil || got.Status != ledger.StatusApplied {
+		t.Errorf("ledger entry s1 = %+v, want applied", got)
+	}
+	if len(result.Chunks) != 2 || result.Chunks[1].LocationCount != 1 {
+		t.Errorf("Chunks = %+v, want both chunks of the manifest", result.Chunks)
+	}
+	if diff := cmp.Diff([]copilotcli.ChunkOutput{{ChunkNumber: 1, Output: "done"}}, result.CopilotOutputs); diff != "" {
+		t.Errorf("CopilotOutputs mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,53 @@
+package orchestrator
+
+import (
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// SuggestionResolver accepts suggestions in the source Google Doc.
+// Implemented by gdocs.Client.
+type SuggestionResolver interface {
+	AcceptSuggestion(ctx context.Context, docID, suggestionID string) error
+}
+
+// ResolveMergedSuggestions runs after a Bauer PR has merged: every suggestion the
+// ledger records as applied or verified is accepted in the Google Doc and marked
+// merged. Failed and skipped suggestions are left for the reviewers.
+// Returns the number of suggestions resolved; failures don't stop the remaining ones.
+func ResolveMergedSuggestions(ctx context.Context, resolver SuggestionResolver, statusLedger *ledger.Ledger) (int, []error) {
+	resolved := 0
+	var errs []error
+
+	for _, entry := range statusLedger.Entries {
+		if entry.Status != ledger.StatusApplied && entry.Status != ledger.StatusVerified {
+			continue
+		}
+		// Comments have nothing to accept; they're left for the reviewers to resolve
+		if gdocs.IsCommentInstruction(entry.SuggestionID) {
+			continue
+		}
+
+		if err := resolver.AcceptSuggestion(ctx, statusLedger.DocumentID, entry.SuggestionID); err != nil {
+			slog.Warn("Failed to accept suggestion",
+				slog.String("suggestion_id", entry.SuggestionID),
+				slog.String("error", err.Error()),
+			)
+			errs = append(errs, fmt.Errorf("suggestion %s: %w", entry.SuggestionID, err))
+			continue
+		}
+
+		statusLedger.Set(entry.SuggestionID, ledger.StatusMerged, "accepted in the document")
+		resolved++
+	}
+
+	slog.Info("Merged suggestions resolved",
+		slog.Int("resolved", resolved),
+		slog.Int("failed", len(errs)),
+	)
+
+	return resolved, errs
+}
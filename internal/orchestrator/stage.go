@@ -0,0 +1,24 @@
+package orchestrator
+
+import (
+	"context"
+
+	"bauer/internal/progress"
+)
+
+// Stages of Execute, in order. Approval is only reported for runs waiting for
+// approval, validation when a validation command is given, and summary for
+// runs of several chunks.
+const (
+	StageExtraction = "extraction"
+	StagePlanning   = "planning"
+	StageApproval   = "approval"
+	StageExecution  = "execution"
+	StageValidation = "validation"
+	StageSummary    = "summary"
+)
+
+// reportStage reports that Execute entered a stage.
+func reportStage(ctx context.Context, stage string) {
+	progress.Report(ctx, progress.Event{Type: progress.EventStage, Stage: stage})
+}
@@ -0,0 +1,112 @@
+package orchestrator
+
+import (
+	"bauer/internal/config"
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"bauer/internal/state"
+	"fmt"
+	"log/slog"
+)
+
+// priorState is what earlier runs recorded about the suggestions of a
+// document, in the state file.
+type priorState struct {
+	store *state.Store
+
+	// done are the suggestions earlier runs applied, dropped from the run;
+	// fresh are those no earlier run recorded, when some run recorded others
+	done  []string
+	fresh []string
+}
+
+// skipApplied drops the suggestions earlier runs applied from result, unless
+// cfg.Reapply is set. It returns nil without a state file.
+func skipApplied(cfg *config.Config, result *gdocs.ProcessingResult) *priorState {
+	if cfg.StateFile == "" {
+		return nil
+	}
+	store, err := state.Open(cfg.StateFile)
+	if err != nil {
+		// The run can go on without it, but may duplicate earlier changes
+		slog.Warn("Failed to read suggestion state; processing every suggestion", slog.String("error", err.Error()))
+		return nil
+	}
+	prior := &priorState{store: store}
+	if len(store.Documents[result.DocumentID]) > 0 {
+		for _, sugg := range result.ActionableSuggestions {
+			if store.Get(result.DocumentID, sugg.ID) == nil {
+				prior.fresh = append(prior.fresh, sugg.ID)
+			}
+		}
+	}
+	if cfg.Reapply {
+		return prior
+	}
+
+	applied := store.AppliedIDs(result.DocumentID)
+	for _, sugg := range result.ActionableSuggestions {
+		for _, id := range applied {
+			if sugg.ID == id {
+				prior.done = append(prior.done, id)
+			}
+		}
+	}
+	if len(prior.done) > 0 {
+		gdocs.FilterSuggestions(result, gdocs.SuggestionFilter{ExcludeIDs: prior.done})
+		slog.Info("Skipped suggestions applied by earlier runs",
+			slog.Int("count", len(prior.done)),
+			slog.String("state_file", cfg.StateFile),
+		)
+	}
+	if len(prior.fresh) > 0 {
+		slog.Info("New suggestions since the last run", slog.Int("count", len(prior.fresh)))
+	}
+	return prior
+}
+
+// recordDone marks the suggestions earlier runs applied as done in the
+// ledger, with where they went.
+func (p *priorState) recordDone(statusLedger *ledger.Ledger, docID string) {
+	if p == nil {
+		return
+	}
+	for _, id := range p.done {
+		record := p.store.Get(docID, id)
+		note := "applied by run " + record.RunID
+		if record.PullRequestURL != "" {
+			note = fmt.Sprintf("applied in %s", record.PullRequestURL)
+		}
+		statusLedger.Set(id, ledger.StatusDone, note)
+	}
+}
+
+// newSuggestions returns the suggestions no earlier run recorded.
+func (p *priorState) newSuggestions() []string {
+	if p == nil {
+		return nil
+	}
+	return p.fresh
+}
+
+// saveState records the outcome of the suggestions of a run in the state
+// file. Failures are logged: the run is complete without it.
+func saveState(cfg *config.Config, statusLedger *ledger.Ledger, runID string) {
+	if cfg.StateFile == "" {
+		return
+	}
+	store, err := state.Open(cfg.StateFile)
+	if err != nil {
+		slog.Warn("Failed to read suggestion state", slog.String("error", err.Error()))
+		return
+	}
+	recorded := store.Update(statusLedger, runID)
+	if err := store.Save(); err != nil {
+		slog.Warn("Failed to write suggestion state", slog.String("error", err.Error()))
+		return
+	}
+	slog.Info("Suggestion state updated",
+		slog.String("state_file", cfg.StateFile),
+		slog.Int("recorded", recorded),
+	)
+}
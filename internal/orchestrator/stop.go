@@ -0,0 +1,34 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrStopped is returned by Execute for runs stopped between chunks (see
+// StopAfterChunk). The chunk manifest records the chunks that completed, so
+// the run can be resumed with config.Config.Resume.
+var ErrStopped = errors.New("run stopped before all chunks were executed")
+
+type stopKey struct{}
+
+// StopAfterChunk returns ctx with a stop signal for Execute: once stop is
+// closed, runs finish the chunk they are executing and return ErrStopped
+// rather than start the next, e.g. when the server shuts down.
+func StopAfterChunk(ctx context.Context, stop <-chan struct{}) context.Context {
+	return context.WithValue(ctx, stopKey{}, stop)
+}
+
+// stopRequested reports whether the stop signal of ctx was given.
+func stopRequested(ctx context.Context) bool {
+	stop, _ := ctx.Value(stopKey{}).(<-chan struct{})
+	if stop == nil {
+		return false
+	}
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,40 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"bauer/internal/config"
+	"bauer/internal/prompt"
+)
+
+func TestExecuteChunksStopped(t *testing.T) {
+	chunks := []prompt.ChunkResult{
+		{ChunkNumber: 1, Filename: "chunk-1.md", Content: "first"},
+		{ChunkNumber: 2, Filename: "chunk-2.md", Content: "second"},
+	}
+	manifest := prompt.NewManifest("doc", chunks)
+	manifest.SetStatus(1, prompt.ChunkCompleted, "")
+	cfg := &config.Config{OutputDir: t.TempDir()}
+
+	if stopRequested(context.Background()) {
+		t.Error("stopRequested() without a stop signal = true")
+	}
+	stop := make(chan struct{})
+	ctx := StopAfterChunk(context.Background(), stop)
+	if stopRequested(ctx) {
+		t.Error("stopRequested() before stop is closed = true")
+	}
+	close(stop)
+
+	// Chunk 1 is skipped as completed, and chunk 2 never starts, so the
+	// executor is never used
+	_, _, err := executeCopilotChunks(ctx, chunks, cfg, nil, manifest, nil, nil, nil)
+	if !errors.Is(err, ErrStopped) {
+		t.Fatalf("executeCopilotChunks() error = %v, want ErrStopped", err)
+	}
+	if status := manifest.Get(2).Status; status != prompt.ChunkPending {
+		t.Errorf("chunk 2 status = %s, want %s", status, prompt.ChunkPending)
+	}
+}
@@ -0,0 +1,164 @@
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"bauer/internal/htmlcheck"
+)
+
+// TemplateDamage is damage a chunk did to an HTML template. Chunk 0 is the
+// direct patches.
+type TemplateDamage struct {
+	Chunk    int                 `json:"chunk"`
+	File     string              `json:"file"`
+	Problems []htmlcheck.Problem `json:"problems"`
+}
+
+// templateGuard checks the HTML templates changed by each chunk for damage,
+// against their content before the chunk, so the damage is traced to the
+// chunk that did it. Changes made before the run are taken as they are.
+type templateGuard struct {
+	root string
+
+	// contents are the changed templates as of the last check
+	contents map[string]string
+	damage   []TemplateDamage
+}
+
+// newTemplateGuard returns a guard for the git repository dir is in.
+func newTemplateGuard(dir string) (*templateGuard, error) {
+	out, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	g := &templateGuard{root: strings.TrimSpace(out), contents: make(map[string]string)}
+	paths, err := g.changedTemplates()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		g.contents[path] = g.read(path)
+	}
+	return g, nil
+}
+
+// check compares the templates changed since the last check with their
+// previous content and records the damage found as the chunk's.
+func (g *templateGuard) check(chunkNumber int) {
+	paths, err := g.changedTemplates()
+	if err != nil {
+		slog.Warn("Failed to list changed templates", slog.Int("chunk", chunkNumber), slog.String("error", err.Error()))
+		return
+	}
+	for _, path := range paths {
+		after := g.read(path)
+		before, ok := g.contents[path]
+		if !ok {
+			before = g.original(path)
+		}
+		if after == before {
+			continue
+		}
+		g.contents[path] = after
+
+		problems := htmlcheck.Compare(before, after)
+		if len(problems) == 0 {
+			continue
+		}
+		g.damage = append(g.damage, TemplateDamage{Chunk: chunkNumber, File: path, Problems: problems})
+		for _, problem := range problems {
+			slog.Error("Chunk damaged a template",
+				slog.Int("chunk", chunkNumber),
+				slog.String("file", path),
+				slog.String("problem", problem.String()),
+			)
+		}
+	}
+}
+
+// found returns the damage found so far; nil for a nil guard.
+func (g *templateGuard) found() []TemplateDamage {
+	if g == nil {
+		return nil
+	}
+	return g.damage
+}
+
+// err returns an error wrapping ErrValidationFailed when a chunk damaged a template.
+func (g *templateGuard) err() error {
+	if g == nil || len(g.damage) == 0 {
+		return nil
+	}
+	var chunks []string
+	for _, damage := range g.damage {
+		if damage.Chunk == directPatchesChunk {
+			chunks = append(chunks, "direct patches in "+damage.File)
+			continue
+		}
+		chunks = append(chunks, fmt.Sprintf("chunk %d in %s", damage.Chunk, damage.File))
+	}
+	return fmt.Errorf("%w: templates damaged by %s", ErrValidationFailed, strings.Join(chunks, ", "))
+}
+
+// changedTemplates lists the HTML files changed or added in the working
+// tree, relative to the repository root. Deleted files are left out.
+func (g *templateGuard) changedTemplates() ([]string, error) {
+	out, err := gitOutput(g.root, "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		status, path := entry[:2], entry[3:]
+		if status[0] == 'R' || status[0] == 'C' {
+			// The original path follows renames and copies
+			i++
+		}
+		if strings.Contains(status, "D") || !strings.EqualFold(filepath.Ext(path), ".html") {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// read returns the content of a file in the working tree.
+func (g *templateGuard) read(path string) string {
+	content, err := os.ReadFile(filepath.Join(g.root, path))
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// original returns the content of a file at HEAD, empty for new files.
+func (g *templateGuard) original(path string) string {
+	content, err := gitOutput(g.root, "show", "HEAD:"+path)
+	if err != nil {
+		return ""
+	}
+	return content
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
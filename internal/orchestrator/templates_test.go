@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTemplateGuard(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Bauer")
+	t.Setenv("GIT_AUTHOR_EMAIL", "bauer@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Bauer")
+	t.Setenv("GIT_COMMITTER_EMAIL", "bauer@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	dir := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("templates/index.html", "{% block content %}<div><p>Old copy</p></div>{% endblock %}\n")
+	write("templates/about.html", "{% block content %}<h1>About</h1>{% endblock %}\n")
+	for _, args := range [][]string{{"init"}, {"add", "."}, {"commit", "-m", "Initial commit"}} {
+		if _, err := gitOutput(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	guard, err := newTemplateGuard(filepath.Join(dir, "templates"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Chunk 1 changes copy; chunk 2 breaks a tag and deletes a block
+	write("templates/index.html", "{% block content %}<div><p>New copy</p></div>{% endblock %}\n")
+	write("notes.txt", "not a template\n")
+	guard.check(1)
+	if err := guard.err(); err != nil {
+		t.Fatalf("err() after chunk 1 = %v", err)
+	}
+	write("templates/index.html", "{% block content %}<div><p>New <strong>copy</p></div>{% endblock %}\n")
+	write("templates/about.html", "<h1>About us</h1>\n")
+	guard.check(2)
+
+	var got []string
+	for _, damage := range guard.found() {
+		for _, problem := range damage.Problems {
+			got = append(got, damage.File+": "+problem.String())
+		}
+		if damage.Chunk != 2 {
+			t.Errorf("damage to %s attributed to chunk %d, want 2", damage.File, damage.Chunk)
+		}
+	}
+	want := []string{
+		"templates/about.html: {% block content %} was removed",
+		"templates/index.html: line 1: <strong> is not closed before </p>",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("damage mismatch (-want +got):\n%s", diff)
+	}
+	if err := guard.err(); !errors.Is(err, ErrValidationFailed) {
+		t.Errorf("err() = %v, want ErrValidationFailed", err)
+	}
+}
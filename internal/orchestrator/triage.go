@@ -0,0 +1,78 @@
+package orchestrator
+
+import (
+	"bauer/internal/config"
+	"bauer/internal/executor"
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"bauer/internal/triage"
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+)
+
+// triageSuggestions classifies the groups of result and routes them: held
+// and skipped groups are recorded in the ledger, and the result the chunks
+// are planned from has the groups to apply.
+func triageSuggestions(ctx context.Context, cfg *config.Config, result *gdocs.ProcessingResult, statusLedger *ledger.Ledger) (*gdocs.ProcessingResult, *triage.Report, error) {
+	routes, err := triage.ParseRoutes(cfg.TriageRoutes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid triage routes: %w", err)
+	}
+	report := triage.ClassifyGroups(result.GroupedSuggestions, routes)
+	if cfg.Triage == triage.ModeLLM {
+		refineTriage(ctx, cfg, result.GroupedSuggestions, report, routes)
+	}
+
+	reportPath := filepath.Join(cfg.OutputDir, triage.TriageFile)
+	if err := report.Save(reportPath); err != nil {
+		// The classification is for review; the run doesn't depend on it
+		slog.Warn("Failed to write triage report", slog.String("error", err.Error()))
+	}
+
+	split := report.Split(result.GroupedSuggestions)
+	for _, entry := range report.Entries {
+		note := fmt.Sprintf("%s, %s risk", entry.Category, entry.Risk)
+		for _, id := range entry.SuggestionIDs {
+			switch entry.Route {
+			case triage.RouteReview:
+				statusLedger.Set(id, ledger.StatusHeld, note)
+			case triage.RouteSkip:
+				statusLedger.Set(id, ledger.StatusSkipped, "triage: "+note)
+			}
+		}
+	}
+	counts := report.Count()
+	slog.Info("Suggestions triaged",
+		slog.String("mode", report.Mode),
+		slog.Int("apply", counts[triage.RouteApply]),
+		slog.Int("review", counts[triage.RouteReview]),
+		slog.Int("skip", counts[triage.RouteSkip]),
+		slog.String("triage_file", reportPath),
+	)
+
+	routed := *result
+	routed.GroupedSuggestions = split[triage.RouteApply]
+	return &routed, report, nil
+}
+
+// refineTriage has the model of the openai executor review the rule
+// classification. Failures keep the rules, as they are only a second opinion.
+func refineTriage(ctx context.Context, cfg *config.Config, groups []gdocs.LocationGroupedSuggestions, report *triage.Report, routes triage.Routes) {
+	assistant := executor.NewOpenAIExecutor(cfg.WorkDir, "")
+	if err := assistant.Start(); err != nil {
+		slog.Warn("LLM triage unavailable; keeping the rules", slog.String("error", err.Error()))
+		return
+	}
+	model := cmp.Or(cfg.SummaryModel, cfg.Model)
+	ask := triage.AskFunc(func(ctx context.Context, prompt string) (string, error) {
+		reply, usage, err := assistant.Ask(ctx, model, prompt)
+		slog.Info("Triage usage", slog.String("model", model), slog.String("usage", usage.String()))
+		return reply, err
+	})
+	if err := triage.Refine(ctx, ask, groups, report, routes); err != nil {
+		slog.Warn("LLM triage failed; keeping the rules", slog.String("error", err.Error()))
+	}
+}
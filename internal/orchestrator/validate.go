@@ -0,0 +1,130 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bauer/internal/config"
+	"bauer/internal/copilotcli"
+	"bauer/internal/executor"
+)
+
+// validationTimeout bounds a single run of the validation command.
+const validationTimeout = 15 * time.Minute
+
+// validationOutputLines is how much of the output of a failing validation is
+// kept and shown to the fixing session.
+const validationOutputLines = 200
+
+// ErrValidationFailed is returned when the validation command still fails
+// after the fixing sessions; the changes must not be pushed.
+var ErrValidationFailed = errors.New("validation failed")
+
+// ValidationResult records how the changes of a run were validated.
+type ValidationResult struct {
+	Command string `json:"command"`
+	Passed  bool   `json:"passed"`
+
+	// Fixes is the number of sessions asked to fix validation errors
+	Fixes int `json:"fixes"`
+
+	// Output is the end of the output of the last failing run
+	Output string `json:"output,omitempty"`
+}
+
+// validateChanges runs cfg.ValidateCommand in the target repository. While it
+// fails, up to cfg.ValidateRetries sessions are given its output to fix the
+// errors; they are numbered after the chunks, from firstSession. Like chunks,
+// each session's changes to protected paths are reverted and its templates
+// checked, with its number; a damaged template stops the fixes. It returns
+// the token usage of the fixing sessions.
+func validateChanges(ctx context.Context, cfg *config.Config, client executor.Executor, cwd string, firstSession int,
+	guard *templateGuard, protected *pathPolicy) (*ValidationResult, copilotcli.Usage, error) {
+	result := &ValidationResult{Command: cfg.ValidateCommand}
+	var usage copilotcli.Usage
+	for {
+		output, err := runValidation(ctx, cfg.ValidateCommand, cwd)
+		if err == nil {
+			result.Passed = true
+			result.Output = ""
+			slog.Info("Validation passed", slog.String("command", cfg.ValidateCommand), slog.Int("fixes", result.Fixes))
+			return result, usage, nil
+		}
+		result.Output = lastLines(output, validationOutputLines)
+		slog.Warn("Validation failed",
+			slog.String("command", cfg.ValidateCommand),
+			slog.Int("fixes", result.Fixes),
+			slog.String("error", err.Error()),
+		)
+		if result.Fixes == cfg.ValidateRetries || ctx.Err() != nil {
+			return result, usage, fmt.Errorf("%w: %s: %v", ErrValidationFailed, cfg.ValidateCommand, err)
+		}
+
+		result.Fixes++
+		path := filepath.Join(cfg.OutputDir, fmt.Sprintf("validation-fix-%d.md", result.Fixes))
+		if err := os.WriteFile(path, []byte(validationFixPrompt(cfg.ValidateCommand, result.Output)), 0644); err != nil {
+			return result, usage, fmt.Errorf("failed to write validation fix prompt: %w", err)
+		}
+		session := firstSession + result.Fixes - 1
+		_, sessionUsage, err := client.ExecuteChunk(ctx, path, session, cfg.Model)
+		usage.Add(sessionUsage)
+		if err != nil {
+			// The validation is run again; a failed session changes nothing
+			slog.Warn("Validation fix session failed", slog.Int("fix", result.Fixes), slog.String("error", err.Error()))
+		}
+		if protected != nil {
+			protected.check(session)
+		}
+		if guard != nil {
+			guard.check(session)
+			if err := guard.err(); err != nil {
+				return result, usage, err
+			}
+		}
+	}
+}
+
+// runValidation runs the validation command with sh and returns its combined
+// output.
+func runValidation(ctx context.Context, command, dir string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, validationTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return string(output), fmt.Errorf("timed out after %s", validationTimeout)
+	}
+	return string(output), err
+}
+
+// validationFixPrompt asks a session to fix the errors the changes caused.
+func validationFixPrompt(command, output string) string {
+	return fmt.Sprintf(`# Fix validation errors
+
+Copy changes were just applied to this repository. Since then, `+"`%s`"+` fails:
+
+`+"```"+`
+%s
+`+"```"+`
+
+Fix the errors, e.g. unbalanced HTML tags or template syntax broken by the copy changes. Keep the copy changes: don't revert them, and change nothing unrelated to the errors. The command is run again once you are done.
+`, command, strings.TrimSpace(output))
+}
+
+// lastLines keeps the last n lines of text, where errors are usually reported.
+func lastLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
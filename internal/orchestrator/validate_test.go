@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bauer/internal/config"
+	"bauer/internal/copilotcli"
+)
+
+// fixingExecutor creates the file the validation command checks for once it
+// has run fixesNeeded sessions, calling edit, when set, in each session.
+type fixingExecutor struct {
+	dir         string
+	fixesNeeded int
+	sessions    []int
+	edit        func()
+}
+
+func (f *fixingExecutor) Start() error { return nil }
+func (f *fixingExecutor) Stop() error  { return nil }
+func (f *fixingExecutor) GenerateSummary(ctx context.Context, outputs []copilotcli.ChunkOutput, model string) (copilotcli.Usage, error) {
+	return copilotcli.Usage{}, nil
+}
+
+func (f *fixingExecutor) ExecuteChunk(ctx context.Context, chunkPath string, chunkNumber int, model string) (string, copilotcli.Usage, error) {
+	f.sessions = append(f.sessions, chunkNumber)
+	if f.edit != nil {
+		f.edit()
+	}
+	if len(f.sessions) == f.fixesNeeded {
+		if err := os.WriteFile(filepath.Join(f.dir, "fixed"), nil, 0644); err != nil {
+			return "", copilotcli.Usage{}, err
+		}
+	}
+	return "done", copilotcli.Usage{InputTokens: 100}, nil
+}
+
+func TestValidateChanges(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixesNeeded int
+		retries     int
+		wantFixes   int
+		wantErr     bool
+	}{
+		{"passes", 0, 2, 0, false},
+		{"fixed", 2, 2, 2, false},
+		{"retries exhausted", 3, 2, 2, true},
+		{"no retries", 1, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.fixesNeeded == 0 {
+				if err := os.WriteFile(filepath.Join(dir, "fixed"), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg := &config.Config{
+				ValidateCommand: "test -f fixed || { echo 'missing fixed'; exit 1; }",
+				ValidateRetries: tt.retries,
+				OutputDir:       t.TempDir(),
+				Model:           "mini",
+			}
+			fake := &fixingExecutor{dir: dir, fixesNeeded: tt.fixesNeeded}
+
+			result, usage, err := validateChanges(context.Background(), cfg, fake, dir, 4, nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateChanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrValidationFailed) {
+				t.Errorf("validateChanges() error = %v, want ErrValidationFailed", err)
+			}
+			if result.Passed == tt.wantErr {
+				t.Errorf("Passed = %v, want %v", result.Passed, !tt.wantErr)
+			}
+			if result.Fixes != tt.wantFixes {
+				t.Errorf("Fixes = %d, want %d", result.Fixes, tt.wantFixes)
+			}
+			if usage.InputTokens != 100*tt.wantFixes {
+				t.Errorf("InputTokens = %d, want %d", usage.InputTokens, 100*tt.wantFixes)
+			}
+			for i, session := range fake.sessions {
+				if session != 4+i {
+					t.Errorf("session %d numbered %d, want %d", i, session, 4+i)
+				}
+			}
+			if tt.wantErr && result.Output != "missing fixed" {
+				t.Errorf("Output = %q", result.Output)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package orchestrator
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bauer/internal/config"
+	"bauer/internal/copilotcli"
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"bauer/internal/prompt"
+	"bauer/internal/staleness"
+)
+
+// verifyChunkChanges checks, chunk by chunk, that the suggestions still marked
+// applied made it into the files the chunk modified: the text expected after
+// each change must be found in one of them. Suggestions whose text isn't found
+// are marked unverified, for reviewers to check before the PR merges.
+// The files are those of the chunk's JSON report, or else the file each
+// suggestion's location was resolved to; without either a suggestion stays applied.
+func verifyChunkChanges(cfg *config.Config, chunks []prompt.ChunkResult, outputs []copilotcli.ChunkOutput, result *gdocs.ProcessingResult, statusLedger *ledger.Ledger) {
+	repoRoot := cmp.Or(cfg.TargetRepo, ".")
+	// The normalization steps were checked when the config was validated
+	normalization, _ := cfg.Normalization()
+
+	suggestions := make(map[string]gdocs.GroupedActionableSuggestion)
+	resolvedFiles := make(map[string]string)
+	for _, group := range result.GroupedSuggestions {
+		for _, sugg := range group.Suggestions {
+			suggestions[sugg.ID] = sugg
+			if group.ResolvedFile != "" {
+				resolvedFiles[sugg.ID] = group.ResolvedFile
+			}
+		}
+	}
+
+	texts := make(map[string][]string)
+	for _, output := range outputs {
+		var chunk *prompt.ChunkResult
+		for i := range chunks {
+			if chunks[i].ChunkNumber == output.ChunkNumber {
+				chunk = &chunks[i]
+			}
+		}
+		if chunk == nil {
+			continue
+		}
+
+		var modified []string
+		if report, err := ledger.ParseChunkReport(output.Output); err == nil {
+			modified = report.FilesModified
+		}
+
+		verified, unverified := 0, 0
+		for _, id := range chunk.SuggestionIDs {
+			entry := statusLedger.Get(id)
+			sugg, ok := suggestions[id]
+			if entry == nil || entry.Status != ledger.StatusApplied || !ok {
+				continue
+			}
+			expected := compactText(normalization.Apply(staleness.PageText(sugg.Verification.TextAfterChange)))
+			paths := modified
+			if len(paths) == 0 && resolvedFiles[id] != "" {
+				paths = []string{resolvedFiles[id]}
+			}
+			if expected == "" || len(paths) == 0 {
+				continue
+			}
+
+			status, note := ledger.StatusUnverified, "expected text not found in "+strings.Join(paths, ", ")
+			for _, path := range paths {
+				if _, ok := texts[path]; !ok {
+					texts[path] = loadFileTexts(repoRoot, path, normalization)
+				}
+				if containsAny(texts[path], expected) {
+					status, note = ledger.StatusVerified, "expected text found in "+path
+					break
+				}
+			}
+
+			statusLedger.Set(id, status, note)
+			for _, mergedID := range sugg.MergedIDs {
+				statusLedger.Set(mergedID, status, fmt.Sprintf("merged into `%s`", id))
+			}
+			if status == ledger.StatusVerified {
+				verified++
+				continue
+			}
+			unverified++
+			slog.Warn("Applied suggestion could not be verified",
+				slog.String("suggestion_id", id),
+				slog.Int("chunk", chunk.ChunkNumber),
+				slog.String("files", strings.Join(paths, ", ")),
+			)
+		}
+
+		slog.Info("Chunk verification complete",
+			slog.Int("chunk", chunk.ChunkNumber),
+			slog.Int("verified", verified),
+			slog.Int("unverified", unverified),
+		)
+	}
+}
+
+// loadFileTexts returns the text of a modified file in the forms an expected
+// text is looked for in: with markup stripped, and as is, for text in
+// attributes such as meta descriptions. Files that can't be read have none.
+func loadFileTexts(repoRoot, path string, normalization gdocs.Normalization) []string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repoRoot, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("Failed to read modified file for verification",
+			slog.String("path", path),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+	return []string{
+		compactText(normalization.Apply(staleness.PageText(string(content)))),
+		compactText(normalization.Apply(string(content))),
+	}
+}
+
+// compactText drops all whitespace, so text still matches when markup split
+// it differently, e.g. "a <em>b</em>." stripped to "a b ."
+func compactText(text string) string {
+	return strings.Join(strings.Fields(text), "")
+}
+
+func containsAny(texts []string, s string) bool {
+	for _, text := range texts {
+		if strings.Contains(text, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+// Package preview renders the planned changes of a run as a local HTML page,
+// so a content owner can review every suggestion before Copilot applies it.
+package preview
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+
+	"bauer/internal/gdocs"
+)
+
+// PreviewFile is the name of the preview written with each run's artifacts.
+const PreviewFile = "bauer-preview.html"
+
+//go:embed templates/preview.html
+var previewTemplate string
+
+var tmpl = template.Must(template.New("preview").Parse(previewTemplate))
+
+type pageData struct {
+	Title      string
+	DocumentID string
+	URL        string
+	Total      int
+	Locations  []locationData
+}
+
+type locationData struct {
+	ID          string
+	Context     string
+	Comments    []string
+	Suggestions []suggestionData
+}
+
+type suggestionData struct {
+	ID        string
+	Type      string
+	Author    string
+	Preceding string
+	Original  string
+	New       string
+	Following string
+
+	// Detail describes changes that aren't text edits, e.g. formatting
+	Detail string
+
+	// Notes flag things the reviewer should check, e.g. stale anchors
+	Notes []string
+}
+
+// Write renders the preview of result to path.
+func Write(path string, result *gdocs.ProcessingResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create preview: %w", err)
+	}
+	defer f.Close()
+
+	if err := Render(f, result); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write preview: %w", err)
+	}
+	return nil
+}
+
+// Render writes the preview of result as an HTML page: every grouped
+// suggestion as a red/green diff in its surrounding text, under its location.
+func Render(w io.Writer, result *gdocs.ProcessingResult) error {
+	data := pageData{
+		Title:      result.DocumentTitle,
+		DocumentID: result.DocumentID,
+	}
+	if result.Metadata != nil {
+		data.URL = result.Metadata.SuggestedUrl
+	}
+
+	for _, group := range result.GroupedSuggestions {
+		location := locationData{
+			ID:      group.LocationID,
+			Context: LocationContext(group.Location),
+		}
+		for _, comment := range group.Comments {
+			location.Comments = append(location.Comments, fmt.Sprintf("%s: %s", comment.Author, comment.Content))
+		}
+		for _, sugg := range group.Suggestions {
+			location.Suggestions = append(location.Suggestions, newSuggestionData(sugg))
+		}
+		data.Total += len(group.Suggestions)
+		data.Locations = append(data.Locations, location)
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render preview: %w", err)
+	}
+	return nil
+}
+
+func newSuggestionData(sugg gdocs.GroupedActionableSuggestion) suggestionData {
+	change := sugg.Change
+	data := suggestionData{
+		ID:        sugg.ID,
+		Type:      change.Type,
+		Author:    sugg.Author,
+		Preceding: sugg.Anchor.PrecedingText,
+		Following: sugg.Anchor.FollowingText,
+		Original:  change.OriginalText,
+		New:       change.NewText,
+	}
+
+	switch change.Type {
+	case "style", "paragraph":
+		// The text stays the same
+		data.Preceding += change.OriginalText
+		data.Original, data.New = "", ""
+		if change.Style != nil {
+			data.Detail = change.Style.String()
+		} else if change.NamedStyle != nil {
+			data.Detail = fmt.Sprintf("paragraph style: %s -> %s", change.NamedStyle.Before, change.NamedStyle.After)
+		}
+	case "comment_instruction":
+		data.Detail = "Reviewer comment: " + change.Instruction
+	}
+
+	if change.OriginalLinkURL != change.NewLinkURL && change.NewLinkURL != "" {
+		data.Notes = append(data.Notes, "links to "+change.NewLinkURL)
+	}
+	if sugg.MovedFrom != nil {
+		data.Notes = append(data.Notes, "moved from "+LocationContext(sugg.MovedFrom.Location))
+	}
+	if len(sugg.MergedIDs) > 0 {
+		data.Notes = append(data.Notes, "includes "+strings.Join(sugg.MergedIDs, ", "))
+	}
+	if sugg.Stale {
+		data.Notes = append(data.Notes, "stale: the text was not found on the published page")
+	}
+	return data
+}
+
+// LocationContext describes a location for people, e.g.
+// "Body › Pricing › Hero table, row 2, column 1", prefixed with the page URL
+// in documents that cover several pages.
+func LocationContext(loc gdocs.SuggestionLocation) string {
+	var parts []string
+	if loc.PageURL != "" {
+		parts = append(parts, loc.PageURL)
+	}
+	if loc.Tab != "" {
+		parts = append(parts, loc.Tab)
+	}
+	parts = append(parts, loc.Section)
+	if loc.InMetadata {
+		parts = append(parts, strings.TrimSpace(loc.MetadataTable+" Metadata"))
+	}
+	if loc.ParentHeading != "" {
+		parts = append(parts, loc.ParentHeading)
+	}
+	if loc.Table != nil {
+		table := loc.Table.TableTitle
+		if table == "" {
+			table = loc.Table.TableID
+		}
+		parts = append(parts, fmt.Sprintf("%s table, row %d, column %d", table, loc.Table.RowIndex, loc.Table.ColumnIndex))
+	}
+	if loc.List != nil {
+		parts = append(parts, fmt.Sprintf("list item %d", loc.List.Ordinal))
+	}
+	if loc.Element != "" {
+		parts = append(parts, strings.ReplaceAll(loc.Element, "_", " "))
+	}
+	if loc.NearObject != "" {
+		parts = append(parts, "near "+strings.ReplaceAll(loc.NearObject, "_", " "))
+	}
+	return strings.Join(parts, " › ")
+}
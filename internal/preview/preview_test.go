@@ -0,0 +1,58 @@
+package preview
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"bauer/internal/gdocs"
+)
+
+func TestRender(t *testing.T) {
+	result := &gdocs.ProcessingResult{
+		DocumentTitle: "Pricing",
+		GroupedSuggestions: []gdocs.LocationGroupedSuggestions{
+			{
+				LocationID: "loc-1",
+				Location: gdocs.SuggestionLocation{
+					Section:       "Body",
+					ParentHeading: "Plans",
+				},
+				Suggestions: []gdocs.GroupedActionableSuggestion{
+					{
+						ID:     "suggest.1",
+						Anchor: gdocs.SuggestionAnchor{PrecedingText: "Costs ", FollowingText: " a month"},
+						Change: gdocs.SuggestionChange{Type: "replace", OriginalText: "$10", NewText: "<b>$12</b>"},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, result); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	page := buf.String()
+
+	for _, want := range []string{
+		`<h2>Body › Plans</h2>`,
+		`<span class="context">Costs </span><del>$10</del><ins>&lt;b&gt;$12&lt;/b&gt;</ins><span class="context"> a month</span>`,
+		`1 suggestions in 1 locations`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Render() output missing %q", want)
+		}
+	}
+}
+
+func TestLocationContext(t *testing.T) {
+	loc := gdocs.SuggestionLocation{
+		Section: "Body",
+		InTable: true,
+		Table:   &gdocs.TableLocation{TableTitle: "Hero", RowIndex: 2, ColumnIndex: 1},
+	}
+	if got, want := LocationContext(loc), "Body › Hero table, row 2, column 1"; got != want {
+		t.Errorf("LocationContext() = %q, want %q", got, want)
+	}
+}
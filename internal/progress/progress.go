@@ -0,0 +1,59 @@
+// Package progress carries the progress events of a run, such as stages and
+// chunks starting, to whoever follows the run, e.g. the API's event streams.
+// Runs report to the Reporter of their context; without one, nothing is reported.
+package progress
+
+import "context"
+
+// Event types.
+const (
+	EventStage          = "stage"
+	EventChunkStarted   = "chunk_started"
+	EventChunkCompleted = "chunk_completed"
+	EventChunkFailed    = "chunk_failed"
+	EventDelta          = "delta"
+	EventPRCreated      = "pr_created"
+
+	// EventApprovalRequested is reported when a run waits for its plan to be
+	// approved, with how to approve it as the message
+	EventApprovalRequested = "approval_requested"
+)
+
+// Event is a step of a run.
+type Event struct {
+	Type string `json:"type"`
+
+	// Stage is the stage entered, for stage events
+	Stage string `json:"stage,omitempty"`
+
+	// Chunk and TotalChunks are set for chunk and delta events
+	Chunk       int `json:"chunk,omitempty"`
+	TotalChunks int `json:"total_chunks,omitempty"`
+
+	// Text is the streamed model output, for delta events
+	Text string `json:"text,omitempty"`
+
+	// URL is the pull request, for pr_created events
+	URL string `json:"url,omitempty"`
+
+	// Message is the error of failed chunks, or the instructions of
+	// approval requests
+	Message string `json:"message,omitempty"`
+}
+
+// Reporter receives the events of a run. It must not block.
+type Reporter func(Event)
+
+type reporterKey struct{}
+
+// WithReporter returns a context whose runs report their events to fn.
+func WithReporter(ctx context.Context, fn Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, fn)
+}
+
+// Report sends an event to the Reporter of ctx, if any.
+func Report(ctx context.Context, event Event) {
+	if fn, ok := ctx.Value(reporterKey{}).(Reporter); ok && fn != nil {
+		fn(event)
+	}
+}
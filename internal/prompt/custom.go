@@ -0,0 +1,139 @@
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"bauer/internal/gdocs"
+)
+
+// Custom template files read from a template directory.
+const (
+	// InstructionsTemplateFile replaces the built-in instructions and the
+	// Vanilla Framework reference of every chunk prompt
+	InstructionsTemplateFile = "instructions.md.tmpl"
+	// SummaryTemplateFile replaces the built-in instructions of the summary prompt
+	SummaryTemplateFile = "summary.md.tmpl"
+)
+
+// InstructionsData is the data the instructions template is executed with.
+type InstructionsData struct {
+	DocumentTitle string
+	SuggestedURL  string
+	ChunkNumber   int
+	TotalChunks   int
+
+	// PageRefresh is true when whole sections are rebuilt rather than
+	// suggestions applied
+	PageRefresh bool
+
+	// Locations are the location groups of the chunk; Sections the sections
+	// of a section refresh chunk
+	Locations []gdocs.LocationGroupedSuggestions
+	Sections  []gdocs.DocumentSection
+}
+
+// SummaryData is the data the summary template is executed with.
+type SummaryData struct {
+	DocumentTitle string
+	ChunkCount    int
+}
+
+// Templates are user-provided Go templates (text/template) that replace the
+// built-in prompts, e.g. for sites that use another CSS framework. Either
+// template may be nil, in which case the built-in prompt is used.
+type Templates struct {
+	Instructions *template.Template
+	Summary      *template.Template
+}
+
+// LoadTemplates reads and validates the templates in dir. The directory must
+// hold at least one of InstructionsTemplateFile and SummaryTemplateFile, and
+// no other .tmpl files. Templates are checked by executing them with sample
+// data, so unknown fields are reported before a run starts.
+func LoadTemplates(dir string) (*Templates, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template directory: %w", err)
+	}
+
+	templates := &Templates{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".tmpl") {
+			continue
+		}
+		switch name {
+		case InstructionsTemplateFile:
+			templates.Instructions, err = parseTemplate(dir, name, InstructionsData{
+				DocumentTitle: "Example",
+				SuggestedURL:  "example.com/page",
+				ChunkNumber:   1,
+				TotalChunks:   1,
+			})
+		case SummaryTemplateFile:
+			templates.Summary, err = parseTemplate(dir, name, SummaryData{DocumentTitle: "Example", ChunkCount: 2})
+		default:
+			err = fmt.Errorf("unknown template %s (expected %s or %s)", name, InstructionsTemplateFile, SummaryTemplateFile)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if templates.Instructions == nil && templates.Summary == nil {
+		return nil, fmt.Errorf("no templates in %s (expected %s or %s)", dir, InstructionsTemplateFile, SummaryTemplateFile)
+	}
+	return templates, nil
+}
+
+// parseTemplate parses a template file and executes it with sample data.
+func parseTemplate(dir, name string, sample any) (*template.Template, error) {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+	tmpl, err := newTemplate(name, string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, sample); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderSummary renders the summary instructions, or returns "" when the
+// built-in ones should be used.
+func (t *Templates) RenderSummary(data SummaryData) (string, error) {
+	if t == nil || t.Summary == nil {
+		return "", nil
+	}
+	return execute(t.Summary, data)
+}
+
+// renderInstructions renders the custom instructions template, or builtin
+// when there is none. It reports whether the custom template was used.
+func (t *Templates) renderInstructions(builtin *template.Template, data InstructionsData) (string, bool, error) {
+	if t == nil || t.Instructions == nil {
+		text, err := execute(builtin, data)
+		return text, false, err
+	}
+	text, err := execute(t.Instructions, data)
+	return text, true, err
+}
+
+func execute(tmpl *template.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template %s: %w", tmpl.Name(), err)
+	}
+	if strings.TrimSpace(buf.String()) == "" {
+		return "", fmt.Errorf("template %s rendered nothing", tmpl.Name())
+	}
+	return buf.String(), nil
+}
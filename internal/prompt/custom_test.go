@@ -0,0 +1,80 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		InstructionsTemplateFile: "# Apply {{.DocumentTitle}} to {{.SuggestedURL}}{{if .PageRefresh}} (refresh){{end}}\nUse Bootstrap.",
+		SummaryTemplateFile:      "Summarise {{.ChunkCount}} chunks of {{.DocumentTitle}}.",
+		"notes.md":               "not a template",
+	})
+
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+
+	engine := &Engine{Templates: templates}
+	content, err := engine.RenderChunk(PromptData{
+		DocumentTitle:   "Pricing",
+		SuggestedURL:    "example.com/pricing",
+		SuggestionsJSON: `[{"suggestions": []}]`,
+	})
+	if err != nil {
+		t.Fatalf("RenderChunk() error = %v", err)
+	}
+	if !strings.HasPrefix(content, "# Apply Pricing to example.com/pricing\nUse Bootstrap.") {
+		t.Errorf("RenderChunk() doesn't start with the custom instructions:\n%s", content)
+	}
+	if strings.Contains(content, "Vanilla") {
+		t.Error("RenderChunk() still includes the Vanilla Framework reference")
+	}
+	if !strings.Contains(content, `[{"suggestions": []}]`) {
+		t.Error("RenderChunk() is missing the suggestions data")
+	}
+
+	summary, err := templates.RenderSummary(SummaryData{DocumentTitle: "Pricing", ChunkCount: 3})
+	if err != nil {
+		t.Fatalf("RenderSummary() error = %v", err)
+	}
+	if summary != "Summarise 3 chunks of Pricing." {
+		t.Errorf("RenderSummary() = %q", summary)
+	}
+}
+
+func TestLoadTemplates_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"empty directory", nil, "no templates"},
+		{"unknown template", map[string]string{"instruction.md.tmpl": "x"}, "unknown template instruction.md.tmpl"},
+		{"syntax error", map[string]string{InstructionsTemplateFile: "{{.DocumentTitle"}, "failed to parse template"},
+		{"unknown field", map[string]string{SummaryTemplateFile: "{{.Chunks}}"}, "can't evaluate field Chunks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTemplates(writeTemplates(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadTemplates() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
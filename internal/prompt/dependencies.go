@@ -0,0 +1,156 @@
+package prompt
+
+import (
+	"cmp"
+	"strings"
+
+	"bauer/internal/gdocs"
+)
+
+// DependencyFile is the report of the location groups that were kept in the
+// same chunk, written next to the chunks when there are any.
+const DependencyFile = "chunk-dependencies.json"
+
+// minSharedAnchorLength is the shortest anchor text considered shared; shorter
+// text, e.g. a lone "Learn more", repeats without the locations interfering
+const minSharedAnchorLength = 12
+
+// DependencyReport is the graph of location groups whose anchors overlap.
+type DependencyReport struct {
+	Edges []DependencyEdge `json:"edges"`
+
+	// Components are the sets of locations kept in the same chunk, by location ID
+	Components [][]string `json:"components"`
+}
+
+// DependencyEdge links two locations with overlapping anchor text. Applied in
+// different chunks, the changes of one could be made at the other, or break
+// the anchors the other relies on.
+type DependencyEdge struct {
+	From           string `json:"from"`
+	To             string `json:"to"`
+	FromSuggestion string `json:"from_suggestion"`
+	ToSuggestion   string `json:"to_suggestion"`
+	Text           string `json:"text"`
+}
+
+// anchorText is the text a suggestion is found by: its anchors and the
+// text it changes.
+func anchorText(sugg gdocs.GroupedActionableSuggestion) string {
+	return strings.TrimSpace(sugg.Anchor.PrecedingText + sugg.Change.OriginalText + sugg.Anchor.FollowingText)
+}
+
+// AnalyzeDependencies finds the location groups whose suggestions share anchor
+// text, e.g. the same table repeated on a page: one anchor equal to or
+// contained in another. Metadata groups are independent of the page copy.
+// The locations of each component of the resulting graph must be applied in
+// the same chunk; the component of each group is returned, indexed like groups.
+func AnalyzeDependencies(groups []gdocs.LocationGroupedSuggestions) (*DependencyReport, []int) {
+	component := make([]int, len(groups))
+	for i := range component {
+		component[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if component[i] != i {
+			component[i] = find(component[i])
+		}
+		return component[i]
+	}
+
+	report := &DependencyReport{Edges: []DependencyEdge{}, Components: [][]string{}}
+	for i := range groups {
+		if groups[i].Location.InMetadata {
+			continue
+		}
+		for j := i + 1; j < len(groups); j++ {
+			if groups[j].Location.InMetadata {
+				continue
+			}
+			edge, ok := sharedAnchor(groups[i], groups[j])
+			if !ok {
+				continue
+			}
+			report.Edges = append(report.Edges, edge)
+			// The earliest group represents its component
+			a, b := find(i), find(j)
+			component[max(a, b)] = min(a, b)
+		}
+	}
+
+	members := make(map[int][]string)
+	for i := range groups {
+		component[i] = find(i)
+		members[component[i]] = append(members[component[i]], locationName(groups[i]))
+	}
+	for i := range groups {
+		if component[i] == i && len(members[i]) > 1 {
+			report.Components = append(report.Components, members[i])
+		}
+	}
+	return report, component
+}
+
+// sharedAnchor returns an edge between two groups when a suggestion of one
+// has anchor text equal to or containing that of a suggestion of the other.
+func sharedAnchor(a, b gdocs.LocationGroupedSuggestions) (DependencyEdge, bool) {
+	for _, sa := range a.Suggestions {
+		textA := anchorText(sa)
+		if len(textA) < minSharedAnchorLength {
+			continue
+		}
+		for _, sb := range b.Suggestions {
+			textB := anchorText(sb)
+			if len(textB) < minSharedAnchorLength {
+				continue
+			}
+			if strings.Contains(textA, textB) || strings.Contains(textB, textA) {
+				shared := textA
+				if len(textB) < len(textA) {
+					shared = textB
+				}
+				return DependencyEdge{
+					From:           locationName(a),
+					To:             locationName(b),
+					FromSuggestion: sa.ID,
+					ToSuggestion:   sb.ID,
+					Text:           shared,
+				}, true
+			}
+		}
+	}
+	return DependencyEdge{}, false
+}
+
+// locationName identifies a group in the report by its location ID, or by
+// its key when it has none.
+func locationName(group gdocs.LocationGroupedSuggestions) string {
+	return cmp.Or(group.LocationID, group.LocationKey)
+}
+
+// ChunkDependentLocations splits location groups into the desired number of
+// chunks like ChunkLocations, but keeps the groups of each component
+// together: they move up to the position of the component's first group.
+func ChunkDependentLocations(groups []gdocs.LocationGroupedSuggestions, component []int, desiredChunks int) [][]gdocs.LocationGroupedSuggestions {
+	var units [][]gdocs.LocationGroupedSuggestions
+	unitOf := make(map[int]int)
+	for i, group := range groups {
+		u, ok := unitOf[component[i]]
+		if !ok {
+			u = len(units)
+			unitOf[component[i]] = u
+			units = append(units, nil)
+		}
+		units[u] = append(units[u], group)
+	}
+
+	var chunks [][]gdocs.LocationGroupedSuggestions
+	for _, unitChunk := range chunkItems(units, desiredChunks) {
+		chunk := []gdocs.LocationGroupedSuggestions{}
+		for _, unit := range unitChunk {
+			chunk = append(chunk, unit...)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
@@ -0,0 +1,58 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"bauer/internal/gdocs"
+)
+
+func TestChunkDependentLocations(t *testing.T) {
+	group := func(id, preceding, original string) gdocs.LocationGroupedSuggestions {
+		return gdocs.LocationGroupedSuggestions{
+			LocationID: id,
+			Suggestions: []gdocs.GroupedActionableSuggestion{{
+				ID:     "s-" + id,
+				Anchor: gdocs.SuggestionAnchor{PrecedingText: preceding, FollowingText: " per month"},
+				Change: gdocs.SuggestionChange{Type: "replace", OriginalText: original, NewText: "$12"},
+			}},
+		}
+	}
+	groups := []gdocs.LocationGroupedSuggestions{
+		group("hero", "Ubuntu Pro costs ", "$10"),
+		group("intro", "Get started today with ", "Ubuntu"),
+		group("faq", "Plans start at ", "$25"),
+		// The pricing table repeated at the end of the page
+		group("table", "Pro costs ", "$10"),
+	}
+
+	report, components := AnalyzeDependencies(groups)
+	wantEdges := []DependencyEdge{{
+		From:           "hero",
+		To:             "table",
+		FromSuggestion: "s-hero",
+		ToSuggestion:   "s-table",
+		Text:           "Pro costs $10 per month",
+	}}
+	if diff := cmp.Diff(wantEdges, report.Edges); diff != "" {
+		t.Errorf("Edges mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([][]string{{"hero", "table"}}, report.Components); diff != "" {
+		t.Errorf("Components mismatch (-want +got):\n%s", diff)
+	}
+
+	var got [][]string
+	for _, chunk := range ChunkDependentLocations(groups, components, 2) {
+		var ids []string
+		for _, g := range chunk {
+			ids = append(ids, g.LocationID)
+		}
+		got = append(got, ids)
+	}
+	// Three units: hero and table count as one
+	want := [][]string{{"hero", "table", "intro"}, {"faq"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ChunkDependentLocations() mismatch (-want +got):\n%s", diff)
+	}
+}
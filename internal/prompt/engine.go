@@ -7,23 +7,45 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"text/template"
 
 	"bauer/internal/gdocs"
 )
 
 //go:embed templates/page-refresh-instructions.md
-var pageRefreshInstructionsTemplate string
+var pageRefreshInstructionsText string
 
 //go:embed templates/copy-docs-instructions.md
-var copyDocsInstructionsTemplate string
+var copyDocsInstructionsText string
+
+var (
+	pageRefreshInstructionsTemplate = template.Must(newTemplate("page-refresh-instructions.md", pageRefreshInstructionsText))
+	copyDocsInstructionsTemplate    = template.Must(newTemplate("copy-docs-instructions.md", copyDocsInstructionsText))
+)
 
 //go:embed templates/vanilla-patterns.md
 var vanillaPatterns string
 
+// chunkReport asks for the JSON report that ends every chunk's output. It is
+// appended to every prompt, including those with custom instructions.
+//
+//go:embed templates/chunk-report.md
+var chunkReport string
+
 // Engine handles prompt generation for Copilot
 type Engine struct {
 	// UsePageRefresh determines which instruction template to use
 	UsePageRefresh bool
+
+	// Order is the strategy used to order locations before chunking (default: position)
+	Order string
+
+	// Templates replace the built-in instructions when set
+	Templates *Templates
+
+	// Dependencies is the graph of locations kept in the same chunk by the
+	// last GenerateAllChunks
+	Dependencies *DependencyReport
 }
 
 // PromptData contains all data needed to render a complete prompt
@@ -39,7 +61,8 @@ type PromptData struct {
 	TotalChunks   int
 	LocationCount int
 
-	// Location-grouped suggestions for this chunk (raw JSON)
+	// Location-grouped suggestions for this chunk, and as raw JSON
+	Locations       []gdocs.LocationGroupedSuggestions
 	SuggestionsJSON string
 }
 
@@ -49,6 +72,15 @@ type ChunkResult struct {
 	Content       string
 	Filename      string
 	LocationCount int
+
+	// SuggestionIDs lists the suggestions covered by this chunk
+	SuggestionIDs []string
+
+	// LocationIDs lists the locations covered by this chunk
+	LocationIDs []string
+
+	// PageURL is the page the chunk's suggestions apply to
+	PageURL string
 }
 
 // NewEngine creates a new prompt engine
@@ -61,38 +93,43 @@ func NewEngine(usePageRefresh bool) (*Engine, error) {
 // ChunkLocations splits location groups into the desired number of chunks
 // chunkSize is the desired number of chunks to create, not locations per chunk
 func ChunkLocations(groups []gdocs.LocationGroupedSuggestions, desiredChunks int) [][]gdocs.LocationGroupedSuggestions {
+	return chunkItems(groups, desiredChunks)
+}
+
+// chunkItems splits items into the desired number of chunks, preserving order
+func chunkItems[T any](items []T, desiredChunks int) [][]T {
 	if desiredChunks <= 0 {
 		desiredChunks = 1
 	}
 
-	totalLocations := len(groups)
+	totalItems := len(items)
 
 	// Handle edge cases
-	if totalLocations == 0 {
-		return [][]gdocs.LocationGroupedSuggestions{{}}
+	if totalItems == 0 {
+		return [][]T{{}}
 	}
 
-	// If desired chunks is greater than or equal to total locations,
-	// create one chunk per location
-	if desiredChunks >= totalLocations {
-		var chunks [][]gdocs.LocationGroupedSuggestions
-		for _, group := range groups {
-			chunks = append(chunks, []gdocs.LocationGroupedSuggestions{group})
+	// If desired chunks is greater than or equal to total items,
+	// create one chunk per item
+	if desiredChunks >= totalItems {
+		var chunks [][]T
+		for _, item := range items {
+			chunks = append(chunks, []T{item})
 		}
 		return chunks
 	}
 
-	// Calculate locations per chunk (rounded up to ensure all locations are included)
-	locationsPerChunk := (totalLocations + desiredChunks - 1) / desiredChunks
+	// Calculate items per chunk (rounded up to ensure all items are included)
+	itemsPerChunk := (totalItems + desiredChunks - 1) / desiredChunks
 
-	var chunks [][]gdocs.LocationGroupedSuggestions
+	var chunks [][]T
 
-	for i := 0; i < totalLocations; i += locationsPerChunk {
-		end := i + locationsPerChunk
-		if end > totalLocations {
-			end = totalLocations
+	for i := 0; i < totalItems; i += itemsPerChunk {
+		end := i + itemsPerChunk
+		if end > totalItems {
+			end = totalItems
 		}
-		chunks = append(chunks, groups[i:end])
+		chunks = append(chunks, items[i:end])
 	}
 
 	return chunks
@@ -102,24 +139,32 @@ func ChunkLocations(groups []gdocs.LocationGroupedSuggestions, desiredChunks int
 func (e *Engine) RenderChunk(data PromptData) (string, error) {
 	var buf bytes.Buffer
 
-	// Write instructions with template variable substitution
-	// Select template based on page refresh mode
-	instructions := copyDocsInstructionsTemplate
+	// Select the instructions based on page refresh mode
+	builtin := copyDocsInstructionsTemplate
 	if e.UsePageRefresh {
-		instructions = pageRefreshInstructionsTemplate
+		builtin = pageRefreshInstructionsTemplate
+	}
+	instructions, custom, err := e.Templates.renderInstructions(builtin, InstructionsData{
+		DocumentTitle: data.DocumentTitle,
+		SuggestedURL:  data.SuggestedURL,
+		ChunkNumber:   data.ChunkNumber,
+		TotalChunks:   data.TotalChunks,
+		PageRefresh:   e.UsePageRefresh,
+		Locations:     data.Locations,
+	})
+	if err != nil {
+		return "", err
 	}
-	instructions = replaceVar(instructions, "DocumentTitle", data.DocumentTitle)
-	instructions = replaceVar(instructions, "SuggestedURL", data.SuggestedURL)
-	instructions = replaceVar(instructions, "ChunkNumber", fmt.Sprintf("%d", data.ChunkNumber))
-	instructions = replaceVar(instructions, "TotalChunks", fmt.Sprintf("%d", data.TotalChunks))
-
 	buf.WriteString(instructions)
 	buf.WriteString("\n\n")
 
-	// Append Vanilla patterns reference (before the data)
-	buf.WriteString("---\n\n")
-	buf.WriteString(vanillaPatterns)
-	buf.WriteString("\n\n")
+	// Append the Vanilla patterns reference, or hints of the patterns around
+	// the locations (before the data)
+	if !custom {
+		buf.WriteString("---\n\n")
+		buf.WriteString(patternReference(data.Locations))
+		buf.WriteString("\n\n")
+	}
 
 	// Write raw JSON suggestions (last, as the data to process)
 	buf.WriteString("---\n\n")
@@ -128,7 +173,8 @@ func (e *Engine) RenderChunk(data PromptData) (string, error) {
 	buf.WriteString("Process each location one by one, applying all suggestions for that location before moving to the next.\n\n")
 	buf.WriteString("```json\n")
 	buf.WriteString(data.SuggestionsJSON)
-	buf.WriteString("\n```\n")
+	buf.WriteString("\n```\n\n")
+	buf.WriteString(chunkReport)
 
 	return buf.String(), nil
 }
@@ -144,14 +190,33 @@ func (e *Engine) GenerateAllChunks(
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Chunk the location groups (simple slicing)
-	chunks := ChunkLocations(result.GroupedSuggestions, chunkSize)
+	// Order the location groups, then chunk each page's (simple slicing)
+	ordered, err := OrderLocations(result.GroupedSuggestions, e.Order)
+	if err != nil {
+		return nil, err
+	}
+	// Locations with overlapping anchors must be applied in the same chunk
+	var chunks [][]gdocs.LocationGroupedSuggestions
+	var chunkURLs []string
+	e.Dependencies = &DependencyReport{Edges: []DependencyEdge{}, Components: [][]string{}}
+	for _, page := range result.GroupsByPage(ordered) {
+		report, components := AnalyzeDependencies(page.Groups)
+		e.Dependencies.Edges = append(e.Dependencies.Edges, report.Edges...)
+		e.Dependencies.Components = append(e.Dependencies.Components, report.Components...)
+		for _, chunk := range ChunkDependentLocations(page.Groups, components, chunkSize) {
+			chunks = append(chunks, chunk)
+			chunkURLs = append(chunkURLs, page.URL)
+		}
+	}
 	totalChunks := len(chunks)
-
-	// Extract suggested URL from metadata
-	suggestedURL := ""
-	if result.Metadata != nil {
-		suggestedURL = result.Metadata.SuggestedUrl
+	if len(e.Dependencies.Edges) > 0 {
+		reportJSON, err := json.MarshalIndent(e.Dependencies, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal chunk dependencies: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, DependencyFile), reportJSON, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write chunk dependencies: %w", err)
+		}
 	}
 
 	var results []ChunkResult
@@ -159,6 +224,7 @@ func (e *Engine) GenerateAllChunks(
 	// Generate prompt for each chunk
 	for i, chunk := range chunks {
 		chunkNum := i + 1
+		suggestedURL := chunkURLs[i]
 
 		// Marshal chunk to JSON
 		chunkJSON, err := json.MarshalIndent(chunk, "", "  ")
@@ -173,6 +239,7 @@ func (e *Engine) GenerateAllChunks(
 			ChunkNumber:     chunkNum,
 			TotalChunks:     totalChunks,
 			LocationCount:   len(chunk),
+			Locations:       chunk,
 			SuggestionsJSON: string(chunkJSON),
 		}
 
@@ -196,36 +263,37 @@ func (e *Engine) GenerateAllChunks(
 			Content:       content,
 			Filename:      filepath,
 			LocationCount: len(chunk),
+			SuggestionIDs: suggestionIDs(chunk),
+			LocationIDs:   locationIDs(chunk),
+			PageURL:       suggestedURL,
 		})
 	}
 
 	return results, nil
 }
 
-// replaceVar is a simple string replacement helper for template variables
-func replaceVar(template, key, value string) string {
-	placeholder := "{{." + key + "}}"
-	var result bytes.Buffer
-
-	for {
-		idx := indexOf(template, placeholder)
-		if idx == -1 {
-			result.WriteString(template)
-			break
-		}
-		result.WriteString(template[:idx])
-		result.WriteString(value)
-		template = template[idx+len(placeholder):]
+// locationIDs returns the IDs of the given locations, in order
+func locationIDs(groups []gdocs.LocationGroupedSuggestions) []string {
+	ids := make([]string, len(groups))
+	for i, group := range groups {
+		ids[i] = group.LocationID
 	}
-	return result.String()
+	return ids
 }
 
-// indexOf finds the index of a substring
-func indexOf(s, substr string) int {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return i
+// suggestionIDs returns the unique suggestion IDs of the given locations, in order
+func suggestionIDs(groups []gdocs.LocationGroupedSuggestions) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, group := range groups {
+		for _, sugg := range group.Suggestions {
+			for _, id := range sugg.SuggestionIDs() {
+				if !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
 		}
 	}
-	return -1
+	return ids
 }
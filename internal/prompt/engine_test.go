@@ -2,6 +2,7 @@ package prompt
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"bauer/internal/gdocs"
@@ -272,6 +273,38 @@ func TestRenderChunk(t *testing.T) {
 	}
 }
 
+func TestRenderChunk_PatternHints(t *testing.T) {
+	engine, err := NewEngine(false)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	content, err := engine.RenderChunk(PromptData{
+		DocumentTitle: "Test Document",
+		ChunkNumber:   1,
+		TotalChunks:   1,
+		Locations: []gdocs.LocationGroupedSuggestions{
+			{LocationID: "loc-1", ResolvedFile: "templates/index.html", Patterns: []string{"hero"}},
+			{LocationID: "loc-2", ResolvedFile: "templates/index.html"},
+		},
+		SuggestionsJSON: `[]`,
+	})
+	if err != nil {
+		t.Fatalf("RenderChunk() failed: %v", err)
+	}
+
+	for _, expected := range []string{"# Vanilla pattern hints", "- Location `loc-1` (templates/index.html): hero", "## Hero pattern"} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Rendered content missing expected string: %q", expected)
+		}
+	}
+	for _, unexpected := range []string{"## Equal heights", "Table of contents"} {
+		if strings.Contains(content, unexpected) {
+			t.Errorf("Rendered content has unexpected string: %q", unexpected)
+		}
+	}
+}
+
 func TestRenderChunkWithPageRefresh(t *testing.T) {
 	// Test with PageRefresh enabled
 	engine, err := NewEngine(true)
@@ -402,47 +435,92 @@ func TestGenerateAllChunks(t *testing.T) {
 	}
 }
 
-func TestReplaceVar(t *testing.T) {
+func TestGenerateAllChunks_Pages(t *testing.T) {
+	engine, err := NewEngine(false)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	result := &gdocs.ProcessingResult{
+		DocumentTitle: "Cloud pages",
+		Metadata:      &gdocs.MetadataTable{SuggestedUrl: "ubuntu.com/cloud"},
+		GroupedSuggestions: []gdocs.LocationGroupedSuggestions{
+			{Location: gdocs.SuggestionLocation{Section: "Body"}, Suggestions: makeTestSuggestions(1)},
+			{Location: gdocs.SuggestionLocation{Section: "Body", PageURL: "ubuntu.com/aws"}, Suggestions: makeTestSuggestions(2)},
+			{Location: gdocs.SuggestionLocation{Section: "Body", PageURL: "ubuntu.com/aws"}, Suggestions: makeTestSuggestions(1)},
+		},
+	}
+
+	// One chunk per page, even when a single chunk is requested
+	chunks, err := engine.GenerateAllChunks(result, 1, t.TempDir())
+	if err != nil {
+		t.Fatalf("GenerateAllChunks() failed: %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 chunks, got %d", len(chunks))
+	}
+	for i, want := range []struct {
+		url       string
+		locations int
+	}{{"ubuntu.com/cloud", 1}, {"ubuntu.com/aws", 2}} {
+		if chunks[i].PageURL != want.url || chunks[i].LocationCount != want.locations {
+			t.Errorf("chunk %d: page %q with %d locations, want %q with %d", i+1, chunks[i].PageURL, chunks[i].LocationCount, want.url, want.locations)
+		}
+		if !strings.Contains(chunks[i].Content, "**"+want.url+"**") {
+			t.Errorf("chunk %d: prompt doesn't target %s", i+1, want.url)
+		}
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
 	tests := []struct {
 		name     string
 		template string
-		key      string
-		value    string
+		data     any
 		expected string
 	}{
 		{
-			name:     "single replacement",
-			template: "Hello {{.Name}}!",
-			key:      "Name",
-			value:    "World",
-			expected: "Hello World!",
+			name:     "truncate",
+			template: "{{.Title | truncate 8}}",
+			data:     map[string]string{"Title": "Ubuntu on AWS"},
+			expected: "Ubuntu …",
 		},
 		{
-			name:     "multiple replacements",
-			template: "{{.Greeting}} {{.Name}}, {{.Greeting}} again!",
-			key:      "Greeting",
-			value:    "Hi",
-			expected: "Hi {{.Name}}, Hi again!",
+			name:     "truncate short text",
+			template: "{{.Title | truncate 80}}",
+			data:     map[string]string{"Title": "Ubuntu"},
+			expected: "Ubuntu",
 		},
 		{
-			name:     "no replacement",
-			template: "Hello World",
-			key:      "Name",
-			value:    "Test",
-			expected: "Hello World",
+			name:     "escape markdown",
+			template: "{{.Title | escapeMarkdown}}",
+			data:     map[string]string{"Title": "*New* [beta] <release>"},
+			expected: `\*New\* \[beta\] \<release\>`,
 		},
 		{
-			name:     "empty value",
-			template: "Value: {{.Value}}",
-			key:      "Value",
-			value:    "",
-			expected: "Value: ",
+			name:     "json",
+			template: "{{jsonIndent .}}",
+			data:     map[string]int{"chunks": 2},
+			expected: "{\n  \"chunks\": 2\n}",
+		},
+		{
+			name:     "loop and conditional",
+			template: "{{range .}}{{if .}}{{.}} {{end}}{{end}}",
+			data:     []string{"a", "", "b"},
+			expected: "a b ",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := replaceVar(tt.template, tt.key, tt.value)
+			tmpl, err := newTemplate(tt.name, tt.template)
+			if err != nil {
+				t.Fatalf("newTemplate() error = %v", err)
+			}
+			result, err := execute(tmpl, tt.data)
+			if err != nil {
+				t.Fatalf("execute() error = %v", err)
+			}
 			if result != tt.expected {
 				t.Errorf("Expected %q, got %q", tt.expected, result)
 			}
@@ -450,6 +528,16 @@ func TestReplaceVar(t *testing.T) {
 	}
 }
 
+func TestTemplateMissingVariable(t *testing.T) {
+	tmpl, err := newTemplate("missing", "Document: {{.DocumentTitle}}")
+	if err != nil {
+		t.Fatalf("newTemplate() error = %v", err)
+	}
+	if _, err := execute(tmpl, map[string]string{"Title": "Ubuntu"}); err == nil {
+		t.Error("execute() with a missing variable succeeded")
+	}
+}
+
 // Helper functions
 
 func makeTestSuggestions(count int) []gdocs.GroupedActionableSuggestion {
@@ -487,3 +575,89 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestOrderLocations(t *testing.T) {
+	simple := gdocs.LocationGroupedSuggestions{
+		Location: gdocs.SuggestionLocation{Section: "Body", ParentHeading: "Simple"},
+		Suggestions: []gdocs.GroupedActionableSuggestion{
+			{ID: "s1", AtomicCount: 1, Change: gdocs.SuggestionChange{Type: "insert", NewText: "a much longer inserted sentence"}},
+		},
+	}
+	complexGroup := gdocs.LocationGroupedSuggestions{
+		Location: gdocs.SuggestionLocation{Section: "Body", ParentHeading: "Complex", InTable: true},
+		Suggestions: []gdocs.GroupedActionableSuggestion{
+			{ID: "c1", AtomicCount: 3, Change: gdocs.SuggestionChange{Type: "replace", OriginalText: "Old", NewText: "New"}},
+		},
+	}
+	groups := []gdocs.LocationGroupedSuggestions{complexGroup, simple}
+
+	tests := []struct {
+		strategy  string
+		wantFirst string
+		wantErr   bool
+	}{
+		{strategy: "", wantFirst: "Complex"},
+		{strategy: OrderByPosition, wantFirst: "Complex"},
+		{strategy: OrderByDifficulty, wantFirst: "Simple"},
+		{strategy: OrderByChurn, wantFirst: "Complex"},
+		{strategy: "random", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			ordered, err := OrderLocations(groups, tt.strategy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OrderLocations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if ordered[0].Location.ParentHeading != tt.wantFirst {
+				t.Errorf("First location = %s, want %s", ordered[0].Location.ParentHeading, tt.wantFirst)
+			}
+			if groups[0].Location.ParentHeading != "Complex" {
+				t.Error("OrderLocations() modified its input")
+			}
+		})
+	}
+}
+
+func TestGenerateSectionChunks(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine, _ := NewEngine(true)
+
+	result := &gdocs.ProcessingResult{
+		DocumentTitle: "Refresh Doc",
+		Metadata:      &gdocs.MetadataTable{SuggestedUrl: "ubuntu.com/aws"},
+		Sections: []gdocs.DocumentSection{
+			{ID: "section-1", Heading: "Hero", Markdown: "# Hero\n\nUbuntu on AWS", SuggestionIDs: []string{"s1"}},
+			{ID: "section-2", Heading: "Features", Markdown: "## Features\n\n- Fast"},
+			{ID: "section-3", Heading: "Pricing", Markdown: "## Pricing"},
+		},
+	}
+
+	chunks, err := engine.GenerateSectionChunks(result, 2, tmpDir)
+	if err != nil {
+		t.Fatalf("GenerateSectionChunks() failed: %v", err)
+	}
+
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 chunks, got %d", len(chunks))
+	}
+	if chunks[0].LocationCount != 2 || chunks[1].LocationCount != 1 {
+		t.Errorf("Unexpected section counts: %d, %d", chunks[0].LocationCount, chunks[1].LocationCount)
+	}
+
+	content := chunks[0].Content
+	for _, want := range []string{"Section Rebuild", "ubuntu.com/aws", "Chunk 1 of 2", "## section-1: Hero", "Ubuntu on AWS", "Reviewed changes in this section: s1"} {
+		if !contains(content, want) {
+			t.Errorf("Chunk content missing %q", want)
+		}
+	}
+	if contains(content, "{{.") {
+		t.Error("Chunk content has unsubstituted template variables")
+	}
+	if _, err := os.Stat(chunks[1].Filename); err != nil {
+		t.Errorf("Chunk file not written: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// templateFuncs are the helpers available to prompt templates, built-in and custom.
+var templateFuncs = template.FuncMap{
+	"truncate":       truncate,
+	"jsonIndent":     jsonIndent,
+	"escapeMarkdown": escapeMarkdown,
+}
+
+// newTemplate parses a prompt template. Executing it fails on missing
+// variables rather than leaving placeholders in the prompt.
+func newTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+}
+
+// truncate shortens s to at most n characters, ending in "…" when cut,
+// e.g. {{.DocumentTitle | truncate 40}}.
+func truncate(n int, s string) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
+
+// jsonIndent renders v as indented JSON, e.g. {{jsonIndent .Locations}}.
+func jsonIndent(v any) (string, error) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return string(out), nil
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`,
+)
+
+// escapeMarkdown escapes the characters of s that Markdown would format, so
+// document text such as titles is shown as written.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,125 @@
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultMaxChunkTokens is the estimated size over which a chunk is flagged:
+// sessions with larger prompts tend to time out or skip locations.
+const DefaultMaxChunkTokens = 60000
+
+// charsPerToken is the rough number of characters of a token, as estimated
+// for the usage of executors without one.
+const charsPerToken = 4
+
+// Severities of lint issues. Errors stop the run before any chunk is executed.
+const (
+	LintWarning = "warning"
+	LintError   = "error"
+)
+
+// LintIssue is a problem of a generated chunk.
+type LintIssue struct {
+	Chunk    int    `json:"chunk"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Data headings of the chunks: suggestions for copy updates, sections for
+// page refreshes.
+const (
+	suggestionsHeading = "# Suggestions Data"
+	sectionsHeading    = "# Sections Data"
+)
+
+var (
+	jsonBlockPattern = regexp.MustCompile("(?s)```json\n(.*?)\n```")
+
+	// templateLeftovers are what Go templates leave of the values they
+	// couldn't substitute
+	templateLeftovers = regexp.MustCompile(`<no value>|\{\{-?\s*\.[A-Za-z]`)
+)
+
+// LintChunks checks each chunk before it's sent to an executor: its data must
+// parse and have suggestions, or sections, its instructions must have every
+// template variable substituted, and its estimated size should be under
+// maxTokens (DefaultMaxChunkTokens when 0).
+func LintChunks(chunks []ChunkResult, maxTokens int) []LintIssue {
+	if maxTokens == 0 {
+		maxTokens = DefaultMaxChunkTokens
+	}
+	issues := []LintIssue{}
+	for _, chunk := range chunks {
+		for _, issue := range lintChunk(chunk, maxTokens) {
+			issue.Chunk = chunk.ChunkNumber
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func lintChunk(chunk ChunkResult, maxTokens int) []LintIssue {
+	var issues []LintIssue
+	add := func(severity, format string, args ...any) {
+		issues = append(issues, LintIssue{Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// The instructions come before the data, which may quote templates
+	instructions, data, found := strings.Cut(chunk.Content, suggestionsHeading)
+	switch {
+	case found:
+		match := jsonBlockPattern.FindStringSubmatch(data)
+		if match == nil {
+			add(LintError, "no JSON block of suggestions")
+			break
+		}
+		var locations []struct {
+			Suggestions []json.RawMessage `json:"suggestions"`
+		}
+		if err := json.Unmarshal([]byte(match[1]), &locations); err != nil {
+			add(LintError, "suggestions JSON doesn't parse: %v", err)
+			break
+		}
+		count := 0
+		for _, location := range locations {
+			count += len(location.Suggestions)
+		}
+		if count == 0 {
+			add(LintError, "no suggestions")
+		}
+	default:
+		instructions, _, found = strings.Cut(chunk.Content, sectionsHeading)
+		if !found {
+			add(LintError, "no suggestions or sections data")
+		} else if chunk.LocationCount == 0 {
+			add(LintError, "no sections")
+		}
+	}
+
+	if leftover := templateLeftovers.FindString(instructions); leftover != "" {
+		add(LintError, "template variable not substituted in the instructions: %q", leftover)
+	}
+	if tokens := EstimateTokens(chunk.Content); tokens > maxTokens {
+		add(LintWarning, "estimated %d tokens, over %d; use a larger --chunk-size to split it", tokens, maxTokens)
+	}
+	return issues
+}
+
+// EstimateTokens estimates the number of tokens of a prompt.
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// LintErrors returns the issues of severity LintError.
+func LintErrors(issues []LintIssue) []LintIssue {
+	var errs []LintIssue
+	for _, issue := range issues {
+		if issue.Severity == LintError {
+			errs = append(errs, issue)
+		}
+	}
+	return errs
+}
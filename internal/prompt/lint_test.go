@@ -0,0 +1,81 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"bauer/internal/gdocs"
+)
+
+func TestLintChunks(t *testing.T) {
+	engine, err := NewEngine(false)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	result := &gdocs.ProcessingResult{
+		DocumentTitle: "Test Document",
+		GroupedSuggestions: []gdocs.LocationGroupedSuggestions{
+			{LocationID: "loc-1", Suggestions: makeTestSuggestions(2)},
+		},
+	}
+	chunks, err := engine.GenerateAllChunks(result, 1, t.TempDir())
+	if err != nil {
+		t.Fatalf("GenerateAllChunks() error = %v", err)
+	}
+	valid := chunks[0]
+	if issues := LintChunks(chunks, 0); len(issues) != 0 {
+		t.Fatalf("LintChunks() of a valid chunk = %v", issues)
+	}
+
+	broken := func(content string) ChunkResult {
+		chunk := valid
+		chunk.Content = content
+		return chunk
+	}
+	dataStart := strings.Index(valid.Content, suggestionsHeading)
+	tests := []struct {
+		name     string
+		chunk    ChunkResult
+		max      int
+		severity string
+		message  string
+	}{
+		{
+			name:     "truncated JSON",
+			chunk:    broken(strings.Replace(valid.Content, "\n]\n```", "\n```", 1)),
+			severity: LintError,
+			message:  "doesn't parse",
+		},
+		{
+			name:     "no suggestions",
+			chunk:    broken(valid.Content[:dataStart] + suggestionsHeading + "\n\n```json\n[{\"suggestions\": []}]\n```\n"),
+			severity: LintError,
+			message:  "no suggestions",
+		},
+		{
+			name:     "template leftover",
+			chunk:    broken("Apply the suggestions of <no value>.\n\n" + valid.Content[dataStart:]),
+			severity: LintError,
+			message:  "not substituted",
+		},
+		{
+			name:     "too large",
+			chunk:    valid,
+			max:      10,
+			severity: LintWarning,
+			message:  "over 10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := LintChunks([]ChunkResult{tt.chunk}, tt.max)
+			if len(issues) != 1 {
+				t.Fatalf("LintChunks() = %v, want one issue", issues)
+			}
+			if issues[0].Severity != tt.severity || !strings.Contains(issues[0].Message, tt.message) || issues[0].Chunk != 1 {
+				t.Errorf("LintChunks() = %+v, want %s containing %q", issues[0], tt.severity, tt.message)
+			}
+		})
+	}
+}
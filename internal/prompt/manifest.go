@@ -0,0 +1,145 @@
+package prompt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ManifestFile is the name of the chunk manifest written next to the chunks.
+const ManifestFile = "chunks-manifest.json"
+
+// ChunkStatus is how far the execution of a chunk got.
+type ChunkStatus string
+
+const (
+	ChunkPending   ChunkStatus = "pending"
+	ChunkRunning   ChunkStatus = "running"
+	ChunkCompleted ChunkStatus = "completed"
+	ChunkFailed    ChunkStatus = "failed"
+)
+
+// Manifest records the chunks of a run and how their execution went, for
+// auditing and resuming runs.
+type Manifest struct {
+	DocumentID string           `json:"document_id"`
+	CreatedAt  time.Time        `json:"created_at"`
+	Chunks     []*ManifestChunk `json:"chunks"`
+}
+
+// ManifestChunk is the record of a single chunk.
+type ManifestChunk struct {
+	ChunkNumber   int      `json:"chunk_number"`
+	Filename      string   `json:"filename"`
+	SHA256        string   `json:"sha256"`
+	PageURL       string   `json:"page_url,omitempty"`
+	Locations     []string `json:"locations"`
+	SuggestionIDs []string `json:"suggestion_ids"`
+
+	Status      ChunkStatus `json:"status"`
+	Error       string      `json:"error,omitempty"`
+	Output      string      `json:"output,omitempty"`
+	StartedAt   *time.Time  `json:"started_at,omitempty"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty"`
+}
+
+// NewManifest records chunks as pending.
+func NewManifest(documentID string, chunks []ChunkResult) *Manifest {
+	m := &Manifest{
+		DocumentID: documentID,
+		CreatedAt:  time.Now().UTC(),
+		Chunks:     []*ManifestChunk{},
+	}
+	for _, chunk := range chunks {
+		sum := sha256.Sum256([]byte(chunk.Content))
+		m.Chunks = append(m.Chunks, &ManifestChunk{
+			ChunkNumber:   chunk.ChunkNumber,
+			Filename:      chunk.Filename,
+			SHA256:        hex.EncodeToString(sum[:]),
+			PageURL:       chunk.PageURL,
+			Locations:     append([]string{}, chunk.LocationIDs...),
+			SuggestionIDs: append([]string{}, chunk.SuggestionIDs...),
+			Status:        ChunkPending,
+		})
+	}
+	return m
+}
+
+// LoadManifest reads a manifest written by Save.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk manifest: %w", err)
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse chunk manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Save writes the manifest as indented JSON.
+func (m *Manifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal chunk manifest: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write chunk manifest: %w", err)
+	}
+	return nil
+}
+
+// Get returns the record of a chunk, or nil if there is none.
+func (m *Manifest) Get(chunkNumber int) *ManifestChunk {
+	for _, chunk := range m.Chunks {
+		if chunk.ChunkNumber == chunkNumber {
+			return chunk
+		}
+	}
+	return nil
+}
+
+// Resume carries over the chunks a previous run of the same document
+// completed, with their output. Chunks whose prompt changed since, e.g.
+// because the document was edited, stay pending. It returns the number of
+// chunks carried over.
+func (m *Manifest) Resume(previous *Manifest) int {
+	if previous == nil || previous.DocumentID != m.DocumentID {
+		return 0
+	}
+	resumed := 0
+	for _, chunk := range m.Chunks {
+		prev := previous.Get(chunk.ChunkNumber)
+		if prev == nil || prev.Status != ChunkCompleted || prev.Filename != chunk.Filename || prev.SHA256 != chunk.SHA256 {
+			continue
+		}
+		chunk.Status = ChunkCompleted
+		chunk.Output = prev.Output
+		chunk.StartedAt = prev.StartedAt
+		chunk.CompletedAt = prev.CompletedAt
+		resumed++
+	}
+	return resumed
+}
+
+// SetStatus records the status of a chunk; errMsg is kept for failed chunks.
+func (m *Manifest) SetStatus(chunkNumber int, status ChunkStatus, errMsg string) {
+	chunk := m.Get(chunkNumber)
+	if chunk == nil {
+		return
+	}
+	now := time.Now().UTC()
+	chunk.Status = status
+	chunk.Error = errMsg
+	switch status {
+	case ChunkRunning:
+		chunk.StartedAt = &now
+		chunk.CompletedAt = nil
+	case ChunkCompleted, ChunkFailed:
+		chunk.CompletedAt = &now
+	}
+}
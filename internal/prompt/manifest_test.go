@@ -0,0 +1,93 @@
+package prompt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestManifest(t *testing.T) {
+	chunks := []ChunkResult{
+		{ChunkNumber: 1, Filename: "chunk-1.md", Content: "first", LocationIDs: []string{"loc-1"}, SuggestionIDs: []string{"suggest.a"}},
+		{ChunkNumber: 2, Filename: "chunk-2.md", Content: "second", PageURL: "ubuntu.com/aws", LocationIDs: []string{"loc-2", "loc-3"}, SuggestionIDs: []string{"suggest.b", "suggest.c"}},
+	}
+	m := NewManifest("doc-1", chunks)
+
+	sum := sha256.Sum256([]byte("second"))
+	want := &ManifestChunk{
+		ChunkNumber:   2,
+		Filename:      "chunk-2.md",
+		SHA256:        hex.EncodeToString(sum[:]),
+		PageURL:       "ubuntu.com/aws",
+		Locations:     []string{"loc-2", "loc-3"},
+		SuggestionIDs: []string{"suggest.b", "suggest.c"},
+		Status:        ChunkPending,
+	}
+	if diff := cmp.Diff(want, m.Get(2)); diff != "" {
+		t.Errorf("Get(2) mismatch (-want +got):\n%s", diff)
+	}
+
+	m.SetStatus(1, ChunkRunning, "")
+	m.SetStatus(1, ChunkCompleted, "")
+	m.SetStatus(2, ChunkRunning, "")
+	m.SetStatus(2, ChunkFailed, "session timed out")
+	m.SetStatus(3, ChunkCompleted, "")
+
+	path := filepath.Join(t.TempDir(), ManifestFile)
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	loaded, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest() failed: %v", err)
+	}
+
+	first, second := loaded.Get(1), loaded.Get(2)
+	if first.Status != ChunkCompleted || first.StartedAt == nil || first.CompletedAt == nil {
+		t.Errorf("Unexpected first chunk: %+v", first)
+	}
+	if second.Status != ChunkFailed || second.Error != "session timed out" {
+		t.Errorf("Unexpected second chunk: %+v", second)
+	}
+	if len(loaded.Chunks) != 2 {
+		t.Errorf("Expected 2 chunks, got %d", len(loaded.Chunks))
+	}
+}
+
+func TestManifest_Resume(t *testing.T) {
+	chunks := []ChunkResult{
+		{ChunkNumber: 1, Filename: "chunk-1.md", Content: "first"},
+		{ChunkNumber: 2, Filename: "chunk-2.md", Content: "second"},
+		{ChunkNumber: 3, Filename: "chunk-3.md", Content: "third"},
+	}
+	previous := NewManifest("doc-1", chunks)
+	previous.SetStatus(1, ChunkCompleted, "")
+	previous.Get(1).Output = "STATUS suggest.a applied"
+	previous.SetStatus(2, ChunkFailed, "session timed out")
+	previous.SetStatus(3, ChunkCompleted, "")
+
+	// The third chunk's prompt changed since the previous run
+	chunks[2].Content = "third, edited"
+	m := NewManifest("doc-1", chunks)
+	if got := m.Resume(previous); got != 1 {
+		t.Errorf("Resume() = %d, want 1", got)
+	}
+
+	var statuses []ChunkStatus
+	for _, chunk := range m.Chunks {
+		statuses = append(statuses, chunk.Status)
+	}
+	if diff := cmp.Diff([]ChunkStatus{ChunkCompleted, ChunkPending, ChunkPending}, statuses); diff != "" {
+		t.Errorf("Statuses mismatch (-want +got):\n%s", diff)
+	}
+	if got := m.Get(1).Output; got != "STATUS suggest.a applied" {
+		t.Errorf("Output = %q", got)
+	}
+
+	if got := NewManifest("doc-2", chunks).Resume(previous); got != 0 {
+		t.Errorf("Resume() of another document = %d, want 0", got)
+	}
+}
@@ -0,0 +1,109 @@
+package prompt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"bauer/internal/gdocs"
+)
+
+// Chunk ordering strategies
+const (
+	// OrderByPosition keeps locations in document order (default)
+	OrderByPosition = "position"
+	// OrderByDifficulty puts structurally simple locations first
+	OrderByDifficulty = "difficulty"
+	// OrderByChurn puts locations that change the least text first
+	OrderByChurn = "churn"
+)
+
+// ValidateOrderStrategy checks that strategy is a known ordering strategy.
+// An empty strategy is valid and means OrderByPosition.
+func ValidateOrderStrategy(strategy string) error {
+	switch strategy {
+	case "", OrderByPosition, OrderByDifficulty, OrderByChurn:
+		return nil
+	default:
+		return fmt.Errorf("unknown chunk order %q (expected %s, %s or %s)", strategy, OrderByPosition, OrderByDifficulty, OrderByChurn)
+	}
+}
+
+// OrderLocations returns the location groups sorted by the given strategy.
+// Low-risk locations come first so they land in the earliest chunks; ties keep document order.
+// The input slice is not modified.
+func OrderLocations(groups []gdocs.LocationGroupedSuggestions, strategy string) ([]gdocs.LocationGroupedSuggestions, error) {
+	if err := ValidateOrderStrategy(strategy); err != nil {
+		return nil, err
+	}
+
+	ordered := make([]gdocs.LocationGroupedSuggestions, len(groups))
+	copy(ordered, groups)
+
+	var score func(gdocs.LocationGroupedSuggestions) int
+	switch strategy {
+	case OrderByDifficulty:
+		score = estimateDifficulty
+	case OrderByChurn:
+		score = estimateChurn
+	default:
+		return ordered, nil
+	}
+
+	scores := make([]int, len(ordered))
+	for i := range ordered {
+		scores[i] = score(ordered[i])
+	}
+	indices := make([]int, len(ordered))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(a, b int) bool {
+		return scores[indices[a]] < scores[indices[b]]
+	})
+
+	result := make([]gdocs.LocationGroupedSuggestions, len(ordered))
+	for i, idx := range indices {
+		result[i] = ordered[idx]
+	}
+	return result, nil
+}
+
+// estimateDifficulty scores how structurally complex a location is to apply.
+// Multi-part replacements, table cells, metadata mapping and paragraph-spanning
+// changes all need more reasoning from the model than a simple word swap.
+func estimateDifficulty(group gdocs.LocationGroupedSuggestions) int {
+	score := 0
+	if group.Location.InTable {
+		score += 2
+	}
+	if group.Location.InMetadata {
+		score += 3
+	}
+
+	for _, sugg := range group.Suggestions {
+		score++
+		score += sugg.AtomicCount - 1
+		if sugg.Change.Type == "replace" {
+			score++
+		}
+		if sugg.Change.Type == "move" {
+			// Two places to edit, possibly in different sections
+			score += 2
+		}
+		if strings.Contains(sugg.Change.OriginalText, "\n") || strings.Contains(sugg.Change.NewText, "\n") {
+			score += 3
+		}
+	}
+
+	return score
+}
+
+// estimateChurn approximates how much of the target file a location rewrites.
+func estimateChurn(group gdocs.LocationGroupedSuggestions) int {
+	churn := 0
+	for _, sugg := range group.Suggestions {
+		churn += len(sugg.Change.OriginalText) + len(sugg.Change.NewText)
+	}
+	return churn
+}
@@ -0,0 +1,56 @@
+package prompt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"bauer/internal/gdocs"
+	"bauer/internal/vanilla"
+)
+
+// patternReference returns the part of the Vanilla patterns reference a chunk
+// needs. When the patterns around its locations were detected, it's a hint
+// per location and the sections of those patterns, otherwise the whole
+// reference.
+func patternReference(locations []gdocs.LocationGroupedSuggestions) string {
+	var hints strings.Builder
+	var names []string
+	for _, loc := range locations {
+		if len(loc.Patterns) == 0 {
+			continue
+		}
+		file := loc.ResolvedFile
+		if file == "" {
+			file = "unknown file"
+		}
+		fmt.Fprintf(&hints, "- Location `%s` (%s): %s\n", loc.LocationID, file, strings.Join(loc.Patterns, ", "))
+		for _, name := range loc.Patterns {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return vanillaPatterns
+	}
+
+	var buf strings.Builder
+	buf.WriteString("# Vanilla pattern hints\n\n")
+	buf.WriteString("These locations were found inside the following Vanilla patterns of their files. Keep edits within the structure of these patterns; locations not listed are outside any known pattern.\n\n")
+	buf.WriteString(hints.String())
+	buf.WriteString("\n# Vanilla Framework Patterns Reference\n\n")
+	buf.WriteString("The reference of the patterns above, from the full Vanilla patterns reference.\n")
+	for _, section := range strings.Split(vanillaPatterns, "\n## ")[1:] {
+		heading, _, _ := strings.Cut(section, "\n")
+		for _, name := range names {
+			if p := vanilla.Lookup(name); p != nil && p.Heading == heading {
+				buf.WriteString("\n## ")
+				buf.WriteString(strings.TrimSuffix(strings.TrimSpace(section), "---"))
+				buf.WriteString("\n")
+				break
+			}
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,157 @@
+package prompt
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"bauer/internal/gdocs"
+)
+
+//go:embed templates/section-refresh-instructions.md
+var sectionRefreshInstructionsText string
+
+var sectionRefreshInstructionsTemplate = template.Must(newTemplate("section-refresh-instructions.md", sectionRefreshInstructionsText))
+
+// SectionPromptData contains all data needed to render a section refresh prompt
+type SectionPromptData struct {
+	DocumentTitle string
+	SuggestedURL  string
+	ChunkNumber   int
+	TotalChunks   int
+
+	// Sections to rebuild in this chunk
+	Sections []gdocs.DocumentSection
+}
+
+// ChunkSections splits document sections into the desired number of chunks
+func ChunkSections(sections []gdocs.DocumentSection, desiredChunks int) [][]gdocs.DocumentSection {
+	return chunkItems(sections, desiredChunks)
+}
+
+// RenderSectionChunk generates a rebuild-this-section prompt for a single chunk
+func (e *Engine) RenderSectionChunk(data SectionPromptData) (string, error) {
+	var buf bytes.Buffer
+
+	instructions, custom, err := e.Templates.renderInstructions(sectionRefreshInstructionsTemplate, InstructionsData{
+		DocumentTitle: data.DocumentTitle,
+		SuggestedURL:  data.SuggestedURL,
+		ChunkNumber:   data.ChunkNumber,
+		TotalChunks:   data.TotalChunks,
+		PageRefresh:   true,
+		Sections:      data.Sections,
+	})
+	if err != nil {
+		return "", err
+	}
+	buf.WriteString(instructions)
+	buf.WriteString("\n\n")
+
+	if !custom {
+		buf.WriteString("---\n\n")
+		buf.WriteString(vanillaPatterns)
+		buf.WriteString("\n\n")
+	}
+
+	// Sections come last, as the data to process
+	buf.WriteString("---\n\n")
+	buf.WriteString("# Sections Data\n\n")
+	buf.WriteString("Each section below is the final content for that part of the page. Rebuild the sections one by one, in order.\n\n")
+
+	for _, section := range data.Sections {
+		heading := section.Heading
+		if heading == "" {
+			heading = "(content before the first heading)"
+		}
+		fmt.Fprintf(&buf, "## %s: %s\n\n", section.ID, heading)
+		if len(section.SuggestionIDs) > 0 {
+			fmt.Fprintf(&buf, "Reviewed changes in this section: %s\n\n", strings.Join(section.SuggestionIDs, ", "))
+		} else {
+			buf.WriteString("No reviewed changes in this section; make sure the page still matches it.\n\n")
+		}
+		buf.WriteString("````markdown\n")
+		buf.WriteString(section.Markdown)
+		buf.WriteString("\n````\n\n")
+	}
+
+	buf.WriteString(chunkReport)
+
+	return buf.String(), nil
+}
+
+// GenerateSectionChunks creates rebuild-this-section prompts for page refresh mode
+// and saves them to files
+func (e *Engine) GenerateSectionChunks(
+	result *gdocs.ProcessingResult,
+	chunkSize int,
+	outputDir string,
+) ([]ChunkResult, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	chunks := ChunkSections(result.Sections, chunkSize)
+	totalChunks := len(chunks)
+
+	suggestedURL := ""
+	if result.Metadata != nil {
+		suggestedURL = result.Metadata.SuggestedUrl
+	}
+
+	var results []ChunkResult
+
+	for i, chunk := range chunks {
+		chunkNum := i + 1
+
+		content, err := e.RenderSectionChunk(SectionPromptData{
+			DocumentTitle: result.DocumentTitle,
+			SuggestedURL:  suggestedURL,
+			ChunkNumber:   chunkNum,
+			TotalChunks:   totalChunks,
+			Sections:      chunk,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to render section chunk %d: %w", chunkNum, err)
+		}
+
+		filename := fmt.Sprintf("chunk-%d-of-%d.md", chunkNum, totalChunks)
+		filepath := filepath.Join(outputDir, filename)
+
+		if err := os.WriteFile(filepath, []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write section chunk %d to file: %w", chunkNum, err)
+		}
+
+		results = append(results, ChunkResult{
+			ChunkNumber:   chunkNum,
+			Content:       content,
+			Filename:      filepath,
+			LocationCount: len(chunk),
+			SuggestionIDs: sectionSuggestionIDs(chunk),
+			LocationIDs:   sectionIDs(chunk),
+		})
+	}
+
+	return results, nil
+}
+
+// sectionIDs returns the IDs of the given sections, in order
+func sectionIDs(sections []gdocs.DocumentSection) []string {
+	ids := make([]string, len(sections))
+	for i, section := range sections {
+		ids[i] = section.ID
+	}
+	return ids
+}
+
+// sectionSuggestionIDs returns the suggestion IDs covered by the given sections
+func sectionSuggestionIDs(sections []gdocs.DocumentSection) []string {
+	var ids []string
+	for _, section := range sections {
+		ids = append(ids, section.SuggestionIDs...)
+	}
+	return ids
+}
@@ -0,0 +1,219 @@
+// Package screenshot captures pages of the target site, served by its local
+// development server, with a headless Chrome, so reviewers can see what a run
+// changed.
+package screenshot
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is where the development server of ubuntu.com listens.
+const DefaultBaseURL = "http://localhost:8001"
+
+// defaultStartTimeout is how long the server has to start serving pages.
+const defaultStartTimeout = 2 * time.Minute
+
+// browsers are the Chrome binaries looked for on PATH, in order.
+var browsers = []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "chrome"}
+
+// Options configure the server and the browser.
+type Options struct {
+	// ServerCommand starts the development server of the site, run with sh
+	// in the target repository, e.g. "dotrun"
+	ServerCommand string
+
+	// BaseURL is where the server serves the site (default: DefaultBaseURL)
+	BaseURL string
+
+	// Browser is the Chrome or Chromium binary (default: the first found on PATH)
+	Browser string
+
+	// StartTimeout is how long the server may take to start (default: 2 minutes)
+	StartTimeout time.Duration
+}
+
+// Enabled reports whether screenshots were asked for.
+func (o Options) Enabled() bool {
+	return o.ServerCommand != ""
+}
+
+// Shot is a page captured before and after the changes of a run.
+type Shot struct {
+	PageURL string `json:"page_url"`
+	Before  string `json:"before,omitempty"`
+	After   string `json:"after,omitempty"`
+}
+
+// Capture starts the server in dir, saves a screenshot of each page to outDir
+// as <page>-<label>.png and stops the server. It returns the screenshots by
+// page URL; pages that fail are logged and left out.
+func Capture(ctx context.Context, opts Options, dir string, pages []string, outDir, label string) (map[string]string, error) {
+	browser, err := findBrowser(opts.Browser)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create screenshot directory: %w", err)
+	}
+
+	server, err := startServer(ctx, opts, dir)
+	if err != nil {
+		return nil, err
+	}
+	defer server.stop()
+
+	baseURL := strings.TrimRight(cmp.Or(opts.BaseURL, DefaultBaseURL), "/")
+	shots := make(map[string]string)
+	for _, page := range pages {
+		path := filepath.Join(outDir, fmt.Sprintf("%s-%s.png", PageName(page), label))
+		if err := takeScreenshot(ctx, browser, baseURL+PagePath(page), path); err != nil {
+			slog.Warn("Failed to capture page", slog.String("page", page), slog.String("error", err.Error()))
+			continue
+		}
+		shots[page] = path
+	}
+	return shots, nil
+}
+
+// PagePath returns the path of a page URL such as "ubuntu.com/desktop/features",
+// i.e. "/desktop/features".
+func PagePath(pageURL string) string {
+	path := pageURL
+	if idx := strings.Index(path, "://"); idx != -1 {
+		path = path[idx+3:]
+	}
+	if idx := strings.Index(path, "/"); idx != -1 {
+		path = path[idx:]
+	} else {
+		path = "/"
+	}
+	if idx := strings.IndexAny(path, "?#"); idx != -1 {
+		path = path[:idx]
+	}
+	return path
+}
+
+// PageName turns a page URL into a file name, e.g. "desktop-features".
+func PageName(pageURL string) string {
+	name := strings.ReplaceAll(strings.Trim(PagePath(pageURL), "/"), "/", "-")
+	return cmp.Or(name, "index")
+}
+
+func findBrowser(browser string) (string, error) {
+	if browser != "" {
+		path, err := exec.LookPath(browser)
+		if err != nil {
+			return "", fmt.Errorf("browser %s not found: %w", browser, err)
+		}
+		return path, nil
+	}
+	for _, name := range browsers {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no Chrome or Chromium found (looked for %s)", strings.Join(browsers, ", "))
+}
+
+func takeScreenshot(ctx context.Context, browser, url, path string) error {
+	args := []string{
+		"--headless=new",
+		"--disable-gpu",
+		"--hide-scrollbars",
+		"--window-size=1280,2400",
+		"--screenshot=" + path,
+	}
+	// Chrome refuses to run as root, as in most containers, with its sandbox
+	if os.Geteuid() == 0 {
+		args = append(args, "--no-sandbox")
+	}
+	cmd := exec.CommandContext(ctx, browser, append(args, url)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to capture %s: %w, output: %s", url, err, output)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("no screenshot of %s: %w", url, err)
+	}
+	return nil
+}
+
+// server is a running development server.
+type server struct {
+	cmd    *exec.Cmd
+	output *bytes.Buffer
+	done   chan struct{}
+}
+
+// startServer runs the server command and waits until the site responds.
+func startServer(ctx context.Context, opts Options, dir string) (*server, error) {
+	cmd := exec.Command("sh", "-c", opts.ServerCommand)
+	cmd.Dir = dir
+	output := &bytes.Buffer{}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start server: %w", err)
+	}
+	s := &server{cmd: cmd, output: output, done: make(chan struct{})}
+	go func() {
+		cmd.Wait()
+		close(s.done)
+	}()
+
+	baseURL := cmp.Or(opts.BaseURL, DefaultBaseURL)
+	deadline := time.Now().Add(cmp.Or(opts.StartTimeout, defaultStartTimeout))
+	client := &http.Client{Timeout: 5 * time.Second}
+	for {
+		// Any response, even an error page, means the server is up
+		if resp, err := client.Get(baseURL); err == nil {
+			resp.Body.Close()
+			return s, nil
+		}
+		select {
+		case <-s.done:
+			return nil, fmt.Errorf("server exited before serving %s, output: %s", baseURL, lastLines(output.String(), 20))
+		case <-ctx.Done():
+			s.stop()
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			s.stop()
+			return nil, fmt.Errorf("server didn't serve %s in time, output: %s", baseURL, lastLines(output.String(), 20))
+		}
+	}
+}
+
+// stop kills the server and the processes it started.
+func (s *server) stop() {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	killProcessGroup(s.cmd)
+	select {
+	case <-s.done:
+	case <-time.After(10 * time.Second):
+		slog.Warn("Development server didn't stop")
+	}
+}
+
+func lastLines(text string, n int) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,76 @@
+package screenshot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPageName(t *testing.T) {
+	tests := map[string]struct{ path, name string }{
+		"ubuntu.com/desktop/features":    {"/desktop/features", "desktop-features"},
+		"https://ubuntu.com/pro?x=1#faq": {"/pro", "pro"},
+		"ubuntu.com":                     {"/", "index"},
+	}
+	for pageURL, want := range tests {
+		if got := PagePath(pageURL); got != want.path {
+			t.Errorf("PagePath(%q) = %q, want %q", pageURL, got, want.path)
+		}
+		if got := PageName(pageURL); got != want.name {
+			t.Errorf("PageName(%q) = %q, want %q", pageURL, got, want.name)
+		}
+	}
+}
+
+func TestCapture(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer site.Close()
+
+	// The browser writes the URL it was given as the screenshot
+	dir := t.TempDir()
+	browser := filepath.Join(dir, "fake-chrome")
+	script := "#!/bin/sh\nfor arg; do case $arg in --screenshot=*) out=${arg#--screenshot=};; esac; url=$arg; done\necho \"$url\" > \"$out\"\n"
+	if err := os.WriteFile(browser, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{ServerCommand: "sleep 60", BaseURL: site.URL, Browser: browser, StartTimeout: 10 * time.Second}
+	outDir := filepath.Join(dir, "screenshots")
+	shots, err := Capture(context.Background(), opts, dir, []string{"ubuntu.com/desktop", "ubuntu.com"}, outDir, "after")
+	if err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+
+	want := map[string]string{
+		"ubuntu.com/desktop": filepath.Join(outDir, "desktop-after.png"),
+		"ubuntu.com":         filepath.Join(outDir, "index-after.png"),
+	}
+	if diff := cmp.Diff(want, shots); diff != "" {
+		t.Errorf("Capture() mismatch (-want +got):\n%s", diff)
+	}
+	if content, _ := os.ReadFile(want["ubuntu.com/desktop"]); strings.TrimSpace(string(content)) != site.URL+"/desktop" {
+		t.Errorf("Screenshot of %q, want %s/desktop", content, site.URL)
+	}
+}
+
+func TestCapture_ServerExits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+	opts := Options{ServerCommand: "echo no such site; exit 1", BaseURL: "http://127.0.0.1:1", Browser: "sh"}
+	_, err := Capture(context.Background(), opts, t.TempDir(), []string{"ubuntu.com"}, t.TempDir(), "before")
+	if err == nil || !strings.Contains(err.Error(), "no such site") {
+		t.Errorf("Capture() error = %v, want the server output", err)
+	}
+}
@@ -0,0 +1,173 @@
+// Package sites maps the pages documents are about onto the repositories and
+// templates that serve them, so one Bauer can work on several sites.
+package sites
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTemplates are where the template of a page is looked for on sites
+// without templates of their own, e.g. ubuntu.com/desktop is
+// templates/desktop.html or templates/desktop/index.html.
+var DefaultTemplates = []string{"templates/{path}.html", "templates/{path}/index.html"}
+
+// Site is a website, e.g. ubuntu.com, and the repository serving it.
+type Site struct {
+	// Prefix is the start of the page URLs of the site, without the scheme,
+	// e.g. "ubuntu.com/"; the longest matching prefix wins.
+	Prefix string `json:"prefix" yaml:"prefix"`
+
+	// Repo is the GitHub repository of the site: owner/repo or an HTTPS URL.
+	Repo string `json:"repo" yaml:"repo"`
+
+	// Templates are the candidate files of a page in the repository, where
+	// {path} is the path of the page after the prefix, without slashes
+	// around it, and "index" for the home page. Default is DefaultTemplates.
+	Templates []string `json:"templates,omitempty" yaml:"templates,omitempty"`
+}
+
+// Table is the sites Bauer knows of.
+type Table []Site
+
+// Parse reads a table of sites from a JSON or YAML file, or from the JSON of
+// the table itself, e.g. a config file setting.
+func Parse(value string) (Table, error) {
+	data := []byte(value)
+	if !strings.HasPrefix(strings.TrimSpace(value), "[") {
+		var err error
+		if data, err = os.ReadFile(value); err != nil {
+			return nil, fmt.Errorf("failed to read sites: %w", err)
+		}
+	}
+	var table Table
+	if err := yaml.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse sites: %w", err)
+	}
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// Validate checks that every site has a prefix, and templates with {path}.
+func (t Table) Validate() error {
+	seen := map[string]bool{}
+	for i, site := range t {
+		if site.Prefix == "" {
+			return fmt.Errorf("site %d has no prefix", i+1)
+		}
+		prefix := sitePrefix(&site)
+		if seen[prefix] {
+			return fmt.Errorf("site %s is listed twice", site.Prefix)
+		}
+		seen[prefix] = true
+		for _, template := range site.Templates {
+			if !strings.Contains(template, "{path}") {
+				return fmt.Errorf("template %q of site %s has no {path}", template, site.Prefix)
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns the site of a page URL, or nil when no site matches.
+func (t Table) Match(pageURL string) *Site {
+	page := pagePath(pageURL)
+	var match *Site
+	for i := range t {
+		prefix := sitePrefix(&t[i])
+		if page != prefix && !strings.HasPrefix(page, prefix+"/") {
+			continue
+		}
+		if match == nil || len(prefix) > len(sitePrefix(match)) {
+			match = &t[i]
+		}
+	}
+	return match
+}
+
+// Repo returns the repository of the site of pages, the page URLs of a
+// document; all of them must be on sites of the same repository.
+func (t Table) Repo(pageURLs []string) (string, error) {
+	repos := map[string]bool{}
+	for _, pageURL := range pageURLs {
+		if pageURL == "" {
+			continue
+		}
+		site := t.Match(pageURL)
+		if site == nil || site.Repo == "" {
+			return "", fmt.Errorf("no site with a repository matches %s", pageURL)
+		}
+		repos[site.Repo] = true
+	}
+	if len(repos) == 0 {
+		return "", errors.New("the document has no page URL to find its site by")
+	}
+	var names []string
+	for repo := range repos {
+		names = append(names, repo)
+	}
+	if len(names) > 1 {
+		sort.Strings(names)
+		return "", fmt.Errorf("the pages of the document are in several repositories: %s", strings.Join(names, ", "))
+	}
+	return names[0], nil
+}
+
+// TemplatePath maps a page URL such as "ubuntu.com/desktop/features" onto
+// its template in repoRoot, with the templates of the page's site, e.g.
+// templates/desktop/features.html or templates/desktop/features/index.html.
+func (t Table) TemplatePath(repoRoot, pageURL string) (string, error) {
+	templates := DefaultTemplates
+	path := pagePath(pageURL)
+	// Without a site, the path of the page starts after the domain
+	if idx := strings.Index(path, "/"); idx != -1 {
+		path = path[idx:]
+	} else {
+		path = ""
+	}
+	if site := t.Match(pageURL); site != nil {
+		if len(site.Templates) > 0 {
+			templates = site.Templates
+		}
+		path = strings.TrimPrefix(pagePath(pageURL), sitePrefix(site))
+	}
+	path = strings.Trim(path, "/")
+	if path == "" {
+		path = "index"
+	}
+
+	for _, template := range templates {
+		candidate := filepath.Join(repoRoot, filepath.FromSlash(strings.ReplaceAll(template, "{path}", path)))
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no template found for %s in %s", pageURL, repoRoot)
+}
+
+// sitePrefix returns the prefix of a site as pagePath does, without a
+// trailing slash.
+func sitePrefix(site *Site) string {
+	return strings.TrimSuffix(pagePath(site.Prefix), "/")
+}
+
+// pagePath returns a page URL without its scheme, "www.", query and
+// fragment, e.g. "ubuntu.com/desktop" for "https://www.ubuntu.com/desktop?x".
+func pagePath(pageURL string) string {
+	path := pageURL
+	if idx := strings.Index(path, "://"); idx != -1 {
+		path = path[idx+3:]
+	}
+	if idx := strings.IndexAny(path, "?#"); idx != -1 {
+		path = path[:idx]
+	}
+	return strings.TrimPrefix(path, "www.")
+}
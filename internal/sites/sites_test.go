@@ -0,0 +1,94 @@
+package sites
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplatePath(t *testing.T) {
+	repo := t.TempDir()
+	for _, file := range []string{
+		"templates/index.html",
+		"templates/desktop/features.html",
+		"templates/desktop/index.html",
+		"content/blog/release.md",
+	} {
+		path := filepath.Join(repo, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("<p>x</p>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	table := Table{
+		{Prefix: "ubuntu.com/", Repo: "canonical/ubuntu.com"},
+		{Prefix: "ubuntu.com/blog", Repo: "canonical/ubuntu.com", Templates: []string{"content/blog/{path}.md"}},
+	}
+
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{url: "ubuntu.com/desktop/features", want: "templates/desktop/features.html"},
+		{url: "https://ubuntu.com/desktop?foo=bar", want: "templates/desktop/index.html"},
+		{url: "https://www.ubuntu.com", want: "templates/index.html"},
+		{url: "ubuntu.com/blog/release", want: "content/blog/release.md"},
+		{url: "canonical.com/desktop/features", want: "templates/desktop/features.html"},
+		{url: "ubuntu.com/missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := table.TemplatePath(repo, tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("TemplatePath(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != filepath.Join(repo, tt.want) {
+			t.Errorf("TemplatePath(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRepo(t *testing.T) {
+	table := Table{
+		{Prefix: "ubuntu.com/", Repo: "canonical/ubuntu.com"},
+		{Prefix: "ubuntu.com/blogger", Repo: "canonical/blogger"},
+		{Prefix: "canonical.com", Repo: "canonical/canonical.com"},
+	}
+	tests := []struct {
+		pages   []string
+		want    string
+		wantErr bool
+	}{
+		{pages: []string{"ubuntu.com/aws", "https://ubuntu.com/blog"}, want: "canonical/ubuntu.com"},
+		{pages: []string{"ubuntu.com/blogger/post"}, want: "canonical/blogger"},
+		{pages: []string{"ubuntu.com/aws", "canonical.com/about"}, wantErr: true},
+		{pages: []string{"snapcraft.io/store"}, wantErr: true},
+		{pages: []string{""}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := table.Repo(tt.pages)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Repo(%q) error = %v, wantErr %v", tt.pages, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Repo(%q) = %q, want %q", tt.pages, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	table, err := Parse(`[{"prefix": "ubuntu.com/", "repo": "canonical/ubuntu.com", "templates": ["templates/{path}.html"]}]`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(table) != 1 || table[0].Repo != "canonical/ubuntu.com" {
+		t.Errorf("Parse() = %+v", table)
+	}
+	if _, err := Parse(`[{"prefix": "ubuntu.com/", "templates": ["templates/index.html"]}]`); err == nil {
+		t.Error("Parse() of a template without {path} succeeded")
+	}
+}
@@ -0,0 +1,177 @@
+// Package staleness detects suggestions whose anchors no longer match the
+// currently published page, e.g. when reviewers commented on a copy of a page
+// that has since been rewritten.
+package staleness
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"bauer/internal/gdocs"
+	"bauer/internal/sites"
+)
+
+const (
+	// SourceHTTP fetches the live page from the metadata URL.
+	SourceHTTP = "http"
+	// SourceRepo reads the page template from the target repository.
+	SourceRepo = "repo"
+
+	// probeLength is the maximum amount of anchor text used to probe the page
+	probeLength = 40
+)
+
+var (
+	tagPattern        = regexp.MustCompile(`(?s)<script.*?</script>|<style.*?</style>|<[^>]*>|\{[%#].*?[%#]\}`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Report summarises a staleness check.
+type Report struct {
+	Source     string   `json:"source"`
+	Location   string   `json:"location"` // URL or file that was checked
+	Checked    int      `json:"checked"`
+	StaleIDs   []string `json:"stale_ids"`
+	StaleCount int      `json:"stale_count"`
+}
+
+// LoadPageText loads the published content of a page and returns its visible text.
+// For SourceHTTP, pageURL is fetched directly (https is assumed when no scheme is set).
+// For SourceRepo, pageURL is resolved to a template inside repoRoot, with the
+// templates of its site in table.
+func LoadPageText(ctx context.Context, source, pageURL, repoRoot string, table sites.Table) (text string, location string, err error) {
+	if pageURL == "" {
+		return "", "", fmt.Errorf("no page URL available for staleness check")
+	}
+
+	switch source {
+	case SourceHTTP:
+		location = pageURL
+		if !strings.Contains(location, "://") {
+			location = "https://" + location
+		}
+		body, err := fetchPage(ctx, location)
+		if err != nil {
+			return "", location, err
+		}
+		return PageText(body), location, nil
+
+	case SourceRepo:
+		location, err = table.TemplatePath(repoRoot, pageURL)
+		if err != nil {
+			return "", "", err
+		}
+		body, err := os.ReadFile(location)
+		if err != nil {
+			return "", location, fmt.Errorf("failed to read page template: %w", err)
+		}
+		return PageText(string(body)), location, nil
+
+	default:
+		return "", "", fmt.Errorf("unknown staleness source: %s", source)
+	}
+}
+
+// PageText strips markup from an HTML (or Jinja) page and collapses whitespace.
+func PageText(page string) string {
+	text := tagPattern.ReplaceAllString(page, " ")
+	return normalize(html.UnescapeString(text))
+}
+
+// MarkStale flags grouped suggestions whose anchors can no longer be found in pageText.
+// pageText is expected to be the output of PageText. The page and anchors are
+// both normalized with n before matching.
+func MarkStale(groups []gdocs.LocationGroupedSuggestions, pageText string, n gdocs.Normalization) *Report {
+	report := &Report{StaleIDs: []string{}}
+	pageText = n.Apply(pageText)
+
+	for gi := range groups {
+		// Metadata suggestions don't map to visible page copy
+		if groups[gi].Location.InMetadata {
+			continue
+		}
+		for si := range groups[gi].Suggestions {
+			sugg := &groups[gi].Suggestions[si]
+			probe := n.Apply(AnchorProbe(*sugg))
+			if probe == "" {
+				continue
+			}
+
+			report.Checked++
+			sugg.Stale = !strings.Contains(pageText, probe)
+			if sugg.Stale {
+				report.StaleIDs = append(report.StaleIDs, sugg.ID)
+			}
+		}
+	}
+
+	report.StaleCount = len(report.StaleIDs)
+	return report
+}
+
+// AnchorProbe picks the text that must still exist on the page for a suggestion to apply.
+// Deletions and replacements need their original text; insertions need the text right
+// before the insertion point.
+func AnchorProbe(sugg gdocs.GroupedActionableSuggestion) string {
+	if sugg.Change.OriginalText != "" {
+		return normalize(sugg.Change.OriginalText)
+	}
+
+	preceding := sugg.Anchor.PrecedingText
+	if idx := strings.LastIndex(strings.TrimRight(preceding, "\n"), "\n"); idx != -1 {
+		preceding = preceding[idx+1:]
+	}
+	preceding = normalize(preceding)
+	if len(preceding) > probeLength {
+		start := len(preceding) - probeLength
+		// Nor mid-rune
+		for start < len(preceding) && !utf8.RuneStart(preceding[start]) {
+			start++
+		}
+		preceding = preceding[start:]
+		// Don't start mid-word
+		if idx := strings.Index(preceding, " "); idx != -1 {
+			preceding = preceding[idx+1:]
+		}
+	}
+	return preceding
+}
+
+func normalize(text string) string {
+	text = strings.ReplaceAll(text, "\u00a0", " ")
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(text, " "))
+}
+
+func fetchPage(ctx context.Context, pageURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build page request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch page %s: status %d", pageURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read page body: %w", err)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,92 @@
+package staleness
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"bauer/internal/gdocs"
+)
+
+func TestPageText(t *testing.T) {
+	page := `<div class="p-strip">{% block content %}<h1>Ubuntu&nbsp;on   AWS</h1>
+<script>var x = "<p>hidden</p>";</script><p>Run it <a href="#">today</a>.</p>{% endblock %}</div>`
+
+	got := PageText(page)
+	want := "Ubuntu on AWS Run it today ."
+	if got != want {
+		t.Errorf("PageText() = %q, want %q", got, want)
+	}
+}
+
+func TestMarkStale(t *testing.T) {
+	groups := []gdocs.LocationGroupedSuggestions{
+		{
+			Location: gdocs.SuggestionLocation{Section: "Body"},
+			Suggestions: []gdocs.GroupedActionableSuggestion{
+				{
+					ID:     "still-there",
+					Change: gdocs.SuggestionChange{Type: "replace", OriginalText: "Ubuntu on AWS", NewText: "Ubuntu on Amazon"},
+				},
+				{
+					ID:     "rewritten",
+					Change: gdocs.SuggestionChange{Type: "delete", OriginalText: "legacy copy"},
+				},
+				{
+					ID:     "insert-ok",
+					Anchor: gdocs.SuggestionAnchor{PrecedingText: "Heading\nRun it ", FollowingText: "today"},
+					Change: gdocs.SuggestionChange{Type: "insert", NewText: "now and "},
+				},
+			},
+		},
+		{
+			Location: gdocs.SuggestionLocation{Section: "Body", InMetadata: true},
+			Suggestions: []gdocs.GroupedActionableSuggestion{
+				{
+					ID:     "metadata",
+					Change: gdocs.SuggestionChange{Type: "replace", OriginalText: "Not on page", NewText: "x"},
+				},
+			},
+		},
+	}
+
+	report := MarkStale(groups, "Ubuntu on AWS Run it today.", gdocs.Normalization{})
+
+	if report.Checked != 3 {
+		t.Errorf("Checked = %d, want 3", report.Checked)
+	}
+	if report.StaleCount != 1 || report.StaleIDs[0] != "rewritten" {
+		t.Errorf("Expected only 'rewritten' to be stale, got %v", report.StaleIDs)
+	}
+	if !groups[0].Suggestions[1].Stale {
+		t.Error("Expected 'rewritten' suggestion to be flagged stale")
+	}
+	if groups[0].Suggestions[0].Stale || groups[0].Suggestions[2].Stale {
+		t.Error("Expected matching suggestions not to be flagged stale")
+	}
+	if groups[1].Suggestions[0].Stale {
+		t.Error("Expected metadata suggestions to be skipped")
+	}
+}
+
+func TestAnchorProbe_NonASCII(t *testing.T) {
+	// No space in the last 40 bytes, whose first falls inside a rune
+	preceding := "Сервер Ubuntu для высокопроизводительных."
+	sugg := gdocs.GroupedActionableSuggestion{
+		Anchor: gdocs.SuggestionAnchor{PrecedingText: preceding},
+		Change: gdocs.SuggestionChange{Type: "insert", NewText: "s"},
+	}
+
+	probe := AnchorProbe(sugg)
+	if !utf8.ValidString(probe) {
+		t.Fatalf("AnchorProbe() = %q, not valid UTF-8", probe)
+	}
+	if probe == "" || !strings.HasSuffix(preceding, probe) {
+		t.Errorf("AnchorProbe() = %q, want the end of %q", probe, preceding)
+	}
+
+	groups := []gdocs.LocationGroupedSuggestions{{Suggestions: []gdocs.GroupedActionableSuggestion{sugg}}}
+	if report := MarkStale(groups, preceding+" серверов", gdocs.Normalization{}); report.StaleCount != 0 {
+		t.Errorf("MarkStale() stale = %v, want none", report.StaleIDs)
+	}
+}
@@ -0,0 +1,176 @@
+// Package state records the outcome of every suggestion across runs, keyed by
+// document and suggestion ID, so a run can skip the suggestions earlier runs
+// already applied.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"bauer/internal/ledger"
+)
+
+// Record is the latest outcome of a suggestion.
+type Record struct {
+	Status ledger.Status `json:"status"`
+	Note   string        `json:"note,omitempty"`
+
+	// RunID is the run that recorded it, and PullRequestURL the pull request
+	// its change went to, once known
+	RunID          string `json:"run_id,omitempty"`
+	PullRequestURL string `json:"pull_request_url,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Applied reports whether a run applied the suggestion; unverified changes
+// count, as applying them again would duplicate them.
+func (r *Record) Applied() bool {
+	switch r.Status {
+	case ledger.StatusApplied, ledger.StatusVerified, ledger.StatusUnverified, ledger.StatusMerged:
+		return true
+	}
+	return false
+}
+
+// Store is the state file: the records of the suggestions of each document.
+type Store struct {
+	Documents map[string]map[string]*Record `json:"documents"`
+
+	path string
+}
+
+// DefaultFile returns <user config dir>/bauer/state.json.
+func DefaultFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user config directory: %w", err)
+	}
+	return filepath.Join(dir, "bauer", "state.json"), nil
+}
+
+// Open reads the state file at path; a missing file is an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{Documents: map[string]map[string]*Record{}, path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	if s.Documents == nil {
+		s.Documents = map[string]map[string]*Record{}
+	}
+	return s, nil
+}
+
+// Get returns the record of a suggestion, or nil.
+func (s *Store) Get(docID, suggestionID string) *Record {
+	return s.Documents[docID][suggestionID]
+}
+
+// AppliedIDs returns the IDs of the suggestions of a document that earlier
+// runs applied.
+func (s *Store) AppliedIDs(docID string) []string {
+	var ids []string
+	for id, record := range s.Documents[docID] {
+		if record.Applied() {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Update records the outcome of the suggestions of a run's ledger. Those the
+// run didn't get to the end of, e.g. in a dry run, keep their record. It
+// returns the number of suggestions recorded.
+func (s *Store) Update(l *ledger.Ledger, runID string) int {
+	records := s.Documents[l.DocumentID]
+	if records == nil {
+		records = map[string]*Record{}
+		s.Documents[l.DocumentID] = records
+	}
+	updated := 0
+	now := time.Now().UTC()
+	for _, entry := range l.Entries {
+		switch entry.Status {
+		case ledger.StatusExtracted, ledger.StatusGrouped, ledger.StatusChunked, ledger.StatusDone:
+			continue
+		}
+		records[entry.SuggestionID] = &Record{
+			Status:    entry.Status,
+			Note:      entry.Note,
+			RunID:     runID,
+			UpdatedAt: now,
+		}
+		updated++
+	}
+	return updated
+}
+
+// SetPullRequest records the pull request the suggestions of a run went to.
+func (s *Store) SetPullRequest(docID, runID, url string) {
+	for _, record := range s.Documents[docID] {
+		if record.RunID == runID {
+			record.PullRequestURL = url
+		}
+	}
+}
+
+// Reopen forgets the outcomes recorded by a run, e.g. once it's rolled back,
+// so later runs apply its suggestions again. It returns the number of
+// suggestions reopened.
+func (s *Store) Reopen(docID, runID string) int {
+	reopened := 0
+	for id, record := range s.Documents[docID] {
+		if record.RunID == runID {
+			delete(s.Documents[docID], id)
+			reopened++
+		}
+	}
+	return reopened
+}
+
+// ReopenPullRequest forgets the outcomes recorded by every run whose changes
+// went to the pull request at url, e.g. once it's closed, whatever their
+// document. It returns the number of suggestions reopened.
+func (s *Store) ReopenPullRequest(url string) int {
+	reopened := 0
+	for _, records := range s.Documents {
+		for id, record := range records {
+			if record.PullRequestURL == url {
+				delete(records, id)
+				reopened++
+			}
+		}
+	}
+	return reopened
+}
+
+// Save writes the store to its file, replacing it at once so concurrent
+// readers never see half of it.
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package state
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"bauer/internal/ledger"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bauer", "state.json")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	l := ledger.New("doc-1")
+	l.Set("s1", ledger.StatusVerified, "")
+	l.Set("s2", ledger.StatusFailed, "anchor not found")
+	l.Set("s3", ledger.StatusChunked, "")
+	if got := store.Update(l, "run-1"); got != 2 {
+		t.Errorf("Update() = %d, want 2", got)
+	}
+	store.SetPullRequest("doc-1", "run-1", "https://github.com/o/r/pull/1")
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"s1"}, store.AppliedIDs("doc-1")); diff != "" {
+		t.Errorf("AppliedIDs() mismatch (-want +got):\n%s", diff)
+	}
+	if got := store.Get("doc-1", "s1").PullRequestURL; got != "https://github.com/o/r/pull/1" {
+		t.Errorf("PullRequestURL = %q", got)
+	}
+	if store.Get("doc-1", "s3") != nil {
+		t.Error("Expected no record of an unfinished suggestion")
+	}
+
+	// A later run fixing the failed suggestion doesn't touch the others
+	l = ledger.New("doc-1")
+	l.Set("s2", ledger.StatusApplied, "")
+	store.Update(l, "run-2")
+	ids := store.AppliedIDs("doc-1")
+	sort.Strings(ids)
+	if diff := cmp.Diff([]string{"s1", "s2"}, ids); diff != "" {
+		t.Errorf("AppliedIDs() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := store.Reopen("doc-1", "run-2"); got != 1 {
+		t.Errorf("Reopen() = %d, want 1", got)
+	}
+	if diff := cmp.Diff([]string{"s1"}, store.AppliedIDs("doc-1")); diff != "" {
+		t.Errorf("AppliedIDs() after Reopen mismatch (-want +got):\n%s", diff)
+	}
+
+	// Closing a PR reopens every run that went to it, whatever the document
+	l = ledger.New("doc-2")
+	l.Set("s1", ledger.StatusApplied, "")
+	store.Update(l, "run-3")
+	store.SetPullRequest("doc-2", "run-3", "https://github.com/o/r/pull/1")
+	if got := store.ReopenPullRequest("https://github.com/o/r/pull/1"); got != 2 {
+		t.Errorf("ReopenPullRequest() = %d, want 2", got)
+	}
+	if got := store.AppliedIDs("doc-1"); len(got) != 0 {
+		t.Errorf("AppliedIDs() after ReopenPullRequest = %v, want none", got)
+	}
+}
@@ -0,0 +1,107 @@
+package triage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"bauer/internal/gdocs"
+)
+
+// Assistant answers a single prompt, e.g. a chat completions request.
+type Assistant interface {
+	Ask(ctx context.Context, prompt string) (string, error)
+}
+
+// AskFunc adapts a function to the Assistant interface.
+type AskFunc func(ctx context.Context, prompt string) (string, error)
+
+// Ask calls f.
+func (f AskFunc) Ask(ctx context.Context, prompt string) (string, error) {
+	return f(ctx, prompt)
+}
+
+const triagePrompt = `You triage copy changes to a website before they are applied.
+Classify each numbered group of changes below with a category and a risk:
+
+- category: "copy" for wording edits, "structural" for changes to the layout or
+  order of the page (paragraphs, lists, moves, formatting), "legal" for changes
+  with legal weight (terms, privacy, warranties, pricing commitments, trademarks),
+  "seo" for page metadata and links
+- risk: "low", "medium" or "high", by how much harm a wrong change would do
+
+Each group has the classification of the rules; keep it unless it's wrong.
+Reply with a JSON array only, one object per group:
+[{"group": 1, "category": "copy", "risk": "low", "reason": "short reason"}]
+
+`
+
+// llmClassification is an entry of the reply to triagePrompt.
+type llmClassification struct {
+	Group    int      `json:"group"`
+	Category Category `json:"category"`
+	Risk     Risk     `json:"risk"`
+	Reason   string   `json:"reason"`
+}
+
+// Refine asks an assistant to review the rule classification of the groups,
+// and routes them again. Entries of the reply that don't parse are ignored,
+// so the rules stand for them.
+func Refine(ctx context.Context, assistant Assistant, groups []gdocs.LocationGroupedSuggestions, report *Report, routes Routes) error {
+	var sb strings.Builder
+	sb.WriteString(triagePrompt)
+	for i, group := range groups {
+		entry := report.Entries[i]
+		fmt.Fprintf(&sb, "## Group %d (rules: %s, %s risk)\n", i+1, entry.Category, entry.Risk)
+		if group.Location.InMetadata {
+			sb.WriteString("In the metadata table\n")
+		} else if group.Location.ParentHeading != "" {
+			fmt.Fprintf(&sb, "Under the heading %q\n", group.Location.ParentHeading)
+		}
+		for _, sugg := range group.Suggestions {
+			fmt.Fprintf(&sb, "- %s: %q -> %q", sugg.Change.Type, sugg.Change.OriginalText, sugg.Change.NewText)
+			if sugg.Change.Instruction != "" {
+				fmt.Fprintf(&sb, " (%s)", sugg.Change.Instruction)
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	reply, err := assistant.Ask(ctx, sb.String())
+	if err != nil {
+		return fmt.Errorf("failed to triage suggestions: %w", err)
+	}
+	classifications, err := parseReply(reply)
+	if err != nil {
+		return err
+	}
+	for _, c := range classifications {
+		if c.Group < 1 || c.Group > len(report.Entries) || !slices.Contains(Categories, c.Category) || !slices.Contains(Risks, c.Risk) {
+			continue
+		}
+		entry := &report.Entries[c.Group-1]
+		if entry.Category != c.Category || entry.Risk != c.Risk {
+			entry.Category, entry.Risk, entry.Reason = c.Category, c.Risk, c.Reason
+		}
+		entry.Route = routes.Route(entry.Classification)
+	}
+	report.Mode = ModeLLM
+	return nil
+}
+
+// parseReply reads the JSON array of a reply, which models may wrap in prose
+// or a code block.
+func parseReply(reply string) ([]llmClassification, error) {
+	start, end := strings.Index(reply, "["), strings.LastIndex(reply, "]")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("no classifications in the triage reply")
+	}
+	var classifications []llmClassification
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &classifications); err != nil {
+		return nil, fmt.Errorf("failed to parse triage reply: %w", err)
+	}
+	return classifications, nil
+}
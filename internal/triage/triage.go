@@ -0,0 +1,297 @@
+// Package triage classifies the suggestion groups of a document by the kind of
+// change they make and its risk, so routes can decide which are applied by
+// the run and which wait for a person's approval.
+package triage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"bauer/internal/gdocs"
+)
+
+// TriageFile is the classification of a run's suggestions, written with its
+// artifacts.
+const TriageFile = "bauer-triage.json"
+
+// Category is the kind of change a group makes.
+type Category string
+
+const (
+	CategoryCopy       Category = "copy"
+	CategoryStructural Category = "structural"
+	CategoryLegal      Category = "legal"
+	CategorySEO        Category = "seo"
+)
+
+// Categories lists every category.
+var Categories = []Category{CategoryCopy, CategoryStructural, CategoryLegal, CategorySEO}
+
+// Risk is how much harm a wrong application of a group could do.
+type Risk string
+
+const (
+	RiskLow    Risk = "low"
+	RiskMedium Risk = "medium"
+	RiskHigh   Risk = "high"
+)
+
+// Risks lists every risk level, lowest first.
+var Risks = []Risk{RiskLow, RiskMedium, RiskHigh}
+
+// Route is what a run does with a group.
+type Route string
+
+const (
+	// RouteApply applies the group, as without triage
+	RouteApply Route = "apply"
+	// RouteReview holds the group back until a person approves it
+	RouteReview Route = "review"
+	// RouteSkip leaves the group out of the run
+	RouteSkip Route = "skip"
+)
+
+// routeOrder ranks routes from least to most strict.
+var routeOrder = []Route{RouteApply, RouteReview, RouteSkip}
+
+// Modes of triage.
+const (
+	ModeRules = "rules"
+	ModeLLM   = "llm"
+)
+
+// lowRiskWords is the most words a copy edit changes to be low risk.
+const lowRiskWords = 12
+
+// legalPattern matches the terms of changes that have legal weight.
+var legalPattern = regexp.MustCompile(`(?i)\b(terms (?:of|and) (?:service|use|conditions)|privacy|gdpr|liabilit(?:y|ies)|warrant(?:y|ies)|indemn\w*|licen[cs](?:e|es|ed|ing)|trademarks?|copyright|complian(?:ce|t)|legal(?:ly)?|disclaimer|guarantee[sd]?|refunds?)\b|[©®™]`)
+
+// Classification is the category and risk of a group, and its route.
+type Classification struct {
+	Category Category `json:"category"`
+	Risk     Risk     `json:"risk"`
+	Reason   string   `json:"reason,omitempty"`
+	Route    Route    `json:"route"`
+}
+
+// Entry is the classification of a location group.
+type Entry struct {
+	LocationID    string   `json:"location_id,omitempty"`
+	SuggestionIDs []string `json:"suggestion_ids"`
+	Classification
+}
+
+// Report is the classification of every group of a run.
+type Report struct {
+	Mode    string  `json:"mode"`
+	Entries []Entry `json:"entries"`
+}
+
+// Classify classifies a group with rules: changes mentioning legal terms are
+// legal, metadata and link changes are SEO, moves and changes to paragraphs
+// or formatting are structural, and the rest are copy edits, low risk unless
+// they change more than a sentence.
+func Classify(group gdocs.LocationGroupedSuggestions) Classification {
+	for _, sugg := range group.Suggestions {
+		for _, text := range []string{sugg.Change.OriginalText, sugg.Change.NewText, sugg.Change.Instruction} {
+			if term := legalPattern.FindString(text); term != "" {
+				return Classification{Category: CategoryLegal, Risk: RiskHigh, Reason: fmt.Sprintf("mentions %q", term)}
+			}
+		}
+	}
+
+	if group.Location.InMetadata {
+		reason := "metadata table"
+		if group.Location.MetadataTable != "" {
+			reason = group.Location.MetadataTable + " metadata table"
+		}
+		return Classification{Category: CategorySEO, Risk: RiskMedium, Reason: reason}
+	}
+	for _, sugg := range group.Suggestions {
+		if sugg.Change.OriginalLinkURL != sugg.Change.NewLinkURL {
+			return Classification{Category: CategorySEO, Risk: RiskMedium, Reason: "changes a link"}
+		}
+	}
+
+	structural := Classification{Category: CategoryStructural}
+	for _, sugg := range group.Suggestions {
+		change := sugg.Change
+		switch {
+		case change.Type == "move":
+			return Classification{Category: CategoryStructural, Risk: RiskHigh, Reason: "moves text"}
+		case change.Paragraph || change.Type == "paragraph" || strings.Count(change.OriginalText+change.NewText, "\n") > 1:
+			structural.Risk, structural.Reason = RiskMedium, "changes whole paragraphs"
+		case change.Type == "style" && structural.Risk == "":
+			structural.Risk, structural.Reason = RiskLow, "changes formatting"
+		}
+	}
+	if structural.Risk != "" {
+		return structural
+	}
+
+	words := 0
+	for _, sugg := range group.Suggestions {
+		words += len(strings.Fields(sugg.Change.OriginalText)) + len(strings.Fields(sugg.Change.NewText))
+		if sugg.Change.Type == "comment_instruction" {
+			return Classification{Category: CategoryCopy, Risk: RiskMedium, Reason: "reviewer instruction"}
+		}
+	}
+	if words > lowRiskWords {
+		return Classification{Category: CategoryCopy, Risk: RiskMedium, Reason: fmt.Sprintf("changes %d words", words)}
+	}
+	return Classification{Category: CategoryCopy, Risk: RiskLow}
+}
+
+// Routes map categories and risk levels to the route of their groups, e.g.
+// legal to review.
+type Routes map[string]Route
+
+// ParseRoutes parses "<category or risk>=<route>" entries, e.g.
+// "legal=review" or "high=skip".
+func ParseRoutes(entries []string) (Routes, error) {
+	routes := Routes{}
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid route %q (expected <category or risk>=<route>)", entry)
+		}
+		name, route := strings.ToLower(strings.TrimSpace(name)), Route(strings.ToLower(strings.TrimSpace(value)))
+		if !slices.Contains(Categories, Category(name)) && !slices.Contains(Risks, Risk(name)) {
+			return nil, fmt.Errorf("unknown category or risk %q in route %q", name, entry)
+		}
+		if !slices.Contains(routeOrder, route) {
+			return nil, fmt.Errorf("unknown route %q (expected apply, review or skip)", value)
+		}
+		routes[name] = route
+	}
+	return routes, nil
+}
+
+// Route returns the route of a classification: the stricter of its
+// category's and its risk's, or RouteApply when neither has one.
+func (r Routes) Route(c Classification) Route {
+	route := RouteApply
+	for _, name := range []string{string(c.Category), string(c.Risk)} {
+		if candidate, ok := r[name]; ok && slices.Index(routeOrder, candidate) > slices.Index(routeOrder, route) {
+			route = candidate
+		}
+	}
+	return route
+}
+
+// ClassifyGroups classifies every group with rules and routes it.
+func ClassifyGroups(groups []gdocs.LocationGroupedSuggestions, routes Routes) *Report {
+	report := &Report{Mode: ModeRules, Entries: make([]Entry, 0, len(groups))}
+	for _, group := range groups {
+		entry := Entry{LocationID: group.LocationID, Classification: Classify(group)}
+		for _, sugg := range group.Suggestions {
+			entry.SuggestionIDs = append(entry.SuggestionIDs, sugg.ID)
+		}
+		entry.Route = routes.Route(entry.Classification)
+		report.Entries = append(report.Entries, entry)
+	}
+	return report
+}
+
+// Split returns the groups of each route, in their order.
+func (r *Report) Split(groups []gdocs.LocationGroupedSuggestions) map[Route][]gdocs.LocationGroupedSuggestions {
+	split := make(map[Route][]gdocs.LocationGroupedSuggestions)
+	for i, group := range groups {
+		route := r.Entries[i].Route
+		split[route] = append(split[route], group)
+	}
+	return split
+}
+
+// Get returns the classification of a suggestion, or nil.
+func (r *Report) Get(suggestionID string) *Entry {
+	if r == nil {
+		return nil
+	}
+	for i := range r.Entries {
+		if slices.Contains(r.Entries[i].SuggestionIDs, suggestionID) {
+			return &r.Entries[i]
+		}
+	}
+	return nil
+}
+
+// Count returns the number of suggestions of each route.
+func (r *Report) Count() map[Route]int {
+	counts := make(map[Route]int)
+	for _, entry := range r.Entries {
+		counts[entry.Route] += len(entry.SuggestionIDs)
+	}
+	return counts
+}
+
+// Save writes the report as JSON.
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode triage report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write triage report: %w", err)
+	}
+	return nil
+}
+
+// Markdown renders the report as a table of the number of suggestions of
+// each category, risk and route, followed by the suggestions held for review.
+func (r *Report) Markdown() string {
+	type row struct {
+		category Category
+		risk     Risk
+		route    Route
+	}
+	counts := make(map[row]int)
+	var rows []row
+	for _, entry := range r.Entries {
+		key := row{entry.Category, entry.Risk, entry.Route}
+		if counts[key] == 0 {
+			rows = append(rows, key)
+		}
+		counts[key] += len(entry.SuggestionIDs)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	slices.SortStableFunc(rows, func(a, b row) int {
+		if c := slices.Index(Categories, a.category) - slices.Index(Categories, b.category); c != 0 {
+			return c
+		}
+		return slices.Index(Risks, a.risk) - slices.Index(Risks, b.risk)
+	})
+
+	var sb strings.Builder
+	sb.WriteString("| Category | Risk | Route | Suggestions |\n| --- | --- | --- | --- |\n")
+	for _, key := range rows {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %d |\n", key.category, key.risk, key.route, counts[key])
+	}
+
+	var held []string
+	for _, entry := range r.Entries {
+		if entry.Route != RouteReview {
+			continue
+		}
+		reason := fmt.Sprintf("%s, %s risk", entry.Category, entry.Risk)
+		if entry.Reason != "" {
+			reason += ": " + entry.Reason
+		}
+		for _, id := range entry.SuggestionIDs {
+			held = append(held, fmt.Sprintf("- `%s` (%s)\n", id, reason))
+		}
+	}
+	if len(held) > 0 {
+		fmt.Fprintf(&sb, "\n**%d suggestions await approval** and weren't applied:\n\n", len(held))
+		for _, line := range held {
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
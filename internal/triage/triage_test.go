@@ -0,0 +1,132 @@
+package triage
+
+import (
+	"context"
+	"testing"
+
+	"bauer/internal/gdocs"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func group(location gdocs.SuggestionLocation, changes ...gdocs.SuggestionChange) gdocs.LocationGroupedSuggestions {
+	g := gdocs.LocationGroupedSuggestions{Location: location}
+	for i, change := range changes {
+		g.Suggestions = append(g.Suggestions, gdocs.GroupedActionableSuggestion{ID: string(rune('a' + i)), Change: change})
+	}
+	return g
+}
+
+func TestClassify(t *testing.T) {
+	body := gdocs.SuggestionLocation{Section: "Body"}
+	tests := []struct {
+		name  string
+		group gdocs.LocationGroupedSuggestions
+		want  Classification
+	}{
+		{
+			name:  "word swap",
+			group: group(body, gdocs.SuggestionChange{Type: "replace", OriginalText: "fast", NewText: "faster"}),
+			want:  Classification{Category: CategoryCopy, Risk: RiskLow},
+		},
+		{
+			name: "rewrite",
+			group: group(body, gdocs.SuggestionChange{Type: "replace",
+				OriginalText: "Ubuntu is the most popular Linux distribution in the cloud",
+				NewText:      "Ubuntu runs more cloud workloads than any other Linux distribution"}),
+			want: Classification{Category: CategoryCopy, Risk: RiskMedium, Reason: "changes 20 words"},
+		},
+		{
+			name:  "legal term",
+			group: group(body, gdocs.SuggestionChange{Type: "insert", NewText: " Read our Privacy notice."}),
+			want:  Classification{Category: CategoryLegal, Risk: RiskHigh, Reason: `mentions "Privacy"`},
+		},
+		{
+			name:  "metadata",
+			group: group(gdocs.SuggestionLocation{InMetadata: true, MetadataTable: "SEO"}, gdocs.SuggestionChange{Type: "replace", OriginalText: "a", NewText: "b"}),
+			want:  Classification{Category: CategorySEO, Risk: RiskMedium, Reason: "SEO metadata table"},
+		},
+		{
+			name:  "link",
+			group: group(body, gdocs.SuggestionChange{Type: "style", OriginalLinkURL: "/aws", NewLinkURL: "/cloud/aws"}),
+			want:  Classification{Category: CategorySEO, Risk: RiskMedium, Reason: "changes a link"},
+		},
+		{
+			name:  "move",
+			group: group(body, gdocs.SuggestionChange{Type: "style"}, gdocs.SuggestionChange{Type: "move", NewText: "Pricing"}),
+			want:  Classification{Category: CategoryStructural, Risk: RiskHigh, Reason: "moves text"},
+		},
+		{
+			name:  "new paragraph",
+			group: group(body, gdocs.SuggestionChange{Type: "insert", NewText: "Support\n", Paragraph: true}),
+			want:  Classification{Category: CategoryStructural, Risk: RiskMedium, Reason: "changes whole paragraphs"},
+		},
+		{
+			name:  "formatting",
+			group: group(body, gdocs.SuggestionChange{Type: "style", OriginalText: "free"}),
+			want:  Classification{Category: CategoryStructural, Risk: RiskLow, Reason: "changes formatting"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, Classify(tt.group)); diff != "" {
+				t.Errorf("Classify() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	routes, err := ParseRoutes([]string{"legal=review", " Structural = Review", "high=skip"})
+	if err != nil {
+		t.Fatalf("ParseRoutes() error = %v", err)
+	}
+	tests := []struct {
+		classification Classification
+		want           Route
+	}{
+		{Classification{Category: CategoryCopy, Risk: RiskLow}, RouteApply},
+		{Classification{Category: CategoryLegal, Risk: RiskMedium}, RouteReview},
+		{Classification{Category: CategoryStructural, Risk: RiskHigh}, RouteSkip},
+	}
+	for _, tt := range tests {
+		if got := routes.Route(tt.classification); got != tt.want {
+			t.Errorf("Route(%+v) = %s, want %s", tt.classification, got, tt.want)
+		}
+	}
+
+	for _, entries := range [][]string{{"legal"}, {"pricing=review"}, {"legal=approve"}} {
+		if _, err := ParseRoutes(entries); err == nil {
+			t.Errorf("ParseRoutes(%q) expected an error", entries)
+		}
+	}
+}
+
+func TestRefine(t *testing.T) {
+	groups := []gdocs.LocationGroupedSuggestions{
+		group(gdocs.SuggestionLocation{Section: "Body"}, gdocs.SuggestionChange{Type: "replace", OriginalText: "$10", NewText: "$12"}),
+		group(gdocs.SuggestionLocation{Section: "Body"}, gdocs.SuggestionChange{Type: "replace", OriginalText: "fast", NewText: "faster"}),
+	}
+	routes := Routes{"legal": RouteReview}
+	report := ClassifyGroups(groups, routes)
+
+	assistant := AskFunc(func(ctx context.Context, prompt string) (string, error) {
+		return "Here you go:\n```json\n" + `[{"group": 1, "category": "legal", "risk": "high", "reason": "pricing commitment"},
+			{"group": 2, "category": "unknown", "risk": "low"}]` + "\n```", nil
+	})
+	if err := Refine(context.Background(), assistant, groups, report, routes); err != nil {
+		t.Fatalf("Refine() error = %v", err)
+	}
+
+	want := []Entry{
+		{SuggestionIDs: []string{"a"}, Classification: Classification{Category: CategoryLegal, Risk: RiskHigh, Reason: "pricing commitment", Route: RouteReview}},
+		{SuggestionIDs: []string{"a"}, Classification: Classification{Category: CategoryCopy, Risk: RiskLow, Route: RouteApply}},
+	}
+	if diff := cmp.Diff(want, report.Entries); diff != "" {
+		t.Errorf("Entries mismatch (-want +got):\n%s", diff)
+	}
+	if report.Mode != ModeLLM {
+		t.Errorf("Mode = %q, want %q", report.Mode, ModeLLM)
+	}
+}
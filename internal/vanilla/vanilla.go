@@ -0,0 +1,227 @@
+// Package vanilla detects the Vanilla Framework patterns around the anchors
+// of suggestions in the templates of the target repository, so the chunk
+// prompts can point Copilot at the patterns it is editing.
+package vanilla
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"bauer/internal/gdocs"
+	"bauer/internal/staleness"
+)
+
+// Pattern is a Vanilla pattern, recognized by the Jinja macro rendering it or
+// the classes of its markup.
+type Pattern struct {
+	Name string
+
+	// Heading is the pattern's section of the patterns reference of the prompts
+	Heading string
+
+	Macros  []string
+	Classes []string
+}
+
+// Patterns are the patterns detected, as documented in the patterns reference.
+var Patterns = []Pattern{
+	{Name: "hero", Heading: "Hero pattern", Macros: []string{"vf_hero"}, Classes: []string{"p-section--hero"}},
+	{Name: "equal-heights", Heading: "Equal heights", Macros: []string{"vf_equal_heights"}, Classes: []string{"p-equal-height-row"}},
+	{Name: "text-spotlight", Heading: "Text Spotlight", Macros: []string{"vf_text_spotlight"}},
+	{Name: "logo-section", Heading: "Logo section (aka logo cloud)", Macros: []string{"vf_logo_section"}, Classes: []string{"p-logo-section"}},
+	{Name: "tabs", Heading: "Tab section", Macros: []string{"vf_tab_section"}, Classes: []string{"p-tabs"}},
+	{Name: "tiered-list", Heading: "Tiered list", Macros: []string{"vf_tiered_list"}},
+	{Name: "basic-section", Heading: "Basic section", Macros: []string{"vf_basic_section"}},
+}
+
+// Lookup returns the pattern called name, or nil.
+func Lookup(name string) *Pattern {
+	for i := range Patterns {
+		if Patterns[i].Name == name {
+			return &Patterns[i]
+		}
+	}
+	return nil
+}
+
+const (
+	// minProbeLength is the shortest anchor text looked for in a template
+	minProbeLength = 8
+
+	// probeWords is how many words of the anchor are looked for at first;
+	// longer text is more likely to be split by markup
+	probeWords = 6
+)
+
+// tokenPattern matches the Jinja call blocks, expressions and HTML tags that
+// open and close the elements around an anchor.
+var tokenPattern = regexp.MustCompile(`(?s)\{%-?\s*(call|endcall)\b(.*?)-?%\}|\{\{(.*?)\}\}|<(/?)([a-zA-Z][a-zA-Z0-9-]*)((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+
+// voidElements never have a closing tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// element is an open element or call block around the anchor.
+type element struct {
+	tag     string // HTML tag name, or "call"
+	pattern string
+}
+
+// Detect returns the names of the patterns whose markup encloses offset in a
+// template, outermost first.
+func Detect(content string, offset int) []string {
+	var stack []element
+	var inside []string
+	for _, m := range tokenPattern.FindAllStringSubmatchIndex(content, -1) {
+		if m[0] >= offset {
+			break
+		}
+		token := func(group int) string {
+			if m[2*group] < 0 {
+				return ""
+			}
+			return content[m[2*group]:m[2*group+1]]
+		}
+
+		switch {
+		case token(1) == "call":
+			stack = append(stack, element{tag: "call", pattern: macroPattern(token(2))})
+			if m[1] > offset {
+				// The anchor is in the arguments of the call, e.g. title_text
+				inside = append(inside, stack[len(stack)-1].pattern)
+			}
+		case token(1) == "endcall":
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].tag == "call" {
+					stack = stack[:i]
+					break
+				}
+			}
+		case m[6] >= 0:
+			if m[1] > offset {
+				inside = append(inside, macroPattern(token(3)))
+			}
+		case token(4) == "/":
+			name := strings.ToLower(token(5))
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].tag == name {
+					stack = stack[:i]
+					break
+				}
+			}
+		default:
+			name := strings.ToLower(token(5))
+			attrs := token(6)
+			if voidElements[name] || strings.HasSuffix(strings.TrimSpace(attrs), "/") {
+				continue
+			}
+			stack = append(stack, element{tag: name, pattern: classPattern(attrs)})
+		}
+	}
+
+	var names []string
+	add := func(name string) {
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, el := range stack {
+		add(el.pattern)
+	}
+	for _, name := range inside {
+		add(name)
+	}
+	return names
+}
+
+// DetectGroups records the patterns around the anchors of each location
+// group in its resolved file in repoRoot. It returns the number of groups
+// with patterns.
+func DetectGroups(repoRoot string, groups []gdocs.LocationGroupedSuggestions) int {
+	contents := map[string]string{}
+	detected := 0
+	for i := range groups {
+		group := &groups[i]
+		if group.ResolvedFile == "" || group.Location.InMetadata {
+			continue
+		}
+		content, ok := contents[group.ResolvedFile]
+		if !ok {
+			data, err := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(group.ResolvedFile)))
+			if err == nil {
+				content = string(data)
+			}
+			contents[group.ResolvedFile] = content
+		}
+
+		group.Patterns = nil
+		for _, sugg := range group.Suggestions {
+			offset := findAnchor(content, staleness.AnchorProbe(sugg))
+			if offset < 0 {
+				continue
+			}
+			for _, name := range Detect(content, offset) {
+				if !slices.Contains(group.Patterns, name) {
+					group.Patterns = append(group.Patterns, name)
+				}
+			}
+		}
+		if len(group.Patterns) > 0 {
+			detected++
+		}
+	}
+	return detected
+}
+
+// findAnchor returns the offset of anchor text in a template, or -1. Markup
+// can split the text, so its first words are looked for, fewer until found.
+func findAnchor(content, probe string) int {
+	words := strings.Fields(probe)
+	for n := min(len(words), probeWords); n > 0; n-- {
+		text := strings.Join(words[:n], " ")
+		if len(text) < minProbeLength {
+			break
+		}
+		if offset := strings.Index(content, text); offset >= 0 {
+			return offset
+		}
+	}
+	return -1
+}
+
+// macroPattern returns the pattern rendered by a Jinja call or expression.
+func macroPattern(code string) string {
+	for _, p := range Patterns {
+		for _, macro := range p.Macros {
+			if strings.Contains(code, macro+"(") {
+				return p.Name
+			}
+		}
+	}
+	return ""
+}
+
+// classAttr matches the class attribute of a tag.
+var classAttr = regexp.MustCompile(`(?i)\bclass\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// classPattern returns the pattern of an element, by its classes.
+func classPattern(attrs string) string {
+	m := classAttr.FindStringSubmatch(attrs)
+	if m == nil {
+		return ""
+	}
+	classes := strings.Fields(m[1] + " " + m[2])
+	for _, p := range Patterns {
+		for _, class := range p.Classes {
+			if slices.Contains(classes, class) {
+				return p.Name
+			}
+		}
+	}
+	return ""
+}
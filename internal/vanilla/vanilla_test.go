@@ -0,0 +1,86 @@
+package vanilla
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bauer/internal/gdocs"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const template = `{% from "_macros/vf_hero.jinja" import vf_hero %}
+{% call(slot) vf_hero(title_text="Ubuntu for the enterprise", layout="50/50") %}
+  {%- if slot == 'description' -%}
+    <p>Secure and supported for ten years.</p>
+  {%- endif -%}
+{% endcall %}
+<section class="p-section">
+  <div class="p-equal-height-row">
+    <div class="p-equal-height-row__col">
+      <img src="a.png" alt="">
+      <p>Fast deployment at scale</p>
+    </div>
+  </div>
+  <div class="p-tabs">
+    <button class="p-tabs__item">Overview of the platform</button>
+  </div>
+</section>
+<p>Contact us to learn more</p>
+`
+
+func TestDetect(t *testing.T) {
+	for _, tt := range []struct {
+		anchor string
+		want   []string
+	}{
+		{"Ubuntu for the enterprise", []string{"hero"}},
+		{"Secure and supported", []string{"hero"}},
+		{"Fast deployment", []string{"equal-heights"}},
+		{"Overview of the platform", []string{"tabs"}},
+		{"Contact us", nil},
+	} {
+		got := Detect(template, strings.Index(template, tt.anchor))
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("Detect(%q) mismatch (-want +got):\n%s", tt.anchor, diff)
+		}
+	}
+}
+
+func TestDetectGroups(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "templates", "index.html"), []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	suggestion := func(original string) gdocs.GroupedActionableSuggestion {
+		return gdocs.GroupedActionableSuggestion{Change: gdocs.SuggestionChange{OriginalText: original}}
+	}
+	groups := []gdocs.LocationGroupedSuggestions{
+		{ResolvedFile: "templates/index.html", Suggestions: []gdocs.GroupedActionableSuggestion{
+			suggestion("Fast deployment at scale and with ease"),
+			suggestion("Overview of the platform"),
+		}},
+		{ResolvedFile: "templates/index.html", Suggestions: []gdocs.GroupedActionableSuggestion{
+			suggestion("Contact us to learn more"),
+		}},
+		{Suggestions: []gdocs.GroupedActionableSuggestion{suggestion("Overview of the platform")}},
+	}
+
+	if got := DetectGroups(dir, groups); got != 1 {
+		t.Errorf("DetectGroups() = %d, want 1", got)
+	}
+	var got [][]string
+	for _, group := range groups {
+		got = append(got, group.Patterns)
+	}
+	want := [][]string{{"equal-heights", "tabs"}, nil, nil}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DetectGroups() patterns mismatch (-want +got):\n%s", diff)
+	}
+}
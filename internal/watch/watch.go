@@ -0,0 +1,156 @@
+// Package watch polls a Google Doc and runs Bauer whenever it gains suggestions
+// that weren't there on the previous run.
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StateFile is the default name of the file the watcher keeps its cursor in.
+const StateFile = "bauer-watch-state.json"
+
+// State is the cursor persisted between polls, so a restarted watcher doesn't
+// re-run for suggestions it has already handled.
+type State struct {
+	DocID         string    `json:"doc_id"`
+	RevisionID    string    `json:"revision_id"`
+	SuggestionIDs []string  `json:"suggestion_ids"`
+	LastChecked   time.Time `json:"last_checked"`
+	LastRun       time.Time `json:"last_run,omitempty"`
+}
+
+// LoadState reads the state file. A missing file yields an empty state.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watch state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse watch state: %w", err)
+	}
+	return &state, nil
+}
+
+// Save writes the state file.
+func (s *State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal watch state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write watch state: %w", err)
+	}
+	return nil
+}
+
+// FetchFunc returns the current revision of a document and the IDs of its suggestions.
+// Implemented by gdocs.Client.FetchSuggestionIDs.
+type FetchFunc func(ctx context.Context, docID string) (revisionID string, suggestionIDs []string, err error)
+
+// RunFunc processes the document, e.g. by running the Bauer workflow.
+type RunFunc func(ctx context.Context, docID string) error
+
+// Watcher polls a document and calls Run when it has new suggestions.
+type Watcher struct {
+	DocID     string
+	Interval  time.Duration
+	StatePath string
+	Fetch     FetchFunc
+	Run       RunFunc
+}
+
+// Watch polls until ctx is cancelled. Errors from a single poll are logged and
+// retried on the next one.
+func (w *Watcher) Watch(ctx context.Context) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.Poll(ctx); err != nil {
+			slog.Warn("watch: poll failed", slog.String("doc_id", w.DocID), slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks the document once and runs Bauer if it has suggestions that
+// weren't seen on the last successful run. Returns whether a run happened.
+// The cursor only advances after a successful run, so failed runs are retried.
+func (w *Watcher) Poll(ctx context.Context) (bool, error) {
+	state, err := LoadState(w.StatePath)
+	if err != nil {
+		return false, err
+	}
+	if state.DocID != w.DocID {
+		// The state belongs to another document; start over
+		state = &State{DocID: w.DocID}
+	}
+
+	revisionID, ids, err := w.Fetch(ctx, w.DocID)
+	if err != nil {
+		return false, err
+	}
+	state.LastChecked = time.Now()
+
+	newIDs := NewSuggestions(state.SuggestionIDs, ids)
+	if revisionID == state.RevisionID || len(newIDs) == 0 {
+		// Keep the cursor in step with suggestions that were accepted or rejected meanwhile
+		state.RevisionID = revisionID
+		state.SuggestionIDs = ids
+		slog.Info("watch: no new suggestions", slog.String("doc_id", w.DocID), slog.Int("suggestions", len(ids)))
+		return false, state.Save(w.StatePath)
+	}
+
+	slog.Info("watch: new suggestions found, running Bauer",
+		slog.String("doc_id", w.DocID),
+		slog.Int("new", len(newIDs)),
+		slog.Int("total", len(ids)),
+	)
+	if err := w.Run(ctx, w.DocID); err != nil {
+		if saveErr := state.Save(w.StatePath); saveErr != nil {
+			slog.Warn("watch: failed to save state", slog.String("error", saveErr.Error()))
+		}
+		return false, fmt.Errorf("failed to process document: %w", err)
+	}
+
+	state.RevisionID = revisionID
+	state.SuggestionIDs = ids
+	state.LastRun = time.Now()
+	return true, state.Save(w.StatePath)
+}
+
+// NewSuggestions returns the IDs in current that aren't in seen.
+func NewSuggestions(seen, current []string) []string {
+	known := make(map[string]bool, len(seen))
+	for _, id := range seen {
+		known[id] = true
+	}
+
+	var added []string
+	for _, id := range current {
+		if !known[id] {
+			added = append(added, id)
+		}
+	}
+	return added
+}
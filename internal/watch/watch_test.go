@@ -0,0 +1,64 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWatcherPoll(t *testing.T) {
+	revision, ids := "rev-1", []string{"a", "b"}
+	var runs int
+	var runErr error
+
+	w := &Watcher{
+		DocID:     "doc-1",
+		StatePath: filepath.Join(t.TempDir(), StateFile),
+		Fetch: func(ctx context.Context, docID string) (string, []string, error) {
+			return revision, ids, nil
+		},
+		Run: func(ctx context.Context, docID string) error {
+			runs++
+			return runErr
+		},
+	}
+	ctx := context.Background()
+
+	if ran, err := w.Poll(ctx); err != nil || !ran {
+		t.Fatalf("First poll: ran=%v err=%v, expected a run", ran, err)
+	}
+	if ran, _ := w.Poll(ctx); ran {
+		t.Error("Expected no run when the document hasn't changed")
+	}
+
+	// A new revision that only removes suggestions doesn't trigger a run
+	revision, ids = "rev-2", []string{"a"}
+	if ran, _ := w.Poll(ctx); ran {
+		t.Error("Expected no run when suggestions were only removed")
+	}
+
+	// A failed run keeps the cursor, so the next poll retries
+	revision, ids = "rev-3", []string{"a", "c"}
+	runErr = errors.New("boom")
+	if _, err := w.Poll(ctx); err == nil {
+		t.Error("Expected the run error to be returned")
+	}
+	runErr = nil
+	if ran, err := w.Poll(ctx); err != nil || !ran {
+		t.Errorf("Retry poll: ran=%v err=%v, expected a run", ran, err)
+	}
+	if runs != 3 {
+		t.Errorf("Expected 3 runs, got %d", runs)
+	}
+
+	state, err := LoadState(w.StatePath)
+	if err != nil {
+		t.Fatalf("LoadState() failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "c"}, state.SuggestionIDs); diff != "" || state.RevisionID != "rev-3" {
+		t.Errorf("Unexpected state (-want +got):\n%s revision=%s", diff, state.RevisionID)
+	}
+}
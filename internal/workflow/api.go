@@ -1,12 +1,14 @@
 package workflow
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
 
+	"bauer/internal/github"
 	"bauer/internal/orchestrator"
 )
 
@@ -16,6 +18,7 @@ type APIRequest struct {
 	GitHubRepo   string `json:"github_repo" binding:"required"`  // "owner/repo" or HTTPS URL
 	GitHubToken  string `json:"github_token" binding:"required"` // Personal access token
 	BranchPrefix string `json:"branch_prefix" default:"bauer"`   // Branch naming prefix
+	GitHubHost   string `json:"github_host"`                     // GitHub Enterprise Server host (optional)
 
 	// Bauer configuration
 	DocID       string `json:"doc_id" binding:"required"`         // Google Doc ID
@@ -26,10 +29,58 @@ type APIRequest struct {
 	Model       string `json:"model" default:"gpt-5-mini-high"`   // Copilot model
 	DryRun      bool   `json:"dry_run" default:"false"`           // Dry run mode
 
+	SkipCodeOwnerReviews bool `json:"skip_code_owner_reviews" default:"false"` // Don't request CODEOWNERS reviews
+	IncludeComments      bool `json:"include_comments" default:"false"`        // Treat unresolved comments as suggestions
+
+	Reviewers   []string          `json:"reviewers"`    // GitHub handles or org/team to request reviews from
+	Assignees   []string          `json:"assignees"`    // GitHub handles to assign the PR to
+	Labels      []string          `json:"labels"`       // Labels of the PR
+	Milestone   string            `json:"milestone"`    // Milestone of the PR, by name
+	ReviewerMap map[string]string `json:"reviewer_map"` // Emails of document authors to GitHub handles, to request their reviews
+
+	CommentOnDoc bool `json:"comment_on_doc" default:"false"` // Comment on the document with the PR link
+
+	ScreenshotServer  string `json:"screenshot_server"`  // Command starting the site's dev server, to add screenshots to the PR
+	ScreenshotURL     string `json:"screenshot_url"`     // Where the dev server serves the site (default http://localhost:8001)
+	ScreenshotBrowser string `json:"screenshot_browser"` // Chrome or Chromium binary (default: found on PATH)
+
+	ValidateCommand string `json:"validate_command"`             // Command checking the changes before they are pushed, e.g. "yarn build"
+	ValidateRetries int    `json:"validate_retries" default:"0"` // Sessions asked to fix the errors of a failing validation
+
+	SkipTemplateCheck bool `json:"skip_template_check" default:"false"` // Don't check changed HTML templates for damage
+
 	// Local repository path
 	LocalRepoPath string `json:"local_repo_path" default:"/tmp"` // Where to clone (optional)
+
+	ShallowClone bool     `json:"shallow_clone" default:"false"` // Clone only the latest commit
+	SparsePaths  []string `json:"sparse_paths"`                  // Only check out these directories, e.g. ["templates"]
+}
+
+// Redacted returns the request without its GitHub token and credentials,
+// e.g. to be stored.
+func (r APIRequest) Redacted() APIRequest {
+	if r.GitHubToken != "" {
+		r.GitHubToken = "REDACTED"
+	}
+	if r.Credentials != "" {
+		r.Credentials = "REDACTED"
+	}
+	return r
 }
 
+// RunRecorder records the workflow runs of the API, e.g. in a job store.
+type RunRecorder interface {
+	// StartRun records a run as it starts and returns its ID.
+	StartRun(ctx context.Context, req APIRequest) string
+
+	// FinishRun records the output of a run, or the error it failed with.
+	FinishRun(ctx context.Context, id string, output *WorkflowOutput, err error)
+}
+
+// Authorizer decides whether the caller of ctx may run a workflow request,
+// e.g. on the request's repository.
+type Authorizer func(ctx context.Context, req APIRequest) error
+
 // APIResponse represents the API response from workflow execution
 type APIResponse struct {
 	Status    string          `json:"status"` // "success", "partial", "failed"
@@ -39,8 +90,10 @@ type APIResponse struct {
 	Timestamp time.Time       `json:"timestamp"`
 }
 
-// ExecuteWorkflowHandler is an HTTP handler for executing the complete workflow
-func ExecuteWorkflowHandler(orch orchestrator.Orchestrator) http.HandlerFunc {
+// ExecuteWorkflowHandler is an HTTP handler for executing the complete workflow.
+// Runs are recorded with recorder, and requests refused by authorize are
+// answered with 403 Forbidden, when they are not nil.
+func ExecuteWorkflowHandler(orch orchestrator.Orchestrator, recorder RunRecorder, authorize Authorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		logger := slog.Default()
 
@@ -74,6 +127,12 @@ func ExecuteWorkflowHandler(orch orchestrator.Orchestrator) http.HandlerFunc {
 			writeError(w, http.StatusBadRequest, "credentials is required")
 			return
 		}
+		if authorize != nil {
+			if err := authorize(r.Context(), req); err != nil {
+				writeError(w, http.StatusForbidden, err.Error())
+				return
+			}
+		}
 
 		// Set defaults
 		if req.BranchPrefix == "" {
@@ -96,6 +155,7 @@ func ExecuteWorkflowHandler(orch orchestrator.Orchestrator) http.HandlerFunc {
 		input := WorkflowInput{
 			GitHubRepo:    req.GitHubRepo,
 			GitHubToken:   req.GitHubToken,
+			GitHubHost:    req.GitHubHost,
 			BranchPrefix:  req.BranchPrefix,
 			DocID:         req.DocID,
 			Credentials:   req.Credentials,
@@ -105,6 +165,23 @@ func ExecuteWorkflowHandler(orch orchestrator.Orchestrator) http.HandlerFunc {
 			Model:         req.Model,
 			DryRun:        req.DryRun,
 			LocalRepoPath: fmt.Sprintf("%s/%s-%d", req.LocalRepoPath, "bauer-workflow", time.Now().Unix()),
+
+			SkipCodeOwnerReviews: req.SkipCodeOwnerReviews,
+			Reviewers:            req.Reviewers,
+			Assignees:            req.Assignees,
+			Labels:               req.Labels,
+			Milestone:            req.Milestone,
+			ReviewerMap:          github.NewHandleMap(req.ReviewerMap),
+			CommentOnDoc:         req.CommentOnDoc,
+			ScreenshotServer:     req.ScreenshotServer,
+			ScreenshotURL:        req.ScreenshotURL,
+			ScreenshotBrowser:    req.ScreenshotBrowser,
+			ValidateCommand:      req.ValidateCommand,
+			ValidateRetries:      req.ValidateRetries,
+			SkipTemplateCheck:    req.SkipTemplateCheck,
+			IncludeComments:      req.IncludeComments,
+			ShallowClone:         req.ShallowClone,
+			SparsePaths:          req.SparsePaths,
 		}
 
 		logger.Info("workflow API request",
@@ -115,7 +192,14 @@ func ExecuteWorkflowHandler(orch orchestrator.Orchestrator) http.HandlerFunc {
 
 		// Execute workflow
 		ctx := r.Context()
+		var runID string
+		if recorder != nil {
+			runID = recorder.StartRun(ctx, req)
+		}
 		workflowOutput, err := ExecuteWorkflow(ctx, input, orch)
+		if recorder != nil {
+			recorder.FinishRun(ctx, runID, workflowOutput, err)
+		}
 
 		// Build response
 		response := APIResponse{
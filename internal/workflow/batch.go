@@ -0,0 +1,159 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"bauer/internal/copilotcli"
+	"bauer/internal/orchestrator"
+)
+
+// BatchSummaryFile is the combined summary written when several documents are processed.
+const BatchSummaryFile = "bauer-batch-summary.json"
+
+// BatchDocumentResult summarises the workflow run of a single document in a batch
+type BatchDocumentResult struct {
+	DocID            string            `json:"doc_id"`
+	Status           string            `json:"status"`
+	OutputDir        string            `json:"output_dir"`
+	BranchName       string            `json:"branch_name"`
+	PullRequestURL   string            `json:"pull_request_url,omitempty"`
+	TotalSuggestions int               `json:"total_suggestions"`
+	Usage            *copilotcli.Usage `json:"usage,omitempty"`
+	Duration         time.Duration     `json:"duration"`
+	Errors           []string          `json:"errors"`
+}
+
+// BatchOutput is the combined result of processing several documents
+type BatchOutput struct {
+	Status        string                `json:"status"` // "success", "partial", "failed"
+	OutputDir     string                `json:"output_dir"`
+	Documents     []BatchDocumentResult `json:"documents"`
+	StartTime     time.Time             `json:"start_time"`
+	EndTime       time.Time             `json:"end_time"`
+	TotalDuration time.Duration         `json:"total_duration"`
+
+	// SinglePR is set for batches whose documents went to one pull request,
+	// on BranchName; see ExecuteCombined
+	SinglePR       bool   `json:"single_pr,omitempty"`
+	BranchName     string `json:"branch_name,omitempty"`
+	PullRequestURL string `json:"pull_request_url,omitempty"`
+}
+
+// ExecuteBatch runs the complete workflow once per document. Each document gets its
+// own output directory and branch; a failure in one document doesn't stop the others.
+// The combined summary is written to BatchSummaryFile in input.OutputDir.
+func ExecuteBatch(ctx context.Context, input WorkflowInput, docIDs []string, orch orchestrator.Orchestrator) (*BatchOutput, error) {
+	logger := slog.Default()
+	batch := &BatchOutput{
+		OutputDir: input.OutputDir,
+		StartTime: time.Now(),
+		Documents: []BatchDocumentResult{},
+	}
+
+	for i, docID := range docIDs {
+		logger.Info("batch: processing document",
+			"doc_id", docID,
+			"index", i+1,
+			"total", len(docIDs),
+		)
+
+		docInput := input
+		docInput.DocID = docID
+		docInput.OutputDir = filepath.Join(input.OutputDir, docID)
+		// Keep branch names unique when several documents start within the same second
+		docInput.BranchPrefix = fmt.Sprintf("%s/%s", input.BranchPrefix, shortDocID(docID))
+
+		docResult := BatchDocumentResult{
+			DocID:     docID,
+			OutputDir: docInput.OutputDir,
+			Errors:    []string{},
+		}
+
+		output, err := ExecuteWorkflow(ctx, docInput, orch)
+		if output != nil {
+			docResult.Status = output.Status
+			docResult.BranchName = output.RepositoryInfo.BranchName
+			docResult.PullRequestURL = output.FinalizationInfo.PullRequest.URL
+			docResult.TotalSuggestions = output.BauerResult.TotalSuggestions
+			docResult.Usage = output.BauerResult.Usage
+			docResult.Duration = output.TotalDuration
+			docResult.Errors = append(docResult.Errors, output.Errors...)
+		}
+		if err != nil {
+			docResult.Status = "failed"
+			if output == nil {
+				docResult.Errors = append(docResult.Errors, err.Error())
+			}
+			logger.Warn("batch: document failed", "doc_id", docID, "error", err)
+		}
+
+		batch.Documents = append(batch.Documents, docResult)
+	}
+
+	batch.EndTime = time.Now()
+	batch.TotalDuration = batch.EndTime.Sub(batch.StartTime)
+	batch.Status = batchStatus(batch.Documents)
+
+	if err := writeBatchSummary(input.OutputDir, batch); err != nil {
+		return batch, err
+	}
+
+	logger.Info("batch: complete",
+		"status", batch.Status,
+		"documents", len(batch.Documents),
+		"duration", batch.TotalDuration,
+	)
+
+	return batch, nil
+}
+
+// batchStatus is "success" when every document succeeded, "failed" when none did
+// and "partial" otherwise
+func batchStatus(docs []BatchDocumentResult) string {
+	succeeded := 0
+	for _, doc := range docs {
+		if doc.Status == "success" {
+			succeeded++
+		}
+	}
+
+	switch succeeded {
+	case len(docs):
+		return "success"
+	case 0:
+		return "failed"
+	default:
+		return "partial"
+	}
+}
+
+func writeBatchSummary(outputDir string, batch *BatchOutput) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(batch, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch summary: %w", err)
+	}
+
+	path := filepath.Join(outputDir, BatchSummaryFile)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write batch summary: %w", err)
+	}
+	return nil
+}
+
+// shortDocID shortens a document ID for use in branch names
+func shortDocID(docID string) string {
+	if len(docID) > 8 {
+		return docID[:8]
+	}
+	return docID
+}
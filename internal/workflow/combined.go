@@ -0,0 +1,265 @@
+package workflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"bauer/internal/github"
+	"bauer/internal/orchestrator"
+)
+
+// combinedDocument is a document of a single-PR batch, once processed.
+type combinedDocument struct {
+	docID     string
+	input     WorkflowInput
+	outputDir string
+	result    *orchestrator.OrchestrationResult
+	commit    string
+}
+
+// ExecuteCombined runs several documents targeting the same repository into
+// a single pull request, e.g. the pages of a campaign: the repository is set
+// up once, each document is processed in turn on the same branch and its
+// changes committed on their own, and one PR groups the commits of all of
+// them. A document whose changes fail validation or go over a limit stops
+// the batch without pushing anything; its changes are left in the local
+// repository to look into. The combined summary is written to
+// BatchSummaryFile in input.OutputDir, as for ExecuteBatch.
+func ExecuteCombined(ctx context.Context, input WorkflowInput, docIDs []string, orch orchestrator.Orchestrator) (*BatchOutput, error) {
+	logger := slog.Default()
+	batch := &BatchOutput{
+		OutputDir: input.OutputDir,
+		StartTime: time.Now(),
+		Documents: []BatchDocumentResult{},
+		SinglePR:  true,
+	}
+	finish := func(err error) (*BatchOutput, error) {
+		batch.EndTime = time.Now()
+		batch.TotalDuration = batch.EndTime.Sub(batch.StartTime)
+		batch.Status = batchStatus(batch.Documents)
+		if writeErr := writeBatchSummary(input.OutputDir, batch); writeErr != nil && err == nil {
+			err = writeErr
+		}
+		logger.Info("batch: complete",
+			"status", batch.Status,
+			"documents", len(batch.Documents),
+			"pull_request", batch.PullRequestURL,
+			"duration", batch.TotalDuration,
+		)
+		return batch, err
+	}
+	if input.GitHubRepo == "" {
+		return finish(fmt.Errorf("a single pull request of several documents needs their repository"))
+	}
+
+	var credentialsPath string
+	if input.Credentials != "" {
+		absPath, err := filepath.Abs(input.Credentials)
+		if err != nil {
+			return finish(fmt.Errorf("failed to resolve credentials path: %w", err))
+		}
+		credentialsPath = absPath
+	}
+	docFiles := []string{input.File, input.Before, input.Replay, input.TemplateDir}
+	for i, path := range docFiles {
+		if path == "" || strings.HasPrefix(path, "git:") {
+			continue
+		}
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return finish(fmt.Errorf("failed to resolve document file path: %w", err))
+		}
+		docFiles[i] = absPath
+	}
+	if input.LocalRepoPath == "" {
+		input.LocalRepoPath = DefaultLocalRepoPath(input.GitHubRepo, input.GitHubHost)
+	}
+
+	// The branch is keyed by the documents, so that later runs of the same
+	// batch update its pull request
+	setupInput := input
+	setupInput.DocID = batchKey(docIDs)
+	setupOutput := &WorkflowOutput{StartTime: time.Now()}
+	githubSetupOutput, err := setupGitHub(setupInput, setupOutput, false)
+	if err != nil {
+		return finish(err)
+	}
+	batch.BranchName = githubSetupOutput.BranchName
+	repoPath, err := filepath.Abs(input.LocalRepoPath)
+	if err != nil {
+		return finish(fmt.Errorf("failed to resolve cloned repository path: %w", err))
+	}
+	outputDir := input.OutputDir
+	if !filepath.IsAbs(outputDir) {
+		outputDir = filepath.Join(repoPath, outputDir)
+	}
+
+	var docs []combinedDocument
+	var blocked error
+	for i, docID := range docIDs {
+		logger.Info("batch: processing document",
+			"doc_id", docID,
+			"index", i+1,
+			"total", len(docIDs),
+		)
+		doc := combinedDocument{docID: docID, input: input, outputDir: filepath.Join(outputDir, docID)}
+		doc.input.DocID = docID
+		doc.input.OutputDir = filepath.Join(input.OutputDir, docID)
+		docResult := BatchDocumentResult{
+			DocID:      docID,
+			OutputDir:  doc.input.OutputDir,
+			BranchName: githubSetupOutput.BranchName,
+			Errors:     []string{},
+		}
+		start := time.Now()
+
+		bauerCfg := bauerConfig(doc.input, credentialsPath, docFiles, repoPath, doc.outputDir)
+		result, err := orch.Execute(ctx, bauerCfg)
+		doc.result = result
+		docResult.Duration = time.Since(start)
+		if result != nil {
+			if result.Ledger != nil {
+				docResult.TotalSuggestions = len(result.Ledger.Entries)
+			}
+			if !result.Usage.IsZero() {
+				docResult.Usage = &result.Usage
+			}
+		}
+		if err != nil {
+			docResult.Errors = append(docResult.Errors, fmt.Sprintf("Bauer processing error: %v", err))
+			logger.Warn("batch: document failed", "doc_id", docID, "error", err)
+		}
+		if errors.Is(err, orchestrator.ErrValidationFailed) || errors.Is(err, orchestrator.ErrInvalidChunks) ||
+			errors.Is(err, orchestrator.ErrLimitExceeded) {
+			docResult.Status = "failed"
+			batch.Documents = append(batch.Documents, docResult)
+			blocked = fmt.Errorf("document %s: %w", docID, err)
+			break
+		}
+
+		// Each document is a commit of its own on the branch
+		doc.commit = fmt.Sprintf("Apply BAU suggestions from doc %s", docID)
+		switch err := github.CommitChanges(repoPath, doc.commit); {
+		case errors.Is(err, github.ErrNoChanges):
+			doc.commit = ""
+			logger.Info("batch: document made no changes", "doc_id", docID)
+		case err != nil:
+			doc.commit = ""
+			docResult.Errors = append(docResult.Errors, fmt.Sprintf("failed to commit changes: %v", err))
+		}
+		docResult.Status = "success"
+		if len(docResult.Errors) > 0 {
+			docResult.Status = "partial"
+		}
+		docs = append(docs, doc)
+		batch.Documents = append(batch.Documents, docResult)
+	}
+
+	if blocked != nil {
+		logger.Warn("batch: a document failed validation or a run limit, not pushing", "local_path", input.LocalRepoPath, "error", blocked)
+		for i := range batch.Documents {
+			batch.Documents[i].Status = "failed"
+		}
+		return finish(nil)
+	}
+	finalizeCombined(ctx, input, batch, githubSetupOutput, docs, repoPath, credentialsPath)
+	return finish(nil)
+}
+
+// finalizeCombined pushes the branch of a single-PR batch, opens or updates
+// its pull request, and comments on and records each document.
+func finalizeCombined(ctx context.Context, input WorkflowInput, batch *BatchOutput, githubSetupOutput *github.GitHubSetupOutput,
+	docs []combinedDocument, repoPath, credentialsPath string) {
+	logger := slog.Default()
+	if input.ScreenshotServer != "" {
+		logger.Warn("batch: screenshots aren't taken for a single pull request of several documents")
+	}
+
+	var pages []github.PageFiles
+	reviewers := append([]string{}, input.Reviewers...)
+	for _, doc := range docs {
+		if doc.result == nil {
+			continue
+		}
+		pages = append(pages, pageFiles(repoPath, doc.result.ExtractionResult, input.Sites)...)
+		for _, handle := range input.ReviewerMap.Handles(authorEmails(doc.result.ExtractionResult)) {
+			if !slices.Contains(reviewers, handle) {
+				reviewers = append(reviewers, handle)
+			}
+		}
+	}
+
+	finalizationOutput, _ := github.FinalizeGitHubPhase(github.GitHubFinalizationInput{
+		LocalRepoPath: input.LocalRepoPath,
+		BranchName:    githubSetupOutput.BranchName,
+		DefaultBranch: githubSetupOutput.DefaultBranch,
+		Host:          githubSetupOutput.Repo.Host,
+		Owner:         githubSetupOutput.Repo.Owner,
+		Repo:          githubSetupOutput.Repo.Name,
+		DryRun:        input.DryRun,
+		PRTitle:       fmt.Sprintf("Apply BAU suggestions from %d documents to %s", len(docs), githubSetupOutput.Repo.Name),
+		PRBody:        buildCombinedPRBody(docs),
+		Labels:        input.Labels,
+		Assignees:     input.Assignees,
+		Milestone:     input.Milestone,
+		Reviewers:     reviewers,
+
+		RequestCodeOwnerReviews: !input.SkipCodeOwnerReviews,
+		Pages:                   pages,
+		ExistingPR:              githubSetupOutput.ExistingPR,
+		Committed:               true,
+	})
+	prURL := finalizationOutput.PullRequest.URL
+	batch.PullRequestURL = prURL
+
+	for i, doc := range docs {
+		docResult := &batch.Documents[i]
+		docResult.PullRequestURL = prURL
+		docResult.Errors = append(docResult.Errors, finalizationOutput.Errors...)
+		if !finalizationOutput.BranchPushed {
+			docResult.Status = "failed"
+		} else if len(docResult.Errors) > 0 {
+			docResult.Status = "partial"
+		}
+
+		if input.CommentOnDoc && prURL != "" {
+			if err := commentOnDoc(ctx, doc.input, credentialsPath, prURL, finalizationOutput.PullRequest.Updated, doc.result); err != nil {
+				logger.Warn("batch: failed to comment on document", "doc_id", doc.docID, "error", err)
+			}
+		}
+		if err := recordPublished(doc.input, doc.result, finalizationOutput.BranchPushed, prURL); err != nil {
+			logger.Warn("batch: failed to update suggestion state", "doc_id", doc.docID, "error", err)
+		}
+		if doc.result != nil && doc.result.Report != nil {
+			doc.result.Report.Git = &orchestrator.GitReport{
+				Repository:         fmt.Sprintf("%s/%s/%s", githubSetupOutput.Repo.Host, githubSetupOutput.Repo.Owner, githubSetupOutput.Repo.Name),
+				Branch:             githubSetupOutput.BranchName,
+				BaseBranch:         githubSetupOutput.DefaultBranch,
+				CommitMessage:      doc.commit,
+				Pushed:             finalizationOutput.BranchPushed,
+				PullRequestURL:     prURL,
+				PullRequestNumber:  finalizationOutput.PullRequest.Number,
+				PullRequestUpdated: finalizationOutput.PullRequest.Updated,
+			}
+			if err := doc.result.Report.Save(doc.outputDir); err != nil {
+				logger.Warn("batch: failed to write run report", "doc_id", doc.docID, "error", err)
+			}
+		}
+	}
+}
+
+// batchKey identifies a set of documents in branch names, whatever their
+// order.
+func batchKey(docIDs []string) string {
+	sorted := slices.Sorted(slices.Values(docIDs))
+	sum := sha256.Sum256([]byte(strings.Join(sorted, ",")))
+	return "batch-" + hex.EncodeToString(sum[:])[:12]
+}
@@ -0,0 +1,92 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+
+	"bauer/internal/docsource"
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"bauer/internal/orchestrator"
+)
+
+// maxCommentSuggestions is the most suggestions listed in the comment on the
+// document; the PR has the full list.
+const maxCommentSuggestions = 30
+
+// commentOnDoc posts a comment on the Google Doc with the PR of the run and
+// the status of each suggestion.
+func commentOnDoc(ctx context.Context, input WorkflowInput, credentialsPath, prURL string, updated bool, result *orchestrator.OrchestrationResult) error {
+	if source := cmp.Or(input.Source, docsource.SourceGoogleDocs); input.Replay != "" || (source != docsource.SourceGoogleDocs && source != docsource.SourceRevisions) {
+		return fmt.Errorf("only Google Docs can be commented on")
+	}
+
+	client, err := gdocs.NewCommentClient(ctx, gdocs.ClientOptions{
+		Mode:            gdocs.CredentialsMode(input.CredentialsMode),
+		CredentialsPath: credentialsPath,
+		Retry:           gdocs.RetryPolicy{MaxAttempts: input.APIMaxAttempts},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize Google Docs client: %w", err)
+	}
+	_, err = client.PostComment(ctx, input.DocID, docComment(prURL, updated, result))
+	return err
+}
+
+// docComment renders the comment on the document: the PR link, the status
+// counts and the status of each suggestion, by the text it suggested. Drive
+// comments are plain text.
+func docComment(prURL string, updated bool, result *orchestrator.OrchestrationResult) string {
+	var sb strings.Builder
+	if updated {
+		fmt.Fprintf(&sb, "Bauer updated the pull request with the suggestions of this document: %s\n", prURL)
+	} else {
+		fmt.Fprintf(&sb, "Bauer opened a pull request with the suggestions of this document: %s\n", prURL)
+	}
+	if result == nil || result.Ledger == nil || len(result.Ledger.Entries) == 0 {
+		return sb.String()
+	}
+
+	changes := make(map[string]gdocs.SuggestionChange)
+	if result.ExtractionResult != nil {
+		for _, group := range result.ExtractionResult.GroupedSuggestions {
+			for _, sugg := range group.Suggestions {
+				changes[sugg.ID] = sugg.Change
+			}
+		}
+		if report := result.ExtractionResult.ConflictReport; report != nil {
+			for _, dropped := range report.Dropped {
+				changes[dropped.ID] = dropped.Change
+			}
+		}
+	}
+
+	fmt.Fprintf(&sb, "\n%d suggestions (%s)\n\n", len(result.Ledger.Entries), result.Ledger.Summary())
+	for i, entry := range result.Ledger.Entries {
+		if i == maxCommentSuggestions {
+			fmt.Fprintf(&sb, "…and %d more, listed in the pull request\n", len(result.Ledger.Entries)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "- %s: %s", suggestionLabel(entry, changes[entry.SuggestionID]), entry.Status)
+		if entry.Note != "" && entry.Status != ledger.StatusVerified {
+			fmt.Fprintf(&sb, " (%s)", entry.Note)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// suggestionLabel names a suggestion by the text it suggested, or the text it
+// deletes, as reviewers know it from the document.
+func suggestionLabel(entry *ledger.Entry, change gdocs.SuggestionChange) string {
+	text := shorten(cmp.Or(change.NewText, change.OriginalText))
+	if text == "" {
+		return entry.SuggestionID
+	}
+	if change.NewText == "" {
+		return "delete “" + text + "”"
+	}
+	return "“" + text + "”"
+}
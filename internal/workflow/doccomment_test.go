@@ -0,0 +1,55 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"bauer/internal/orchestrator"
+)
+
+func TestDocComment(t *testing.T) {
+	statusLedger := ledger.New("doc1")
+	statusLedger.Set("suggest.a", ledger.StatusVerified, "expected text found in templates/aws/index.html")
+	statusLedger.Set("suggest.b", ledger.StatusFailed, "anchor not found")
+	statusLedger.Set("suggest.c", ledger.StatusSkipped, "")
+
+	result := &orchestrator.OrchestrationResult{
+		Ledger: statusLedger,
+		ExtractionResult: &gdocs.ProcessingResult{
+			GroupedSuggestions: []gdocs.LocationGroupedSuggestions{{
+				Suggestions: []gdocs.GroupedActionableSuggestion{
+					{ID: "suggest.a", Change: gdocs.SuggestionChange{OriginalText: "AWS", NewText: "Amazon Web Services"}},
+					{ID: "suggest.b", Change: gdocs.SuggestionChange{OriginalText: "Learn more"}},
+				},
+			}},
+		},
+	}
+
+	got := docComment("https://github.com/canonical/ubuntu.com/pull/1", false, result)
+	want := "Bauer opened a pull request with the suggestions of this document: https://github.com/canonical/ubuntu.com/pull/1\n" +
+		"\n3 suggestions (failed: 1, skipped: 1, verified: 1)\n\n" +
+		"- “Amazon Web Services”: verified\n" +
+		"- delete “Learn more”: failed (anchor not found)\n" +
+		"- suggest.c: skipped\n"
+	if got != want {
+		t.Errorf("docComment() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := docComment("https://example.com/pull/2", true, nil); !strings.HasPrefix(got, "Bauer updated the pull request") {
+		t.Errorf("docComment() of updated PR = %q", got)
+	}
+}
+
+func TestDocComment_Truncated(t *testing.T) {
+	statusLedger := ledger.New("doc1")
+	for i := range maxCommentSuggestions + 5 {
+		statusLedger.Set(fmt.Sprintf("suggest.%d", i), ledger.StatusApplied, "")
+	}
+	got := docComment("https://example.com/pull/1", false, &orchestrator.OrchestrationResult{Ledger: statusLedger})
+	if !strings.HasSuffix(got, "…and 5 more, listed in the pull request\n") {
+		t.Errorf("docComment() doesn't end with the count of unlisted suggestions:\n%s", got)
+	}
+}
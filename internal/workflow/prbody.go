@@ -0,0 +1,241 @@
+package workflow
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"bauer/internal/orchestrator"
+)
+
+// snippetLength is the longest text shown for each side of a change in the
+// suggestion table.
+const snippetLength = 60
+
+// buildPRBody renders the PR description of a run: a link to the document,
+// the suggestion status, a collapsible table of the suggestions, those dropped
+// in conflicts, their triage and the files the run changed. result may be nil
+// when the run failed before extraction.
+func buildPRBody(docID string, result *orchestrator.OrchestrationResult) string {
+	return "Automated copy update changes from Bauer\n\n" + documentSection(docID, result)
+}
+
+// buildCombinedPRBody renders the PR description of the documents of a
+// single-PR batch, with a section per document.
+func buildCombinedPRBody(docs []combinedDocument) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Automated copy update changes from Bauer, for %d documents\n", len(docs))
+	for _, doc := range docs {
+		title := doc.docID
+		if doc.result != nil && doc.result.ExtractionResult != nil && doc.result.ExtractionResult.DocumentTitle != "" {
+			title = doc.result.ExtractionResult.DocumentTitle
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n", title)
+		sb.WriteString(documentSection(doc.docID, doc.result))
+	}
+	return sb.String()
+}
+
+// documentSection renders the part of a PR description about a document.
+func documentSection(docID string, result *orchestrator.OrchestrationResult) string {
+	var sb strings.Builder
+	var extraction *gdocs.ProcessingResult
+	if result != nil {
+		extraction = result.ExtractionResult
+	}
+	title := docID
+	if extraction != nil && extraction.DocumentTitle != "" {
+		title = extraction.DocumentTitle
+	}
+	fmt.Fprintf(&sb, "Document: [%s](%s)\n", escapeCell(title), gdocs.DocumentURL(docID))
+	if result == nil {
+		return sb.String()
+	}
+
+	if result.Ledger != nil {
+		if statusSection := result.Ledger.Markdown(); statusSection != "" {
+			sb.WriteString("\n" + statusSection)
+		}
+	}
+	if extraction != nil {
+		sb.WriteString(formatSuggestionTable(extraction.GroupedSuggestions, result.Ledger))
+		sb.WriteString(formatConflicts(extraction.ConflictReport))
+	}
+	if result.Triage != nil {
+		if triageSection := result.Triage.Markdown(); triageSection != "" {
+			sb.WriteString("\n### Triage\n\n" + triageSection)
+		}
+	}
+	if result.Diff != nil {
+		sb.WriteString(formatDiffStats(result.Diff))
+	} else {
+		sb.WriteString(formatModifiedFiles(result))
+	}
+	if validation := result.Validation; validation != nil && validation.Passed {
+		fmt.Fprintf(&sb, "\n### Validation\n\n`%s` passed", validation.Command)
+		if validation.Fixes > 0 {
+			fmt.Fprintf(&sb, " after %d fixing sessions", validation.Fixes)
+		}
+		sb.WriteString(".\n")
+	}
+	return sb.String()
+}
+
+// formatSuggestionTable lists every suggestion with its location, change and
+// status, folded so long documents don't bury the rest of the description.
+func formatSuggestionTable(groups []gdocs.LocationGroupedSuggestions, statusLedger *ledger.Ledger) string {
+	var rows []string
+	for _, group := range groups {
+		location := locationLabel(group.Location)
+		for _, sugg := range group.Suggestions {
+			status := "-"
+			if statusLedger != nil {
+				if entry := statusLedger.Get(sugg.ID); entry != nil {
+					status = string(entry.Status)
+				}
+			}
+			rows = append(rows, fmt.Sprintf("| `%s` | %s | %s | %s | %s |",
+				sugg.ID, escapeCell(location), sugg.Change.Type, changeSnippet(sugg.Change), status))
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n<details>\n<summary>%d suggestions</summary>\n\n", len(rows))
+	sb.WriteString("| Suggestion | Location | Type | Change | Status |\n| --- | --- | --- | --- | --- |\n")
+	for _, row := range rows {
+		sb.WriteString(row + "\n")
+	}
+	sb.WriteString("\n</details>\n")
+	return sb.String()
+}
+
+// formatConflicts lists the suggestions dropped for overlapping others.
+func formatConflicts(report *gdocs.ConflictReport) string {
+	if report == nil || len(report.Dropped) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n### Conflicts\n\n")
+	for _, dropped := range report.Dropped {
+		fmt.Fprintf(&sb, "- `%s` (%s) superseded by `%s`: %s\n",
+			dropped.ID, dropped.Reason, dropped.SupersededBy, changeSnippet(dropped.Change))
+	}
+	return sb.String()
+}
+
+// formatDiffStats lists the files the run changed with their added and
+// removed lines, marking those outside the pages of the document.
+func formatDiffStats(stats *orchestrator.DiffStats) string {
+	if len(stats.Files) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n### Files changed\n\n%d files, +%d -%d\n\n", len(stats.Files), stats.Added, stats.Removed)
+	sb.WriteString("| File | Added | Removed |\n| --- | --- | --- |\n")
+	for _, file := range stats.Files {
+		path := "`" + escapeCell(file.Path) + "`"
+		if slices.Contains(stats.Unexpected, file.Path) {
+			path += " (unexpected)"
+		}
+		if file.Binary {
+			fmt.Fprintf(&sb, "| %s | binary | binary |\n", path)
+			continue
+		}
+		fmt.Fprintf(&sb, "| %s | %d | %d |\n", path, file.Added, file.Removed)
+	}
+	if len(stats.Unexpected) > 0 {
+		sb.WriteString("\nFiles marked unexpected are outside the pages of the document; check they were meant to change.\n")
+	}
+	return sb.String()
+}
+
+// formatModifiedFiles lists the files the chunk reports say Copilot modified,
+// for runs whose changes couldn't be listed from git.
+func formatModifiedFiles(result *orchestrator.OrchestrationResult) string {
+	var files []string
+	for _, output := range result.CopilotOutputs {
+		report, err := ledger.ParseChunkReport(output.Output)
+		if err != nil {
+			continue
+		}
+		for _, file := range report.FilesModified {
+			if !slices.Contains(files, file) {
+				files = append(files, file)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
+	slices.Sort(files)
+
+	var sb strings.Builder
+	sb.WriteString("\n### Files modified by Copilot\n\n")
+	for _, file := range files {
+		fmt.Fprintf(&sb, "- `%s`\n", file)
+	}
+	return sb.String()
+}
+
+// locationLabel describes where in the document a suggestion is, e.g.
+// "Pricing (table)".
+func locationLabel(location gdocs.SuggestionLocation) string {
+	if location.InMetadata {
+		return "Metadata"
+	}
+	label := cmp.Or(location.ParentHeading, location.Section)
+	switch {
+	case location.InTable:
+		label += " (table)"
+	case location.List != nil:
+		label += " (list)"
+	}
+	if location.PageURL != "" {
+		label = location.PageURL + ": " + label
+	}
+	return label
+}
+
+// changeSnippet renders a change as "before → after", shortened to fit a
+// table cell.
+func changeSnippet(change gdocs.SuggestionChange) string {
+	before, after := snippet(change.OriginalText), snippet(change.NewText)
+	switch {
+	case before == "" && after == "":
+		return ""
+	case before == "":
+		return "→ " + after
+	case after == "":
+		return before + " →"
+	}
+	return before + " → " + after
+}
+
+// snippet quotes text for a table cell, shortened to snippetLength characters.
+func snippet(text string) string {
+	if text = shorten(text); text == "" {
+		return ""
+	}
+	return "“" + escapeCell(text) + "”"
+}
+
+// shorten collapses the whitespace of text and cuts it to snippetLength
+// characters.
+func shorten(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > snippetLength {
+		text = string(runes[:snippetLength-1]) + "…"
+	}
+	return text
+}
+
+// escapeCell keeps text from breaking a Markdown table or link.
+var escapeCell = strings.NewReplacer("|", "\\|", "[", "\\[", "]", "\\]", "<", "&lt;", ">", "&gt;").Replace
@@ -0,0 +1,99 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"bauer/internal/copilotcli"
+	"bauer/internal/gdocs"
+	"bauer/internal/ledger"
+	"bauer/internal/orchestrator"
+)
+
+func TestBuildPRBody(t *testing.T) {
+	statusLedger := ledger.New("doc1")
+	statusLedger.Set("suggest.a", ledger.StatusVerified, "expected text found in templates/aws/index.html")
+	statusLedger.Set("suggest.b", ledger.StatusSuperseded, "overlaps suggest.a")
+
+	result := &orchestrator.OrchestrationResult{
+		ExtractionResult: &gdocs.ProcessingResult{
+			DocumentTitle: "Ubuntu on AWS | Copy review",
+			GroupedSuggestions: []gdocs.LocationGroupedSuggestions{{
+				Location: gdocs.SuggestionLocation{Section: "Body", ParentHeading: "Pricing", InTable: true},
+				Suggestions: []gdocs.GroupedActionableSuggestion{{
+					ID:     "suggest.a",
+					Change: gdocs.SuggestionChange{Type: "replace", OriginalText: "Ubuntu on AWS", NewText: "Ubuntu on\nAmazon Web Services"},
+				}},
+			}},
+			ConflictReport: &gdocs.ConflictReport{Dropped: []gdocs.DroppedSuggestion{{
+				ID:           "suggest.b",
+				SupersededBy: "suggest.a",
+				Reason:       gdocs.ConflictNested,
+				Change:       gdocs.SuggestionChange{Type: "delete", OriginalText: "AWS"},
+			}}},
+		},
+		Ledger: statusLedger,
+		CopilotOutputs: []copilotcli.ChunkOutput{
+			{ChunkNumber: 1, Output: "```json\n{\"files_modified\": [\"templates/aws/index.html\"], \"applied\": [\"suggest.a\"]}\n```"},
+			{ChunkNumber: 2, Output: "no report"},
+		},
+	}
+
+	body := buildPRBody("doc1", result)
+	for _, want := range []string{
+		"Document: [Ubuntu on AWS \\| Copy review](https://docs.google.com/document/d/doc1/edit)",
+		"### Suggestion status",
+		"<summary>1 suggestions</summary>",
+		"| `suggest.a` | Pricing (table) | replace | “Ubuntu on AWS” → “Ubuntu on Amazon Web Services” | verified |",
+		"- `suggest.b` (nested) superseded by `suggest.a`: “AWS” →",
+		"### Files modified by Copilot\n\n- `templates/aws/index.html`\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("buildPRBody() missing %q in:\n%s", want, body)
+		}
+	}
+
+	// Runs that failed before extraction still link the document
+	if got := buildPRBody("doc1", nil); got != "Automated copy update changes from Bauer\n\nDocument: [doc1](https://docs.google.com/document/d/doc1/edit)\n" {
+		t.Errorf("buildPRBody() without result = %q", got)
+	}
+}
+
+func TestBuildCombinedPRBody(t *testing.T) {
+	docs := []combinedDocument{
+		{docID: "doc1", result: &orchestrator.OrchestrationResult{
+			ExtractionResult: &gdocs.ProcessingResult{DocumentTitle: "Ubuntu on AWS"},
+			Diff: &orchestrator.DiffStats{
+				Files:   []orchestrator.FileDiff{{Path: "templates/aws/index.html", Added: 2, Removed: 1}},
+				Added:   2,
+				Removed: 1,
+			},
+		}},
+		{docID: "doc2"},
+	}
+	body := buildCombinedPRBody(docs)
+	for _, want := range []string{
+		"Automated copy update changes from Bauer, for 2 documents\n",
+		"\n## Ubuntu on AWS\n\nDocument: [Ubuntu on AWS](https://docs.google.com/document/d/doc1/edit)\n",
+		"| `templates/aws/index.html` | 2 | 1 |\n",
+		"\n## doc2\n\nDocument: [doc2](https://docs.google.com/document/d/doc2/edit)\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("buildCombinedPRBody() missing %q in:\n%s", want, body)
+		}
+	}
+
+	if batchKey([]string{"doc1", "doc2"}) != batchKey([]string{"doc2", "doc1"}) {
+		t.Error("batchKey() depends on the order of the documents")
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("a", snippetLength+10)
+	if got := snippet(long); got != "“"+strings.Repeat("a", snippetLength-1)+"…”" {
+		t.Errorf("snippet() = %q", got)
+	}
+	if got := snippet("  \n "); got != "" {
+		t.Errorf("snippet() of blank text = %q, want empty", got)
+	}
+}
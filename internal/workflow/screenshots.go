@@ -0,0 +1,103 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bauer/internal/gdocs"
+	"bauer/internal/github"
+	"bauer/internal/screenshot"
+)
+
+// captureScreenshots captures the pages of a document before and after the
+// changes of the run. The changes are stashed for the before screenshots.
+func captureScreenshots(ctx context.Context, opts screenshot.Options, repoPath string, pages []string, outDir string) ([]screenshot.Shot, error) {
+	stashed, err := github.StashChanges(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	before, beforeErr := screenshot.Capture(ctx, opts, repoPath, pages, outDir, "before")
+	if stashed {
+		if err := github.RestoreChanges(repoPath); err != nil {
+			return nil, err
+		}
+	}
+	if beforeErr != nil {
+		return nil, beforeErr
+	}
+	after, err := screenshot.Capture(ctx, opts, repoPath, pages, outDir, "after")
+	if err != nil {
+		return nil, err
+	}
+
+	var shots []screenshot.Shot
+	for _, page := range pages {
+		if before[page] != "" || after[page] != "" {
+			shots = append(shots, screenshot.Shot{PageURL: page, Before: before[page], After: after[page]})
+		}
+	}
+	return shots, nil
+}
+
+// publishScreenshots uploads screenshots to the <prefix>/screenshots branch of
+// the repository, under the branch of the PR, and comments on the PR with them.
+func publishScreenshots(setup *github.GitHubSetupOutput, prefix, prURL string, shots []screenshot.Shot) error {
+	dir := fmt.Sprintf("%s/%d", setup.BranchName, time.Now().Unix())
+	files := make(map[string]string)
+	for _, shot := range shots {
+		for _, file := range []string{shot.Before, shot.After} {
+			if file != "" {
+				files[path.Join(dir, filepath.Base(file))] = file
+			}
+		}
+	}
+
+	commit, err := github.PublishFiles(setup.LocalPath, prefix+"/screenshots", files, "Screenshots of "+setup.BranchName)
+	if err != nil {
+		return err
+	}
+	repo := setup.Repo
+	return github.CommentPR(prURL, screenshotComment(shots, func(file string) string {
+		return github.FileURL(repo.Host, repo.Owner, repo.Name, commit, path.Join(dir, filepath.Base(file)))
+	}))
+}
+
+// screenshotComment renders the PR comment with the screenshots of each page,
+// side by side.
+func screenshotComment(shots []screenshot.Shot, fileURL func(string) string) string {
+	image := func(file string) string {
+		if file == "" {
+			return "Not captured"
+		}
+		return fmt.Sprintf(`<img src="%s" width="400">`, fileURL(file))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### Screenshots\n\nThe pages of the document before and after the changes.\n")
+	for _, shot := range shots {
+		fmt.Fprintf(&sb, "\n#### %s\n\n| Before | After |\n| --- | --- |\n| %s | %s |\n", shot.PageURL, image(shot.Before), image(shot.After))
+	}
+	return sb.String()
+}
+
+// pageURLs lists the URLs of the pages of a document: those of its page
+// sections, or the suggested URL of its metadata.
+func pageURLs(result *gdocs.ProcessingResult) []string {
+	if result == nil {
+		return nil
+	}
+	var urls []string
+	for _, page := range result.Pages {
+		if page.URL != "" {
+			urls = append(urls, page.URL)
+		}
+	}
+	if len(urls) == 0 && result.Metadata != nil && result.Metadata.SuggestedUrl != "" {
+		urls = append(urls, result.Metadata.SuggestedUrl)
+	}
+	return urls
+}
@@ -2,15 +2,25 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"bauer/internal/config"
+	"bauer/internal/copilotcli"
+	"bauer/internal/gdocs"
 	"bauer/internal/github"
+	"bauer/internal/ledger"
 	"bauer/internal/orchestrator"
+	"bauer/internal/progress"
+	"bauer/internal/screenshot"
+	"bauer/internal/sites"
+	"bauer/internal/state"
 )
 
 // WorkflowInput represents the input for a complete workflow execution
@@ -20,6 +30,15 @@ type WorkflowInput struct {
 	GitHubToken  string
 	BranchPrefix string
 
+	// GitHubHost is the GitHub Enterprise Server host of the repository;
+	// empty means GITHUB_API_URL, or github.com
+	GitHubHost string
+
+	// ShallowClone clones only the latest commit, and SparsePaths only
+	// checks out these directories of the repository
+	ShallowClone bool
+	SparsePaths  []string
+
 	// Bauer configuration
 	DocID       string
 	Credentials string
@@ -28,6 +47,148 @@ type WorkflowInput struct {
 	OutputDir   string
 	Model       string
 	DryRun      bool
+	Resume      bool
+	StaleCheck  string
+	ChunkOrder  string
+	TemplateDir string
+	DirectApply bool
+
+	// DirectThreshold is the confidence fuzzy matches need to be patched
+	// with DirectApply
+	DirectThreshold float64
+
+	// Triage classifies the suggestions, and TriageRoutes route them by
+	// category or risk
+	Triage       string
+	TriageRoutes []string
+
+	// MaxChunkTokens is the estimated size over which a chunk is flagged
+	MaxChunkTokens int
+
+	// StateFile records the outcome of the suggestions across runs, and
+	// where their changes went; Reapply processes those already applied
+	StateFile string
+	Reapply   bool
+
+	// Executor selects the LLM backend that applies the chunks, and
+	// SummaryModel the model of the summary session
+	Executor     string
+	SummaryModel string
+
+	// AllowedTools, ExcludedTools, DenyShell, DenyNetwork and RestrictWrites
+	// restrict Copilot sessions
+	AllowedTools   []string
+	ExcludedTools  []string
+	DenyShell      bool
+	DenyNetwork    bool
+	RestrictWrites bool
+
+	// ChunkRetries and FallbackModel retry chunks whose Copilot session failed
+	ChunkRetries  int
+	FallbackModel string
+
+	// ValidateCommand checks the changes before they are pushed; up to
+	// ValidateRetries sessions fix its errors
+	ValidateCommand string
+	ValidateRetries int
+
+	// SkipTemplateCheck disables checking changed HTML templates for damage
+	SkipTemplateCheck bool
+
+	// Approval holds the run until its plan is approved, and
+	// ApprovalWebhook is posted the plan
+	Approval        string
+	ApprovalWebhook string
+
+	// Limits of the run, which stop it before its changes are pushed
+	MaxSuggestions     int
+	MaxChunks          int
+	MaxCopilotDuration string
+	MaxFilesModified   int
+	MaxLinesChanged    int
+
+	// UnexpectedChanges is "warn" or "fail" for runs changing files outside
+	// the pages of the document
+	UnexpectedChanges string
+
+	// ProtectedPaths are patterns of files chunks must not change
+	ProtectedPaths []string
+
+	// SkipCodeOwnerReviews disables requesting reviews from CODEOWNERS of modified files
+	SkipCodeOwnerReviews bool
+
+	// Reviewers, Assignees, Labels and Milestone are set on the created PR.
+	// ReviewerMap adds the authors of the document's suggestions and
+	// comments, by email, as reviewers
+	Reviewers   []string
+	Assignees   []string
+	Labels      []string
+	Milestone   string
+	ReviewerMap github.HandleMap
+
+	// CommentOnDoc comments on the Google Doc with the PR and the status of
+	// its suggestions
+	CommentOnDoc bool
+
+	// ScreenshotServer starts the site's development server, serving at
+	// ScreenshotURL, to capture the document's pages before and after the
+	// changes with ScreenshotBrowser; the screenshots are added to the PR
+	ScreenshotServer  string
+	ScreenshotURL     string
+	ScreenshotBrowser string
+
+	// CredentialsMode selects where Google credentials come from (default: the Credentials file)
+	CredentialsMode string
+
+	// APIMaxAttempts is the number of attempts per Google API call (default: 5)
+	APIMaxAttempts int
+
+	// NoCache fetches the full document instead of reusing a cached copy
+	NoCache bool
+
+	// DumpRaw writes the raw document JSON; Replay builds the run from one
+	DumpRaw bool
+	Replay  string
+
+	// IncludeComments treats unresolved comments on quoted text as suggestions
+	IncludeComments bool
+
+	// OnlyAuthors, Since and SuggestionIDs limit the run to some suggestions
+	OnlyAuthors   []string
+	Since         string
+	SuggestionIDs []string
+
+	// AnchorLength and AnchorBoundary control the text around each suggestion
+	AnchorLength   int
+	AnchorBoundary string
+
+	// Normalize lists the anchor normalization steps
+	Normalize []string
+
+	// MergeSentences combines suggestions in the same sentence
+	MergeSentences bool
+
+	// ConflictStrategy picks which of two overlapping suggestions wins
+	ConflictStrategy string
+
+	// Source and File read the document from a local file instead of Google Docs;
+	// Before is the old page version for the diff source
+	Source string
+	File   string
+	Before string
+
+	// BeforeRevision and AfterRevision are the revisions of the Google Doc
+	// compared by the revisions source
+	BeforeRevision string
+	AfterRevision  string
+
+	// Tab is the only tab read of documents with tabs
+	Tab string
+
+	// Sites map the pages of documents onto their repositories and
+	// templates; without GitHubRepo, the repository of the document's site
+	// is used
+	Sites sites.Table
 
 	// Local repository path
 	LocalRepoPath string
@@ -47,11 +208,40 @@ type WorkflowOutput struct {
 
 	// Bauer Processing
 	BauerResult struct {
+		// RunID identifies the run, and ReportPath is its report, e.g. for
+		// `bauer rollback`
+		RunID      string `json:"run_id,omitempty"`
+		ReportPath string `json:"report_path,omitempty"`
+
 		ExtractionDuration time.Duration `json:"extraction_duration"`
 		PlanDuration       time.Duration `json:"plan_duration"`
 		CopilotDuration    time.Duration `json:"copilot_duration"`
 		ChunkCount         int           `json:"chunk_count"`
 		TotalSuggestions   int           `json:"total_suggestions"`
+
+		// SuggestionStatus counts suggestions per pipeline status; Suggestions has the per-suggestion detail
+		SuggestionStatus map[ledger.Status]int `json:"suggestion_status,omitempty"`
+		Suggestions      []*ledger.Entry       `json:"suggestions,omitempty"`
+
+		// Stats summarises the suggestions by change type, section and table
+		Stats *gdocs.SuggestionStats `json:"stats,omitempty"`
+
+		// Usage is the token usage of the run; ChunkUsage that of each chunk executed
+		Usage      *copilotcli.Usage `json:"usage,omitempty"`
+		ChunkUsage []ChunkUsage      `json:"chunk_usage,omitempty"`
+
+		// ChunkOutputs are the Copilot outputs of the chunks, left out of
+		// responses for their size
+		ChunkOutputs []copilotcli.ChunkOutput `json:"-"`
+
+		// Screenshots are the pages captured before and after the changes
+		Screenshots []screenshot.Shot `json:"screenshots,omitempty"`
+
+		// Validation is the result of the validation command, when given
+		Validation *orchestrator.ValidationResult `json:"validation,omitempty"`
+
+		// TemplateDamage is the damage chunks did to HTML templates
+		TemplateDamage []orchestrator.TemplateDamage `json:"template_damage,omitempty"`
 	} `json:"bauer_result"`
 
 	// GitHub Finalization
@@ -62,7 +252,12 @@ type WorkflowOutput struct {
 			URL    string
 			Number int
 			Title  string
+
+			// Updated is true when the PR of an earlier run was updated
+			Updated bool
 		}
+		FileOwners map[string][]string `json:"file_owners,omitempty"`
+		Reviewers  []string            `json:"reviewers,omitempty"`
 	} `json:"finalization_info"`
 
 	// Overall
@@ -74,6 +269,12 @@ type WorkflowOutput struct {
 	Warnings      []string      `json:"warnings"`
 }
 
+// ChunkUsage is the token usage of a chunk, over all its attempts.
+type ChunkUsage struct {
+	ChunkNumber int `json:"chunk_number"`
+	copilotcli.Usage
+}
+
 // ExecuteWorkflow orchestrates the complete flow:
 // 1. GitHub Setup (clone, create branch)
 // 2. Bauer Processing (extract, chunk, apply changes)
@@ -88,34 +289,6 @@ func ExecuteWorkflow(ctx context.Context, input WorkflowInput, orch orchestrator
 
 	logger := slog.Default()
 
-	// GitHub setup
-	logger.Info("workflow: Setting up GitHub")
-
-	githubSetupInput := github.GitHubSetupInput{
-		GitHubRepo:    input.GitHubRepo,
-		GitHubToken:   input.GitHubToken,
-		BranchPrefix:  input.BranchPrefix,
-		LocalRepoPath: input.LocalRepoPath,
-	}
-
-	githubSetupOutput, err := github.SetupGitHubPhase(githubSetupInput)
-	if err != nil {
-		output.Status = "failed"
-		output.Errors = append(output.Errors, err.Error())
-		output.EndTime = time.Now()
-		output.TotalDuration = output.EndTime.Sub(output.StartTime)
-		return output, err
-	}
-	// Store GH setup results
-	output.RepositoryInfo.Owner = githubSetupOutput.Repo.Owner
-	output.RepositoryInfo.Repo = githubSetupOutput.Repo.Name
-	output.RepositoryInfo.LocalPath = githubSetupOutput.LocalPath
-	output.RepositoryInfo.BranchName = githubSetupOutput.BranchName
-	output.RepositoryInfo.DefaultBranch = githubSetupOutput.DefaultBranch
-	output.RepositoryInfo.CurrentBranch = githubSetupOutput.CurrentBranch
-
-	logger.Info("workflow success: GitHub setup successful")
-
 	// Convert credentials path to absolute
 	// Do this before changing directory so relative paths work
 	var credentialsPath string
@@ -131,49 +304,79 @@ func ExecuteWorkflow(ctx context.Context, input WorkflowInput, orch orchestrator
 		credentialsPath = absPath
 		logger.Info("workflow: resolved credentials path", "path", credentialsPath)
 	}
+	// Local paths are relative to the current directory, not the
+	// repository; git:<revision>:<path> versions are read from the repository
+	docFiles := []string{input.File, input.Before, input.Replay, input.TemplateDir}
+	for i, path := range docFiles {
+		if path == "" || strings.HasPrefix(path, "git:") {
+			continue
+		}
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			output.Status = "failed"
+			output.Errors = append(output.Errors, fmt.Sprintf("failed to resolve document file path: %v", err))
+			output.EndTime = time.Now()
+			output.TotalDuration = output.EndTime.Sub(output.StartTime)
+			return output, err
+		}
+		docFiles[i] = absPath
+	}
 
-	// Change to target repository directory
-	// Save original directory to restore later
-	originalDir, err := os.Getwd()
+	// Without a repository, the document's pages name it
+	var extractionFile string
+	if input.GitHubRepo == "" {
+		repo, file, cleanup, err := extractSiteRepo(ctx, input, orch, credentialsPath, docFiles)
+		if err != nil {
+			output.Status = "failed"
+			output.Errors = append(output.Errors, err.Error())
+			output.EndTime = time.Now()
+			output.TotalDuration = output.EndTime.Sub(output.StartTime)
+			return output, err
+		}
+		defer cleanup()
+		logger.Info("workflow: found repository of the document's site", "repo", repo)
+		input.GitHubRepo, extractionFile = repo, file
+	}
+	if input.LocalRepoPath == "" {
+		input.LocalRepoPath = DefaultLocalRepoPath(input.GitHubRepo, input.GitHubHost)
+	}
+
+	githubSetupOutput, err := setupGitHub(input, output, false)
 	if err != nil {
-		output.Status = "failed"
-		output.Errors = append(output.Errors, fmt.Sprintf("failed to get current directory: %v", err))
-		output.EndTime = time.Now()
-		output.TotalDuration = output.EndTime.Sub(output.StartTime)
 		return output, err
 	}
+	logger.Info("workflow success: GitHub setup successful")
 
-	if err := os.Chdir(input.LocalRepoPath); err != nil {
+	// Bauer works in the cloned repository; a relative output directory
+	// is inside it, where the commit leaves it out
+	repoPath, err := filepath.Abs(input.LocalRepoPath)
+	if err != nil {
 		output.Status = "failed"
-		output.Errors = append(output.Errors, fmt.Sprintf("failed to change to cloned repository: %v", err))
+		output.Errors = append(output.Errors, fmt.Sprintf("failed to resolve cloned repository path: %v", err))
 		output.EndTime = time.Now()
 		output.TotalDuration = output.EndTime.Sub(output.StartTime)
 		return output, err
 	}
-	logger.Info("workflow: changed to cloned repository", "path", input.LocalRepoPath)
-	defer os.Chdir(originalDir)
+	outputDir := input.OutputDir
+	if !filepath.IsAbs(outputDir) {
+		outputDir = filepath.Join(repoPath, outputDir)
+	}
 
 	// Bauer processing
 	logger.Info("workflow: starting phase 2 - Bauer processing")
 
 	bauerStartTime := time.Now()
 
-	// Create Bauer config with target repo (now current directory)
-	bauerCfg := &config.Config{
-		DocID:           input.DocID,
-		CredentialsPath: credentialsPath, // Use absolute path
-		DryRun:          input.DryRun,
-		ChunkSize:       input.ChunkSize,
-		PageRefresh:     input.PageRefresh,
-		OutputDir:       input.OutputDir,
-		Model:           input.Model,
-		TargetRepo:      ".", // Current directory is the cloned repo
-	}
+	// Create Bauer config working in the cloned repo
+	bauerCfg := bauerConfig(input, credentialsPath, docFiles, repoPath, outputDir)
+	bauerCfg.Extraction = extractionFile
 
 	logger.Info("workflow: Bauer target repository set at", "path", bauerCfg.TargetRepo)
 
 	// Execute Bauer orchestration
 	bauerResult, err := orch.Execute(ctx, bauerCfg)
+	validationFailed := errors.Is(err, orchestrator.ErrValidationFailed) || errors.Is(err, orchestrator.ErrInvalidChunks) ||
+		errors.Is(err, orchestrator.ErrLimitExceeded)
 	if err != nil {
 		output.Status = "partial"
 		output.Errors = append(output.Errors, fmt.Sprintf("Bauer processing error: %v", err))
@@ -181,48 +384,355 @@ func ExecuteWorkflow(ctx context.Context, input WorkflowInput, orch orchestrator
 		// Continue anyway - we can still commit what we have
 	}
 
-	// Store Bauer results
+	recordBauerResult(output, bauerResult)
+
+	logger.Info("Bauer results",
+		"extraction_duration", output.BauerResult.ExtractionDuration,
+		"plan_duration", output.BauerResult.PlanDuration,
+		"copilot_duration", output.BauerResult.CopilotDuration,
+		"chunk_count", output.BauerResult.ChunkCount,
+		"total_suggestions", output.BauerResult.TotalSuggestions,
+	)
+	output.BauerResult.CopilotDuration = time.Since(bauerStartTime)
+
+	// Changes that fail validation, or runs whose chunks were invalid or that
+	// went over a limit, aren't pushed; they are left in the local repository
+	// to look into
+	if validationFailed {
+		output.Status = "failed"
+		output.EndTime = time.Now()
+		output.TotalDuration = output.EndTime.Sub(output.StartTime)
+		logger.Warn("workflow: changes failed validation or a run limit, not pushing", "local_path", input.LocalRepoPath)
+		return output, nil
+	}
+	logger.Info("workflow success: Bauer processing finished")
+
+	finalize(ctx, input, output, githubSetupOutput, bauerResult, repoPath, outputDir, credentialsPath)
+	return output, nil
+}
+
+// bauerConfig returns the configuration of the Bauer processing of input in
+// the repository at repoPath. credentialsPath and docFiles, the document
+// files, template directory and replay of input, are absolute.
+func bauerConfig(input WorkflowInput, credentialsPath string, docFiles []string, repoPath, outputDir string) *config.Config {
+	return &config.Config{
+		DocID:              input.DocID,
+		CredentialsPath:    credentialsPath, // Use absolute path
+		CredentialsMode:    input.CredentialsMode,
+		APIMaxAttempts:     input.APIMaxAttempts,
+		NoCache:            input.NoCache,
+		DumpRaw:            input.DumpRaw,
+		Replay:             docFiles[2],
+		DryRun:             input.DryRun,
+		Resume:             input.Resume,
+		ChunkSize:          input.ChunkSize,
+		PageRefresh:        input.PageRefresh,
+		OutputDir:          outputDir,
+		Model:              input.Model,
+		Executor:           input.Executor,
+		SummaryModel:       input.SummaryModel,
+		AllowedTools:       input.AllowedTools,
+		ExcludedTools:      input.ExcludedTools,
+		DenyShell:          input.DenyShell,
+		DenyNetwork:        input.DenyNetwork,
+		RestrictWrites:     input.RestrictWrites,
+		ChunkRetries:       input.ChunkRetries,
+		FallbackModel:      input.FallbackModel,
+		ValidateCommand:    input.ValidateCommand,
+		ValidateRetries:    input.ValidateRetries,
+		SkipTemplateCheck:  input.SkipTemplateCheck,
+		Approval:           input.Approval,
+		ApprovalWebhook:    input.ApprovalWebhook,
+		MaxSuggestions:     input.MaxSuggestions,
+		MaxChunks:          input.MaxChunks,
+		MaxCopilotDuration: input.MaxCopilotDuration,
+		MaxFilesModified:   input.MaxFilesModified,
+		MaxLinesChanged:    input.MaxLinesChanged,
+		UnexpectedChanges:  input.UnexpectedChanges,
+		ProtectedPaths:     input.ProtectedPaths,
+		StaleCheck:         input.StaleCheck,
+		StateFile:          input.StateFile,
+		Reapply:            input.Reapply,
+		ChunkOrder:         input.ChunkOrder,
+		DirectApply:        input.DirectApply,
+		DirectThreshold:    input.DirectThreshold,
+		Triage:             input.Triage,
+		TriageRoutes:       input.TriageRoutes,
+		MaxChunkTokens:     input.MaxChunkTokens,
+		TemplateDir:        docFiles[3],
+		IncludeComments:    input.IncludeComments,
+		OnlyAuthors:        input.OnlyAuthors,
+		Since:              input.Since,
+		SuggestionIDs:      input.SuggestionIDs,
+		AnchorLength:       input.AnchorLength,
+		AnchorBoundary:     input.AnchorBoundary,
+		Normalize:          input.Normalize,
+		MergeSentences:     input.MergeSentences,
+		ConflictStrategy:   input.ConflictStrategy,
+		Source:             input.Source,
+		File:               docFiles[0],
+		Before:             docFiles[1],
+		BeforeRevision:     input.BeforeRevision,
+		AfterRevision:      input.AfterRevision,
+		Tab:                input.Tab,
+		WorkDir:            repoPath,
+		TargetRepo:         repoPath,
+		Sites:              input.Sites,
+	}
+}
+
+// extractSiteRepo finds the repository of the document of input, whose
+// GitHubRepo is empty, in its Sites by the document's page URLs. The
+// extraction is left in a temporary directory, for the run to reuse;
+// cleanup removes it.
+func extractSiteRepo(ctx context.Context, input WorkflowInput, orch orchestrator.Orchestrator, credentialsPath string, docFiles []string) (repo, extractionFile string, cleanup func(), err error) {
+	if len(input.Sites) == 0 {
+		return "", "", nil, errors.New("a GitHub repository, or sites to find it by, is required")
+	}
+	extractor, ok := orch.(orchestrator.Extractor)
+	if !ok {
+		return "", "", nil, errors.New("a GitHub repository is required")
+	}
+
+	dir, err := os.MkdirTemp("", "bauer-extraction-")
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to create extraction directory: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	// The repository isn't cloned yet: nothing is resolved or checked in it
+	cfg := bauerConfig(input, credentialsPath, docFiles, dir, dir)
+	cfg.StaleCheck = ""
+	result, err := extractor.Extract(ctx, cfg)
+	if err != nil {
+		cleanup()
+		return "", "", nil, err
+	}
+	if repo, err = SiteRepo(input.Sites, result.ExtractionResult); err != nil {
+		cleanup()
+		return "", "", nil, err
+	}
+	return repo, filepath.Join(dir, orchestrator.ExtractionResultFile), cleanup, nil
+}
+
+// SiteRepo returns the repository of the site of the pages of a document.
+func SiteRepo(table sites.Table, result *gdocs.ProcessingResult) (string, error) {
+	var pageURLs []string
+	for _, page := range result.GroupsByPage(result.GroupedSuggestions) {
+		pageURLs = append(pageURLs, page.URL)
+	}
+	return table.Repo(pageURLs)
+}
+
+// DefaultLocalRepoPath is where a repository is cloned when LocalRepoPath is
+// empty: a directory named after it in the temporary directory.
+func DefaultLocalRepoPath(githubRepo, githubHost string) string {
+	name := "repository"
+	if repo, err := github.ParseGitHubRepo(githubRepo, github.ResolveHost(githubHost)); err == nil {
+		name = repo.Name
+	}
+	return filepath.Join(os.TempDir(), name)
+}
+
+// PublishChanges commits, pushes and opens or updates the pull request of
+// changes an earlier run made in the local repository, e.g. `bauer apply`,
+// from the result of that run: nothing is cloned and no document is read.
+// The repository continues on its Bauer branch, if it is on one.
+func PublishChanges(ctx context.Context, input WorkflowInput, bauerResult *orchestrator.OrchestrationResult) (*WorkflowOutput, error) {
+	output := &WorkflowOutput{
+		Status:    "pending",
+		StartTime: time.Now(),
+		Errors:    []string{},
+		Warnings:  []string{},
+	}
+	fail := func(err error) (*WorkflowOutput, error) {
+		output.Status = "failed"
+		output.Errors = append(output.Errors, err.Error())
+		output.EndTime = time.Now()
+		output.TotalDuration = output.EndTime.Sub(output.StartTime)
+		return output, err
+	}
+
+	// Changes that failed validation or went over a limit aren't pushed, as
+	// in ExecuteWorkflow
+	if validation := bauerResult.Validation; (validation != nil && !validation.Passed) || len(bauerResult.TemplateDamage) > 0 {
+		return fail(orchestrator.ErrValidationFailed)
+	}
+	if diff := bauerResult.Diff; diff != nil && diff.Blocked {
+		return fail(fmt.Errorf("%w: unexpected files changed: %s", orchestrator.ErrValidationFailed, strings.Join(diff.Unexpected, ", ")))
+	}
+	if bauerResult.LimitExceeded != "" {
+		return fail(fmt.Errorf("%w: %s", orchestrator.ErrLimitExceeded, bauerResult.LimitExceeded))
+	}
+	repoPath, err := filepath.Abs(input.LocalRepoPath)
+	if err != nil {
+		return fail(fmt.Errorf("failed to resolve repository path: %w", err))
+	}
+	outputDir := input.OutputDir
+	if !filepath.IsAbs(outputDir) {
+		outputDir = filepath.Join(repoPath, outputDir)
+	}
+	var credentialsPath string
+	if input.Credentials != "" {
+		if credentialsPath, err = filepath.Abs(input.Credentials); err != nil {
+			return fail(fmt.Errorf("failed to resolve credentials path: %w", err))
+		}
+	}
+
+	if input.GitHubRepo == "" {
+		if input.GitHubRepo, err = SiteRepo(input.Sites, bauerResult.ExtractionResult); err != nil {
+			return fail(err)
+		}
+	}
+
+	input.Resume = true
+	githubSetupOutput, err := setupGitHub(input, output, true)
+	if err != nil {
+		return output, err
+	}
+	recordBauerResult(output, bauerResult)
+	finalize(ctx, input, output, githubSetupOutput, bauerResult, repoPath, outputDir, credentialsPath)
+	return output, nil
+}
+
+// setupGitHub runs the GitHub setup phase and records the repository in
+// output. useLocal takes the local repository as it is; see
+// github.GitHubSetupInput.UseLocal. Output is marked failed on errors.
+func setupGitHub(input WorkflowInput, output *WorkflowOutput, useLocal bool) (*github.GitHubSetupOutput, error) {
+	logger := slog.Default()
+
+	// GitHub setup
+	logger.Info("workflow: Setting up GitHub")
+
+	githubSetupInput := github.GitHubSetupInput{
+		GitHubRepo:    input.GitHubRepo,
+		GitHubToken:   input.GitHubToken,
+		BranchPrefix:  input.BranchPrefix,
+		LocalRepoPath: input.LocalRepoPath,
+		Resume:        input.Resume,
+		DocID:         input.DocID,
+		GitHubHost:    input.GitHubHost,
+		Clone:         github.CloneOptions{Shallow: input.ShallowClone, SparsePaths: input.SparsePaths},
+		UseLocal:      useLocal,
+	}
+
+	githubSetupOutput, err := github.SetupGitHubPhase(githubSetupInput)
+	if err != nil {
+		output.Status = "failed"
+		output.Errors = append(output.Errors, err.Error())
+		output.EndTime = time.Now()
+		output.TotalDuration = output.EndTime.Sub(output.StartTime)
+		return nil, err
+	}
+	// Store GH setup results
+	output.RepositoryInfo.Owner = githubSetupOutput.Repo.Owner
+	output.RepositoryInfo.Repo = githubSetupOutput.Repo.Name
+	output.RepositoryInfo.LocalPath = githubSetupOutput.LocalPath
+	output.RepositoryInfo.BranchName = githubSetupOutput.BranchName
+	output.RepositoryInfo.DefaultBranch = githubSetupOutput.DefaultBranch
+	output.RepositoryInfo.CurrentBranch = githubSetupOutput.CurrentBranch
+	return githubSetupOutput, nil
+}
+
+// recordBauerResult stores the result of the Bauer processing in output.
+func recordBauerResult(output *WorkflowOutput, bauerResult *orchestrator.OrchestrationResult) {
 	if bauerResult != nil {
 		output.BauerResult.ExtractionDuration = bauerResult.ExtractionDuration
 		output.BauerResult.PlanDuration = bauerResult.PlanDuration
 		output.BauerResult.CopilotDuration = bauerResult.CopilotDuration
+		if bauerResult.Report != nil {
+			output.BauerResult.RunID = bauerResult.Report.RunID
+		}
 		if len(bauerResult.Chunks) > 0 {
 			output.BauerResult.ChunkCount = len(bauerResult.Chunks)
 		}
+		if bauerResult.Ledger != nil {
+			output.BauerResult.TotalSuggestions = len(bauerResult.Ledger.Entries)
+			output.BauerResult.SuggestionStatus = bauerResult.Ledger.Counts()
+			output.BauerResult.Suggestions = bauerResult.Ledger.Entries
+		}
 		if bauerResult.ExtractionResult != nil {
-			// Count total suggestions from extraction result
-			output.BauerResult.TotalSuggestions = 0 // TODO: adjust based on actual field
+			output.BauerResult.Stats = bauerResult.ExtractionResult.Stats
+		}
+		output.BauerResult.Validation = bauerResult.Validation
+		output.BauerResult.TemplateDamage = bauerResult.TemplateDamage
+		output.BauerResult.ChunkOutputs = bauerResult.CopilotOutputs
+		if !bauerResult.Usage.IsZero() {
+			output.BauerResult.Usage = &bauerResult.Usage
+			for _, chunk := range bauerResult.CopilotOutputs {
+				if usage := chunk.TotalUsage(); !usage.IsZero() {
+					output.BauerResult.ChunkUsage = append(output.BauerResult.ChunkUsage, ChunkUsage{ChunkNumber: chunk.ChunkNumber, Usage: usage})
+				}
+			}
 		}
 	}
+}
 
-	logger.Info("Bauer results",
-		"extraction_duration", output.BauerResult.ExtractionDuration,
-		"plan_duration", output.BauerResult.PlanDuration,
-		"copilot_duration", output.BauerResult.CopilotDuration,
-		"chunk_count", output.BauerResult.ChunkCount,
-		"total_suggestions", output.BauerResult.TotalSuggestions,
-	)
-	output.BauerResult.CopilotDuration = time.Since(bauerStartTime)
-	logger.Info("workflow success: Bauer processing finished")
+// finalize runs the GitHub finalization phase: the changes in repoPath are
+// committed and pushed, and the pull request opened or updated, with
+// screenshots, the document comment and the run report on the way. The
+// status of output is set from its errors.
+func finalize(ctx context.Context, input WorkflowInput, output *WorkflowOutput, githubSetupOutput *github.GitHubSetupOutput,
+	bauerResult *orchestrator.OrchestrationResult, repoPath, outputDir, credentialsPath string) {
+	logger := slog.Default()
+
+	// Screenshots are taken before the changes are committed, which are
+	// set aside for the before screenshots; the run stands without them
+	screenshots := screenshot.Options{
+		ServerCommand: input.ScreenshotServer,
+		BaseURL:       input.ScreenshotURL,
+		Browser:       input.ScreenshotBrowser,
+	}
+	var screenshotPages []string
+	if bauerResult != nil {
+		screenshotPages = pageURLs(bauerResult.ExtractionResult)
+	}
+	if screenshots.Enabled() && len(screenshotPages) > 0 {
+		shots, err := captureScreenshots(ctx, screenshots, repoPath, screenshotPages, filepath.Join(outputDir, "screenshots"))
+		if err != nil {
+			output.Warnings = append(output.Warnings, fmt.Sprintf("failed to capture screenshots: %v", err))
+			logger.Warn("workflow: failed to capture screenshots", "error", err)
+		}
+		output.BauerResult.Screenshots = shots
+	}
 
 	// GitHub finalization
 	logger.Info("workflow: GitHub finalization")
 
 	commitMessage := fmt.Sprintf("Apply BAU suggestions from doc %s", input.DocID)
 	prTitle := fmt.Sprintf("Apply BAU suggestions to %s", githubSetupOutput.Repo.Name)
-	prBody := fmt.Sprintf("Automated copy update changes from Bauer\n\nGDoc ID: %s", input.DocID)
+	prBody := buildPRBody(input.DocID, bauerResult)
+
+	var pages []github.PageFiles
+	reviewers := append([]string{}, input.Reviewers...)
+	if bauerResult != nil {
+		pages = pageFiles(repoPath, bauerResult.ExtractionResult, input.Sites)
+		for _, handle := range input.ReviewerMap.Handles(authorEmails(bauerResult.ExtractionResult)) {
+			if !slices.Contains(reviewers, handle) {
+				reviewers = append(reviewers, handle)
+			}
+		}
+	}
 
 	finalizationInput := github.GitHubFinalizationInput{
 		LocalRepoPath: input.LocalRepoPath,
 		BranchName:    githubSetupOutput.BranchName,
 		DefaultBranch: githubSetupOutput.DefaultBranch,
+		Host:          githubSetupOutput.Repo.Host,
 		Owner:         githubSetupOutput.Repo.Owner,
 		Repo:          githubSetupOutput.Repo.Name,
 		CommitMessage: commitMessage,
 		DryRun:        input.DryRun,
 		PRTitle:       prTitle,
 		PRBody:        prBody,
-		Labels:        []string{},
+		Labels:        input.Labels,
+		Assignees:     input.Assignees,
+		Milestone:     input.Milestone,
+		Reviewers:     reviewers,
+
+		RequestCodeOwnerReviews: !input.SkipCodeOwnerReviews,
+		Pages:                   pages,
+		ExistingPR:              githubSetupOutput.ExistingPR,
 	}
 
 	finalizationOutput, _ := github.FinalizeGitHubPhase(finalizationInput)
@@ -232,11 +742,61 @@ func ExecuteWorkflow(ctx context.Context, input WorkflowInput, orch orchestrator
 	output.FinalizationInfo.BranchPushed = finalizationOutput.BranchPushed
 	output.FinalizationInfo.PullRequest.URL = finalizationOutput.PullRequest.URL
 	output.FinalizationInfo.PullRequest.Title = finalizationOutput.PullRequest.Title
+	output.FinalizationInfo.PullRequest.Number = finalizationOutput.PullRequest.Number
+	output.FinalizationInfo.PullRequest.Updated = finalizationOutput.PullRequest.Updated
+	output.FinalizationInfo.FileOwners = finalizationOutput.FileOwners
+	output.FinalizationInfo.Reviewers = finalizationOutput.Reviewers
+	if prURL := finalizationOutput.PullRequest.URL; prURL != "" {
+		progress.Report(ctx, progress.Event{Type: progress.EventPRCreated, URL: prURL})
+	}
 
 	// Merge warnings and errors from finalization
 	output.Warnings = append(output.Warnings, finalizationOutput.Warnings...)
 	output.Errors = append(output.Errors, finalizationOutput.Errors...)
 
+	if prURL := finalizationOutput.PullRequest.URL; prURL != "" && len(output.BauerResult.Screenshots) > 0 {
+		if err := publishScreenshots(githubSetupOutput, input.BranchPrefix, prURL, output.BauerResult.Screenshots); err != nil {
+			output.Warnings = append(output.Warnings, fmt.Sprintf("failed to add screenshots to PR: %v", err))
+			logger.Warn("workflow: failed to add screenshots to PR", "error", err)
+		}
+	}
+
+	// Tell the document's reviewers where their suggestions went; the PR
+	// stands without it
+	if prURL := finalizationOutput.PullRequest.URL; input.CommentOnDoc && prURL != "" {
+		if err := commentOnDoc(ctx, input, credentialsPath, prURL, finalizationOutput.PullRequest.Updated, bauerResult); err != nil {
+			output.Warnings = append(output.Warnings, fmt.Sprintf("failed to comment on document: %v", err))
+			logger.Warn("workflow: failed to comment on document", "error", err)
+		} else {
+			logger.Info("workflow: commented on document", "doc_id", input.DocID)
+		}
+	}
+
+	if err := recordPublished(input, bauerResult, finalizationOutput.BranchPushed, finalizationOutput.PullRequest.URL); err != nil {
+		output.Warnings = append(output.Warnings, fmt.Sprintf("failed to update suggestion state: %v", err))
+		logger.Warn("workflow: failed to update suggestion state", "error", err)
+	}
+
+	// The run report is written again with where the changes went
+	if bauerResult != nil && bauerResult.Report != nil {
+		bauerResult.Report.Git = &orchestrator.GitReport{
+			Repository:         fmt.Sprintf("%s/%s/%s", githubSetupOutput.Repo.Host, githubSetupOutput.Repo.Owner, githubSetupOutput.Repo.Name),
+			Branch:             githubSetupOutput.BranchName,
+			BaseBranch:         githubSetupOutput.DefaultBranch,
+			CommitMessage:      finalizationOutput.CommitMessage,
+			Pushed:             finalizationOutput.BranchPushed,
+			PullRequestURL:     finalizationOutput.PullRequest.URL,
+			PullRequestNumber:  finalizationOutput.PullRequest.Number,
+			PullRequestUpdated: finalizationOutput.PullRequest.Updated,
+		}
+		if err := bauerResult.Report.Save(outputDir); err != nil {
+			output.Warnings = append(output.Warnings, fmt.Sprintf("failed to write run report: %v", err))
+			logger.Warn("workflow: failed to write run report", "error", err)
+		} else {
+			output.BauerResult.ReportPath = filepath.Join(outputDir, orchestrator.ReportFile)
+		}
+	}
+
 	logger.Info("workflow: phase 3 complete - GitHub finalization finished")
 
 	output.EndTime = time.Now()
@@ -256,6 +816,74 @@ func ExecuteWorkflow(ctx context.Context, input WorkflowInput, orch orchestrator
 		"errors", len(output.Errors),
 		"warnings", len(output.Warnings),
 	)
+}
 
-	return output, nil
+// recordPublished records the pull request the suggestions of a run went to
+// in the state file. Suggestions whose changes weren't pushed are forgotten,
+// for the next run to apply again.
+func recordPublished(input WorkflowInput, bauerResult *orchestrator.OrchestrationResult, pushed bool, prURL string) error {
+	if input.StateFile == "" || input.DryRun || bauerResult == nil || bauerResult.Report == nil {
+		return nil
+	}
+	store, err := state.Open(input.StateFile)
+	if err != nil {
+		return err
+	}
+	report := bauerResult.Report
+	if pushed {
+		store.SetPullRequest(report.DocumentID, report.RunID, prURL)
+	} else {
+		store.Reopen(report.DocumentID, report.RunID)
+	}
+	return store.Save()
+}
+
+// authorEmails lists the emails of the authors of a document's suggestions
+// and comments, in order of appearance.
+func authorEmails(result *gdocs.ProcessingResult) []string {
+	if result == nil {
+		return nil
+	}
+
+	var emails []string
+	add := func(email string) {
+		if email != "" && !slices.Contains(emails, email) {
+			emails = append(emails, email)
+		}
+	}
+	for _, group := range result.GroupedSuggestions {
+		for _, sugg := range group.Suggestions {
+			add(sugg.AuthorEmail)
+		}
+	}
+	for _, comment := range result.Comments {
+		add(comment.AuthorEmail)
+	}
+	return emails
+}
+
+// pageFiles lists the files of each page of a document that covers several
+// pages: the page's template and the files its suggestions were found in.
+// repoPath is the cloned repository, and table the sites of its templates.
+func pageFiles(repoPath string, result *gdocs.ProcessingResult, table sites.Table) []github.PageFiles {
+	if result == nil || len(result.Pages) == 0 {
+		return nil
+	}
+
+	var pages []github.PageFiles
+	for _, page := range result.GroupsByPage(result.GroupedSuggestions) {
+		var files []string
+		if template, err := table.TemplatePath(repoPath, page.URL); err == nil {
+			if rel, err := filepath.Rel(repoPath, template); err == nil {
+				files = append(files, filepath.ToSlash(rel))
+			}
+		}
+		for _, group := range page.Groups {
+			if group.ResolvedFile != "" && !slices.Contains(files, group.ResolvedFile) {
+				files = append(files, group.ResolvedFile)
+			}
+		}
+		pages = append(pages, github.PageFiles{URL: page.URL, Files: files})
+	}
+	return pages
 }
@@ -0,0 +1,47 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"bauer/internal/gdocs"
+	"bauer/internal/screenshot"
+)
+
+func TestAuthorEmails(t *testing.T) {
+	author := func(email string) gdocs.GroupedActionableSuggestion {
+		return gdocs.GroupedActionableSuggestion{SuggestionAuthor: gdocs.SuggestionAuthor{AuthorEmail: email}}
+	}
+	result := &gdocs.ProcessingResult{
+		GroupedSuggestions: []gdocs.LocationGroupedSuggestions{
+			{Suggestions: []gdocs.GroupedActionableSuggestion{author("jane@example.com"), author("")}},
+			{Suggestions: []gdocs.GroupedActionableSuggestion{author("john@example.com"), author("jane@example.com")}},
+		},
+		Comments: []gdocs.Comment{{AuthorEmail: "ann@example.com"}, {AuthorEmail: "john@example.com"}},
+	}
+
+	want := []string{"jane@example.com", "john@example.com", "ann@example.com"}
+	if diff := cmp.Diff(want, authorEmails(result)); diff != "" {
+		t.Errorf("authorEmails() mismatch (-want +got):\n%s", diff)
+	}
+	if got := authorEmails(nil); got != nil {
+		t.Errorf("authorEmails(nil) = %v, want nil", got)
+	}
+}
+
+func TestScreenshotComment(t *testing.T) {
+	shots := []screenshot.Shot{
+		{PageURL: "ubuntu.com/desktop", Before: "out/desktop-before.png", After: "out/desktop-after.png"},
+		{PageURL: "ubuntu.com/pro", After: "out/pro-after.png"},
+	}
+	got := screenshotComment(shots, func(file string) string { return "https://example.com/" + file })
+	want := "### Screenshots\n\nThe pages of the document before and after the changes.\n" +
+		"\n#### ubuntu.com/desktop\n\n| Before | After |\n| --- | --- |\n" +
+		`| <img src="https://example.com/out/desktop-before.png" width="400"> | <img src="https://example.com/out/desktop-after.png" width="400"> |` + "\n" +
+		"\n#### ubuntu.com/pro\n\n| Before | After |\n| --- | --- |\n" +
+		`| Not captured | <img src="https://example.com/out/pro-after.png" width="400"> |` + "\n"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("screenshotComment() mismatch (-want +got):\n%s", diff)
+	}
+}